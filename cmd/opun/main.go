@@ -26,7 +26,9 @@ import (
 
 	"github.com/charmbracelet/fang"
 	"github.com/rizome-dev/opun/internal/cli"
+	"github.com/rizome-dev/opun/internal/telemetry"
 	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/internal/workflow"
 )
 
 var (
@@ -36,7 +38,15 @@ var (
 )
 
 func main() {
-	// Set up signal handling for graceful shutdown
+	// Record the build in run manifests and traces
+	workflow.OpunVersion, workflow.OpunCommit = version, commit
+	telemetry.ServiceVersion = version
+
+	// Release sessions, servers and temporary files if opun crashes
+	defer utils.CleanupOnPanic()
+
+	// Set up signal handling for graceful shutdown. Commands register what
+	// needs stopping with utils.RegisterShutdown rather than exiting here.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -48,20 +58,8 @@ func main() {
 		fmt.Fprintln(os.Stderr, "\nReceived interrupt signal, shutting down gracefully...")
 		cancel()
 
-		// Run all registered cleanup functions
-		cleanupDone := make(chan struct{})
-		go func() {
-			utils.RunCleanup()
-			close(cleanupDone)
-		}()
-
-		// Wait for cleanup with timeout
-		select {
-		case <-cleanupDone:
-			// Cleanup completed
-		case <-time.After(3 * time.Second):
-			fmt.Fprintln(os.Stderr, "Cleanup timeout exceeded, forcing exit")
-		}
+		// Run all registered shutdown handlers, each bounded by its timeout
+		utils.RunCleanup()
 
 		os.Exit(130) // Standard exit code for SIGINT
 	}()
@@ -80,7 +78,14 @@ func main() {
 	if err := fang.Execute(ctx, rootCmd); err != nil {
 		// Don't print error if context was cancelled (user interrupted)
 		if ctx.Err() != context.Canceled {
-			os.Exit(1)
+			// Workflow failures exit with the code of their error class
+			os.Exit(workflow.ExitCode(err))
 		}
 	}
+
+	// After an interrupt, returning would end the process while shutdown
+	// handlers are still running; the signal handler exits once they finish
+	if ctx.Err() != nil {
+		time.Sleep(utils.ShutdownTimeout + time.Second)
+	}
 }
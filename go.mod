@@ -11,6 +11,7 @@ require (
 	github.com/spf13/cobra v1.9.1
 	github.com/spf13/viper v1.18.2
 	github.com/stretchr/testify v1.10.0
+	go.starlark.net v0.0.0-20231121155337-90ade8b19d09
 	golang.org/x/term v0.6.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -17,12 +17,15 @@ package cli
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/rizome-dev/opun/internal/config"
 	"github.com/rizome-dev/opun/internal/tools"
 	"github.com/rizome-dev/opun/pkg/core"
 	"github.com/spf13/cobra"
@@ -102,12 +105,34 @@ var testActionCmd = &cobra.Command{
 	},
 }
 
+// importActionCmd registers project commands as actions
+var importActionCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import project scripts as actions",
+	Long: `Import scans a project for package.json scripts, Makefile targets and Taskfile
+tasks and registers them as actions, so agents can run the project's standard
+build, test and lint commands through MCP.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromScripts, _ := cmd.Flags().GetBool("from-scripts")
+		dir, _ := cmd.Flags().GetString("dir")
+		yes, _ := cmd.Flags().GetBool("yes")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !fromScripts {
+			return fmt.Errorf("specify what to import from: --from-scripts")
+		}
+		return importScriptActions(dir, yes, force)
+	},
+}
+
 func init() {
 	actionCmd.AddCommand(listActionsCmd)
 	actionCmd.AddCommand(addActionCmd)
 	actionCmd.AddCommand(removeActionCmd)
 	actionCmd.AddCommand(runActionCmd)
 	actionCmd.AddCommand(testActionCmd)
+	actionCmd.AddCommand(importActionCmd)
 
 	// List flags
 	listActionsCmd.Flags().StringP("provider", "p", "", "Filter by provider")
@@ -120,6 +145,12 @@ func init() {
 	addActionCmd.Flags().String("workflow", "", "Workflow to reference")
 	addActionCmd.Flags().String("prompt", "", "Prompt to reference")
 	addActionCmd.MarkFlagsMutuallyExclusive("command", "workflow", "prompt")
+
+	// Import flags
+	importActionCmd.Flags().Bool("from-scripts", false, "Import package.json scripts, Makefile targets and Taskfile tasks")
+	importActionCmd.Flags().String("dir", ".", "Project directory to scan")
+	importActionCmd.Flags().BoolP("yes", "y", false, "Register without asking for confirmation")
+	importActionCmd.Flags().Bool("force", false, "Overwrite actions that already exist")
 }
 
 func listActions(provider, category string) error {
@@ -161,7 +192,9 @@ func listActions(provider, category string) error {
 
 	for _, action := range actions {
 		actionType := "command"
-		if action.WorkflowRef != "" {
+		if len(action.Steps) > 0 {
+			actionType = "steps"
+		} else if action.WorkflowRef != "" {
 			actionType = "workflow"
 		} else if action.PromptRef != "" {
 			actionType = "prompt"
@@ -229,30 +262,20 @@ func removeAction(actionID string) error {
 	}
 
 	fmt.Printf("Successfully removed action '%s'\n", actionID)
+	cleanupGeneratedArtifacts(config.ArtifactAction, actionID)
 	return nil
 }
 
 func runAction(actionID, args string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	actionsDir := filepath.Join(homeDir, ".opun", "actions")
-	loader := tools.NewLoader(actionsDir)
-
-	if err := loader.LoadAll(); err != nil {
-		return fmt.Errorf("failed to load actions: %w", err)
-	}
-
-	registry := loader.GetRegistry()
-	action, err := registry.Get(actionID)
+	action, err := loadAction(actionID)
 	if err != nil {
-		return fmt.Errorf("action not found: %w", err)
+		return err
 	}
 
 	// Execute based on type
-	if action.Command != "" {
+	if len(action.Steps) > 0 {
+		return runActionSteps(*action, args)
+	} else if action.Command != "" {
 		fmt.Printf("Executing command: %s %s\n", action.Command, args)
 		// In a real implementation, would execute the command
 		fmt.Println("(Command execution would happen here)")
@@ -269,6 +292,157 @@ func runAction(actionID, args string) error {
 	return nil
 }
 
+// loadAction loads an action from ~/.opun/actions
+func loadAction(actionID string) (*core.StandardAction, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	actionsDir := filepath.Join(homeDir, ".opun", "actions")
+	loader := tools.NewLoader(actionsDir)
+
+	if err := loader.LoadAll(); err != nil {
+		return nil, fmt.Errorf("failed to load actions: %w", err)
+	}
+
+	registry := loader.GetRegistry()
+	action, err := registry.Get(actionID)
+	if err != nil {
+		return nil, fmt.Errorf("action not found: %w", err)
+	}
+	return action, nil
+}
+
+// runActionSteps runs a multi-step action, streaming each step's output
+func runActionSteps(action core.StandardAction, args string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	runner := tools.NewStepRunner(cwd)
+	runner.Output = os.Stdout
+
+	fmt.Printf("▶️  Running action '%s' (%d steps)\n", action.Name, len(action.Steps))
+	report, err := runner.Run(ctx, action, args)
+	if report != nil {
+		for _, step := range report.Steps {
+			switch {
+			case step.Skipped:
+				fmt.Printf("⏭️  %s skipped\n", step.Name)
+			case step.Failed:
+				fmt.Printf("❌ %s failed (exit code %d)\n", step.Name, step.ExitCode)
+			default:
+				fmt.Printf("✅ %s\n", step.Name)
+			}
+		}
+		for _, step := range report.RolledBack {
+			if step.Failed {
+				fmt.Printf("⚠️  rollback of %s failed (exit code %d)\n", step.Name, step.ExitCode)
+			} else {
+				fmt.Printf("↩️  rolled back %s\n", step.Name)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("action '%s' failed: %w", action.Name, err)
+	}
+
+	fmt.Printf("✅ Action '%s' completed\n", action.Name)
+	return nil
+}
+
+// importScriptActions discovers the scripts in dir and registers them as actions
+func importScriptActions(dir string, yes, force bool) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	scripts, err := tools.DiscoverScripts(absDir)
+	if err != nil {
+		return fmt.Errorf("failed to discover scripts: %w", err)
+	}
+	if len(scripts) == 0 {
+		fmt.Printf("No package.json scripts, Makefile targets or Taskfile tasks found in %s\n", absDir)
+		return nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	actionsDir := filepath.Join(homeDir, ".opun", "actions")
+	loader := tools.NewLoader(actionsDir)
+	if err := loader.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load actions: %w", err)
+	}
+	registry := loader.GetRegistry()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCOMMAND\tSOURCE\tSTATUS\tDESCRIPTION")
+	fmt.Fprintln(w, "---\t-------\t------\t------\t-----------")
+
+	var toImport []core.StandardAction
+	skipped := 0
+	for _, script := range scripts {
+		action := script.Action()
+		status := "new"
+		if _, err := registry.Get(action.ID); err == nil {
+			status = "exists"
+			if force {
+				status = "overwrite"
+			} else {
+				skipped++
+			}
+		}
+		if status != "exists" {
+			toImport = append(toImport, action)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", action.ID, action.Command, script.Source, status, truncate(script.Description, 50))
+	}
+	w.Flush()
+
+	if len(toImport) == 0 {
+		fmt.Println("\nAll discovered scripts are already registered (use --force to overwrite)")
+		return nil
+	}
+
+	if !yes {
+		ok, err := Confirm(fmt.Sprintf("Register %d actions?", len(toImport)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Import cancelled")
+			return nil
+		}
+	}
+
+	for _, action := range toImport {
+		if _, err := registry.Get(action.ID); err == nil {
+			if err := loader.DeleteAction(action.ID); err != nil {
+				return fmt.Errorf("failed to replace action %s: %w", action.ID, err)
+			}
+		}
+		if err := loader.SaveAction(action); err != nil {
+			return fmt.Errorf("failed to save action %s: %w", action.ID, err)
+		}
+	}
+
+	fmt.Printf("Successfully imported %d actions", len(toImport))
+	if skipped > 0 {
+		fmt.Printf(" (%d already registered)", skipped)
+	}
+	fmt.Println()
+	return nil
+}
+
 func testAction(file string) error {
 	loader := tools.NewLoader("")
 
@@ -17,6 +17,7 @@ package cli
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -39,6 +40,11 @@ func AddCmd() *cobra.Command {
 		asWorkflow bool
 		asPrompt   bool
 		asAction   bool
+		onConflict string
+		all        bool
+		fromURL    string
+		cleanup    bool
+		provider   string
 	)
 
 	cmd := &cobra.Command{
@@ -52,9 +58,18 @@ Examples:
   
   # Add a prompt
   opun add prompt --path prompt.txt --name my-prompt
+
+  # Import a prompt from a web page or gist, letting an agent extract it
+  opun add prompt --from-url https://gist.github.com/user/abc123 --cleanup
   
   # Add an action
   opun add action --path action.yaml --name my-action
+
+  # Replace an existing workflow without asking
+  opun add workflow --path workflow.yaml --name my-workflow --on-conflict=overwrite
+
+  # Add every workflow of a file with several documents separated by ---
+  opun add workflow workflows.yaml --all
   
   # Interactive mode
   opun add`,
@@ -78,25 +93,58 @@ Examples:
 				return runInteractiveAdd()
 			}
 
+			// The file can also follow the type, e.g. `opun add workflow file.yaml`
+			if path == "" && len(args) > 1 {
+				path = args[1]
+			}
+
+			policy, err := conflictPolicy(onConflict)
+			if err != nil {
+				return err
+			}
+
+			if fromURL != "" {
+				if !asPrompt {
+					return fmt.Errorf("--from-url only applies to prompts")
+				}
+				if path != "" {
+					return fmt.Errorf("--from-url can't be combined with --path")
+				}
+				return addPromptFromURL(fromURL, name, cleanup, provider, policy)
+			}
+			if cleanup {
+				return fmt.Errorf("--cleanup only applies to --from-url")
+			}
+
 			// Validate required fields
 			if path == "" {
 				return fmt.Errorf("--path is required")
 			}
 
+			if all {
+				if !asWorkflow {
+					return fmt.Errorf("--all only applies to workflows")
+				}
+				if name != "" {
+					return fmt.Errorf("--name can't be combined with --all; each workflow is added under its own name")
+				}
+				return addAllWorkflows(path, policy)
+			}
+
 			if name == "" {
 				return fmt.Errorf("--name is required")
 			}
 
 			if asWorkflow {
-				return addWorkflow(path, name)
+				return addWorkflow(path, name, policy)
 			}
 
 			if asPrompt {
-				return addPrompt(path, name)
+				return addPrompt(path, name, policy)
 			}
 
 			if asAction {
-				return addActionFromFile(path, name)
+				return addActionFromFile(path, name, policy)
 			}
 
 			return fmt.Errorf("specify either workflow, prompt, or action")
@@ -109,6 +157,11 @@ Examples:
 	cmd.Flags().BoolVar(&asAction, "action", false, "Add an action")
 	cmd.Flags().StringVar(&path, "path", "", "path to file")
 	cmd.Flags().StringVar(&name, "name", "", "name for the item")
+	cmd.Flags().BoolVar(&all, "all", false, "add every workflow of a multi-document file, each under its own name")
+	cmd.Flags().StringVar(&fromURL, "from-url", "", "fetch a prompt from a web page, raw file or gist; the URL is recorded to check for updates")
+	cmd.Flags().BoolVar(&cleanup, "cleanup", false, "with --from-url, have an agent extract the prompt, name, description and tags from the page")
+	cmd.Flags().StringVar(&provider, "cleanup-provider", "", "provider of the --cleanup agent (default claude)")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "when the name is taken: ask, overwrite, rename, skip, merge or fail (default ask in a terminal, fail otherwise)")
 
 	// Only one type can be used at a time
 	cmd.MarkFlagsMutuallyExclusive("workflow", "prompt", "action")
@@ -157,14 +210,63 @@ func copyFile(src, dst string) error {
 	return err
 }
 
-// addWorkflow adds a workflow to the system
-func addWorkflow(path, name string) error {
+// addWorkflow adds a workflow to the system. onConflict says what to do when
+// a workflow with the name already exists.
+func addWorkflow(path, name, onConflict string) error {
 	// Read workflow file
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read workflow file: %w", err)
 	}
 
+	return addWorkflowData(data, name, onConflict)
+}
+
+// addAllWorkflows adds each workflow of a multi-document file under its own name
+func addAllWorkflows(path, onConflict string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow file: %w", err)
+	}
+
+	docs, err := workflow.SplitDocuments(data)
+	if err != nil {
+		return fmt.Errorf("invalid workflow format: %w", err)
+	}
+
+	// Check every document before adding any
+	names := make([]string, len(docs))
+	seen := make(map[string]int)
+	parser := workflow.NewParser("")
+	for i, doc := range docs {
+		wf, err := parser.Parse(doc)
+		if err != nil {
+			return fmt.Errorf("invalid workflow in document %d: %w", i+1, err)
+		}
+		if wf.Name == "" {
+			return fmt.Errorf("workflow in document %d has no name", i+1)
+		}
+		if strings.ContainsAny(wf.Name, `/\`) {
+			return fmt.Errorf("workflow '%s' in document %d can't be used as a file name", wf.Name, i+1)
+		}
+		if other, ok := seen[wf.Name]; ok {
+			return fmt.Errorf("documents %d and %d are both named '%s'", other, i+1, wf.Name)
+		}
+		seen[wf.Name] = i + 1
+		names[i] = wf.Name
+	}
+
+	for i, doc := range docs {
+		if err := addWorkflowData(doc, names[i], onConflict); err != nil {
+			return fmt.Errorf("failed to add '%s': %w", names[i], err)
+		}
+	}
+	fmt.Printf("✓ Added %d workflows from %s\n", len(docs), path)
+	return nil
+}
+
+// addWorkflowData validates and saves a single workflow document
+func addWorkflowData(data []byte, name, onConflict string) error {
 	// Get workflow directory first
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -175,13 +277,24 @@ func addWorkflow(path, name string) error {
 
 	// Parse workflow to validate it
 	parser := workflow.NewParser(workflowDir)
-	wf, err := parser.Parse(data)
+	if _, err := parser.Parse(data); err != nil {
+		return fmt.Errorf("invalid workflow format: %w", err)
+	}
+
+	resolution, err := checkAddConflict("workflow", workflowDir, name, data, onConflict)
 	if err != nil {
+		return err
+	}
+	if resolution.skip {
+		return nil
+	}
+	name, data = resolution.name, resolution.content
+
+	// A merge can produce an invalid workflow
+	if _, err := parser.Parse(data); err != nil {
 		return fmt.Errorf("invalid workflow format: %w", err)
 	}
 
-	// Set the command name
-	wf.Command = name
 	if err := utils.EnsureDir(workflowDir); err != nil {
 		if os.IsPermission(err) {
 			return fmt.Errorf("permission denied: cannot create %s\nTry: sudo chown -R $USER ~/.opun", workflowDir)
@@ -203,6 +316,9 @@ func addWorkflow(path, name string) error {
 
 	// Update config to register the workflow
 	workflows := viper.GetStringSlice("workflows")
+	if containsString(workflows, name) {
+		return nil
+	}
 	workflows = append(workflows, name)
 	viper.Set("workflows", workflows)
 
@@ -219,48 +335,95 @@ func addWorkflow(path, name string) error {
 	return nil
 }
 
-// addPrompt adds a prompt to the prompt garden
-func addPrompt(path, name string) error {
+// addPrompt adds a prompt to the prompt garden. onConflict says what to do
+// when a prompt with the name already exists.
+func addPrompt(path, name, onConflict string) error {
 	// Read prompt file
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read prompt file: %w", err)
 	}
 
+	_, err = savePromptData(path, name, data, promptgarden.PromptMetadata{
+		Tags:        extractTags(string(data)),
+		Description: fmt.Sprintf("Prompt added from %s", filepath.Base(path)),
+	}, onConflict)
+	return err
+}
+
+// savePromptData adds prompt content read from path (a file or URL, whose
+// extension picks the format) to the prompt garden under name. It returns
+// the name it was saved under, empty when the user skipped it.
+func savePromptData(path, name string, data []byte, metadata promptgarden.PromptMetadata, onConflict string) (string, error) {
 	// Get prompt garden
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	gardenPath := filepath.Join(home, ".opun", "promptgarden")
 	garden, err := promptgarden.NewGarden(gardenPath)
 	if err != nil {
-		return fmt.Errorf("failed to access prompt garden: %w", err)
+		return "", fmt.Errorf("failed to access prompt garden: %w", err)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	if existing, err := lookupPrompt(garden, name); err == nil {
+		resolution, err := resolveAddConflict(addConflict{
+			kind:     "prompt",
+			name:     name,
+			existing: []byte(existing.Content()),
+			incoming: data,
+			exists: func(candidate string) bool {
+				_, err := lookupPrompt(garden, candidate)
+				return err == nil
+			},
+		}, onConflict, in, os.Stdout)
+		if err != nil {
+			return "", err
+		}
+		if resolution.skip {
+			return "", nil
+		}
+		name = resolution.name
+	}
+
+	body, err := promptgarden.ParsePromptBody(path, data)
+	if err != nil {
+		return "", err
+	}
+
+	if add, err := warnSimilarPrompts(garden, name, body.Content, onConflict, in, os.Stdout); err != nil || !add {
+		return "", err
 	}
 
 	// Create prompt
 	prompt := &promptgarden.Prompt{
 		ID:      name,
 		Name:    name,
-		Content: string(data),
+		Content: body.Content,
 		Metadata: promptgarden.PromptMetadata{
-			Tags:        extractTags(string(data)),
+			Tags:        metadata.Tags,
 			Category:    "user",
 			Version:     "1.0.0",
-			Description: fmt.Sprintf("Prompt added from %s", filepath.Base(path)),
+			Description: metadata.Description,
+			VariantMode: body.VariantMode,
 		},
+		Variants: body.Variants,
 	}
 
 	// Save prompt
 	if err := garden.SavePrompt(prompt); err != nil {
-		return fmt.Errorf("failed to save prompt: %w", err)
+		return "", fmt.Errorf("failed to save prompt: %w", err)
 	}
 
 	fmt.Printf("✓ Added prompt '%s' to prompt garden\n", name)
+	if len(body.Variants) > 0 {
+		fmt.Printf("  Variants: %d (compare with: opun prompt report %s)\n", len(body.Variants), name)
+	}
 	fmt.Printf("  Access with: promptgarden://%s\n", name)
 
-	return nil
+	return name, nil
 }
 
 // extractTags extracts tags from prompt content (looks for #tag patterns)
@@ -284,8 +447,9 @@ func extractTags(content string) []string {
 	return tags
 }
 
-// addActionFromFile adds an action to the system from a file
-func addActionFromFile(path, name string) error {
+// addActionFromFile adds an action to the system from a file. onConflict says
+// what to do when an action with the name already exists.
+func addActionFromFile(path, name, onConflict string) error {
 	// Read tool file
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -303,6 +467,15 @@ func addActionFromFile(path, name string) error {
 	// Create action loader
 	loader := tools.NewLoader(actionsDir)
 
+	resolution, err := checkAddConflict("action", actionsDir, name, data, onConflict)
+	if err != nil {
+		return err
+	}
+	if resolution.skip {
+		return nil
+	}
+	name, data = resolution.name, resolution.content
+
 	// Parse the tool to validate it
 	tempFile := filepath.Join(os.TempDir(), "temp-action.yaml")
 	if err := utils.WriteFile(tempFile, data); err != nil {
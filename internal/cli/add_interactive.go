@@ -29,6 +29,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rizome-dev/opun/internal/plugin"
 	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -263,10 +264,18 @@ func (m interactiveAddModel) View() string {
 
 // RunInteractiveAdd runs the new interactive add flow
 func RunInteractiveAdd() error {
-	p := tea.NewProgram(initialInteractiveAddModel())
-	result, err := p.Run()
-	if err != nil {
-		return err
+	var result tea.Model
+	if utils.PlainUI() {
+		var err error
+		if result, err = runPlainAdd(initialInteractiveAddModel()); err != nil {
+			return err
+		}
+	} else {
+		p := tea.NewProgram(initialInteractiveAddModel())
+		var err error
+		if result, err = p.Run(); err != nil {
+			return err
+		}
 	}
 
 	model, ok := result.(interactiveAddModel)
@@ -286,6 +295,25 @@ func RunInteractiveAdd() error {
 	}
 }
 
+// runPlainAdd walks the add steps with numbered menus, feeding each choice
+// to the model as if it had been picked with enter
+func runPlainAdd(m interactiveAddModel) (tea.Model, error) {
+	for m.step != "done" {
+		choice, err := plainSelect(m.list.Title, m.list.Items())
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range m.list.Items() {
+			if item == choice {
+				m.list.Select(i)
+			}
+		}
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(interactiveAddModel)
+	}
+	return m, nil
+}
+
 // handleInteractiveCreate handles the interactive creation of components
 func handleInteractiveCreate(itemType itemType) error {
 	switch itemType {
@@ -413,8 +441,7 @@ func createInteractiveAgent(agentID string) (map[string]interface{}, error) {
 		"model":    model,
 		"prompt":   prompt,
 		"settings": map[string]interface{}{
-			"temperature": 0.7,
-			"timeout":     300,
+			"timeout": 300,
 		},
 	}
 
@@ -621,13 +648,13 @@ func handleLocalAdd(itemType itemType) error {
 
 	switch itemType {
 	case itemTypePrompt:
-		return addPrompt(path, name)
+		return addPrompt(path, name, conflictAsk)
 	case itemTypeWorkflow:
-		return addWorkflow(path, name)
+		return addWorkflow(path, name, conflictAsk)
 	case itemTypeAction:
-		return addActionFromFile(path, name)
+		return addActionFromFile(path, name, conflictAsk)
 	case itemTypeTool:
-		return addTool(path, name)
+		return addTool(path, name, conflictAsk)
 	}
 
 	return nil
@@ -680,8 +707,9 @@ func installFromURL(url string, itemType itemType) error {
 	return nil
 }
 
-// addTool adds a tool to the MCP server configuration
-func addTool(path, name string) error {
+// addTool adds a tool to the MCP server configuration. onConflict says what to
+// do when a tool with the name already exists.
+func addTool(path, name, onConflict string) error {
 	// Get home directory
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -714,15 +742,24 @@ func addTool(path, name string) error {
 		return fmt.Errorf("tool description is required")
 	}
 
-	// Save to tools directory
-	destPath := filepath.Join(toolsDir, name+".yaml")
-
 	// Marshal back to YAML with proper formatting
 	output, err := yaml.Marshal(toolDef)
 	if err != nil {
 		return fmt.Errorf("failed to format tool definition: %w", err)
 	}
 
+	resolution, err := checkAddConflict("tool", toolsDir, name, output, onConflict)
+	if err != nil {
+		return err
+	}
+	if resolution.skip {
+		return nil
+	}
+	name, output = resolution.name, resolution.content
+
+	// Save to tools directory
+	destPath := filepath.Join(toolsDir, name+".yaml")
+
 	if err := os.WriteFile(destPath, output, 0644); err != nil {
 		return fmt.Errorf("failed to save tool: %w", err)
 	}
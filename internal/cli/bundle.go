@@ -0,0 +1,186 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+// BundleRunCmd creates the bundle-run command
+func BundleRunCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "bundle-run <run-id>",
+		Short: "Package a run as a sanitized archive to attach to a bug report",
+		Long: `Package a recorded run as a tar.gz archive to attach to a GitHub issue: the
+workflow it ran, its manifest, what each step was given (see 'opun
+inspect'), its text outputs and, for detached runs, its log.
+
+Everything is scrubbed with all of Opun's secret patterns, secret-looking
+variables are hidden and your home directory is replaced by ~. Outputs over
+1MB and binary files are left out. Review the archive before sharing it.
+
+Maintainers can reproduce the run with 'opun replay-bundle'.`,
+		Example: `  opun bundle-run 4242-1730000000000000000
+  opun bundle-run 4242-1730000000000000000 -o bug.tar.gz`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID := args[0]
+			if output == "" {
+				output = fmt.Sprintf("opun-run-%s.tar.gz", runID)
+			}
+
+			// Runs recorded before workflows were kept get the current definition
+			var fallback *wf.Workflow
+			if dir, err := workflow.HistoryDir(); err == nil {
+				if record, err := workflow.LoadRunRecord(dir, runID); err == nil {
+					fallback, _ = loadWorkflow(record.Workflow)
+				}
+			}
+
+			info, err := workflow.CreateRunBundle(output, runID, fallback)
+			if err != nil {
+				return fmt.Errorf("failed to bundle run: %w", err)
+			}
+			printBundleInfo(os.Stdout, output, info)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "archive to write (default opun-run-<run-id>.tar.gz)")
+
+	return cmd
+}
+
+// printBundleInfo prints what went into a run bundle
+func printBundleInfo(out io.Writer, archive string, info *workflow.BundleInfo) {
+	fmt.Fprintf(out, "📦 Bundled run %s of %s: %s\n", info.RunID, info.Workflow, archive)
+	for _, file := range info.Files {
+		fmt.Fprintf(out, "   %s\n", file)
+	}
+	if len(info.Redactions) > 0 {
+		total := 0
+		for _, n := range info.Redactions {
+			total += n
+		}
+		fmt.Fprintf(out, "🔒 Redacted %d secret(s): %s\n", total, secretNames(info.Redactions))
+	}
+	for _, skipped := range info.Skipped {
+		fmt.Fprintf(out, "⚠️  Left out %s\n", skipped)
+	}
+	for _, note := range info.Notes {
+		fmt.Fprintf(out, "⚠️  %s\n", note)
+	}
+	fmt.Fprintln(out, "Review the archive before attaching it to an issue.")
+}
+
+// ReplayBundleCmd creates the replay-bundle command
+func ReplayBundleCmd() *cobra.Command {
+	var (
+		outputDir  string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay-bundle <bundle>",
+		Short: "Reproduce a bundled run on the mock provider",
+		Long: `Replay a run bundle made with 'opun bundle-run' to reproduce an
+orchestration bug without provider accounts. The bundled workflow runs
+headlessly with every agent on the mock provider and the run's variables,
+then each step is compared with how it ended in the recorded run.
+
+Nothing from the bundle runs on your machine: wait conditions, hook
+scripts and artifact checks are skipped, and sandbox, lock, storage and
+matrix settings are ignored.`,
+		Example: `  opun replay-bundle opun-run-4242-1730000000000000000.tar.gz
+  opun replay-bundle bug.tar.gz --output-dir ./replay --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bundle, err := workflow.OpenRunBundle(args[0])
+			if err != nil {
+				return err
+			}
+
+			if outputDir == "" {
+				if outputDir, err = os.MkdirTemp("", "opun-replay-"); err != nil {
+					return err
+				}
+			}
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			if !jsonOutput {
+				fmt.Printf("🔁 Replaying run %s of %s on the mock provider\n", bundle.Info.RunID, bundle.Info.Workflow)
+			}
+			report, err := bundle.Replay(ctx, outputDir)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(report)
+			}
+			printReplayReport(os.Stdout, report)
+			fmt.Printf("📁 Outputs: %s\n", outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "directory for the replay's outputs (default a temporary directory)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+
+	return cmd
+}
+
+// printReplayReport prints each step of a replay next to the recorded run
+func printReplayReport(out io.Writer, report *workflow.ReplayReport) {
+	for _, change := range report.Changes {
+		fmt.Fprintf(out, "   %s\n", change)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tRECORDED\tREPLAYED\tOUTPUT")
+	fmt.Fprintln(w, "-----\t--------\t--------\t------")
+	for _, step := range report.Steps {
+		output := step.Output
+		if output == "" {
+			output = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", step.Agent, step.Recorded, step.Replayed, output)
+	}
+	_ = w.Flush()
+
+	if report.Result.Error != "" {
+		fmt.Fprintf(out, "   Error: %s\n", report.Result.Error)
+	}
+	if n := report.Mismatches(); n > 0 {
+		fmt.Fprintf(out, "⚠️  %d step(s) ended differently than in the recorded run\n", n)
+	} else {
+		fmt.Fprintln(out, "✅ Every step ended as in the recorded run")
+	}
+}
@@ -53,6 +53,7 @@ var listCapabilitiesCmd = &cobra.Command{
 		capType, _ := cmd.Flags().GetString("type")
 		provider, _ := cmd.Flags().GetString("provider")
 
+		defer pageOutput()()
 		return listCapabilities(capType, provider)
 	},
 }
@@ -66,6 +67,7 @@ var searchCapabilitiesCmd = &cobra.Command{
 		query := args[0]
 		capType, _ := cmd.Flags().GetString("type")
 
+		defer pageOutput()()
 		return searchCapabilities(query, capType)
 	},
 }
@@ -176,16 +178,12 @@ func listCapabilities(capType, provider string) error {
 			return fmt.Errorf("failed to create prompt garden: %w", err)
 		}
 
-		prompts, err := garden.List()
-		if err == nil {
-			for _, p := range prompts {
-				metadata := p.Metadata()
-				fmt.Fprintf(w, "Prompt\t%s\t%s\t%s\n",
-					p.Name(),
-					p.Name(),
-					truncate(metadata.Description, 50),
-				)
-			}
+		for _, p := range garden.Summaries() {
+			fmt.Fprintf(w, "Prompt\t%s\t%s\t%s\n",
+				p.Name,
+				p.Name,
+				truncate(p.Description, 50),
+			)
 		}
 	}
 
@@ -326,18 +324,14 @@ func searchCapabilities(query, capType string) error {
 			return fmt.Errorf("failed to create prompt garden: %w", err)
 		}
 
-		prompts, err := garden.List()
-		if err == nil {
-			for _, p := range prompts {
-				metadata := p.Metadata()
-				if matchesQuery(p.Name(), p.Name(), metadata.Description, queryLower) {
-					fmt.Fprintf(w, "Prompt\t%s\t%s\t%s\n",
-						p.Name(),
-						p.Name(),
-						truncate(metadata.Description, 50),
-					)
-					found++
-				}
+		for _, p := range garden.Summaries() {
+			if matchesQuery(p.Name, p.Name, p.Description, queryLower) {
+				fmt.Fprintf(w, "Prompt\t%s\t%s\t%s\n",
+					p.Name,
+					p.Name,
+					truncate(p.Description, 50),
+				)
+				found++
 			}
 		}
 	}
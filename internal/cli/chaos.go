@@ -0,0 +1,60 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/viper"
+)
+
+// loadChaos reads the chaos settings of --chaos, or of OPUN_CHAOS when the
+// flag isn't given, returning nil when chaos is off
+func loadChaos() (*workflow.Chaos, error) {
+	spec, ok := os.LookupEnv(workflow.ChaosEnv)
+	if viper.IsSet("chaos") {
+		spec, ok = viper.GetString("chaos"), true
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	chaos, err := workflow.ParseChaos(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --chaos: %w", err)
+	}
+	fmt.Printf("💥 Chaos mode: %s\n", chaos)
+	return chaos, nil
+}
+
+// printChaosEvents summarizes the failures injected into a run
+func printChaosEvents(out io.Writer, events []workflow.ChaosEvent) {
+	if len(events) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "💥 Injected %d failure(s):\n", len(events))
+	for _, event := range events {
+		line := fmt.Sprintf("   %s: %s", event.Agent, event.Kind)
+		if event.Detail != "" {
+			line += " " + event.Detail
+		}
+		fmt.Fprintln(out, line)
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/creack/pty"
 	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/providers"
 	"github.com/rizome-dev/opun/internal/tools"
 	"github.com/spf13/cobra"
 
@@ -80,22 +81,15 @@ func runChat(cmd *cobra.Command, provider string, providerArgs []string) error {
 		DisplayHealthCheck(provider, services)
 	}
 
-	// Determine which command to run
-	var command string
-	switch provider {
-	case "claude":
-		command = "claude"
-	case "gemini":
-		command = "gemini"
-	case "qwen":
-		command = "qwen"
-	default:
-		return fmt.Errorf("unsupported provider: %s", provider)
+	// Determine which command to run, with any configured binary and wrapper
+	argv, err := providers.ResolveCommand(provider)
+	if err != nil {
+		return err
 	}
 
 	// Create command with prepared environment and provider arguments
-	// #nosec G204 -- command is hardcoded based on provider type
-	c := exec.Command(command, providerArgs...)
+	// #nosec G204 -- command is resolved from the provider type and config
+	c := exec.Command(argv[0], append(argv[1:], config.WithClaudeHooks(provider, providerArgs)...)...)
 	c.Dir = env.WorkingDir
 
 	// Apply environment variables
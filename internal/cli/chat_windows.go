@@ -12,6 +12,7 @@ import (
 
 	"github.com/creack/pty"
 	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/providers"
 	"github.com/rizome-dev/opun/internal/tools"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -61,47 +62,16 @@ func runChat(cmd *cobra.Command, provider string, providerArgs []string) error {
 		DisplayHealthCheck(provider, services)
 	}
 
-	// Determine which command to run
-	var command string
-	var commandArgs []string
-	switch provider {
-	case "claude":
-		// On Windows, we need to handle .exe and .cmd extensions
-		if _, err := exec.LookPath("claude"); err == nil {
-			command = "claude"
-		} else if _, err := exec.LookPath("claude.exe"); err == nil {
-			command = "claude.exe"
-		} else if _, err := exec.LookPath("npx"); err == nil {
-			command = "npx"
-			commandArgs = []string{"claude-code"}
-		} else if _, err := exec.LookPath("npx.cmd"); err == nil {
-			command = "npx.cmd"
-			commandArgs = []string{"claude-code"}
-		} else {
-			return fmt.Errorf("claude command not found, please install Claude CLI")
-		}
-	case "gemini":
-		if _, err := exec.LookPath("gemini"); err == nil {
-			command = "gemini"
-		} else if _, err := exec.LookPath("gemini.exe"); err == nil {
-			command = "gemini.exe"
-		} else {
-			return fmt.Errorf("gemini command not found, please install Gemini CLI")
-		}
-	case "qwen":
-		if _, err := exec.LookPath("qwen"); err == nil {
-			command = "qwen"
-		} else if _, err := exec.LookPath("qwen.exe"); err == nil {
-			command = "qwen.exe"
-		} else {
-			return fmt.Errorf("qwen command not found, please install Qwen Code CLI")
-		}
-	default:
-		return fmt.Errorf("unsupported provider: %s", provider)
+	// Determine which command to run: the configured binary and wrapper,
+	// else the provider's own CLI, with LookPath finding .exe and .cmd
+	argv, err := providers.ResolveCommand(provider)
+	if err != nil {
+		return err
 	}
+	command, commandArgs := argv[0], argv[1:]
 
 	// Append provider arguments to command arguments
-	commandArgs = append(commandArgs, providerArgs...)
+	commandArgs = append(commandArgs, config.WithClaudeHooks(provider, providerArgs)...)
 
 	// Create command with prepared environment and provider arguments
 	// #nosec G204 -- command is hardcoded based on provider type
@@ -0,0 +1,363 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// ciGitHub is the --ci value for GitHub Actions
+const ciGitHub = "github"
+
+// maxSummaryFindings caps the findings listed in a job summary
+const maxSummaryFindings = 50
+
+// maxSummaryOutput caps the final output shown in a job summary, well under
+// GitHub's 1MiB limit per step
+const maxSummaryOutput = 64 * 1024
+
+// githubActions reports a run to GitHub Actions: log groups and error
+// annotations as workflow commands, the run report as the job summary and
+// the run ID, status and artifact paths as step outputs. A nil reporter
+// does nothing.
+type githubActions struct {
+	out io.Writer
+	// file is the workflow file annotations point at, when run from a path
+	file string
+	// summaryPath and outputPath are the files behind GITHUB_STEP_SUMMARY
+	// and GITHUB_OUTPUT, empty outside Actions
+	summaryPath string
+	outputPath  string
+	reported    bool
+}
+
+// newCIReporter returns the reporter for a --ci value, nil when empty
+func newCIReporter(ci, workflowName string) (*githubActions, error) {
+	switch ci {
+	case "":
+		return nil, nil
+	case ciGitHub:
+		g := &githubActions{
+			out:         os.Stdout,
+			summaryPath: os.Getenv("GITHUB_STEP_SUMMARY"),
+			outputPath:  os.Getenv("GITHUB_OUTPUT"),
+		}
+		if info, err := os.Stat(workflowName); err == nil && !info.IsDir() {
+			g.file = filepath.ToSlash(filepath.Clean(workflowName))
+		}
+		return g, nil
+	default:
+		return nil, fmt.Errorf("unknown --ci %q, supported: %s", ci, ciGitHub)
+	}
+}
+
+// group starts a collapsible log group
+func (g *githubActions) group(title string) {
+	if g == nil {
+		return
+	}
+	fmt.Fprintf(g.out, "::group::%s\n", escapeCommandData(title))
+}
+
+// endGroup ends the current log group
+func (g *githubActions) endGroup() {
+	if g == nil {
+		return
+	}
+	fmt.Fprintln(g.out, "::endgroup::")
+}
+
+// error annotates the workflow file, or the job, with an error
+func (g *githubActions) error(title, message string) {
+	if g == nil {
+		return
+	}
+	props := "title=" + escapeCommandProperty(title)
+	if g.file != "" {
+		props = "file=" + escapeCommandProperty(g.file) + "," + props
+	}
+	fmt.Fprintf(g.out, "::error %s::%s\n", props, escapeCommandData(message))
+}
+
+// headlessDone reports a finished headless run
+func (g *githubActions) headlessDone(w *wf.Workflow, runID, outputDir string, result workflow.MatrixResult) {
+	if g == nil {
+		return
+	}
+	g.reported = true
+	if result.Error != "" {
+		g.error("Opun: "+w.Name, result.Error)
+	}
+	g.findings(result.Findings)
+	g.appendSummary(headlessSummary(w, runID, outputDir, result))
+	g.setOutputs(map[string]string{
+		"run_id":        runID,
+		"status":        result.Status,
+		"artifact_path": outputDir,
+		"final_output":  result.Final,
+	})
+}
+
+// matrixDone reports a finished matrix run, annotating every failed combination
+func (g *githubActions) matrixDone(w *wf.Workflow, runID, outputDir string, results []workflow.MatrixResult) {
+	if g == nil {
+		return
+	}
+	g.reported = true
+	status := string(wf.StatusCompleted)
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			g.error(fmt.Sprintf("Opun: %s (%s)", w.Name, result.Name), result.Error)
+		}
+		g.findings(result.Findings)
+	}
+	if failed > 0 || len(results) == 0 {
+		status = string(wf.StatusFailed)
+	}
+
+	icon := "✅"
+	if status != string(wf.StatusCompleted) {
+		icon = "❌"
+	}
+	summary := fmt.Sprintf("## %s Opun: %s\n\nRun `%s`: %d of %d combinations failed. Outputs: `%s`\n\n", icon, w.Name, runID, failed, len(results), outputDir)
+	if data, err := os.ReadFile(filepath.Join(outputDir, "matrix.md")); err == nil {
+		summary += truncateSummary(string(data)) + "\n"
+	}
+	g.appendSummary(summary)
+	g.setOutputs(map[string]string{
+		"run_id":        runID,
+		"status":        status,
+		"artifact_path": outputDir,
+		"final_output":  filepath.Join(outputDir, "matrix.md"),
+	})
+}
+
+// findings annotates the lines review agents reported findings on
+func (g *githubActions) findings(byAgent map[string][]workflow.Finding) {
+	agents := make([]string, 0, len(byAgent))
+	for agent := range byAgent {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	for _, agent := range agents {
+		for _, f := range byAgent[agent] {
+			if f.File == "" {
+				continue
+			}
+			props := "file=" + escapeCommandProperty(f.File)
+			if f.Line > 0 {
+				props += fmt.Sprintf(",line=%d,endLine=%d", f.Line, f.EndLine)
+				if f.Column > 0 {
+					props += fmt.Sprintf(",col=%d", f.Column)
+				}
+			}
+			title := f.Title
+			if title == "" {
+				title = f.Rule
+			}
+			if title == "" {
+				title = agent
+			}
+			props += ",title=" + escapeCommandProperty(title)
+			fmt.Fprintf(g.out, "::%s %s::%s\n", findingCommand(f.Severity), props, escapeCommandData(f.Message))
+		}
+	}
+}
+
+// findingCommand is the workflow command that annotates a finding
+func findingCommand(severity string) string {
+	switch severity {
+	case workflow.SeverityError:
+		return "error"
+	case workflow.SeverityNote:
+		return "notice"
+	default:
+		return "warning"
+	}
+}
+
+// finish reports an error that stopped the run before it could be reported,
+// such as an invalid workflow, and passes it on
+func (g *githubActions) finish(err error) error {
+	if g == nil || g.reported || err == nil {
+		return err
+	}
+	g.reported = true
+	g.error("Opun", err.Error())
+	g.setOutputs(map[string]string{"status": string(wf.StatusFailed)})
+	return err
+}
+
+// appendSummary adds markdown to the job summary
+func (g *githubActions) appendSummary(markdown string) {
+	if g.summaryPath == "" {
+		return
+	}
+	if err := appendFile(g.summaryPath, markdown); err != nil {
+		fmt.Fprintf(g.out, "::warning::%s\n", escapeCommandData("failed to write the job summary: "+err.Error()))
+	}
+}
+
+// setOutputs sets step outputs, using a delimiter for multiline values
+func (g *githubActions) setOutputs(outputs map[string]string) {
+	if g.outputPath == "" {
+		return
+	}
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		value := outputs[name]
+		if !strings.ContainsAny(value, "\r\n") {
+			fmt.Fprintf(&sb, "%s=%s\n", name, value)
+			continue
+		}
+		delimiter := "ghadelimiter_" + randomHex()
+		fmt.Fprintf(&sb, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+	}
+	if err := appendFile(g.outputPath, sb.String()); err != nil {
+		fmt.Fprintf(g.out, "::warning::%s\n", escapeCommandData("failed to set step outputs: "+err.Error()))
+	}
+}
+
+// headlessSummary renders the job summary of a headless run: its status,
+// every step with the usage its provider reported and the final output
+func headlessSummary(w *wf.Workflow, runID, outputDir string, result workflow.MatrixResult) string {
+	var sb strings.Builder
+	icon := "✅"
+	if result.Status != string(wf.StatusCompleted) {
+		icon = "❌"
+	}
+	fmt.Fprintf(&sb, "## %s Opun: %s\n\n", icon, w.Name)
+	fmt.Fprintf(&sb, "Run `%s` %s in %.1fs. Outputs: `%s`\n\n", runID, result.Status, result.Duration, outputDir)
+	if result.Error != "" {
+		fmt.Fprintf(&sb, "> %s\n\n", strings.ReplaceAll(result.Error, "\n", "\n> "))
+	}
+
+	statuses := result.AgentStatuses(w)
+	sb.WriteString("| Step | Provider | Status | Tokens | Cost |\n|---|---|---|---|---|\n")
+	for _, agent := range w.Agents {
+		provider := agent.Provider
+		if agent.Model != "" {
+			provider += " (" + agent.Model + ")"
+		}
+		tokens, cost := "-", "-"
+		if usage, ok := result.Usage[agent.ID]; ok {
+			tokens = fmt.Sprintf("%d in, %d out", usage.InputTokens+usage.CacheCreationInputTokens+usage.CacheReadInputTokens, usage.OutputTokens)
+			if usage.CostUSD > 0 {
+				cost = fmt.Sprintf("$%.4f", usage.CostUSD)
+			}
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", agent.ID, provider, statuses[agent.ID], tokens, cost)
+	}
+
+	var findings []workflow.Finding
+	for _, agent := range w.Agents {
+		findings = append(findings, result.Findings[agent.ID]...)
+	}
+	if len(findings) > 0 {
+		fmt.Fprintf(&sb, "\n### Findings (%d)\n\n| Severity | Location | Finding |\n|---|---|---|\n", len(findings))
+		for i, f := range findings {
+			if i == maxSummaryFindings {
+				fmt.Fprintf(&sb, "\n*…and %d more, see the SARIF files in the outputs*\n", len(findings)-i)
+				break
+			}
+			location := f.File
+			if location != "" && f.Line > 0 {
+				location += fmt.Sprintf(":%d", f.Line)
+			}
+			text := f.Message
+			if f.Title != "" {
+				text = "**" + f.Title + "**: " + text
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", f.Severity, summaryCell(location), summaryCell(text))
+		}
+	}
+
+	if result.Final != "" {
+		if data, err := os.ReadFile(result.Final); err == nil {
+			fmt.Fprintf(&sb, "\n<details><summary>Final output (%s)</summary>\n\n%s\n\n</details>\n", filepath.Base(result.Final), truncateSummary(string(data)))
+		}
+	}
+	return sb.String() + "\n"
+}
+
+// summaryCell makes text fit in a markdown table cell
+func summaryCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// truncateSummary shortens text for the job summary
+func truncateSummary(text string) string {
+	if len(text) <= maxSummaryOutput {
+		return strings.TrimRight(text, "\n")
+	}
+	return strings.ToValidUTF8(text[:maxSummaryOutput], "") + "\n\n*…truncated, see the artifact for the full output*"
+}
+
+// escapeCommandData escapes a workflow command's message
+func escapeCommandData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeCommandProperty escapes a workflow command's property value
+func escapeCommandProperty(s string) string {
+	s = escapeCommandData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+// appendFile appends text to a file, creating it if needed
+func appendFile(path, text string) error {
+	// #nosec G304 -- file named by the Actions runner
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// randomHex returns a random delimiter suffix
+func randomHex() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
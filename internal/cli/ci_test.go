@@ -0,0 +1,147 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGitHubActions returns a reporter writing to temporary Actions files
+func newTestGitHubActions(t *testing.T, workflowName string) (*githubActions, *bytes.Buffer) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(dir, "summary.md"))
+	t.Setenv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+	g, err := newCIReporter(ciGitHub, workflowName)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	g.out = &out
+	return g, &out
+}
+
+func TestNewCIReporter(t *testing.T) {
+	g, err := newCIReporter("", "review")
+	assert.NoError(t, err)
+	assert.Nil(t, g)
+	// A nil reporter does nothing
+	g.group("Run")
+	g.endGroup()
+	assert.EqualError(t, g.finish(errors.New("boom")), "boom")
+
+	_, err = newCIReporter("gitlab", "review")
+	assert.ErrorContains(t, err, `unknown --ci "gitlab"`)
+}
+
+func TestGitHubActionsCommands(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "review.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: review\n"), 0644))
+
+	g, out := newTestGitHubActions(t, file)
+	g.group("Run review")
+	g.endGroup()
+	g.error("Opun: review, fix", "agent fix: 50% done\nthen failed")
+	assert.Equal(t, "::group::Run review\n::endgroup::\n"+
+		"::error file="+escapeCommandProperty(filepath.ToSlash(file))+",title=Opun%3A review%2C fix::agent fix: 50%25 done%0Athen failed\n", out.String())
+
+	// Errors of runs by name annotate the job
+	g, out = newTestGitHubActions(t, "review")
+	assert.EqualError(t, g.finish(errors.New("workflow 'review' not found")), "workflow 'review' not found")
+	assert.Equal(t, "::error title=Opun::workflow 'review' not found\n", out.String())
+	outputs, err := os.ReadFile(g.outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "status=failed\n", string(outputs))
+}
+
+func TestGitHubActionsHeadlessDone(t *testing.T) {
+	dir := t.TempDir()
+	final := filepath.Join(dir, "fix.md")
+	require.NoError(t, os.WriteFile(final, []byte("Fixed the build\n"), 0644))
+
+	w := &wf.Workflow{Name: "review", Agents: []wf.Agent{
+		{ID: "review", Provider: "claude", Model: "sonnet"},
+		{ID: "fix", Provider: "gemini"},
+		{ID: "report", Provider: "claude"},
+	}}
+	result := workflow.MatrixResult{
+		Status:   string(wf.StatusFailed),
+		Duration: 12.5,
+		Outputs:  map[string]string{"review": filepath.Join(dir, "review.md"), "fix": final},
+		Final:    final,
+		Error:    "agent report: exit status 1",
+		Usage:    map[string]providers.Usage{"review": {InputTokens: 1200, OutputTokens: 300, CostUSD: 0.0125}},
+	}
+
+	g, out := newTestGitHubActions(t, "review")
+	g.headlessDone(w, "run-1", dir, result)
+	assert.NoError(t, g.finish(nil))
+	assert.Equal(t, "::error title=Opun%3A review::agent report: exit status 1\n", out.String())
+
+	summary, err := os.ReadFile(g.summaryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "## ❌ Opun: review")
+	assert.Contains(t, string(summary), "Run `run-1` failed in 12.5s")
+	assert.Contains(t, string(summary), "| review | claude (sonnet) | completed | 1200 in, 300 out | $0.0125 |")
+	assert.Contains(t, string(summary), "| report | claude | failed | - | - |")
+	assert.Contains(t, string(summary), "<details><summary>Final output (fix.md)</summary>\n\nFixed the build\n\n</details>")
+
+	outputs, err := os.ReadFile(g.outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "artifact_path="+dir+"\nfinal_output="+final+"\nrun_id=run-1\nstatus=failed\n", string(outputs))
+}
+
+func TestGitHubActionsOutputs(t *testing.T) {
+	g, _ := newTestGitHubActions(t, "review")
+	g.setOutputs(map[string]string{"summary": "line one\nline two"})
+	outputs, err := os.ReadFile(g.outputPath)
+	require.NoError(t, err)
+	assert.Regexp(t, `^summary<<(ghadelimiter_[0-9a-f]{16})\nline one\nline two\n(ghadelimiter_[0-9a-f]{16})\n$`, string(outputs))
+}
+
+func TestGitHubActionsFindings(t *testing.T) {
+	w := &wf.Workflow{Name: "review", Agents: []wf.Agent{{ID: "review", Provider: "claude"}}}
+	result := workflow.MatrixResult{
+		Status:  string(wf.StatusCompleted),
+		Outputs: map[string]string{},
+		Findings: map[string][]workflow.Finding{"review": {
+			{File: "internal/api.go", Line: 12, EndLine: 14, Severity: workflow.SeverityError, Title: "Nil dereference", Message: "resp can be nil | here"},
+			{File: "README.md", Severity: workflow.SeverityNote, Rule: "typo", Message: "Typo"},
+			{Severity: workflow.SeverityWarning, Message: "No tests for the new endpoint"},
+		}},
+	}
+
+	g, out := newTestGitHubActions(t, "review")
+	g.headlessDone(w, "run-1", t.TempDir(), result)
+	assert.Equal(t, "::error file=internal/api.go,line=12,endLine=14,title=Nil dereference::resp can be nil | here\n"+
+		"::notice file=README.md,title=typo::Typo\n", out.String())
+
+	summary, err := os.ReadFile(g.summaryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "### Findings (3)")
+	assert.Contains(t, string(summary), "| error | internal/api.go:12 | **Nil dereference**: resp can be nil \\| here |")
+	assert.Contains(t, string(summary), "| warning |  | No tests for the new endpoint |")
+}
@@ -0,0 +1,156 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// CompareCmd creates the compare command
+func CompareCmd() *cobra.Command {
+	var (
+		jsonOutput       bool
+		showUnchanged    bool
+		failOnRegression bool
+		showEnv          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compare <run-a> <run-b>",
+		Short: "Compare the outputs of two workflow runs",
+		Long: `Compare the output directories of two runs of the same workflow.
+Text files are shown as line diffs, JSON files as structural changes and JUnit
+XML reports as test result deltas. Differences in the runs' manifests, such as
+provider versions or models, are listed first. --env also lists differences
+in the environments the runs happened in: OS, kernel, shell, terminal type and
+size, locale and provider-related environment variables, to tell why a
+prompt behaves differently on another machine.
+
+Regressions are flagged: artifacts missing from the second run, tests that now
+fail and JSON statuses that went from success to failure.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			comparison, err := workflow.CompareRuns(args[0], args[1])
+			if err != nil {
+				return fmt.Errorf("failed to compare runs: %w", err)
+			}
+
+			defer pageOutput()()
+			if jsonOutput {
+				data, err := json.MarshalIndent(comparison, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			} else {
+				printComparison(comparison, showUnchanged, showEnv)
+			}
+
+			if failOnRegression && comparison.HasRegressions() {
+				return fmt.Errorf("%d regressions found", len(comparison.Regressions))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output the comparison as JSON")
+	cmd.Flags().BoolVar(&showUnchanged, "all", false, "also list unchanged files")
+	cmd.Flags().BoolVar(&showEnv, "env", false, "list differences in the runs' environments")
+	cmd.Flags().BoolVar(&failOnRegression, "fail-on-regression", false, "exit with an error when regressions are found")
+
+	return cmd
+}
+
+// printComparison prints a run comparison for the terminal
+func printComparison(c *workflow.RunComparison, showUnchanged, showEnv bool) {
+	fmt.Printf("🔍 Comparing %s → %s\n", c.RunA, c.RunB)
+
+	if len(c.Changes) > 0 {
+		fmt.Println("\nRun changes:")
+		for _, change := range c.Changes {
+			fmt.Printf("  • %s\n", change)
+		}
+	}
+	if showEnv {
+		if len(c.Environment) > 0 {
+			fmt.Println("\nEnvironment changes:")
+			for _, change := range c.Environment {
+				fmt.Printf("  • %s\n", change)
+			}
+		} else {
+			fmt.Println("\nNo environment changes")
+		}
+	} else if len(c.Environment) > 0 {
+		fmt.Printf("\n%d environment differences (use --env to list them)\n", len(c.Environment))
+	}
+
+	icons := map[string]string{
+		workflow.CompareAdded:     "➕",
+		workflow.CompareRemoved:   "➖",
+		workflow.CompareChanged:   "✏️ ",
+		workflow.CompareUnchanged: "  ",
+	}
+
+	unchanged := 0
+	fmt.Println()
+	for _, file := range c.Files {
+		if file.Status == workflow.CompareUnchanged {
+			unchanged++
+			if !showUnchanged {
+				continue
+			}
+		}
+		fmt.Printf("%s %s (%s)\n", icons[file.Status], file.Path, file.Status)
+
+		switch {
+		case file.Tests != nil:
+			t := file.Tests
+			fmt.Printf("    tests: %d passed, %d failed → %d passed, %d failed\n", t.PassedA, t.FailedA, t.PassedB, t.FailedB)
+			for _, name := range t.NewlyFailing {
+				fmt.Printf("    ❌ %s\n", name)
+			}
+			for _, name := range t.NewlyPassing {
+				fmt.Printf("    ✅ %s\n", name)
+			}
+		case len(file.JSONChanges) > 0:
+			for _, change := range file.JSONChanges {
+				fmt.Printf("    %s\n", change)
+			}
+		case file.Diff != "":
+			for _, line := range strings.Split(strings.TrimSuffix(file.Diff, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	if unchanged > 0 && !showUnchanged {
+		fmt.Printf("\n%d unchanged files (use --all to list them)\n", unchanged)
+	}
+
+	if c.HasRegressions() {
+		fmt.Printf("\n⚠️  %d regressions:\n", len(c.Regressions))
+		for _, regression := range c.Regressions {
+			fmt.Printf("  • %s\n", regression)
+		}
+	} else {
+		fmt.Println("\n✅ No regressions")
+	}
+}
@@ -0,0 +1,303 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict policies for adding an item whose name is already taken
+const (
+	conflictAsk       = "ask"
+	conflictOverwrite = "overwrite"
+	conflictRename    = "rename"
+	conflictSkip      = "skip"
+	conflictMerge     = "merge"
+	conflictFail      = "fail"
+)
+
+// conflictPolicies are the values accepted by --on-conflict
+var conflictPolicies = []string{conflictAsk, conflictOverwrite, conflictRename, conflictSkip, conflictMerge, conflictFail}
+
+// addConflict is an item being added under a name that already exists
+type addConflict struct {
+	kind      string // workflow, prompt, action or tool
+	name      string
+	existing  []byte
+	incoming  []byte
+	mergeable bool                   // YAML items can be merged
+	exists    func(name string) bool // Whether a name is taken, for renames; nil can't be renamed
+}
+
+// addResolution is what to save after resolving a conflict
+type addResolution struct {
+	name    string
+	content []byte
+	skip    bool
+}
+
+// conflictPolicy validates an --on-conflict value, defaulting to asking in a
+// terminal and failing otherwise
+func conflictPolicy(policy string) (string, error) {
+	if policy == "" {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			return conflictAsk, nil
+		}
+		return conflictFail, nil
+	}
+	for _, p := range conflictPolicies {
+		if policy == p {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --on-conflict %q (use %s)", policy, strings.Join(conflictPolicies, ", "))
+}
+
+// resolveAddConflict decides what to save when an item already exists. In ask
+// mode it shows a diff and reads the choice from in.
+func resolveAddConflict(c addConflict, policy string, in *bufio.Reader, out io.Writer) (addResolution, error) {
+	if bytes.Equal(bytes.TrimSpace(c.existing), bytes.TrimSpace(c.incoming)) {
+		fmt.Fprintf(out, "✓ %s '%s' is already up to date\n", capitalize(c.kind), c.name)
+		return addResolution{skip: true}, nil
+	}
+
+	if policy == conflictAsk {
+		fmt.Fprintf(out, "⚠️  %s '%s' already exists. Changes from the existing version:\n\n", capitalize(c.kind), c.name)
+		fmt.Fprintln(out, workflow.UnifiedDiff(string(c.existing), string(c.incoming)))
+
+		choices := "[o]verwrite, [s]kip"
+		if c.exists != nil {
+			choices = "[o]verwrite, [r]ename, [s]kip"
+		}
+		if c.mergeable {
+			choices += ", [m]erge"
+		}
+		fmt.Fprintf(out, "%s or [q]uit? ", choices)
+		answer, err := in.ReadString('\n')
+		if err != nil && answer == "" {
+			return addResolution{}, fmt.Errorf("add aborted: %w", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "o", "overwrite":
+			policy = conflictOverwrite
+		case "r", "rename":
+			if c.exists == nil {
+				return addResolution{}, fmt.Errorf("%ss can't be renamed here", c.kind)
+			}
+			suggestion := nextFreeName(c.name, c.exists)
+			fmt.Fprintf(out, "New name [%s]: ", suggestion)
+			newName, _ := in.ReadString('\n')
+			if newName = strings.TrimSpace(newName); newName == "" {
+				newName = suggestion
+			}
+			if c.exists(newName) {
+				return addResolution{}, fmt.Errorf("%s '%s' already exists", c.kind, newName)
+			}
+			return addResolution{name: newName, content: c.incoming}, nil
+		case "s", "skip":
+			policy = conflictSkip
+		case "m", "merge":
+			policy = conflictMerge
+		default:
+			return addResolution{}, fmt.Errorf("add aborted")
+		}
+	}
+
+	switch policy {
+	case conflictOverwrite:
+		return addResolution{name: c.name, content: c.incoming}, nil
+	case conflictRename:
+		if c.exists == nil {
+			return addResolution{}, fmt.Errorf("%ss can't be renamed here; use overwrite, skip or merge", c.kind)
+		}
+		newName := nextFreeName(c.name, c.exists)
+		fmt.Fprintf(out, "ℹ️  %s '%s' exists, adding as '%s'\n", capitalize(c.kind), c.name, newName)
+		return addResolution{name: newName, content: c.incoming}, nil
+	case conflictSkip:
+		fmt.Fprintf(out, "⏭️  Skipped %s '%s', it already exists\n", c.kind, c.name)
+		return addResolution{skip: true}, nil
+	case conflictMerge:
+		if !c.mergeable {
+			return addResolution{}, fmt.Errorf("%ss can't be merged; use overwrite, rename or skip", c.kind)
+		}
+		merged, err := mergeYAML(c.existing, c.incoming)
+		if err != nil {
+			return addResolution{}, fmt.Errorf("failed to merge %s '%s': %w", c.kind, c.name, err)
+		}
+		return addResolution{name: c.name, content: merged}, nil
+	default:
+		return addResolution{}, fmt.Errorf("%s '%s' already exists (use --on-conflict=overwrite, rename, skip or merge)", c.kind, c.name)
+	}
+}
+
+// nextFreeName returns name-2, name-3, ... whichever is free first
+func nextFreeName(name string, exists func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// fileInDir returns an exists check for <dir>/<name>.yaml
+func fileInDir(dir string) func(string) bool {
+	return func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name+".yaml"))
+		return err == nil
+	}
+}
+
+// checkAddConflict resolves a conflict with an existing <dir>/<name>.yaml.
+// With no existing file the incoming content is used as is.
+func checkAddConflict(kind, dir, name string, incoming []byte, policy string) (addResolution, error) {
+	existing, err := os.ReadFile(filepath.Join(dir, name+".yaml"))
+	if err != nil {
+		return addResolution{name: name, content: incoming}, nil
+	}
+	return resolveAddConflict(addConflict{
+		kind:      kind,
+		name:      name,
+		existing:  existing,
+		incoming:  incoming,
+		mergeable: true,
+		exists:    fileInDir(dir),
+	}, policy, bufio.NewReader(os.Stdin), os.Stdout)
+}
+
+// mergeYAML merges incoming YAML into existing YAML, keeping the existing
+// key order and comments. Incoming values win; lists of mappings are merged
+// by their id or name and other lists are replaced.
+func mergeYAML(existing, incoming []byte) ([]byte, error) {
+	var dst, src yaml.Node
+	if err := yaml.Unmarshal(existing, &dst); err != nil {
+		return nil, fmt.Errorf("existing: %w", err)
+	}
+	if err := yaml.Unmarshal(incoming, &src); err != nil {
+		return nil, fmt.Errorf("incoming: %w", err)
+	}
+	if len(dst.Content) == 0 {
+		return incoming, nil
+	}
+	if len(src.Content) == 0 {
+		return existing, nil
+	}
+
+	merged := mergeNodes(dst.Content[0], src.Content[0])
+	dst.Content[0] = merged
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&dst); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNodes merges src into dst and returns the result
+func mergeNodes(dst, src *yaml.Node) *yaml.Node {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			if j := mappingIndex(dst, key.Value); j >= 0 {
+				dst.Content[j+1] = mergeNodes(dst.Content[j+1], value)
+			} else {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+		return dst
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && keyedSequence(dst) && keyedSequence(src):
+		for _, item := range src.Content {
+			key := sequenceKey(item)
+			matched := false
+			for k, existing := range dst.Content {
+				if sequenceKey(existing) == key {
+					dst.Content[k] = mergeNodes(existing, item)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				dst.Content = append(dst.Content, item)
+			}
+		}
+		return dst
+
+	default:
+		return src
+	}
+}
+
+// mappingIndex returns the index of a key in a mapping node, or -1
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// keyedSequence reports whether every item of a sequence is a mapping with an id or name
+func keyedSequence(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if sequenceKey(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sequenceKey returns the id or name of a mapping in a list
+func sequenceKey(node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for _, key := range []string{"id", "name"} {
+		if i := mappingIndex(node, key); i >= 0 {
+			return key + "=" + node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
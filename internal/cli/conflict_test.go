@@ -0,0 +1,176 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConflict(existing, incoming string) addConflict {
+	taken := map[string]bool{"review": true, "review-2": true}
+	return addConflict{
+		kind:      "workflow",
+		name:      "review",
+		existing:  []byte(existing),
+		incoming:  []byte(incoming),
+		mergeable: true,
+		exists:    func(name string) bool { return taken[name] },
+	}
+}
+
+func resolveWith(t *testing.T, c addConflict, policy, input string) (addResolution, string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	resolution, err := resolveAddConflict(c, policy, bufio.NewReader(strings.NewReader(input)), &out)
+	return resolution, out.String(), err
+}
+
+func TestConflictPolicy(t *testing.T) {
+	policy, err := conflictPolicy("merge")
+	require.NoError(t, err)
+	assert.Equal(t, conflictMerge, policy)
+
+	_, err = conflictPolicy("clobber")
+	assert.Error(t, err)
+}
+
+func TestResolveAddConflictPolicies(t *testing.T) {
+	c := testConflict("name: review\n", "name: review\ndescription: new\n")
+
+	resolution, _, err := resolveWith(t, c, conflictOverwrite, "")
+	require.NoError(t, err)
+	assert.Equal(t, addResolution{name: "review", content: c.incoming}, resolution)
+
+	resolution, _, err = resolveWith(t, c, conflictRename, "")
+	require.NoError(t, err)
+	assert.Equal(t, "review-3", resolution.name)
+
+	resolution, _, err = resolveWith(t, c, conflictSkip, "")
+	require.NoError(t, err)
+	assert.True(t, resolution.skip)
+
+	_, _, err = resolveWith(t, c, conflictFail, "")
+	assert.EqualError(t, err, "workflow 'review' already exists (use --on-conflict=overwrite, rename, skip or merge)")
+
+	c.mergeable = false
+	_, _, err = resolveWith(t, c, conflictMerge, "")
+	assert.Error(t, err)
+
+	resolution, out, err := resolveWith(t, testConflict("same\n", "same"), conflictFail, "")
+	require.NoError(t, err)
+	assert.True(t, resolution.skip, "identical content is not a conflict")
+	assert.Contains(t, out, "already up to date")
+}
+
+func TestResolveAddConflictAsk(t *testing.T) {
+	c := testConflict("name: review\n", "name: review\ndescription: new\n")
+
+	resolution, out, err := resolveWith(t, c, conflictAsk, "o\n")
+	require.NoError(t, err)
+	assert.Equal(t, "review", resolution.name)
+	assert.Contains(t, out, "+description: new")
+	assert.Contains(t, out, "[m]erge")
+
+	resolution, _, err = resolveWith(t, c, conflictAsk, "r\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "review-3", resolution.name, "suggested name")
+
+	resolution, _, err = resolveWith(t, c, conflictAsk, "r\nreview-final\n")
+	require.NoError(t, err)
+	assert.Equal(t, "review-final", resolution.name)
+
+	_, _, err = resolveWith(t, c, conflictAsk, "r\nreview-2\n")
+	assert.Error(t, err, "chosen name is taken")
+
+	_, _, err = resolveWith(t, c, conflictAsk, "q\n")
+	assert.Error(t, err)
+
+	// Items synced with a remote keep their name
+	c.exists = nil
+	_, out, err = resolveWith(t, c, conflictAsk, "r\n")
+	assert.Error(t, err)
+	assert.NotContains(t, out, "[r]ename")
+	_, _, err = resolveWith(t, c, conflictRename, "")
+	assert.Error(t, err)
+}
+
+func TestMergeYAML(t *testing.T) {
+	existing := `name: review
+# Keep this comment
+description: old
+agents:
+  - id: analyze
+    provider: claude
+    prompt: Analyze
+  - id: report
+    provider: claude
+    prompt: Report
+tags: [a, b]
+`
+	incoming := `name: review
+description: new
+agents:
+  - id: report
+    provider: gemini
+  - id: publish
+    provider: claude
+    prompt: Publish
+tags: [c]
+`
+	merged, err := mergeYAML([]byte(existing), []byte(incoming))
+	require.NoError(t, err)
+
+	assert.Equal(t, `name: review
+# Keep this comment
+description: new
+agents:
+  - id: analyze
+    provider: claude
+    prompt: Analyze
+  - id: report
+    provider: gemini
+    prompt: Report
+  - id: publish
+    provider: claude
+    prompt: Publish
+tags: [c]
+`, string(merged))
+
+	_, err = mergeYAML([]byte("a: ["), []byte("a: b"))
+	assert.Error(t, err)
+}
+
+func TestCheckAddConflict(t *testing.T) {
+	dir := t.TempDir()
+
+	resolution, err := checkAddConflict("action", dir, "lint", []byte("id: lint\n"), conflictFail)
+	require.NoError(t, err)
+	assert.Equal(t, "lint", resolution.name, "no existing file")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lint.yaml"), []byte("id: lint\n"), 0644))
+	resolution, err = checkAddConflict("action", dir, "lint", []byte("id: lint\ncommand: make lint\n"), conflictRename)
+	require.NoError(t, err)
+	assert.Equal(t, "lint-2", resolution.name)
+}
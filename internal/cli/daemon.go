@@ -0,0 +1,260 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/daemon"
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/rizome-dev/opun/internal/mcp"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DaemonCmd creates the daemon command
+func DaemonCmd() *cobra.Command {
+	var (
+		api         bool
+		addr        string
+		noToken     bool
+		metricsAddr string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run a long-lived Opun service for editors",
+		Long: `Run Opun as a long-lived local service so editor extensions can list and
+edit prompts, workflows, actions and subagents, validate definitions as they
+are typed and start, follow and cancel workflow runs without starting a new
+opun process for every request.
+
+With --api the service speaks JSON-RPC 2.0 over HTTP: POST requests to /rpc
+with one of these methods:
+
+  items/list    {kind?}                   list items, optionally of one kind
+  items/get     {kind, name}              get an item with its content
+  items/save    {kind, name, content}     validate and save an item
+  items/delete  {kind, name}              delete an item
+  validate      {kind, content}           return LSP style diagnostics
+  runs/start    {workflow, variables?}    start a workflow run
+  runs/get      {id}                      get a run with its events
+  runs/list                               list runs
+  runs/cancel   {id}                      cancel a run
+
+Kinds are workflow, prompt, action and subagent. The address and a bearer
+token for the Authorization header are written to ~/.opun/daemon.json;
+set OPUN_API_TOKEN to use a fixed token. The same service is served as a REST
+API under /api/v1, see 'opun serve --help'. Use 'opun lsp' to serve the same
+methods over stdio.
+
+With --metrics-addr, Prometheus metrics are served at /metrics on a separate
+address that needs no token: runs started, finished by status and in progress,
+agents by provider with their durations, and the tool calls counted by every
+Opun MCP server on this machine.`,
+		Example: `  opun daemon --api
+  opun daemon --api --addr 127.0.0.1:0
+  opun daemon --api --metrics-addr 127.0.0.1:9464`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !api {
+				return fmt.Errorf("nothing to serve: pass --api")
+			}
+
+			return runDaemonServer(addr, noToken, metricsAddr, "/rpc")
+		},
+	}
+
+	cmd.Flags().BoolVar(&api, "api", false, "serve the JSON-RPC API over HTTP")
+	cmd.Flags().StringVar(&addr, "addr", daemon.DefaultAddress, "address to listen on")
+	cmd.Flags().BoolVar(&noToken, "no-token", false, "accept requests without a bearer token")
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
+
+	return cmd
+}
+
+// runDaemonServer serves the daemon API over HTTP until Opun shuts down,
+// announcing the endpoint at path
+func runDaemonServer(addr string, noToken bool, metricsAddr, path string) error {
+	if err := features.Require(features.Daemon, "the daemon"); err != nil {
+		return err
+	}
+
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			fmt.Printf("⚠️  Warning: %s is reachable from other machines\n", addr)
+		}
+	}
+
+	service, opunDir, err := newDaemonService()
+	if err != nil {
+		return err
+	}
+
+	token := ""
+	if !noToken {
+		// A fixed token lets dashboards keep working across restarts
+		if token = os.Getenv("OPUN_API_TOKEN"); token == "" {
+			if token, err = daemon.NewToken(); err != nil {
+				return fmt.Errorf("failed to create API token: %w", err)
+			}
+		}
+	}
+
+	server := daemon.NewHTTPServer(service, token)
+	listening, err := server.Start(addr)
+	if err != nil {
+		return err
+	}
+
+	infoPath := filepath.Join(opunDir, daemon.InfoFile)
+	if err := daemon.WriteInfo(infoPath, daemon.Info{
+		Address:   listening,
+		Token:     token,
+		PID:       os.Getpid(),
+		StartTime: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write daemon info: %w", err)
+	}
+
+	// Stop serving and withdraw the connection details on shutdown
+	stopped := make(chan struct{})
+	unregister := utils.RegisterShutdown("daemon", utils.PhaseServers, 5*time.Second, func(ctx context.Context) error {
+		defer close(stopped)
+		fmt.Println("\nShutting down daemon...")
+		os.Remove(infoPath)
+		return server.Stop(ctx)
+	})
+
+	fmt.Printf("🚀 Opun daemon listening on http://%s%s\n", listening, path)
+	fmt.Printf("   Connection details: %s\n", infoPath)
+	dashboard := "http://" + listening + "/"
+	if token != "" {
+		// The fragment never leaves the browser, the page keeps the token
+		dashboard += "#token=" + url.QueryEscape(token)
+	}
+	fmt.Printf("🖥️  Dashboard: %s\n", dashboard)
+
+	if metricsAddr != "" {
+		metrics := daemon.NewMetricsServer(func(w io.Writer) {
+			writeDaemonMetrics(w, service)
+		})
+		metricsListening, err := metrics.Start(metricsAddr)
+		if err != nil {
+			unregister()
+			os.Remove(infoPath)
+			server.Stop(context.Background())
+			return err
+		}
+		utils.RegisterShutdown("metrics server", utils.PhaseServers, 0, metrics.Stop)
+		fmt.Printf("📈 Metrics at http://%s/metrics\n", metricsListening)
+	}
+
+	// Runs until opun's signal handler shuts the daemon down
+	<-stopped
+	return nil
+}
+
+// LSPCmd creates the lsp command
+func LSPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "Run the Opun language server on stdio",
+		Long: `Run a Language Server Protocol server on stdin and stdout for editor
+extensions. Workflow, action, subagent and prompt definitions are validated
+as they are edited, both under ~/.opun and for workflow YAML anywhere else.
+
+The methods listed in 'opun daemon --help' are served on the same connection,
+and workflow run events are sent as opun/runEvent notifications.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The language server serves the daemon's methods
+			if err := features.Require(features.Daemon, "the language server"); err != nil {
+				return err
+			}
+			service, _, err := newDaemonService()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			defer utils.RegisterShutdown("language server", utils.PhaseServers, 0, func(context.Context) error {
+				cancel()
+				return nil
+			})()
+
+			// Nothing else may write to stdout: it carries the protocol
+			return daemon.NewLSPServer(service, os.Stdin, os.Stdout).Run(ctx)
+		},
+	}
+}
+
+// writeDaemonMetrics writes the daemon's run metrics and the tool call
+// counters persisted by MCP servers
+func writeDaemonMetrics(w io.Writer, service *daemon.Service) {
+	if runs := service.Runs(); runs != nil {
+		runs.WritePrometheus(w)
+	}
+	if statsDir, err := mcp.StatsDir(); err == nil {
+		if stats, err := mcp.LoadToolStats(statsDir); err == nil {
+			mcp.WritePrometheus(w, stats)
+		}
+	}
+}
+
+// newDaemonService creates the service behind opun daemon and opun lsp
+func newDaemonService() (*daemon.Service, string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, "", err
+	}
+	opunDir := filepath.Join(home, ".opun")
+
+	garden, err := promptgarden.NewGarden(filepath.Join(opunDir, "promptgarden"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize prompt garden: %w", err)
+	}
+
+	workflowMgr, err := workflow.NewManager(filepath.Join(opunDir, "workflows"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize workflow manager: %w", err)
+	}
+	workflowMgr.SetRequirementsEnvironment(loadRequirementsEnvironment)
+	workflowMgr.SetPromptResolver(workflow.PromptResolver(gardenResolver(garden)))
+	workflowMgr.SetProviderSelection(loadProviderSelection())
+	policy, err := loadPromptPolicy()
+	if err != nil {
+		return nil, "", err
+	}
+	workflowMgr.SetPromptPolicy(policy)
+	// Agent output feeds the run logs of the dashboard and event streams
+	workflowMgr.SetOutputEvents(true)
+	if viper.IsSet("max_concurrent_workflows") {
+		workflowMgr.SetMaxConcurrent(viper.GetInt("max_concurrent_workflows"))
+	}
+
+	return daemon.NewService(opunDir, garden, workflowMgr), opunDir, nil
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/config"
 	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,6 +40,7 @@ func DeleteCmd() *cobra.Command {
 		isAction   bool
 		name       string
 		force      bool
+		cascade    bool
 	)
 
 	cmd := &cobra.Command{
@@ -54,6 +57,9 @@ Examples:
   
   # Delete an action
   opun delete action my-action
+
+  # Delete a prompt together with the workflows and actions that use it
+  opun delete prompt my-prompt --cascade
   
   # Interactive mode
   opun delete`,
@@ -93,15 +99,15 @@ Examples:
 
 			// Determine what to delete based on flags
 			if isWorkflow {
-				return deleteWorkflow(name, force)
+				return deleteWorkflow(name, force, cascade)
 			}
 
 			if isPrompt {
-				return deletePrompt(name, force)
+				return deletePrompt(name, force, cascade)
 			}
 
 			if isAction {
-				return deleteAction(name, force)
+				return deleteAction(name, force, cascade)
 			}
 
 			return fmt.Errorf("specify either workflow, prompt, or action")
@@ -113,7 +119,8 @@ Examples:
 	cmd.Flags().BoolVar(&isPrompt, "prompt", false, "Delete a prompt")
 	cmd.Flags().BoolVar(&isAction, "action", false, "Delete an action")
 	cmd.Flags().StringVar(&name, "name", "", "Name of the item to delete")
-	cmd.Flags().BoolVar(&force, "force", false, "Force deletion without confirmation")
+	cmd.Flags().BoolVar(&force, "force", false, "Force deletion without confirmation, even if other items reference it")
+	cmd.Flags().BoolVar(&cascade, "cascade", false, "Also delete the workflows, prompts and actions that reference the item")
 
 	// Only one type can be used at a time
 	cmd.MarkFlagsMutuallyExclusive("workflow", "prompt", "action")
@@ -122,7 +129,7 @@ Examples:
 }
 
 // deleteWorkflow deletes a workflow from the system
-func deleteWorkflow(name string, force bool) error {
+func deleteWorkflow(name string, force, cascade bool) error {
 	// Get workflow directory
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -137,6 +144,12 @@ func deleteWorkflow(name string, force bool) error {
 		return fmt.Errorf("workflow '%s' not found", name)
 	}
 
+	// Refuse to break the items that still reference it
+	dependents, err := checkDependents("workflow", name, force, cascade)
+	if err != nil {
+		return err
+	}
+
 	// Confirm deletion if not forced
 	if !force {
 		confirm, err := Confirm(fmt.Sprintf("Are you sure you want to delete workflow '%s'?", name))
@@ -178,12 +191,14 @@ func deleteWorkflow(name string, force bool) error {
 	}
 
 	fmt.Printf("✓ Deleted workflow '%s'\n", name)
+	cleanupGeneratedArtifacts(config.ArtifactWorkflow, name)
+	deleteDependents(dependents)
 
 	return nil
 }
 
 // deletePrompt deletes a prompt from the prompt garden
-func deletePrompt(name string, force bool) error {
+func deletePrompt(name string, force, cascade bool) error {
 	// Get prompt garden
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -202,6 +217,12 @@ func deletePrompt(name string, force bool) error {
 		return fmt.Errorf("prompt '%s' not found", name)
 	}
 
+	// Refuse to break the items that still reference it
+	dependents, err := checkDependents("prompt", name, force, cascade)
+	if err != nil {
+		return err
+	}
+
 	// Confirm deletion if not forced
 	if !force {
 		confirm, err := Confirm(fmt.Sprintf("Are you sure you want to delete prompt '%s'?", name))
@@ -220,12 +241,14 @@ func deletePrompt(name string, force bool) error {
 	}
 
 	fmt.Printf("✓ Deleted prompt '%s'\n", name)
+	cleanupGeneratedArtifacts(config.ArtifactPrompt, name)
+	deleteDependents(dependents)
 
 	return nil
 }
 
 // deleteAction deletes an action from the system
-func deleteAction(name string, force bool) error {
+func deleteAction(name string, force, cascade bool) error {
 	// Get actions directory
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -244,6 +267,12 @@ func deleteAction(name string, force bool) error {
 		}
 	}
 
+	// Refuse to break the items that still reference it
+	dependents, err := checkDependents("action", name, force, cascade)
+	if err != nil {
+		return err
+	}
+
 	// Confirm deletion if not forced
 	if !force {
 		confirm, err := Confirm(fmt.Sprintf("Are you sure you want to delete action '%s'?", name))
@@ -265,10 +294,38 @@ func deleteAction(name string, force bool) error {
 	}
 
 	fmt.Printf("✓ Deleted action '%s'\n", name)
+	cleanupGeneratedArtifacts(config.ArtifactAction, name)
+	deleteDependents(dependents)
 
 	return nil
 }
 
+// cleanupGeneratedArtifacts removes the slash commands and provider command
+// files generated for a deleted item. Failures only warn, the item is already gone.
+func cleanupGeneratedArtifacts(kind, name string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("⚠️  Warning: Failed to clean up generated files: %v\n", err)
+		return
+	}
+
+	report, err := config.RemoveGeneratedArtifacts(kind, name, cwd)
+	if report != nil {
+		for _, command := range report.SlashCommands {
+			fmt.Printf("  Removed slash command /%s\n", command)
+		}
+		for _, file := range report.Files {
+			if rel, relErr := filepath.Rel(cwd, file); relErr == nil {
+				file = rel
+			}
+			fmt.Printf("  Removed %s\n", file)
+		}
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Warning: Failed to clean up generated files: %v\n", err)
+	}
+}
+
 // addType constants for compatibility with interactive selection
 type addType int
 
@@ -527,11 +584,11 @@ func runInteractiveDelete() error {
 
 	switch typeChoice {
 	case "workflow":
-		return deleteWorkflow(selectedItem.name, false)
+		return deleteWorkflow(selectedItem.name, false, false)
 	case "prompt":
-		return deletePrompt(selectedItem.name, false)
+		return deletePrompt(selectedItem.name, false, false)
 	case "action":
-		return deleteAction(selectedItem.name, false)
+		return deleteAction(selectedItem.name, false, false)
 	default:
 		return fmt.Errorf("unknown type: %s", typeChoice)
 	}
@@ -574,10 +631,26 @@ func runMultiDelete(items []deleteItem, itemType string) error {
 		itemType: itemType,
 	}
 
-	p := tea.NewProgram(model)
-	result, err := p.Run()
-	if err != nil {
-		return err
+	var result tea.Model = model
+	if utils.PlainUI() {
+		plainItems := make([]list.Item, len(items))
+		for i, item := range items {
+			plainItems[i] = item
+		}
+		picked, err := plainSelectMany(l.Title, plainItems, nil)
+		if err != nil {
+			return err
+		}
+		for _, i := range picked {
+			model.selected[items[i].name] = true
+		}
+	} else {
+		p := tea.NewProgram(model)
+		var err error
+		result, err = p.Run()
+		if err != nil {
+			return err
+		}
 	}
 
 	if m, ok := result.(multiDeleteModel); ok {
@@ -612,11 +685,11 @@ func runMultiDelete(items []deleteItem, itemType string) error {
 			var err error
 			switch itemType {
 			case "workflow":
-				err = deleteWorkflow(name, true)
+				err = deleteWorkflow(name, true, false)
 			case "prompt":
-				err = deletePrompt(name, true)
+				err = deletePrompt(name, true, false)
 			case "action":
-				err = deleteAction(name, true)
+				err = deleteAction(name, true, false)
 			}
 
 			if err != nil {
@@ -674,10 +747,21 @@ func selectDeleteType() (string, error) {
 	// Create a temporary model for selection
 	model := &selectTypeModel{list: l, state: "choosing"}
 
-	p := tea.NewProgram(model)
-	result, err := p.Run()
-	if err != nil {
-		return "", err
+	var result tea.Model = model
+	if utils.PlainUI() {
+		choice, err := plainSelect(l.Title, items)
+		if err != nil {
+			return "", err
+		}
+		chosen := choice.(item)
+		model.choice = &chosen
+	} else {
+		p := tea.NewProgram(model)
+		var err error
+		result, err = p.Run()
+		if err != nil {
+			return "", err
+		}
 	}
 
 	if m, ok := result.(*selectTypeModel); ok && m.choice != nil {
@@ -763,14 +847,9 @@ func getPromptDeleteItems() ([]deleteItem, error) {
 		return nil, fmt.Errorf("failed to access prompt garden: %w", err)
 	}
 
-	prompts, err := garden.ListPrompts()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list prompts: %w", err)
-	}
-
 	var items []deleteItem
-	for _, prompt := range prompts {
-		description := prompt.Metadata.Description
+	for _, prompt := range garden.Summaries() {
+		description := prompt.Description
 		if description == "" {
 			description = "Prompt"
 		}
@@ -871,6 +950,15 @@ func selectItemToDelete(items []deleteItem, itemType string) (*deleteItem, error
 		itemType: itemType,
 	}
 
+	if utils.PlainUI() {
+		choice, err := plainSelect(l.Title, listItems)
+		if err != nil {
+			return nil, err
+		}
+		chosen := choice.(deleteItem)
+		return &chosen, nil
+	}
+
 	p := tea.NewProgram(model)
 	result, err := p.Run()
 	if err != nil {
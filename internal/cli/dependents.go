@@ -0,0 +1,236 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// dependent is an item that references another workflow, prompt or action
+type dependent struct {
+	kind   string // workflow, prompt, action or subagent
+	name   string
+	reason string
+}
+
+// findDependents lists the items in ~/.opun that reference the given item
+func findDependents(kind, name string) ([]dependent, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return scanDependents(filepath.Join(home, ".opun"), kind, name)
+}
+
+// scanDependents looks for references to a workflow, prompt or action in the
+// workflows, actions, prompts and subagent configs under opunDir
+func scanDependents(opunDir, kind, name string) ([]dependent, error) {
+	var deps []dependent
+	promptRef := promptReferenceRegexp(name)
+
+	// Workflows reference prompts in their agent prompts and actions as requirements or agent tools
+	err := forEachConfigFile(filepath.Join(opunDir, "workflows"), func(path string, data []byte) {
+		wfName := configName(path)
+		switch kind {
+		case "prompt":
+			if promptRef.Match(data) {
+				deps = append(deps, dependent{kind: "workflow", name: wfName, reason: "includes promptgarden://" + name})
+			}
+		case "action":
+			var wf workflow.Workflow
+			if yaml.Unmarshal(data, &wf) != nil {
+				return
+			}
+			if containsString(wf.Requires.Actions, name) {
+				deps = append(deps, dependent{kind: "workflow", name: wfName, reason: "requires the action"})
+				return
+			}
+			for _, agent := range wf.Agents {
+				if containsString(agent.Settings.Tools, name) {
+					deps = append(deps, dependent{kind: "workflow", name: wfName, reason: fmt.Sprintf("agent %s uses the action as a tool", agent.ID)})
+					return
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Actions reference prompts and workflows directly
+	err = forEachConfigFile(filepath.Join(opunDir, "actions"), func(path string, data []byte) {
+		var action tools.ToolConfig
+		if yaml.Unmarshal(data, &action) != nil {
+			return
+		}
+		id := action.ID
+		if id == "" {
+			id = configName(path)
+		}
+		switch {
+		case kind == "prompt" && action.PromptRef == name:
+			deps = append(deps, dependent{kind: "action", name: id, reason: "prompt_ref " + name})
+		case kind == "workflow" && action.WorkflowRef == name:
+			deps = append(deps, dependent{kind: "action", name: id, reason: "workflow_ref " + name})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Subagents reference prompts in their system prompt and actions as tools
+	err = forEachConfigFile(filepath.Join(opunDir, "subagents"), func(path string, data []byte) {
+		var agent struct {
+			Name  string   `yaml:"name"`
+			Tools []string `yaml:"tools"`
+		}
+		_ = yaml.Unmarshal(data, &agent)
+		if agent.Name == "" {
+			agent.Name = configName(path)
+		}
+		switch {
+		case kind == "prompt" && promptRef.Match(data):
+			deps = append(deps, dependent{kind: "subagent", name: agent.Name, reason: "includes promptgarden://" + name})
+		case kind == "action" && containsString(agent.Tools, name):
+			deps = append(deps, dependent{kind: "subagent", name: agent.Name, reason: "uses the action as a tool"})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Prompts include other prompts
+	if kind == "prompt" {
+		if garden, err := promptgarden.NewGarden(filepath.Join(opunDir, "promptgarden")); err == nil {
+			prompts, _ := garden.List()
+			for _, prompt := range prompts {
+				if prompt.Name() != name && promptRef.MatchString(prompt.Content()) {
+					deps = append(deps, dependent{kind: "prompt", name: prompt.Name(), reason: "includes " + name})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(deps, func(i, j int) bool {
+		if deps[i].kind != deps[j].kind {
+			return deps[i].kind < deps[j].kind
+		}
+		return deps[i].name < deps[j].name
+	})
+	return deps, nil
+}
+
+// promptReferenceRegexp matches promptgarden://name and include:name references to a prompt
+func promptReferenceRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:promptgarden://|include:)\s*` + regexp.QuoteMeta(name) + `(?:[^A-Za-z0-9_.\-]|$)`)
+}
+
+// forEachConfigFile calls fn with every YAML or JSON file in dir; a missing dir has no files
+func forEachConfigFile(dir string, fn func(path string, data []byte)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// #nosec G304 -- reading Opun's own configuration files
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		fn(path, data)
+	}
+	return nil
+}
+
+func configName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDependents reports the items that reference the one being deleted.
+// Deleting is refused while dependents exist unless cascade or force is set.
+func checkDependents(kind, name string, force, cascade bool) ([]dependent, error) {
+	deps, err := findDependents(kind, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check references: %w", err)
+	}
+	if len(deps) == 0 {
+		return nil, nil
+	}
+
+	fmt.Printf("⚠️  %s '%s' is referenced by:\n", kind, name)
+	for _, dep := range deps {
+		fmt.Printf("  - %s %s (%s)\n", dep.kind, dep.name, dep.reason)
+	}
+
+	if !force && !cascade {
+		return nil, fmt.Errorf("%s '%s' is still referenced; use --cascade to delete the dependents too or --force to delete it anyway", kind, name)
+	}
+	if cascade {
+		return deps, nil
+	}
+	return nil, nil
+}
+
+// deleteDependents deletes the workflows, prompts and actions that referenced a
+// deleted item. Subagents are left for the user to update.
+func deleteDependents(deps []dependent) {
+	for _, dep := range deps {
+		var err error
+		switch dep.kind {
+		case "workflow":
+			err = deleteWorkflow(dep.name, true, true)
+		case "prompt":
+			err = deletePrompt(dep.name, true, true)
+		case "action":
+			err = deleteAction(dep.name, true, true)
+		default:
+			fmt.Printf("⚠️  Warning: %s '%s' still references the deleted item, update it manually\n", dep.kind, dep.name)
+			continue
+		}
+		if err != nil && !strings.Contains(err.Error(), "not found") {
+			fmt.Printf("⚠️  Warning: Failed to delete %s '%s': %v\n", dep.kind, dep.name, err)
+		}
+	}
+}
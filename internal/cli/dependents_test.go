@@ -0,0 +1,109 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanDependents(t *testing.T) {
+	opunDir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(opunDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write("workflows/review.yaml", `name: review
+requires:
+  actions: [lint]
+agents:
+  - id: reviewer
+    provider: claude
+    prompt: "promptgarden://code-review"
+`)
+	write("workflows/docs.yaml", `name: docs
+agents:
+  - id: writer
+    provider: claude
+    prompt: "promptgarden://code-review-extended {{include:style}}"
+    settings:
+      tools: [format]
+`)
+	write("actions/review-action.yaml", `id: review-action
+prompt: code-review
+`)
+	write("actions/ship.yaml", `id: ship
+workflow: review
+`)
+	write("subagents/critic.yaml", `name: critic
+system_prompt: "Follow promptgarden://code-review"
+tools: [lint]
+`)
+
+	garden, err := promptgarden.NewGarden(filepath.Join(opunDir, "promptgarden"))
+	require.NoError(t, err)
+	require.NoError(t, garden.Add(promptgarden.NewTemplatePrompt(core.PromptMetadata{Name: "strict-review"}, "{{include:code-review}} Be strict.")))
+
+	deps, err := scanDependents(opunDir, "prompt", "code-review")
+	require.NoError(t, err)
+	assert.Equal(t, []dependent{
+		{kind: "action", name: "review-action", reason: "prompt_ref code-review"},
+		{kind: "prompt", name: "strict-review", reason: "includes code-review"},
+		{kind: "subagent", name: "critic", reason: "includes promptgarden://code-review"},
+		{kind: "workflow", name: "review", reason: "includes promptgarden://code-review"},
+	}, deps)
+
+	deps, err = scanDependents(opunDir, "action", "lint")
+	require.NoError(t, err)
+	assert.Equal(t, []dependent{
+		{kind: "subagent", name: "critic", reason: "uses the action as a tool"},
+		{kind: "workflow", name: "review", reason: "requires the action"},
+	}, deps)
+
+	deps, err = scanDependents(opunDir, "action", "format")
+	require.NoError(t, err)
+	assert.Equal(t, []dependent{
+		{kind: "workflow", name: "docs", reason: "agent writer uses the action as a tool"},
+	}, deps)
+
+	deps, err = scanDependents(opunDir, "workflow", "review")
+	require.NoError(t, err)
+	assert.Equal(t, []dependent{
+		{kind: "action", name: "ship", reason: "workflow_ref review"},
+	}, deps)
+
+	deps, err = scanDependents(opunDir, "prompt", "unused")
+	require.NoError(t, err)
+	assert.Empty(t, deps)
+}
+
+func TestPromptReferenceRegexp(t *testing.T) {
+	re := promptReferenceRegexp("code-review")
+	assert.True(t, re.MatchString("promptgarden://code-review"))
+	assert.True(t, re.MatchString("{{include: code-review}}"))
+	assert.True(t, re.MatchString(`prompt: "promptgarden://code-review"`))
+	assert.False(t, re.MatchString("promptgarden://code-review-extended"))
+	assert.False(t, re.MatchString("code-review"))
+}
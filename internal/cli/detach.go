@@ -0,0 +1,119 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// detachKey detaches an attached client, like dtach (Ctrl-\)
+const detachKey = 0x1c
+
+// detachedRunInfo describes this process when it is the background half of
+// `opun run --detach`
+type detachedRunInfo struct {
+	id     string
+	server *workflow.AttachServer
+}
+
+// detachedRun is set by serveDetachedRun
+var detachedRun *detachedRunInfo
+
+// AttachCmd creates the attach command
+func AttachCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attach [run-id]",
+		Short: "Attach to a detached workflow run",
+		Long: `Attach your terminal to a workflow started with 'opun run --detach'.
+The run is identified by its run ID (or a unique prefix) or PID, as shown by
+'opun status'. With a single detached run the argument can be omitted.
+
+Press Ctrl-\ to detach again; the workflow keeps running.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runsDir, err := workflow.RunsDir()
+			if err != nil {
+				return err
+			}
+
+			runs, err := workflow.ListRuns(runsDir)
+			if err != nil {
+				return fmt.Errorf("failed to load workflow status: %w", err)
+			}
+
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+
+			run, err := findDetachedRun(runs, query)
+			if err != nil {
+				return err
+			}
+
+			return attachToRun(run)
+		},
+	}
+
+	return cmd
+}
+
+// findDetachedRun finds a detached run by ID, unique ID prefix or PID.
+// An empty query matches the only detached run.
+func findDetachedRun(runs []workflow.RunStatus, query string) (workflow.RunStatus, error) {
+	var detached []workflow.RunStatus
+	for _, run := range runs {
+		if run.Socket != "" {
+			detached = append(detached, run)
+		}
+	}
+
+	if len(detached) == 0 {
+		return workflow.RunStatus{}, fmt.Errorf("no detached workflows are running (start one with 'opun run --detach')")
+	}
+
+	if query == "" {
+		if len(detached) == 1 {
+			return detached[0], nil
+		}
+		return workflow.RunStatus{}, fmt.Errorf("%d detached workflows are running, specify a run ID (see 'opun status')", len(detached))
+	}
+
+	pid, _ := strconv.Atoi(query)
+	var matches []workflow.RunStatus
+	for _, run := range detached {
+		if run.ID == query || (pid > 0 && run.PID == pid) {
+			return run, nil
+		}
+		if strings.HasPrefix(run.ID, query) {
+			matches = append(matches, run)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return workflow.RunStatus{}, fmt.Errorf("no detached run matches %s", query)
+	case 1:
+		return matches[0], nil
+	}
+	return workflow.RunStatus{}, fmt.Errorf("run ID %s is ambiguous, %d runs match", query, len(matches))
+}
@@ -0,0 +1,254 @@
+//go:build !windows
+
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// errRunEnded is returned when the attached run finishes
+var errRunEnded = errors.New("run ended")
+
+// startDetachedRun checks a workflow in the foreground, then runs it in a new
+// background session that `opun attach` can connect to
+func startDetachedRun(name string, vars map[string]string) error {
+	w, err := loadWorkflow(name)
+	if err != nil {
+		return fmt.Errorf("failed to load workflow: %w", err)
+	}
+
+	// Resolve anything interactive while we still have a terminal
+	if err := ensureWorkflowRequirements(w); err != nil {
+		return err
+	}
+	if err := ensureProviderAuth(w); err != nil {
+		return err
+	}
+
+	runsDir, err := workflow.RunsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(runsDir, 0755); err != nil {
+		return err
+	}
+
+	runID := workflow.NewRunID()
+	logPath := filepath.Join(runsDir, runID+".log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create run log: %w", err)
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"run", name, "--run-id", runID, "--skip-auth-check"}
+	for k, v := range vars {
+		args = append(args, "--var", k+"="+v)
+	}
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		args = append(args, "--config", configFile)
+	}
+
+	// #nosec G204 -- re-executes the opun binary itself
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// A new session keeps the run alive when this terminal closes
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start detached run: %w", err)
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+
+	// Wait for the attach socket so an immediate `opun attach` works
+	socketPath := filepath.Join(runsDir, runID+".sock")
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(socketPath); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	fmt.Printf("🚀 Workflow '%s' is running in the background (run %s, PID %d)\n", w.Name, runID, pid)
+	fmt.Printf("   Attach: opun attach %s\n", runID)
+	fmt.Printf("   Log:    %s\n", logPath)
+	return nil
+}
+
+// serveDetachedRun routes this process's stdin and stdout through an attach
+// server, so a client can take over the terminal of the detached run
+func serveDetachedRun(runID string) (func(), error) {
+	runsDir, err := workflow.RunsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(runsDir, 0755); err != nil {
+		return nil, err
+	}
+
+	server, err := workflow.ListenAttach(filepath.Join(runsDir, runID+".sock"))
+	if err != nil {
+		return nil, err
+	}
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	// Output still goes to the run log, which the parent set as our stdout
+	logOut := os.Stdout
+	os.Stdin, os.Stdout, os.Stderr = inR, outW, outW
+
+	copied := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.MultiWriter(logOut, server), outR)
+		close(copied)
+	}()
+	go server.Serve(inW)
+
+	detachedRun = &detachedRunInfo{id: runID, server: server}
+
+	return func() {
+		os.Stdout, os.Stderr = logOut, logOut
+		outW.Close()
+		select {
+		case <-copied:
+		case <-time.After(time.Second):
+		}
+		server.Close()
+		inW.Close()
+		detachedRun = nil
+	}, nil
+}
+
+// attachToRun connects the terminal to a detached run until the user presses
+// the detach key or the run ends
+func attachToRun(run workflow.RunStatus) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("attach needs an interactive terminal")
+	}
+
+	conn, err := net.Dial("unix", run.Socket)
+	if err != nil {
+		return fmt.Errorf("failed to attach to run %s: %w", run.ID, err)
+	}
+	defer conn.Close()
+
+	fmt.Printf("📎 Attached to '%s' (run %s). Press Ctrl-\\ to detach.\n", run.Workflow, run.ID)
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set raw mode: %w", err)
+	}
+
+	// Frames are written from the input and resize goroutines
+	var writeMu sync.Mutex
+	send := func(frameType byte, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return workflow.WriteAttachFrame(conn, frameType, payload)
+	}
+
+	sendSize := func() {
+		if cols, rows, err := term.GetSize(fd); err == nil {
+			_ = send(workflow.AttachFrameResize, workflow.AttachResizePayload(uint16(rows), uint16(cols)))
+		}
+	}
+	sendSize()
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+	go func() {
+		for range winch {
+			sendSize()
+		}
+	}()
+
+	done := make(chan error, 2)
+	go func() {
+		_, _ = io.Copy(os.Stdout, conn)
+		done <- errRunEnded
+	}()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				done <- err
+				return
+			}
+			for i := 0; i < n; i++ {
+				if buf[i] == detachKey {
+					if i > 0 {
+						_ = send(workflow.AttachFrameData, buf[:i])
+					}
+					done <- nil
+					return
+				}
+			}
+			if err := send(workflow.AttachFrameData, buf[:n]); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+
+	err = <-done
+	_ = term.Restore(fd, oldState)
+
+	switch {
+	case err == nil:
+		fmt.Printf("\n👋 Detached. Reattach with: opun attach %s\n", run.ID)
+		return nil
+	case errors.Is(err, errRunEnded):
+		fmt.Printf("\n🏁 Run %s has ended\n", run.ID)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+)
+
+// startDetachedRun is not supported on Windows
+func startDetachedRun(name string, vars map[string]string) error {
+	return fmt.Errorf("detached runs are not supported on Windows")
+}
+
+// serveDetachedRun is not supported on Windows
+func serveDetachedRun(runID string) (func(), error) {
+	return nil, fmt.Errorf("detached runs are not supported on Windows")
+}
+
+// attachToRun is not supported on Windows
+func attachToRun(run workflow.RunStatus) error {
+	return fmt.Errorf("attaching to runs is not supported on Windows")
+}
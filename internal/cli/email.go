@@ -0,0 +1,104 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/viper"
+)
+
+// emailFromConfig reads the email section of the config, the SMTP server and
+// default recipients of run reports. It returns nil when unset.
+func emailFromConfig() *wf.Email {
+	if !viper.IsSet("email") {
+		return nil
+	}
+	return &wf.Email{
+		Disabled:    viper.GetBool("email.disabled"),
+		Host:        viper.GetString("email.host"),
+		Port:        viper.GetInt("email.port"),
+		TLS:         viper.GetString("email.tls"),
+		Username:    viper.GetString("email.username"),
+		PasswordEnv: viper.GetString("email.password_env"),
+		From:        viper.GetString("email.from"),
+		To:          viper.GetStringSlice("email.to"),
+		Cc:          viper.GetStringSlice("email.cc"),
+		On:          viper.GetStringSlice("email.on"),
+		Subject:     viper.GetString("email.subject"),
+		Body:        viper.GetString("email.body"),
+		Attach:      configAttach(),
+	}
+}
+
+// configAttach reads email.attach, keeping an empty list apart from an unset one
+func configAttach() []string {
+	if !viper.IsSet("email.attach") {
+		return nil
+	}
+	return append([]string{}, viper.GetStringSlice("email.attach")...)
+}
+
+// applyEmailConfig fills in what a workflow's settings.email leaves out from
+// the configured email, so workflows only need to name their recipients
+func applyEmailConfig(w *wf.Workflow) {
+	configured := emailFromConfig()
+	if configured == nil {
+		return
+	}
+	if w.Settings.Email == nil {
+		w.Settings.Email = configured
+		return
+	}
+	email := w.Settings.Email
+	if email.Disabled {
+		return
+	}
+	if email.Host == "" {
+		email.Host, email.Port, email.TLS = configured.Host, configured.Port, configured.TLS
+		email.Username, email.PasswordEnv = configured.Username, configured.PasswordEnv
+	}
+	if email.From == "" {
+		email.From = configured.From
+	}
+	if len(email.To) == 0 {
+		email.To, email.Cc = configured.To, configured.Cc
+	}
+	if len(email.On) == 0 {
+		email.On = configured.On
+	}
+	if email.Subject == "" {
+		email.Subject = configured.Subject
+	}
+	if email.Body == "" {
+		email.Body = configured.Body
+	}
+	if email.Attach == nil {
+		email.Attach = configured.Attach
+	}
+}
+
+// emailRunReport emails the report of a headless or matrix run when the
+// workflow asks for one, warning when it can't be sent
+func emailRunReport(ctx context.Context, w *wf.Workflow, report workflow.RunReport) {
+	if err := workflow.SendRunReport(ctx, w.Settings.Email, report); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rizome-dev/opun/internal/export"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/core"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd creates the export command
+func ExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export workflows and prompts to native provider formats",
+		Long: `Export an Opun workflow or prompt into the files a provider reads natively,
+so it can be shared with teammates who don't use Opun.
+
+Prompt garden references are inlined, so the exported files stand alone.`,
+	}
+
+	cmd.AddCommand(exportProviderCmd("claude",
+		"Export as Claude Code commands and subagents",
+		`Write a .claude/commands/<name>.md slash command. Workflows also get one
+.claude/agents/<workflow>-<step>.md subagent per step, and the command
+delegates to them in order.`,
+		export.ClaudeWorkflow, export.ClaudePrompt))
+
+	cmd.AddCommand(exportProviderCmd("gemini",
+		"Export as a Gemini CLI extension",
+		`Write a .gemini/extensions/<name>/ extension with a gemini-extension.json
+manifest, a GEMINI.md context file holding the instructions and a
+commands/<name>.toml command that runs them.`,
+		export.GeminiWorkflow, export.GeminiPrompt))
+
+	return cmd
+}
+
+// exportProviderCmd creates an export subcommand for one provider
+func exportProviderCmd(
+	provider, short, long string,
+	exportWorkflow func(*wf.Workflow, export.PromptResolver) ([]export.File, error),
+	exportPrompt func(core.Prompt, export.PromptResolver) ([]export.File, error),
+) *cobra.Command {
+	var (
+		outputDir string
+		force     bool
+		asPrompt  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   provider + " <workflow|prompt>",
+		Short: short,
+		Long:  long,
+		Example: fmt.Sprintf(`  opun export %[1]s code-review
+  opun export %[1]s --prompt refactor -o ../shared`, provider),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+
+			garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+			if err != nil {
+				return fmt.Errorf("failed to access prompt garden: %w", err)
+			}
+			resolve := gardenResolver(garden)
+
+			var files []export.File
+			kind := "prompt"
+			if !asPrompt {
+				if w, err := workflow.NewParser(filepath.Join(home, ".opun", "workflows")).LoadWorkflow(name); err == nil {
+					kind = "workflow"
+					if files, err = exportWorkflow(w, resolve); err != nil {
+						return fmt.Errorf("failed to export workflow: %w", err)
+					}
+				}
+			}
+			if kind == "prompt" {
+				prompt, err := lookupPrompt(garden, name)
+				if err != nil {
+					if asPrompt {
+						return fmt.Errorf("prompt '%s' not found", name)
+					}
+					return fmt.Errorf("no workflow or prompt named '%s'", name)
+				}
+				if files, err = exportPrompt(prompt, resolve); err != nil {
+					return fmt.Errorf("failed to export prompt: %w", err)
+				}
+			}
+
+			written, err := export.Write(outputDir, files, force)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ Exported %s '%s' for %s\n", kind, name, provider)
+			for _, path := range written {
+				fmt.Printf("  %s\n", path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputDir, "output", "o", ".", "directory to write the exported files to")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "overwrite existing files")
+	cmd.Flags().BoolVar(&asPrompt, "prompt", false, "export a prompt even if a workflow has the same name")
+
+	return cmd
+}
+
+// lookupPrompt finds a garden prompt by name or ID
+func lookupPrompt(garden *promptgarden.Garden, name string) (core.Prompt, error) {
+	if prompt, err := garden.GetByName(name); err == nil {
+		return prompt, nil
+	}
+	return garden.Get(name)
+}
+
+// gardenResolver resolves prompt references against the prompt garden
+func gardenResolver(garden *promptgarden.Garden) export.PromptResolver {
+	return func(name string) (string, error) {
+		prompt, err := lookupPrompt(garden, name)
+		if err != nil {
+			return "", err
+		}
+		return prompt.Content(), nil
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// FeaturesCmd creates the features command
+func FeaturesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "features",
+		Short: "List and toggle experimental features",
+		Long: `Experimental subsystems ship disabled so they can't change how Opun
+behaves until you opt in. Enabling one records features.<name>: true in
+~/.opun/config.yaml; set OPUN_FEATURES to a comma separated list of
+features (or "all") to enable them for a single command.
+
+Features:
+  container-sandbox    settings.sandbox and agent sandboxes
+  daemon               opun daemon, opun serve and opun lsp
+  parallel-workflows   opun run --matrix --parallel N`,
+		Example: `  opun features
+  opun features enable parallel-workflows
+  opun features disable daemon
+  OPUN_FEATURES=daemon opun daemon --api`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printFeatures()
+			return nil
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the experimental features and whether they are enabled",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printFeatures()
+			return nil
+		},
+	})
+
+	for _, on := range []bool{true, false} {
+		use, short := "enable", "Enable experimental features"
+		if !on {
+			use, short = "disable", "Disable experimental features"
+		}
+		cmd.AddCommand(&cobra.Command{
+			Use:       use + " <feature>...",
+			Short:     short,
+			Args:      cobra.MinimumNArgs(1),
+			ValidArgs: features.Names(),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if err := setFeatures(args, on); err != nil {
+					return err
+				}
+				for _, name := range args {
+					if on {
+						fmt.Printf("✅ Enabled %s\n", name)
+					} else if features.FromEnv(name) {
+						fmt.Printf("Disabled %s, though %s still enables it for this shell\n", name, features.EnvVar)
+					} else {
+						fmt.Printf("Disabled %s\n", name)
+					}
+				}
+				return nil
+			},
+		})
+	}
+
+	return cmd
+}
+
+// printFeatures lists the known features with their state
+func printFeatures() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "FEATURE\tSTATUS\tDESCRIPTION")
+	for _, feature := range features.All() {
+		status := "disabled"
+		switch {
+		case viper.GetBool(featureKey(feature.Name)):
+			status = "enabled"
+		case features.FromEnv(feature.Name):
+			status = "enabled (" + features.EnvVar + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", feature.Name, status, feature.Description)
+	}
+	_ = w.Flush()
+}
+
+// setFeatures enables or disables features in the config file
+func setFeatures(names []string, on bool) error {
+	for _, name := range names {
+		if _, ok := features.Lookup(name); !ok {
+			return fmt.Errorf("unknown feature %q, use one of: %s", name, strings.Join(features.Names(), ", "))
+		}
+	}
+
+	for _, name := range names {
+		viper.Set(featureKey(name), on)
+		features.Set(name, on)
+	}
+
+	configPath := viper.ConfigFileUsed()
+	if configPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		configPath = filepath.Join(home, ".opun", "config.yaml")
+	}
+
+	if err := viper.WriteConfigAs(configPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// setFeaturesFromConfig applies the features section of the config
+func setFeaturesFromConfig() {
+	var unknown []string
+	for name := range viper.GetStringMap("features") {
+		if _, ok := features.Lookup(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fmt.Fprintf(os.Stderr, "Warning: unknown feature %s in the config\n", featureKey(name))
+	}
+
+	for _, name := range features.Names() {
+		features.Set(name, viper.GetBool(featureKey(name)))
+	}
+}
+
+// featureKey is the config key of a feature
+func featureKey(name string) string {
+	return "features." + name
+}
@@ -0,0 +1,207 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/core"
+	subagentpkg "github.com/rizome-dev/opun/pkg/subagent"
+	"github.com/spf13/cobra"
+)
+
+// FeedbackCmd creates the feedback command
+func FeedbackCmd() *cobra.Command {
+	var (
+		agent      string
+		rating     int
+		note       string
+		stats      bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "feedback [run-id]",
+		Short: "Rate the outputs of a workflow run",
+		Long: `Record how well a finished run, or one of its agents, did. Feedback is kept
+with the run in ~/.opun/runs/history and the ratings of each agent's
+provider and model feed subagent routing: subagents named like a rated agent,
+or else on its provider, are preferred or avoided accordingly.
+
+Without --rating the run's feedback is listed. --stats shows the average
+rating of every workflow agent across runs.
+
+Examples:
+  # Rate the reviewer agent of a run
+  opun feedback 4242-1730000000000000000 --agent reviewer --rating 2 --note "missed edge case"
+
+  # Rate the whole run
+  opun feedback 4242-1730000000000000000 --rating 5
+
+  # Average ratings per agent, provider and model
+  opun feedback --stats`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if stats {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := workflow.HistoryDir()
+			if err != nil {
+				return err
+			}
+
+			if stats {
+				records, err := workflow.ListRunRecords(dir)
+				if err != nil {
+					return err
+				}
+				summaries := workflow.SummarizeFeedback(records)
+				if jsonOutput {
+					return printJSON(summaries)
+				}
+				printFeedbackStats(summaries)
+				return nil
+			}
+
+			runID := args[0]
+			if rating == 0 {
+				record, err := workflow.LoadRunRecord(dir, runID)
+				if err != nil {
+					return err
+				}
+				if jsonOutput {
+					return printJSON(record.Feedback)
+				}
+				printRunFeedback(record)
+				return nil
+			}
+
+			record, err := workflow.AddFeedback(dir, runID, workflow.Feedback{
+				Agent:  agent,
+				Rating: rating,
+				Note:   note,
+			})
+			if err != nil {
+				return err
+			}
+			fb := record.Feedback[len(record.Feedback)-1]
+			target := record.Workflow
+			if fb.Agent != "" {
+				target = fmt.Sprintf("%s/%s (%s)", record.Workflow, fb.Agent, feedbackModel(fb.Provider, fb.Model))
+			}
+			fmt.Printf("⭐ Rated %s %d/%d\n", target, fb.Rating, workflow.MaxRating)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&agent, "agent", "", "agent of the run to rate (ID or name; default: the whole run)")
+	cmd.Flags().IntVar(&rating, "rating", 0, fmt.Sprintf("rating from %d to %d", workflow.MinRating, workflow.MaxRating))
+	cmd.Flags().StringVar(&note, "note", "", "what was good or bad")
+	cmd.Flags().BoolVar(&stats, "stats", false, "show average ratings per workflow agent")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+
+	return cmd
+}
+
+// learnFromFeedback replays the ratings of workflow agents to the subagent
+// router, so routing favors what users rated well
+func learnFromFeedback(mgr *subagentpkg.Manager) {
+	dir, err := workflow.HistoryDir()
+	if err != nil {
+		return
+	}
+	records, err := workflow.ListRunRecords(dir)
+	if err != nil {
+		return
+	}
+	for _, record := range records {
+		for _, fb := range record.Feedback {
+			if fb.Agent == "" {
+				continue
+			}
+			task := core.SubAgentTask{ID: record.RunID, Name: record.Workflow}
+			mgr.LearnFeedback(task, fb.Agent, core.ProviderType(strings.ToLower(fb.Provider)), fb.Rating)
+		}
+	}
+}
+
+// printRunFeedback lists the feedback given on a run
+func printRunFeedback(record *workflow.RunRecord) {
+	if len(record.Feedback) == 0 {
+		fmt.Printf("No feedback on run %s yet\n", record.RunID)
+		return
+	}
+
+	fmt.Printf("⭐ Feedback on %s (%s)\n\n", record.RunID, record.Workflow)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tMODEL\tRATING\tGIVEN\tNOTE")
+	for _, fb := range record.Feedback {
+		agent := fb.Agent
+		if agent == "" {
+			agent = "(run)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\t%s\n", agent, feedbackModel(fb.Provider, fb.Model), fb.Rating, workflow.MaxRating, fb.Time.Format("2006-01-02 15:04"), fb.Note)
+	}
+	_ = w.Flush()
+}
+
+// printFeedbackStats prints the average rating of every workflow agent
+func printFeedbackStats(summaries []workflow.FeedbackSummary) {
+	if len(summaries) == 0 {
+		fmt.Println("No feedback yet")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKFLOW\tAGENT\tMODEL\tRATINGS\tAVERAGE")
+	for _, s := range summaries {
+		agent := s.Agent
+		if agent == "" {
+			agent = "(run)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.1f\n", s.Workflow, agent, feedbackModel(s.Provider, s.Model), s.Ratings, s.Average)
+	}
+	_ = w.Flush()
+}
+
+// feedbackModel formats a provider and model as provider/model
+func feedbackModel(provider, model string) string {
+	switch {
+	case provider == "":
+		return "-"
+	case model == "":
+		return provider
+	}
+	return provider + "/" + model
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
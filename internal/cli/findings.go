@@ -0,0 +1,90 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// FindingsCmd creates the findings command
+func FindingsCmd() *cobra.Command {
+	var (
+		format string
+		tool   string
+		rule   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "findings <file|->",
+		Short: "Convert review findings to SARIF or GitHub annotations",
+		Long: `Read the findings a review agent listed in a fenced json block (or a JSON
+file) and print them as SARIF 2.1.0 for code scanning, or as GitHub check run
+annotations. Agents with 'findings: true' are asked for this block and have
+their findings converted when they finish; this command converts outputs
+saved earlier or written by other tools.`,
+		Example: `  opun findings opun-runs/20250101-120000/review.md > review.sarif
+  opun findings review.md --format annotations`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				data []byte
+				err  error
+			)
+			if args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			workDir, _ := os.Getwd()
+			findings, err := workflow.ParseFindings(string(data), workDir)
+			if err != nil {
+				return err
+			}
+
+			var out []byte
+			switch format {
+			case "sarif":
+				out, err = workflow.SARIF(tool, rule, findings)
+			case "annotations":
+				out, err = json.MarshalIndent(workflow.GitHubAnnotations(findings), "", "  ")
+			default:
+				return fmt.Errorf("unknown format %q, use sarif or annotations", format)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "sarif", "output format: sarif or annotations")
+	cmd.Flags().StringVar(&tool, "tool", "opun", "tool name in SARIF")
+	cmd.Flags().StringVar(&rule, "rule", "review", "rule ID of findings that don't name one")
+
+	return cmd
+}
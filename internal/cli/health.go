@@ -137,9 +137,8 @@ func (h *HealthCheck) checkPromptGardenClaude() ServiceStatus {
 
 	gardenPath := filepath.Join(h.homeDir, ".opun", "promptgarden")
 	if garden, err := promptgarden.NewGarden(gardenPath); err == nil {
-		prompts, _ := garden.ListPrompts()
 		status.Status = true
-		status.Count = len(prompts)
+		status.Count = len(garden.Summaries())
 	} else {
 		status.Status = false
 		status.Count = 0
@@ -158,9 +157,8 @@ func (h *HealthCheck) checkPromptGardenGemini() ServiceStatus {
 	if mcpStatus := h.checkMCPServer(); mcpStatus.Status {
 		gardenPath := filepath.Join(h.homeDir, ".opun", "promptgarden")
 		if garden, err := promptgarden.NewGarden(gardenPath); err == nil {
-			prompts, _ := garden.ListPrompts()
 			status.Status = true
-			status.Count = len(prompts)
+			status.Count = len(garden.Summaries())
 		} else {
 			status.Status = false
 			status.Count = 0
@@ -0,0 +1,271 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maxAuditInput caps the tool input or prompt kept in an audit record
+const maxAuditInput = 200
+
+// claudeHookInput is the JSON Claude passes a hook command on stdin
+type claudeHookInput struct {
+	SessionID    string          `json:"session_id"`
+	Cwd          string          `json:"cwd"`
+	Event        string          `json:"hook_event_name"`
+	Prompt       string          `json:"prompt,omitempty"`
+	ToolName     string          `json:"tool_name,omitempty"`
+	ToolInput    json.RawMessage `json:"tool_input,omitempty"`
+	ToolResponse json.RawMessage `json:"tool_response,omitempty"`
+}
+
+// claudeHooks runs Opun's prompt policy, secret redaction and audit log for
+// a Claude session
+type claudeHooks struct {
+	policy   *workflow.PromptPolicy
+	redactor *workflow.Redactor
+	auditLog string
+}
+
+// HookCmd creates the hook command providers run for Opun's hooks
+func HookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "hook",
+		Short:  "Handle provider hook events",
+		Hidden: true,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "claude <user-prompt-submit|pre-tool-use|post-tool-use>",
+		Short: "Handle a Claude hook event",
+		Long: `Handle a Claude hook event read from stdin. Opun passes these hooks with
+--settings when it launches Claude: prompts are checked
+against prompt_policy, prompts and tool inputs containing secrets are
+blocked, Claude is warned about secrets in tool output, and every event is
+appended to the audit log.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hooks, err := loadClaudeHooks()
+			if err != nil || hooks == nil {
+				return err
+			}
+
+			var input claudeHookInput
+			if err := json.NewDecoder(os.Stdin).Decode(&input); err != nil {
+				return fmt.Errorf("invalid hook input: %w", err)
+			}
+			output, err := hooks.handle(cmd.Context(), args[0], input)
+			if err != nil || output == nil {
+				return err
+			}
+			return json.NewEncoder(os.Stdout).Encode(output)
+		},
+	})
+
+	return cmd
+}
+
+// claudeHooksEnabled reads claude_hooks.enabled, on unless turned off
+func claudeHooksEnabled() bool {
+	if viper.IsSet("claude_hooks.enabled") {
+		return viper.GetBool("claude_hooks.enabled")
+	}
+	return true
+}
+
+// loadClaudeHooks reads the claude_hooks section of the config, returning
+// nil when the hooks are turned off
+func loadClaudeHooks() (*claudeHooks, error) {
+	if !claudeHooksEnabled() {
+		return nil, nil
+	}
+
+	policy, err := loadPromptPolicy()
+	if err != nil {
+		return nil, err
+	}
+	hooks := &claudeHooks{policy: policy}
+
+	if !viper.IsSet("claude_hooks.redact") || viper.GetBool("claude_hooks.redact") {
+		// Email addresses are everywhere in code and commits, so they only
+		// count as secrets when asked for
+		disable := []string{"email"}
+		if viper.IsSet("claude_hooks.redact_disable") {
+			disable = viper.GetStringSlice("claude_hooks.redact_disable")
+		}
+		hooks.redactor, err = workflow.NewRedactor(&wf.Redact{
+			Enabled:  true,
+			Patterns: viper.GetStringMapString("claude_hooks.redact_patterns"),
+			Disable:  disable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid claude_hooks config: %w", err)
+		}
+	}
+
+	if viper.IsSet("claude_hooks.audit_log") {
+		hooks.auditLog = viper.GetString("claude_hooks.audit_log")
+	} else if home, err := os.UserHomeDir(); err == nil {
+		hooks.auditLog = filepath.Join(home, ".opun", "hooks", "claude-audit.log")
+	}
+	return hooks, nil
+}
+
+// handle runs a hook event and returns the JSON Claude should get back, nil
+// to let it continue
+func (h *claudeHooks) handle(ctx context.Context, event string, input claudeHookInput) (map[string]interface{}, error) {
+	var (
+		output   map[string]interface{}
+		reason   string
+		text     string
+		secrets  map[string]int
+		decision = "allow"
+	)
+
+	switch event {
+	case "user-prompt-submit":
+		text = input.Prompt
+		secrets = h.findSecrets(text)
+		if len(secrets) > 0 {
+			reason = fmt.Sprintf("Opun blocked this prompt: it contains secrets (%s). Remove them or refer to an environment variable instead.", secretNames(secrets))
+		} else if h.policy != nil {
+			check, err := h.policy.Check(ctx, text, []string{"OPUN_PROVIDER=claude"})
+			if err != nil {
+				return nil, err
+			}
+			if check.Action != workflow.PolicyAllow {
+				reason = fmt.Sprintf("Opun's prompt policy blocked this prompt (%s).", strings.Join(check.Reasons, "; "))
+				if check.Action == workflow.PolicyConfirm {
+					reason += " It needs confirmation, which Claude sessions can't give; rephrase it or run it outside Opun."
+				}
+			}
+		}
+		if reason != "" {
+			decision = "block"
+			output = map[string]interface{}{"decision": "block", "reason": reason}
+		}
+
+	case "pre-tool-use":
+		text = string(input.ToolInput)
+		secrets = h.findSecrets(text)
+		if len(secrets) > 0 {
+			decision = "deny"
+			output = map[string]interface{}{
+				"hookSpecificOutput": map[string]interface{}{
+					"hookEventName":            "PreToolUse",
+					"permissionDecision":       "deny",
+					"permissionDecisionReason": fmt.Sprintf("Opun blocked %s: its input contains secrets (%s). Use a placeholder or an environment variable instead.", input.ToolName, secretNames(secrets)),
+				},
+			}
+		}
+
+	case "post-tool-use":
+		text = string(input.ToolInput)
+		secrets = h.findSecrets(string(input.ToolResponse))
+		if len(secrets) > 0 {
+			decision = "warn"
+			output = map[string]interface{}{
+				"decision": "block",
+				"reason":   fmt.Sprintf("The output of %s contains secrets (%s). Don't repeat, store or send them anywhere.", input.ToolName, secretNames(secrets)),
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown Claude hook event %q", event)
+	}
+
+	h.audit(event, decision, input, text, secrets)
+	return output, nil
+}
+
+// findSecrets counts the secrets in text by pattern
+func (h *claudeHooks) findSecrets(text string) map[string]int {
+	if h.redactor == nil || text == "" {
+		return nil
+	}
+	_, counts := h.redactor.Redact(text)
+	return counts
+}
+
+// secretNames lists the patterns that found secrets
+func secretNames(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// audit appends a record of a hook event to the audit log. The input is
+// stored redacted and shortened.
+func (h *claudeHooks) audit(event, decision string, input claudeHookInput, text string, secrets map[string]int) {
+	if h.auditLog == "" {
+		return
+	}
+
+	if h.redactor != nil {
+		text, _ = h.redactor.Redact(text)
+	}
+	if runes := []rune(text); len(runes) > maxAuditInput {
+		text = string(runes[:maxAuditInput]) + "…"
+	}
+	entry := map[string]interface{}{
+		"time":     time.Now().Format(time.RFC3339),
+		"provider": "claude",
+		"session":  input.SessionID,
+		"cwd":      input.Cwd,
+		"event":    event,
+		"decision": decision,
+		"input":    text,
+	}
+	if input.ToolName != "" {
+		entry["tool"] = input.ToolName
+	}
+	if len(secrets) > 0 {
+		entry["secrets"] = secrets
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := utils.EnsureDir(filepath.Dir(h.auditLog)); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "%s\n", data)
+}
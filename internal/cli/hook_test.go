@@ -0,0 +1,82 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaudeHooks(t *testing.T) {
+	redactor, err := workflow.NewRedactor(&wf.Redact{Enabled: true, Disable: []string{"email"}})
+	require.NoError(t, err)
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	hooks := &claudeHooks{
+		policy:   &workflow.PromptPolicy{Rules: []workflow.PolicyRule{{Name: "prod", Phrases: []string{"drop the production"}}}},
+		redactor: redactor,
+		auditLog: auditLog,
+	}
+	ctx := context.Background()
+	key := "sk-ant-" + strings.Repeat("a", 30)
+
+	output, err := hooks.handle(ctx, "user-prompt-submit", claudeHookInput{SessionID: "s1", Prompt: "Refactor the parser for bob@example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, output)
+
+	output, err = hooks.handle(ctx, "user-prompt-submit", claudeHookInput{Prompt: "Drop the production database"})
+	require.NoError(t, err)
+	assert.Equal(t, "block", output["decision"])
+	assert.Contains(t, output["reason"], "rule prod")
+
+	output, err = hooks.handle(ctx, "user-prompt-submit", claudeHookInput{Prompt: "Use " + key})
+	require.NoError(t, err)
+	assert.Contains(t, output["reason"], "anthropic_key")
+
+	input, _ := json.Marshal(map[string]string{"file_path": ".env", "content": "KEY=" + key})
+	output, err = hooks.handle(ctx, "pre-tool-use", claudeHookInput{ToolName: "Write", ToolInput: input})
+	require.NoError(t, err)
+	decision := output["hookSpecificOutput"].(map[string]interface{})
+	assert.Equal(t, "deny", decision["permissionDecision"])
+
+	response, _ := json.Marshal(map[string]string{"stdout": key})
+	output, err = hooks.handle(ctx, "post-tool-use", claudeHookInput{ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"cat .env"}`), ToolResponse: response})
+	require.NoError(t, err)
+	assert.Equal(t, "block", output["decision"])
+
+	_, err = hooks.handle(ctx, "stop", claudeHookInput{})
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(auditLog)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 5)
+	assert.NotContains(t, string(data), key, "the audit log is redacted")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &entry))
+	assert.Equal(t, "pre-tool-use", entry["event"])
+	assert.Equal(t, "deny", entry["decision"])
+	assert.Equal(t, "Write", entry["tool"])
+}
@@ -0,0 +1,197 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// InspectCmd creates the inspect command
+func InspectCmd() *cobra.Command {
+	var (
+		agent      string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "inspect <run-id>",
+		Short: "Show what each step of a run was given and why it behaved as it did",
+		Long: `Reconstruct a finished or running workflow run step by step: the fully
+rendered prompt injected into each agent (one per attempt), the workflow
+variables when it was rendered, the {{agent.output}} references and the
+files they resolved to, and the decisions taken about the step -- its
+condition, prompt size guard, prompt policy, output nudges and produces
+retries -- along with its final status.
+
+Prompts are kept in ~/.opun/runs/history/inspect, readable only by you,
+and scrubbed when the workflow has settings.redact on.`,
+		Example: `  opun inspect 4242-1730000000000000000
+  opun inspect 4242-1730000000000000000 --agent reviewer --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID := args[0]
+			path, err := workflow.InspectionPath(runID)
+			if err != nil {
+				return err
+			}
+			inspection, err := workflow.LoadInspection(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fmt.Errorf("nothing recorded for run %s; only runs started with 'opun run' can be inspected", runID)
+				}
+				return err
+			}
+
+			// The run record has each agent's final status
+			var record *workflow.RunRecord
+			if dir, err := workflow.HistoryDir(); err == nil {
+				record, _ = workflow.LoadRunRecord(dir, runID)
+			}
+
+			if agent != "" {
+				step := inspection.Step(agent)
+				if step == nil {
+					return fmt.Errorf("agent %s has nothing recorded in run %s", agent, runID)
+				}
+				inspection.Steps = []*workflow.StepInspection{step}
+			}
+
+			if jsonOutput {
+				return printJSON(inspection)
+			}
+			printInspection(os.Stdout, inspection, record)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&agent, "agent", "", "only show this agent (ID or name)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+
+	return cmd
+}
+
+// printInspection prints a run's steps with their decisions and prompts
+func printInspection(out io.Writer, inspection *workflow.RunInspection, record *workflow.RunRecord) {
+	fmt.Fprintf(out, "🔍 Run %s of %s", inspection.RunID, inspection.Workflow)
+	if record != nil {
+		fmt.Fprintf(out, " (%s)", record.Status)
+	}
+	fmt.Fprintln(out)
+	if record != nil && record.Error != "" {
+		fmt.Fprintf(out, "   Error: %s\n", record.Error)
+	}
+
+	for i, step := range inspection.Steps {
+		fmt.Fprintf(out, "\n━━━ %d. %s", i+1, step.Agent)
+		if step.Name != "" && step.Name != step.Agent {
+			fmt.Fprintf(out, " (%s)", step.Name)
+		}
+		if step.Provider != "" {
+			fmt.Fprintf(out, " · %s", step.Provider)
+			if step.Model != "" {
+				fmt.Fprintf(out, "/%s", step.Model)
+			}
+		}
+		if status := inspectedStatus(record, step.Agent); status != "" {
+			fmt.Fprintf(out, " · %s", status)
+		}
+		fmt.Fprintln(out)
+
+		if len(step.Decisions) > 0 {
+			fmt.Fprintln(out, "Decisions:")
+			for _, d := range step.Decisions {
+				line := fmt.Sprintf("  %s  %-13s %s", d.Time.Format("15:04:05"), d.Kind, d.Outcome)
+				if d.Detail != "" {
+					line += " -- " + d.Detail
+				}
+				fmt.Fprintln(out, line)
+			}
+		}
+
+		if len(step.Inputs) > 0 {
+			fmt.Fprintln(out, "Inputs:")
+			for _, name := range sortedKeysOf(step.Inputs) {
+				value, _ := json.Marshal(step.Inputs[name])
+				fmt.Fprintf(out, "  %s = %s\n", name, value)
+			}
+		}
+
+		for _, attempt := range step.Attempts {
+			fmt.Fprintf(out, "Attempt %d at %s", attempt.Attempt, attempt.Time.Format("15:04:05"))
+			if attempt.Tokens > 0 {
+				fmt.Fprintf(out, ", ~%d tokens", attempt.Tokens)
+			}
+			fmt.Fprintln(out, ":")
+
+			if len(attempt.Variables) > 0 {
+				fmt.Fprintln(out, "  Variables:")
+				for _, name := range sortedKeysOf(attempt.Variables) {
+					fmt.Fprintf(out, "    %s = %v\n", name, attempt.Variables[name])
+				}
+			}
+			if len(attempt.References) > 0 {
+				fmt.Fprintln(out, "  References:")
+				refs := make([]string, 0, len(attempt.References))
+				for ref := range attempt.References {
+					refs = append(refs, ref)
+				}
+				sort.Strings(refs)
+				for _, ref := range refs {
+					fmt.Fprintf(out, "    %s → %s\n", ref, attempt.References[ref])
+				}
+			}
+			fmt.Fprintln(out, "  Prompt:")
+			for _, line := range strings.Split(strings.TrimRight(attempt.Prompt, "\n"), "\n") {
+				fmt.Fprintf(out, "    │ %s\n", line)
+			}
+		}
+	}
+}
+
+// inspectedStatus returns an agent's final status from the run record
+func inspectedStatus(record *workflow.RunRecord, agentID string) string {
+	if record == nil {
+		return ""
+	}
+	for _, agent := range record.Agents {
+		if agent.ID == agentID {
+			if agent.ErrorClass != "" {
+				return fmt.Sprintf("%s (%s)", agent.Status, agent.ErrorClass)
+			}
+			return agent.Status
+		}
+	}
+	return ""
+}
+
+// sortedKeysOf returns the keys of a map in order
+func sortedKeysOf(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,65 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintInspection(t *testing.T) {
+	at := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	inspection := &workflow.RunInspection{
+		RunID:    "7-3000",
+		Workflow: "review",
+		Steps: []*workflow.StepInspection{{
+			Agent:    "fix",
+			Name:     "Fixer",
+			Provider: "claude",
+			Decisions: []workflow.Decision{
+				{Time: at, Kind: workflow.DecisionCondition, Outcome: "run", Detail: "plan.success"},
+			},
+			Attempts: []workflow.PromptAttempt{{
+				Attempt:    1,
+				Time:       at,
+				Variables:  map[string]interface{}{"focus": "security"},
+				References: map[string]string{"{{plan.output}}": "@out/plan.md"},
+				Prompt:     "Fix @out/plan.md\nCarefully",
+				Tokens:     42,
+			}},
+		}},
+	}
+	record := &workflow.RunRecord{RunManifest: workflow.RunManifest{
+		Status: "failed",
+		Agents: []workflow.ManifestAgent{{ID: "fix", Status: "failed", ErrorClass: "gate_failed"}},
+	}}
+
+	var out bytes.Buffer
+	printInspection(&out, inspection, record)
+	text := out.String()
+	assert.Contains(t, text, "Run 7-3000 of review (failed)")
+	assert.Contains(t, text, "1. fix (Fixer) · claude · failed (gate_failed)")
+	assert.Contains(t, text, "condition     run -- plan.success")
+	assert.Contains(t, text, "Attempt 1 at 15:04:05, ~42 tokens:")
+	assert.Contains(t, text, "focus = security")
+	assert.Contains(t, text, "{{plan.output}} → @out/plan.md")
+	assert.Contains(t, text, "    │ Carefully\n")
+}
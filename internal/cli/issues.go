@@ -0,0 +1,68 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/viper"
+)
+
+// issuesFromConfig reads the issues section of the config, the trackers
+// for workflows that don't configure their own. It returns nil when unset.
+func issuesFromConfig() *wf.IssueTrackers {
+	if !viper.IsSet("issues") {
+		return nil
+	}
+	trackers := &wf.IssueTrackers{DryRun: viper.GetBool("issues.dry_run")}
+	if viper.IsSet("issues.jira") {
+		trackers.Jira = &wf.JiraTracker{
+			URL:       viper.GetString("issues.jira.url"),
+			Email:     viper.GetString("issues.jira.email"),
+			TokenEnv:  viper.GetString("issues.jira.token_env"),
+			Project:   viper.GetString("issues.jira.project"),
+			IssueType: viper.GetString("issues.jira.issue_type"),
+		}
+	}
+	if viper.IsSet("issues.linear") {
+		trackers.Linear = &wf.LinearTracker{
+			TokenEnv: viper.GetString("issues.linear.token_env"),
+			Team:     viper.GetString("issues.linear.team"),
+			APIURL:   viper.GetString("issues.linear.api_url"),
+		}
+	}
+	return trackers
+}
+
+// applyIssueConfig gives a workflow the configured issue trackers it
+// doesn't configure itself. A dry run in either place wins.
+func applyIssueConfig(w *wf.Workflow) {
+	configured := issuesFromConfig()
+	if configured == nil {
+		return
+	}
+	if w.Settings.Issues == nil {
+		w.Settings.Issues = configured
+		return
+	}
+	if w.Settings.Issues.Jira == nil {
+		w.Settings.Issues.Jira = configured.Jira
+	}
+	if w.Settings.Issues.Linear == nil {
+		w.Settings.Issues.Linear = configured.Linear
+	}
+	w.Settings.Issues.DryRun = w.Settings.Issues.DryRun || configured.DryRun
+}
@@ -0,0 +1,653 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// launcherKind identifies what a launcher entry runs
+type launcherKind string
+
+const (
+	launcherWorkflow launcherKind = "workflow"
+	launcherPrompt   launcherKind = "prompt"
+	launcherAction   launcherKind = "action"
+	launcherSubAgent launcherKind = "subagent"
+)
+
+// launcherItem is a single runnable entry shown in the launcher
+type launcherItem struct {
+	kind        launcherKind
+	name        string
+	description string
+	preview     string
+	// loadPreview loads the preview when it is first shown, for items whose
+	// preview is expensive to read
+	loadPreview func() string
+}
+
+// launcherMatch is a launcher item that matched the current query
+type launcherMatch struct {
+	item  launcherItem
+	score int
+}
+
+// GoCmd creates the interactive launcher command
+func GoCmd() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:   "go [query]",
+		Short: "Fuzzy-find and run a workflow, prompt, action, or subagent",
+		Long: `Open an interactive launcher listing everything Opun can run: workflows, prompts,
+actions, and subagents. Type to fuzzy-search, inspect the preview, and press Enter to run.
+
+Prompts are rendered and sent to a new chat session on the default provider
+(or the one given with --provider). Subagents ask for a task before executing.
+
+Examples:
+  opun go                  # Open the launcher
+  opun go review           # Open the launcher pre-filtered with "review"
+  opun go -p gemini        # Send selected prompts to Gemini`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := collectLauncherItems()
+			if err != nil {
+				return err
+			}
+
+			if len(items) == 0 {
+				return fmt.Errorf("nothing to run yet. Add workflows, prompts, or actions using 'opun add'")
+			}
+
+			selected, err := selectLauncherItem(items, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+
+			return runLauncherItem(cmd, selected, provider)
+		},
+	}
+
+	cmd.Flags().StringVarP(&provider, "provider", "p", "", "provider to use when running prompts (default from config)")
+
+	return cmd
+}
+
+// collectLauncherItems gathers every runnable item from the Opun directories
+func collectLauncherItems() ([]launcherItem, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	opunDir := filepath.Join(home, ".opun")
+	var items []launcherItem
+
+	// Workflows
+	workflows, err := getAvailableWorkflows()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range workflows {
+		preview := w.description
+		if data, err := os.ReadFile(w.path); err == nil {
+			preview = string(data)
+		}
+		items = append(items, launcherItem{
+			kind:        launcherWorkflow,
+			name:        w.name,
+			description: w.description,
+			preview:     preview,
+		})
+	}
+
+	// Prompts
+	gardenPath := filepath.Join(opunDir, "promptgarden")
+	if _, err := os.Stat(gardenPath); err == nil {
+		if garden, err := promptgarden.NewGarden(gardenPath); err == nil {
+			for _, p := range garden.Summaries() {
+				// Skip templates, they are only meant to be included
+				if strings.HasSuffix(p.Name, "-template") {
+					continue
+				}
+				items = append(items, launcherItem{
+					kind:        launcherPrompt,
+					name:        p.Name,
+					description: p.Description,
+					loadPreview: lazyPromptContent(garden, p.ID),
+				})
+			}
+		}
+	}
+
+	// Actions
+	actionsDir := filepath.Join(opunDir, "actions")
+	if _, err := os.Stat(actionsDir); err == nil {
+		loader := tools.NewLoader(actionsDir)
+		if err := loader.LoadAll(); err == nil {
+			for _, action := range loader.GetRegistry().List("") {
+				items = append(items, launcherItem{
+					kind:        launcherAction,
+					name:        action.ID,
+					description: action.Description,
+					preview:     actionPreview(action),
+				})
+			}
+		}
+	}
+
+	// Subagents
+	for _, agent := range GetSubAgentManager().List() {
+		config := agent.Config()
+		var preview strings.Builder
+		fmt.Fprintf(&preview, "Provider: %s\n", agent.Provider())
+		if config.Model != "" {
+			fmt.Fprintf(&preview, "Model: %s\n", config.Model)
+		}
+		if caps := agent.GetCapabilities(); len(caps) > 0 {
+			fmt.Fprintf(&preview, "Capabilities: %s\n", strings.Join(caps, ", "))
+		}
+		if config.SystemPrompt != "" {
+			fmt.Fprintf(&preview, "\n%s\n", config.SystemPrompt)
+		}
+		items = append(items, launcherItem{
+			kind:        launcherSubAgent,
+			name:        agent.Name(),
+			description: config.Description,
+			preview:     preview.String(),
+		})
+	}
+
+	return items, nil
+}
+
+// lazyPromptContent reads a prompt's content the first time it is asked for,
+// so the launcher only loads the prompts that are previewed
+func lazyPromptContent(garden *promptgarden.Garden, id string) func() string {
+	var (
+		once    sync.Once
+		content string
+	)
+	return func() string {
+		once.Do(func() {
+			if prompt, err := garden.Get(id); err == nil {
+				content = prompt.Content()
+			}
+		})
+		return content
+	}
+}
+
+// actionPreview describes what an action will execute
+func actionPreview(action core.StandardAction) string {
+	var preview strings.Builder
+	switch {
+	case len(action.Steps) > 0:
+		preview.WriteString("Steps:\n")
+		for i, step := range action.Steps {
+			fmt.Fprintf(&preview, "  %d. %s\n", i+1, step.Command)
+		}
+	case action.Command != "":
+		fmt.Fprintf(&preview, "Command: %s\n", action.Command)
+	case action.WorkflowRef != "":
+		fmt.Fprintf(&preview, "Workflow: %s\n", action.WorkflowRef)
+	case action.PromptRef != "":
+		fmt.Fprintf(&preview, "Prompt: %s\n", action.PromptRef)
+	}
+	if action.Category != "" {
+		fmt.Fprintf(&preview, "Category: %s\n", action.Category)
+	}
+	if action.Description != "" {
+		fmt.Fprintf(&preview, "\n%s\n", action.Description)
+	}
+	return preview.String()
+}
+
+// fuzzyScore scores how well pattern matches text as an ordered subsequence.
+// Consecutive runs, word starts and a leading match are rewarded. The second
+// return value is false if pattern is not a subsequence of text.
+func fuzzyScore(text, pattern string) (int, bool) {
+	text = strings.ToLower(text)
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return 0, true
+	}
+
+	score := 0
+	patternIdx := 0
+	lastMatch := -1
+	for i := 0; i < len(text) && patternIdx < len(pattern); i++ {
+		if text[i] != pattern[patternIdx] {
+			continue
+		}
+
+		score++
+		if lastMatch == i-1 {
+			score += 3
+		}
+		if i == 0 {
+			score += 5
+		} else if strings.ContainsRune(" -_/.", rune(text[i-1])) {
+			score += 2
+		}
+		lastMatch = i
+		patternIdx++
+	}
+
+	if patternIdx < len(pattern) {
+		return 0, false
+	}
+
+	// Prefer shorter names when scores tie
+	return score*100 - len(text), true
+}
+
+// filterLauncherItems returns the items matching query, best match first
+func filterLauncherItems(items []launcherItem, query string) []launcherMatch {
+	var matches []launcherMatch
+	for _, item := range items {
+		score, ok := fuzzyScore(item.name, query)
+		if !ok {
+			// Fall back to the kind-qualified name so "wf review" style queries work
+			score, ok = fuzzyScore(string(item.kind)+" "+item.name, query)
+			if !ok {
+				continue
+			}
+			score -= 50
+		}
+		matches = append(matches, launcherMatch{item: item, score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if matches[i].item.kind != matches[j].item.kind {
+			return matches[i].item.kind < matches[j].item.kind
+		}
+		return matches[i].item.name < matches[j].item.name
+	})
+
+	return matches
+}
+
+// launcherModel is the fuzzy finder TUI model
+type launcherModel struct {
+	textInput textinput.Model
+	items     []launcherItem
+	matches   []launcherMatch
+	selected  int
+	width     int
+	height    int
+	choice    *launcherItem
+	quitting  bool
+}
+
+func initialLauncherModel(items []launcherItem, query string) launcherModel {
+	ti := textinput.New()
+	ti.Placeholder = "Type to search workflows, prompts, actions, subagents..."
+	ti.Focus()
+	ti.CharLimit = 200
+	ti.Width = 50
+	ti.SetValue(query)
+
+	return launcherModel{
+		textInput: ti,
+		items:     items,
+		matches:   filterLauncherItems(items, query),
+		width:     100,
+		height:    24,
+	}
+}
+
+func (m launcherModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m launcherModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.quitting = true
+			return m, tea.Quit
+		case tea.KeyEnter:
+			if m.selected >= 0 && m.selected < len(m.matches) {
+				item := m.matches[m.selected].item
+				m.choice = &item
+				return m, tea.Quit
+			}
+			return m, nil
+		case tea.KeyUp, tea.KeyCtrlP:
+			if m.selected > 0 {
+				m.selected--
+			}
+			return m, nil
+		case tea.KeyDown, tea.KeyCtrlN:
+			if m.selected < len(m.matches)-1 {
+				m.selected++
+			}
+			return m, nil
+		}
+	}
+
+	oldValue := m.textInput.Value()
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+
+	if m.textInput.Value() != oldValue {
+		m.matches = filterLauncherItems(m.items, m.textInput.Value())
+		m.selected = 0
+	}
+
+	return m, cmd
+}
+
+func (m launcherModel) View() string {
+	if m.quitting || m.choice != nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("230")).
+		Padding(0, 1)
+
+	inputStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1)
+
+	kindStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("230"))
+
+	paneStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(0, 1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	// Leave room for the title, input box, help line and pane borders
+	bodyHeight := m.height - 10
+	if bodyHeight < 5 {
+		bodyHeight = 5
+	}
+	listWidth := m.width/2 - 4
+	if listWidth < 30 {
+		listWidth = 30
+	}
+	previewWidth := m.width - listWidth - 8
+	if previewWidth < 20 {
+		previewWidth = 20
+	}
+
+	// Keep the selection in view
+	start := 0
+	if m.selected >= bodyHeight {
+		start = m.selected - bodyHeight + 1
+	}
+
+	var rows []string
+	for i := start; i < len(m.matches) && i < start+bodyHeight; i++ {
+		item := m.matches[i].item
+		kind := fmt.Sprintf("%-10s", "["+string(item.kind)+"]")
+		name := truncate(item.name, listWidth-len(kind)-1)
+		if i == m.selected {
+			rows = append(rows, selectedStyle.Render(kind+" "+name))
+		} else {
+			rows = append(rows, kindStyle.Render(kind)+" "+name)
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, helpStyle.Render("No matches"))
+	}
+
+	var preview string
+	if m.selected >= 0 && m.selected < len(m.matches) {
+		item := m.matches[m.selected].item
+		var lines []string
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render(item.name))
+		if item.description != "" {
+			lines = append(lines, helpStyle.Render(truncate(item.description, previewWidth)))
+		}
+		lines = append(lines, "")
+		text := item.preview
+		if text == "" && item.loadPreview != nil {
+			text = item.loadPreview()
+		}
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			if len(lines) >= bodyHeight {
+				lines = append(lines, helpStyle.Render("..."))
+				break
+			}
+			lines = append(lines, truncate(line, previewWidth))
+		}
+		preview = strings.Join(lines, "\n")
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		paneStyle.Width(listWidth).Height(bodyHeight).Render(strings.Join(rows, "\n")),
+		paneStyle.Width(previewWidth).Height(bodyHeight).Render(preview),
+	)
+
+	return fmt.Sprintf("%s\n\n%s\n%s\n%s",
+		titleStyle.Render(fmt.Sprintf("Opun • %d/%d", len(m.matches), len(m.items))),
+		inputStyle.Render(m.textInput.View()),
+		body,
+		helpStyle.Render("(Type to search • ↑↓ to navigate • Enter to run • Esc to cancel)"),
+	)
+}
+
+// selectLauncherItem runs the launcher TUI and returns the chosen item
+func selectLauncherItem(items []launcherItem, query string) (launcherItem, error) {
+	if utils.PlainUI() {
+		return selectLauncherItemPlain(items, query)
+	}
+
+	p := tea.NewProgram(initialLauncherModel(items, query), tea.WithAltScreen())
+	result, err := p.Run()
+	if err != nil {
+		return launcherItem{}, err
+	}
+
+	if m, ok := result.(launcherModel); ok {
+		if m.choice != nil {
+			return *m.choice, nil
+		}
+		if m.quitting {
+			return launcherItem{}, fmt.Errorf("launcher cancelled")
+		}
+	}
+
+	return launcherItem{}, fmt.Errorf("nothing selected")
+}
+
+// selectLauncherItemPlain asks for a search and offers the matches as a
+// numbered menu; an empty search lists everything
+func selectLauncherItemPlain(items []launcherItem, query string) (launcherItem, error) {
+	prompter := utils.StdPrompter()
+	for {
+		search, err := prompter.Ask("Search", query)
+		if err != nil {
+			return launcherItem{}, err
+		}
+		matches := filterLauncherItems(items, search)
+		if len(matches) == 0 {
+			fmt.Println("No matches")
+			query = ""
+			continue
+		}
+
+		labels := make([]string, len(matches))
+		for i, match := range matches {
+			labels[i] = fmt.Sprintf("[%s] %s", match.item.kind, match.item.name)
+			if match.item.description != "" {
+				labels[i] += " - " + match.item.description
+			}
+		}
+		i, err := prompter.Select(fmt.Sprintf("%d/%d matches", len(matches), len(items)), labels)
+		if err != nil {
+			return launcherItem{}, err
+		}
+		return matches[i].item, nil
+	}
+}
+
+// runLauncherItem runs the selected launcher item
+func runLauncherItem(cmd *cobra.Command, item launcherItem, provider string) error {
+	switch item.kind {
+	case launcherWorkflow:
+		return runWorkflow(item.name, map[string]string{}, "", "", false)
+
+	case launcherAction:
+		return runAction(item.name, "")
+
+	case launcherPrompt:
+		return runLauncherPrompt(cmd, item.name, provider)
+
+	case launcherSubAgent:
+		task, err := Prompt(fmt.Sprintf("Task for subagent '%s':", item.name))
+		if err != nil {
+			return err
+		}
+		if task == "" {
+			return fmt.Errorf("no task given")
+		}
+		return executeLauncherSubAgent(cmd, item.name, task)
+	}
+
+	return fmt.Errorf("unknown launcher item type: %s", item.kind)
+}
+
+// runLauncherPrompt renders a prompt, asking for any missing required
+// variables, and starts a chat session with it
+func runLauncherPrompt(cmd *cobra.Command, name, provider string) error {
+	if provider == "" {
+		provider = viper.GetString("default_provider")
+		if provider == "" {
+			return fmt.Errorf("no provider specified and no default provider configured. Run 'opun setup' to configure a default provider")
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+	if err != nil {
+		return fmt.Errorf("failed to access prompt garden: %w", err)
+	}
+
+	prompt, err := garden.GetByName(name)
+	if err != nil {
+		return fmt.Errorf("prompt not found: %s", name)
+	}
+
+	vars := make(map[string]interface{})
+	for _, v := range prompt.Variables() {
+		if !v.Required || v.DefaultValue != nil {
+			continue
+		}
+		question := v.Name
+		if v.Description != "" {
+			question = fmt.Sprintf("%s (%s)", v.Name, v.Description)
+		}
+		value, err := Prompt(question + ":")
+		if err != nil {
+			return err
+		}
+		vars[v.Name] = value
+	}
+
+	rendered, err := garden.Execute(name, vars)
+	if err != nil {
+		return fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	return runChat(cmd, provider, promptLaunchArgs(provider, rendered))
+}
+
+// promptLaunchArgs returns the provider arguments that start an interactive
+// session seeded with the given prompt
+func promptLaunchArgs(provider, prompt string) []string {
+	switch strings.ToLower(provider) {
+	case "gemini", "qwen":
+		return []string{"--prompt-interactive", prompt}
+	default:
+		return []string{prompt}
+	}
+}
+
+// executeLauncherSubAgent executes a single task on a named subagent
+func executeLauncherSubAgent(cmd *cobra.Command, name, taskContent string) error {
+	task := core.SubAgentTask{
+		ID:          fmt.Sprintf("task-%d", time.Now().Unix()),
+		Name:        "Launcher Task",
+		Description: taskContent,
+		Input:       taskContent,
+		Priority:    1,
+		Context:     make(map[string]interface{}),
+		Variables:   make(map[string]interface{}),
+	}
+
+	fmt.Printf("🚀 Executing task on subagent '%s'...\n", name)
+
+	result, err := GetSubAgentManager().Execute(cmd.Context(), task, name)
+	if err != nil {
+		return fmt.Errorf("task execution failed: %w", err)
+	}
+
+	if result.Output != "" {
+		fmt.Printf("\n📝 Output:\n%s\n", result.Output)
+	}
+	if result.Error != nil {
+		fmt.Printf("\n❌ Error: %v\n", result.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	t.Run("Empty Pattern Matches", func(t *testing.T) {
+		_, ok := fuzzyScore("anything", "")
+		assert.True(t, ok)
+	})
+
+	t.Run("Subsequence Matches", func(t *testing.T) {
+		_, ok := fuzzyScore("code-review", "crv")
+		assert.True(t, ok)
+
+		_, ok = fuzzyScore("code-review", "xyz")
+		assert.False(t, ok)
+	})
+
+	t.Run("Case Insensitive", func(t *testing.T) {
+		_, ok := fuzzyScore("Code-Review", "CODE")
+		assert.True(t, ok)
+	})
+
+	t.Run("Prefix Beats Scattered", func(t *testing.T) {
+		prefix, _ := fuzzyScore("review", "rev")
+		scattered, _ := fuzzyScore("refactor-everything-v", "rev")
+		assert.Greater(t, prefix, scattered)
+	})
+}
+
+func TestFilterLauncherItems(t *testing.T) {
+	items := []launcherItem{
+		{kind: launcherWorkflow, name: "code-review"},
+		{kind: launcherPrompt, name: "review"},
+		{kind: launcherAction, name: "run-tests"},
+		{kind: launcherSubAgent, name: "security-auditor"},
+	}
+
+	t.Run("Empty Query Returns All", func(t *testing.T) {
+		matches := filterLauncherItems(items, "")
+		assert.Len(t, matches, len(items))
+	})
+
+	t.Run("Best Match First", func(t *testing.T) {
+		matches := filterLauncherItems(items, "review")
+		if assert.Len(t, matches, 2) {
+			assert.Equal(t, "review", matches[0].item.name)
+			assert.Equal(t, "code-review", matches[1].item.name)
+		}
+	})
+
+	t.Run("Kind Qualified Query", func(t *testing.T) {
+		matches := filterLauncherItems(items, "action tests")
+		if assert.NotEmpty(t, matches) {
+			assert.Equal(t, "run-tests", matches[0].item.name)
+		}
+	})
+
+	t.Run("No Matches", func(t *testing.T) {
+		assert.Empty(t, filterLauncherItems(items, "zzz"))
+	})
+}
+
+func TestPromptLaunchArgs(t *testing.T) {
+	assert.Equal(t, []string{"hello"}, promptLaunchArgs("claude", "hello"))
+	assert.Equal(t, []string{"--prompt-interactive", "hello"}, promptLaunchArgs("gemini", "hello"))
+	assert.Equal(t, []string{"--prompt-interactive", "hello"}, promptLaunchArgs("qwen", "hello"))
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/script"
 	"github.com/rizome-dev/opun/internal/tools"
 	"github.com/spf13/cobra"
 )
@@ -33,6 +34,7 @@ func ListCmd() *cobra.Command {
 		listWorkflows bool
 		listPrompts   bool
 		listActions   bool
+		listScripts   bool
 		listAll       bool
 	)
 
@@ -41,8 +43,10 @@ func ListCmd() *cobra.Command {
 		Short: "List available workflows, prompts, and tools",
 		Long:  `List all available workflows, prompts, and tools that can be used with Opun.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			defer pageOutput()()
+
 			// Default to listing all if no specific flag is set
-			if !listWorkflows && !listPrompts && !listActions {
+			if !listWorkflows && !listPrompts && !listActions && !listScripts {
 				listAll = true
 			}
 
@@ -72,6 +76,16 @@ func ListCmd() *cobra.Command {
 				if err := showActions(); err != nil {
 					return err
 				}
+				shown = true
+			}
+
+			if listAll || listScripts {
+				if shown {
+					fmt.Println() // Add spacing
+				}
+				if err := showScripts(); err != nil {
+					return err
+				}
 			}
 
 			return nil
@@ -82,6 +96,7 @@ func ListCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&listWorkflows, "workflows", "w", false, "List only workflows")
 	cmd.Flags().BoolVarP(&listPrompts, "prompts", "p", false, "List only prompts")
 	cmd.Flags().BoolVarP(&listActions, "actions", "a", false, "List only actions")
+	cmd.Flags().BoolVarP(&listScripts, "scripts", "s", false, "List only hook scripts")
 
 	return cmd
 }
@@ -171,19 +186,16 @@ func showPrompts() error {
 		return fmt.Errorf("failed to access prompt garden: %w", err)
 	}
 
-	// List prompts
-	prompts, err := garden.ListPrompts()
-	if err != nil {
-		return fmt.Errorf("failed to list prompts: %w", err)
-	}
+	// List prompts from the index, without reading their content
+	prompts := garden.Summaries()
 
 	fmt.Println("🌱 Prompts:")
 	fmt.Println(strings.Repeat("-", 50))
 
 	// Group by category
-	categories := make(map[string][]*promptgarden.Prompt)
+	categories := make(map[string][]promptgarden.Summary)
 	for _, prompt := range prompts {
-		category := prompt.Metadata.Category
+		category := prompt.Category
 		if category == "" {
 			category = "uncategorized"
 		}
@@ -194,7 +206,7 @@ func showPrompts() error {
 	for category, categoryPrompts := range categories {
 		fmt.Printf("\n  %s:\n", strings.Title(category))
 		for _, prompt := range categoryPrompts {
-			desc := prompt.Metadata.Description
+			desc := prompt.Description
 			if desc == "" {
 				desc = "No description"
 			}
@@ -205,8 +217,8 @@ func showPrompts() error {
 			fmt.Printf("    promptgarden://%s - %s\n", prompt.ID, desc)
 
 			// Show tags if any
-			if len(prompt.Metadata.Tags) > 0 {
-				fmt.Printf("      Tags: %s\n", strings.Join(prompt.Metadata.Tags, ", "))
+			if len(prompt.Tags) > 0 {
+				fmt.Printf("      Tags: %s\n", strings.Join(prompt.Tags, ", "))
 			}
 		}
 	}
@@ -284,3 +296,32 @@ func showActions() error {
 	fmt.Printf("\nTotal: %d tool(s)\n", len(actionList))
 	return nil
 }
+
+// showScripts lists the hook scripts in ~/.opun/scripts
+func showScripts() error {
+	dir, err := script.Dir()
+	if err != nil {
+		return err
+	}
+
+	names, err := script.List(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list scripts: %w", err)
+	}
+
+	fmt.Println("📜 Scripts:")
+	fmt.Println(strings.Repeat("-", 50))
+	if len(names) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, name := range names {
+		if _, err := script.LoadFrom(dir, name); err != nil {
+			fmt.Printf("  %s ⚠️  %v\n", name, err)
+			continue
+		}
+		fmt.Printf("  %s\n", name)
+	}
+
+	fmt.Printf("\nTotal: %d script(s)\n", len(names))
+	return nil
+}
@@ -0,0 +1,441 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// MapIndexFile is the index a map run writes next to its outputs
+const MapIndexFile = "index.json"
+
+// mapRunner runs one rendered prompt headlessly
+type mapRunner func(ctx context.Context, prompt string) (string, error)
+
+// mapRenderer renders the prompt for one input file
+type mapRenderer func(path, content string) (promptgarden.Rendered, error)
+
+// mapJob is one input file and where its answer goes
+type mapJob struct {
+	Input  string
+	Output string
+}
+
+// mapResult is the outcome of one input
+type mapResult struct {
+	Input    string  `json:"input"`
+	Output   string  `json:"output,omitempty"`
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_seconds"`
+	// Variant is the prompt variant the input was run with
+	Variant string `json:"variant,omitempty"`
+}
+
+// mapIndex is written to the output directory once a map run ends
+type mapIndex struct {
+	Prompt   string      `json:"prompt"`
+	Provider string      `json:"provider"`
+	Model    string      `json:"model,omitempty"`
+	Started  time.Time   `json:"started"`
+	Results  []mapResult `json:"results"`
+}
+
+// MapCmd creates the map command
+func MapCmd() *cobra.Command {
+	var (
+		promptName  string
+		inputGlob   string
+		outputDir   string
+		concurrency int
+		provider    string
+		model       string
+		vars        map[string]string
+		extension   string
+		force       bool
+		timeout     time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "map",
+		Short: "Run a prompt on every file matching a glob",
+		Long: `Run a prompt garden prompt headlessly once per input file and write one
+answer per input to the output directory, mirroring the inputs' layout below
+the glob's fixed prefix. The prompt gets the file in the input variable, its
+path in input_path and its name in input_name; when it doesn't use input the
+file is appended to the prompt.
+
+Inputs whose output already exists are skipped, so an interrupted run can be
+resumed; pass --force to redo them. An index.json in the output directory
+lists every input with its status and output file.`,
+		Example: `  opun map --prompt summarize --input-glob "docs/*.md" --output-dir summaries/ --concurrency 4
+  opun map --prompt review --input-glob "internal/**/*.go" --output-dir reviews --provider gemini`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if promptName == "" || inputGlob == "" || outputDir == "" {
+				return fmt.Errorf("--prompt, --input-glob and --output-dir are required")
+			}
+			if provider == "" {
+				provider = viper.GetString("default_provider")
+			}
+			if provider == "" {
+				provider = "claude"
+			}
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+			if err != nil {
+				return fmt.Errorf("failed to access prompt garden: %w", err)
+			}
+			if _, err := lookupPrompt(garden, promptName); err != nil {
+				return fmt.Errorf("prompt not found: %s", promptName)
+			}
+
+			jobs, err := mapJobs(inputGlob, outputDir, extension)
+			if err != nil {
+				return err
+			}
+			if len(jobs) == 0 {
+				return fmt.Errorf("no files match %s", inputGlob)
+			}
+
+			var pending []mapJob
+			var results []mapResult
+			for _, job := range jobs {
+				if _, err := os.Stat(job.Output); err == nil && !force {
+					results = append(results, mapResult{Input: job.Input, Output: job.Output, Status: "skipped"})
+					continue
+				}
+				pending = append(pending, job)
+			}
+
+			workDir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			render := func(path, content string) (promptgarden.Rendered, error) {
+				return renderMapPrompt(garden, promptName, vars, path, content)
+			}
+			run := func(ctx context.Context, prompt string) (string, error) {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return providers.RunHeadless(ctx, provider, model, prompt, workDir)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			fmt.Printf("🗺️  Mapping %s over %d files with %s", promptName, len(pending), provider)
+			if skipped := len(jobs) - len(pending); skipped > 0 {
+				fmt.Printf(" (%d already done)", skipped)
+			}
+			fmt.Println()
+
+			// The progress bar owns the terminal while it runs, so failures
+			// are listed once it's done
+			var progress *Progress
+			var done func(mapResult)
+			finished := 0
+			if !utils.PlainUI() && len(pending) > 0 {
+				progress = NewProgress(fmt.Sprintf("Mapping %s", promptName))
+				done = func(mapResult) {
+					finished++
+					progress.Update(float64(finished) / float64(len(pending)))
+				}
+			} else {
+				done = func(result mapResult) {
+					finished++
+					icon := "✅"
+					if result.Status != "completed" {
+						icon = "❌"
+					}
+					fmt.Printf("%s [%d/%d] %s (%.1fs)\n", icon, finished, len(pending), result.Input, result.Duration)
+				}
+			}
+
+			started := time.Now()
+			results = append(results, runMap(ctx, pending, concurrency, render, run, done)...)
+			if progress != nil {
+				progress.Done()
+			}
+			sort.Slice(results, func(i, j int) bool { return results[i].Input < results[j].Input })
+			recordMapOutcomes(ctx, promptName, provider, model, results)
+
+			index := mapIndex{Prompt: promptName, Provider: provider, Model: model, Started: started, Results: results}
+			if err := writeMapIndex(outputDir, index); err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, result := range results {
+				if result.Status == "failed" {
+					failed++
+					if progress != nil {
+						fmt.Printf("❌ %s: %s\n", result.Input, result.Error)
+					}
+				}
+			}
+			fmt.Printf("📁 Outputs: %s (index: %s)\n", outputDir, filepath.Join(outputDir, MapIndexFile))
+
+			if ctx.Err() != nil {
+				return fmt.Errorf("interrupted, rerun to finish the remaining files")
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d files failed", failed, len(pending))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&promptName, "prompt", "p", "", "prompt garden prompt to run on each file")
+	cmd.Flags().StringVarP(&inputGlob, "input-glob", "i", "", "files to process, e.g. \"docs/**/*.md\"")
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "directory to write one output per input to")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 4, "files to process at once")
+	cmd.Flags().StringVar(&provider, "provider", "", "provider to run the prompt on (default: default_provider)")
+	cmd.Flags().StringVar(&model, "model", "", "model to use")
+	cmd.Flags().StringToStringVarP(&vars, "var", "v", map[string]string{}, "extra prompt variables (key=value)")
+	cmd.Flags().StringVar(&extension, "ext", ".md", "extension of the output files")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "redo inputs whose output already exists")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "maximum time for each file")
+
+	return cmd
+}
+
+// mapJobs expands the input glob and pairs every file with its output path
+func mapJobs(pattern, outputDir, extension string) ([]mapJob, error) {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	re, err := utils.GlobRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	base := globBase(pattern)
+	outputAbs, _ := filepath.Abs(outputDir)
+
+	var jobs []mapJob
+	err = filepath.WalkDir(filepath.FromSlash(base), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := filepath.ToSlash(path)
+		if d.IsDir() {
+			if rel != base && (strings.HasPrefix(d.Name(), ".") || skippedMapDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			// Outputs from an earlier run are never inputs
+			if abs, _ := filepath.Abs(path); abs == outputAbs {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !re.MatchString(rel) {
+			return nil
+		}
+
+		out := strings.TrimPrefix(rel, base)
+		if base == "." {
+			out = rel
+		}
+		out = strings.TrimPrefix(out, "/")
+		out = strings.TrimSuffix(out, filepath.Ext(out)) + extension
+		jobs = append(jobs, mapJob{Input: rel, Output: filepath.Join(outputDir, filepath.FromSlash(out))})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", pattern, err)
+	}
+	return jobs, nil
+}
+
+// skippedMapDirs are never searched for inputs, on top of hidden directories
+var skippedMapDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// globBase returns the directories of a glob before its first wildcard,
+// e.g. "docs/api" for "docs/api/**/*.md", or "." when it starts with one
+func globBase(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	var fixed []string
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+		fixed = append(fixed, part)
+	}
+	if len(fixed) == 0 {
+		return "."
+	}
+	return strings.Join(fixed, "/")
+}
+
+// renderMapPrompt renders the prompt for one input, appending the file when
+// the prompt doesn't reference it
+func renderMapPrompt(garden *promptgarden.Garden, name string, extra map[string]string, path, content string) (promptgarden.Rendered, error) {
+	// The template engine reparses substituted text, so the file goes in
+	// after rendering to keep braces in it from being read as template syntax
+	vars := map[string]interface{}{
+		"input":      mapInputPlaceholder,
+		"input_path": path,
+		"input_name": filepath.Base(path),
+	}
+	for k, v := range extra {
+		vars[k] = v
+	}
+
+	rendered, err := garden.ExecuteVariant(name, vars)
+	if err != nil {
+		return promptgarden.Rendered{}, err
+	}
+	if !strings.Contains(rendered.Text, mapInputPlaceholder) {
+		rendered.Text += fmt.Sprintf("\n\n%s:\n```\n%s\n```", path, strings.TrimRight(content, "\n"))
+		return rendered, nil
+	}
+	rendered.Text = strings.ReplaceAll(rendered.Text, mapInputPlaceholder, content)
+	return rendered, nil
+}
+
+// mapInputPlaceholder stands in for the input file while a prompt renders
+const mapInputPlaceholder = "\x00opun-map-input\x00"
+
+// runMap runs the prompt on every job, at most concurrency at a time, and
+// writes each answer to the job's output file. done is called as each job
+// finishes. Results are returned in job order.
+func runMap(ctx context.Context, jobs []mapJob, concurrency int, render mapRenderer, run mapRunner, done func(mapResult)) []mapResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]mapResult, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job mapJob) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = runMapJob(ctx, job, render, run)
+			mu.Lock()
+			done(results[i])
+			mu.Unlock()
+		}(i, job)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runMapJob runs the prompt on one input file
+func runMapJob(ctx context.Context, job mapJob, render mapRenderer, run mapRunner) mapResult {
+	start := time.Now()
+	result := mapResult{Input: job.Input, Status: "completed"}
+
+	fail := func(err error) mapResult {
+		result.Status = "failed"
+		result.Error = err.Error()
+		result.Duration = time.Since(start).Seconds()
+		return result
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	content, err := os.ReadFile(job.Input)
+	if err != nil {
+		return fail(err)
+	}
+	prompt, err := render(job.Input, string(content))
+	if err != nil {
+		return fail(fmt.Errorf("failed to render prompt: %w", err))
+	}
+	result.Variant = prompt.Variant
+
+	output, err := run(ctx, prompt.Text)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(job.Output), 0755); err != nil {
+		return fail(err)
+	}
+	if err := os.WriteFile(job.Output, []byte(output+"\n"), 0644); err != nil {
+		return fail(err)
+	}
+
+	result.Output = job.Output
+	result.Duration = time.Since(start).Seconds()
+	return result
+}
+
+// recordMapOutcomes adds the inputs that ran to the prompt history. Inputs
+// cut short by an interrupt say nothing about the prompt and are left out.
+func recordMapOutcomes(ctx context.Context, prompt, provider, model string, results []mapResult) {
+	for _, result := range results {
+		if result.Status == "skipped" || (result.Status == "failed" && ctx.Err() != nil) {
+			continue
+		}
+		recordPromptOutcome(promptgarden.Outcome{
+			Prompt:   prompt,
+			Variant:  result.Variant,
+			Command:  "map",
+			Provider: provider,
+			Model:    model,
+			Success:  result.Status == "completed",
+			Duration: result.Duration,
+			Error:    result.Error,
+		})
+	}
+}
+
+// writeMapIndex writes the index of a map run to dir/index.json
+func writeMapIndex(dir string, index mapIndex) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, MapIndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
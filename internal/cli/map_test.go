@@ -0,0 +1,124 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobBase(t *testing.T) {
+	assert.Equal(t, "docs", globBase("docs/*.md"))
+	assert.Equal(t, "docs/api", globBase("docs/api/**/*.md"))
+	assert.Equal(t, ".", globBase("**/*.md"))
+	assert.Equal(t, ".", globBase("*.md"))
+}
+
+func TestMapJobs(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	for _, path := range []string{"docs/a.md", "docs/guide/b.md", "docs/c.txt", "docs/.drafts/d.md", "summaries/docs/a.md"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(path), 0644))
+	}
+
+	jobs, err := mapJobs("docs/**/*.md", "summaries", ".txt")
+	require.NoError(t, err)
+	assert.Equal(t, []mapJob{
+		{Input: "docs/a.md", Output: filepath.Join("summaries", "a.txt")},
+		{Input: "docs/guide/b.md", Output: filepath.Join("summaries", "guide", "b.txt")},
+	}, jobs)
+
+	// The output directory is left out even when the glob covers it
+	jobs, err = mapJobs("**/*.md", "summaries", ".md")
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, filepath.Join("summaries", "docs", "a.md"), jobs[0].Output)
+}
+
+func TestRunMap(t *testing.T) {
+	dir := t.TempDir()
+	var jobs []mapJob
+	for _, name := range []string{"a", "b", "c", "d", "fail"} {
+		input := filepath.Join(dir, name+".txt")
+		require.NoError(t, os.WriteFile(input, []byte("content of "+name), 0644))
+		jobs = append(jobs, mapJob{Input: input, Output: filepath.Join(dir, "out", name+".md")})
+	}
+
+	render := func(path, content string) (promptgarden.Rendered, error) {
+		return promptgarden.Rendered{Text: "summarize: " + content}, nil
+	}
+	var running, maxRunning int32
+	run := func(ctx context.Context, prompt string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if strings.Contains(prompt, "fail") {
+			return "", errors.New("provider failed")
+		}
+		return "summary of " + strings.TrimPrefix(prompt, "summarize: "), nil
+	}
+
+	var done int
+	results := runMap(context.Background(), jobs, 2, render, run, func(mapResult) { done++ })
+	require.Len(t, results, 5)
+	assert.Equal(t, 5, done)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning), "concurrency should be bounded")
+
+	assert.Equal(t, "completed", results[0].Status)
+	content, err := os.ReadFile(results[0].Output)
+	require.NoError(t, err)
+	assert.Equal(t, "summary of content of a\n", string(content))
+
+	assert.Equal(t, "failed", results[4].Status)
+	assert.Equal(t, "provider failed", results[4].Error)
+	assert.NoFileExists(t, jobs[4].Output)
+}
+
+func TestRenderMapPrompt(t *testing.T) {
+	garden, err := promptgarden.NewGarden(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{ID: "summarize", Name: "summarize", Content: "Summarize {{input_name}} for {{audience}}:\n{{input}}"}))
+	require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{ID: "review", Name: "review", Content: "Review this file."}))
+
+	rendered, err := renderMapPrompt(garden, "summarize", map[string]string{"audience": "users"}, "docs/a.md", "Hello {{name}}")
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize a.md for users:\nHello {{name}}", rendered.Text)
+
+	rendered, err = renderMapPrompt(garden, "review", nil, "main.go", "package main")
+	require.NoError(t, err)
+	assert.Equal(t, "Review this file.\n\nmain.go:\n```\npackage main\n```", rendered.Text)
+	assert.Empty(t, rendered.Variant)
+}
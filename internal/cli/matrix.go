@@ -0,0 +1,254 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/run"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// runMatrix runs a workflow headlessly for every combination of its matrix,
+// reporting it to ci when set
+func runMatrix(name string, vars map[string]string, parallel int, copyFinal bool, ci *githubActions) error {
+	wf, err := loadWorkflow(name)
+	if err != nil {
+		return fmt.Errorf("failed to load workflow: %w", err)
+	}
+	if wf.Matrix == nil {
+		return fmt.Errorf("workflow %s has no matrix section", wf.Name)
+	}
+	applyStorageConfig(wf)
+	applyIssueConfig(wf)
+	applyEmailConfig(wf)
+
+	if err := ensureWorkflowRequirements(wf); err != nil {
+		return err
+	}
+
+	policy, err := loadPromptPolicy()
+	if err != nil {
+		return err
+	}
+
+	chaos, err := loadChaos()
+	if err != nil {
+		return err
+	}
+
+	variables := run.Variables(wf, vars)
+	runID := workflow.NewRunID()
+	outputDir := headlessOutputDir(wf, "opun-matrix", runID)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// The whole sweep holds the workflow's lock
+	lock, err := lockWorkflow(ctx, wf, "", nil)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	recordPromptUsage(wf)
+	runner := workflow.NewMatrixRunner(outputDir, parallel)
+	runner.Policy = policy
+	runner.Chaos = chaos
+	runner.SetPromptResolver(lazyGardenResolver())
+	ci.group("Run " + wf.Name)
+	results, err := runner.Run(ctx, wf, variables)
+	ci.endGroup()
+	if len(results) > 0 {
+		printMatrixResults(results)
+		fmt.Printf("\n📁 Results: %s\n", filepath.Join(outputDir, workflow.MatrixResultsFile))
+		stored := storeHeadlessOutputs(ctx, wf, runID, outputDir, []string{workflow.MatrixResultsFile, "matrix.md"})
+		ci.matrixDone(wf, runID, outputDir, results)
+		emailRunReport(ctx, wf, workflow.MatrixReport(wf, runID, outputDir, results, stored))
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d matrix combinations failed", failed, len(results))
+	}
+	if copyFinal {
+		copyOutputFile(filepath.Join(outputDir, "matrix.md"))
+	}
+	return nil
+}
+
+// runHeadlessWorkflow runs a workflow once without terminal sessions,
+// reporting it to ci when set
+func runHeadlessWorkflow(name string, vars map[string]string, copyFinal bool, ci *githubActions) error {
+	wf, err := loadWorkflow(name)
+	if err != nil {
+		return fmt.Errorf("failed to load workflow: %w", err)
+	}
+
+	// Reject invalid --var values before starting any step
+	if err := workflow.ValidateVariables(wf, run.Variables(wf, vars)); err != nil {
+		return err
+	}
+	if err := workflow.RequireFeatures(wf); err != nil {
+		return err
+	}
+
+	if err := ensureWorkflowRequirements(wf); err != nil {
+		return err
+	}
+	applyStorageConfig(wf)
+	applyIssueConfig(wf)
+	applyEmailConfig(wf)
+
+	policy, err := loadPromptPolicy()
+	if err != nil {
+		return err
+	}
+
+	chaos, err := loadChaos()
+	if err != nil {
+		return err
+	}
+
+	runID := workflow.NewRunID()
+	outputDir := headlessOutputDir(wf, "opun-runs", runID)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	lock, err := lockWorkflow(ctx, wf, "", nil)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	recordPromptUsage(wf)
+	fmt.Printf("🚀 Running %s headlessly\n", wf.Name)
+	runner := workflow.NewMatrixRunner(outputDir, 1)
+	runner.Policy = policy
+	runner.Chaos = chaos
+	runner.SetPromptResolver(lazyGardenResolver())
+	runner.History = true
+	runner.RunID = runID
+	runner.Progress = func(agentID string, p providers.StreamProgress) {
+		printHeadlessProgress(os.Stdout, agentID, p)
+	}
+	ci.group("Run " + wf.Name)
+	result, err := runner.RunHeadless(ctx, wf, run.Variables(wf, vars))
+	ci.endGroup()
+	printChaosEvents(os.Stdout, result.Chaos)
+	printHeadlessUsage(os.Stdout, result.Usage)
+	fmt.Printf("📁 Outputs: %s (%.1fs)\n", outputDir, result.Duration)
+	outputs := make([]string, 0, len(result.Outputs))
+	for _, output := range result.Outputs {
+		outputs = append(outputs, output)
+	}
+	stored := storeHeadlessOutputs(ctx, wf, runID, outputDir, outputs)
+	ci.headlessDone(wf, runID, outputDir, result)
+	emailRunReport(ctx, wf, workflow.HeadlessReport(wf, runID, outputDir, result, stored))
+	if err != nil {
+		return err
+	}
+	if result.Final != "" {
+		fmt.Println(result.Final)
+	}
+	if copyFinal {
+		copyOutputFile(result.Final)
+	}
+	return nil
+}
+
+// printHeadlessProgress prints what an agent does as its answer streams in
+func printHeadlessProgress(out io.Writer, agentID string, p providers.StreamProgress) {
+	switch p.Kind {
+	case providers.ProgressStarted:
+		if p.Text != "" {
+			fmt.Fprintf(out, "   ▶️  %s: started on %s\n", agentID, p.Text)
+		}
+	case providers.ProgressTool:
+		fmt.Fprintf(out, "   🔧 %s: %s %s\n", agentID, p.Tool, truncate(p.Text, 80))
+	case providers.ProgressText:
+		line, _, _ := strings.Cut(p.Text, "\n")
+		fmt.Fprintf(out, "   💬 %s: %s\n", agentID, truncate(line, 80))
+	}
+}
+
+// printHeadlessUsage prints the tokens and cost providers reported for a run
+func printHeadlessUsage(out io.Writer, usage map[string]providers.Usage) {
+	if len(usage) == 0 {
+		return
+	}
+	var total providers.Usage
+	for _, u := range usage {
+		total.Add(u)
+	}
+	line := fmt.Sprintf("🪙 Usage: %d input, %d output tokens", total.InputTokens+total.CacheCreationInputTokens+total.CacheReadInputTokens, total.OutputTokens)
+	if total.CacheReadInputTokens > 0 {
+		line += fmt.Sprintf(" (%d from cache)", total.CacheReadInputTokens)
+	}
+	if total.CostUSD > 0 {
+		line += fmt.Sprintf(", $%.4f", total.CostUSD)
+	}
+	fmt.Fprintln(out, line)
+}
+
+// headlessOutputDir returns where a headless run writes its outputs,
+// defaulting to a timestamped directory under base. An empty runID gets a
+// new one for the output_dir placeholders.
+func headlessOutputDir(w *wf.Workflow, base, runID string) string {
+	now := time.Now()
+	if runID == "" {
+		runID = workflow.NewRunID()
+	}
+	if w.Settings.OutputDir != "" {
+		return workflow.ExpandOutputPath(w.Settings.OutputDir, workflow.OutputPathVars{Workflow: w.Name, RunID: runID, Time: now})
+	}
+	return filepath.Join(base, now.Format("20060102-150405"))
+}
+
+// printMatrixResults prints a summary table of a matrix run
+func printMatrixResults(results []workflow.MatrixResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCOMBINATION\tSTATUS\tDURATION\tOUTPUT")
+	for _, result := range results {
+		output := result.Final
+		if result.Error != "" {
+			output = result.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.1fs\t%s\n", result.Name, result.Status, result.Duration, output)
+	}
+	_ = w.Flush()
+}
@@ -18,19 +18,23 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
+	"sort"
+	"text/tabwriter"
+	"time"
 
 	"github.com/rizome-dev/opun/internal/command"
 	"github.com/rizome-dev/opun/internal/mcp"
 	"github.com/rizome-dev/opun/internal/plugin"
 	"github.com/rizome-dev/opun/internal/promptgarden"
 	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/internal/workflow"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // MCPCmd creates the MCP command
@@ -44,6 +48,7 @@ func MCPCmd() *cobra.Command {
 	cmd.AddCommand(
 		mcpServeCmd(),
 		mcpStdioCmd(),
+		mcpStatsCmd(),
 	)
 
 	return cmd
@@ -85,16 +90,28 @@ This server can be used by Claude, Gemini, and other MCP-compatible clients.`,
 
 			// Create unified server
 			server := mcp.NewOpunMCPServer(garden, registry, manager, port)
+			server.SetClientACLs(viper.GetStringMapStringSlice("mcp_client_acls"))
 
-			// Handle graceful shutdown
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+			// Expose actions from ~/.opun/tools
+			toolLoader := tools.NewLoader(filepath.Join(home, ".opun", "tools"))
+			if err := toolLoader.LoadAll(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to load tools: %v\n", err)
+			}
+			server.SetActionRegistry(toolLoader.GetRegistry())
 
-			go func() {
-				<-sigChan
+			meter, err := newToolMeter()
+			if err != nil {
+				return err
+			}
+			server.SetToolMeter(meter)
+
+			// Stop serving on shutdown
+			utils.RegisterShutdown("mcp server", utils.PhaseServers, 5*time.Second, func(ctx context.Context) error {
 				fmt.Println("\nShutting down MCP server...")
-				os.Exit(0)
-			}()
+				err := server.Stop(ctx)
+				_ = meter.Close()
+				return err
+			})
 
 			fmt.Printf("Starting Opun MCP server on port %d...\n", port)
 			ctx := context.Background()
@@ -159,6 +176,11 @@ To use with Gemini, add this to ~/.gemini/settings.json:
 			if err != nil {
 				// Suppress warnings in stdio mode - they interfere with JSON-RPC protocol
 				// fmt.Fprintf(os.Stderr, "Warning: failed to initialize workflow manager: %v\n", err)
+			} else {
+				workflowMgr.SetRequirementsEnvironment(loadRequirementsEnvironment)
+				if viper.IsSet("max_concurrent_workflows") {
+					workflowMgr.SetMaxConcurrent(viper.GetInt("max_concurrent_workflows"))
+				}
 			}
 
 			// Initialize tool registry
@@ -172,17 +194,35 @@ To use with Gemini, add this to ~/.gemini/settings.json:
 
 			// Create stdio server
 			server := mcp.NewStdioMCPServer(garden, registry, manager, workflowMgr, toolRegistry)
+			server.SetClientACLs(viper.GetStringMapStringSlice("mcp_client_acls"))
+			server.SetFSPolicy(fsPolicyFromConfig())
+			server.SetWebPolicy(webPolicyFromConfig())
+
+			cache, err := resultCacheFromConfig()
+			if err != nil {
+				return err
+			}
+			server.SetResultCache(cache)
 
-			// Setup signal handling
+			meter, err := newToolMeter()
+			if err != nil {
+				return err
+			}
+			server.SetToolMeter(meter)
+			defer meter.Close()
+
+			// Expose subagent delegation; routing uses the client's model via sampling
+			if err := InitSubAgentManager(); err == nil {
+				server.SetSubAgentManager(globalSubAgentManager)
+			}
+
+			// Stop reading requests on shutdown
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
-
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-			go func() {
-				<-sigChan
+			defer utils.RegisterShutdown("mcp server", utils.PhaseServers, 0, func(context.Context) error {
 				cancel()
-			}()
+				return nil
+			})()
 
 			// Run the server
 			return server.Run(ctx)
@@ -191,3 +231,162 @@ To use with Gemini, add this to ~/.gemini/settings.json:
 
 	return cmd
 }
+
+// mcpStatsCmd creates the stats command for MCP tool usage
+func mcpStatsCmd() *cobra.Command {
+	var jsonOutput bool
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show MCP tool usage statistics",
+		Long: `Show per-tool call counts, errors, rate-limited calls and execution time
+collected by Opun MCP servers.
+
+Rate limits are configured in ~/.opun/config.yaml as calls per minute:
+
+  mcp_rate_limits:
+    "action_*": 10
+    workflow_deploy: 2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statsDir, err := mcp.StatsDir()
+			if err != nil {
+				return err
+			}
+
+			if reset {
+				if err := os.RemoveAll(statsDir); err != nil {
+					return fmt.Errorf("failed to reset stats: %w", err)
+				}
+				fmt.Println("✅ MCP tool statistics reset")
+				return nil
+			}
+
+			stats, err := mcp.LoadToolStats(statsDir)
+			if err != nil {
+				return fmt.Errorf("failed to load stats: %w", err)
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(stats, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+
+			if len(stats) == 0 {
+				fmt.Println("No MCP tool calls recorded yet.")
+				return nil
+			}
+
+			tools := make([]string, 0, len(stats))
+			for tool := range stats {
+				tools = append(tools, tool)
+			}
+			sort.Strings(tools)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TOOL\tCALLS\tERRORS\tRATE LIMITED\tAVG TIME\tLAST CALL")
+			fmt.Fprintln(w, "----\t-----\t------\t------------\t--------\t---------")
+			for _, tool := range tools {
+				s := stats[tool]
+				avg := time.Duration(0)
+				if s.Calls > 0 {
+					avg = s.TotalTime / time.Duration(s.Calls)
+				}
+				lastCall := "-"
+				if !s.LastCall.IsZero() {
+					lastCall = s.LastCall.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n", tool, s.Calls, s.Errors, s.RateLimited, avg.Round(time.Millisecond), lastCall)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output statistics as JSON")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Clear recorded statistics")
+
+	return cmd
+}
+
+// newToolMeter creates a tool meter using the configured rate limits.
+// Each server process persists its counters to its own file in the stats
+// directory, named after its PID and start time so a reused PID doesn't
+// overwrite an earlier server's file. Callers Close the meter on exit.
+func newToolMeter() (*mcp.ToolMeter, error) {
+	limits, err := mcp.ParseRateLimits(viper.GetStringMap("mcp_rate_limits"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mcp_rate_limits config: %w", err)
+	}
+
+	meter := mcp.NewToolMeter(limits)
+	if statsDir, err := mcp.StatsDir(); err == nil {
+		meter.SetStatsFile(filepath.Join(statsDir, fmt.Sprintf("server-%d-%d.json", os.Getpid(), time.Now().UnixNano())))
+	}
+
+	return meter, nil
+}
+
+// fsPolicyFromConfig builds the filesystem tool policy from the mcp_fs_tools config section
+func fsPolicyFromConfig() mcp.FSPolicy {
+	policy := mcp.DefaultFSPolicy()
+
+	if viper.IsSet("mcp_fs_tools.enabled") {
+		policy.Enabled = viper.GetBool("mcp_fs_tools.enabled")
+	}
+	// fs_write_file and fs_apply_patch are only exposed when asked for
+	policy.ReadOnly = !viper.GetBool("mcp_fs_tools.allow_writes")
+	if deny := viper.GetStringSlice("mcp_fs_tools.deny"); len(deny) > 0 {
+		policy.Deny = append(policy.Deny, deny...)
+	}
+	if viper.IsSet("mcp_fs_tools.audit_log") {
+		policy.AuditLog = viper.GetString("mcp_fs_tools.audit_log")
+	}
+
+	return policy
+}
+
+// webPolicyFromConfig builds the web tool policy from the mcp_web_tools config section
+func webPolicyFromConfig() mcp.WebPolicy {
+	policy := mcp.DefaultWebPolicy()
+
+	if viper.IsSet("mcp_web_tools.enabled") {
+		policy.Enabled = viper.GetBool("mcp_web_tools.enabled")
+	}
+	policy.AllowedDomains = viper.GetStringSlice("mcp_web_tools.allowed_domains")
+	if maxBytes := viper.GetInt64("mcp_web_tools.max_bytes"); maxBytes > 0 {
+		policy.MaxBytes = maxBytes
+	}
+	policy.SearchURL = viper.GetString("mcp_web_tools.search_url")
+	policy.SearchAPIKeyEnv = viper.GetString("mcp_web_tools.search_api_key_env")
+	policy.SearchKeyHeader = viper.GetString("mcp_web_tools.search_key_header")
+
+	return policy
+}
+
+// resultCacheFromConfig builds the opt-in tool result cache from the mcp_cache config section.
+// It returns nil when no tools are configured for caching.
+func resultCacheFromConfig() (*mcp.ResultCache, error) {
+	tools := viper.GetStringMapString("mcp_cache.tools")
+	if len(tools) == 0 {
+		return nil, nil
+	}
+
+	ttls := make(map[string]time.Duration, len(tools))
+	for tool, value := range tools {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mcp_cache TTL for %s: %w", tool, err)
+		}
+		ttls[tool] = ttl
+	}
+
+	return mcp.NewResultCache(mcp.CacheConfig{
+		TTLs:       ttls,
+		MaxEntries: viper.GetInt("mcp_cache.max_entries"),
+		MaxBytes:   viper.GetInt("mcp_cache.max_bytes"),
+	}), nil
+}
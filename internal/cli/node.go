@@ -0,0 +1,160 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// NodeCmd creates the node command
+func NodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "Manage named SSH targets for remote workflow runs",
+		Long: `Manage nodes, names for SSH addresses that workflow agents can run on,
+such as a Rizome cloud workspace. Nodes are kept in ~/.opun/nodes.yaml;
+agents with 'target: <node-id>' run their provider over SSH at the node's
+address, exactly as with an ssh:// target. Opun doesn't provision
+nodes or look them up anywhere else.`,
+	}
+
+	cmd.AddCommand(
+		nodeListCmd(),
+		nodeAddCmd(),
+		nodeRemoveCmd(),
+		nodeConnectCmd(),
+	)
+
+	return cmd
+}
+
+// nodeListCmd lists registered nodes
+func nodeListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List registered nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := workflow.NodesPath()
+			if err != nil {
+				return err
+			}
+			nodes, err := workflow.LoadNodes(path)
+			if err != nil {
+				return err
+			}
+
+			if len(nodes) == 0 {
+				fmt.Println("No nodes registered. Add one with 'opun node add <id> ssh://user@host'.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tADDRESS\tDESCRIPTION")
+			fmt.Fprintln(w, "--\t-------\t-----------")
+			for _, node := range nodes {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", node.ID, node.Address, node.Description)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// nodeAddCmd registers a node
+func nodeAddCmd() *cobra.Command {
+	var description string
+
+	cmd := &cobra.Command{
+		Use:   "add <id> <ssh://user@host[:port][/dir]>",
+		Short: "Register a node",
+		Long: `Register a node under a name for its SSH address. The optional path is the
+working directory on the node; by default agents use the same path as locally.
+Adding an existing ID replaces it.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := workflow.NodesPath()
+			if err != nil {
+				return err
+			}
+
+			node := workflow.Node{ID: args[0], Address: args[1], Description: description}
+			if err := workflow.SaveNode(path, node); err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Node '%s' registered, use it with target: %s\n", node.ID, node.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&description, "description", "", "Description of the node")
+
+	return cmd
+}
+
+// nodeRemoveCmd unregisters a node
+func nodeRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Unregister a node",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := workflow.NodesPath()
+			if err != nil {
+				return err
+			}
+			if err := workflow.RemoveNode(path, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Node '%s' removed\n", args[0])
+			return nil
+		},
+	}
+}
+
+// nodeConnectCmd opens a shell on a node
+func nodeConnectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "connect <id>",
+		Short: "Open a shell on a node",
+		Long: `Open an interactive shell on a registered node, for example to install
+or log in to providers before running workflows there.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := workflow.NodeTarget(args[0])
+			if err != nil {
+				return err
+			}
+
+			name, sshArgs := target.ShellCommand()
+			// #nosec G204 -- ssh with arguments from the user's node registry
+			sshCmd := exec.Command(name, sshArgs...)
+			sshCmd.Stdin = os.Stdin
+			sshCmd.Stdout = os.Stdout
+			sshCmd.Stderr = os.Stderr
+
+			fmt.Printf("🌐 Connecting to node %s...\n", args[0])
+			return sshCmd.Run()
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"golang.org/x/term"
+)
+
+// noPager turns off paging of long output, set by --no-pager
+var noPager bool
+
+// copyOutput copies a command's final output for --copy, reporting the result
+func copyOutput(text string) {
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(os.Stderr, "⚠️  Nothing to copy")
+		return
+	}
+	if err := utils.NewClipboard().Copy(text); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not copy to the clipboard: %v\n", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "📋 Copied to the clipboard")
+}
+
+// copyOutputFile copies the contents of an output file for --copy
+func copyOutputFile(path string) {
+	if path == "" {
+		copyOutput("")
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not copy %s: %v\n", path, err)
+		return
+	}
+	copyOutput(string(data))
+}
+
+// pagerCommand returns the pager to run: $OPUN_PAGER, then $PAGER, then less
+// (more on Windows). Options that make less keep colors and quit on short
+// output are added when less is used without any.
+func pagerCommand(getenv func(string) string, goos string) []string {
+	pager := getenv("OPUN_PAGER")
+	if pager == "" {
+		pager = getenv("PAGER")
+	}
+	if pager == "" {
+		pager = "less"
+		if goos == "windows" {
+			pager = "more"
+		}
+	}
+
+	args := strings.Fields(pager)
+	if len(args) == 0 {
+		return nil
+	}
+	if args[0] == "less" && len(args) == 1 && getenv("LESS") == "" {
+		args = append(args, "-FRX")
+	}
+	return args
+}
+
+// pageOutput captures what a command prints to stdout and, when it doesn't
+// fit on the terminal, shows it through the pager. Call the returned
+// function once the output is complete. Without a terminal, with
+// --no-pager, or with OPUN_PAGER/PAGER set to "cat", output goes straight
+// to stdout.
+func pageOutput() func() {
+	stdout := os.Stdout
+	if noPager || !term.IsTerminal(int(stdout.Fd())) {
+		return func() {}
+	}
+	pager := pagerCommand(os.Getenv, runtime.GOOS)
+	if len(pager) == 0 || pager[0] == "cat" {
+		return func() {}
+	}
+	_, height, err := term.GetSize(int(stdout.Fd()))
+	if err != nil {
+		return func() {}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	os.Stdout = w
+
+	captured := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		captured <- data
+	}()
+
+	return func() {
+		os.Stdout = stdout
+		_ = w.Close()
+		data := <-captured
+		_ = r.Close()
+
+		if bytes.Count(data, []byte("\n")) < height {
+			_, _ = stdout.Write(data)
+			return
+		}
+
+		cmd := exec.Command(pager[0], pager[1:]...)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stdout = stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			// A missing or broken pager shouldn't swallow the output
+			_, _ = stdout.Write(data)
+		}
+	}
+}
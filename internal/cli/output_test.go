@@ -0,0 +1,48 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagerCommand(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	assert.Equal(t, []string{"less", "-FRX"}, pagerCommand(env(nil), "linux"))
+	assert.Equal(t, []string{"less"}, pagerCommand(env(map[string]string{"LESS": "-R"}), "linux"))
+	assert.Equal(t, []string{"more"}, pagerCommand(env(nil), "windows"))
+	assert.Equal(t, []string{"most", "-s"}, pagerCommand(env(map[string]string{"PAGER": "most -s"}), "linux"))
+	assert.Equal(t, []string{"cat"}, pagerCommand(env(map[string]string{"PAGER": "less", "OPUN_PAGER": "cat"}), "linux"))
+}
+
+func TestRecordFinalOutput(t *testing.T) {
+	var path string
+	handle := recordFinalOutput(&path)
+
+	handle(wf.WorkflowEvent{Type: wf.EventAgentComplete, Data: map[string]interface{}{"output": "out/plan.md"}})
+	handle(wf.WorkflowEvent{Type: wf.EventAgentStart, Data: map[string]interface{}{"output": "ignored"}})
+	handle(wf.WorkflowEvent{Type: wf.EventAgentComplete, Data: map[string]interface{}{"output": "out/review.md"}})
+	handle(wf.WorkflowEvent{Type: wf.EventAgentComplete})
+
+	assert.Equal(t, "out/review.md", path)
+}
@@ -0,0 +1,270 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// panelRunner runs a prompt headlessly on one provider
+type panelRunner func(ctx context.Context, provider, model, prompt string) (string, error)
+
+// panelAnswer is one provider's answer to a panel prompt
+type panelAnswer struct {
+	Provider string
+	Model    string
+	Output   string
+	Err      error
+	Duration time.Duration
+}
+
+// PanelCmd creates the panel command
+func PanelCmd() *cobra.Command {
+	var (
+		providerList []string
+		models       map[string]string
+		judge        string
+		format       string
+		outputFile   string
+		timeout      time.Duration
+		copyReport   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "panel [prompt]",
+		Short: "Ask several providers the same prompt at once",
+		Long: `Run the same prompt concurrently on multiple providers in headless mode and
+compare their answers side by side. Optionally a judge provider synthesizes
+a final answer from all responses.
+
+Examples:
+  opun panel "How should I structure this CLI?" --providers claude,gemini,qwen
+  opun panel "Review main.go" --judge claude --format markdown --output panel.md`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prompt := strings.Join(args, " ")
+
+			if format != "columns" && format != "markdown" {
+				return fmt.Errorf("unsupported format: %s (use columns or markdown)", format)
+			}
+
+			workDir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			runner := func(ctx context.Context, provider, model, prompt string) (string, error) {
+				return providers.RunHeadless(ctx, provider, model, prompt, workDir)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			fmt.Printf("🧑‍⚖️ Asking %s...\n", strings.Join(providerList, ", "))
+			answers := runPanel(ctx, prompt, providerList, models, runner)
+
+			var verdict *panelAnswer
+			if judge != "" {
+				fmt.Printf("⚖️  Asking %s to judge...\n", judge)
+				start := time.Now()
+				output, err := runner(ctx, judge, models[judge], buildJudgePrompt(prompt, answers))
+				verdict = &panelAnswer{
+					Provider: judge,
+					Model:    models[judge],
+					Output:   output,
+					Err:      err,
+					Duration: time.Since(start),
+				}
+			}
+
+			report := renderPanelMarkdown(prompt, answers, verdict)
+			if outputFile != "" {
+				if err := os.WriteFile(outputFile, []byte(report), 0644); err != nil {
+					return fmt.Errorf("failed to write report: %w", err)
+				}
+				fmt.Printf("📝 Report written to %s\n", outputFile)
+			}
+
+			done := pageOutput()
+			if format == "markdown" || !term.IsTerminal(int(os.Stdout.Fd())) {
+				fmt.Print(report)
+			} else {
+				width, _, err := term.GetSize(int(os.Stdout.Fd()))
+				if err != nil {
+					width = 120
+				}
+				fmt.Println(renderPanelColumns(answers, width))
+				if verdict != nil {
+					fmt.Println(renderPanelVerdict(verdict, width))
+				}
+			}
+			done()
+
+			if copyReport {
+				copyOutput(report)
+			}
+
+			for _, answer := range answers {
+				if answer.Err == nil {
+					return nil
+				}
+			}
+			return fmt.Errorf("all providers failed")
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&providerList, "providers", []string{"claude", "gemini"}, "providers to ask (comma-separated)")
+	cmd.Flags().StringToStringVar(&models, "model", map[string]string{}, "model per provider (provider=model)")
+	cmd.Flags().StringVar(&judge, "judge", "", "provider that synthesizes a final answer from all responses")
+	cmd.Flags().StringVar(&format, "format", "columns", "output format: columns or markdown")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "also write a markdown report to this file")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "maximum time to wait for all providers")
+	cmd.Flags().BoolVar(&copyReport, "copy", false, "copy the markdown report to the clipboard")
+
+	return cmd
+}
+
+// runPanel asks every provider the prompt concurrently.
+// Answers are returned in the order the providers were given.
+func runPanel(ctx context.Context, prompt string, providerList []string, models map[string]string, run panelRunner) []panelAnswer {
+	answers := make([]panelAnswer, len(providerList))
+
+	var wg sync.WaitGroup
+	for i, provider := range providerList {
+		wg.Add(1)
+		go func(i int, provider string) {
+			defer wg.Done()
+			start := time.Now()
+			output, err := run(ctx, provider, models[provider], prompt)
+			answers[i] = panelAnswer{
+				Provider: provider,
+				Model:    models[provider],
+				Output:   output,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	return answers
+}
+
+// buildJudgePrompt asks a judge to synthesize a final answer from the panel
+func buildJudgePrompt(prompt string, answers []panelAnswer) string {
+	var sb strings.Builder
+	sb.WriteString("Several AI assistants answered the same question. ")
+	sb.WriteString("Compare their answers, point out where they disagree, and write the single best final answer.\n\n")
+	fmt.Fprintf(&sb, "## Question\n\n%s\n\n", prompt)
+
+	for i, answer := range answers {
+		if answer.Err != nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "## Answer %d (%s)\n\n%s\n\n", i+1, answer.Provider, answer.Output)
+	}
+
+	sb.WriteString("## Final answer\n")
+	return sb.String()
+}
+
+// renderPanelMarkdown renders the panel as a markdown report
+func renderPanelMarkdown(prompt string, answers []panelAnswer, verdict *panelAnswer) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Panel\n\n> %s\n\n", strings.ReplaceAll(prompt, "\n", "\n> "))
+
+	for _, answer := range answers {
+		sb.WriteString(renderPanelSection("## "+panelTitle(answer), answer))
+	}
+
+	if verdict != nil {
+		sb.WriteString(renderPanelSection("## Verdict ("+panelTitle(*verdict)+")", *verdict))
+	}
+
+	return sb.String()
+}
+
+// renderPanelSection renders one answer as a markdown section
+func renderPanelSection(heading string, answer panelAnswer) string {
+	body := answer.Output
+	if answer.Err != nil {
+		body = fmt.Sprintf("❌ %v", answer.Err)
+	}
+	return fmt.Sprintf("%s\n\n_%s_\n\n%s\n\n", heading, answer.Duration.Round(time.Second), body)
+}
+
+// panelTitle returns "provider" or "provider (model)"
+func panelTitle(answer panelAnswer) string {
+	if answer.Model != "" {
+		return fmt.Sprintf("%s (%s)", answer.Provider, answer.Model)
+	}
+	return answer.Provider
+}
+
+// renderPanelColumns renders the answers side by side to fit the terminal width
+func renderPanelColumns(answers []panelAnswer, width int) string {
+	if len(answers) == 0 {
+		return ""
+	}
+
+	columnWidth := width/len(answers) - 2
+	if columnWidth < 20 {
+		columnWidth = 20
+	}
+
+	columns := make([]string, 0, len(answers))
+	for _, answer := range answers {
+		columns = append(columns, renderPanelBox(answer, columnWidth))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// renderPanelVerdict renders the judge's answer across the full width
+func renderPanelVerdict(verdict *panelAnswer, width int) string {
+	return renderPanelBox(*verdict, width-2)
+}
+
+// renderPanelBox renders one answer in a bordered box
+func renderPanelBox(answer panelAnswer, width int) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("86"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(width - 2)
+
+	body := answer.Output
+	if answer.Err != nil {
+		body = lipgloss.NewStyle().Foreground(lipgloss.Color("161")).Render(fmt.Sprintf("❌ %v", answer.Err))
+	}
+
+	header := fmt.Sprintf("%s  %s", titleStyle.Render(panelTitle(answer)), answer.Duration.Round(time.Second))
+	return boxStyle.Render(header + "\n\n" + body)
+}
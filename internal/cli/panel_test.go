@@ -0,0 +1,99 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPanel(t *testing.T) {
+	var running, maxRunning int32
+	runner := func(ctx context.Context, provider, model, prompt string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if provider == "qwen" {
+			return "", errors.New("not logged in")
+		}
+		return provider + "/" + model + ": " + prompt, nil
+	}
+
+	answers := runPanel(context.Background(), "hi", []string{"claude", "gemini", "qwen"}, map[string]string{"claude": "opus"}, runner)
+	require.Len(t, answers, 3)
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&maxRunning), "providers should run concurrently")
+	assert.Equal(t, "claude", answers[0].Provider)
+	assert.Equal(t, "opus", answers[0].Model)
+	assert.Equal(t, "claude/opus: hi", answers[0].Output)
+	assert.Equal(t, "gemini/: hi", answers[1].Output)
+	assert.EqualError(t, answers[2].Err, "not logged in")
+}
+
+func TestBuildJudgePrompt(t *testing.T) {
+	answers := []panelAnswer{
+		{Provider: "claude", Output: "Use cobra"},
+		{Provider: "gemini", Err: errors.New("timeout")},
+		{Provider: "qwen", Output: "Use urfave/cli"},
+	}
+
+	prompt := buildJudgePrompt("Which CLI library?", answers)
+	assert.Contains(t, prompt, "## Question\n\nWhich CLI library?")
+	assert.Contains(t, prompt, "## Answer 1 (claude)\n\nUse cobra")
+	assert.Contains(t, prompt, "## Answer 3 (qwen)\n\nUse urfave/cli")
+	assert.NotContains(t, prompt, "gemini", "failed answers are left out")
+}
+
+func TestRenderPanelMarkdown(t *testing.T) {
+	answers := []panelAnswer{
+		{Provider: "claude", Model: "opus", Output: "A", Duration: 2 * time.Second},
+		{Provider: "gemini", Err: errors.New("boom")},
+	}
+	verdict := &panelAnswer{Provider: "claude", Output: "Final"}
+
+	report := renderPanelMarkdown("line one\nline two", answers, verdict)
+	assert.Contains(t, report, "> line one\n> line two")
+	assert.Contains(t, report, "## claude (opus)\n\n_2s_\n\nA")
+	assert.Contains(t, report, "## gemini")
+	assert.Contains(t, report, "❌ boom")
+	assert.Contains(t, report, "## Verdict (claude)")
+	assert.Contains(t, report, "Final")
+}
+
+func TestRenderPanelColumns(t *testing.T) {
+	assert.Empty(t, renderPanelColumns(nil, 80))
+
+	out := renderPanelColumns([]panelAnswer{
+		{Provider: "claude", Output: "left"},
+		{Provider: "gemini", Output: "right"},
+	}, 80)
+	assert.Contains(t, out, "left")
+	assert.Contains(t, out, "right")
+}
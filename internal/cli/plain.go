@@ -0,0 +1,60 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+// noTUI switches interactive flows to line prompts, set by --no-tui
+var noTUI bool
+
+// plainLabel describes a list item on one line for a numbered menu
+func plainLabel(item list.Item) string {
+	if d, ok := item.(list.DefaultItem); ok {
+		if d.Description() != "" {
+			return d.Title() + " - " + d.Description()
+		}
+		return d.Title()
+	}
+	return item.FilterValue()
+}
+
+// plainSelect shows the items of a list as a numbered menu, the --no-tui
+// stand-in for a bubbles list, and returns the chosen one
+func plainSelect(title string, items []list.Item) (list.Item, error) {
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = plainLabel(item)
+	}
+	i, err := utils.StdPrompter().Select(title, labels)
+	if err != nil {
+		return nil, err
+	}
+	return items[i], nil
+}
+
+// plainSelectMany shows the items of a list as a numbered menu with the
+// selected ones marked and returns the indexes chosen
+func plainSelectMany(title string, items []list.Item, selected []bool) ([]int, error) {
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = plainLabel(item)
+	}
+	return utils.StdPrompter().SelectMany(title, labels, selected)
+}
@@ -0,0 +1,141 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/i18n"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// workflowProviders returns the providers used by a workflow's local agents, in order.
+// Agents with a remote target use the provider installed on that machine.
+func workflowProviders(w *wf.Workflow) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for i := range w.Agents {
+		agent := &w.Agents[i]
+		if workflow.AgentRunsRemotely(w, agent) {
+			continue
+		}
+		provider := strings.ToLower(agent.Provider)
+		if provider == "" || seen[provider] {
+			continue
+		}
+		seen[provider] = true
+		names = append(names, provider)
+	}
+	return names
+}
+
+// ensureProviderAuth checks that every provider a workflow uses is installed and
+// logged in before the first agent starts. In a terminal the user can log in and
+// press enter to re-check; otherwise it fails fast.
+func ensureProviderAuth(w *wf.Workflow) error {
+	if viper.GetBool("skip_auth_check") {
+		return nil
+	}
+
+	names := workflowProviders(w)
+	if len(names) == 0 {
+		return nil
+	}
+
+	checker := providers.NewAuthChecker()
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		statuses := checker.CheckAll(context.Background(), names)
+		notReady := notReadyProviders(statuses)
+		if len(notReady) == 0 {
+			// Logins kept out of sight, e.g. in the Keychain, can't be confirmed
+			for _, status := range statuses {
+				if status.Unverified {
+					fmt.Println(i18n.T("preflight.unverified", status.Provider, status.Reason))
+					if status.LoginHint != "" {
+						fmt.Printf("   %s\n", i18n.T("preflight.login_hint", status.LoginHint))
+					}
+				}
+			}
+			return nil
+		}
+
+		fmt.Println(i18n.T("preflight.not_ready"))
+		for _, status := range notReady {
+			fmt.Printf("   ❌ %s: %s\n", status.Provider, status.Reason)
+			if status.Installed && status.LoginHint != "" {
+				fmt.Printf("      %s\n", i18n.T("preflight.login_hint", status.LoginHint))
+			}
+		}
+
+		if !interactive {
+			err := errors.New(i18n.T("preflight.failed", providerNames(notReady)))
+			return workflow.Classify(preflightErrorClass(notReady), err)
+		}
+
+		fmt.Print("\n" + i18n.T("preflight.recheck"))
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("%s: %w", i18n.T("preflight.aborted"), err)
+		}
+		if strings.EqualFold(strings.TrimSpace(answer), "q") {
+			return workflow.Classify(workflow.ErrorUserAborted, errors.New(i18n.T("preflight.aborted")))
+		}
+	}
+}
+
+// preflightErrorClass is provider_not_found when a provider isn't
+// installed and auth when they're all installed but not logged in
+func preflightErrorClass(notReady []providers.AuthStatus) workflow.ErrorClass {
+	for _, status := range notReady {
+		if !status.Installed {
+			return workflow.ErrorProviderNotFound
+		}
+	}
+	return workflow.ErrorAuth
+}
+
+// notReadyProviders filters statuses down to providers that can't be used
+func notReadyProviders(statuses []providers.AuthStatus) []providers.AuthStatus {
+	var notReady []providers.AuthStatus
+	for _, status := range statuses {
+		if !status.Ready() {
+			notReady = append(notReady, status)
+		}
+	}
+	return notReady
+}
+
+// providerNames joins provider names for error messages
+func providerNames(statuses []providers.AuthStatus) string {
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.Provider)
+	}
+	return strings.Join(names, ", ")
+}
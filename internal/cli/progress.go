@@ -24,6 +24,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/utils"
 )
 
 // progressModel shows a progress bar for long-running operations
@@ -96,13 +97,21 @@ func (m progressModel) View() string {
 	)
 }
 
-// Progress creates a progress bar that can be updated
+// Progress creates a progress bar that can be updated. In plain mode it
+// prints a line every ten percent instead.
 type Progress struct {
 	program *tea.Program
+	title   string
+	printed int
 }
 
 // NewProgress creates a new progress indicator
 func NewProgress(title string) *Progress {
+	if utils.PlainUI() {
+		fmt.Println(title)
+		return &Progress{title: title, printed: -1}
+	}
+
 	p := tea.NewProgram(initialProgressModel(title))
 	go p.Run()
 
@@ -113,11 +122,22 @@ func NewProgress(title string) *Progress {
 
 // Update updates the progress percentage (0.0 to 1.0)
 func (p *Progress) Update(percent float64) {
+	if p.program == nil {
+		if step := int(percent * 10); step > p.printed && step < 10 {
+			p.printed = step
+			fmt.Printf("%s: %.0f%%\n", p.title, percent*100)
+		}
+		return
+	}
 	p.program.Send(progressMsg(percent))
 }
 
 // Done marks the progress as complete
 func (p *Progress) Done() {
+	if p.program == nil {
+		fmt.Printf("%s completed\n", p.title)
+		return
+	}
 	p.program.Send(doneMsg{})
 	time.Sleep(100 * time.Millisecond) // Give it time to display
 }
@@ -194,13 +214,20 @@ func (m statusModel) View() string {
 	return fmt.Sprintf("%s %s", m.spinner.View(), m.status)
 }
 
-// Status creates a spinner with status text
+// Status creates a spinner with status text. In plain mode each status is
+// printed on its own line.
 type Status struct {
 	program *tea.Program
+	status  string
 }
 
 // NewStatus creates a new status indicator
 func NewStatus(initialStatus string) *Status {
+	if utils.PlainUI() {
+		fmt.Println(initialStatus)
+		return &Status{status: initialStatus}
+	}
+
 	p := tea.NewProgram(initialStatusModel(initialStatus))
 	go p.Run()
 
@@ -211,17 +238,30 @@ func NewStatus(initialStatus string) *Status {
 
 // Update updates the status text
 func (s *Status) Update(status string) {
+	if s.program == nil {
+		s.status = status
+		fmt.Println(status)
+		return
+	}
 	s.program.Send(statusMsg(status))
 }
 
 // Done marks the status as complete
 func (s *Status) Done() {
+	if s.program == nil {
+		fmt.Printf("%s completed\n", s.status)
+		return
+	}
 	s.program.Send(statusDoneMsg{})
 	time.Sleep(100 * time.Millisecond) // Give it time to display
 }
 
 // Error marks the status as failed
 func (s *Status) Error(err error) {
+	if s.program == nil {
+		fmt.Printf("%s failed: %v\n", s.status, err)
+		return
+	}
 	s.program.Send(statusErrorMsg(err))
 	time.Sleep(100 * time.Millisecond) // Give it time to display
 }
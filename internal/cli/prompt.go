@@ -26,6 +26,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/utils"
 )
 
 // promptModel is a simple text input model for prompts
@@ -96,6 +97,10 @@ func (m promptModel) View() string {
 
 // Prompt asks the user a question and returns their answer
 func Prompt(question string) (string, error) {
+	if utils.PlainUI() {
+		return utils.StdPrompter().Ask(strings.TrimSuffix(question, ":"), "")
+	}
+
 	p := tea.NewProgram(initialPromptModel(question))
 	m, err := p.Run()
 	if err != nil {
@@ -162,6 +167,10 @@ func (m confirmModel) View() string {
 
 // Confirm asks the user a yes/no question
 func Confirm(question string) (bool, error) {
+	if utils.PlainUI() {
+		return utils.StdPrompter().Confirm(question, false)
+	}
+
 	p := tea.NewProgram(initialConfirmModel(question))
 	m, err := p.Run()
 	if err != nil {
@@ -589,34 +598,42 @@ func (m filePromptModel) View() string {
 
 // FilePrompt asks the user to select a file with autocomplete and fuzzy find
 func FilePrompt(question string) (string, error) {
-	p := tea.NewProgram(initialFilePromptModel(question))
-	m, err := p.Run()
-	if err != nil {
-		return "", err
+	var value string
+	if utils.PlainUI() {
+		answer, err := utils.StdPrompter().Ask(strings.TrimSuffix(question, ":")+" (path)", "")
+		if err != nil {
+			return "", err
+		}
+		value = answer
+	} else {
+		p := tea.NewProgram(initialFilePromptModel(question))
+		m, err := p.Run()
+		if err != nil {
+			return "", err
+		}
+		fm, ok := m.(filePromptModel)
+		if !ok {
+			return "", fmt.Errorf("unexpected model type")
+		}
+		value = strings.TrimSpace(fm.textInput.Value())
 	}
 
-	if m, ok := m.(filePromptModel); ok {
-		value := strings.TrimSpace(m.textInput.Value())
-
-		// Expand relative paths to absolute paths
-		if value != "" && !filepath.IsAbs(value) {
-			if strings.HasPrefix(value, "~/") {
-				home, err := os.UserHomeDir()
-				if err != nil {
-					return "", err
-				}
-				value = filepath.Join(home, value[2:])
-			} else {
-				abs, err := filepath.Abs(value)
-				if err != nil {
-					return "", err
-				}
-				value = abs
+	// Expand relative paths to absolute paths
+	if value != "" && !filepath.IsAbs(value) {
+		if strings.HasPrefix(value, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			value = filepath.Join(home, value[2:])
+		} else {
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return "", err
 			}
+			value = abs
 		}
-
-		return value, nil
 	}
 
-	return "", fmt.Errorf("unexpected model type")
+	return value, nil
 }
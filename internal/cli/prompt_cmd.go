@@ -0,0 +1,221 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// PromptCmd creates the prompt command
+func PromptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Run prompt garden prompts",
+	}
+	cmd.AddCommand(promptListCmd())
+	cmd.AddCommand(promptExecCmd())
+	cmd.AddCommand(promptReportCmd())
+	cmd.AddCommand(promptImproveCmd())
+	cmd.AddCommand(promptPendingCmd())
+	cmd.AddCommand(promptApproveCmd())
+	cmd.AddCommand(promptRejectCmd())
+	cmd.AddCommand(promptDedupeCmd())
+	cmd.AddCommand(promptPruneCmd())
+	return cmd
+}
+
+// promptExecCmd runs one garden prompt headlessly and prints the answer
+func promptExecCmd() *cobra.Command {
+	var (
+		vars     map[string]string
+		stdinVar string
+		provider string
+		model    string
+		timeout  time.Duration
+		copyOut  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exec [prompt]",
+		Short: "Run a prompt headlessly and print the answer",
+		Long: `Render a prompt garden prompt and run it with a provider's non-interactive
+mode, printing the answer to stdout so it composes with other commands.
+
+--stdin-var NAME (or --var NAME=-) fills a variable from stdin. Input over
+8KB is saved to a temporary file and passed as an @path reference that the
+provider reads itself.`,
+		Example: `  git diff | opun prompt exec code-review --stdin-var diff
+  opun prompt exec explain --var topic=channels --provider gemini`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if stdinVar != "" {
+				if _, ok := vars[stdinVar]; ok {
+					return fmt.Errorf("%s is set with both --var and --stdin-var", stdinVar)
+				}
+				vars[stdinVar] = stdinValue
+			}
+			cleanup, err := resolveStdinVars(vars)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if provider == "" {
+				provider = viper.GetString("default_provider")
+			}
+			if provider == "" {
+				provider = "claude"
+			}
+
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+
+			variables := make(map[string]interface{}, len(vars))
+			for k, v := range vars {
+				variables[k] = v
+			}
+			rendered, err := garden.ExecuteVariant(name, variables)
+			if err != nil {
+				return fmt.Errorf("failed to render prompt: %w", err)
+			}
+
+			workDir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+			ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+			defer cancelTimeout()
+
+			start := time.Now()
+			output, err := providers.RunHeadless(ctx, provider, model, rendered.Text, workDir)
+			outcome := promptgarden.Outcome{
+				Prompt:      name,
+				Variant:     rendered.Variant,
+				Command:     "exec",
+				Provider:    provider,
+				Model:       model,
+				Success:     err == nil,
+				Duration:    time.Since(start).Seconds(),
+				OutputBytes: len(output),
+			}
+			if err != nil {
+				outcome.Error = err.Error()
+			}
+			recordPromptOutcome(outcome)
+			if err != nil {
+				return err
+			}
+			fmt.Println(output)
+			if copyOut {
+				copyOutput(output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringToStringVarP(&vars, "var", "v", map[string]string{}, "prompt variables (key=value, key=- reads stdin)")
+	cmd.Flags().StringVar(&stdinVar, "stdin-var", "", "variable to fill from stdin")
+	cmd.Flags().StringVar(&provider, "provider", "", "provider to run the prompt on (default: default_provider)")
+	cmd.Flags().StringVar(&model, "model", "", "model to use")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "maximum time to wait for the answer")
+	cmd.Flags().BoolVar(&copyOut, "copy", false, "copy the answer to the clipboard")
+
+	return cmd
+}
+
+// promptReportCmd summarizes the recorded runs of a prompt per variant
+func promptReportCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "report <name>",
+		Short: "Compare the outcomes of a prompt's variants",
+		Long: `Summarize every recorded run of a prompt by variant: how many runs it had,
+how many succeeded, and their average duration and answer size.
+
+Runs of opun prompt exec and opun map are recorded in ~/.opun/runs/` + promptgarden.HistoryFile + `.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			runsDir, err := workflow.RunsDir()
+			if err != nil {
+				return err
+			}
+			outcomes, err := promptgarden.LoadOutcomes(runsDir, name)
+			if err != nil {
+				return fmt.Errorf("failed to read prompt history: %w", err)
+			}
+			summary := promptgarden.SummarizeOutcomes(outcomes)
+
+			if asJSON {
+				data, err := json.MarshalIndent(summary, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+
+			if len(summary) == 0 {
+				fmt.Printf("No recorded runs of %s\n", name)
+				return nil
+			}
+			fmt.Printf("📊 %s: %d runs\n\n", name, len(outcomes))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VARIANT\tRUNS\tSUCCESS\tAVG DURATION\tAVG OUTPUT")
+			for _, stats := range summary {
+				fmt.Fprintf(w, "%s\t%d\t%d (%.0f%%)\t%.1fs\t%.0f bytes\n",
+					stats.Variant, stats.Runs, stats.Successes, stats.SuccessRate*100, stats.AvgDuration, stats.AvgOutput)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the summary as JSON")
+
+	return cmd
+}
+
+// recordPromptOutcome adds a prompt run to the history. It only warns when
+// that fails, since the run itself is done.
+func recordPromptOutcome(outcome promptgarden.Outcome) {
+	runsDir, err := workflow.RunsDir()
+	if err == nil {
+		err = promptgarden.RecordOutcome(runsDir, outcome)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record prompt run: %v\n", err)
+	}
+}
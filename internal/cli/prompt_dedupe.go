@@ -0,0 +1,239 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/spf13/cobra"
+)
+
+// promptDedupeCmd finds near-duplicate prompts in the garden and offers to
+// merge them
+func promptDedupeCmd() *cobra.Command {
+	var (
+		threshold float64
+		list      bool
+		asJSON    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find and merge near-duplicate prompts",
+		Long: `Group the prompt garden's prompts into clusters of near duplicates: the
+same content apart from case and spacing, or content at least --threshold
+alike by shared word sequences.
+
+For each cluster, pick the prompt to keep and the others are merged into it:
+their tags are added to it and they are deleted. Prompts that workflows,
+actions or other prompts still reference are left in place. Keeping all of
+them records that they are meant to be alike, and they are not reported
+again.`,
+		Example: `  opun prompt dedupe
+  opun prompt dedupe --threshold 0.6 --list`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if threshold <= 0 || threshold > 1 {
+				return fmt.Errorf("--threshold must be between 0 and 1, got %g", threshold)
+			}
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+			clusters, err := garden.FindDuplicates(threshold)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				if clusters == nil {
+					clusters = []promptgarden.DuplicateCluster{}
+				}
+				data, err := json.MarshalIndent(clusters, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			if len(clusters) == 0 {
+				fmt.Printf("✅ No duplicate prompts (%.0f%% alike or more)\n", threshold*100)
+				return nil
+			}
+			if list {
+				for _, cluster := range clusters {
+					printDuplicateCluster(os.Stdout, cluster)
+				}
+				return nil
+			}
+			return dedupeClusters(garden, clusters, bufio.NewReader(os.Stdin), os.Stdout)
+		},
+	}
+
+	cmd.Flags().Float64Var(&threshold, "threshold", promptgarden.DefaultSimilarity, "how alike prompts must be to count as duplicates, from 0 to 1")
+	cmd.Flags().BoolVar(&list, "list", false, "only list the clusters")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the clusters as JSON")
+
+	return cmd
+}
+
+// printDuplicateCluster shows a cluster's prompts, numbered
+func printDuplicateCluster(out io.Writer, cluster promptgarden.DuplicateCluster) {
+	if cluster.Exact {
+		fmt.Fprintf(out, "\n🔁 %d identical prompts:\n", len(cluster.Prompts))
+	} else {
+		fmt.Fprintf(out, "\n🔁 %d similar prompts (%.0f%% alike or more):\n", len(cluster.Prompts), cluster.Similarity*100)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, prompt := range cluster.Prompts {
+		fmt.Fprintf(w, "  %d.\t%s\t%s\t%s\n", i+1, prompt.Name, prompt.Metadata.Version, promptSnippet(prompt))
+	}
+	_ = w.Flush()
+}
+
+// promptSnippet is the description of a prompt, or its first line
+func promptSnippet(prompt *promptgarden.Prompt) string {
+	snippet := prompt.Metadata.Description
+	if snippet == "" {
+		snippet, _, _ = strings.Cut(strings.TrimSpace(prompt.Content), "\n")
+	}
+	if len(snippet) > 60 {
+		snippet = snippet[:57] + "..."
+	}
+	return snippet
+}
+
+// dedupeClusters asks what to do with each cluster, reading choices from in
+func dedupeClusters(garden *promptgarden.Garden, clusters []promptgarden.DuplicateCluster, in *bufio.Reader, out io.Writer) error {
+	merged := 0
+	for _, cluster := range clusters {
+		printDuplicateCluster(out, cluster)
+		fmt.Fprintf(out, "Keep one and merge the others into it [1-%d], [k]eep all, [s]kip or [q]uit? ", len(cluster.Prompts))
+		answer, err := in.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(out)
+			break
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		switch answer {
+		case "k", "keep":
+			if err := garden.KeepDistinct(cluster.Names); err != nil {
+				return fmt.Errorf("failed to record kept prompts: %w", err)
+			}
+			fmt.Fprintf(out, "✓ Keeping %s\n", strings.Join(cluster.Names, ", "))
+			continue
+		case "", "s", "skip":
+			continue
+		case "q", "quit":
+			return nil
+		}
+		choice, err := strconv.Atoi(answer)
+		if err != nil || choice < 1 || choice > len(cluster.Prompts) {
+			fmt.Fprintf(out, "⚠️  Invalid choice %q, skipping\n", answer)
+			continue
+		}
+		n, err := mergePrompts(garden, cluster.Prompts[choice-1], cluster.Prompts, out)
+		if err != nil {
+			return err
+		}
+		merged += n
+	}
+	if merged > 0 {
+		fmt.Fprintf(out, "✅ Merged %d duplicate prompts\n", merged)
+	}
+	return nil
+}
+
+// mergePrompts merges duplicates into keep: keep gets their tags and they are
+// deleted, except built-in prompts and prompts that are still referenced.
+// It returns how many prompts were deleted.
+func mergePrompts(garden *promptgarden.Garden, keep *promptgarden.Prompt, duplicates []*promptgarden.Prompt, out io.Writer) (int, error) {
+	tags := append([]string{}, keep.Metadata.Tags...)
+	deleted := 0
+	for _, prompt := range duplicates {
+		if prompt.ID == keep.ID {
+			continue
+		}
+		if promptgarden.IsBuiltin(prompt.Name) {
+			fmt.Fprintf(out, "  Keeping built-in prompt '%s'\n", prompt.Name)
+			continue
+		}
+		deps, err := findDependents("prompt", prompt.Name)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to check references: %w", err)
+		}
+		if len(deps) > 0 {
+			fmt.Fprintf(out, "  ⚠️  Keeping '%s', still referenced by %s %s; point it to '%s' and run dedupe again\n", prompt.Name, deps[0].kind, deps[0].name, keep.Name)
+			continue
+		}
+		for _, tag := range prompt.Metadata.Tags {
+			if !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if err := garden.DeletePrompt(prompt.ID); err != nil {
+			return deleted, fmt.Errorf("failed to delete prompt '%s': %w", prompt.Name, err)
+		}
+		fmt.Fprintf(out, "  ✓ Merged '%s' into '%s'\n", prompt.Name, keep.Name)
+		deleted++
+	}
+	if len(tags) > len(keep.Metadata.Tags) {
+		keep.Metadata.Tags = tags
+		if err := garden.SavePrompt(keep); err != nil {
+			return deleted, fmt.Errorf("failed to update prompt '%s': %w", keep.Name, err)
+		}
+	}
+	return deleted, nil
+}
+
+// warnSimilarPrompts points out garden prompts that look like one being
+// added. When asking, it returns false if the user doesn't want to add it.
+func warnSimilarPrompts(garden *promptgarden.Garden, name, content, onConflict string, in *bufio.Reader, out io.Writer) (bool, error) {
+	matches, err := garden.SimilarPrompts(content, name, promptgarden.DefaultSimilarity)
+	if err != nil || len(matches) == 0 {
+		return true, err
+	}
+	similar := make([]string, len(matches))
+	for i, match := range matches {
+		if match.Exact {
+			similar[i] = match.Name + " (identical)"
+		} else {
+			similar[i] = fmt.Sprintf("%s (%.0f%% alike)", match.Name, match.Similarity*100)
+		}
+	}
+	fmt.Fprintf(out, "⚠️  '%s' looks like prompts already in the garden: %s\n", name, strings.Join(similar, ", "))
+	if onConflict != conflictAsk {
+		fmt.Fprintln(out, "   Merge duplicates with: opun prompt dedupe")
+		return true, nil
+	}
+	fmt.Fprint(out, "Add it anyway? [y/N] ")
+	answer, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	fmt.Fprintf(out, "Skipped '%s'\n", name)
+	return false, nil
+}
@@ -0,0 +1,93 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeClusters(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	garden, err := openPromptGarden()
+	require.NoError(t, err)
+	review := "Review the following diff for bugs, missing tests and unclear names. List blocking issues first: {{diff}}"
+	for name, tags := range map[string][]string{"review": {"code"}, "review-2": {"go"}, "review-3": {"pr"}} {
+		require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{ID: name, Name: name, Content: review, Metadata: promptgarden.PromptMetadata{Tags: tags, Version: "1.0.0"}}))
+	}
+	summary := "Summarize this document in three bullet points: {{doc}}"
+	for _, name := range []string{"summarize", "summary"} {
+		require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{ID: name, Name: name, Content: summary}))
+	}
+	// A workflow still uses review-3
+	workflows := filepath.Join(home, ".opun", "workflows")
+	require.NoError(t, os.MkdirAll(workflows, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workflows, "pr.yaml"), []byte("name: pr\nagents:\n  - prompt: promptgarden://review-3\n"), 0644))
+
+	clusters, err := garden.FindDuplicates(promptgarden.DefaultSimilarity)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+
+	var out bytes.Buffer
+	require.NoError(t, dedupeClusters(garden, clusters, bufio.NewReader(strings.NewReader("1\nk\n")), &out))
+	assert.Contains(t, out.String(), "Merged 'review-2' into 'review'")
+	assert.Contains(t, out.String(), "Keeping 'review-3', still referenced by workflow pr")
+	assert.Contains(t, out.String(), "Keeping summarize, summary")
+
+	_, err = garden.GetPrompt("review-2")
+	assert.Error(t, err)
+	kept, err := garden.GetPrompt("review-3")
+	require.NoError(t, err)
+	assert.Equal(t, review, kept.Content)
+	merged, err := garden.GetPrompt("review")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"code", "go"}, merged.Metadata.Tags)
+
+	clusters, err = garden.FindDuplicates(promptgarden.DefaultSimilarity)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, []string{"review", "review-3"}, clusters[0].Names)
+}
+
+func TestWarnSimilarPrompts(t *testing.T) {
+	garden, err := promptgarden.NewGarden(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{ID: "summary", Name: "summary", Content: "Summarize this document in three bullet points: {{doc}}"}))
+
+	var out bytes.Buffer
+	add, err := warnSimilarPrompts(garden, "summarize", "summarize this document in three bullet points: {{doc}}", conflictFail, nil, &out)
+	require.NoError(t, err)
+	assert.True(t, add)
+	assert.Contains(t, out.String(), "summary (identical)")
+
+	add, err = warnSimilarPrompts(garden, "summarize", "Summarize this document in three bullet points: {{doc}}", conflictAsk, bufio.NewReader(strings.NewReader("n\n")), &out)
+	require.NoError(t, err)
+	assert.False(t, add)
+
+	add, err = warnSimilarPrompts(garden, "translate", "Translate {{text}} to French.", conflictAsk, nil, &out)
+	require.NoError(t, err)
+	assert.True(t, add)
+}
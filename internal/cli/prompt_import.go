@@ -0,0 +1,158 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/rizome-dev/opun/internal/mcp"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/provenance"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/run"
+)
+
+// fetchPromptURL downloads a prompt, converting HTML pages to text. The user
+// gave the URL, so any public host is fine.
+var fetchPromptURL = func(ctx context.Context, rawURL string) (string, error) {
+	return mcp.NewWebTools(mcp.AnyHostWebPolicy()).Fetch(ctx, rawURL, false)
+}
+
+// addPromptFromURL fetches a prompt from a web page or gist and adds it to the
+// prompt garden, recording the URL so the prompt can be checked for updates.
+// With cleanup an agent extracts the prompt and its metadata from the page.
+func addPromptFromURL(rawURL, name string, cleanup bool, provider, onConflict string) error {
+	fetchURL, err := promptgarden.RawPromptURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	fmt.Printf("🌐 Fetching %s\n", fetchURL)
+	page, err := fetchPromptURL(ctx, fetchURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", fetchURL, err)
+	}
+	if strings.TrimSpace(page) == "" {
+		return fmt.Errorf("%s is empty", fetchURL)
+	}
+
+	u, err := url.Parse(fetchURL)
+	if err != nil {
+		return err
+	}
+	path := u.Path
+	data := []byte(page)
+	metadata := promptgarden.PromptMetadata{
+		Tags:        extractTags(page),
+		Description: fmt.Sprintf("Prompt imported from %s", rawURL),
+	}
+	if cleanup {
+		imported, err := cleanupImportedPrompt(ctx, rawURL, page, provider)
+		if err != nil {
+			return err
+		}
+		// The agent's answer is plain text whatever the page was
+		path = "prompt.md"
+		data = []byte(imported.Content)
+		metadata.Tags = imported.Tags
+		if imported.Description != "" {
+			metadata.Description = imported.Description
+		}
+		if name == "" {
+			name = imported.Name
+		}
+	}
+	if name == "" {
+		name = promptgarden.PromptNameFromURL(fetchURL)
+	}
+	if name == "" {
+		return fmt.Errorf("can't name the prompt from %s, pass --name", rawURL)
+	}
+
+	saved, err := savePromptData(path, name, data, metadata, onConflict)
+	if err != nil || saved == "" {
+		return err
+	}
+
+	registry, err := provenance.Path()
+	if err != nil {
+		return err
+	}
+	if err := provenance.Mark(registry, provenance.Item{
+		Kind:   "prompt",
+		Name:   saved,
+		Source: provenance.SourceURL,
+		Origin: rawURL,
+		Digest: promptgarden.ContentDigest([]byte(page)),
+	}); err != nil {
+		return fmt.Errorf("failed to record the prompt's source: %w", err)
+	}
+	fmt.Printf("  Source: %s\n", rawURL)
+	return nil
+}
+
+// cleanupImportedPrompt runs the built-in prompt-import workflow on a fetched
+// page and returns the prompt its agent extracted
+func cleanupImportedPrompt(ctx context.Context, rawURL, page, provider string) (promptgarden.ImportedPrompt, error) {
+	wf, err := loadWorkflow(workflow.PromptImportWorkflow)
+	if err != nil {
+		return promptgarden.ImportedPrompt{}, fmt.Errorf("failed to load workflow: %w", err)
+	}
+	if provider != "" {
+		for i := range wf.Agents {
+			wf.Agents[i].Provider, wf.Agents[i].Model = provider, ""
+		}
+	}
+	if err := ensureWorkflowRequirements(wf); err != nil {
+		return promptgarden.ImportedPrompt{}, err
+	}
+	policy, err := loadPromptPolicy()
+	if err != nil {
+		return promptgarden.ImportedPrompt{}, err
+	}
+
+	runsDir, err := workflow.RunsDir()
+	if err != nil {
+		return promptgarden.ImportedPrompt{}, err
+	}
+	outputDir := headlessOutputDir(wf, filepath.Join(runsDir, "import"), "")
+	fmt.Println("🧹 Extracting the prompt from the page")
+	runner := workflow.NewMatrixRunner(outputDir, 1)
+	runner.Policy = policy
+	result, err := runner.RunHeadless(ctx, wf, run.Variables(wf, map[string]string{
+		"source_url": rawURL,
+		"page":       page,
+	}))
+	if err != nil {
+		return promptgarden.ImportedPrompt{}, err
+	}
+	output, err := os.ReadFile(result.Outputs["extract"])
+	if err != nil {
+		return promptgarden.ImportedPrompt{}, fmt.Errorf("cleanup produced no prompt: %w", err)
+	}
+	return promptgarden.ParseImportedPrompt(string(output))
+}
@@ -0,0 +1,70 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddPromptFromURL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	var fetched []string
+	original := fetchPromptURL
+	fetchPromptURL = func(ctx context.Context, rawURL string) (string, error) {
+		fetched = append(fetched, rawURL)
+		return "#review #go\nReview {{diff}} for bugs.\n", nil
+	}
+	t.Cleanup(func() { fetchPromptURL = original })
+
+	source := "https://github.com/acme/prompts/blob/main/code-review.md"
+	require.NoError(t, addPromptFromURL(source, "", false, "", conflictFail))
+	assert.Equal(t, []string{"https://raw.githubusercontent.com/acme/prompts/main/code-review.md"}, fetched)
+
+	garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+	require.NoError(t, err)
+	prompt, err := garden.GetPrompt("code-review")
+	require.NoError(t, err)
+	assert.Contains(t, prompt.Content, "Review {{diff}} for bugs.")
+	assert.Equal(t, []string{"review", "go"}, prompt.Metadata.Tags)
+	assert.Equal(t, "Prompt imported from "+source, prompt.Metadata.Description)
+
+	registry, err := provenance.Path()
+	require.NoError(t, err)
+	item, err := provenance.Find(registry, "prompt", "code-review")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, provenance.SourceURL, item.Source)
+	assert.Equal(t, source, item.Origin)
+	assert.Equal(t, promptgarden.ContentDigest([]byte("#review #go\nReview {{diff}} for bugs.\n")), item.Digest)
+	assert.False(t, item.ReadOnly)
+
+	// Fetching it again changes nothing, and a changed prompt conflicts
+	require.NoError(t, addPromptFromURL(source, "", false, "", conflictFail))
+	fetchPromptURL = func(ctx context.Context, rawURL string) (string, error) {
+		return "Review {{diff}} for bugs and style.\n", nil
+	}
+	assert.Error(t, addPromptFromURL(source, "", false, "", conflictFail))
+	assert.ErrorContains(t, addPromptFromURL("ftp://example.com/p.md", "p", false, "", conflictFail), "http or https")
+}
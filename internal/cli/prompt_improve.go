@@ -0,0 +1,381 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// maxImproveFailures caps how many failed runs of a prompt are shown to the proposer
+const maxImproveFailures = 10
+
+// promptImproveCmd runs the built-in prompt-improve workflow on a prompt
+func promptImproveCmd() *cobra.Command {
+	var (
+		provider          string
+		model             string
+		evaluatorProvider string
+		evaluatorModel    string
+		timeout           time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "improve <name>",
+		Short: "Propose an improved version of a prompt",
+		Long: `Run the built-in prompt-improve workflow on a prompt garden prompt. A
+proposer agent rewrites the prompt from the ratings, notes and failed runs
+collected for it, then an evaluator agent judges the rewrite against the
+prompt's test cases.
+
+Test cases are read from ~/.opun/promptgarden/tests/<name>.yaml:
+
+  - name: empty diff
+    vars:
+      diff: ""
+    expect: Says there is nothing to review instead of inventing issues
+
+A rewrite that passes is saved as the prompt's next version, pending
+approval with opun prompt approve. Nothing changes until then.
+
+Install your own prompt-improve workflow in ~/.opun/workflows to change how
+proposals are made and judged.`,
+		Example: `  opun prompt improve code-review
+  opun prompt improve code-review --provider claude --evaluator-provider gemini
+  opun prompt pending code-review
+  opun prompt approve code-review`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+			existing, err := garden.GetByName(name)
+			if err != nil {
+				return err
+			}
+			prompt, err := garden.GetPrompt(existing.ID())
+			if err != nil {
+				return err
+			}
+
+			cases, err := garden.LoadTestCases(name)
+			if err != nil {
+				return err
+			}
+			if len(cases) == 0 {
+				return fmt.Errorf("%s has no test cases to evaluate an improvement against; add them to %s", name, garden.TestsPath(name))
+			}
+
+			runsDir, err := workflow.RunsDir()
+			if err != nil {
+				return err
+			}
+			outcomes, err := promptgarden.LoadOutcomes(runsDir, name)
+			if err != nil {
+				return fmt.Errorf("failed to read prompt history: %w", err)
+			}
+			historyDir, err := workflow.HistoryDir()
+			if err != nil {
+				return err
+			}
+			records, err := workflow.ListRunRecords(historyDir)
+			if err != nil {
+				return fmt.Errorf("failed to read run history: %w", err)
+			}
+
+			wf, err := loadWorkflow(workflow.PromptImproveWorkflow)
+			if err != nil {
+				return fmt.Errorf("failed to load workflow: %w", err)
+			}
+			if err := ensureWorkflowRequirements(wf); err != nil {
+				return err
+			}
+			for i := range wf.Agents {
+				agentProvider, agentModel := provider, model
+				if wf.Agents[i].ID == "evaluate" && evaluatorProvider != "" {
+					agentProvider, agentModel = evaluatorProvider, evaluatorModel
+				}
+				if agentProvider != "" {
+					wf.Agents[i].Provider = agentProvider
+					wf.Agents[i].Model = agentModel
+				}
+			}
+			policy, err := loadPromptPolicy()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+			ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+			defer cancelTimeout()
+
+			outputDir := headlessOutputDir(wf, filepath.Join(runsDir, "improve", name), "")
+			fmt.Printf("🌱 Improving %s (%d test cases)\n", name, len(cases))
+			runner := workflow.NewMatrixRunner(outputDir, 1)
+			runner.Policy = policy
+			result, err := runner.RunHeadless(ctx, wf, run.Variables(wf, map[string]string{
+				"prompt_name":    name,
+				"prompt_content": prompt.Content,
+				"feedback":       improvementFeedback(name, outcomes, records),
+				"test_cases":     formatTestCases(cases),
+			}))
+			fmt.Printf("📁 Outputs: %s (%.1fs)\n", outputDir, result.Duration)
+			if err != nil {
+				return err
+			}
+
+			proposal, err := os.ReadFile(result.Outputs["propose"])
+			if err != nil {
+				return fmt.Errorf("workflow produced no proposal: %w", err)
+			}
+			evaluation, err := os.ReadFile(result.Outputs["evaluate"])
+			if err != nil {
+				return fmt.Errorf("workflow produced no evaluation: %w", err)
+			}
+			verdict, err := promptgarden.ParseVerdict(string(evaluation))
+			if err != nil {
+				return err
+			}
+			content := promptgarden.StripCodeFence(string(proposal))
+
+			if !verdict.Pass {
+				fmt.Printf("❌ The proposed version failed evaluation (score %.2f)\n", verdict.Score)
+				if verdict.Notes != "" {
+					fmt.Println(verdict.Notes)
+				}
+				return workflow.Classify(workflow.ErrorGateFailed, fmt.Errorf("improved %s did not pass its test cases", name))
+			}
+			if content == "" || content == strings.TrimSpace(prompt.Content) {
+				fmt.Printf("✅ No changes proposed for %s\n", name)
+				return nil
+			}
+
+			pending := &promptgarden.PendingVersion{
+				Prompt:      name,
+				BaseVersion: prompt.Metadata.Version,
+				Version:     promptgarden.NextVersion(prompt.Metadata.Version),
+				Content:     content,
+				Verdict:     verdict,
+				OutputDir:   outputDir,
+			}
+			if err := garden.SavePending(pending); err != nil {
+				return fmt.Errorf("failed to save pending version: %w", err)
+			}
+			fmt.Printf("✅ %s %s passed evaluation (score %.2f) and is pending approval\n", name, pending.Version, verdict.Score)
+			fmt.Printf("   Review it with: opun prompt pending %s\n", name)
+			fmt.Printf("   Then run: opun prompt approve %s (or reject)\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&provider, "provider", "", "provider for both agents (default: the workflow's)")
+	cmd.Flags().StringVar(&model, "model", "", "model for both agents")
+	cmd.Flags().StringVar(&evaluatorProvider, "evaluator-provider", "", "provider for the evaluator agent")
+	cmd.Flags().StringVar(&evaluatorModel, "evaluator-model", "", "model for the evaluator agent")
+	cmd.Flags().DurationVar(&timeout, "timeout", 20*time.Minute, "maximum time for proposing and evaluating")
+
+	return cmd
+}
+
+// promptPendingCmd lists pending prompt versions or shows one of them
+func promptPendingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending [name]",
+		Short: "List improved prompt versions waiting for approval",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				pending, err := garden.LoadPending(args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("📝 %s %s (from %s), score %.2f\n", pending.Prompt, pending.Version, pending.BaseVersion, pending.Verdict.Score)
+				if pending.Verdict.Notes != "" {
+					fmt.Printf("\n%s\n", pending.Verdict.Notes)
+				}
+				fmt.Printf("\n%s\n", pending.Content)
+				return nil
+			}
+
+			list, err := garden.ListPending()
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Println("No prompt versions pending approval")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROMPT\tVERSION\tSCORE\tPROPOSED")
+			for _, pending := range list {
+				fmt.Fprintf(w, "%s\t%s -> %s\t%.2f\t%s\n", pending.Prompt, pending.BaseVersion, pending.Version, pending.Verdict.Score, pending.Created.Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// promptApproveCmd makes a pending version the prompt's current one
+func promptApproveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve <name>",
+		Short: "Apply the pending improved version of a prompt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+			prompt, err := garden.ApprovePending(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s is now at version %s\n", prompt.Name, prompt.Metadata.Version)
+			return nil
+		},
+	}
+}
+
+// promptRejectCmd discards a pending version
+func promptRejectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reject <name>",
+		Short: "Discard the pending improved version of a prompt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+			if err := garden.RejectPending(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("🗑️  Discarded the pending version of %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// openPromptGarden opens the user's prompt garden
+func openPromptGarden() (*promptgarden.Garden, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to access prompt garden: %w", err)
+	}
+	return garden, nil
+}
+
+// improvementFeedback describes what is known to go wrong with a prompt: the
+// feedback on workflow runs that used it and its latest failed runs
+func improvementFeedback(name string, outcomes []promptgarden.Outcome, records []*workflow.RunRecord) string {
+	var lines []string
+	for _, record := range records {
+		if !usesPrompt(record, name) {
+			continue
+		}
+		for _, fb := range record.Feedback {
+			target := record.Workflow
+			if fb.Agent != "" {
+				target += "/" + fb.Agent
+			}
+			line := fmt.Sprintf("- Rated %d/%d on %s", fb.Rating, workflow.MaxRating, target)
+			if fb.Note != "" {
+				line += ": " + fb.Note
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	var failures []promptgarden.Outcome
+	for _, outcome := range outcomes {
+		if !outcome.Success {
+			failures = append(failures, outcome)
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Time.After(failures[j].Time) })
+	if len(failures) > maxImproveFailures {
+		failures = failures[:maxImproveFailures]
+	}
+	for _, outcome := range failures {
+		line := fmt.Sprintf("- Failed on %s", outcome.Provider)
+		if outcome.Variant != "" {
+			line += fmt.Sprintf(" (variant %s)", outcome.Variant)
+		}
+		if outcome.Error != "" {
+			line += ": " + outcome.Error
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return "No feedback collected yet."
+	}
+	return strings.Join(lines, "\n")
+}
+
+// usesPrompt reports whether a recorded run used the named garden prompt
+func usesPrompt(record *workflow.RunRecord, name string) bool {
+	for _, prompt := range record.Prompts {
+		if prompt.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTestCases lists test cases for the improvement workflow's agents
+func formatTestCases(cases []promptgarden.TestCase) string {
+	var sb strings.Builder
+	for i, tc := range cases {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, tc.Name)
+		keys := make([]string, 0, len(tc.Vars))
+		for key := range tc.Vars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&sb, "   %s: %s\n", key, tc.Vars[key])
+		}
+		fmt.Fprintf(&sb, "   Expected: %s\n", tc.Expect)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,62 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImprovementFeedback(t *testing.T) {
+	assert.Equal(t, "No feedback collected yet.", improvementFeedback("review", nil, nil))
+
+	now := time.Now()
+	records := []*workflow.RunRecord{
+		{
+			RunManifest: workflow.RunManifest{
+				Workflow:     "pr-check",
+				RunInventory: workflow.RunInventory{Prompts: []workflow.ManifestItem{{Name: "review", Version: "1.0.0"}}},
+			},
+			Feedback: []workflow.Feedback{{Agent: "reviewer", Rating: 2, Note: "too vague"}},
+		},
+		{
+			RunManifest: workflow.RunManifest{Workflow: "other"},
+			Feedback:    []workflow.Feedback{{Rating: 1, Note: "unrelated"}},
+		},
+	}
+	outcomes := []promptgarden.Outcome{
+		{Time: now.Add(-time.Hour), Provider: "claude", Success: false, Error: "timeout"},
+		{Time: now, Provider: "gemini", Variant: "short", Success: false, Error: "empty answer"},
+		{Time: now, Provider: "claude", Success: true},
+	}
+
+	assert.Equal(t, "- Rated 2/5 on pr-check/reviewer: too vague\n"+
+		"- Failed on gemini (variant short): empty answer\n"+
+		"- Failed on claude: timeout", improvementFeedback("review", outcomes, records))
+}
+
+func TestFormatTestCases(t *testing.T) {
+	cases := []promptgarden.TestCase{
+		{Name: "empty", Vars: map[string]string{"lang": "go", "diff": ""}, Expect: "Says there is nothing to review"},
+		{Name: "sqli", Expect: "Flags the injection"},
+	}
+	assert.Equal(t, "1. empty\n   diff: \n   lang: go\n   Expected: Says there is nothing to review\n2. sqli\n   Expected: Flags the injection", formatTestCases(cases))
+}
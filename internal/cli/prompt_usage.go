@@ -0,0 +1,232 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/spf13/cobra"
+)
+
+// Orders of opun prompt list
+const (
+	sortByName  = "name"
+	sortByUsage = "usage"
+)
+
+// promptListEntry is a prompt with its usage, as listed by opun prompt list
+type promptListEntry struct {
+	promptgarden.Summary
+	Uses     int        `json:"uses"`
+	LastUsed *time.Time `json:"last_used,omitempty"`
+}
+
+// promptListCmd lists the garden's prompts with how often they are used
+func promptListCmd() *cobra.Command {
+	var (
+		sortBy string
+		asJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List prompts with how often they are used",
+		Long: `List the prompt garden's prompts with how many times each was used and
+when it was last used. Uses are counted when a prompt is rendered (opun prompt
+exec, opun map, MCP prompt_ tools and slash commands) and once per run of a
+workflow that references it with promptgarden://.
+
+Uses are recorded in ~/.opun/promptgarden/` + promptgarden.UsageFile + `.`,
+		Example: `  opun prompt list --sort usage
+  opun prompt list --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sortBy != sortByName && sortBy != sortByUsage {
+				return fmt.Errorf("--sort must be %s or %s, got %q", sortByName, sortByUsage, sortBy)
+			}
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+			usage, err := garden.Usage()
+			if err != nil {
+				return fmt.Errorf("failed to read prompt usage: %w", err)
+			}
+
+			summaries := garden.Summaries()
+			entries := make([]promptListEntry, len(summaries))
+			for i, summary := range summaries {
+				entries[i] = promptListEntry{Summary: summary}
+				if stats, ok := usage[summary.Name]; ok {
+					lastUsed := stats.LastUsed
+					entries[i].Uses, entries[i].LastUsed = stats.Count, &lastUsed
+				}
+			}
+			sortPromptEntries(entries, sortBy)
+
+			if asJSON {
+				data, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			if len(entries) == 0 {
+				fmt.Println("No prompts in the garden")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tUSES\tLAST USED\tDESCRIPTION")
+			for _, entry := range entries {
+				lastUsed := "never"
+				if entry.LastUsed != nil {
+					lastUsed = formatAge(time.Since(*entry.LastUsed)) + " ago"
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", entry.Name, entry.Uses, lastUsed, entry.Description)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&sortBy, "sort", sortByName, "order prompts by name or usage (most used first)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the prompts as JSON")
+
+	return cmd
+}
+
+// sortPromptEntries orders prompts by name, or by uses and then recency
+func sortPromptEntries(entries []promptListEntry, sortBy string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if sortBy == sortByUsage {
+			if a.Uses != b.Uses {
+				return a.Uses > b.Uses
+			}
+			if a.LastUsed != nil && b.LastUsed != nil && !a.LastUsed.Equal(*b.LastUsed) {
+				return a.LastUsed.After(*b.LastUsed)
+			}
+		}
+		return a.Name < b.Name
+	})
+}
+
+// promptPruneCmd suggests prompts to delete because nobody uses them
+func promptPruneCmd() *cobra.Command {
+	var (
+		unusedSince string
+		asJSON      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Suggest prompts to delete because they went unused",
+		Long: `List the prompts that were neither used nor changed in the given time, with
+the command to delete each. Nothing is deleted: check the suggestions, then
+run the ones you agree with. Built-in prompts are never suggested.`,
+		Example: `  opun prompt prune --unused-since 90d
+  opun prompt prune --unused-since 2w --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(unusedSince)
+			if err != nil {
+				return fmt.Errorf("invalid --unused-since: %w", err)
+			}
+			garden, err := openPromptGarden()
+			if err != nil {
+				return err
+			}
+			unused, err := garden.UnusedSince(time.Now().Add(-age))
+			if err != nil {
+				return fmt.Errorf("failed to read prompt usage: %w", err)
+			}
+
+			if asJSON {
+				if unused == nil {
+					unused = []promptgarden.UnusedPrompt{}
+				}
+				data, err := json.MarshalIndent(unused, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			if len(unused) == 0 {
+				fmt.Printf("✅ Every prompt was used or changed in the last %s\n", unusedSince)
+				return nil
+			}
+			fmt.Printf("🧹 %d prompts unused in the last %s:\n\n", len(unused), unusedSince)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tLAST USED\tCHANGED\tDELETE WITH")
+			for _, prompt := range unused {
+				lastUsed := "never"
+				if !prompt.LastUsed.IsZero() {
+					lastUsed = formatAge(time.Since(prompt.LastUsed)) + " ago"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s ago\topun delete prompt %s\n", prompt.Name, lastUsed, formatAge(time.Since(prompt.Modified)), prompt.Name)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&unusedSince, "unused-since", "90d", "how long a prompt must have gone unused, e.g. 90d, 2w or 720h")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the suggestions as JSON")
+
+	return cmd
+}
+
+// parseAge parses a duration that may also be given in days (90d) or weeks (2w)
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive, got %s", s)
+	}
+	return d, nil
+}
+
+// formatAge renders an age in the largest whole unit: days, hours or minutes
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{"90d": 90 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "36h": 36 * time.Hour} {
+		got, err := parseAge(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "d", "-3d", "0h", "soon"} {
+		_, err := parseAge(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestSortPromptEntries(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	entries := []promptListEntry{
+		{Summary: promptgarden.Summary{Name: "b"}},
+		{Summary: promptgarden.Summary{Name: "c"}, Uses: 3, LastUsed: &earlier},
+		{Summary: promptgarden.Summary{Name: "a"}, Uses: 3, LastUsed: &now},
+		{Summary: promptgarden.Summary{Name: "d"}, Uses: 1, LastUsed: &now},
+	}
+	sortPromptEntries(entries, sortByUsage)
+	names := []string{entries[0].Name, entries[1].Name, entries[2].Name, entries[3].Name}
+	assert.Equal(t, []string{"a", "c", "d", "b"}, names)
+
+	sortPromptEntries(entries, sortByName)
+	assert.Equal(t, "a", entries[0].Name)
+	assert.Equal(t, "d", entries[3].Name)
+}
+
+func TestRecordPromptUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	garden, err := openPromptGarden()
+	require.NoError(t, err)
+	require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{ID: "review", Name: "review", Content: "Review {{diff}}"}))
+
+	recordPromptUsage(&wf.Workflow{Agents: []wf.Agent{
+		{ID: "one", Prompt: "Use promptgarden://review and promptgarden://missing"},
+		{ID: "two", SystemPrompt: "promptgarden://review"},
+	}})
+	usage, err := garden.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage["review"].Count)
+	assert.Equal(t, map[string]int{promptgarden.UsageWorkflow: 1}, usage["review"].Sources)
+	assert.NotContains(t, usage, "missing")
+}
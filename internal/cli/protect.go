@@ -0,0 +1,102 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rizome-dev/opun/internal/provenance"
+	"golang.org/x/term"
+)
+
+// guardManaged checks an update of an item against where it was installed
+// from. Read-only items are forked into a personal copy from path instead
+// when --fork is set or the user agrees; proceed is false when there is
+// nothing left to update. An empty path is an in-place edit, which can't be
+// forked.
+func guardManaged(kind, name, path string, fork, force bool) (proceed bool, err error) {
+	registry, err := provenance.Path()
+	if err != nil {
+		return false, err
+	}
+	item, err := provenance.Find(registry, kind, name)
+	if err != nil {
+		return false, err
+	}
+	if item == nil || !item.ReadOnly {
+		return true, nil
+	}
+
+	forkName := provenance.ForkPrefix + name
+	fmt.Printf("⚠️  Warning: %s '%s' is managed by %s and read-only; local changes diverge from the shared version and are overwritten by the next pull\n", kind, name, item.Describe())
+	if force {
+		return true, nil
+	}
+
+	if !fork {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			if path == "" {
+				return false, fmt.Errorf("%s '%s' is read-only, change it through %s", kind, name, item.Describe())
+			}
+			return false, fmt.Errorf("%s '%s' is read-only, use --fork to add your version as '%s' or --force to change it anyway", kind, name, forkName)
+		}
+
+		if path != "" {
+			fork, err = Confirm(fmt.Sprintf("Add your version as '%s' in your personal namespace instead?", forkName))
+			if err != nil {
+				return false, err
+			}
+		}
+		if !fork {
+			anyway, err := Confirm(fmt.Sprintf("Change managed %s '%s' anyway?", kind, name))
+			if err != nil {
+				return false, err
+			}
+			if !anyway {
+				fmt.Println("Update cancelled.")
+			}
+			return anyway, nil
+		}
+	}
+
+	if path == "" {
+		return false, fmt.Errorf("--fork needs the new version's --path")
+	}
+	if err := forkManaged(kind, forkName, path); err != nil {
+		return false, err
+	}
+	fmt.Printf("🍴 Forked %s '%s' as '%s', pulls leave it alone\n", kind, name, forkName)
+	return false, nil
+}
+
+// forkManaged adds the file at path as a user-owned item
+func forkManaged(kind, name, path string) error {
+	policy, err := conflictPolicy("")
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "workflow":
+		return addWorkflow(path, name, policy)
+	case "prompt":
+		return addPrompt(path, name, policy)
+	case "action":
+		return addActionFromFile(path, name, policy)
+	}
+	return fmt.Errorf("cannot fork %s '%s'", kind, name)
+}
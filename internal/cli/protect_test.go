@@ -0,0 +1,65 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardManaged(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	registry, err := provenance.Path()
+	require.NoError(t, err)
+	require.NoError(t, provenance.Mark(registry,
+		provenance.Item{Kind: "prompt", Name: "review", Source: provenance.SourceRemote, Origin: "origin/team", ReadOnly: true},
+		provenance.Item{Kind: "prompt", Name: "shared", Source: provenance.SourceRemote, Origin: "origin/team"},
+	))
+
+	path := filepath.Join(t.TempDir(), "review.md")
+	require.NoError(t, os.WriteFile(path, []byte("Review {{diff}} carefully\n"), 0644))
+
+	proceed, err := guardManaged("prompt", "mine", path, false, false)
+	require.NoError(t, err)
+	assert.True(t, proceed, "the user's own items update freely")
+
+	proceed, err = guardManaged("prompt", "shared", path, false, false)
+	require.NoError(t, err)
+	assert.True(t, proceed, "items the user maintains update freely")
+
+	_, err = guardManaged("prompt", "review", path, false, false)
+	assert.ErrorContains(t, err, "--fork")
+
+	proceed, err = guardManaged("prompt", "review", path, false, true)
+	require.NoError(t, err)
+	assert.True(t, proceed)
+
+	proceed, err = guardManaged("prompt", "review", path, true, false)
+	require.NoError(t, err)
+	assert.False(t, proceed)
+	garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+	require.NoError(t, err)
+	_, err = garden.GetByName(provenance.ForkPrefix + "review")
+	assert.NoError(t, err)
+}
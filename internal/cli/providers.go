@@ -0,0 +1,160 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+// ProvidersCmd creates the providers command
+func ProvidersCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		cached     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "providers [provider...]",
+		Short: "Probe provider CLIs and show what they support",
+		Long: `Run each provider CLI with --version and --help to find its version, whether
+it has a non-interactive print mode, JSON output, session resume and MCP
+support, and the models its help lists, then show them as a feature matrix.
+
+The results are cached in ~/.opun/providers.json for a day. Headless runs use
+the cached print flag, and agents that 'require' capabilities such as mcp,
+session-continuation or json-output are matched against what was probed.
+--cached shows the cache without probing.
+
+Provider CLIs are found in PATH under their own names unless
+providers.<name>.binary in the config gives a path or a wrapper command to
+start them with, such as [mise, exec, --].`,
+		Example: `  opun providers
+  opun providers claude --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := args
+			if len(names) == 0 {
+				names = providers.KnownProviders
+			}
+			path, err := providers.ProbePath()
+			if err != nil {
+				return err
+			}
+
+			var probes []providers.Features
+			if cached {
+				all, err := providers.LoadProbes(path)
+				if err != nil {
+					return err
+				}
+				for _, name := range names {
+					if f, ok := all[strings.ToLower(name)]; ok {
+						probes = append(probes, f)
+					}
+				}
+			} else {
+				for _, name := range names {
+					probes = append(probes, providers.Probe(cmd.Context(), strings.ToLower(name)))
+				}
+				if err := providers.SaveProbes(path, probes); err != nil {
+					return fmt.Errorf("failed to cache probe results: %w", err)
+				}
+			}
+			sort.SliceStable(probes, func(i, j int) bool { return probes[i].Installed && !probes[j].Installed })
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(probes, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			printFeatureMatrix(os.Stdout, probes)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	cmd.Flags().BoolVar(&cached, "cached", false, "show the last probe results without probing")
+
+	return cmd
+}
+
+// printFeatureMatrix prints probe results as a table of providers and features
+func printFeatureMatrix(out io.Writer, probes []providers.Features) {
+	if len(probes) == 0 {
+		fmt.Fprintln(out, "No probe results. Run 'opun providers' to probe the installed providers.")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tVERSION\tPRINT\tJSON\tRESUME\tMCP\tMODELS")
+	fmt.Fprintln(w, "--------\t-------\t-----\t----\t------\t---\t------")
+	var problems, commands []string
+	for _, f := range probes {
+		configured := !providers.BinaryFor(f.Provider).IsZero()
+		if !f.Installed {
+			fmt.Fprintf(w, "%s\tnot installed\t-\t-\t-\t-\t-\n", f.Provider)
+			// A configured binary that can't be found is a mistake worth showing
+			if configured && f.Error != "" {
+				problems = append(problems, fmt.Sprintf("%s: %s", f.Provider, f.Error))
+			}
+			continue
+		}
+		if configured {
+			commands = append(commands, fmt.Sprintf("%s runs as: %s", f.Provider, f.Command))
+		}
+		if f.Error != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", f.Provider, f.Error))
+		}
+		version := f.Version
+		if version == "" {
+			version = "unknown"
+		}
+		models := strings.Join(f.Models, ", ")
+		if models == "" {
+			models = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.Provider, version, featureMark(f.Print), featureMark(f.JSON), featureMark(f.Resume), featureMark(f.MCP), models)
+	}
+	_ = w.Flush()
+
+	for _, command := range commands {
+		fmt.Fprintf(out, "🔧 %s\n", command)
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(out, "⚠️  Warning: %s\n", problem)
+	}
+}
+
+// featureMark renders whether a provider has a feature
+func featureMark(supported bool) string {
+	if supported {
+		return "✓"
+	}
+	return "✗"
+}
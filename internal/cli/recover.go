@@ -0,0 +1,155 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// RecoverCmd creates the recover command
+func RecoverCmd() *cobra.Command {
+	var (
+		force  bool
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recover",
+		Short: "Clean up after a crashed run",
+		Long: `Find workflow runs whose opun process died without cleaning up, stop the
+provider processes they left running, mark their outputs aborted so the
+output directories can be reused, remove state files and attach sockets of
+runs that are gone, and restore the terminal if a run left it in raw mode.
+
+Every command checks for crashed runs when it starts: leftovers without
+running processes are cleaned up then, and orphaned providers are reported
+so they can be stopped with opun recover.`,
+		Example: `  opun recover
+  opun recover --dry-run
+  opun recover --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runsDir, err := workflow.RunsDir()
+			if err != nil {
+				return err
+			}
+			recovery, err := workflow.FindCrashedRuns(runsDir)
+			if err != nil {
+				return err
+			}
+			if recovery.Empty() {
+				fmt.Println("✅ Nothing to recover")
+				return nil
+			}
+
+			printRecovery(recovery)
+			if dryRun {
+				return nil
+			}
+
+			kill := false
+			if orphans := recovery.Orphans(); len(orphans) > 0 {
+				kill = force
+				if !force {
+					if kill, err = Confirm(fmt.Sprintf("Stop %d orphaned provider process(es)?", len(orphans))); err != nil {
+						return err
+					}
+				}
+			}
+
+			restoreTerminal(recovery)
+			errs := recovery.Clean(kill)
+			for _, err := range errs {
+				fmt.Printf("❌ %v\n", err)
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("recovery incomplete")
+			}
+
+			if len(recovery.Orphans()) > 0 && !kill {
+				fmt.Println("🧹 Cleaned up; orphaned providers were left running")
+			} else {
+				fmt.Println("🧹 Recovered")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "stop orphaned providers without confirmation")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only show what would be cleaned up")
+
+	return cmd
+}
+
+// printRecovery lists what crashed runs left behind
+func printRecovery(recovery *workflow.Recovery) {
+	for _, run := range recovery.Runs {
+		r := run.Registry
+		fmt.Printf("💥 Run %s (%s) crashed; started %s\n", r.RunID, r.Workflow, r.StartTime.Format("2006-01-02 15:04:05"))
+		for _, p := range run.Orphans {
+			fmt.Printf("  • %s is still running %s (PID %d)\n", p.AgentID, p.Provider, p.PID)
+		}
+		if r.OutputDir != "" {
+			fmt.Printf("  • Output: %s\n", r.OutputDir)
+		}
+	}
+	if n := len(recovery.StaleFiles); n > 0 {
+		fmt.Printf("🗑️  %d stale state file(s) and socket(s)\n", n)
+	}
+}
+
+// restoreTerminal undoes raw mode left behind by a crashed run
+func restoreTerminal(recovery *workflow.Recovery) {
+	if recovery.RawTerminal() && term.IsTerminal(int(os.Stdin.Fd())) {
+		_ = exec.Command("stty", "sane").Run()
+	}
+}
+
+// checkCrashedRuns runs at startup: it cleans up after crashed runs that left
+// no processes behind and points at opun recover for those that did
+func checkCrashedRuns(cmd *cobra.Command) {
+	// Commands whose output is a protocol must stay quiet, and recover does
+	// this itself
+	switch cmd.Name() {
+	case "recover", "stdio", "lsp", "__complete", "__completeNoDesc":
+		return
+	}
+	if os.Getenv("OPUN_MCP_STDIO") != "" {
+		return
+	}
+
+	runsDir, err := workflow.RunsDir()
+	if err != nil {
+		return
+	}
+	recovery, err := workflow.FindCrashedRuns(runsDir)
+	if err != nil || recovery.Empty() {
+		return
+	}
+
+	restoreTerminal(recovery)
+	recovery.Clean(false)
+	if orphans := recovery.Orphans(); len(orphans) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d provider process(es) from a crashed run are still running; stop them with 'opun recover'\n", len(orphans))
+	}
+}
@@ -0,0 +1,327 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/teamsync"
+	"github.com/spf13/cobra"
+)
+
+// RemoteCmd creates the remote command
+func RemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Manage shared git repositories for team sync",
+		Long: `Manage the git repositories a team shares prompts, workflows and actions
+through. A repository holds one directory per namespace, each with prompts/,
+workflows/ and actions/ directories; 'opun pull' and 'opun push' sync the
+namespaces a remote selects with the items installed locally.`,
+	}
+
+	cmd.AddCommand(
+		remoteListCmd(),
+		remoteAddCmd(),
+		remoteRemoveCmd(),
+	)
+
+	return cmd
+}
+
+// remoteListCmd lists configured remotes
+func remoteListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List configured remotes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := teamsync.RemotesPath()
+			if err != nil {
+				return err
+			}
+			remotes, err := teamsync.LoadRemotes(path)
+			if err != nil {
+				return err
+			}
+
+			if len(remotes) == 0 {
+				fmt.Println("No remotes configured. Add one with 'opun remote add <git-url>'.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tURL\tBRANCH\tNAMESPACES\tROLE")
+			fmt.Fprintln(w, "----\t---\t------\t----------\t----")
+			for _, remote := range remotes {
+				branch := remote.Branch
+				if branch == "" {
+					branch = teamsync.DefaultBranch
+				}
+				namespaces := strings.Join(remote.Namespaces, ", ")
+				if namespaces == "" {
+					namespaces = "(all)"
+				}
+				role := remote.Role
+				if role == "" {
+					role = teamsync.RoleMember
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", remote.Name, remote.URL, branch, namespaces, role)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// remoteAddCmd configures a remote
+func remoteAddCmd() *cobra.Command {
+	var (
+		name       string
+		branch     string
+		namespaces []string
+		role       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <git-url>",
+		Short: "Add a shared repository",
+		Long: `Add a shared git repository. Without --namespace every namespace in the
+repository is synced. Adding an existing name replaces it.
+
+Items pulled as a member are read-only locally: 'opun update' warns and
+offers to fork them into your own namespace. Maintainers change them in
+place and push the result.`,
+		Example: `  opun remote add git@github.com:org/opun-config.git
+  opun remote add git@github.com:org/opun-config.git --namespace backend --namespace shared
+  opun remote add git@github.com:org/opun-config.git --role maintainer`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := teamsync.RemotesPath()
+			if err != nil {
+				return err
+			}
+
+			remote := teamsync.Remote{Name: name, URL: args[0], Branch: branch, Namespaces: namespaces, Role: role}
+			if err := teamsync.SaveRemote(path, remote); err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Remote '%s' added, sync it with 'opun pull' and 'opun push'\n", remote.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", teamsync.DefaultRemote, "name of the remote")
+	cmd.Flags().StringVar(&branch, "branch", "", "branch to sync (default: "+teamsync.DefaultBranch+")")
+	cmd.Flags().StringSliceVar(&namespaces, "namespace", nil, "namespace to sync (repeatable, default: all)")
+	cmd.Flags().StringVar(&role, "role", "", "your role on the remote: member (read-only items) or maintainer (default: member)")
+
+	return cmd
+}
+
+// remoteRemoveCmd removes a remote and its checkout
+func remoteRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a shared repository",
+		Long:  `Remove a remote. Items installed from it are kept.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := teamsync.RemotesPath()
+			if err != nil {
+				return err
+			}
+			if err := teamsync.RemoveRemote(path, args[0]); err != nil {
+				return err
+			}
+			if syncer, err := teamsync.NewSyncer(teamsync.Remote{Name: args[0]}, nil); err == nil {
+				_ = os.RemoveAll(syncer.Dir)
+			}
+
+			fmt.Printf("✅ Remote '%s' removed\n", args[0])
+			return nil
+		},
+	}
+}
+
+// PullCmd creates the pull command
+func PullCmd() *cobra.Command {
+	var (
+		remoteName string
+		onConflict string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Install shared prompts, workflows and actions",
+		Long: `Fetch a remote and install the items of its namespaces. Items only changed
+in the repository are updated, items only changed locally are kept for the
+next push, and items deleted from the repository are removed unless they
+were changed locally.
+
+Items changed on both sides are conflicts, resolved one by one with
+--on-conflict: overwrite takes the repository's version, skip keeps the
+local one (published by the next push), merge merges workflows and actions
+key by key (also published by the next push) and fail stops. In a terminal
+the default is to ask.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := conflictPolicy(onConflict)
+			if err != nil {
+				return err
+			}
+			syncer, err := newSyncer(remoteName)
+			if err != nil {
+				return err
+			}
+			in := bufio.NewReader(os.Stdin)
+			syncer.Resolve = func(c teamsync.Conflict) ([]byte, error) {
+				kind := strings.TrimSuffix(c.Kind, "s")
+				resolution, err := resolveAddConflict(addConflict{
+					kind:      kind,
+					name:      c.Name,
+					existing:  c.Local,
+					incoming:  c.Remote,
+					mergeable: c.Kind != teamsync.KindPrompts,
+				}, policy, in, os.Stdout)
+				if err != nil {
+					return nil, err
+				}
+				if resolution.skip {
+					return c.Local, nil
+				}
+				return resolution.content, nil
+			}
+
+			fmt.Printf("⬇️  Pulling from %s (%s)\n", syncer.Remote.Name, syncer.Remote.URL)
+			report, err := syncer.Pull(cmd.Context())
+			if err != nil {
+				return err
+			}
+			printSyncReport(report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&remoteName, "remote", "", "remote to pull from (default: the only one, or origin)")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "how to resolve items changed on both sides: ask, overwrite, skip, merge or fail")
+
+	return cmd
+}
+
+// PushCmd creates the push command
+func PushCmd() *cobra.Command {
+	var (
+		remoteName string
+		namespace  string
+		message    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "push [kind/name...]",
+		Short: "Publish local changes to shared items",
+		Long: `Commit the synced items changed locally to a remote and push them. Items
+not in the repository yet are published by naming them, e.g. workflow/review,
+into --namespace.
+
+Pushing fails when the repository changed an item since the last pull; pull
+first to resolve it. Deleting an item locally doesn't delete it from the
+repository, delete it there instead.`,
+		Example: `  opun push
+  opun push workflow/review prompt/explain --namespace backend -m "Share review flow"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var add []teamsync.ItemRef
+			for _, arg := range args {
+				ref, err := teamsync.ParseItemRef(arg)
+				if err != nil {
+					return err
+				}
+				add = append(add, ref)
+			}
+
+			syncer, err := newSyncer(remoteName)
+			if err != nil {
+				return err
+			}
+			if namespace == "" && len(syncer.Remote.Namespaces) == 1 {
+				namespace = syncer.Remote.Namespaces[0]
+			}
+
+			fmt.Printf("⬆️  Pushing to %s (%s)\n", syncer.Remote.Name, syncer.Remote.URL)
+			report, err := syncer.Push(cmd.Context(), add, namespace, message)
+			if err != nil {
+				return err
+			}
+			printSyncReport(report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&remoteName, "remote", "", "remote to push to (default: the only one, or origin)")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "namespace to add new items to (default: the remote's only namespace)")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "commit message")
+
+	return cmd
+}
+
+// newSyncer creates a syncer for a configured remote and the local items
+func newSyncer(remoteName string) (*teamsync.Syncer, error) {
+	path, err := teamsync.RemotesPath()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := teamsync.FindRemote(path, remoteName)
+	if err != nil {
+		return nil, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	local, err := teamsync.NewLocalStore(filepath.Join(home, ".opun"))
+	if err != nil {
+		return nil, err
+	}
+	return teamsync.NewSyncer(*remote, local)
+}
+
+// printSyncReport lists what a pull or push changed
+func printSyncReport(report *teamsync.Report) {
+	for _, warning := range report.Warnings {
+		fmt.Printf("⚠️  Warning: %s\n", warning)
+	}
+	if len(report.Changes) == 0 {
+		fmt.Println("✓ Everything up to date")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEM\tNAMESPACE\tCHANGE")
+	for _, change := range report.Changes {
+		action := change.Action
+		switch action {
+		case teamsync.ChangeLocal, teamsync.ChangeKept, teamsync.ChangeMerged:
+			action += " (push to publish)"
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\n", change.Kind, change.Name, change.Namespace, action)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,236 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/mcp"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// loadRequirementsEnvironment collects the installed and enabled MCP servers
+// and the available actions, for checking workflow requirements
+func loadRequirementsEnvironment() (workflow.RequirementsEnvironment, error) {
+	var env workflow.RequirementsEnvironment
+
+	configManager, err := config.NewSharedConfigManager()
+	if err != nil {
+		return env, err
+	}
+	for _, server := range configManager.GetMCPServers() {
+		// Command-based servers (like opun itself) need no installation
+		if server.Installed || (server.Package == "" && server.Command != "") {
+			env.InstalledServers = append(env.InstalledServers, server.Name)
+		}
+	}
+
+	if viper.IsSet("mcp_servers") {
+		env.EnabledServers = viper.GetStringSlice("mcp_servers")
+		if env.EnabledServers == nil {
+			env.EnabledServers = []string{}
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return env, err
+	}
+	actionLoader := tools.NewLoader(filepath.Join(home, ".opun", "actions"))
+	if err := actionLoader.LoadAll(); err == nil {
+		for _, action := range actionLoader.GetRegistry().List("") {
+			env.Actions = append(env.Actions, action.ID)
+		}
+	}
+
+	return env, nil
+}
+
+// promptReferencePattern matches prompt garden references in agent prompts
+var promptReferencePattern = regexp.MustCompile(`promptgarden://([\w.-]+)`)
+
+// loadRunInventory collects the versions of the installed prompts and actions a
+// workflow uses, for its run manifest
+func loadRunInventory(w *wf.Workflow) workflow.RunInventory {
+	var inventory workflow.RunInventory
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return inventory
+	}
+
+	if len(w.Requires.Actions) > 0 {
+		actionLoader := tools.NewLoader(filepath.Join(home, ".opun", "actions"))
+		if err := actionLoader.LoadAll(); err == nil {
+			for _, id := range w.Requires.Actions {
+				if action, err := actionLoader.GetRegistry().Get(id); err == nil {
+					inventory.Actions = append(inventory.Actions, workflow.ManifestItem{Name: id, Version: action.Version})
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var garden *promptgarden.Garden
+	for _, agent := range w.Agents {
+		for _, match := range promptReferencePattern.FindAllStringSubmatch(agent.Prompt+"\n"+agent.SystemPrompt, -1) {
+			name := match[1]
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			if garden == nil {
+				if garden, err = promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden")); err != nil {
+					return inventory
+				}
+			}
+			if prompt, err := garden.GetByName(name); err == nil {
+				inventory.Prompts = append(inventory.Prompts, workflow.ManifestItem{Name: name, Version: prompt.Metadata().Version})
+			}
+		}
+	}
+
+	return inventory
+}
+
+// recordPromptUsage counts one use of each garden prompt a workflow's agents
+// reference, once per run
+func recordPromptUsage(w *wf.Workflow) {
+	seen := make(map[string]bool)
+	for _, agent := range w.Agents {
+		for _, match := range promptReferencePattern.FindAllStringSubmatch(agent.Prompt+"\n"+agent.SystemPrompt, -1) {
+			seen[match[1]] = true
+		}
+	}
+	if len(seen) == 0 {
+		return
+	}
+	garden, err := openPromptGarden()
+	if err != nil {
+		return
+	}
+	for name := range seen {
+		if prompt, err := garden.GetByName(name); err == nil {
+			_ = garden.RecordUsage(prompt.Name(), promptgarden.UsageWorkflow)
+		}
+	}
+}
+
+// ensureWorkflowRequirements verifies the MCP servers and actions a workflow
+// declares under requires. In a terminal it offers to install or enable missing
+// servers; otherwise it fails fast.
+func ensureWorkflowRequirements(w *wf.Workflow) error {
+	if !workflow.HasRequirements(w) {
+		return nil
+	}
+
+	env, err := loadRequirementsEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to check workflow requirements: %w", err)
+	}
+
+	missing := workflow.CheckRequirements(w.Requires, env)
+	if missing.Empty() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		if missing.Empty() {
+			return nil
+		}
+		return missing
+	}
+
+	if len(missing.NotInstalled) > 0 {
+		install, err := Confirm(fmt.Sprintf("Workflow '%s' needs MCP servers that aren't installed: %v. Install them now?", w.Name, missing.NotInstalled))
+		if err != nil {
+			return err
+		}
+		if install {
+			installer, err := mcp.NewSharedMCPInstaller()
+			if err != nil {
+				return err
+			}
+			if err := installer.InstallServers(context.Background(), missing.NotInstalled); err != nil {
+				return fmt.Errorf("failed to install MCP servers: %w", err)
+			}
+		}
+	}
+
+	if len(missing.NotEnabled) > 0 {
+		enable, err := Confirm(fmt.Sprintf("Workflow '%s' needs MCP servers that aren't enabled: %v. Enable them now?", w.Name, missing.NotEnabled))
+		if err != nil {
+			return err
+		}
+		if enable {
+			if err := enableMCPServers(missing.NotEnabled); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Re-check so anything still missing fails with a clear message
+	env, err = loadRequirementsEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to check workflow requirements: %w", err)
+	}
+	if missing := workflow.CheckRequirements(w.Requires, env); !missing.Empty() {
+		return missing
+	}
+
+	fmt.Println("✅ Workflow requirements satisfied")
+	return nil
+}
+
+// enableMCPServers adds servers to the enabled mcp_servers list in the config
+func enableMCPServers(names []string) error {
+	enabled := viper.GetStringSlice("mcp_servers")
+	for _, name := range names {
+		found := false
+		for _, existing := range enabled {
+			if existing == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			enabled = append(enabled, name)
+		}
+	}
+	viper.Set("mcp_servers", enabled)
+
+	configPath := viper.ConfigFileUsed()
+	if configPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		configPath = filepath.Join(home, ".opun", "config.yaml")
+	}
+
+	if err := viper.WriteConfigAs(configPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
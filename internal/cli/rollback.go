@@ -0,0 +1,123 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// RollbackCmd creates the rollback command
+func RollbackCmd() *cobra.Command {
+	var (
+		clean bool
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback <run-id> [agent]",
+		Short: "Restore the workspace from before an agent ran",
+		Long: `Restore the workspace snapshot taken before an agent with snapshot: true ran.
+In a git repository the tracked files are restored from a commit saved under
+refs/opun/snapshots without moving HEAD or touching the index; elsewhere the
+files are restored from an archive. Files the agent created are listed and
+left in place unless --clean is set.
+
+Without an agent, the run's snapshots are listed.
+
+Examples:
+  # List the snapshots of a run
+  opun rollback 4242-1730000000000000000
+
+  # Undo what the refactor agent changed
+  opun rollback 4242-1730000000000000000 refactor`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := workflow.SnapshotsDir()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				snapshots, err := workflow.ListSnapshots(dir, args[0])
+				if err != nil {
+					return err
+				}
+				printSnapshots(snapshots)
+				return nil
+			}
+
+			snapshot, err := workflow.LoadSnapshot(dir, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				confirm, err := Confirm(fmt.Sprintf("Restore %s to its state before '%s' ran? Changes made since will be lost", snapshot.WorkDir, snapshot.Agent))
+				if err != nil {
+					return err
+				}
+				if !confirm {
+					fmt.Println("Rollback cancelled")
+					return nil
+				}
+			}
+
+			created, err := snapshot.Restore(clean)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("⏪ Restored %s to its state before '%s' ran\n", snapshot.WorkDir, snapshot.Agent)
+			if len(created) > 0 {
+				if clean {
+					fmt.Printf("🧹 Removed %d file(s) created since the snapshot:\n", len(created))
+				} else {
+					fmt.Printf("⚠️  %d file(s) created since the snapshot were left in place (use --clean to remove them):\n", len(created))
+				}
+				for _, rel := range created {
+					fmt.Printf("  • %s\n", rel)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clean, "clean", false, "also remove files created since the snapshot")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "restore without confirmation")
+
+	return cmd
+}
+
+// printSnapshots lists a run's snapshots
+func printSnapshots(snapshots []*workflow.Snapshot) {
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tWORKFLOW\tMETHOD\tTAKEN\tWORKSPACE")
+	for _, s := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Agent, s.Workflow, s.Method, s.CreatedAt.Format("2006-01-02 15:04:05"), s.WorkDir)
+	}
+	_ = w.Flush()
+}
@@ -24,6 +24,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/i18n"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/providers"
 	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,11 +39,19 @@ func RootCmd() *cobra.Command {
 
 	rootCmd := &cobra.Command{
 		Use:   "opun",
-		Short: "AI code agent automation framework",
-		Long: `Opun automates interaction with AI code agents (Claude Code, Gemini CLI, and Qwen Code)
-by managing their interactive sessions and providing workflow orchestration.`,
+		Short: i18n.T("root.short"),
+		Long:  i18n.T("root.long"),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return initConfig(configFile)
+			if err := initConfig(configFile); err != nil {
+				return err
+			}
+			// Through the environment so the workflow package and the
+			// commands opun starts itself see it too
+			if noTUI {
+				_ = os.Setenv(utils.NoTUIEnv, "1")
+			}
+			checkCrashedRuns(cmd)
+			return nil
 		},
 		// Override default help behavior to show our custom grouped commands
 		Run: func(cmd *cobra.Command, args []string) {
@@ -48,11 +60,23 @@ by managing their interactive sessions and providing workflow orchestration.`,
 	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is $HOME/.opun/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", i18n.T("flag.config"))
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, i18n.T("flag.no_pager"))
+	rootCmd.PersistentFlags().BoolVar(&noTUI, "no-tui", false, i18n.T("flag.no_tui"))
+	rootCmd.Flags().BoolP("help", "h", false, i18n.T("flag.help"))
 
 	// Set custom help template
 	rootCmd.SetHelpTemplate(customHelpTemplate())
 
+	// Help is shown without running PersistentPreRunE, so load the config
+	// here for a configured locale before rendering it
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		_ = initConfig(configFile)
+		localizeRoot(cmd.Root())
+		defaultHelp(cmd, args)
+	})
+
 	// Disable the default help command and add our own
 	rootCmd.SetHelpCommand(&cobra.Command{
 		Use:    "no-help",
@@ -62,7 +86,7 @@ by managing their interactive sessions and providing workflow orchestration.`,
 	// Add custom help command
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "help [command]",
-		Short: "Help about any command",
+		Short: i18n.T("help.command.help"),
 		Long: `Help provides help for any command in the application.
 Simply type opun help [path to command] for full details.`,
 		DisableFlagsInUseLine: true,
@@ -99,6 +123,9 @@ Simply type opun help [path to command] for full details.`,
 		UpdateCmd(),
 		DeleteCmd(),
 		ListCmd(),
+		RemoteCmd(),
+		PullCmd(),
+		PushCmd(),
 	)
 
 	// Add Main commands (user-facing operations)
@@ -114,86 +141,115 @@ Simply type opun help [path to command] for full details.`,
 		SubAgentCmd(),
 	)
 
+	// Add Node command
+	rootCmd.AddCommand(
+		NodeCmd(),
+	)
+
 	// Add System commands (internal operations)
 	rootCmd.AddCommand(
 		SetupCmd(),
+		ProvidersCmd(),
+		RecoverCmd(),
 		MCPCmd(),
 		CompletionCmd(),
+		FeaturesCmd(),
+		HookCmd(),
 	)
 
 	return rootCmd
 }
 
-// customHelpTemplate returns a custom help template with grouped commands
-func customHelpTemplate() string {
-	return `{{.Long}}
+// helpSection is a group of commands in the grouped help
+type helpSection struct {
+	title    string
+	commands []string
+}
 
-Usage:
-  {{.UseLine}}
+// helpSections lists the commands shown in the grouped help; each one's
+// description is the help.command.<name> message
+var helpSections = []helpSection{
+	{"help.section.registry", []string{"add", "update", "delete", "list", "remote", "pull", "push"}},
+	{"help.section.main", []string{"go", "chat", "run", "watch", "panel", "map", "prompt", "status", "attach", "compare", "inspect", "bundle-run", "replay-bundle", "rollback", "feedback", "export", "daemon", "lsp", "node", "refactor", "subagent"}},
+	{"help.section.capability", []string{"capability"}},
+	{"help.section.system", []string{"setup", "providers", "recover", "mcp", "completion", "features"}},
+}
 
-Registry Commands (manage configuration):
-  add         Add workflows, prompts, actions, or tools
-  update      Update existing configuration
-  delete      Delete from configuration
-  list        List all configured items
+// helpCommandList renders the grouped command list in the selected locale
+func helpCommandList(withHelp bool) string {
+	// Line descriptions up after the longest command name
+	width := len("help")
+	for _, section := range helpSections {
+		for _, name := range section.commands {
+			width = max(width, len(name))
+		}
+	}
 
-Main Commands:
-  chat        Start an interactive chat session
-  run         Run a workflow
-  refactor    Refactor code files
-  subagent    Manage cross-provider subagents
+	var b strings.Builder
+	for _, section := range helpSections {
+		fmt.Fprintf(&b, "%s:\n", i18n.T(section.title))
+		commands := section.commands
+		if withHelp && section.title == "help.section.system" {
+			commands = append(append([]string{}, commands...), "help")
+		}
+		for _, name := range commands {
+			fmt.Fprintf(&b, "  %-*s %s\n", width, name, i18n.T("help.command."+name))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// localizeRoot translates the root command's descriptions and flag usages
+// into the selected locale
+func localizeRoot(rootCmd *cobra.Command) {
+	rootCmd.Short = i18n.T("root.short")
+	rootCmd.Long = i18n.T("root.long")
+	for name, id := range map[string]string{"config": "flag.config", "no-pager": "flag.no_pager", "no-tui": "flag.no_tui"} {
+		if flag := rootCmd.PersistentFlags().Lookup(name); flag != nil {
+			flag.Usage = i18n.T(id)
+		}
+	}
+	if flag := rootCmd.Flags().Lookup("help"); flag != nil {
+		flag.Usage = i18n.T("flag.help")
+	}
+	rootCmd.SetHelpTemplate(customHelpTemplate())
+}
 
-Capability Commands:
-  capability  List and search all Opun capabilities
+// customHelpTemplate returns a custom help template with grouped commands
+func customHelpTemplate() string {
+	return `{{.Long}}
 
-System Commands:
-  setup       Configure Opun for first use
-  mcp         Manage MCP server
-  completion  Generate shell completions
+` + i18n.T("help.usage") + `:
+  {{.UseLine}}
 
-Flags:
+` + helpCommandList(false) + i18n.T("help.flags") + `:
 {{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
 
-Global Flags:
+` + i18n.T("help.global_flags") + `:
 {{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}
 
-Use "{{.CommandPath}} [command] --help" for more information about a command.
+` + i18n.T("help.more", "{{.CommandPath}}") + `
 `
 }
 
 // GetCustomHelp returns the formatted help text for display
 func GetCustomHelp() string {
-	return `Opun automates interaction with AI code agents (Claude Code, Gemini CLI, and Qwen Code)
-by managing their interactive sessions and providing workflow orchestration.
-
-Usage:
-  opun [command]
-
-Registry Commands (manage configuration):
-  add         Add workflows, prompts, actions, or tools
-  update      Update existing configuration  
-  delete      Delete from configuration
-  list        List all configured items
+	// Shown before any command runs, so the locale has to come from the config here
+	_ = initConfig("")
 
-Main Commands:
-  chat        Start an interactive chat session
-  run         Run a workflow
-  refactor    Refactor code files
+	return i18n.T("root.long") + `
 
-Capability Commands:
-  capability  List and search all Opun capabilities
-
-System Commands:
-  setup       Configure Opun for first use
-  mcp         Manage MCP server
-  completion  Generate shell completions
-  help        Help about any command
+` + i18n.T("help.usage") + `:
+  opun [command]
 
-Flags:
-  --config string   config file (default is $HOME/.opun/config.yaml)
-  -h, --help       help for opun
+` + helpCommandList(true) + i18n.T("help.flags") + `:
+  --config string   ` + i18n.T("flag.config") + `
+  --no-pager        ` + i18n.T("flag.no_pager") + `
+  --no-tui          ` + i18n.T("flag.no_tui") + `
+  -h, --help        ` + i18n.T("flag.help") + `
 
-Use "opun [command] --help" for more information about a command.
+` + i18n.T("help.more", "opun") + `
 `
 }
 
@@ -280,9 +336,108 @@ func initConfig(configFile string) error {
 	// Read config if it exists
 	_ = viper.ReadInConfig()
 
+	promptgarden.SetShellPolicy(shellPolicyFromConfig())
+	config.SetClaudeHooks(claudeHooksEnabled())
+	setFormatProfilesFromConfig()
+	setStartupScriptsFromConfig()
+	setBinariesFromConfig()
+	setFeaturesFromConfig()
+	initLocale()
+
 	return nil
 }
 
+// initLocale selects the message locale from OPUN_LOCALE, the locale config
+// key or the system, with translations also read from ~/.opun/locales
+func initLocale() {
+	if home, err := os.UserHomeDir(); err == nil {
+		i18n.SetCatalogDir(filepath.Join(home, ".opun", "locales"))
+	}
+	i18n.SetLocale(i18n.Detect(viper.GetString("locale")))
+}
+
+// shellPolicyFromConfig builds the prompt template shell policy from the prompt_shell config section
+func shellPolicyFromConfig() promptgarden.ShellPolicy {
+	policy := promptgarden.DefaultShellPolicy()
+
+	policy.Enabled = viper.GetBool("prompt_shell.enabled")
+	policy.Allow = viper.GetStringSlice("prompt_shell.allow")
+	if timeout := viper.GetDuration("prompt_shell.timeout"); timeout > 0 {
+		policy.Timeout = timeout
+	}
+
+	return policy
+}
+
+// setFormatProfilesFromConfig applies providers.<name>.format from the
+// config over each provider's built-in format profile
+func setFormatProfilesFromConfig() {
+	for _, name := range providers.KnownProviders {
+		key := "providers." + name + ".format"
+		if !viper.IsSet(key) {
+			continue
+		}
+		profile := providers.FormatProfileFor(name)
+		err := viper.UnmarshalKey(key, &profile)
+		if err == nil {
+			err = profile.Validate()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid %s: %v\n", key, err)
+			continue
+		}
+		providers.SetFormatProfile(name, profile)
+	}
+}
+
+// setStartupScriptsFromConfig applies providers.<name>.startup from the
+// config, the dialogs to answer before a provider's prompt is typed
+func setStartupScriptsFromConfig() {
+	for _, name := range providers.KnownProviders {
+		key := "providers." + name + ".startup"
+		if !viper.IsSet(key) {
+			continue
+		}
+		var script providers.StartupScript
+		err := viper.UnmarshalKey(key, &script)
+		if err == nil {
+			err = script.Validate()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid %s: %v\n", key, err)
+			continue
+		}
+		providers.SetStartupScript(name, script)
+	}
+}
+
+// setBinariesFromConfig applies providers.<name>.binary from the config, the
+// path and wrapper command a provider CLI is started with. They are resolved
+// when the provider is first used, so a missing wrapper only fails runs that
+// need it.
+func setBinariesFromConfig() {
+	for _, name := range providers.KnownProviders {
+		key := "providers." + name + ".binary"
+		if !viper.IsSet(key) {
+			continue
+		}
+		// A plain string is shorthand for the path
+		binary := providers.Binary{Path: viper.GetString(key)}
+		var err error
+		if _, ok := viper.Get(key).(string); !ok {
+			err = viper.UnmarshalKey(key, &binary)
+		}
+		if err == nil {
+			err = binary.Validate()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid %s: %v\n", key, err)
+			continue
+		}
+		providers.SetBinary(name, binary)
+	}
+}
+
 // checkAndWarnPermissions checks if the .opun directory has correct ownership
 func checkAndWarnPermissions(opunDir string) error {
 	// Get actual user info
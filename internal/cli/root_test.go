@@ -20,8 +20,12 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -168,3 +172,58 @@ func setEnv(key, value string) {
 func unsetEnv(key string) {
 	os.Unsetenv(key)
 }
+
+func TestSetBinariesFromConfig(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(func() {
+		viper.Reset()
+		providers.SetBinary("claude", providers.Binary{})
+		providers.SetBinary("gemini", providers.Binary{})
+	})
+	viper.Set("providers.claude.binary", "/opt/tools/claude")
+	viper.Set("providers.gemini.binary", map[string]interface{}{
+		"wrapper": []string{"mise", "exec", "--"},
+	})
+
+	setBinariesFromConfig()
+
+	assert.Equal(t, providers.Binary{Path: "/opt/tools/claude"}, providers.BinaryFor("claude"))
+	assert.Equal(t, providers.Binary{Wrapper: []string{"mise", "exec", "--"}}, providers.BinaryFor("gemini"))
+	assert.True(t, providers.BinaryFor("qwen").IsZero())
+}
+
+func TestSetFeaturesFromConfig(t *testing.T) {
+	t.Setenv(features.EnvVar, "")
+	viper.Reset()
+	t.Cleanup(func() {
+		viper.Reset()
+		features.Set(features.Daemon, false)
+	})
+	viper.Set("features.daemon", true)
+	viper.Set("features.parallel-workflows", false)
+
+	setFeaturesFromConfig()
+
+	assert.True(t, features.Enabled(features.Daemon))
+	assert.False(t, features.Enabled(features.ParallelWorkflows))
+	assert.False(t, features.Enabled(features.ContainerSandbox))
+}
+
+func TestHelpCommandList(t *testing.T) {
+	list := helpCommandList(true)
+
+	// Every description starts in the same column, past the longest name
+	column := -1
+	for _, line := range strings.Split(list, "\n") {
+		if !strings.HasPrefix(line, "  ") {
+			continue
+		}
+		name := strings.Fields(line)[0]
+		start := len(line) - len(strings.TrimLeft(line[2+len(name):], " "))
+		if column < 0 {
+			column = start
+		}
+		assert.Equal(t, column, start, line)
+	}
+	assert.Greater(t, column, 2+len("replay-bundle"))
+}
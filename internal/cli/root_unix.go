@@ -8,6 +8,24 @@ func addMainCommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(
 		ChatCmd(),
 		RunCmd(),
+		WatchCmd(),
 		RefactorCmd(),
+		GoCmd(),
+		PanelCmd(),
+		MapCmd(),
+		PromptCmd(),
+		StatusCmd(),
+		AttachCmd(),
+		CompareCmd(),
+		InspectCmd(),
+		BundleRunCmd(),
+		ReplayBundleCmd(),
+		RollbackCmd(),
+		FeedbackCmd(),
+		ExportCmd(),
+		FindingsCmd(),
+		DaemonCmd(),
+		ServeCmd(),
+		LSPCmd(),
 	)
 }
@@ -7,6 +7,24 @@ import "github.com/spf13/cobra"
 func addMainCommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(
 		RunCmd(),
+		WatchCmd(),
 		RefactorCmd(),
+		GoCmd(),
+		PanelCmd(),
+		MapCmd(),
+		PromptCmd(),
+		StatusCmd(),
+		AttachCmd(),
+		CompareCmd(),
+		InspectCmd(),
+		BundleRunCmd(),
+		ReplayBundleCmd(),
+		RollbackCmd(),
+		FeedbackCmd(),
+		ExportCmd(),
+		FindingsCmd(),
+		DaemonCmd(),
+		ServeCmd(),
+		LSPCmd(),
 	)
 }
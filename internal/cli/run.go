@@ -24,30 +24,88 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/export"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/telemetry"
+	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/run"
 	wf "github.com/rizome-dev/opun/pkg/workflow"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"golang.org/x/term"
 )
 
 // RunCmd creates the run command
 func RunCmd() *cobra.Command {
 	var (
-		workflowName string
-		variables    map[string]string
+		workflowName  string
+		variables     map[string]string
+		skipAuthCheck bool
+		detach        bool
+		runID         string
+		matrix        bool
+		parallel      int
+		eventStream   string
+		otel          string
+		chaos         string
+		headless      bool
+		copyFinal     bool
+		ci            string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "run [workflow]",
 		Short: "Run a workflow",
-		Long:  `Run a workflow by name or from a file path. If no workflow is specified, shows an interactive selection.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Run a workflow by name or from a file path. If no workflow is specified, shows an interactive selection.
+
+--event-stream writes newline-delimited JSON events (run_started,
+agent_started, output_chunk, variable_needed, agent_completed, agent_failed,
+run_completed, run_failed, ...) to an inherited file descriptor (fd:3) or a
+Unix socket the caller listens on (unix:/path), so editor plugins can follow
+the run without parsing terminal output.
+
+--otel exports an OpenTelemetry trace of the run: the run is the root span and
+every agent a child span carrying its provider, model, duration, estimated
+prompt tokens and resource usage, and MCP tool calls the agent makes through
+Opun join its span. The target is an OTLP/HTTP endpoint (http://host:4318) or
+file:/path for OTLP JSON lines. Without the flag, OTEL_EXPORTER_OTLP_ENDPOINT
+or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT turn tracing on.
+
+--headless runs every agent with its provider's non-interactive mode instead
+of a terminal session and writes the answers to the output directory, for
+scripts and 'opun watch'. Input steps need their variable passed with --var.
+
+--var NAME=- reads the variable from stdin, e.g. cat error.log | opun run
+triage --var log=-. Input over 8KB is saved to a temporary file and passed as
+an @path reference that providers read themselves.
+
+--chaos injects failures into provider calls so retry, gate and resume
+handling can be exercised, e.g. in CI with the mock provider: random delays,
+providers exiting partway through their answer and malformed answers. Give
+settings as --chaos=delay=2s,exit=0.2,malformed=0.1,seed=42 (defaults: up to
+1s delays, 10% exits and malformed answers); OPUN_CHAOS does the same
+without the flag. Headless and matrix runs inject into every provider,
+interactive runs into mock sessions, and the injected failures are recorded
+under chaos in the run manifest and matrix.json.
+
+--ci github reports the run to GitHub Actions: agent progress goes in a
+collapsible log group, failures become error annotations on the workflow
+file, the run report is added to the job summary and the run_id, status,
+artifact_path and final_output step outputs are set. It runs the workflow
+headlessly unless --matrix is given.
+
+--copy puts the final agent's output on the clipboard when the run succeeds
+(matrix runs copy the side-by-side matrix.md).`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// If no workflow specified, run interactive selection
 			if len(args) == 0 {
@@ -60,32 +118,222 @@ func RunCmd() *cobra.Command {
 				workflowName = args[0]
 			}
 
-			return runWorkflow(workflowName, variables)
+			if skipAuthCheck {
+				viper.Set("skip_auth_check", true)
+			}
+
+			if cmd.Flags().Changed("chaos") {
+				viper.Set("chaos", chaos)
+				// The background run picks chaos up from its environment
+				if detach {
+					_ = os.Setenv(workflow.ChaosEnv, chaos)
+				}
+			}
+
+			if ci != "" {
+				if detach || eventStream != "" || otel != "" {
+					return fmt.Errorf("--ci can't be combined with --detach, --event-stream or --otel")
+				}
+				// CI jobs have no terminal to run providers in
+				headless = !matrix
+			}
+			reporter, err := newCIReporter(ci, workflowName)
+			if err != nil {
+				return err
+			}
+
+			if eventStream != "" && (matrix || detach || headless) {
+				return fmt.Errorf("--event-stream can't be combined with --matrix, --detach or --headless")
+			}
+
+			if otel != "" && (matrix || headless) {
+				return fmt.Errorf("--otel can't be combined with --matrix or --headless")
+			}
+
+			if matrix && headless {
+				return fmt.Errorf("--matrix runs are always headless")
+			}
+
+			if copyFinal && detach {
+				return fmt.Errorf("--copy can't be combined with --detach")
+			}
+
+			if name, err := stdinVar(variables); err != nil {
+				return err
+			} else if name != "" && detach {
+				return fmt.Errorf("--var %s=- can't be used with --detach, the background run has no stdin", name)
+			}
+			cleanupStdin, err := resolveStdinVars(variables)
+			if err != nil {
+				return err
+			}
+			defer cleanupStdin()
+
+			if matrix {
+				return reporter.finish(runMatrix(workflowName, variables, parallel, copyFinal, reporter))
+			}
+
+			if headless {
+				return reporter.finish(runHeadlessWorkflow(workflowName, variables, copyFinal, reporter))
+			}
+
+			if detach {
+				// The background run picks the trace target up from its environment
+				if otel != "" {
+					_ = os.Setenv(telemetry.TargetEnv, otel)
+				}
+				return startDetachedRun(workflowName, variables)
+			}
+
+			// Background half of --detach: serve the terminal to `opun attach`
+			if runID != "" {
+				cleanup, err := serveDetachedRun(runID)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+			}
+
+			// Piped input is used up, providers need the keyboard
+			reattachTerminal()
+			return runWorkflow(workflowName, variables, eventStream, otel, copyFinal)
 		},
 	}
 
 	// Flags
 	cmd.Flags().StringToStringVarP(&variables, "var", "v", map[string]string{}, "variables to pass to the workflow (key=value)")
+	cmd.Flags().BoolVar(&skipAuthCheck, "skip-auth-check", false, "skip checking that providers are installed and logged in")
+	cmd.Flags().BoolVarP(&detach, "detach", "d", false, "run in the background; reattach with 'opun attach'")
+	cmd.Flags().BoolVar(&matrix, "matrix", false, "run every combination of the workflow's matrix headlessly")
+	cmd.Flags().IntVar(&parallel, "parallel", 1, "matrix combinations to run at once (needs the parallel-workflows feature)")
+	cmd.Flags().BoolVar(&headless, "headless", false, "run without terminal sessions using the providers' non-interactive mode")
+	cmd.Flags().BoolVar(&copyFinal, "copy", false, "copy the final output to the clipboard")
+	cmd.Flags().StringVar(&ci, "ci", "", "report the run to a CI system: github")
+	cmd.Flags().StringVar(&eventStream, "event-stream", "", "write JSON run events to fd:N or unix:/path")
+	cmd.Flags().StringVar(&otel, "otel", "", "export an OpenTelemetry trace to an OTLP endpoint (http://host:4318) or file:/path")
+	cmd.Flags().StringVar(&chaos, "chaos", "", "inject provider failures: delay=2s,exit=0.2,malformed=0.1,seed=42")
+	cmd.Flags().Lookup("chaos").NoOptDefVal = "default"
+	cmd.Flags().StringVar(&runID, "run-id", "", "run ID of a detached run (internal)")
+	_ = cmd.Flags().MarkHidden("run-id")
 
 	return cmd
 }
 
-// runWorkflow executes a workflow
-func runWorkflow(name string, vars map[string]string) error {
+// runWorkflow executes a workflow. A non-empty eventStream is an event
+// stream target (fd:N or unix:/path) that receives the run's events, and a
+// non-empty traceTarget an OpenTelemetry exporter target; without one the
+// OTLP environment variables decide whether the run is traced. copyFinal
+// copies the last agent's output to the clipboard once the run succeeds.
+func runWorkflow(name string, vars map[string]string, eventStream, traceTarget string, copyFinal bool) error {
 	ctx := context.Background()
 
+	var stream *workflow.EventStream
+	if eventStream != "" {
+		var err error
+		if stream, err = workflow.OpenEventStream(eventStream); err != nil {
+			return err
+		}
+		defer stream.Close()
+	}
+
 	// Load workflow
 	wf, err := loadWorkflow(name)
 	if err != nil {
 		return fmt.Errorf("failed to load workflow: %w", err)
 	}
 
+	applyStorageConfig(wf)
+	applyIssueConfig(wf)
+	applyEmailConfig(wf)
+
+	// Reject invalid --var values before checking providers or starting agents
+	if err := workflow.ValidateVariables(wf, run.Variables(wf, vars)); err != nil {
+		return err
+	}
+	if err := workflow.RequireFeatures(wf); err != nil {
+		return err
+	}
+
 	// Workflow header is printed by the executor
+	if wf.Matrix != nil {
+		fmt.Println("ℹ️  This workflow defines a matrix; run it with --matrix to sweep every combination")
+	}
+
+	// Verify required MCP servers and actions before starting any agent
+	if err := ensureWorkflowRequirements(wf); err != nil {
+		return err
+	}
+
+	// Make sure every provider is logged in so the run doesn't fail mid-way
+	if err := ensureProviderAuth(wf); err != nil {
+		return err
+	}
+
+	policy, err := loadPromptPolicy()
+	if err != nil {
+		return err
+	}
+
+	chaos, err := loadChaos()
+	if err != nil {
+		return err
+	}
 
 	// Initialize components
 
 	// Create workflow executor
 	executor := workflow.NewExecutor()
+	executor.SetRunInventory(loadRunInventory(wf))
+	recordPromptUsage(wf)
+	executor.SetPromptResolver(lazyGardenResolver())
+	executor.SetPromptPolicy(policy)
+	executor.SetChaos(chaos)
+
+	// Publish progress so `opun status` can show this run from other terminals
+	var handlers []workflow.EventHandler
+	var tracker *workflow.RunTracker
+	runID := ""
+	if runsDir, err := workflow.RunsDir(); err == nil {
+		if detachedRun != nil {
+			runID = detachedRun.id
+		}
+		tracker = workflow.NewRunTracker(runsDir, runID, wf.Name)
+		if detachedRun != nil {
+			tracker.SetAttachSocket(detachedRun.server.SocketPath())
+			executor.SetAttachServer(detachedRun.server)
+		}
+		runID = tracker.ID()
+		handlers = append(handlers, tracker.HandleEvent)
+		defer tracker.Close()
+	}
+	executor.SetRunID(runID)
+	if stream != nil {
+		stream.SetRun(runID, wf.Name)
+		executor.SetOutputEvents(true)
+		handlers = append(handlers, stream.HandleEvent)
+	}
+	if traceTarget == "" {
+		traceTarget = telemetry.TargetFromEnv()
+	}
+	if traceTarget != "" {
+		tracer, err := workflow.NewRunTracer(traceTarget)
+		if err != nil {
+			return err
+		}
+		tracer.SetRun(runID, wf.Name)
+		executor.SetTracer(tracer)
+		handlers = append(handlers, tracer.HandleEvent)
+		defer func() {
+			if err := tracer.Close(); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+		}()
+	}
+	finalOutput := ""
+	if copyFinal {
+		handlers = append(handlers, recordFinalOutput(&finalOutput))
+	}
+	executor.SetEventHandler(workflow.CombineEventHandlers(handlers...))
 
 	// Convert string vars to interface{}
 	variables := make(map[string]interface{})
@@ -120,8 +368,16 @@ func runWorkflow(name string, vars map[string]string) error {
 		// Don't exit immediately - let the workflow executor handle cleanup
 	}()
 
+	// Hold the workflow's lock for the whole run; ctrl+c stops waiting for it
+	lock, err := lockWorkflow(ctx, wf, runID, tracker)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Execute workflow
 	execErr := executor.Execute(ctx, wf, variables)
+	printChaosEvents(os.Stdout, chaos.Events())
 
 	// Always ensure terminal is restored, whether we succeeded or failed
 	if term.IsTerminal(int(os.Stdin.Fd())) {
@@ -133,7 +389,7 @@ func runWorkflow(name string, vars map[string]string) error {
 	case <-signalHandled:
 		// Give a moment for the executor to finish cleanup
 		time.Sleep(200 * time.Millisecond)
-		return fmt.Errorf("interrupted")
+		return workflow.Classify(workflow.ErrorUserAborted, fmt.Errorf("interrupted"))
 	default:
 		// Normal completion or error
 	}
@@ -142,6 +398,10 @@ func runWorkflow(name string, vars map[string]string) error {
 		return fmt.Errorf("workflow execution failed: %w", execErr)
 	}
 
+	if copyFinal {
+		copyOutputFile(finalOutput)
+	}
+
 	// Completion message is printed by the executor
 	return nil
 }
@@ -167,7 +427,11 @@ func loadWorkflow(name string) (*wf.Workflow, error) {
 		workflowPath := filepath.Join(home, ".opun", "workflows", name+".yaml")
 		data, err = os.ReadFile(workflowPath)
 		if err != nil {
-			return nil, fmt.Errorf("workflow '%s' not found", name)
+			builtin, ok := workflow.BuiltinWorkflow(name)
+			if !ok {
+				return nil, fmt.Errorf("workflow '%s' not found", name)
+			}
+			data = builtin
 		}
 	}
 
@@ -175,7 +439,53 @@ func loadWorkflow(name string) (*wf.Workflow, error) {
 	home, _ := os.UserHomeDir()
 	workflowDir := filepath.Join(home, ".opun", "workflows")
 	parser := workflow.NewParser(workflowDir)
-	return parser.Parse(data)
+	w, err := parser.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Choose providers for agents that list capabilities instead of one
+	if err := workflow.SelectProviders(w, loadProviderSelection()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// loadPromptPolicy reads the prompt_policy section of the config, returning
+// nil when no policy is configured
+func loadPromptPolicy() (*workflow.PromptPolicy, error) {
+	if !viper.IsSet("prompt_policy") {
+		return nil, nil
+	}
+	var policy workflow.PromptPolicy
+	if err := viper.UnmarshalKey("prompt_policy", &policy); err != nil {
+		return nil, fmt.Errorf("invalid prompt_policy config: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// loadProviderSelection reads the provider_selection section of the config,
+// adding what the last provider probe found
+func loadProviderSelection() workflow.ProviderSelection {
+	var selection workflow.ProviderSelection
+	if err := viper.UnmarshalKey("provider_selection", &selection); err != nil {
+		fmt.Printf("⚠️  Ignoring invalid provider_selection config: %v\n", err)
+	}
+	selection.Probed = providers.CachedCapabilities()
+	return selection
+}
+
+// recordFinalOutput returns an event handler that keeps the output file of
+// the last agent to complete in path
+func recordFinalOutput(path *string) workflow.EventHandler {
+	return func(event wf.WorkflowEvent) {
+		if output, ok := event.Data["output"].(string); ok && event.Type == wf.EventAgentComplete && output != "" {
+			*path = output
+		}
+	}
 }
 
 // handleWorkflowEvent handles workflow execution events
@@ -281,6 +591,14 @@ func selectWorkflowInteractively() (string, error) {
 		Foreground(lipgloss.Color("230")).
 		Padding(0, 1)
 
+	if utils.PlainUI() {
+		item, err := plainSelect(l.Title, items)
+		if err != nil {
+			return "", err
+		}
+		return item.(workflowItem).name, nil
+	}
+
 	model := workflowSelectionModel{list: l}
 
 	// Run the program
@@ -355,3 +673,41 @@ func getAvailableWorkflows() ([]workflowItem, error) {
 
 	return workflows, nil
 }
+
+// lazyGardenResolver resolves prompt references against the prompt garden,
+// opening it on first use
+func lazyGardenResolver() workflow.PromptResolver {
+	var (
+		once    sync.Once
+		resolve export.PromptResolver
+		openErr error
+	)
+	return func(name string) (string, error) {
+		once.Do(func() {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				openErr = err
+				return
+			}
+			garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+			if err != nil {
+				openErr = fmt.Errorf("failed to initialize prompt garden: %w", err)
+				return
+			}
+			resolve = gardenResolver(garden)
+		})
+		if openErr != nil {
+			return "", openErr
+		}
+		return resolve(name)
+	}
+}
+
+// lockWorkflow takes the workflow's settings.lock for a run, telling the
+// user when the run has to wait for another one
+func lockWorkflow(ctx context.Context, w *wf.Workflow, runID string, tracker *workflow.RunTracker) (*workflow.RunLock, error) {
+	return workflow.LockRun(ctx, w, runID, tracker, func(holder workflow.LockHolder) {
+		fmt.Printf("🔒 Waiting for lock %s, held by %s (pid %d) since %s\n",
+			holder.Name, holder.Workflow, holder.PID, holder.AcquiredAt.Format(time.Kitchen))
+	})
+}
@@ -0,0 +1,75 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"github.com/rizome-dev/opun/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+// ServeCmd creates the serve command
+func ServeCmd() *cobra.Command {
+	var (
+		addr        string
+		noToken     bool
+		metricsAddr string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve --api ADDR",
+		Short: "Serve Opun's REST API for dashboards and services",
+		Long: `Serve Opun over a plain REST API so web dashboards and other services can
+list items, start workflow runs, follow their events and fetch their
+artifacts without speaking JSON-RPC or MCP. Every request needs the bearer
+token written to ~/.opun/daemon.json, or the one in OPUN_API_TOKEN.
+
+  GET  /api/v1/items[/{kind}]               list items, optionally of one kind
+  GET  /api/v1/items/{kind}/{name}          get an item with its content
+  GET  /api/v1/runs                         list runs
+  POST /api/v1/runs                         start a run: {workflow, variables?}
+  GET  /api/v1/runs/{id}                    get a run with its events
+  POST /api/v1/runs/{id}/cancel             cancel a run
+  GET  /api/v1/runs/{id}/events             stream run events (server-sent events)
+  GET  /api/v1/runs/{id}/logs               get the end of each agent's output
+  GET  /api/v1/runs/{id}/artifacts          list the files a run wrote
+  GET  /api/v1/runs/{id}/artifacts/{path}   download one of them
+  GET  /api/v1/history[?limit=n]            list past runs with their costs
+  GET  /api/v1/history/{id}                 get the record of a past run
+
+Runs are looked up by daemon run ID or Opun run ID, so the artifacts of runs
+in the workflow history can be fetched too. The event stream sends the events
+so far, then new ones as they happen, and ends with a done event carrying the
+finished run; agent output arrives as output_chunk events. Costs are the
+usage providers report, which headless runs record. The JSON-RPC API of
+'opun daemon' is served alongside at /rpc.
+
+A web dashboard at / browses the library, follows runs with their agents'
+output, lists the run history with costs and downloads artifacts. Open the
+URL printed at startup, which carries the token.`,
+		Example: `  opun serve --api 127.0.0.1:7420
+  OPUN_API_TOKEN=secret opun serve --api :7420`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDaemonServer(addr, noToken, metricsAddr, daemon.RESTPrefix)
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "api", daemon.DefaultAddress, "address to serve the REST API on")
+	cmd.Flags().BoolVar(&noToken, "no-token", false, "accept requests without a bearer token")
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
+
+	return cmd
+}
@@ -257,6 +257,14 @@ func selectProvider() (string, error) {
 		Foreground(lipgloss.Color("230")).
 		Padding(0, 1)
 
+	if utils.PlainUI() {
+		choice, err := plainSelect(l.Title, items)
+		if err != nil {
+			return "", err
+		}
+		return choice.(providerItem).value, nil
+	}
+
 	model := providerModel{list: l}
 
 	p := tea.NewProgram(model)
@@ -301,6 +309,24 @@ func selectMCPServers() ([]string, error) {
 		preSelected = append(preSelected, server.Name)
 	}
 
+	if utils.PlainUI() {
+		labels := make([]list.Item, len(servers))
+		selected := make([]bool, len(servers))
+		for i, server := range servers {
+			labels[i] = item{title: server.Name, description: server.Description}
+			selected[i] = true
+		}
+		picked, err := plainSelectMany(l.Title, labels, selected)
+		if err != nil {
+			return nil, err
+		}
+		var choices []string
+		for _, i := range picked {
+			choices = append(choices, servers[i].Name)
+		}
+		return choices, nil
+	}
+
 	model := mcpModel{
 		list:    l,
 		servers: servers,
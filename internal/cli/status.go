@@ -0,0 +1,191 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// StatusCmd creates the status command
+func StatusCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		watch      bool
+		interval   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show running workflows",
+		Long: `Show workflows that are currently running in any terminal, with their
+process ID, current agent and elapsed time. Useful for long runs started in tmux
+or another terminal. Named locks (settings.lock) are listed with the run holding
+them and the runs waiting for them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runsDir, err := workflow.RunsDir()
+			if err != nil {
+				return err
+			}
+
+			for {
+				runs, err := workflow.ListRuns(runsDir)
+				if err != nil {
+					return fmt.Errorf("failed to load workflow status: %w", err)
+				}
+
+				if jsonOutput {
+					if runs == nil {
+						runs = []workflow.RunStatus{}
+					}
+					data, err := json.MarshalIndent(runs, "", "  ")
+					if err != nil {
+						return err
+					}
+					fmt.Println(string(data))
+					return nil
+				}
+
+				if watch {
+					// Clear the screen between refreshes
+					fmt.Print("\033[H\033[2J")
+				}
+				printRuns(runs, time.Now())
+				if locksDir, err := workflow.LocksDir(); err == nil {
+					if locks, err := workflow.ListLocks(locksDir); err == nil {
+						printLocks(locks, runs, time.Now())
+					}
+				}
+
+				if !watch {
+					return nil
+				}
+				time.Sleep(interval)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "refresh until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "refresh interval for --watch")
+
+	return cmd
+}
+
+// printRuns prints running workflows as a table
+func printRuns(runs []workflow.RunStatus, now time.Time) {
+	if len(runs) == 0 {
+		fmt.Println("No workflows are running.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN ID\tPID\tWORKFLOW\tSTEP\tAGENT\tAGENT TIME\tTOTAL TIME\tDIRECTORY")
+	fmt.Fprintln(w, "------\t---\t--------\t----\t-----\t----------\t----------\t---------")
+	detached := false
+	for _, run := range runs {
+		workflowName := run.Workflow
+		if run.Socket != "" {
+			workflowName += " (detached)"
+			detached = true
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			run.ID,
+			run.PID,
+			workflowName,
+			formatRunStep(run),
+			formatRunAgent(run),
+			formatRunElapsed(run.AgentStartTime, now),
+			formatRunElapsed(&run.StartTime, now),
+			run.WorkDir,
+		)
+	}
+	_ = w.Flush()
+
+	if detached {
+		fmt.Println("\nAttach to a detached run with: opun attach <run-id>")
+	}
+}
+
+// printLocks prints the held workflow locks and the runs queued for them
+func printLocks(locks []workflow.LockHolder, runs []workflow.RunStatus, now time.Time) {
+	if len(locks) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LOCK\tHELD BY\tRUN ID\tPID\tHELD FOR\tWAITING")
+	fmt.Fprintln(w, "----\t-------\t------\t---\t--------\t-------")
+	for _, lock := range locks {
+		waiting := 0
+		for _, run := range runs {
+			if run.Lock == lock.Name && run.Status == workflow.RunWaiting {
+				waiting++
+			}
+		}
+		runID := lock.RunID
+		if runID == "" {
+			runID = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\n",
+			lock.Name, lock.Workflow, runID, lock.PID, formatRunElapsed(&lock.AcquiredAt, now), waiting)
+	}
+	_ = w.Flush()
+}
+
+// formatRunStep renders the current step as "n/total"
+func formatRunStep(run workflow.RunStatus) string {
+	if run.Status == workflow.RunWaiting {
+		return "waiting for lock " + run.Lock
+	}
+	if run.Status == workflow.RunQueued {
+		return fmt.Sprintf("queued (#%d)", run.QueuePosition)
+	}
+	if run.CurrentAgent == "" {
+		return "starting"
+	}
+	if run.TotalAgents > 0 {
+		return fmt.Sprintf("%d/%d", run.AgentIndex+1, run.TotalAgents)
+	}
+	return fmt.Sprintf("%d", run.AgentIndex+1)
+}
+
+// formatRunAgent renders the current agent and its provider
+func formatRunAgent(run workflow.RunStatus) string {
+	if run.CurrentAgent == "" {
+		return "-"
+	}
+	if run.Provider != "" {
+		return fmt.Sprintf("%s (%s)", run.CurrentAgent, run.Provider)
+	}
+	return run.CurrentAgent
+}
+
+// formatRunElapsed renders the time since start, rounded to seconds
+func formatRunElapsed(start *time.Time, now time.Time) string {
+	if start == nil || start.IsZero() {
+		return "-"
+	}
+	return now.Sub(*start).Round(time.Second).String()
+}
@@ -0,0 +1,124 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"golang.org/x/term"
+)
+
+const (
+	// stdinValue is the --var value that reads the variable from stdin
+	stdinValue = "-"
+
+	// stdinInlineLimit is the piped input size in bytes above which it's
+	// passed as an @file reference, which providers expand, instead of inline
+	stdinInlineLimit = 8 * 1024
+)
+
+// stdinVar returns the variable whose value is read from stdin, if any
+func stdinVar(vars map[string]string) (string, error) {
+	var names []string
+	for name, value := range vars {
+		if value == stdinValue {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	switch len(names) {
+	case 0:
+		return "", nil
+	case 1:
+		return names[0], nil
+	default:
+		return "", fmt.Errorf("only one variable can read stdin, got %s", strings.Join(names, ", "))
+	}
+}
+
+// resolveStdinVars replaces a "-" variable value with what was piped on
+// stdin. Input larger than stdinInlineLimit is written to a temporary file
+// and passed as @path; the returned cleanup removes it.
+func resolveStdinVars(vars map[string]string) (func(), error) {
+	name, err := stdinVar(vars)
+	if err != nil || name == "" {
+		return func() {}, err
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("--var %s=- reads stdin, pipe something in (e.g. cat file | opun ...)", name)
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	value, cleanup, err := stdinReference(data, os.TempDir())
+	if err != nil {
+		return nil, err
+	}
+	vars[name] = value
+	return cleanup, nil
+}
+
+// stdinReference returns piped input as a variable value: inline when it's
+// small, otherwise as an @reference to a file under tempRoot
+func stdinReference(data []byte, tempRoot string) (string, func(), error) {
+	if len(data) <= stdinInlineLimit {
+		return strings.TrimRight(string(data), "\n"), func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp(tempRoot, "opun-stdin-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to store stdin: %w", err)
+	}
+	path := filepath.Join(dir, "stdin.txt")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to store stdin: %w", err)
+	}
+
+	// Removed when the command returns, or at shutdown if it's interrupted
+	unregister := utils.RegisterTempDir(dir)
+	return "@" + path, func() {
+		unregister()
+		_ = os.RemoveAll(dir)
+	}, nil
+}
+
+// reattachTerminal points stdin back at the controlling terminal after piped
+// input was read, so interactive provider sessions still get the keyboard
+func reattachTerminal() {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	path := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		path = "CONIN$"
+	}
+	if tty, err := os.OpenFile(path, os.O_RDWR, 0); err == nil {
+		os.Stdin = tty
+	}
+}
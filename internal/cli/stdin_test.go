@@ -0,0 +1,63 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdinVar(t *testing.T) {
+	name, err := stdinVar(map[string]string{"a": "1", "log": "-"})
+	require.NoError(t, err)
+	assert.Equal(t, "log", name)
+
+	name, err = stdinVar(map[string]string{"a": "1"})
+	require.NoError(t, err)
+	assert.Empty(t, name)
+
+	_, err = stdinVar(map[string]string{"a": "-", "b": "-"})
+	assert.EqualError(t, err, "only one variable can read stdin, got a, b")
+}
+
+func TestStdinReference(t *testing.T) {
+	dir := t.TempDir()
+
+	value, cleanup, err := stdinReference([]byte("panic: boom\n"), dir)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, "panic: boom", value)
+
+	large := strings.Repeat("x", stdinInlineLimit+1)
+	value, cleanup, err = stdinReference([]byte(large), dir)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(value, "@"))
+
+	path := strings.TrimPrefix(value, "@")
+	assert.Equal(t, dir, filepath.Dir(filepath.Dir(path)))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(content))
+
+	cleanup()
+	assert.NoDirExists(t, filepath.Dir(path))
+}
@@ -0,0 +1,74 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/spf13/viper"
+)
+
+// storageFromConfig reads the storage section of the config, the default
+// for workflows without settings.storage. It returns nil when unset.
+func storageFromConfig() *wf.Storage {
+	if !viper.IsSet("storage.backend") {
+		return nil
+	}
+	return &wf.Storage{
+		Backend:        viper.GetString("storage.backend"),
+		Bucket:         viper.GetString("storage.bucket"),
+		Prefix:         viper.GetString("storage.prefix"),
+		Region:         viper.GetString("storage.region"),
+		Endpoint:       viper.GetString("storage.endpoint"),
+		Account:        viper.GetString("storage.account"),
+		ServiceAccount: viper.GetString("storage.service_account"),
+		URLExpiry:      viper.GetString("storage.url_expiry"),
+	}
+}
+
+// applyStorageConfig gives a workflow without settings.storage the
+// configured storage
+func applyStorageConfig(w *wf.Workflow) {
+	if w.Settings.Storage == nil {
+		w.Settings.Storage = storageFromConfig()
+	}
+}
+
+// storeHeadlessOutputs uploads the output directory of a headless or matrix
+// run when storage is configured, signing URLs to files (relative to dir).
+// It returns nil when nothing was uploaded.
+func storeHeadlessOutputs(ctx context.Context, w *wf.Workflow, runID, dir string, files []string) *workflow.StoredArtifacts {
+	if w.Settings.Storage == nil {
+		return nil
+	}
+	var signed []string
+	for _, file := range files {
+		if rel, err := filepath.Rel(dir, file); err == nil && filepath.IsLocal(rel) {
+			signed = append(signed, rel)
+		} else if filepath.IsLocal(file) {
+			signed = append(signed, file)
+		}
+	}
+	vars := workflow.OutputPathVars{Workflow: w.Name, RunID: runID, Time: time.Now()}
+	stored := workflow.StoreArtifacts(context.WithoutCancel(ctx), w.Settings.Storage, dir, vars, signed, nil)
+	workflow.PrintStoredArtifacts(stored)
+	return stored
+}
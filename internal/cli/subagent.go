@@ -26,10 +26,12 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/rizome-dev/opun/internal/script"
 	"github.com/rizome-dev/opun/internal/subagent"
 	"github.com/rizome-dev/opun/pkg/core"
 	subagentpkg "github.com/rizome-dev/opun/pkg/subagent"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
@@ -42,11 +44,23 @@ var (
 func InitSubAgentManager() error {
 	if globalSubAgentManager == nil {
 		globalSubAgentManager = subagentpkg.NewManager()
+
+		// A routing script decides which subagent gets a task
+		if name := viper.GetString("routing_script"); name != "" {
+			s, err := script.Load(name)
+			if err != nil {
+				return fmt.Errorf("failed to load routing script: %w", err)
+			}
+			globalSubAgentManager.SetRouter(script.NewRouter(s, subagentpkg.NewSimpleRouter()))
+		}
 		
 		// Load subagent configurations from disk
 		if err := loadSubAgentConfigs(); err != nil {
 			return fmt.Errorf("failed to load subagent configs: %w", err)
 		}
+
+		// Ratings given with `opun feedback` steer routing
+		learnFromFeedback(globalSubAgentManager)
 	}
 	return nil
 }
@@ -344,6 +358,17 @@ or loaded from a file using the --input flag.`,
 				}
 			}
 
+			if len(result.Artifacts) > 0 {
+				fmt.Printf("\n📎 Artifacts:\n")
+				for _, artifact := range result.Artifacts {
+					change := artifact.Change
+					if change == "" {
+						change = artifact.Type
+					}
+					fmt.Printf("  %s (%s, %d bytes)\n", artifact.Name, change, artifact.Size)
+				}
+			}
+
 			if result.Error != nil {
 				fmt.Printf("\n❌ Error: %v\n", result.Error)
 			}
@@ -406,6 +431,14 @@ func subAgentInfoCmd() *cobra.Command {
 				fmt.Printf("\n📝 System Prompt:\n%s\n", config.SystemPrompt)
 			}
 
+			if agents, ok := mgr.RouterStats()["agents"].(map[string]interface{}); ok {
+				if stats, ok := agents[agent.Name()].(map[string]interface{}); ok {
+					if rating, ok := stats["avg_rating"].(float64); ok {
+						fmt.Printf("\n⭐ Feedback: %.1f average from %v ratings\n", rating, stats["ratings"])
+					}
+				}
+			}
+
 			// Show active tasks if any
 			activeTasks := mgr.ListActiveTasks()
 			if len(activeTasks) > 0 {
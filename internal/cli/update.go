@@ -28,6 +28,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rizome-dev/opun/internal/promptgarden"
 	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/internal/workflow"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -41,12 +42,19 @@ func UpdateCmd() *cobra.Command {
 		isAction   bool
 		name       string
 		path       string
+		fork       bool
+		force      bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update existing workflows, prompts, or tools",
-		Long:  `Update existing workflows, prompts, or tools that have been added to Opun.`,
+		Long: `Update existing workflows, prompts, or tools that have been added to Opun.
+
+Items pulled from a team remote as a member, or installed from a plugin
+manifest, are read-only: updating one warns and offers to add your version
+as a personal fork (my-<name>) instead. --fork does so without asking and
+--force changes the managed item anyway.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// If no flags provided, run interactive mode
 			if !isWorkflow && !isPrompt && !isAction {
@@ -62,6 +70,16 @@ func UpdateCmd() *cobra.Command {
 				return fmt.Errorf("--path is required")
 			}
 
+			kind := "action"
+			if isWorkflow {
+				kind = "workflow"
+			} else if isPrompt {
+				kind = "prompt"
+			}
+			if proceed, err := guardManaged(kind, name, path, fork, force); err != nil || !proceed {
+				return err
+			}
+
 			// Determine what to update based on flags
 			if isWorkflow {
 				return updateWorkflow(name, path)
@@ -85,9 +103,12 @@ func UpdateCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&isAction, "action", false, "Update an action")
 	cmd.Flags().StringVar(&name, "name", "", "Name of the workflow, prompt, or action to update")
 	cmd.Flags().StringVar(&path, "path", "", "New file path for the workflow, prompt, or action")
+	cmd.Flags().BoolVar(&fork, "fork", false, "Add a read-only item's new version as a personal copy")
+	cmd.Flags().BoolVar(&force, "force", false, "Change a read-only item anyway")
 
 	// Only one of workflow, prompt, or tool can be used at a time
 	cmd.MarkFlagsMutuallyExclusive("workflow", "prompt", "action")
+	cmd.MarkFlagsMutuallyExclusive("fork", "force")
 
 	return cmd
 }
@@ -414,6 +435,14 @@ func runInteractiveUpdate() error {
 			return nil
 		}
 
+		kind := typeChoice
+		if kind == "tool" {
+			kind = "action"
+		}
+		if proceed, err := guardManaged(kind, selectedItem.name, path, false, false); err != nil || !proceed {
+			return err
+		}
+
 		// Step 6a: Execute the update
 		fmt.Printf("\n📝 Updating %s...\n", typeChoice)
 
@@ -429,6 +458,9 @@ func runInteractiveUpdate() error {
 		}
 	} else {
 		// Step 4b: Execute interactive update
+		if proceed, err := guardManaged(selectedItem.itemType, selectedItem.name, "", false, false); err != nil || !proceed {
+			return err
+		}
 		return runInteractiveEdit(selectedItem)
 	}
 }
@@ -460,10 +492,22 @@ func selectUpdateMethod() (string, error) {
 
 	model := addModel{list: l, state: "choosing"}
 
-	p := tea.NewProgram(model)
-	result, err := p.Run()
-	if err != nil {
-		return "", err
+	var result tea.Model = model
+	if utils.PlainUI() {
+		choice, err := plainSelect(l.Title, items)
+		if err != nil {
+			return "", err
+		}
+		chosen := choice.(updateTypeItem)
+		model.choice = &chosen
+		result = model
+	} else {
+		p := tea.NewProgram(model)
+		var err error
+		result, err = p.Run()
+		if err != nil {
+			return "", err
+		}
 	}
 
 	if m, ok := result.(addModel); ok && m.choice != nil {
@@ -773,10 +817,22 @@ func selectUpdateType() (string, error) {
 
 	model := addModel{list: l, state: "choosing"}
 
-	p := tea.NewProgram(model)
-	result, err := p.Run()
-	if err != nil {
-		return "", err
+	var result tea.Model = model
+	if utils.PlainUI() {
+		choice, err := plainSelect(l.Title, items)
+		if err != nil {
+			return "", err
+		}
+		chosen := choice.(updateTypeItem)
+		model.choice = &chosen
+		result = model
+	} else {
+		p := tea.NewProgram(model)
+		var err error
+		result, err = p.Run()
+		if err != nil {
+			return "", err
+		}
 	}
 
 	if m, ok := result.(addModel); ok && m.choice != nil {
@@ -864,14 +920,9 @@ func getPromptItems() ([]updateItem, error) {
 		return nil, fmt.Errorf("failed to access prompt garden: %w", err)
 	}
 
-	prompts, err := garden.ListPrompts()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list prompts: %w", err)
-	}
-
 	var items []updateItem
-	for _, prompt := range prompts {
-		description := prompt.Metadata.Description
+	for _, prompt := range garden.Summaries() {
+		description := prompt.Description
 		if description == "" {
 			description = "Prompt"
 		}
@@ -972,6 +1023,15 @@ func selectItemToUpdate(items []updateItem, itemType string) (*updateItem, error
 		itemType: itemType,
 	}
 
+	if utils.PlainUI() {
+		choice, err := plainSelect(l.Title, listItems)
+		if err != nil {
+			return nil, err
+		}
+		chosen := choice.(updateItem)
+		return &chosen, nil
+	}
+
 	p := tea.NewProgram(model)
 	result, err := p.Run()
 	if err != nil {
@@ -0,0 +1,556 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/internal/watch"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// maxWatchRuns is how many finished runs the status view lists
+	maxWatchRuns = 8
+
+	// maxWatchOutput is how many output lines the status view keeps
+	maxWatchOutput = 10
+)
+
+// watchPipeline is a named list of steps run when matching files change
+type watchPipeline struct {
+	Name     string            `mapstructure:"-"`
+	Glob     []string          `mapstructure:"glob"`
+	Ignore   []string          `mapstructure:"ignore"`
+	Run      []string          `mapstructure:"run"`
+	Debounce time.Duration     `mapstructure:"debounce"`
+	Vars     map[string]string `mapstructure:"vars"`
+}
+
+// watchStep is one step of a pipeline, e.g. "workflow:quick-review"
+type watchStep struct {
+	Kind   string
+	Name   string
+	action *core.StandardAction
+}
+
+func (s watchStep) String() string {
+	return s.Kind + ":" + s.Name
+}
+
+// parseWatchStep parses a workflow:NAME or action:ID step
+func parseWatchStep(spec string) (watchStep, error) {
+	kind, name, ok := strings.Cut(spec, ":")
+	if !ok || name == "" || (kind != "workflow" && kind != "action") {
+		return watchStep{}, fmt.Errorf("invalid step %q: use workflow:NAME or action:ID", spec)
+	}
+	return watchStep{Kind: kind, Name: name}, nil
+}
+
+// resolveWatchStep checks that a step's workflow or action exists. Actions
+// that wrap a workflow become workflow steps.
+func resolveWatchStep(step watchStep) (watchStep, error) {
+	if step.Kind == "action" {
+		action, err := loadAction(step.Name)
+		if err != nil {
+			return step, err
+		}
+		switch {
+		case len(action.Steps) > 0:
+		case action.Command != "":
+			action.Steps = []core.ActionStep{{Name: action.ID, Command: action.Command}}
+		case action.WorkflowRef != "":
+			step = watchStep{Kind: "workflow", Name: action.WorkflowRef}
+		default:
+			return step, fmt.Errorf("action %s has no command or workflow to run", step.Name)
+		}
+		if step.Kind == "action" {
+			step.action = action
+			return step, nil
+		}
+	}
+
+	if _, err := loadWorkflow(step.Name); err != nil {
+		return step, err
+	}
+	return step, nil
+}
+
+// workflowArgs returns the opun arguments that run a workflow step headlessly
+func (s watchStep) workflowArgs(vars map[string]string, changed []string) []string {
+	args := []string{"run", s.Name, "--headless"}
+	if _, ok := vars["changed_files"]; !ok {
+		args = append(args, "--var", "changed_files="+strings.Join(changed, " "))
+	}
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--var", k+"="+vars[k])
+	}
+	return args
+}
+
+// WatchCmd creates the watch command
+func WatchCmd() *cobra.Command {
+	var (
+		globs    []string
+		ignore   []string
+		steps    []string
+		debounce time.Duration
+		interval time.Duration
+		vars     map[string]string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch [pipeline...]",
+		Short: "Run pipelines when files change",
+		Long: `Watch the current directory and run a pipeline of workflows and actions
+whenever matching files change. Workflows run headlessly and get the changed
+files in the changed_files variable; actions get them space separated in
+ARGUMENTS and one per line in OPUN_WATCH_CHANGED.
+Steps run in order and a pipeline stops at its first failing step.
+
+Pipelines are either given with flags:
+
+  opun watch --glob "**/*.go" --run workflow:quick-review --debounce 5s
+
+or named in the config and selected by name (all of them when none is given):
+
+  watch_pipelines:
+    review:
+      glob: ["**/*.go"]
+      ignore: ["**/*_test.go"]
+      run: ["action:lint", "workflow:quick-review"]
+      debounce: 5s
+
+Hidden directories, node_modules and vendor are never watched, and files
+written while a pipeline runs don't trigger it again.`,
+		Example: `  opun watch --glob "**/*.go" --run workflow:quick-review
+  opun watch --glob "docs/**" --ignore "docs/build/**" --run action:docs
+  opun watch review`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var pipelines []watchPipeline
+			if len(steps) > 0 {
+				if len(args) > 0 {
+					return fmt.Errorf("use either --run or named pipelines, not both")
+				}
+				pipelines = append(pipelines, watchPipeline{
+					Name:     strings.Join(steps, " → "),
+					Glob:     globs,
+					Ignore:   ignore,
+					Run:      steps,
+					Debounce: debounce,
+					Vars:     vars,
+				})
+			} else {
+				configured, err := loadWatchPipelines(args)
+				if err != nil {
+					return err
+				}
+				for _, p := range configured {
+					p.Glob = append(p.Glob, globs...)
+					p.Ignore = append(p.Ignore, ignore...)
+					if cmd.Flags().Changed("debounce") || p.Debounce == 0 {
+						p.Debounce = debounce
+					}
+					if p.Vars == nil {
+						p.Vars = make(map[string]string)
+					}
+					for k, v := range vars {
+						p.Vars[k] = v
+					}
+					pipelines = append(pipelines, p)
+				}
+			}
+
+			useTUI := !utils.PlainUI()
+			return runWatch(pipelines, interval, useTUI)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&globs, "glob", nil, "files to watch, e.g. \"**/*.go\" (repeatable, default all)")
+	cmd.Flags().StringArrayVar(&ignore, "ignore", nil, "files or directories to ignore, e.g. \"dist/**\" (repeatable)")
+	cmd.Flags().StringArrayVar(&steps, "run", nil, "step to run on changes: workflow:NAME or action:ID (repeatable, run in order)")
+	cmd.Flags().DurationVar(&debounce, "debounce", watch.DefaultDebounce, "how long files must be quiet before the pipeline runs")
+	cmd.Flags().DurationVar(&interval, "interval", watch.DefaultInterval, "how often to check for changes")
+	cmd.Flags().StringToStringVarP(&vars, "var", "v", map[string]string{}, "variables to pass to workflows (key=value)")
+
+	return cmd
+}
+
+// loadWatchPipelines reads the watch_pipelines section of the config,
+// returning the named pipelines or all of them
+func loadWatchPipelines(names []string) ([]watchPipeline, error) {
+	configured := make(map[string]watchPipeline)
+	if err := viper.UnmarshalKey("watch_pipelines", &configured); err != nil {
+		return nil, fmt.Errorf("invalid watch_pipelines config: %w", err)
+	}
+	if len(configured) == 0 {
+		return nil, fmt.Errorf("no pipelines configured: pass --run or add watch_pipelines to the config")
+	}
+
+	if len(names) == 0 {
+		for name := range configured {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var pipelines []watchPipeline
+	for _, name := range names {
+		p, ok := configured[name]
+		if !ok {
+			return nil, fmt.Errorf("pipeline %s not found in watch_pipelines", name)
+		}
+		p.Name = name
+		pipelines = append(pipelines, p)
+	}
+	return pipelines, nil
+}
+
+// watchEvent reports what a pipeline is doing
+type watchEvent struct {
+	Pipeline string
+	State    string
+	Changed  int
+	Output   string
+	Run      *watchRun
+}
+
+// watchRun is a finished pipeline run
+type watchRun struct {
+	Pipeline string
+	Changed  int
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// runWatch watches for every pipeline until interrupted
+func runWatch(pipelines []watchPipeline, interval time.Duration, useTUI bool) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the opun executable: %w", err)
+	}
+
+	type prepared struct {
+		pipeline watchPipeline
+		steps    []watchStep
+		watcher  *watch.Watcher
+	}
+	var all []prepared
+	for _, p := range pipelines {
+		if len(p.Run) == 0 {
+			return fmt.Errorf("pipeline %s has no steps to run", p.Name)
+		}
+		var steps []watchStep
+		for _, spec := range p.Run {
+			step, err := parseWatchStep(spec)
+			if err == nil {
+				step, err = resolveWatchStep(step)
+			}
+			if err != nil {
+				return fmt.Errorf("pipeline %s: %w", p.Name, err)
+			}
+			steps = append(steps, step)
+		}
+
+		watcher, err := watch.New(".", p.Glob, p.Ignore)
+		if err != nil {
+			return fmt.Errorf("pipeline %s: %w", p.Name, err)
+		}
+		watcher.Interval = interval
+		watcher.Debounce = p.Debounce
+		all = append(all, prepared{pipeline: p, steps: steps, watcher: watcher})
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var program *tea.Program
+	var report func(watchEvent)
+	if useTUI {
+		names := make([]string, len(all))
+		for i, p := range all {
+			names[i] = p.pipeline.Name
+		}
+		program = tea.NewProgram(newWatchModel(names), tea.WithAltScreen())
+		report = func(e watchEvent) { program.Send(e) }
+	} else {
+		report = printWatchEvent
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(all))
+	for _, p := range all {
+		p := p
+		p.watcher.OnChange = func(changed []string) {
+			report(watchEvent{Pipeline: p.pipeline.Name, State: "debouncing", Changed: len(changed)})
+		}
+		report(watchEvent{Pipeline: p.pipeline.Name, State: "watching"})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.watcher.Run(ctx, func(ctx context.Context, changed []string) {
+				runWatchPipeline(ctx, executable, p.pipeline, p.steps, changed, report)
+			})
+			if err != nil {
+				errs <- fmt.Errorf("pipeline %s: %w", p.pipeline.Name, err)
+				cancel()
+			}
+		}()
+	}
+
+	if program != nil {
+		go func() {
+			<-ctx.Done()
+			program.Quit()
+		}()
+		if _, err := program.Run(); err != nil {
+			cancel()
+			wg.Wait()
+			return fmt.Errorf("status view failed: %w", err)
+		}
+		cancel()
+	} else {
+		<-ctx.Done()
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// runWatchPipeline runs a pipeline's steps in order, stopping at the first
+// failure. Workflows run as headless opun subprocesses so each gets its own
+// providers and output directory.
+func runWatchPipeline(ctx context.Context, executable string, p watchPipeline, steps []watchStep, changed []string, report func(watchEvent)) {
+	run := &watchRun{Pipeline: p.Name, Changed: len(changed), Start: time.Now()}
+	output := &watchOutput{report: func(line string) {
+		report(watchEvent{Pipeline: p.Name, Output: line})
+	}}
+
+	for _, step := range steps {
+		report(watchEvent{Pipeline: p.Name, State: "running " + step.String(), Changed: len(changed)})
+
+		var err error
+		if step.action != nil {
+			err = runWatchAction(ctx, *step.action, changed, output)
+		} else {
+			cmd := exec.CommandContext(ctx, executable, step.workflowArgs(p.Vars, changed)...)
+			cmd.Env = append(os.Environ(), "OPUN_WATCH_CHANGED="+strings.Join(changed, "\n"))
+			cmd.Stdout = output
+			cmd.Stderr = output
+			err = cmd.Run()
+		}
+		output.flush()
+		if err != nil {
+			run.Err = fmt.Errorf("%s: %w", step, err)
+			break
+		}
+	}
+
+	run.Duration = time.Since(run.Start)
+	report(watchEvent{Pipeline: p.Name, State: "watching", Run: run})
+}
+
+// runWatchAction runs an action's steps with the changed files in ARGUMENTS
+// and OPUN_WATCH_CHANGED
+func runWatchAction(ctx context.Context, action core.StandardAction, changed []string, output *watchOutput) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	env := make(map[string]string, len(action.Env)+1)
+	for k, v := range action.Env {
+		env[k] = v
+	}
+	env["OPUN_WATCH_CHANGED"] = strings.Join(changed, "\n")
+	action.Env = env
+
+	runner := tools.NewStepRunner(cwd)
+	runner.Output = output
+	_, err = runner.Run(ctx, action, strings.Join(changed, " "))
+	return err
+}
+
+// watchOutput splits a step's output into lines for the status view
+type watchOutput struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	report func(line string)
+}
+
+func (o *watchOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.buf.Write(p)
+	for {
+		line, err := o.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line for the next write
+			o.buf.WriteString(line)
+			return len(p), nil
+		}
+		o.report(strings.TrimRight(line, "\r\n"))
+	}
+}
+
+func (o *watchOutput) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.buf.Len() > 0 {
+		o.report(o.buf.String())
+		o.buf.Reset()
+	}
+}
+
+// printWatchEvent prints an event as a plain log line
+func printWatchEvent(e watchEvent) {
+	switch {
+	case e.Run != nil:
+		if e.Run.Err != nil {
+			fmt.Printf("❌ %s failed after %s: %v\n", e.Pipeline, e.Run.Duration.Round(time.Millisecond), e.Run.Err)
+		} else {
+			fmt.Printf("✅ %s finished in %s\n", e.Pipeline, e.Run.Duration.Round(time.Millisecond))
+		}
+	case e.Output != "":
+		fmt.Printf("   │ %s\n", e.Output)
+	case e.State == "watching":
+		fmt.Printf("👀 Watching for %s\n", e.Pipeline)
+	case e.State == "debouncing":
+		fmt.Printf("📝 %d file(s) changed for %s\n", e.Changed, e.Pipeline)
+	default:
+		fmt.Printf("🚀 %s: %s\n", e.Pipeline, e.State)
+	}
+}
+
+// watchModel is the status view of opun watch
+type watchModel struct {
+	pipelines []string
+	states    map[string]string
+	changed   map[string]int
+	runs      []watchRun
+	output    []string
+}
+
+func newWatchModel(pipelines []string) watchModel {
+	return watchModel{
+		pipelines: pipelines,
+		states:    make(map[string]string),
+		changed:   make(map[string]int),
+	}
+}
+
+func (m watchModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		}
+
+	case watchEvent:
+		if msg.State != "" {
+			m.states[msg.Pipeline] = msg.State
+		}
+		if msg.State == "debouncing" {
+			m.changed[msg.Pipeline] += msg.Changed
+		}
+		if msg.Output != "" {
+			m.output = append(m.output, msg.Output)
+			if len(m.output) > maxWatchOutput {
+				m.output = m.output[len(m.output)-maxWatchOutput:]
+			}
+		}
+		if msg.Run != nil {
+			m.changed[msg.Pipeline] = 0
+			m.runs = append([]watchRun{*msg.Run}, m.runs...)
+			if len(m.runs) > maxWatchRuns {
+				m.runs = m.runs[:maxWatchRuns]
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m watchModel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("👀 opun watch") + "\n\n")
+
+	for _, name := range m.pipelines {
+		state := m.states[name]
+		if n := m.changed[name]; n > 0 && state == "debouncing" {
+			state = fmt.Sprintf("debouncing (%d changes)", n)
+		}
+		fmt.Fprintf(&b, "  %-30s %s\n", name, state)
+	}
+
+	if len(m.runs) > 0 {
+		b.WriteString("\n" + titleStyle.Render("Recent runs") + "\n")
+		for _, run := range m.runs {
+			icon, detail := "✅", ""
+			if run.Err != nil {
+				icon, detail = "❌", "  "+run.Err.Error()
+			}
+			fmt.Fprintf(&b, "  %s %s  %s  %d files  %s%s\n", icon, run.Start.Format("15:04:05"), run.Pipeline,
+				run.Changed, run.Duration.Round(time.Millisecond), detail)
+		}
+	}
+
+	if len(m.output) > 0 {
+		b.WriteString("\n" + titleStyle.Render("Output") + "\n")
+		for _, line := range m.output {
+			b.WriteString(dimStyle.Render("  │ "+line) + "\n")
+		}
+	}
+
+	b.WriteString("\n" + dimStyle.Render("q to quit") + "\n")
+	return b.String()
+}
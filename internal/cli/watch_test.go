@@ -0,0 +1,95 @@
+package cli
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWatchStep(t *testing.T) {
+	step, err := parseWatchStep("workflow:quick-review")
+	require.NoError(t, err)
+	assert.Equal(t, watchStep{Kind: "workflow", Name: "quick-review"}, step)
+	assert.Equal(t, "workflow:quick-review", step.String())
+
+	step, err = parseWatchStep("action:lint")
+	require.NoError(t, err)
+	assert.Equal(t, "action", step.Kind)
+
+	for _, spec := range []string{"quick-review", "workflow:", "script:lint"} {
+		_, err := parseWatchStep(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestWatchStepWorkflowArgs(t *testing.T) {
+	step := watchStep{Kind: "workflow", Name: "review"}
+
+	args := step.workflowArgs(map[string]string{"b": "2", "a": "1"}, []string{"main.go", "pkg/x.go"})
+	assert.Equal(t, []string{"run", "review", "--headless",
+		"--var", "changed_files=main.go pkg/x.go", "--var", "a=1", "--var", "b=2"}, args)
+
+	args = step.workflowArgs(map[string]string{"changed_files": "all"}, []string{"main.go"})
+	assert.Equal(t, []string{"run", "review", "--headless", "--var", "changed_files=all"}, args)
+}
+
+func TestWatchOutput(t *testing.T) {
+	var lines []string
+	output := &watchOutput{report: func(line string) { lines = append(lines, line) }}
+
+	_, _ = output.Write([]byte("first\nsec"))
+	_, _ = output.Write([]byte("ond\r\nthird"))
+	assert.Equal(t, []string{"first", "second"}, lines)
+
+	output.flush()
+	assert.Equal(t, []string{"first", "second", "third"}, lines)
+}
+
+func TestWatchModel(t *testing.T) {
+	var m watchModel = newWatchModel([]string{"review"})
+
+	update := func(e watchEvent) {
+		model, _ := m.Update(e)
+		m = model.(watchModel)
+	}
+
+	update(watchEvent{Pipeline: "review", State: "watching"})
+	update(watchEvent{Pipeline: "review", State: "debouncing", Changed: 2})
+	update(watchEvent{Pipeline: "review", State: "debouncing", Changed: 1})
+	assert.Contains(t, m.View(), "debouncing (3 changes)")
+
+	update(watchEvent{Pipeline: "review", Output: "looks good"})
+	update(watchEvent{Pipeline: "review", State: "watching", Run: &watchRun{
+		Pipeline: "review", Changed: 3, Start: time.Now(), Duration: time.Second, Err: errors.New("workflow:review: exit status 1"),
+	}})
+
+	view := m.View()
+	assert.Contains(t, view, "❌")
+	assert.Contains(t, view, "exit status 1")
+	assert.Contains(t, view, "looks good")
+	assert.Equal(t, 0, m.changed["review"])
+
+	for i := 0; i < maxWatchOutput+5; i++ {
+		update(watchEvent{Pipeline: "review", Output: "line"})
+	}
+	assert.Len(t, m.output, maxWatchOutput)
+}
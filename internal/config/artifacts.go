@@ -0,0 +1,276 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/core"
+)
+
+// GeneratedManifestFile records the files Opun generated in a provider commands directory
+const GeneratedManifestFile = ".opun-generated.json"
+
+// Kinds of items whose generated artifacts can be removed
+const (
+	ArtifactWorkflow = "workflow"
+	ArtifactPrompt   = "prompt"
+	ArtifactAction   = "action"
+)
+
+// GeneratedFiles tracks the command files written into a provider commands
+// directory together with their content hashes. Files for items that no longer
+// exist can then be removed without touching files the user wrote or edited.
+type GeneratedFiles struct {
+	dir      string
+	manifest string
+	files    map[string]string // path relative to dir -> sha256 of the generated content
+	written  map[string]bool
+	modified []string
+}
+
+// LoadGeneratedFiles reads the manifest of dir; a missing or unreadable
+// manifest means nothing is known to be generated yet
+func LoadGeneratedFiles(dir string) *GeneratedFiles {
+	return loadGeneratedFiles(dir, filepath.Join(dir, GeneratedManifestFile))
+}
+
+// loadGeneratedFiles tracks files under dir in a manifest kept elsewhere, for
+// directories like the project root where a manifest would be in the way
+func loadGeneratedFiles(dir, manifest string) *GeneratedFiles {
+	g := &GeneratedFiles{
+		dir:      dir,
+		manifest: manifest,
+		files:    make(map[string]string),
+		written:  make(map[string]bool),
+	}
+
+	// #nosec G304 -- manifest lives in the provider commands directory or the project workspace
+	if data, err := os.ReadFile(manifest); err == nil {
+		_ = json.Unmarshal(data, &g.files)
+	}
+	return g
+}
+
+// Write writes a generated file and records it in the manifest. A file that
+// already holds the same content is left untouched, and so is a file that was
+// changed since Opun generated it; those are listed by Modified.
+func (g *GeneratedFiles) Write(path string, data []byte) error {
+	rel, err := filepath.Rel(g.dir, path)
+	if err != nil {
+		_, err = writeIfChanged(path, data)
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+
+	hash := contentHash(data)
+	if previous, tracked := g.files[rel]; tracked {
+		// #nosec G304 -- path is a tracked generated file
+		if existing, err := os.ReadFile(path); err == nil {
+			if current := contentHash(existing); current != previous && current != hash {
+				// Keep tracking the generated content, so the file is
+				// regenerated once it's deleted or restored
+				g.written[rel] = true
+				g.modified = append(g.modified, path)
+				return nil
+			}
+		}
+	}
+
+	if _, err := writeIfChanged(path, data); err != nil {
+		return err
+	}
+	g.files[rel] = hash
+	g.written[rel] = true
+	return nil
+}
+
+// Modified returns the generated files Write left alone because they were
+// changed outside Opun since it last wrote them
+func (g *GeneratedFiles) Modified() []string {
+	return g.modified
+}
+
+// Prune removes the files recorded by an earlier generation that were not
+// written again, then saves the manifest. It returns the removed paths.
+func (g *GeneratedFiles) Prune() ([]string, error) {
+	var stale []string
+	for rel := range g.files {
+		if !g.written[rel] {
+			stale = append(stale, rel)
+		}
+	}
+	return g.Remove(stale...)
+}
+
+// Remove deletes the given generated files, relative to the directory, if
+// they still hold the content Opun wrote. Edited files are left in place but
+// are no longer tracked. It returns the removed paths.
+func (g *GeneratedFiles) Remove(paths ...string) ([]string, error) {
+	sort.Strings(paths)
+
+	var removed []string
+	for _, rel := range paths {
+		rel = filepath.ToSlash(rel)
+		hash, tracked := g.files[rel]
+		if !tracked {
+			continue
+		}
+		delete(g.files, rel)
+		delete(g.written, rel)
+
+		path := filepath.Join(g.dir, filepath.FromSlash(rel))
+		// #nosec G304 -- path is a tracked file in the commands directory
+		data, err := os.ReadFile(path)
+		if err != nil || contentHash(data) != hash {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, g.save()
+}
+
+// save writes the manifest, or removes it once nothing is tracked
+func (g *GeneratedFiles) save() error {
+	path := g.manifest
+	if len(g.files) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(g.files, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writeIfChanged(path, data)
+	return err
+}
+
+// writeIfChanged writes data to path unless the file's content hash already
+// matches, so regenerating provider files on every launch doesn't touch their
+// modification times and wake file watchers in the project. It reports
+// whether the file was written.
+func writeIfChanged(path string, data []byte) (bool, error) {
+	// #nosec G304 -- path is a file Opun generates
+	if existing, err := os.ReadFile(path); err == nil && contentHash(existing) == contentHash(data) {
+		return false, nil
+	}
+	if err := utils.WriteFile(path, data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CleanupReport lists the generated artifacts removed for a deleted item
+type CleanupReport struct {
+	SlashCommands []string // shared slash commands removed from the configuration
+	Files         []string // generated command files removed from the project and the Gemini extension
+}
+
+// RemoveGeneratedArtifacts removes the shared slash commands and the provider
+// command files generated for a workflow, prompt or action. Files are removed
+// from projectDir's .claude/commands and Gemini extension; other projects drop theirs the
+// next time a provider is prepared there, since generation prunes files for
+// items that no longer exist.
+func RemoveGeneratedArtifacts(kind, name, projectDir string) (*CleanupReport, error) {
+	manager, err := NewSharedConfigManager()
+	if err != nil {
+		return nil, err
+	}
+	return removeGeneratedArtifacts(manager, kind, name, projectDir)
+}
+
+func removeGeneratedArtifacts(manager *SharedConfigManager, kind, name, projectDir string) (*CleanupReport, error) {
+	report := &CleanupReport{}
+	var files []string
+
+	var handler string
+	switch kind {
+	case ArtifactWorkflow:
+		handler = name
+	case ArtifactPrompt:
+		handler = "promptgarden://" + name
+		var generator PromptCommandGenerator
+		files = append(files, "prompts/"+generator.sanitizeCommandName(name)+".md")
+	case ArtifactAction:
+		files = append(files, name+".md")
+	default:
+		return nil, fmt.Errorf("unknown item kind: %s", kind)
+	}
+
+	if handler != "" {
+		removed, err := manager.RemoveSlashCommands(func(cmd core.SharedSlashCommand) bool {
+			return cmd.Type == kind && cmd.Handler == handler
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update slash commands: %w", err)
+		}
+		for _, cmd := range removed {
+			report.SlashCommands = append(report.SlashCommands, cmd.Name)
+			files = append(files, cmd.Name+".md")
+			for _, alias := range cmd.Aliases {
+				files = append(files, alias+".md")
+			}
+		}
+	}
+
+	// Don't race a session generating the project's files
+	unlock, err := lockFile(projectDir)
+	if err != nil {
+		return report, err
+	}
+	defer unlock()
+
+	generated := LoadGeneratedFiles(filepath.Join(projectDir, ".claude", "commands"))
+	removed, err := generated.Remove(files...)
+	report.Files = removed
+	if err != nil {
+		return report, err
+	}
+
+	// The Gemini extension names its commands like Claude's, as TOML
+	tomlFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		tomlFiles = append(tomlFiles, strings.TrimSuffix(file, ".md")+".toml")
+	}
+	removed, err = LoadGeneratedFiles(filepath.Join(GeminiExtensionDir(projectDir), "commands")).Remove(tomlFiles...)
+	report.Files = append(report.Files, removed...)
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,224 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	generated := LoadGeneratedFiles(dir)
+	require.NoError(t, generated.Write(filepath.Join(dir, "keep.md"), []byte("keep")))
+	require.NoError(t, generated.Write(filepath.Join(dir, "stale.md"), []byte("stale")))
+	require.NoError(t, generated.Write(filepath.Join(dir, "edited.md"), []byte("generated")))
+	require.NoError(t, generated.Write(filepath.Join(dir, "prompts", "old.md"), []byte("old")))
+	removed, err := generated.Prune()
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+	assert.FileExists(t, filepath.Join(dir, GeneratedManifestFile))
+
+	// The user edits one file and writes another by hand
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "edited.md"), []byte("mine now"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "handwritten.md"), []byte("mine"), 0644))
+
+	// The next generation only produces keep.md
+	generated = LoadGeneratedFiles(dir)
+	require.NoError(t, generated.Write(filepath.Join(dir, "keep.md"), []byte("keep")))
+	removed, err = generated.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "prompts", "old.md"), filepath.Join(dir, "stale.md")}, removed)
+
+	assert.FileExists(t, filepath.Join(dir, "keep.md"))
+	assert.FileExists(t, filepath.Join(dir, "edited.md"))
+	assert.FileExists(t, filepath.Join(dir, "handwritten.md"))
+	assert.NoFileExists(t, filepath.Join(dir, "stale.md"))
+
+	// Only keep.md is still tracked
+	generated = LoadGeneratedFiles(dir)
+	assert.Equal(t, []string{"keep.md"}, trackedFiles(generated))
+
+	removed, err = generated.Remove("keep.md")
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.NoFileExists(t, filepath.Join(dir, GeneratedManifestFile))
+}
+
+func TestGeneratedFilesSkipUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "review.md")
+	manifest := filepath.Join(dir, GeneratedManifestFile)
+
+	generated := LoadGeneratedFiles(dir)
+	require.NoError(t, generated.Write(path, []byte("review")))
+	_, err := generated.Prune()
+	require.NoError(t, err)
+
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(path, old, old))
+	require.NoError(t, os.Chtimes(manifest, old, old))
+
+	// Regenerating the same content leaves both files untouched
+	generated = LoadGeneratedFiles(dir)
+	require.NoError(t, generated.Write(path, []byte("review")))
+	_, err = generated.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, old, modTime(t, path))
+	assert.Equal(t, old, modTime(t, manifest))
+
+	// Changed content is written
+	generated = LoadGeneratedFiles(dir)
+	require.NoError(t, generated.Write(path, []byte("review v2")))
+	_, err = generated.Prune()
+	require.NoError(t, err)
+	assert.NotEqual(t, old, modTime(t, path))
+	assert.NotEqual(t, old, modTime(t, manifest))
+}
+
+func TestGeneratedFilesModified(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".mcp.json")
+	manifest := filepath.Join(t.TempDir(), GeneratedManifestFile)
+
+	generated := loadGeneratedFiles(dir, manifest)
+	require.NoError(t, generated.Write(path, []byte("v1")))
+	_, err := generated.Prune()
+	require.NoError(t, err)
+	assert.FileExists(t, manifest)
+	assert.NoFileExists(t, filepath.Join(dir, GeneratedManifestFile))
+
+	// Another tool edits the file; regenerating leaves the edit in place
+	require.NoError(t, os.WriteFile(path, []byte("edited"), 0644))
+	generated = loadGeneratedFiles(dir, manifest)
+	require.NoError(t, generated.Write(path, []byte("v2")))
+	_, err = generated.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, generated.Modified())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(data))
+
+	// It stays tracked, so deleting it brings the generated content back
+	require.NoError(t, os.Remove(path))
+	generated = loadGeneratedFiles(dir, manifest)
+	require.NoError(t, generated.Write(path, []byte("v2")))
+	assert.Empty(t, generated.Modified())
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+}
+
+func TestWriteIfChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", ".mcp.json")
+
+	written, err := writeIfChanged(path, []byte("{}"))
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	written, err = writeIfChanged(path, []byte("{}"))
+	require.NoError(t, err)
+	assert.False(t, written)
+
+	written, err = writeIfChanged(path, []byte(`{"mcpServers":{}}`))
+	require.NoError(t, err)
+	assert.True(t, written)
+}
+
+func modTime(t *testing.T, path string) time.Time {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.ModTime()
+}
+
+func trackedFiles(g *GeneratedFiles) []string {
+	var files []string
+	for rel := range g.files {
+		files = append(files, rel)
+	}
+	return files
+}
+
+func TestRemoveGeneratedArtifacts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	manager := &SharedConfigManager{
+		configPath: filepath.Join(home, "shared-config.yaml"),
+		config: &core.SharedConfig{
+			SlashCommands: []core.SharedSlashCommand{
+				{Name: "code-review", Type: "prompt", Handler: "promptgarden://code-review", Aliases: []string{"cr"}},
+				{Name: "other", Type: "prompt", Handler: "promptgarden://other"},
+				{Name: "deploy", Type: "workflow", Handler: "deploy"},
+			},
+		},
+	}
+
+	project := t.TempDir()
+	commandsDir := filepath.Join(project, ".claude", "commands")
+	generated := LoadGeneratedFiles(commandsDir)
+	for _, rel := range []string{"code-review.md", "cr.md", "other.md", "deploy.md", "lint.md", "prompts/code-review.md"} {
+		require.NoError(t, generated.Write(filepath.Join(commandsDir, filepath.FromSlash(rel)), []byte(rel)))
+	}
+	_, err := generated.Prune()
+	require.NoError(t, err)
+
+	geminiDir := filepath.Join(GeminiExtensionDir(project), "commands")
+	geminiGenerated := LoadGeneratedFiles(geminiDir)
+	for _, rel := range []string{"deploy.toml", "prompts/code-review.toml"} {
+		require.NoError(t, geminiGenerated.Write(filepath.Join(geminiDir, filepath.FromSlash(rel)), []byte(rel)))
+	}
+	_, err = geminiGenerated.Prune()
+	require.NoError(t, err)
+
+	report, err := removeGeneratedArtifacts(manager, ArtifactPrompt, "code-review", project)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"code-review"}, report.SlashCommands)
+	assert.Len(t, report.Files, 4)
+	assert.NoFileExists(t, filepath.Join(geminiDir, "prompts", "code-review.toml"))
+	assert.NoFileExists(t, filepath.Join(commandsDir, "code-review.md"))
+	assert.NoFileExists(t, filepath.Join(commandsDir, "cr.md"))
+	assert.NoFileExists(t, filepath.Join(commandsDir, "prompts", "code-review.md"))
+	assert.FileExists(t, filepath.Join(commandsDir, "other.md"))
+	require.Len(t, manager.GetSlashCommands(), 2)
+
+	report, err = removeGeneratedArtifacts(manager, ArtifactWorkflow, "deploy", project)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy"}, report.SlashCommands)
+	assert.NoFileExists(t, filepath.Join(commandsDir, "deploy.md"))
+	assert.NoFileExists(t, filepath.Join(geminiDir, "deploy.toml"))
+
+	report, err = removeGeneratedArtifacts(manager, ArtifactAction, "lint", project)
+	require.NoError(t, err)
+	assert.Empty(t, report.SlashCommands)
+	assert.Equal(t, []string{filepath.Join(commandsDir, "lint.md")}, report.Files)
+
+	// Deleting an item with nothing generated is a no-op
+	report, err = removeGeneratedArtifacts(manager, ArtifactAction, "missing", project)
+	require.NoError(t, err)
+	assert.Empty(t, report.Files)
+
+	_, err = removeGeneratedArtifacts(manager, "widget", "x", project)
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ClaudeSettingsFile is the project's local, uncommitted Claude settings
+// file earlier versions of Opun wrote their hooks into
+const ClaudeSettingsFile = "settings.local.json"
+
+// ClaudeHookEvents maps the Claude hook events Opun handles to the
+// 'opun hook claude' argument that handles them
+var ClaudeHookEvents = map[string]string{
+	"UserPromptSubmit": "user-prompt-submit",
+	"PreToolUse":       "pre-tool-use",
+	"PostToolUse":      "post-tool-use",
+}
+
+// claudeHookTimeout bounds each hook command, in seconds
+const claudeHookTimeout = 30
+
+// claudeHookMarker identifies the hook commands Opun generated
+const claudeHookMarker = " hook claude "
+
+var (
+	claudeHooksMu      sync.RWMutex
+	claudeHooksEnabled bool
+)
+
+// SetClaudeHooks turns Opun's hooks in the Claude sessions it launches on or off
+func SetClaudeHooks(enabled bool) {
+	claudeHooksMu.Lock()
+	defer claudeHooksMu.Unlock()
+	claudeHooksEnabled = enabled
+}
+
+func claudeHooksOn() bool {
+	claudeHooksMu.RLock()
+	defer claudeHooksMu.RUnlock()
+	return claudeHooksEnabled
+}
+
+// hookExecutable returns the command Claude runs Opun's hooks with
+func hookExecutable() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "opun"
+	}
+	return `"` + strings.ReplaceAll(exe, `"`, `\"`) + `"`
+}
+
+// WithClaudeHooks adds Opun's hooks to the arguments a Claude session is
+// launched with. They go in the --settings Claude reads for that launch only,
+// merged into inline settings already in args, so Claude sessions Opun
+// didn't start never run them. Other providers' args are returned as is.
+func WithClaudeHooks(provider string, args []string) []string {
+	if !strings.EqualFold(provider, "claude") || !claudeHooksOn() {
+		return args
+	}
+	return withClaudeHookSettings(args, hookExecutable())
+}
+
+// withClaudeHookSettings merges the hooks running executable into the
+// --settings argument in args, adding one when there is none. A --settings
+// naming a file is left alone, since Claude takes the flag once.
+func withClaudeHookSettings(args []string, executable string) []string {
+	settings := make(map[string]interface{})
+	index := -1
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] != "--settings" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(args[i+1]), &settings); err != nil {
+			return args
+		}
+		index = i + 1
+		break
+	}
+
+	settings["hooks"] = mergeClaudeHooks(settings["hooks"], executable)
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return args
+	}
+
+	merged := append([]string(nil), args...)
+	if index < 0 {
+		return append(merged, "--settings", string(data))
+	}
+	merged[index] = string(data)
+	return merged
+}
+
+// mergeClaudeHooks adds Opun's hook groups to a Claude hooks section
+func mergeClaudeHooks(existing interface{}, executable string) map[string]interface{} {
+	hooks, _ := existing.(map[string]interface{})
+	if hooks == nil {
+		hooks = make(map[string]interface{})
+	}
+	for event, arg := range ClaudeHookEvents {
+		group := map[string]interface{}{
+			"hooks": []interface{}{
+				map[string]interface{}{
+					"type":    "command",
+					"command": executable + claudeHookMarker + arg,
+					"timeout": claudeHookTimeout,
+				},
+			},
+		}
+		if event != "UserPromptSubmit" {
+			group["matcher"] = "*"
+		}
+		groups, _ := hooks[event].([]interface{})
+		hooks[event] = append(groups, group)
+	}
+	return hooks
+}
+
+// removeClaudeHooks removes the hooks earlier versions of Opun wrote into a
+// project's Claude settings file. Settings and hooks that Opun didn't write
+// are kept.
+func removeClaudeHooks(settingsPath string) error {
+	settings := make(map[string]interface{})
+	// #nosec G304 -- settings file in the project's .claude directory
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", settingsPath, err)
+	}
+
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	removed := false
+	for event := range hooks {
+		groups, _ := hooks[event].([]interface{})
+		kept := make([]interface{}, 0, len(groups))
+		for _, group := range groups {
+			if !isOpunHookGroup(group) {
+				kept = append(kept, group)
+			}
+		}
+		removed = removed || len(kept) != len(groups)
+		if len(kept) == 0 {
+			delete(hooks, event)
+		} else {
+			hooks[event] = kept
+		}
+	}
+	if !removed {
+		return nil
+	}
+
+	if len(hooks) == 0 {
+		delete(settings, "hooks")
+	}
+	if len(settings) == 0 {
+		return os.Remove(settingsPath)
+	}
+
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writeIfChanged(settingsPath, append(data, '\n'))
+	return err
+}
+
+// isOpunHookGroup reports whether a hook matcher group runs Opun's hooks
+func isOpunHookGroup(group interface{}) bool {
+	m, _ := group.(map[string]interface{})
+	handlers, _ := m["hooks"].([]interface{})
+	for _, handler := range handlers {
+		h, _ := handler.(map[string]interface{})
+		if command, _ := h["command"].(string); strings.Contains(command, claudeHookMarker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithClaudeHooks(t *testing.T) {
+	args := withClaudeHookSettings([]string{"--settings", `{"env":{"MAX_THINKING_TOKENS":"10000"}}`, "--model", "opus"}, "/usr/bin/opun")
+	require.Len(t, args, 4)
+	assert.Equal(t, []string{"--settings", "--model", "opus"}, []string{args[0], args[2], args[3]})
+
+	var settings map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(args[1]), &settings))
+	assert.Contains(t, settings, "env", "settings already passed are kept")
+	hooks := settings["hooks"].(map[string]interface{})
+	require.Len(t, hooks, len(ClaudeHookEvents))
+
+	pre := hooks["PreToolUse"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "*", pre["matcher"])
+	handler := pre["hooks"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "/usr/bin/opun hook claude pre-tool-use", handler["command"])
+
+	prompt := hooks["UserPromptSubmit"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, prompt, "matcher")
+
+	// Without settings a flag is added; a settings file is left alone
+	args = withClaudeHookSettings([]string{"--model", "opus"}, "opun")
+	assert.Equal(t, "--settings", args[2])
+	assert.Equal(t, []string{"--settings", "team.json"}, withClaudeHookSettings([]string{"--settings", "team.json"}, "opun"))
+
+	// Only Claude gets hooks, and only when they are on
+	SetClaudeHooks(true)
+	defer SetClaudeHooks(false)
+	assert.Equal(t, []string{"-m", "pro"}, WithClaudeHooks("gemini", []string{"-m", "pro"}))
+	assert.Len(t, WithClaudeHooks("claude", nil), 2)
+	SetClaudeHooks(false)
+	assert.Empty(t, WithClaudeHooks("claude", nil))
+}
+
+func TestRemoveClaudeHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ClaudeSettingsFile)
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "permissions": {"allow": ["Bash(ls:*)"]},
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "my-linter"}]},
+      {"matcher": "*", "hooks": [{"type": "command", "command": "/usr/bin/opun hook claude pre-tool-use"}]}
+    ],
+    "UserPromptSubmit": [{"hooks": [{"type": "command", "command": "/usr/bin/opun hook claude user-prompt-submit"}]}]
+  }
+}`), 0644))
+
+	// Only Opun's hooks are removed
+	require.NoError(t, removeClaudeHooks(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var settings map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &settings))
+	assert.Contains(t, settings, "permissions")
+	hooks := settings["hooks"].(map[string]interface{})
+	assert.Len(t, hooks, 1)
+	assert.Len(t, hooks["PreToolUse"], 1)
+
+	// A file holding only Opun's hooks is removed
+	only := filepath.Join(t.TempDir(), ClaudeSettingsFile)
+	require.NoError(t, os.WriteFile(only, []byte(`{"hooks": {"PostToolUse": [{"matcher": "*", "hooks": [{"type": "command", "command": "opun hook claude post-tool-use"}]}]}}`), 0644))
+	require.NoError(t, removeClaudeHooks(only))
+	assert.NoFileExists(t, only)
+
+	require.NoError(t, removeClaudeHooks(filepath.Join(t.TempDir(), ClaudeSettingsFile)))
+}
@@ -0,0 +1,124 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+// Opun sessions running side by side in different projects still share the
+// provider configs in the home directory, and sessions in the same project
+// share its generated files. Writers take a lock on what they write so their
+// read-modify-write cycles don't interleave.
+var (
+	// lockTimeout bounds how long a session waits for another to finish writing
+	lockTimeout = 10 * time.Second
+	// lockPollInterval is how often a waiting session checks the lock again
+	lockPollInterval = 25 * time.Millisecond
+)
+
+// fileLockHolder is written into a file lock so a lock left by a crashed
+// session can be told from a live one
+type fileLockHolder struct {
+	PID        int       `json:"pid"`
+	Path       string    `json:"path"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// lockFile takes an exclusive lock on path, waiting while another session
+// holds it. The returned function releases the lock. Lock files live in
+// ~/.opun/locks/files rather than next to path, so a crashed session never
+// leaves one behind in a project or a provider's config directory; its lock
+// is taken over once its process is gone.
+func lockFile(path string) (func(), error) {
+	lockPath, err := lockFilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(fileLockHolder{PID: os.Getpid(), Path: path, AcquiredAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lock, held, err := utils.TryLockFile(lockPath, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if lock != nil {
+			return lock.Release, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s, held by %s", path, lockOwner(held))
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// lockFilePath returns the lock file for path
+func lockFilePath(path string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".opun", "locks", "files", pathKey(path)+".lock"), nil
+}
+
+// pathKey names a file or directory after its base name and a hash of its
+// absolute path, so equally named ones in different places don't collide
+func pathKey(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := filepath.Base(abs)
+	if name == string(filepath.Separator) || name == "." {
+		name = "root"
+	}
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// lockOwner describes the session holding a file lock
+func lockOwner(data []byte) string {
+	var holder fileLockHolder
+	if err := json.Unmarshal(data, &holder); err != nil || holder.PID == 0 {
+		return "another Opun session"
+	}
+	return fmt.Sprintf("pid %d", holder.PID)
+}
+
+// ProjectWorkspace returns the directory under ~/.opun/workspace that holds
+// a project's provider workspace files and the manifest of what Opun
+// generated in the project root. Projects get their own, so sessions in
+// different projects never write the same workspace files.
+func ProjectWorkspace(projectDir string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".opun", "workspace", "projects", pathKey(projectDir)), nil
+}
@@ -0,0 +1,131 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldTimeout := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	defer func() { lockTimeout = oldTimeout }()
+
+	path := filepath.Join(t.TempDir(), "settings.json")
+	unlock, err := lockFile(path)
+	require.NoError(t, err)
+
+	// The lock file is kept out of the locked file's directory
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = lockFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out waiting")
+
+	// Other files aren't affected
+	unlockOther, err := lockFile(filepath.Join(t.TempDir(), "settings.json"))
+	require.NoError(t, err)
+	unlockOther()
+
+	unlock()
+	unlock, err = lockFile(path)
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestLockFileStale(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "project")
+	lockPath, err := lockFilePath(path)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(lockPath), 0755))
+
+	// A fresh lock of a live session is kept however old it looks
+	oldTimeout := lockTimeout
+	lockTimeout = 50 * time.Millisecond
+	defer func() { lockTimeout = oldTimeout }()
+	require.NoError(t, os.WriteFile(lockPath, []byte(fmt.Sprintf(`{"pid": %d}`, os.Getpid())), 0644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+	_, err = lockFile(path)
+	assert.ErrorContains(t, err, fmt.Sprintf("held by pid %d", os.Getpid()))
+
+	// A lock whose process is gone is taken over
+	require.NoError(t, os.WriteFile(lockPath, []byte(`{"pid": 999999}`), 0644))
+	unlock, err := lockFile(path)
+	require.NoError(t, err)
+	unlock()
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestLockFileSerializes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "counter")
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	inside, maxInside := 0, 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := lockFile(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			inside++
+			if inside > maxInside {
+				maxInside = inside
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			inside--
+			mu.Unlock()
+			unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxInside)
+}
+
+func TestProjectWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	a, err := ProjectWorkspace(filepath.Join(t.TempDir(), "app"))
+	require.NoError(t, err)
+	b, err := ProjectWorkspace(filepath.Join(t.TempDir(), "app"))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, filepath.Join(home, ".opun", "workspace", "projects"), filepath.Dir(a))
+	assert.Contains(t, filepath.Base(a), "app-")
+}
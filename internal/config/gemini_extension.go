@@ -0,0 +1,121 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/export"
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+// GeminiExtensionName is the name of the extension Opun generates for Gemini CLI
+const GeminiExtensionName = "opun"
+
+// GeminiExtensionManifest is the manifest file of a Gemini CLI extension
+const GeminiExtensionManifest = "gemini-extension.json"
+
+// GeminiExtension is a Gemini CLI extension manifest
+type GeminiExtension struct {
+	Name       string                     `json:"name"`
+	Version    string                     `json:"version"`
+	MCPServers map[string]GeminiMCPServer `json:"mcpServers,omitempty"`
+	// ContextFileName is loaded into Gemini's context while the extension is active
+	ContextFileName string `json:"contextFileName,omitempty"`
+}
+
+// GeminiExtensionDir returns the directory of Opun's Gemini extension in a
+// project, .gemini/extensions/opun, where Gemini loads workspace extensions from
+func GeminiExtensionDir(projectDir string) string {
+	return filepath.Join(projectDir, ".gemini", "extensions", GeminiExtensionName)
+}
+
+// generateGeminiExtension writes Opun's Gemini extension: the manifest with
+// the shared MCP servers, GEMINI.md as its context file and a TOML command
+// for every slash command, prompt and action. Commands for items that no
+// longer exist are pruned like Claude's.
+func (m *InjectionManager) generateGeminiExtension(extDir string) error {
+	commandsDir := filepath.Join(extDir, "commands")
+	if err := utils.EnsureDir(commandsDir); err != nil {
+		return err
+	}
+	generated := LoadGeneratedFiles(commandsDir)
+
+	for _, cmd := range m.sharedManager.GetSlashCommands() {
+		if cmd.Hidden {
+			continue
+		}
+		content := geminiCommandTOML(cmd.Description, m.generateCommandMarkdown(cmd))
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if err := generated.Write(filepath.Join(commandsDir, name+".toml"), content); err != nil {
+				return fmt.Errorf("failed to write command %s: %w", name, err)
+			}
+		}
+	}
+
+	generator, err := NewPromptCommandGenerator()
+	if err != nil {
+		return fmt.Errorf("failed to create prompt generator: %w", err)
+	}
+	if err := generator.GenerateGeminiPromptFiles(commandsDir, generated); err != nil {
+		return err
+	}
+
+	if m.actionRegistry != nil {
+		for _, action := range m.actionRegistry.List("gemini") {
+			content := geminiCommandTOML(action.Description, m.generateActionMarkdown(action))
+			if err := generated.Write(filepath.Join(commandsDir, action.ID+".toml"), content); err != nil {
+				return fmt.Errorf("failed to write action %s: %w", action.ID, err)
+			}
+		}
+	}
+
+	if _, err := generated.Prune(); err != nil {
+		return err
+	}
+	warnModified(generated)
+
+	if err := m.generateGeminiSystemPrompt(filepath.Join(extDir, "GEMINI.md")); err != nil {
+		return err
+	}
+
+	servers, err := NewGeminiConfigTranslator().TranslateMCPConfig(m.sharedManager.GetMCPServers())
+	if err != nil {
+		return err
+	}
+	manifest := GeminiExtension{
+		Name:            GeminiExtensionName,
+		Version:         "1.0.0",
+		MCPServers:      servers.(GeminiConfig).MCPServers,
+		ContextFileName: "GEMINI.md",
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writeIfChanged(filepath.Join(extDir, GeminiExtensionManifest), data)
+	return err
+}
+
+// geminiCommandTOML renders a Gemini custom command from Claude-style
+// command markdown, translating $ARGUMENTS to Gemini's {{args}}
+func geminiCommandTOML(description, prompt string) []byte {
+	return export.GeminiCommand(description, strings.ReplaceAll(prompt, "$ARGUMENTS", "{{args}}"))
+}
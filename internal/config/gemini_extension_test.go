@@ -0,0 +1,88 @@
+package config
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGeminiExtension(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	garden, err := promptgarden.NewGarden(filepath.Join(home, ".opun", "promptgarden"))
+	require.NoError(t, err)
+	require.NoError(t, garden.SavePrompt(&promptgarden.Prompt{
+		ID:       "code-review",
+		Name:     "code-review",
+		Content:  "Review \"$ARGUMENTS\"\nthoroughly",
+		Metadata: promptgarden.PromptMetadata{Description: "Review code"},
+	}))
+
+	manager := &SharedConfigManager{
+		configPath: filepath.Join(home, "shared-config.yaml"),
+		config: &core.SharedConfig{
+			MCPServers: []core.SharedMCPServer{
+				{Name: "opun", Command: "opun", Args: []string{"mcp", "stdio"}, Installed: true},
+				{Name: "missing", Command: "npx", Args: []string{"missing"}},
+			},
+			SlashCommands: []core.SharedSlashCommand{
+				{Name: "deploy", Description: "Deploy the app", Type: "workflow", Handler: "deploy", Aliases: []string{"dp"}},
+				{Name: "secret", Type: "builtin", Handler: "secret", Hidden: true},
+			},
+		},
+	}
+	m := &InjectionManager{sharedManager: manager}
+
+	extDir := GeminiExtensionDir(t.TempDir())
+	require.NoError(t, m.generateGeminiExtension(extDir))
+
+	data, err := os.ReadFile(filepath.Join(extDir, GeminiExtensionManifest))
+	require.NoError(t, err)
+	var manifest GeminiExtension
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, GeminiExtensionName, manifest.Name)
+	assert.Equal(t, "GEMINI.md", manifest.ContextFileName)
+	assert.Contains(t, manifest.MCPServers, "opun")
+	assert.NotContains(t, manifest.MCPServers, "missing")
+	assert.FileExists(t, filepath.Join(extDir, "GEMINI.md"))
+
+	deploy, err := os.ReadFile(filepath.Join(extDir, "commands", "deploy.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(deploy), `description = "Deploy the app"`)
+	assert.Contains(t, string(deploy), "- Arguments: {{args}}\n")
+	assert.FileExists(t, filepath.Join(extDir, "commands", "dp.toml"))
+	assert.NoFileExists(t, filepath.Join(extDir, "commands", "secret.toml"))
+
+	review, err := os.ReadFile(filepath.Join(extDir, "commands", "prompts", "code-review.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, "description = \"Review code\"\nprompt = \"\"\"\nReview \"{{args}}\"\nthoroughly\"\"\"\n", string(review))
+
+	// Commands of removed items are pruned on the next generation
+	manager.config.SlashCommands = nil
+	require.NoError(t, m.generateGeminiExtension(extDir))
+	assert.NoFileExists(t, filepath.Join(extDir, "commands", "deploy.toml"))
+	assert.FileExists(t, filepath.Join(extDir, "commands", "prompts", "code-review.toml"))
+}
@@ -17,6 +17,7 @@ package config
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -31,7 +32,6 @@ import (
 // InjectionManager handles dynamic configuration injection for providers
 type InjectionManager struct {
 	sharedManager  *SharedConfigManager
-	workspaceDir   string // Temporary workspace for provider configs
 	actionRegistry core.ActionRegistry
 }
 
@@ -42,20 +42,8 @@ func NewInjectionManager(actionRegistry core.ActionRegistry) (*InjectionManager,
 		return nil, fmt.Errorf("failed to create shared config manager: %w", err)
 	}
 
-	// Create workspace directory for temporary configs
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
-
-	workspaceDir := filepath.Join(homeDir, ".opun", "workspace")
-	if err := utils.EnsureDir(workspaceDir); err != nil {
-		return nil, fmt.Errorf("failed to create workspace: %w", err)
-	}
-
 	return &InjectionManager{
 		sharedManager:  sharedManager,
-		workspaceDir:   workspaceDir,
 		actionRegistry: actionRegistry,
 	}, nil
 }
@@ -80,6 +68,13 @@ func (m *InjectionManager) PrepareProviderEnvironment(provider string) (*Provide
 		WorkingDir:  currentDir, // Use actual working directory, not workspace
 	}
 
+	// Sessions starting in the same project take turns generating its files
+	unlock, err := lockFile(currentDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	switch strings.ToLower(provider) {
 	case "claude":
 		if err := m.prepareClaudeEnvironment(env); err != nil {
@@ -115,26 +110,50 @@ func (m *InjectionManager) prepareClaudeEnvironment(env *ProviderEnvironment) er
 		return err
 	}
 
+	// Track generated files so commands for deleted items can be pruned
+	generated := LoadGeneratedFiles(commandsDir)
+
 	// Generate slash commands from shared config
-	if err := m.generateClaudeSlashCommands(commandsDir); err != nil {
+	if err := m.generateClaudeSlashCommands(commandsDir, generated); err != nil {
 		return err
 	}
 
 	// Generate prompt commands
-	if err := m.generateClaudePromptCommands(commandsDir); err != nil {
+	if err := m.generateClaudePromptCommands(commandsDir, generated); err != nil {
 		return err
 	}
 
 	// Generate action commands from action registry
-	if err := m.generateClaudeActionCommands(commandsDir); err != nil {
+	if err := m.generateClaudeActionCommands(commandsDir, generated); err != nil {
 		return err
 	}
 
-	// Create project-level MCP configuration in current directory
-	mcpConfigPath := filepath.Join(currentDir, ".mcp.json")
-	if err := m.generateProjectMCPConfig(mcpConfigPath); err != nil {
+	// Remove commands generated for workflows, prompts and actions that no longer exist
+	if _, err := generated.Prune(); err != nil {
 		return err
 	}
+	warnModified(generated)
+
+	// Opun's hooks are passed per launch (see WithClaudeHooks); drop any an
+	// earlier version left in the project's settings
+	if err := removeClaudeHooks(filepath.Join(claudeDir, ClaudeSettingsFile)); err != nil {
+		return err
+	}
+
+	// Create project-level MCP configuration in current directory, tracked
+	// in the project workspace so edits made since are left alone
+	workspace, err := ProjectWorkspace(currentDir)
+	if err != nil {
+		return err
+	}
+	projectFiles := loadGeneratedFiles(currentDir, filepath.Join(workspace, GeneratedManifestFile))
+	if err := m.generateProjectMCPConfig(filepath.Join(currentDir, ".mcp.json"), projectFiles); err != nil {
+		return err
+	}
+	if _, err := projectFiles.Prune(); err != nil {
+		return err
+	}
+	warnModified(projectFiles)
 
 	// Set environment to use current directory as project
 	env.Environment["CLAUDE_PROJECT_DIR"] = currentDir
@@ -144,17 +163,16 @@ func (m *InjectionManager) prepareClaudeEnvironment(env *ProviderEnvironment) er
 
 // prepareGeminiEnvironment prepares Gemini-specific environment
 func (m *InjectionManager) prepareGeminiEnvironment(env *ProviderEnvironment) error {
-	// Since Gemini doesn't support custom slash commands,
-	// we rely entirely on MCP servers for extensions
-
-	// Create GEMINI.md for system prompt customization in workspace
-	geminiMdPath := filepath.Join(m.workspaceDir, "GEMINI.md")
-	if err := m.generateGeminiSystemPrompt(geminiMdPath); err != nil {
-		return err
+	// Gemini loads commands, context and MCP servers from extensions, so
+	// Opun's workflows, prompts and actions are installed as one
+	extDir := GeminiExtensionDir(env.WorkingDir)
+	if err := m.generateGeminiExtension(extDir); err != nil {
+		return fmt.Errorf("failed to generate Gemini extension: %w", err)
 	}
+	env.ConfigFiles = append(env.ConfigFiles, filepath.Join(extDir, GeminiExtensionManifest))
 
-	// Ensure MCP servers include our slash command server
-	// This is already handled by SyncToProvider
+	// MCP servers are also synced into settings.json by SyncToProvider,
+	// whose entries take precedence over the extension's
 
 	return nil
 }
@@ -164,8 +182,12 @@ func (m *InjectionManager) prepareQwenEnvironment(env *ProviderEnvironment) erro
 	// Since Qwen is a fork of Gemini, it has similar limitations
 	// We rely entirely on MCP servers for extensions
 
-	// Create QWEN.md for system prompt customization in workspace
-	qwenMdPath := filepath.Join(m.workspaceDir, "QWEN.md")
+	// Create QWEN.md for system prompt customization in the project's workspace
+	workspace, err := ProjectWorkspace(env.WorkingDir)
+	if err != nil {
+		return err
+	}
+	qwenMdPath := filepath.Join(workspace, "QWEN.md")
 	if err := m.generateQwenSystemPrompt(qwenMdPath); err != nil {
 		return err
 	}
@@ -177,7 +199,7 @@ func (m *InjectionManager) prepareQwenEnvironment(env *ProviderEnvironment) erro
 }
 
 // generateClaudeSlashCommands generates markdown files for Claude slash commands
-func (m *InjectionManager) generateClaudeSlashCommands(commandsDir string) error {
+func (m *InjectionManager) generateClaudeSlashCommands(commandsDir string, generated *GeneratedFiles) error {
 	commands := m.sharedManager.GetSlashCommands()
 
 	for _, cmd := range commands {
@@ -191,14 +213,14 @@ func (m *InjectionManager) generateClaudeSlashCommands(commandsDir string) error
 		cmdFilePath := filepath.Join(commandsDir, filename)
 
 		content := m.generateCommandMarkdown(cmd)
-		if err := utils.WriteFile(cmdFilePath, []byte(content)); err != nil {
+		if err := generated.Write(cmdFilePath, []byte(content)); err != nil {
 			return fmt.Errorf("failed to write command %s: %w", cmd.Name, err)
 		}
 
 		// Also create files for aliases
 		for _, alias := range cmd.Aliases {
 			aliasFile := filepath.Join(commandsDir, fmt.Sprintf("%s.md", alias))
-			if err := utils.WriteFile(aliasFile, []byte(content)); err != nil {
+			if err := generated.Write(aliasFile, []byte(content)); err != nil {
 				return fmt.Errorf("failed to write alias %s: %w", alias, err)
 			}
 		}
@@ -208,18 +230,18 @@ func (m *InjectionManager) generateClaudeSlashCommands(commandsDir string) error
 }
 
 // generateClaudePromptCommands generates commands for prompts
-func (m *InjectionManager) generateClaudePromptCommands(commandsDir string) error {
+func (m *InjectionManager) generateClaudePromptCommands(commandsDir string, generated *GeneratedFiles) error {
 	// Use the prompt command generator to create proper prompt files
 	generator, err := NewPromptCommandGenerator()
 	if err != nil {
 		return fmt.Errorf("failed to create prompt generator: %w", err)
 	}
 
-	return generator.GenerateClaudePromptFiles(commandsDir)
+	return generator.GenerateClaudePromptFiles(commandsDir, generated)
 }
 
 // generateClaudeActionCommands generates commands from the action registry
-func (m *InjectionManager) generateClaudeActionCommands(commandsDir string) error {
+func (m *InjectionManager) generateClaudeActionCommands(commandsDir string, generated *GeneratedFiles) error {
 	if m.actionRegistry == nil {
 		// No action registry, skip
 		return nil
@@ -237,7 +259,7 @@ func (m *InjectionManager) generateClaudeActionCommands(commandsDir string) erro
 		cmdFilePath := filepath.Join(commandsDir, filename)
 
 		content := m.generateActionMarkdown(action)
-		if err := utils.WriteFile(cmdFilePath, []byte(content)); err != nil {
+		if err := generated.Write(cmdFilePath, []byte(content)); err != nil {
 			return fmt.Errorf("failed to write action %s: %w", action.ID, err)
 		}
 	}
@@ -284,7 +306,13 @@ func (m *InjectionManager) generateActionMarkdown(action core.StandardAction) st
 	sb.WriteString(fmt.Sprintf("# %s\n\n", action.Name))
 	sb.WriteString(fmt.Sprintf("%s\n\n", action.Description))
 
-	if action.Command != "" {
+	if len(action.Steps) > 0 {
+		sb.WriteString("## Steps\n\n")
+		sb.WriteString("```bash\n")
+		sb.WriteString(fmt.Sprintf("opun action run %s $ARGUMENTS\n", action.ID))
+		sb.WriteString("```\n\n")
+		sb.WriteString("Run the steps through Opun so a failing step rolls back the ones before it.\n")
+	} else if action.Command != "" {
 		sb.WriteString("## Command\n\n")
 		sb.WriteString("```bash\n")
 		sb.WriteString(fmt.Sprintf("%s $ARGUMENTS\n", action.Command))
@@ -308,7 +336,7 @@ func (m *InjectionManager) generateActionMarkdown(action core.StandardAction) st
 }
 
 // generateProjectMCPConfig generates project-level MCP configuration
-func (m *InjectionManager) generateProjectMCPConfig(configPath string) error {
+func (m *InjectionManager) generateProjectMCPConfig(configPath string, generated *GeneratedFiles) error {
 	// Get MCP servers from shared config
 	servers := m.sharedManager.GetMCPServers()
 
@@ -342,7 +370,15 @@ func (m *InjectionManager) generateProjectMCPConfig(configPath string) error {
 		return err
 	}
 
-	return utils.WriteFile(configPath, data)
+	return generated.Write(configPath, data)
+}
+
+// warnModified reports the generated files left alone because they were
+// edited since Opun wrote them
+func warnModified(generated *GeneratedFiles) {
+	for _, path := range generated.Modified() {
+		fmt.Printf("Warning: %s was changed since Opun generated it; leaving it as is (delete it to regenerate)\n", path)
+	}
 }
 
 // generateGeminiSystemPrompt generates GEMINI.md for system customization
@@ -351,9 +387,9 @@ func (m *InjectionManager) generateGeminiSystemPrompt(mdPath string) error {
 
 This file provides system-level guidance for Gemini CLI when working in this Opun session.
 
-## Available Commands via MCP
+## Available Commands
 
-Since Gemini doesn't support custom slash commands natively, use the MCP tools to access Opun functionality:
+The opun extension adds Opun's workflows, actions and prompts as slash commands: workflows and actions by name (e.g. /review) and prompts as /prompts:<name>. They run through the opun MCP server, whose tools can also be called directly:
 
 ### Workflows
 {{range .Commands}}{{if eq .Type "workflow"}}
@@ -400,13 +436,13 @@ This is a managed Opun session with the following MCP servers available:
 		Servers:  m.sharedManager.GetMCPServers(),
 	}
 
-	file, err := os.Create(mdPath)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return t.Execute(file, data)
+	_, err = writeIfChanged(mdPath, buf.Bytes())
+	return err
 }
 
 // generateQwenSystemPrompt generates QWEN.md for system customization
@@ -464,13 +500,13 @@ This is a managed Opun session with the following MCP servers available:
 		Servers:  m.sharedManager.GetMCPServers(),
 	}
 
-	file, err := os.Create(mdPath)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return t.Execute(file, data)
+	_, err = writeIfChanged(mdPath, buf.Bytes())
+	return err
 }
 
 // ProviderEnvironment contains the prepared environment for a provider
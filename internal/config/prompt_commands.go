@@ -65,6 +65,17 @@ func (g *PromptCommandGenerator) GeneratePromptCommands() error {
 		return fmt.Errorf("failed to list prompts: %w", err)
 	}
 
+	// Drop the commands of prompts that have been deleted from the garden
+	existing := make(map[string]bool, len(prompts))
+	for _, prompt := range prompts {
+		existing["promptgarden://"+prompt.Name()] = true
+	}
+	if _, err := g.sharedManager.RemoveSlashCommands(func(cmd core.SharedSlashCommand) bool {
+		return cmd.Type == "prompt" && strings.HasPrefix(cmd.Handler, "promptgarden://") && !existing[cmd.Handler]
+	}); err != nil {
+		fmt.Printf("Warning: failed to remove stale prompt commands: %v\n", err)
+	}
+
 	// Create slash commands for each prompt
 	for _, prompt := range prompts {
 		// Skip system prompts that shouldn't be exposed
@@ -79,7 +90,8 @@ func (g *PromptCommandGenerator) GeneratePromptCommands() error {
 		}
 	}
 
-	return g.sharedManager.Save()
+	// AddSlashCommand and RemoveSlashCommands save whenever something changed
+	return nil
 }
 
 // createPromptCommand creates a slash command from a prompt
@@ -107,7 +119,7 @@ func (g *PromptCommandGenerator) createPromptCommand(prompt core.Prompt) core.Sh
 }
 
 // GenerateClaudePromptFiles generates .claude/commands files for prompts
-func (g *PromptCommandGenerator) GenerateClaudePromptFiles(commandsDir string) error {
+func (g *PromptCommandGenerator) GenerateClaudePromptFiles(commandsDir string, generated *GeneratedFiles) error {
 	// Create prompts subdirectory with proper ownership
 	promptsDir := filepath.Join(commandsDir, "prompts")
 	if err := utils.EnsureDir(promptsDir); err != nil {
@@ -126,7 +138,7 @@ func (g *PromptCommandGenerator) GenerateClaudePromptFiles(commandsDir string) e
 		}
 
 		// Generate command file
-		if err := g.generatePromptFile(promptsDir, prompt); err != nil {
+		if err := g.generatePromptFile(promptsDir, prompt, generated); err != nil {
 			fmt.Printf("Warning: failed to generate prompt file for %s: %v\n", prompt.Name(), err)
 		}
 	}
@@ -143,11 +155,38 @@ The prompt will be loaded from the PromptGarden and executed with any provided a
 Available prompts can be found in the other files in this directory.`
 
 	runnerFile := filepath.Join(promptsDir, "run.md")
-	return utils.WriteFile(runnerFile, []byte(runnerContent))
+	return generated.Write(runnerFile, []byte(runnerContent))
+}
+
+// GenerateGeminiPromptFiles generates a commands/prompts TOML command for
+// each prompt of the Gemini extension, run as /prompts:<name>
+func (g *PromptCommandGenerator) GenerateGeminiPromptFiles(commandsDir string, generated *GeneratedFiles) error {
+	promptsDir := filepath.Join(commandsDir, "prompts")
+	if err := utils.EnsureDir(promptsDir); err != nil {
+		return err
+	}
+
+	prompts, err := g.garden.List()
+	if err != nil {
+		return fmt.Errorf("failed to list prompts: %w", err)
+	}
+
+	for _, prompt := range prompts {
+		if strings.HasPrefix(prompt.Name(), "_") || strings.HasPrefix(prompt.Name(), ".") {
+			continue
+		}
+
+		metadata := prompt.Metadata()
+		path := filepath.Join(promptsDir, g.sanitizeCommandName(metadata.Name)+".toml")
+		if err := generated.Write(path, geminiCommandTOML(metadata.Description, prompt.Content())); err != nil {
+			fmt.Printf("Warning: failed to generate prompt command for %s: %v\n", prompt.Name(), err)
+		}
+	}
+	return nil
 }
 
 // generatePromptFile creates a command file for a specific prompt
-func (g *PromptCommandGenerator) generatePromptFile(promptsDir string, prompt core.Prompt) error {
+func (g *PromptCommandGenerator) generatePromptFile(promptsDir string, prompt core.Prompt, generated *GeneratedFiles) error {
 	metadata := prompt.Metadata()
 	cmdName := g.sanitizeCommandName(metadata.Name)
 	filename := fmt.Sprintf("%s.md", cmdName)
@@ -177,7 +216,7 @@ func (g *PromptCommandGenerator) generatePromptFile(promptsDir string, prompt co
 	sb.WriteString("This command will execute the above prompt template. ")
 	sb.WriteString("Any occurrences of `$ARGUMENTS` in the template will be replaced with your input.\n")
 
-	return utils.WriteFile(cmdFilePath, []byte(sb.String()))
+	return generated.Write(cmdFilePath, []byte(sb.String()))
 }
 
 // sanitizeCommandName converts a prompt name to a valid command name
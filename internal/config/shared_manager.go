@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"time"
@@ -35,6 +36,7 @@ import (
 type SharedConfigManager struct {
 	configPath string
 	config     *core.SharedConfig
+	loaded     string // content hash of the file as last loaded or saved
 }
 
 // NewSharedConfigManager creates a new shared configuration manager
@@ -78,11 +80,46 @@ func (m *SharedConfigManager) Load() error {
 	}
 
 	m.config = &config
+	m.loaded = contentHash(data)
 	return nil
 }
 
 // Save saves the shared configuration to disk
 func (m *SharedConfigManager) Save() error {
+	unlock, err := lockFile(m.configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return m.save()
+}
+
+// update applies change to the configuration and saves it when change
+// reports a modification. Sessions in other projects may have saved the
+// file since it was loaded, so it is reloaded first under the lock and
+// their changes are kept.
+func (m *SharedConfigManager) update(change func(config *core.SharedConfig) bool) error {
+	unlock, err := lockFile(m.configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// #nosec G304 -- configPath is the shared configuration file
+	if data, err := os.ReadFile(m.configPath); err == nil && contentHash(data) != m.loaded {
+		if err := m.Load(); err != nil {
+			return err
+		}
+	}
+
+	if !change(m.config) {
+		return nil
+	}
+	return m.save()
+}
+
+// save writes the configuration; the caller holds the lock
+func (m *SharedConfigManager) save() error {
 	// Update timestamp
 	m.config.LastUpdated = time.Now()
 
@@ -95,6 +132,7 @@ func (m *SharedConfigManager) Save() error {
 	if err := utils.WriteFile(m.configPath, data); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
+	m.loaded = contentHash(data)
 
 	return nil
 }
@@ -159,14 +197,25 @@ func (m *SharedConfigManager) CheckMCPServerInstalled(serverName string) (bool,
 
 // UpdateMCPServerStatus updates the installation status of an MCP server
 func (m *SharedConfigManager) UpdateMCPServerStatus(serverName string, installed bool, version string) error {
-	for i, server := range m.config.MCPServers {
-		if server.Name == serverName {
-			m.config.MCPServers[i].Installed = installed
-			m.config.MCPServers[i].Version = version
-			return m.Save()
+	found := false
+	err := m.update(func(config *core.SharedConfig) bool {
+		for i, server := range config.MCPServers {
+			if server.Name == serverName {
+				config.MCPServers[i].Installed = installed
+				config.MCPServers[i].Version = version
+				found = true
+				return true
+			}
 		}
+		return false
+	})
+	if err != nil {
+		return err
 	}
-	return fmt.Errorf("server %s not found", serverName)
+	if !found {
+		return fmt.Errorf("server %s not found", serverName)
+	}
+	return nil
 }
 
 // GetMCPServers returns all configured MCP servers
@@ -181,30 +230,65 @@ func (m *SharedConfigManager) GetSlashCommands() []core.SharedSlashCommand {
 
 // AddMCPServer adds a new MCP server to the configuration
 func (m *SharedConfigManager) AddMCPServer(server core.SharedMCPServer) error {
-	// Check if already exists
-	for i, existing := range m.config.MCPServers {
-		if existing.Name == server.Name {
-			m.config.MCPServers[i] = server
-			return m.Save()
+	return m.update(func(config *core.SharedConfig) bool {
+		// Check if already exists
+		for i, existing := range config.MCPServers {
+			if existing.Name == server.Name {
+				config.MCPServers[i] = server
+				return true
+			}
 		}
-	}
 
-	m.config.MCPServers = append(m.config.MCPServers, server)
-	return m.Save()
+		config.MCPServers = append(config.MCPServers, server)
+		return true
+	})
 }
 
 // AddSlashCommand adds a new slash command to the configuration
 func (m *SharedConfigManager) AddSlashCommand(command core.SharedSlashCommand) error {
-	// Check if already exists
-	for i, existing := range m.config.SlashCommands {
-		if existing.Name == command.Name {
-			m.config.SlashCommands[i] = command
-			return m.Save()
+	return m.update(func(config *core.SharedConfig) bool {
+		// Check if already exists
+		for i, existing := range config.SlashCommands {
+			if existing.Name == command.Name {
+				if reflect.DeepEqual(existing, command) {
+					return false
+				}
+				config.SlashCommands[i] = command
+				return true
+			}
 		}
-	}
 
-	m.config.SlashCommands = append(m.config.SlashCommands, command)
-	return m.Save()
+		config.SlashCommands = append(config.SlashCommands, command)
+		return true
+	})
+}
+
+// RemoveSlashCommands removes the slash commands matching match and saves the
+// configuration when anything was removed
+func (m *SharedConfigManager) RemoveSlashCommands(match func(core.SharedSlashCommand) bool) ([]core.SharedSlashCommand, error) {
+	var removed []core.SharedSlashCommand
+	err := m.update(func(config *core.SharedConfig) bool {
+		var kept []core.SharedSlashCommand
+		removed = nil
+		for _, command := range config.SlashCommands {
+			if match(command) {
+				removed = append(removed, command)
+			} else {
+				kept = append(kept, command)
+			}
+		}
+
+		if len(removed) == 0 {
+			return false
+		}
+
+		config.SlashCommands = kept
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
 }
 
 // SyncToProvider syncs the shared configuration to a specific provider
@@ -250,6 +334,13 @@ func (m *SharedConfigManager) writeProviderConfig(translator core.ProviderConfig
 		configPath = filepath.Join(homeDir, configPath[2:])
 	}
 
+	// Other sessions merge into the same file
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Read existing config if it exists
 	var existingConfig map[string]interface{}
 	if existingData, err := os.ReadFile(configPath); err == nil {
@@ -285,8 +376,8 @@ func (m *SharedConfigManager) writeProviderConfig(translator core.ProviderConfig
 		return fmt.Errorf("failed to marshal merged config: %w", err)
 	}
 
-	// Use utils.WriteFile which handles directory creation and permissions
-	if err := utils.WriteFile(configPath, data); err != nil {
+	// Leave the file alone when nothing changed
+	if _, err := writeIfChanged(configPath, data); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
@@ -125,6 +125,29 @@ func TestSharedConfigManager_Save(t *testing.T) {
 	assert.True(t, found)
 }
 
+func TestSharedConfigManager_ConcurrentSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Two sessions load the configuration before either changes it
+	first, err := NewSharedConfigManager()
+	require.NoError(t, err)
+	second, err := NewSharedConfigManager()
+	require.NoError(t, err)
+
+	require.NoError(t, first.AddSlashCommand(core.SharedSlashCommand{Name: "review", Type: "workflow", Handler: "review"}))
+	require.NoError(t, second.AddSlashCommand(core.SharedSlashCommand{Name: "deploy", Type: "workflow", Handler: "deploy"}))
+
+	// The second save kept the first session's command
+	reloaded, err := NewSharedConfigManager()
+	require.NoError(t, err)
+	var names []string
+	for _, cmd := range reloaded.GetSlashCommands() {
+		names = append(names, cmd.Name)
+	}
+	assert.Contains(t, names, "review")
+	assert.Contains(t, names, "deploy")
+}
+
 func TestSharedConfigManager_UpdateMCPServerStatus(t *testing.T) {
 	tempDir := t.TempDir()
 	oldHome := os.Getenv("HOME")
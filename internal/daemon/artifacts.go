@@ -0,0 +1,115 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+)
+
+// Artifact is a file a run wrote to its output directory
+type Artifact struct {
+	// Path is relative to the output directory, with forward slashes
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// RunOutputDir returns where a run wrote its artifacts. id is a daemon run
+// ID, or an Opun run ID looked up in the run history.
+func (s *Service) RunOutputDir(id string) (string, error) {
+	dir := ""
+	if s.runs != nil {
+		if run, ok := s.runs.Get(id); ok {
+			dir = run.OutputDir
+		} else {
+			for _, run := range s.runs.List() {
+				if run.RunID == id {
+					dir = run.OutputDir
+				}
+			}
+		}
+	}
+	if dir == "" {
+		historyDir, err := workflow.HistoryDir()
+		if err != nil {
+			return "", err
+		}
+		record, err := workflow.LoadRunRecord(historyDir, id)
+		if err != nil {
+			return "", fmt.Errorf("unknown run: %s", id)
+		}
+		dir = record.OutputDir
+	}
+	if dir == "" {
+		return "", fmt.Errorf("run %s has no output directory", id)
+	}
+	return filepath.Abs(dir)
+}
+
+// ListArtifacts lists the files in a run's output directory
+func (s *Service) ListArtifacts(id string) ([]Artifact, error) {
+	dir, err := s.RunOutputDir(id)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := []Artifact{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, Artifact{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// ArtifactPath returns the file of one of a run's artifacts, refusing paths
+// outside its output directory
+func (s *Service) ArtifactPath(id, path string) (string, error) {
+	dir, err := s.RunOutputDir(id)
+	if err != nil {
+		return "", err
+	}
+	full := filepath.Join(dir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(dir, full); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid artifact path: %q", path)
+	}
+	info, err := os.Stat(full)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("run %s has no artifact %s", id, path)
+	}
+	return full, nil
+}
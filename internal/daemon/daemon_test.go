@@ -0,0 +1,710 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/providers"
+	workflowexec "github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWorkflowYAML = `name: review
+description: Review a change
+agents:
+  - id: review
+    provider: claude
+    prompt: Review the change
+  - id: fix
+    provider: gemini
+    prompt: promptgarden://fixer
+    depends_on: [review]
+`
+
+func newTestService(t *testing.T) (*Service, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	opunDir := filepath.Join(home, ".opun")
+	garden, err := promptgarden.NewGarden(filepath.Join(opunDir, "promptgarden"))
+	require.NoError(t, err)
+	return NewService(opunDir, garden, nil), opunDir
+}
+
+func TestServiceItems(t *testing.T) {
+	service, opunDir := newTestService(t)
+
+	item, diagnostics, err := service.SaveItem(KindWorkflow, "review", testWorkflowYAML)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(opunDir, "workflows", "review.yaml"), item.Path)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, SeverityWarning, diagnostics[0].Severity)
+	assert.Contains(t, diagnostics[0].Message, "fixer")
+
+	items, err := service.ListItems(KindWorkflow)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "review", items[0].Name)
+	assert.Equal(t, "Review a change", items[0].Description)
+	assert.Empty(t, items[0].Content)
+
+	got, err := service.GetItem(KindWorkflow, "review")
+	require.NoError(t, err)
+	assert.Equal(t, testWorkflowYAML, got.Content)
+
+	_, diagnostics, err = service.SaveItem(KindWorkflow, "broken", "name: broken\nagents: []\n")
+	assert.Error(t, err)
+	assert.True(t, HasErrors(diagnostics))
+	assert.NoFileExists(t, filepath.Join(opunDir, "workflows", "broken.yaml"))
+
+	_, _, err = service.SaveItem(KindWorkflow, "../escape", testWorkflowYAML)
+	assert.Error(t, err)
+	_, err = service.ListItems("plugin")
+	assert.Error(t, err)
+
+	_, diagnostics, err = service.SaveItem(KindPrompt, "fixer", "Fix the issues found in {{include:style}}")
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 1)
+	assert.Contains(t, diagnostics[0].Message, "'style'")
+
+	diagnostics, err = service.Validate(KindWorkflow, testWorkflowYAML)
+	require.NoError(t, err)
+	assert.Empty(t, diagnostics, "the referenced prompt now exists")
+
+	items, err = service.ListItems("")
+	require.NoError(t, err)
+	listed := make(map[string]bool)
+	for _, item := range items {
+		listed[item.Kind+"/"+item.Name] = true
+	}
+	assert.True(t, listed["workflow/review"])
+	assert.True(t, listed["prompt/fixer"])
+
+	_, err = service.DeleteItem(KindWorkflow, "review", t.TempDir())
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(opunDir, "workflows", "review.yaml"))
+	_, err = service.GetItem(KindWorkflow, "review")
+	assert.Error(t, err)
+
+	_, err = service.DeleteItem(KindPrompt, "fixer", t.TempDir())
+	require.NoError(t, err)
+	_, err = service.GetItem(KindPrompt, "fixer")
+	assert.Error(t, err)
+}
+
+func TestValidateDiagnosticLines(t *testing.T) {
+	service, _ := newTestService(t)
+
+	tests := []struct {
+		name    string
+		kind    string
+		content string
+		line    int
+		message string
+	}{
+		{
+			name:    "yaml syntax",
+			kind:    KindWorkflow,
+			content: "name: x\ndescription: a: b\nagents: []\n",
+			line:    1,
+			message: "yaml",
+		},
+		{
+			name:    "duplicate agent",
+			kind:    KindWorkflow,
+			content: "name: x\nagents:\n  - id: a\n    provider: claude\n    prompt: p\n  - id: a\n    provider: claude\n    prompt: p\n",
+			line:    5,
+			message: "duplicate agent ID: a",
+		},
+		{
+			name:    "unknown dependency",
+			kind:    KindWorkflow,
+			content: "name: x\nagents:\n  - id: a\n    provider: claude\n    prompt: p\n  - id: \"b\"\n    provider: claude\n    prompt: p\n    depends_on: [c]\n",
+			line:    5,
+			message: "unknown dependency c",
+		},
+		{
+			name:    "action without command",
+			kind:    KindAction,
+			content: "id: build\ndescription: Build\n",
+			line:    0,
+			message: "execution method",
+		},
+		{
+			name:    "subagent provider",
+			kind:    KindSubagent,
+			content: "name: helper\ndescription: Helps\nprovider: copilot\n",
+			line:    2,
+			message: "unsupported provider",
+		},
+		{
+			name:    "empty prompt",
+			kind:    KindPrompt,
+			content: "  \n",
+			line:    0,
+			message: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := service.Validate(tt.kind, tt.content)
+			require.NoError(t, err)
+			require.NotEmpty(t, diagnostics)
+			assert.Equal(t, SeverityError, diagnostics[0].Severity)
+			assert.Equal(t, tt.line, diagnostics[0].Range.Start.Line)
+			assert.Contains(t, diagnostics[0].Message, tt.message)
+		})
+	}
+
+	diagnostics, err := service.Validate(KindAction, "id: build\ncommand: make build\n")
+	require.NoError(t, err)
+	assert.NotNil(t, diagnostics)
+	assert.Empty(t, diagnostics)
+}
+
+func TestRunManager(t *testing.T) {
+	release := make(chan struct{})
+	runs := NewRunManager(func(ctx context.Context, name string, variables map[string]interface{}, onEvent func(workflow.WorkflowEvent)) (interface{}, error) {
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowStart, Message: "start", Data: map[string]interface{}{"total_agents": 2}})
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventAgentComplete, AgentID: "a", Message: "a done"})
+		if name == "fails" {
+			return nil, fmt.Errorf("agent b failed")
+		}
+		select {
+		case <-release:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	var received []string
+	done := make(chan struct{}, 10)
+	unsubscribe := runs.Subscribe(func(runID string, event workflow.WorkflowEvent) {
+		received = append(received, event.Message)
+		done <- struct{}{}
+	})
+
+	waitFor := func(id string, status RunStatus) Run {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if run, ok := runs.Get(id); ok && run.Status == status {
+				return run
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("run %s never became %s", id, status)
+		return Run{}
+	}
+
+	completed := runs.Start("ok", nil)
+	assert.Equal(t, RunRunning, completed.Status)
+	<-done
+	<-done
+	unsubscribe()
+	assert.Equal(t, []string{"start", "a done"}, received)
+	close(release)
+	run := waitFor(completed.ID, RunCompleted)
+	assert.Equal(t, 2, run.Progress)
+	assert.Equal(t, 2, run.Total)
+	assert.Len(t, run.Events, 2)
+
+	failed := waitFor(runs.Start("fails", nil).ID, RunFailed)
+	assert.Equal(t, "agent b failed", failed.Error)
+	assert.Equal(t, 1, failed.Progress)
+
+	release = make(chan struct{})
+	cancelled := runs.Start("slow", nil)
+	require.NoError(t, runs.Cancel(cancelled.ID))
+	waitFor(cancelled.ID, RunCancelled)
+	assert.Error(t, runs.Cancel(cancelled.ID))
+	assert.Error(t, runs.Cancel("run-missing"))
+
+	list := runs.List()
+	require.Len(t, list, 3)
+	assert.Nil(t, list[0].Events)
+}
+
+func TestRunManagerQueued(t *testing.T) {
+	start := make(chan struct{})
+	runs := NewRunManager(func(ctx context.Context, name string, variables map[string]interface{}, onEvent func(workflow.WorkflowEvent)) (interface{}, error) {
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowQueued, Message: "queued", Data: map[string]interface{}{"position": 1}})
+		select {
+		case <-start:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowStart, Message: "start", Data: map[string]interface{}{"total_agents": 1}})
+		return nil, nil
+	})
+
+	queued := runs.Start("review", nil)
+	require.Eventually(t, func() bool {
+		run, _ := runs.Get(queued.ID)
+		return run.Status == RunQueued && run.QueuePosition == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var metrics strings.Builder
+	runs.WritePrometheus(&metrics)
+	assert.Contains(t, metrics.String(), "opun_runs_queued 1\n")
+
+	close(start)
+	require.Eventually(t, func() bool {
+		run, _ := runs.Get(queued.ID)
+		return run.Status == RunCompleted && run.QueuePosition == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Queued runs can be cancelled
+	start = make(chan struct{})
+	waiting := runs.Start("review", nil)
+	require.Eventually(t, func() bool {
+		run, _ := runs.Get(waiting.ID)
+		return run.Status == RunQueued
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, runs.Cancel(waiting.ID))
+}
+
+func TestRunMetrics(t *testing.T) {
+	start := time.Now()
+	runs := NewRunManager(func(ctx context.Context, name string, variables map[string]interface{}, onEvent func(workflow.WorkflowEvent)) (interface{}, error) {
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, AgentID: "a", Timestamp: start, Data: map[string]interface{}{"provider": "claude"}})
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventAgentComplete, AgentID: "a", Timestamp: start.Add(20 * time.Second)})
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, AgentID: "b", Timestamp: start, Data: map[string]interface{}{"provider": "claude"}})
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventAgentError, AgentID: "b", Timestamp: start.Add(2 * time.Second)})
+		return nil, fmt.Errorf("agent b failed")
+	})
+
+	id := runs.Start("review", nil).ID
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if run, _ := runs.Get(id); run.Status == RunFailed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	server := httptest.NewServer(NewMetricsServer(runs.WritePrometheus).Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	metrics := string(body)
+
+	for _, line := range []string{
+		"opun_runs_started_total 1",
+		`opun_runs_finished_total{status="failed"} 1`,
+		`opun_runs_finished_total{status="completed"} 0`,
+		"opun_runs_running 0",
+		"opun_agents_running 0",
+		`opun_agents_finished_total{provider="claude",status="completed"} 1`,
+		`opun_agents_finished_total{provider="claude",status="failed"} 1`,
+		`opun_agent_duration_seconds_bucket{provider="claude",le="5"} 1`,
+		`opun_agent_duration_seconds_bucket{provider="claude",le="30"} 2`,
+		`opun_agent_duration_seconds_bucket{provider="claude",le="+Inf"} 2`,
+		`opun_agent_duration_seconds_sum{provider="claude"} 22`,
+	} {
+		assert.Contains(t, metrics, line+"\n")
+	}
+}
+
+func rpcCall(t *testing.T, url, token, method string, params interface{}) (int, rpcResponse) {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, url+"/rpc", bytes.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response rpcResponse
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	}
+	return resp.StatusCode, response
+}
+
+func TestHTTPServer(t *testing.T) {
+	service, _ := newTestService(t)
+	server := httptest.NewServer(NewHTTPServer(service, "secret").Handler())
+	defer server.Close()
+
+	status, _ := rpcCall(t, server.URL, "", MethodItemsList, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, response := rpcCall(t, server.URL, "secret", MethodItemsSave, map[string]string{
+		"kind": KindAction, "name": "build", "content": "id: build\ncommand: make build\n",
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Nil(t, response.Error)
+
+	_, response = rpcCall(t, server.URL, "secret", MethodItemsList, map[string]string{"kind": KindAction})
+	require.Nil(t, response.Error)
+	var list struct {
+		Items []Item `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(response.Result, &list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "build", list.Items[0].Name)
+
+	_, response = rpcCall(t, server.URL, "secret", MethodItemsSave, map[string]string{
+		"kind": KindAction, "name": "empty", "content": "id: empty\n",
+	})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, CodeServerError, response.Error.Code)
+	assert.Contains(t, fmt.Sprint(response.Error.Data), "execution method")
+
+	_, response = rpcCall(t, server.URL, "secret", MethodItemsGet, map[string]string{"kind": KindAction})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, CodeInvalidParams, response.Error.Code)
+
+	_, response = rpcCall(t, server.URL, "secret", "items/rename", nil)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, CodeMethodNotFound, response.Error.Code)
+
+	_, response = rpcCall(t, server.URL, "secret", MethodRunsList, nil)
+	require.NotNil(t, response.Error, "runs need a workflow manager")
+}
+
+// restCall makes a REST API request and decodes the JSON response into out
+func restCall(t *testing.T, method, url, token string, body interface{}, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestRESTServer(t *testing.T) {
+	service, _ := newTestService(t)
+	outputDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, "review"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "review", "output.md"), []byte("looks good"), 0644))
+
+	release := make(chan struct{})
+	service.runs = NewRunManager(func(ctx context.Context, name string, variables map[string]interface{}, onEvent func(workflow.WorkflowEvent)) (interface{}, error) {
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowStart, Message: "start", Data: map[string]interface{}{
+			"total_agents": 1, "run_id": "review-1", "output_dir": outputDir,
+		}})
+		<-release
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventOutputChunk, AgentID: "review", Data: map[string]interface{}{"text": "\x1b[1mLooks\x1b[0m good\r\n"}})
+		onEvent(workflow.WorkflowEvent{Type: workflow.EventAgentComplete, AgentID: "review", Message: "review done"})
+		return nil, nil
+	})
+	_, _, err := service.SaveItem(KindWorkflow, "review", testWorkflowYAML)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewHTTPServer(service, "secret").Handler())
+	defer server.Close()
+	api := server.URL + RESTPrefix
+
+	var failure map[string]string
+	assert.Equal(t, http.StatusUnauthorized, restCall(t, http.MethodGet, api+"/items", "", nil, &failure))
+	assert.Equal(t, "unauthorized", failure["error"])
+
+	var list struct {
+		Items []Item `json:"items"`
+	}
+	require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/items/workflow", "secret", nil, &list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "review", list.Items[0].Name)
+
+	var item Item
+	require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/items/workflow/review", "secret", nil, &item))
+	assert.Contains(t, item.Content, "Review the change")
+	assert.Equal(t, http.StatusBadRequest, restCall(t, http.MethodGet, api+"/items/recipe/review", "secret", nil, &failure))
+	assert.Equal(t, http.StatusNotFound, restCall(t, http.MethodGet, api+"/items/workflow/missing", "secret", nil, &failure))
+
+	assert.Equal(t, http.StatusNotFound, restCall(t, http.MethodPost, api+"/runs", "secret", map[string]string{"workflow": "missing"}, &failure))
+	assert.Equal(t, http.StatusBadRequest, restCall(t, http.MethodPost, api+"/runs", "secret", map[string]string{}, &failure))
+
+	var run Run
+	require.Equal(t, http.StatusAccepted, restCall(t, http.MethodPost, api+"/runs", "secret", map[string]string{"workflow": "review"}, &run))
+	assert.Equal(t, "review", run.Workflow)
+
+	// The event stream replays the start event, then follows the run
+	req, err := http.NewRequest(http.MethodGet, api+"/runs/"+run.ID+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	var names, messages []string
+	for len(names) < 4 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			names = append(names, strings.TrimSpace(strings.TrimPrefix(line, "event: ")))
+			if len(names) == 1 {
+				close(release)
+			}
+		case strings.HasPrefix(line, "data: "):
+			var event workflow.WorkflowEvent
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			messages = append(messages, event.Message)
+		}
+	}
+	assert.Equal(t, []string{"event", "event", "event", "done"}, names)
+	assert.Equal(t, []string{"start", "", "review done"}, messages[:3])
+
+	// Output is kept as plain text per agent, not among the events
+	var logs struct {
+		Logs map[string]string `json:"logs"`
+	}
+	require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/runs/"+run.ID+"/logs", "secret", nil, &logs))
+	assert.Equal(t, map[string]string{"review": "Looks good\n"}, logs.Logs)
+
+	require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/runs/"+run.ID, "secret", nil, &run))
+	assert.Equal(t, RunCompleted, run.Status)
+	assert.Equal(t, "review-1", run.RunID)
+	assert.Len(t, run.Events, 2)
+	assert.Equal(t, http.StatusNotFound, restCall(t, http.MethodGet, api+"/runs/run-missing", "secret", nil, &failure))
+	assert.Equal(t, http.StatusConflict, restCall(t, http.MethodPost, api+"/runs/"+run.ID+"/cancel", "secret", nil, &failure))
+
+	// Artifacts can be found by the daemon or the Opun run ID
+	for _, id := range []string{run.ID, "review-1"} {
+		var artifacts struct {
+			Artifacts []Artifact `json:"artifacts"`
+		}
+		require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/runs/"+id+"/artifacts", "secret", nil, &artifacts))
+		require.Len(t, artifacts.Artifacts, 1)
+		assert.Equal(t, "review/output.md", artifacts.Artifacts[0].Path)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, api+"/runs/review-1/artifacts/review/output.md", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "looks good", string(content))
+
+	_, err = service.ArtifactPath(run.ID, "../outside.md")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, restCall(t, http.MethodGet, api+"/runs/"+run.ID+"/artifacts/missing.md", "secret", nil, &failure))
+}
+
+func TestRESTHistory(t *testing.T) {
+	service, _ := newTestService(t)
+	dir, err := workflowexec.HistoryDir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for i, id := range []string{"old", "new"} {
+		record := workflowexec.RunRecord{RunManifest: workflowexec.RunManifest{
+			RunID:     id,
+			Workflow:  "review",
+			Status:    "completed",
+			StartedAt: time.Now().Add(time.Duration(i) * time.Minute),
+			Agents: []workflowexec.ManifestAgent{
+				{ID: "a", Usage: &providers.Usage{InputTokens: 100, OutputTokens: 10, CostUSD: 0.25}},
+				{ID: "b", Usage: &providers.Usage{InputTokens: 50, OutputTokens: 5, CostUSD: 0.5}},
+			},
+		}}
+		data, err := json.Marshal(record)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, id+".json"), data, 0644))
+	}
+
+	server := httptest.NewServer(NewHTTPServer(service, "secret").Handler())
+	defer server.Close()
+	api := server.URL + RESTPrefix
+
+	var history struct {
+		Runs []HistoryRun `json:"runs"`
+	}
+	require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/history?limit=1", "secret", nil, &history))
+	require.Len(t, history.Runs, 1)
+	assert.Equal(t, "new", history.Runs[0].RunID)
+	assert.Equal(t, 2, history.Runs[0].Agents)
+	assert.Equal(t, &providers.Usage{InputTokens: 150, OutputTokens: 15, CostUSD: 0.75}, history.Runs[0].Usage)
+
+	var failure map[string]string
+	assert.Equal(t, http.StatusBadRequest, restCall(t, http.MethodGet, api+"/history?limit=none", "secret", nil, &failure))
+
+	var record workflowexec.RunRecord
+	require.Equal(t, http.StatusOK, restCall(t, http.MethodGet, api+"/history/old", "secret", nil, &record))
+	assert.Equal(t, "old", record.RunID)
+	assert.Equal(t, http.StatusNotFound, restCall(t, http.MethodGet, api+"/history/missing", "secret", nil, &failure))
+	assert.Equal(t, http.StatusServiceUnavailable, restCall(t, http.MethodGet, api+"/runs", "secret", nil, &failure))
+}
+
+func TestDashboard(t *testing.T) {
+	service, _ := newTestService(t)
+	server := httptest.NewServer(NewHTTPServer(service, "secret").Handler())
+	defer server.Close()
+
+	// The page is served without a token, the API it calls is not
+	resp, err := http.Get(server.URL + "/")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	assert.Contains(t, string(body), RESTPrefix)
+
+	resp, err = http.Get(server.URL + "/missing")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestInfoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), InfoFile)
+	info := Info{Address: "127.0.0.1:7420", Token: "secret", PID: 42}
+	require.NoError(t, WriteInfo(path, info))
+
+	got, err := ReadInfo(path)
+	require.NoError(t, err)
+	assert.Equal(t, info.Address, got.Address)
+	assert.Equal(t, info.Token, got.Token)
+
+	if stat, err := os.Stat(path); err == nil && os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	}
+}
+
+// lspFrame encodes a message with LSP framing
+func lspFrame(t *testing.T, message map[string]interface{}) string {
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// readLSPFrames decodes every framed message in output
+func readLSPFrames(t *testing.T, output []byte) []map[string]interface{} {
+	var messages []map[string]interface{}
+	reader := bufio.NewReader(bytes.NewReader(output))
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return messages
+		}
+		require.NoError(t, err)
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		require.NoError(t, err)
+		_, err = reader.ReadString('\n')
+		require.NoError(t, err)
+
+		data := make([]byte, length)
+		_, err = io.ReadFull(reader, data)
+		require.NoError(t, err)
+		var message map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &message))
+		messages = append(messages, message)
+	}
+}
+
+func TestLSPServer(t *testing.T) {
+	service, opunDir := newTestService(t)
+	uri := "file://" + filepath.ToSlash(filepath.Join(opunDir, "workflows", "review.yaml"))
+
+	var input strings.Builder
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]interface{}{}}))
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "initialized"}))
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "text": "name: review\nagents: []\n"},
+	}}))
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": "file:///tmp/notes.md", "text": "# notes"},
+	}}))
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": MethodItemsList, "params": map[string]string{"kind": KindWorkflow}}))
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 3, "method": "shutdown"}))
+	input.WriteString(lspFrame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}))
+
+	var output bytes.Buffer
+	require.NoError(t, NewLSPServer(service, strings.NewReader(input.String()), &output).Run(context.Background()))
+
+	messages := readLSPFrames(t, output.Bytes())
+	require.Len(t, messages, 4)
+
+	assert.EqualValues(t, 1, messages[0]["id"])
+	assert.Contains(t, messages[0]["result"], "capabilities")
+
+	assert.Equal(t, "textDocument/publishDiagnostics", messages[1]["method"])
+	params := messages[1]["params"].(map[string]interface{})
+	assert.Equal(t, uri, params["uri"])
+	diagnostics := params["diagnostics"].([]interface{})
+	require.Len(t, diagnostics, 1)
+	assert.Contains(t, diagnostics[0].(map[string]interface{})["message"], "at least one agent")
+
+	assert.EqualValues(t, 2, messages[2]["id"])
+	assert.Contains(t, messages[2]["result"], "items")
+
+	assert.EqualValues(t, 3, messages[3]["id"])
+	assert.Contains(t, messages[3], "result")
+	assert.Nil(t, messages[3]["result"])
+}
+
+func TestDetectKind(t *testing.T) {
+	service, opunDir := newTestService(t)
+
+	assert.Equal(t, KindWorkflow, service.DetectKind(filepath.Join(opunDir, "workflows", "a.yaml"), ""))
+	assert.Equal(t, KindAction, service.DetectKind(filepath.Join(opunDir, "actions", "a.yml"), ""))
+	assert.Equal(t, KindSubagent, service.DetectKind(filepath.Join(opunDir, "subagents", "a.json"), ""))
+	assert.Equal(t, KindPrompt, service.DetectKind(filepath.Join(opunDir, "promptgarden", "a.md"), ""))
+	assert.Equal(t, "", service.DetectKind(filepath.Join(opunDir, "config.yaml"), "provider: claude\n"))
+	assert.Equal(t, KindWorkflow, service.DetectKind("/work/ci/review.yaml", testWorkflowYAML))
+	assert.Equal(t, "", service.DetectKind("/work/ci/compose.yaml", "services:\n  web: {}\n"))
+}
@@ -0,0 +1,95 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/workflow"
+)
+
+// defaultHistoryLimit is how many runs History returns when no limit is given
+const defaultHistoryLimit = 50
+
+// HistoryRun summarises a run kept in the run history
+type HistoryRun struct {
+	RunID      string     `json:"run_id"`
+	Workflow   string     `json:"workflow"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Agents     int        `json:"agents"`
+	Error      string     `json:"error,omitempty"`
+	// Usage is the tokens and cost the run's providers reported, if any did
+	Usage *providers.Usage `json:"usage,omitempty"`
+	// Rating is the average rating given to the run, 0 when it has none
+	Rating float64 `json:"rating,omitempty"`
+}
+
+// History returns the most recent runs in the run history, newest first
+func (s *Service) History(limit int) ([]HistoryRun, error) {
+	dir, err := workflow.HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+	records, err := workflow.ListRunRecords(dir)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	runs := []HistoryRun{}
+	for i := len(records) - 1; i >= 0 && len(runs) < limit; i-- {
+		record := records[i]
+		run := HistoryRun{
+			RunID:      record.RunID,
+			Workflow:   record.Workflow,
+			Status:     record.Status,
+			StartedAt:  record.StartedAt,
+			FinishedAt: record.FinishedAt,
+			Agents:     len(record.Agents),
+			Error:      record.Error,
+			Usage:      record.Usage(),
+		}
+		if len(record.Feedback) > 0 {
+			total := 0
+			for _, feedback := range record.Feedback {
+				total += feedback.Rating
+			}
+			run.Rating = float64(total) / float64(len(record.Feedback))
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// HistoryRecord returns the full record of a run in the run history
+func (s *Service) HistoryRecord(id string) (*workflow.RunRecord, error) {
+	dir, err := workflow.HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+	record, err := workflow.LoadRunRecord(dir, id)
+	if err != nil {
+		return nil, fmt.Errorf("unknown run: %s", id)
+	}
+	return record, nil
+}
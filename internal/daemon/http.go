@@ -0,0 +1,186 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultAddress is where `opun daemon --api` listens unless told otherwise
+const DefaultAddress = "127.0.0.1:7420"
+
+// InfoFile is where a running daemon describes itself, relative to ~/.opun
+const InfoFile = "daemon.json"
+
+// maxRequestSize bounds the size of an API request body
+const maxRequestSize = 10 << 20
+
+// Info tells editor extensions how to reach a running daemon
+type Info struct {
+	Address   string    `json:"address"`
+	Token     string    `json:"token,omitempty"`
+	PID       int       `json:"pid"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// HTTPServer serves the daemon API as JSON-RPC over HTTP at /rpc, and as a
+// REST API under /api/v1 with the web dashboard at /
+type HTTPServer struct {
+	service *Service
+	token   string
+	server  *http.Server
+}
+
+// NewHTTPServer creates an API server. When token is set, requests must send it
+// as a bearer token.
+func NewHTTPServer(service *Service, token string) *HTTPServer {
+	return &HTTPServer{service: service, token: token}
+}
+
+// Handler returns the HTTP handler for the API
+func (s *HTTPServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/rpc", s.handleRPC)
+	s.restRoutes(mux)
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	return mux
+}
+
+// Start listens on addr and serves in the background. It returns the address
+// actually listened on, which differs from addr when its port is 0.
+func (s *HTTPServer) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "Daemon API error: %v\n", err)
+		}
+	}()
+
+	return listener.Addr().String(), nil
+}
+
+// Stop shuts the server down
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	if s.server != nil {
+		return s.server.Shutdown(ctx)
+	}
+	return nil
+}
+
+func (s *HTTPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *HTTPServer) handleRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var request rpcRequest
+	var response *rpcResponse
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		response = newResponse(nil, nil, &RPCError{Code: CodeParseError, Message: fmt.Sprintf("parse error: %v", err)})
+	} else if request.Method == "" {
+		response = newResponse(request.ID, nil, &RPCError{Code: CodeInvalidRequest, Message: "method is required"})
+	} else {
+		result, err := s.service.Call(r.Context(), request.Method, request.Params)
+		if request.isNotification() {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		response = newResponse(request.ID, result, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// authorized checks the request's bearer token
+func (s *HTTPServer) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// NewToken returns a random API token
+func NewToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// WriteInfo publishes a daemon's address and token. The file is only readable
+// by the current user since it holds the token.
+func WriteInfo(path string, info Info) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ReadInfo reads the description of a running daemon
+func ReadInfo(path string) (Info, error) {
+	var info Info
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("invalid daemon info: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,249 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// workflowDocRegexp recognizes workflow definitions outside ~/.opun/workflows
+var workflowDocRegexp = regexp.MustCompile(`(?m)^agents:`)
+
+// LSPServer speaks the Language Server Protocol over a stream. It publishes
+// diagnostics for Opun definitions as they are edited, and serves the daemon
+// API methods on the same connection so editor extensions need only one
+// process. Run events are pushed as opun/runEvent notifications.
+type LSPServer struct {
+	service *Service
+	reader  *bufio.Reader
+	writer  io.Writer
+	writeMu sync.Mutex
+}
+
+// NewLSPServer creates a language server reading requests from r and writing
+// responses to w
+func NewLSPServer(service *Service, r io.Reader, w io.Writer) *LSPServer {
+	return &LSPServer{
+		service: service,
+		reader:  bufio.NewReader(r),
+		writer:  w,
+	}
+}
+
+// Run serves requests until the client sends exit or closes the stream
+func (s *LSPServer) Run(ctx context.Context) error {
+	if runs := s.service.Runs(); runs != nil {
+		unsubscribe := runs.Subscribe(func(runID string, event workflow.WorkflowEvent) {
+			s.notify("opun/runEvent", map[string]interface{}{"run_id": runID, "event": event})
+		})
+		defer unsubscribe()
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		data, err := s.readMessage()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var request rpcRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			s.send(newResponse(nil, nil, &RPCError{Code: CodeParseError, Message: fmt.Sprintf("parse error: %v", err)}))
+			continue
+		}
+		if request.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(ctx, &request)
+		if !request.isNotification() {
+			s.send(newResponse(request.ID, result, err))
+		}
+	}
+}
+
+// handle answers LSP lifecycle and document methods, passing the rest to the service
+func (s *LSPServer) handle(ctx context.Context, request *rpcRequest) (interface{}, error) {
+	switch request.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    1, // full document sync
+					"save":      map[string]bool{"includeText": true},
+				},
+			},
+			"serverInfo": map[string]string{"name": "opun"},
+		}, nil
+
+	case "initialized", "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didSave", "textDocument/didClose":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+			Text *string `json:"text"`
+		}
+		if err := decodeParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		uri := params.TextDocument.URI
+		switch request.Method {
+		case "textDocument/didOpen":
+			s.publishDiagnostics(uri, params.TextDocument.Text)
+		case "textDocument/didChange":
+			if n := len(params.ContentChanges); n > 0 {
+				s.publishDiagnostics(uri, params.ContentChanges[n-1].Text)
+			}
+		case "textDocument/didSave":
+			if params.Text != nil {
+				s.publishDiagnostics(uri, *params.Text)
+			}
+		case "textDocument/didClose":
+			s.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": []Diagnostic{}})
+		}
+		return nil, nil
+	}
+
+	if strings.HasPrefix(request.Method, "$/") {
+		// Optional protocol notifications such as $/cancelRequest
+		return nil, nil
+	}
+	return s.service.Call(ctx, request.Method, request.Params)
+}
+
+// publishDiagnostics validates a document if it is an Opun definition
+func (s *LSPServer) publishDiagnostics(uri, text string) {
+	kind := s.service.DetectKind(uriPath(uri), text)
+	if kind == "" {
+		return
+	}
+
+	diagnostics, err := s.service.Validate(kind, text)
+	if err != nil {
+		return
+	}
+	s.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": diagnostics})
+}
+
+// DetectKind works out which kind of item a file defines from its location
+// under ~/.opun, falling back to its content for workflows kept elsewhere.
+// It returns "" for files that aren't Opun definitions.
+func (s *Service) DetectKind(path, content string) string {
+	if rel, err := filepath.Rel(s.opunDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		dir := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+		if dir == "promptgarden" {
+			if filepath.Ext(path) == ".md" {
+				return KindPrompt
+			}
+			return ""
+		}
+		for kind, kindDir := range itemDirs {
+			if dir == kindDir && isConfigFile(path) {
+				return kind
+			}
+		}
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		if workflowDocRegexp.MatchString(content) {
+			return KindWorkflow
+		}
+	}
+	return ""
+}
+
+// uriPath converts a file URI to a local path
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	// file:///C:/dir on Windows
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// readMessage reads one Content-Length framed message
+func (s *LSPServer) readMessage() ([]byte, error) {
+	headers, err := textproto.NewReader(s.reader).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 || length > maxRequestSize {
+		return nil, fmt.Errorf("invalid Content-Length: %q", headers.Get("Content-Length"))
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// notify sends a notification to the client
+func (s *LSPServer) notify(method string, params interface{}) {
+	s.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// send writes one Content-Length framed message
+func (s *LSPServer) send(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
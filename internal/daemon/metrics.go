@@ -0,0 +1,254 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// agentDurationBuckets are the upper bounds, in seconds, of the agent
+// duration histogram; agents run from seconds to the better part of an hour
+var agentDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+
+// runMetrics counts runs and agents for Prometheus
+type runMetrics struct {
+	mu       sync.Mutex
+	started  int64
+	finished map[RunStatus]int64
+	agents   map[string]*agentMetric // by provider
+	running  map[string]agentStart   // by run ID and agent ID
+}
+
+// agentStart is an agent that has started but not finished
+type agentStart struct {
+	provider string
+	time     time.Time
+}
+
+// agentMetric holds the agent counters and duration histogram of a provider
+type agentMetric struct {
+	completed int64
+	failed    int64
+	buckets   []int64 // cumulative counts per agentDurationBuckets bound
+	sum       float64
+	count     int64
+}
+
+func newRunMetrics() *runMetrics {
+	return &runMetrics{
+		finished: make(map[RunStatus]int64),
+		agents:   make(map[string]*agentMetric),
+		running:  make(map[string]agentStart),
+	}
+}
+
+func (m *runMetrics) runStarted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started++
+}
+
+func (m *runMetrics) runFinished(runID string, status RunStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finished[status]++
+
+	// Agents of a cancelled run never report back
+	prefix := runID + "/"
+	for key := range m.running {
+		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
+			delete(m.running, key)
+		}
+	}
+}
+
+// observe records agent starts and ends from a run's events
+func (m *runMetrics) observe(runID string, event workflow.WorkflowEvent) {
+	key := runID + "/" + event.AgentID
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch event.Type {
+	case workflow.EventAgentStart:
+		provider, _ := event.Data["provider"].(string)
+		if provider == "" {
+			provider = "none"
+		}
+		m.running[key] = agentStart{provider: provider, time: event.Timestamp}
+
+	case workflow.EventAgentComplete, workflow.EventAgentError:
+		start, ok := m.running[key]
+		if !ok {
+			return
+		}
+		delete(m.running, key)
+
+		agent := m.agents[start.provider]
+		if agent == nil {
+			agent = &agentMetric{buckets: make([]int64, len(agentDurationBuckets))}
+			m.agents[start.provider] = agent
+		}
+		if event.Type == workflow.EventAgentError {
+			agent.failed++
+		} else {
+			agent.completed++
+		}
+
+		seconds := event.Timestamp.Sub(start.time).Seconds()
+		for i, bound := range agentDurationBuckets {
+			if seconds <= bound {
+				agent.buckets[i]++
+			}
+		}
+		agent.sum += seconds
+		agent.count++
+	}
+}
+
+// WritePrometheus writes run and agent metrics in the Prometheus text
+// exposition format
+func (m *RunManager) WritePrometheus(w io.Writer) {
+	m.mu.Lock()
+	running, queued := 0, 0
+	for _, run := range m.runs {
+		switch run.Status {
+		case RunRunning:
+			running++
+		case RunQueued:
+			queued++
+		}
+	}
+	m.mu.Unlock()
+
+	metrics := m.metrics
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP opun_runs_started_total Total number of workflow runs started.")
+	fmt.Fprintln(w, "# TYPE opun_runs_started_total counter")
+	fmt.Fprintf(w, "opun_runs_started_total %d\n", metrics.started)
+
+	fmt.Fprintln(w, "# HELP opun_runs_finished_total Total number of workflow runs finished, by status.")
+	fmt.Fprintln(w, "# TYPE opun_runs_finished_total counter")
+	for _, status := range []RunStatus{RunCompleted, RunFailed, RunCancelled} {
+		fmt.Fprintf(w, "opun_runs_finished_total{status=%q} %d\n", status, metrics.finished[status])
+	}
+
+	fmt.Fprintln(w, "# HELP opun_runs_running Number of workflow runs in progress.")
+	fmt.Fprintln(w, "# TYPE opun_runs_running gauge")
+	fmt.Fprintf(w, "opun_runs_running %d\n", running)
+
+	fmt.Fprintln(w, "# HELP opun_runs_queued Number of workflow runs waiting for their turn.")
+	fmt.Fprintln(w, "# TYPE opun_runs_queued gauge")
+	fmt.Fprintf(w, "opun_runs_queued %d\n", queued)
+
+	fmt.Fprintln(w, "# HELP opun_agents_running Number of agents in progress.")
+	fmt.Fprintln(w, "# TYPE opun_agents_running gauge")
+	fmt.Fprintf(w, "opun_agents_running %d\n", len(metrics.running))
+
+	providers := make([]string, 0, len(metrics.agents))
+	for provider := range metrics.agents {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	fmt.Fprintln(w, "# HELP opun_agents_finished_total Total number of agents finished, by provider and status.")
+	fmt.Fprintln(w, "# TYPE opun_agents_finished_total counter")
+	for _, provider := range providers {
+		agent := metrics.agents[provider]
+		fmt.Fprintf(w, "opun_agents_finished_total{provider=%q,status=\"completed\"} %d\n", provider, agent.completed)
+		fmt.Fprintf(w, "opun_agents_finished_total{provider=%q,status=\"failed\"} %d\n", provider, agent.failed)
+	}
+
+	fmt.Fprintln(w, "# HELP opun_agent_duration_seconds Time from an agent's start to its end, by provider.")
+	fmt.Fprintln(w, "# TYPE opun_agent_duration_seconds histogram")
+	for _, provider := range providers {
+		agent := metrics.agents[provider]
+		for i, bound := range agentDurationBuckets {
+			fmt.Fprintf(w, "opun_agent_duration_seconds_bucket{provider=%q,le=%q} %d\n",
+				provider, strconv.FormatFloat(bound, 'f', -1, 64), agent.buckets[i])
+		}
+		fmt.Fprintf(w, "opun_agent_duration_seconds_bucket{provider=%q,le=\"+Inf\"} %d\n", provider, agent.count)
+		fmt.Fprintf(w, "opun_agent_duration_seconds_sum{provider=%q} %s\n", provider, strconv.FormatFloat(agent.sum, 'f', -1, 64))
+		fmt.Fprintf(w, "opun_agent_duration_seconds_count{provider=%q} %d\n", provider, agent.count)
+	}
+}
+
+// MetricsServer serves Prometheus metrics at /metrics. It is separate from
+// the API server and needs no token, so scrapers can reach it.
+type MetricsServer struct {
+	write  func(w io.Writer)
+	server *http.Server
+}
+
+// NewMetricsServer creates a metrics server that calls write for every scrape
+func NewMetricsServer(write func(w io.Writer)) *MetricsServer {
+	return &MetricsServer{write: write}
+}
+
+// Handler returns the HTTP handler for the metrics endpoint
+func (s *MetricsServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		s.write(w)
+	})
+	return mux
+}
+
+// Start listens on addr and serves in the background, returning the address
+// actually listened on
+func (s *MetricsServer) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "Metrics server error: %v\n", err)
+		}
+	}()
+
+	return listener.Addr().String(), nil
+}
+
+// Stop shuts the server down
+func (s *MetricsServer) Stop(ctx context.Context) error {
+	if s.server != nil {
+		return s.server.Shutdown(ctx)
+	}
+	return nil
+}
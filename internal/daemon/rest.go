@@ -0,0 +1,267 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RESTPrefix is where the REST API is served
+const RESTPrefix = "/api/v1"
+
+// sseKeepAlive is how often an idle event stream gets a comment, so proxies
+// keep the connection open
+const sseKeepAlive = 15 * time.Second
+
+// restRoutes adds the REST API to mux. It serves the same service as the
+// JSON-RPC API for clients that would rather not speak JSON-RPC, such as web
+// dashboards.
+func (s *HTTPServer) restRoutes(mux *http.ServeMux) {
+	route := func(pattern string, handler http.HandlerFunc) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if !s.authorized(r) {
+				restError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+				return
+			}
+			handler(w, r)
+		})
+	}
+
+	route("GET "+RESTPrefix+"/items", s.restListItems)
+	route("GET "+RESTPrefix+"/items/{kind}", s.restListItems)
+	route("GET "+RESTPrefix+"/items/{kind}/{name}", s.restGetItem)
+	route("GET "+RESTPrefix+"/runs", s.restRuns(s.restListRuns))
+	route("POST "+RESTPrefix+"/runs", s.restRuns(s.restStartRun))
+	route("GET "+RESTPrefix+"/runs/{id}", s.restRuns(s.restGetRun))
+	route("POST "+RESTPrefix+"/runs/{id}/cancel", s.restRuns(s.restCancelRun))
+	route("GET "+RESTPrefix+"/runs/{id}/events", s.restRuns(s.restRunEvents))
+	route("GET "+RESTPrefix+"/runs/{id}/logs", s.restRuns(s.restRunLogs))
+	route("GET "+RESTPrefix+"/runs/{id}/artifacts", s.restListArtifacts)
+	route("GET "+RESTPrefix+"/runs/{id}/artifacts/{path...}", s.restGetArtifact)
+	route("GET "+RESTPrefix+"/history", s.restHistory)
+	route("GET "+RESTPrefix+"/history/{id}", s.restHistoryRecord)
+}
+
+// restRuns rejects run requests when the service has no workflow manager
+func (s *HTTPServer) restRuns(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.service.Runs() == nil {
+			restError(w, http.StatusServiceUnavailable, fmt.Errorf("workflow runs are not available"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *HTTPServer) restListItems(w http.ResponseWriter, r *http.Request) {
+	items, err := s.service.ListItems(r.PathValue("kind"))
+	if err != nil {
+		restError(w, http.StatusBadRequest, err)
+		return
+	}
+	restJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+}
+
+func (s *HTTPServer) restGetItem(w http.ResponseWriter, r *http.Request) {
+	kind := r.PathValue("kind")
+	if err := checkKind(kind); err != nil {
+		restError(w, http.StatusBadRequest, err)
+		return
+	}
+	item, err := s.service.GetItem(kind, r.PathValue("name"))
+	if err != nil {
+		restError(w, http.StatusNotFound, err)
+		return
+	}
+	restJSON(w, http.StatusOK, item)
+}
+
+func (s *HTTPServer) restListRuns(w http.ResponseWriter, r *http.Request) {
+	restJSON(w, http.StatusOK, map[string]interface{}{"runs": s.service.Runs().List()})
+}
+
+func (s *HTTPServer) restStartRun(w http.ResponseWriter, r *http.Request) {
+	var p runParams
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&p); err != nil {
+		restError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if p.Workflow == "" {
+		restError(w, http.StatusBadRequest, fmt.Errorf("workflow is required"))
+		return
+	}
+	if s.service.findFile(KindWorkflow, p.Workflow) == "" {
+		restError(w, http.StatusNotFound, fmt.Errorf("workflow '%s' not found", p.Workflow))
+		return
+	}
+	run := s.service.Runs().Start(p.Workflow, p.Variables)
+	w.Header().Set("Location", RESTPrefix+"/runs/"+run.ID)
+	restJSON(w, http.StatusAccepted, run)
+}
+
+func (s *HTTPServer) restGetRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.service.Runs().Get(r.PathValue("id"))
+	if !ok {
+		restError(w, http.StatusNotFound, fmt.Errorf("unknown run: %s", r.PathValue("id")))
+		return
+	}
+	restJSON(w, http.StatusOK, run)
+}
+
+func (s *HTTPServer) restCancelRun(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.service.Runs().Get(id); !ok {
+		restError(w, http.StatusNotFound, fmt.Errorf("unknown run: %s", id))
+		return
+	}
+	if err := s.service.Runs().Cancel(id); err != nil {
+		restError(w, http.StatusConflict, err)
+		return
+	}
+	run, _ := s.service.Runs().Get(id)
+	restJSON(w, http.StatusOK, run)
+}
+
+// restRunEvents streams a run's events as server-sent events: the events it
+// has had so far, then new ones as they happen. The stream ends with a done
+// event carrying the finished run.
+func (s *HTTPServer) restRunEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	past, events, stop, ok := s.service.Runs().Follow(id)
+	if !ok {
+		restError(w, http.StatusNotFound, fmt.Errorf("unknown run: %s", id))
+		return
+	}
+	defer stop()
+
+	// Streams outlive the server's write timeout
+	controller := http.NewResponseController(w)
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(name string, data interface{}) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	for _, event := range past {
+		if err := send("event", event); err != nil {
+			return
+		}
+	}
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, open := <-events:
+			if !open {
+				run, _ := s.service.Runs().Get(id)
+				_ = send("done", run)
+				return
+			}
+			if err := send("event", event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || controller.Flush() != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *HTTPServer) restRunLogs(w http.ResponseWriter, r *http.Request) {
+	logs, ok := s.service.Runs().Logs(r.PathValue("id"))
+	if !ok {
+		restError(w, http.StatusNotFound, fmt.Errorf("unknown run: %s", r.PathValue("id")))
+		return
+	}
+	restJSON(w, http.StatusOK, map[string]interface{}{"logs": logs})
+}
+
+func (s *HTTPServer) restHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			restError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", value))
+			return
+		}
+		limit = n
+	}
+	runs, err := s.service.History(limit)
+	if err != nil {
+		restError(w, http.StatusInternalServerError, err)
+		return
+	}
+	restJSON(w, http.StatusOK, map[string]interface{}{"runs": runs})
+}
+
+func (s *HTTPServer) restHistoryRecord(w http.ResponseWriter, r *http.Request) {
+	record, err := s.service.HistoryRecord(r.PathValue("id"))
+	if err != nil {
+		restError(w, http.StatusNotFound, err)
+		return
+	}
+	restJSON(w, http.StatusOK, record)
+}
+
+func (s *HTTPServer) restListArtifacts(w http.ResponseWriter, r *http.Request) {
+	artifacts, err := s.service.ListArtifacts(r.PathValue("id"))
+	if err != nil {
+		restError(w, http.StatusNotFound, err)
+		return
+	}
+	restJSON(w, http.StatusOK, map[string]interface{}{"artifacts": artifacts})
+}
+
+func (s *HTTPServer) restGetArtifact(w http.ResponseWriter, r *http.Request) {
+	path, err := s.service.ArtifactPath(r.PathValue("id"), r.PathValue("path"))
+	if err != nil {
+		restError(w, http.StatusNotFound, err)
+		return
+	}
+	http.ServeFile(w, r, path)
+}
+
+// restJSON writes a JSON response
+func restJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// restError writes an error as {"error": message}
+func restError(w http.ResponseWriter, status int, err error) {
+	restJSON(w, status, map[string]string{"error": err.Error()})
+}
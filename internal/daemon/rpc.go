@@ -0,0 +1,251 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000
+)
+
+// Methods served by the daemon
+const (
+	MethodItemsList   = "items/list"
+	MethodItemsGet    = "items/get"
+	MethodItemsSave   = "items/save"
+	MethodItemsDelete = "items/delete"
+	MethodValidate    = "validate"
+	MethodRunsStart   = "runs/start"
+	MethodRunsGet     = "runs/get"
+	MethodRunsList    = "runs/list"
+	MethodRunsCancel  = "runs/cancel"
+)
+
+// RPCError is a JSON-RPC error
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// rpcRequest is a JSON-RPC request or notification
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether the request expects no response
+func (r *rpcRequest) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+// rpcResponse is a JSON-RPC response. Result is kept raw so that a null
+// result is still sent.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// newResponse builds the response to a request from a method's result
+func newResponse(id json.RawMessage, result interface{}, err error) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	response := &rpcResponse{JSONRPC: "2.0", ID: id}
+	if err != nil {
+		rpcErr, ok := err.(*RPCError)
+		if !ok {
+			rpcErr = &RPCError{Code: CodeServerError, Message: err.Error()}
+		}
+		response.Error = rpcErr
+		return response
+	}
+
+	data, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		response.Error = &RPCError{Code: CodeServerError, Message: marshalErr.Error()}
+		return response
+	}
+	response.Result = data
+	return response
+}
+
+type itemParams struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Content    string `json:"content"`
+	ProjectDir string `json:"project_dir"`
+}
+
+type runParams struct {
+	ID        string                 `json:"id"`
+	Workflow  string                 `json:"workflow"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// Call runs a daemon method with JSON encoded params
+func (s *Service) Call(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case MethodItemsList:
+		var p itemParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		items, err := s.ListItems(p.Kind)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"items": items}, nil
+
+	case MethodItemsGet:
+		var p itemParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := requireParams("kind", p.Kind, "name", p.Name); err != nil {
+			return nil, err
+		}
+		return s.GetItem(p.Kind, p.Name)
+
+	case MethodItemsSave:
+		var p itemParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := requireParams("kind", p.Kind, "name", p.Name); err != nil {
+			return nil, err
+		}
+		item, diagnostics, err := s.SaveItem(p.Kind, p.Name, p.Content)
+		if err != nil {
+			if diagnostics != nil {
+				return nil, &RPCError{Code: CodeServerError, Message: err.Error(), Data: map[string]interface{}{"diagnostics": diagnostics}}
+			}
+			return nil, err
+		}
+		return map[string]interface{}{"item": item, "diagnostics": diagnostics}, nil
+
+	case MethodItemsDelete:
+		var p itemParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := requireParams("kind", p.Kind, "name", p.Name); err != nil {
+			return nil, err
+		}
+		report, err := s.DeleteItem(p.Kind, p.Name, p.ProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"deleted": true, "removed_commands": report.SlashCommands, "removed_files": report.Files}, nil
+
+	case MethodValidate:
+		var p itemParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := requireParams("kind", p.Kind); err != nil {
+			return nil, err
+		}
+		diagnostics, err := s.Validate(p.Kind, p.Content)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"diagnostics": diagnostics}, nil
+
+	case MethodRunsStart, MethodRunsGet, MethodRunsList, MethodRunsCancel:
+		if s.runs == nil {
+			return nil, fmt.Errorf("workflow runs are not available")
+		}
+		return s.callRuns(method, params)
+	}
+
+	return nil, &RPCError{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+}
+
+func (s *Service) callRuns(method string, params json.RawMessage) (interface{}, error) {
+	var p runParams
+	switch method {
+	case MethodRunsStart:
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := requireParams("workflow", p.Workflow); err != nil {
+			return nil, err
+		}
+		if s.findFile(KindWorkflow, p.Workflow) == "" {
+			return nil, fmt.Errorf("workflow '%s' not found", p.Workflow)
+		}
+		return s.runs.Start(p.Workflow, p.Variables), nil
+
+	case MethodRunsGet, MethodRunsCancel:
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := requireParams("id", p.ID); err != nil {
+			return nil, err
+		}
+		if method == MethodRunsCancel {
+			if err := s.runs.Cancel(p.ID); err != nil {
+				return nil, err
+			}
+		}
+		run, ok := s.runs.Get(p.ID)
+		if !ok {
+			return nil, fmt.Errorf("unknown run: %s", p.ID)
+		}
+		return run, nil
+	}
+
+	return map[string]interface{}{"runs": s.runs.List()}, nil
+}
+
+// decodeParams unmarshals a method's params into v
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, v); err != nil {
+			return &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+		}
+	}
+	return nil
+}
+
+// requireParams checks that params given as name, value pairs are set
+func requireParams(fields ...string) error {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] == "" {
+			return &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("missing param: %s", fields[i])}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+const (
+	// runRetention is how long finished runs are kept for status queries
+	runRetention = time.Hour
+
+	// maxRunEvents bounds the events kept for each run
+	maxRunEvents = 200
+
+	// maxAgentLog bounds the output kept for each agent of a run, in bytes
+	maxAgentLog = 64 << 10
+)
+
+// RunStatus is the state of a workflow run started through the daemon
+type RunStatus string
+
+const (
+	RunQueued    RunStatus = "queued"
+	RunRunning   RunStatus = "running"
+	RunCompleted RunStatus = "completed"
+	RunFailed    RunStatus = "failed"
+	RunCancelled RunStatus = "cancelled"
+)
+
+// Run is a workflow run started through the daemon
+type Run struct {
+	ID        string                   `json:"id"`
+	Workflow  string                   `json:"workflow"`
+	Status    RunStatus                `json:"status"`
+	Progress  int                      `json:"progress"`
+	Total     int                      `json:"total,omitempty"`
+	Message   string                   `json:"message,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+	StartTime time.Time                `json:"start_time"`
+	EndTime   *time.Time               `json:"end_time,omitempty"`
+	Events    []workflow.WorkflowEvent `json:"events,omitempty"`
+	// QueuePosition is the run's place in the workflow queue while it is queued
+	QueuePosition int `json:"queue_position,omitempty"`
+	// RunID is the ID Opun gave the run, as in 'opun status' and the run history
+	RunID string `json:"run_id,omitempty"`
+	// OutputDir is where the run writes its artifacts
+	OutputDir string `json:"output_dir,omitempty"`
+	// logs is the end of each agent's terminal output, by agent ID
+	logs map[string]string
+}
+
+// RunFunc executes a workflow by name, reporting progress through onEvent
+type RunFunc func(ctx context.Context, name string, variables map[string]interface{}, onEvent func(workflow.WorkflowEvent)) (interface{}, error)
+
+// RunListener is called for every event of every run
+type RunListener func(runID string, event workflow.WorkflowEvent)
+
+// RunManager starts workflow runs in the background and tracks them
+type RunManager struct {
+	mu        sync.Mutex
+	execute   RunFunc
+	runs      map[string]*Run
+	cancels   map[string]context.CancelFunc
+	listeners map[int]RunListener
+	followers map[int]*follower
+	counter   int
+	metrics   *runMetrics
+}
+
+// NewRunManager creates a run manager that executes workflows with execute
+func NewRunManager(execute RunFunc) *RunManager {
+	return &RunManager{
+		execute:   execute,
+		runs:      make(map[string]*Run),
+		cancels:   make(map[string]context.CancelFunc),
+		listeners: make(map[int]RunListener),
+		followers: make(map[int]*follower),
+		metrics:   newRunMetrics(),
+	}
+}
+
+// Start runs a workflow in the background and returns immediately
+func (m *RunManager) Start(name string, variables map[string]interface{}) Run {
+	m.mu.Lock()
+	m.prune()
+	m.counter++
+	id := fmt.Sprintf("run-%d-%d", time.Now().Unix(), m.counter)
+	run := &Run{
+		ID:        id,
+		Workflow:  name,
+		Status:    RunRunning,
+		StartTime: time.Now(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.runs[id] = run
+	m.cancels[id] = cancel
+	snapshot := *run
+	m.mu.Unlock()
+	m.metrics.runStarted()
+
+	go func() {
+		defer utils.CleanupOnPanic()
+		defer cancel()
+		_, err := m.execute(ctx, name, variables, func(event workflow.WorkflowEvent) {
+			m.record(id, event)
+		})
+		m.finish(id, err, ctx.Err() != nil)
+	}()
+
+	return snapshot
+}
+
+// Get returns a snapshot of a run, including its events
+func (m *RunManager) Get(id string) (Run, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, exists := m.runs[id]
+	if !exists {
+		return Run{}, false
+	}
+	snapshot := *run
+	snapshot.Events = append([]workflow.WorkflowEvent(nil), run.Events...)
+	return snapshot, true
+}
+
+// List returns snapshots of all known runs without their events, oldest first
+func (m *RunManager) List() []Run {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	runs := make([]Run, 0, len(m.runs))
+	for _, run := range m.runs {
+		snapshot := *run
+		snapshot.Events = nil
+		runs = append(runs, snapshot)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartTime.Before(runs[j].StartTime)
+	})
+	return runs
+}
+
+// Cancel stops a running workflow
+func (m *RunManager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, exists := m.runs[id]
+	if !exists {
+		return fmt.Errorf("unknown run: %s", id)
+	}
+	if run.Status != RunRunning && run.Status != RunQueued {
+		return fmt.Errorf("run %s is already %s", id, run.Status)
+	}
+	m.cancels[id]()
+	return nil
+}
+
+// Subscribe registers a listener for run events and returns a function that
+// removes it
+func (m *RunManager) Subscribe(listener RunListener) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counter++
+	key := m.counter
+	m.listeners[key] = listener
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.listeners, key)
+	}
+}
+
+// follower receives the events of one run until it finishes
+type follower struct {
+	runID  string
+	events chan workflow.WorkflowEvent
+}
+
+// Follow returns the events a run has had so far and a channel of its later
+// events, which is closed when the run finishes. stop ends following early.
+// Events are dropped for followers that fall maxRunEvents behind.
+func (m *RunManager) Follow(id string) (past []workflow.WorkflowEvent, events <-chan workflow.WorkflowEvent, stop func(), ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, exists := m.runs[id]
+	if !exists {
+		return nil, nil, nil, false
+	}
+	past = append([]workflow.WorkflowEvent(nil), run.Events...)
+	ch := make(chan workflow.WorkflowEvent, maxRunEvents)
+	if run.EndTime != nil {
+		close(ch)
+		return past, ch, func() {}, true
+	}
+
+	m.counter++
+	key := m.counter
+	m.followers[key] = &follower{runID: id, events: ch}
+	return past, ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, following := m.followers[key]; following {
+			delete(m.followers, key)
+			close(ch)
+		}
+	}, true
+}
+
+// record stores an event and updates the run's progress
+func (m *RunManager) record(id string, event workflow.WorkflowEvent) {
+	m.mu.Lock()
+	run, exists := m.runs[id]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+
+	// Output is kept apart from the events, so it doesn't push them out
+	if event.Type == workflow.EventOutputChunk {
+		text, _ := event.Data["text"].(string)
+		text = cleanOutput(text)
+		if run.logs == nil {
+			run.logs = make(map[string]string)
+		}
+		log := run.logs[event.AgentID] + text
+		if len(log) > maxAgentLog {
+			log = strings.ToValidUTF8(log[len(log)-maxAgentLog:], "")
+		}
+		run.logs[event.AgentID] = log
+		event.Data = map[string]interface{}{"text": text}
+		m.notifyFollowers(id, event)
+		m.mu.Unlock()
+		return
+	}
+
+	switch event.Type {
+	case workflow.EventWorkflowQueued:
+		run.Status = RunQueued
+		run.QueuePosition, _ = event.Data["position"].(int)
+	case workflow.EventWorkflowStart:
+		run.Status = RunRunning
+		run.QueuePosition = 0
+		if n, ok := event.Data["total_agents"].(int); ok {
+			run.Total = n
+		}
+		run.RunID, _ = event.Data["run_id"].(string)
+		run.OutputDir, _ = event.Data["output_dir"].(string)
+	case workflow.EventAgentComplete, workflow.EventAgentError:
+		run.Progress++
+	}
+	run.Message = event.Message
+	run.Events = append(run.Events, event)
+	if len(run.Events) > maxRunEvents {
+		run.Events = run.Events[len(run.Events)-maxRunEvents:]
+	}
+
+	listeners := make([]RunListener, 0, len(m.listeners))
+	for _, listener := range m.listeners {
+		listeners = append(listeners, listener)
+	}
+	m.notifyFollowers(id, event)
+	m.mu.Unlock()
+
+	m.metrics.observe(id, event)
+	for _, listener := range listeners {
+		listener(id, event)
+	}
+}
+
+// notifyFollowers sends an event to the run's followers; the caller holds mu
+func (m *RunManager) notifyFollowers(id string, event workflow.WorkflowEvent) {
+	for _, f := range m.followers {
+		if f.runID != id {
+			continue
+		}
+		select {
+		case f.events <- event:
+		default:
+		}
+	}
+}
+
+// Logs returns the end of each agent's terminal output, by agent ID. Output
+// is only kept for runs that report it as output_chunk events.
+func (m *RunManager) Logs(id string) (map[string]string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, exists := m.runs[id]
+	if !exists {
+		return nil, false
+	}
+	logs := make(map[string]string, len(run.logs))
+	for agent, log := range run.logs {
+		logs[agent] = log
+	}
+	return logs, true
+}
+
+// cleanOutput turns terminal output into plain text lines
+func cleanOutput(text string) string {
+	text = providers.StripANSI(text)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// finish marks a run as completed, failed or cancelled
+func (m *RunManager) finish(id string, err error, cancelled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, exists := m.runs[id]
+	if !exists {
+		return
+	}
+
+	endTime := time.Now()
+	run.EndTime = &endTime
+	run.QueuePosition = 0
+	delete(m.cancels, id)
+	for key, f := range m.followers {
+		if f.runID == id {
+			delete(m.followers, key)
+			close(f.events)
+		}
+	}
+
+	switch {
+	case cancelled:
+		run.Status = RunCancelled
+	case err != nil:
+		run.Status = RunFailed
+		run.Error = err.Error()
+	default:
+		run.Status = RunCompleted
+		if run.Total > 0 {
+			run.Progress = run.Total
+		}
+	}
+	m.metrics.runFinished(id, run.Status)
+}
+
+// prune removes finished runs older than the retention period.
+// Callers must hold the lock.
+func (m *RunManager) prune() {
+	cutoff := time.Now().Add(-runRetention)
+	for id, run := range m.runs {
+		if run.EndTime != nil && run.EndTime.Before(cutoff) {
+			delete(m.runs, id)
+		}
+	}
+}
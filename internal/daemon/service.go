@@ -0,0 +1,361 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Item kinds served by the daemon
+const (
+	KindWorkflow = "workflow"
+	KindPrompt   = "prompt"
+	KindAction   = "action"
+	KindSubagent = "subagent"
+)
+
+// Kinds lists the item kinds in display order
+var Kinds = []string{KindWorkflow, KindPrompt, KindAction, KindSubagent}
+
+// itemDirs maps file based item kinds to their directory under ~/.opun
+var itemDirs = map[string]string{
+	KindWorkflow: "workflows",
+	KindAction:   "actions",
+	KindSubagent: "subagents",
+}
+
+// Item is a workflow, prompt, action or subagent definition
+type Item struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Content     string `json:"content,omitempty"`
+}
+
+// Service implements the daemon API on top of an Opun home directory
+type Service struct {
+	opunDir string
+	workDir string
+	garden  *promptgarden.Garden
+	runs    *RunManager
+
+	// mu serializes changes to item definitions
+	mu sync.Mutex
+}
+
+// NewService creates a service for the items in opunDir (normally ~/.opun).
+// Workflow runs are only available when workflows is set.
+func NewService(opunDir string, garden *promptgarden.Garden, workflows *workflow.Manager) *Service {
+	workDir, _ := os.Getwd()
+
+	s := &Service{
+		opunDir: opunDir,
+		workDir: workDir,
+		garden:  garden,
+	}
+	if workflows != nil {
+		s.runs = NewRunManager(workflows.ExecuteWithProgress)
+	}
+	return s
+}
+
+// Runs returns the run manager, or nil when runs are unavailable
+func (s *Service) Runs() *RunManager {
+	return s.runs
+}
+
+// ListItems lists the items of a kind, or of every kind when kind is empty.
+// Item content is left out.
+func (s *Service) ListItems(kind string) ([]Item, error) {
+	kinds := Kinds
+	if kind != "" {
+		if err := checkKind(kind); err != nil {
+			return nil, err
+		}
+		kinds = []string{kind}
+	}
+
+	items := []Item{}
+	for _, k := range kinds {
+		var list []Item
+		var err error
+		if k == KindPrompt {
+			list, err = s.listPrompts()
+		} else {
+			list, err = s.listFiles(k)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, list...)
+	}
+	return items, nil
+}
+
+// GetItem returns an item with its content
+func (s *Service) GetItem(kind, name string) (Item, error) {
+	if err := checkKind(kind); err != nil {
+		return Item{}, err
+	}
+
+	if kind == KindPrompt {
+		if s.garden == nil {
+			return Item{}, fmt.Errorf("prompt garden not available")
+		}
+		prompt, err := s.garden.GetPrompt(name)
+		if err != nil {
+			return Item{}, fmt.Errorf("prompt '%s' not found", name)
+		}
+		return Item{
+			Kind:        KindPrompt,
+			Name:        prompt.Name,
+			Description: prompt.Metadata.Description,
+			Content:     prompt.Content,
+		}, nil
+	}
+
+	path := s.findFile(kind, name)
+	if path == "" {
+		return Item{}, fmt.Errorf("%s '%s' not found", kind, name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{
+		Kind:        kind,
+		Name:        name,
+		Description: yamlDescription(data),
+		Path:        path,
+		Content:     string(data),
+	}, nil
+}
+
+// SaveItem validates content and creates or replaces an item with it. Nothing
+// is written when validation reports errors; the diagnostics are returned
+// either way.
+func (s *Service) SaveItem(kind, name, content string) (Item, []Diagnostic, error) {
+	if err := checkKind(kind); err != nil {
+		return Item{}, nil, err
+	}
+	if err := checkName(name); err != nil {
+		return Item{}, nil, err
+	}
+
+	diagnostics, err := s.Validate(kind, content)
+	if err != nil {
+		return Item{}, nil, err
+	}
+	if HasErrors(diagnostics) {
+		return Item{}, diagnostics, fmt.Errorf("%s '%s' is invalid", kind, name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kind == KindPrompt {
+		if s.garden == nil {
+			return Item{}, diagnostics, fmt.Errorf("prompt garden not available")
+		}
+		prompt := &promptgarden.Prompt{ID: name, Name: name, Content: content}
+		if existing, err := s.garden.GetPrompt(name); err == nil {
+			prompt.Metadata = existing.Metadata
+		} else {
+			prompt.Metadata = promptgarden.PromptMetadata{Category: "user", Version: "1.0.0"}
+		}
+		if err := s.garden.SavePrompt(prompt); err != nil {
+			return Item{}, diagnostics, fmt.Errorf("failed to save prompt: %w", err)
+		}
+		return Item{Kind: kind, Name: name, Description: prompt.Metadata.Description}, diagnostics, nil
+	}
+
+	path := s.findFile(kind, name)
+	if path == "" {
+		path = filepath.Join(s.opunDir, itemDirs[kind], name+".yaml")
+	}
+	if err := utils.WriteFile(path, []byte(content)); err != nil {
+		return Item{}, diagnostics, fmt.Errorf("failed to save %s: %w", kind, err)
+	}
+	return Item{Kind: kind, Name: name, Description: yamlDescription([]byte(content)), Path: path}, diagnostics, nil
+}
+
+// DeleteItem removes an item along with the slash commands and command files
+// generated for it in projectDir (the daemon's directory when empty)
+func (s *Service) DeleteItem(kind, name, projectDir string) (*config.CleanupReport, error) {
+	if err := checkKind(kind); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kind == KindPrompt {
+		if s.garden == nil {
+			return nil, fmt.Errorf("prompt garden not available")
+		}
+		if _, err := s.garden.GetPrompt(name); err != nil {
+			return nil, fmt.Errorf("prompt '%s' not found", name)
+		}
+		if err := s.garden.DeletePrompt(name); err != nil {
+			return nil, fmt.Errorf("failed to delete prompt: %w", err)
+		}
+	} else {
+		path := s.findFile(kind, name)
+		if path == "" {
+			return nil, fmt.Errorf("%s '%s' not found", kind, name)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", kind, err)
+		}
+	}
+
+	// Subagents have no generated commands
+	if kind == KindSubagent {
+		return &config.CleanupReport{}, nil
+	}
+	if projectDir == "" {
+		projectDir = s.workDir
+	}
+	return config.RemoveGeneratedArtifacts(kind, name, projectDir)
+}
+
+// listFiles lists the definitions in a kind's directory
+func (s *Service) listFiles(kind string) ([]Item, error) {
+	dir := filepath.Join(s.opunDir, itemDirs[kind])
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() || !isConfigFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		items = append(items, Item{
+			Kind:        kind,
+			Name:        strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+			Description: yamlDescription(data),
+			Path:        path,
+		})
+	}
+	return items, nil
+}
+
+// listPrompts lists the prompt garden
+func (s *Service) listPrompts() ([]Item, error) {
+	if s.garden == nil {
+		return nil, nil
+	}
+	prompts, err := s.garden.ListPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(prompts))
+	for _, prompt := range prompts {
+		items = append(items, Item{
+			Kind:        KindPrompt,
+			Name:        prompt.Name,
+			Description: prompt.Metadata.Description,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+// findFile returns the definition file of a file based item, or "" if there is none
+func (s *Service) findFile(kind, name string) string {
+	if checkName(name) != nil {
+		return ""
+	}
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		path := filepath.Join(s.opunDir, itemDirs[kind], name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// promptExists reports whether the prompt garden has a prompt with this name or ID
+func (s *Service) promptExists(name string) bool {
+	if s.garden == nil {
+		return false
+	}
+	if _, err := s.garden.GetByName(name); err == nil {
+		return true
+	}
+	_, err := s.garden.Get(name)
+	return err == nil
+}
+
+func checkKind(kind string) error {
+	for _, k := range Kinds {
+		if k == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown item kind: %s (expected one of %s)", kind, strings.Join(Kinds, ", "))
+}
+
+// checkName rejects names that would escape the item directories
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid item name: %q", name)
+	}
+	return nil
+}
+
+func isConfigFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// yamlDescription returns the top level description of a YAML or JSON definition
+func yamlDescription(data []byte) string {
+	var doc struct {
+		Description string `yaml:"description"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return ""
+	}
+	return doc.Description
+}
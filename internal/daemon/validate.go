@@ -0,0 +1,250 @@
+package daemon
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic severities, as defined by the Language Server Protocol
+const (
+	SeverityError   = 1
+	SeverityWarning = 2
+)
+
+// Position is a zero based line and character offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of text in a document
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is a problem found in an item definition. The shape matches LSP
+// diagnostics so editors can display them directly.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+var (
+	// yamlLineRegexp finds the line number in YAML parse errors
+	yamlLineRegexp = regexp.MustCompile(`line (\d+):`)
+
+	// agentErrorRegexp finds the agent a workflow validation error is about
+	agentErrorRegexp = regexp.MustCompile(`(?:^|: )(?:agent|duplicate agent ID:) ([^\s:]+)`)
+
+	// promptRefRegexp matches prompt garden includes inside a prompt
+	promptRefRegexp = regexp.MustCompile(`\{\{\s*(?:include:|promptgarden://)([^}]+)\}\}`)
+)
+
+// Validate checks an item definition without saving it
+func (s *Service) Validate(kind, content string) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	switch kind {
+	case KindWorkflow:
+		diagnostics = s.validateWorkflow(content)
+	case KindPrompt:
+		diagnostics = s.validatePrompt(content)
+	case KindAction:
+		diagnostics = s.validateAction(content)
+	case KindSubagent:
+		diagnostics = validateSubagent(content)
+	default:
+		return nil, checkKind(kind)
+	}
+
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return diagnostics, nil
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) validateWorkflow(content string) []Diagnostic {
+	wf, err := workflow.NewParser(filepath.Join(s.opunDir, itemDirs[KindWorkflow])).Parse([]byte(content))
+	if err != nil {
+		return []Diagnostic{errorDiagnostic(content, err)}
+	}
+
+	var diagnostics []Diagnostic
+	for _, agent := range wf.Agents {
+		for _, ref := range append(promptReferences(agent.Prompt), promptReferences(agent.SystemPrompt)...) {
+			if !s.promptExists(ref) {
+				diagnostics = append(diagnostics, newDiagnostic(content, lineContaining(content, ref), SeverityWarning,
+					fmt.Sprintf("agent %s: prompt '%s' is not in the prompt garden", agent.ID, ref)))
+			}
+		}
+	}
+	return diagnostics
+}
+
+func (s *Service) validatePrompt(content string) []Diagnostic {
+	if strings.TrimSpace(content) == "" {
+		return []Diagnostic{newDiagnostic(content, 0, SeverityError, "prompt is empty")}
+	}
+
+	var diagnostics []Diagnostic
+	for _, ref := range promptReferences(content) {
+		if !s.promptExists(ref) {
+			diagnostics = append(diagnostics, newDiagnostic(content, lineContaining(content, ref), SeverityWarning,
+				fmt.Sprintf("included prompt '%s' is not in the prompt garden", ref)))
+		}
+	}
+	return diagnostics
+}
+
+func (s *Service) validateAction(content string) []Diagnostic {
+	var config tools.ToolConfig
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return []Diagnostic{errorDiagnostic(content, err)}
+	}
+
+	if config.Command == "" && config.WorkflowRef == "" && config.PromptRef == "" && len(config.Steps) == 0 {
+		return []Diagnostic{newDiagnostic(content, 0, SeverityError,
+			"action must have at least one execution method (command, steps, workflow, or prompt)")}
+	}
+	if err := tools.ValidateSteps(config.Steps); err != nil {
+		return []Diagnostic{newDiagnostic(content, lineContaining(content, "steps:"), SeverityError, err.Error())}
+	}
+
+	var diagnostics []Diagnostic
+	if config.PromptRef != "" && !s.promptExists(config.PromptRef) {
+		diagnostics = append(diagnostics, newDiagnostic(content, lineContaining(content, "prompt:"), SeverityWarning,
+			fmt.Sprintf("prompt '%s' is not in the prompt garden", config.PromptRef)))
+	}
+	if config.WorkflowRef != "" && s.findFile(KindWorkflow, config.WorkflowRef) == "" {
+		diagnostics = append(diagnostics, newDiagnostic(content, lineContaining(content, "workflow:"), SeverityWarning,
+			fmt.Sprintf("workflow '%s' does not exist", config.WorkflowRef)))
+	}
+	return diagnostics
+}
+
+func validateSubagent(content string) []Diagnostic {
+	var config core.SubAgentConfig
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return []Diagnostic{errorDiagnostic(content, err)}
+	}
+
+	var diagnostics []Diagnostic
+	if config.Name == "" {
+		diagnostics = append(diagnostics, newDiagnostic(content, 0, SeverityError, "subagent name is required"))
+	}
+	switch config.Provider {
+	case core.ProviderTypeClaude, core.ProviderTypeGemini, core.ProviderTypeQwen:
+	case "":
+		diagnostics = append(diagnostics, newDiagnostic(content, 0, SeverityError, "subagent provider is required"))
+	default:
+		diagnostics = append(diagnostics, newDiagnostic(content, lineContaining(content, "provider:"), SeverityError,
+			fmt.Sprintf("unsupported provider type: %s", config.Provider)))
+	}
+	return diagnostics
+}
+
+// errorDiagnostic turns a parse or validation error into a diagnostic on the
+// line it is about, when that can be worked out
+func errorDiagnostic(content string, err error) Diagnostic {
+	message := err.Error()
+	line := 0
+	if m := yamlLineRegexp.FindStringSubmatch(message); m != nil {
+		if n, convErr := strconv.Atoi(m[1]); convErr == nil && n > 0 {
+			line = n - 1
+		}
+	} else if m := agentErrorRegexp.FindStringSubmatch(message); m != nil {
+		// Duplicates are reported on the second definition
+		line = agentLine(content, m[1], strings.Contains(message, "duplicate"))
+	}
+	return newDiagnostic(content, line, SeverityError, message)
+}
+
+// newDiagnostic creates a diagnostic spanning a whole line
+func newDiagnostic(content string, line, severity int, message string) Diagnostic {
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		line = 0
+	}
+	return Diagnostic{
+		Range: Range{
+			Start: Position{Line: line},
+			End:   Position{Line: line, Character: len(strings.TrimRight(lines[line], "\r"))},
+		},
+		Severity: severity,
+		Source:   "opun",
+		Message:  message,
+	}
+}
+
+// agentLine finds the line where an agent with this ID is defined
+func agentLine(content, id string, last bool) int {
+	pattern := regexp.MustCompile(`^\s*(?:-\s*)?id:\s*["']?` + regexp.QuoteMeta(id) + `["']?\s*$`)
+	found := 0
+	for i, line := range strings.Split(content, "\n") {
+		if pattern.MatchString(strings.TrimRight(line, "\r")) {
+			found = i
+			if !last {
+				break
+			}
+		}
+	}
+	return found
+}
+
+// lineContaining returns the first line containing s, or 0
+func lineContaining(content, s string) int {
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, s) {
+			return i
+		}
+	}
+	return 0
+}
+
+// promptReferences returns the prompt garden prompts a prompt refers to
+func promptReferences(prompt string) []string {
+	var refs []string
+	if trimmed := strings.TrimSpace(prompt); strings.HasPrefix(trimmed, "promptgarden://") && !strings.ContainsAny(trimmed, " \n") {
+		refs = append(refs, strings.TrimPrefix(trimmed, "promptgarden://"))
+	}
+	for _, m := range promptRefRegexp.FindAllStringSubmatch(prompt, -1) {
+		refs = append(refs, strings.TrimSpace(m[1]))
+	}
+	return refs
+}
@@ -0,0 +1,156 @@
+package export
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Claude Code reads project commands and subagents from these directories
+const (
+	claudeCommandsDir = ".claude/commands"
+	claudeAgentsDir   = ".claude/agents"
+)
+
+// claudeModelAliases are the model values a Claude Code subagent accepts
+var claudeModelAliases = map[string]bool{"sonnet": true, "opus": true, "haiku": true}
+
+// ClaudeWorkflow converts a workflow into a Claude Code slash command that runs
+// each provider step through its own subagent
+func ClaudeWorkflow(wf *workflow.Workflow, resolve PromptResolver) ([]File, error) {
+	name := slug(wf.Name)
+	var files []File
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "description: %s\n", yamlString(workflowDescription(wf)))
+	if hint := workflowArgumentHint(wf); hint != "" {
+		fmt.Fprintf(&b, "argument-hint: %s\n", yamlString(hint))
+	}
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n", wf.Name)
+	if wf.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", wf.Description)
+	}
+	b.WriteString("Arguments: $ARGUMENTS\n\n")
+	writeVariables(&b, wf)
+
+	b.WriteString("## Steps\n\n")
+	b.WriteString("Run these steps in order. Delegate each agent step to the named subagent with the Task tool, ")
+	b.WriteString("passing along the outputs of the steps it depends on.\n\n")
+
+	for i, agent := range wf.Agents {
+		if text, ok := nonAgentStep(agent); ok {
+			fmt.Fprintf(&b, "%d. **%s**: %s\n", i+1, stepTitle(agent), text)
+			continue
+		}
+
+		agentName := name + "-" + slug(agent.ID)
+		fmt.Fprintf(&b, "%d. **%s**: use the `%s` subagent", i+1, stepTitle(agent), agentName)
+		if len(agent.DependsOn) > 0 {
+			fmt.Fprintf(&b, " with the output of %s", strings.Join(agent.DependsOn, ", "))
+		}
+		if agent.Output != "" {
+			fmt.Fprintf(&b, ", and save its result to `%s`", agent.Output)
+		}
+		b.WriteString(".\n")
+
+		prompt, err := agentInstructions(agent, resolve)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", agent.ID, err)
+		}
+		files = append(files, File{
+			Path:    path.Join(claudeAgentsDir, agentName+".md"),
+			Content: []byte(claudeAgent(agentName, wf, agent, prompt)),
+		})
+	}
+
+	files = append(files, File{
+		Path:    path.Join(claudeCommandsDir, name+".md"),
+		Content: []byte(b.String()),
+	})
+	return sortedFiles(files), nil
+}
+
+// claudeAgent renders a subagent definition for one workflow step
+func claudeAgent(agentName string, wf *workflow.Workflow, agent workflow.Agent, prompt string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "name: %s\n", agentName)
+	fmt.Fprintf(&b, "description: %s\n", yamlString(fmt.Sprintf("%s step of the %s workflow", stepTitle(agent), wf.Name)))
+	if agent.Provider == "claude" && claudeModelAliases[agent.Model] {
+		fmt.Fprintf(&b, "model: %s\n", agent.Model)
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(prompt))
+	b.WriteString("\n")
+	if agent.Provider != "" && agent.Provider != "claude" {
+		fmt.Fprintf(&b, "\n<!-- Exported from a %s step; review the instructions for Claude. -->\n", agent.Provider)
+	}
+	return b.String()
+}
+
+// ClaudePrompt converts a prompt into a Claude Code slash command
+func ClaudePrompt(prompt core.Prompt, resolve PromptResolver) ([]File, error) {
+	content, err := ResolvePrompt(prompt.Content(), resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "description: %s\n", yamlString(promptDescription(prompt)))
+	if hint := promptArgumentHint(prompt); hint != "" {
+		fmt.Fprintf(&b, "argument-hint: %s\n", yamlString(hint))
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(content))
+	b.WriteString("\n")
+	if !strings.Contains(content, "$ARGUMENTS") {
+		b.WriteString("\nArguments: $ARGUMENTS\n")
+	}
+
+	return []File{{
+		Path:    path.Join(claudeCommandsDir, slug(prompt.Name())+".md"),
+		Content: []byte(b.String()),
+	}}, nil
+}
+
+func workflowDescription(wf *workflow.Workflow) string {
+	if wf.Description != "" {
+		return wf.Description
+	}
+	return fmt.Sprintf("Run the %s workflow", wf.Name)
+}
+
+func promptDescription(prompt core.Prompt) string {
+	if description := prompt.Metadata().Description; description != "" {
+		return description
+	}
+	return fmt.Sprintf("Run the %s prompt", prompt.Name())
+}
+
+// yamlString quotes s for a single line YAML scalar
+func yamlString(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
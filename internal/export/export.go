@@ -0,0 +1,246 @@
+package export
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// File is a generated file, relative to the export directory
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// PromptResolver returns the content of a prompt garden prompt
+type PromptResolver func(name string) (string, error)
+
+// maxIncludeDepth bounds nested prompt includes so cycles terminate
+const maxIncludeDepth = 10
+
+var includeRegex = regexp.MustCompile(`\{\{\s*(?:include:|promptgarden://)([^}]+)\}\}`)
+
+// ResolvePrompt inlines prompt garden references so exported artifacts work
+// without Opun: a prompt that is just promptgarden://name is replaced by that
+// prompt, and {{include:name}} directives by the included content
+func ResolvePrompt(prompt string, resolve PromptResolver) (string, error) {
+	return resolvePrompt(prompt, resolve, 0)
+}
+
+func resolvePrompt(prompt string, resolve PromptResolver, depth int) (string, error) {
+	if resolve == nil {
+		return prompt, nil
+	}
+	if depth > maxIncludeDepth {
+		return "", fmt.Errorf("prompt includes nest deeper than %d levels", maxIncludeDepth)
+	}
+
+	trimmed := strings.TrimSpace(prompt)
+	if strings.HasPrefix(trimmed, "promptgarden://") && !strings.ContainsAny(trimmed, " \n") {
+		content, err := resolve(strings.TrimPrefix(trimmed, "promptgarden://"))
+		if err != nil {
+			return "", err
+		}
+		return resolvePrompt(content, resolve, depth+1)
+	}
+
+	var resolveErr error
+	result := includeRegex.ReplaceAllStringFunc(prompt, func(match string) string {
+		name := strings.TrimSpace(includeRegex.FindStringSubmatch(match)[1])
+		content, err := resolve(name)
+		if err == nil {
+			content, err = resolvePrompt(content, resolve, depth+1)
+		}
+		if err != nil && resolveErr == nil {
+			resolveErr = fmt.Errorf("failed to include prompt %s: %w", name, err)
+		}
+		return content
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
+
+// agentInstructions returns an agent's resolved prompt, preceded by its system
+// prompt when it has one
+func agentInstructions(agent workflow.Agent, resolve PromptResolver) (string, error) {
+	prompt, err := ResolvePrompt(agent.Prompt, resolve)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(agent.SystemPrompt) == "" {
+		return prompt, nil
+	}
+	systemPrompt, err := ResolvePrompt(agent.SystemPrompt, resolve)
+	if err != nil {
+		return "", fmt.Errorf("system prompt: %w", err)
+	}
+	return strings.TrimSpace(systemPrompt) + "\n\n" + strings.TrimSpace(prompt), nil
+}
+
+// Write writes files under dir. Existing files are only replaced when force is
+// set, and nothing is written if any would be refused.
+func Write(dir string, files []File, force bool) ([]string, error) {
+	if !force {
+		var existing []string
+		for _, file := range files {
+			if _, err := os.Stat(filepath.Join(dir, file.Path)); err == nil {
+				existing = append(existing, file.Path)
+			}
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("refusing to overwrite %s (use --force)", strings.Join(existing, ", "))
+		}
+	}
+
+	var written []string
+	for _, file := range files {
+		path := filepath.Join(dir, file.Path)
+		if err := utils.WriteFile(path, file.Content); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// slug turns a name into a file name safe identifier
+func slug(name string) string {
+	s := strings.ToLower(strings.TrimSpace(name))
+	s = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
+
+// workflowArguments lists the variables a user supplies when running a workflow
+func workflowArguments(wf *workflow.Workflow) []workflow.Variable {
+	var vars []workflow.Variable
+	for _, v := range wf.Variables {
+		if !v.Internal {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// argumentHint renders variables as <name> placeholders, optional ones in brackets
+func argumentHint(names []string, required map[string]bool) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		if required[name] {
+			parts[i] = "<" + name + ">"
+		} else {
+			parts[i] = "[" + name + "]"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func workflowArgumentHint(wf *workflow.Workflow) string {
+	var names []string
+	required := make(map[string]bool)
+	for _, v := range workflowArguments(wf) {
+		names = append(names, v.Name)
+		required[v.Name] = v.Required
+	}
+	return argumentHint(names, required)
+}
+
+func promptArgumentHint(prompt core.Prompt) string {
+	var names []string
+	required := make(map[string]bool)
+	for _, v := range prompt.Variables() {
+		names = append(names, v.Name)
+		required[v.Name] = v.Required
+	}
+	return argumentHint(names, required)
+}
+
+// writeVariables documents the workflow variables as a markdown list
+func writeVariables(b *strings.Builder, wf *workflow.Workflow) {
+	vars := workflowArguments(wf)
+	if len(vars) == 0 {
+		return
+	}
+
+	b.WriteString("## Variables\n\n")
+	for _, v := range vars {
+		fmt.Fprintf(b, "- `%s`", v.Name)
+		if v.Description != "" {
+			fmt.Fprintf(b, ": %s", v.Description)
+		}
+		if v.Required {
+			b.WriteString(" (required)")
+		} else if v.DefaultValue != nil {
+			fmt.Fprintf(b, " (default: %v)", v.DefaultValue)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nWherever a step mentions `{{name}}`, substitute the value of that variable.\n\n")
+}
+
+// stepTitle returns the display name of a workflow step
+func stepTitle(agent workflow.Agent) string {
+	if agent.Name != "" {
+		return agent.Name
+	}
+	return agent.ID
+}
+
+// nonAgentStep describes wait and input steps, which have no prompt
+func nonAgentStep(agent workflow.Agent) (string, bool) {
+	switch agent.Type {
+	case workflow.StepTypeWait:
+		var parts []string
+		if agent.Duration != "" {
+			parts = append(parts, "wait "+agent.Duration)
+		}
+		if agent.Until != nil {
+			if agent.Until.Command != "" {
+				parts = append(parts, fmt.Sprintf("wait until `%s` succeeds", agent.Until.Command))
+			}
+			if agent.Until.File != "" {
+				parts = append(parts, fmt.Sprintf("wait until `%s` exists", agent.Until.File))
+			}
+		}
+		return strings.Join(parts, ", then "), true
+	case workflow.StepTypeInput:
+		text := fmt.Sprintf("ask the user: %s", strings.TrimSpace(agent.Prompt))
+		if len(agent.Options) > 0 {
+			text += fmt.Sprintf(" (options: %s)", strings.Join(agent.Options, ", "))
+		}
+		if agent.Variable != "" {
+			text += fmt.Sprintf(", and use the answer as `{{%s}}`", agent.Variable)
+		}
+		return text, true
+	}
+	return "", false
+}
+
+func sortedFiles(files []File) []File {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
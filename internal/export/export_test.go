@@ -0,0 +1,196 @@
+package export
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPrompt is a core.Prompt whose content is used as is
+type testPrompt struct {
+	*core.BasePrompt
+}
+
+func newTestPrompt(metadata core.PromptMetadata, content string) core.Prompt {
+	return testPrompt{core.NewBasePrompt(metadata, content)}
+}
+
+func (p testPrompt) Template(map[string]interface{}) (string, error) { return p.Content(), nil }
+
+func (p testPrompt) Validate(map[string]interface{}) error { return nil }
+
+func testResolver(prompts map[string]string) PromptResolver {
+	return func(name string) (string, error) {
+		content, ok := prompts[name]
+		if !ok {
+			return "", os.ErrNotExist
+		}
+		return content, nil
+	}
+}
+
+func testWorkflow() *workflow.Workflow {
+	return &workflow.Workflow{
+		Name:        "Code Review",
+		Description: "Review and fix a change",
+		Variables: []workflow.Variable{
+			{Name: "target", Description: "What to review", Required: true},
+			{Name: "style", DefaultValue: "strict"},
+			{Name: "run_id", Internal: true},
+		},
+		Agents: []workflow.Agent{
+			{ID: "review", Provider: "claude", Model: "opus", Prompt: "promptgarden://reviewer"},
+			{ID: "approve", Type: workflow.StepTypeInput, Prompt: "Apply the fixes?", Options: []string{"yes", "no"}, Variable: "apply"},
+			{ID: "fix", Name: "Fix issues", Provider: "gemini", Model: "gemini-pro", SystemPrompt: "You are a careful engineer.", Prompt: "Fix {{target}}", DependsOn: []string{"review"}, Output: "fix.md"},
+		},
+	}
+}
+
+func filesByPath(files []File) map[string]string {
+	m := make(map[string]string)
+	for _, f := range files {
+		m[f.Path] = string(f.Content)
+	}
+	return m
+}
+
+func TestResolvePrompt(t *testing.T) {
+	resolve := testResolver(map[string]string{
+		"base":  "Be careful. {{include:tone}}",
+		"tone":  "Be kind.",
+		"loop":  "{{include:loop}}",
+		"outer": "promptgarden://base",
+	})
+
+	out, err := ResolvePrompt("promptgarden://outer", resolve)
+	require.NoError(t, err)
+	assert.Equal(t, "Be careful. Be kind.", out)
+
+	out, err = ResolvePrompt("Start. {{promptgarden://tone}} End {{target}}", resolve)
+	require.NoError(t, err)
+	assert.Equal(t, "Start. Be kind. End {{target}}", out)
+
+	_, err = ResolvePrompt("{{include:missing}}", resolve)
+	assert.Error(t, err)
+
+	_, err = ResolvePrompt("{{include:loop}}", resolve)
+	assert.Error(t, err)
+}
+
+func TestClaudeWorkflow(t *testing.T) {
+	files, err := ClaudeWorkflow(testWorkflow(), testResolver(map[string]string{"reviewer": "Review {{target}} thoroughly."}))
+	require.NoError(t, err)
+
+	byPath := filesByPath(files)
+	require.Len(t, byPath, 3)
+
+	command := byPath[".claude/commands/code-review.md"]
+	assert.Contains(t, command, `description: "Review and fix a change"`)
+	assert.Contains(t, command, `argument-hint: "<target> [style]"`)
+	assert.NotContains(t, command, "run_id")
+	assert.Contains(t, command, "1. **review**: use the `code-review-review` subagent.")
+	assert.Contains(t, command, "2. **approve**: ask the user: Apply the fixes? (options: yes, no)")
+	assert.Contains(t, command, "with the output of review, and save its result to `fix.md`")
+
+	review := byPath[".claude/agents/code-review-review.md"]
+	assert.Contains(t, review, "name: code-review-review\n")
+	assert.Contains(t, review, "model: opus\n")
+	assert.Contains(t, review, "Review {{target}} thoroughly.")
+
+	fix := byPath[".claude/agents/code-review-fix.md"]
+	assert.NotContains(t, fix, "model:")
+	assert.Contains(t, fix, "You are a careful engineer.\n\nFix {{target}}")
+	assert.Contains(t, fix, "Exported from a gemini step")
+}
+
+func TestClaudePrompt(t *testing.T) {
+	prompt := newTestPrompt(core.PromptMetadata{
+		Name:      "Refactor",
+		Variables: []core.PromptVariable{{Name: "file", Required: true}},
+	}, "Refactor {{file}}")
+
+	files, err := ClaudePrompt(prompt, nil)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".claude/commands/refactor.md", files[0].Path)
+
+	content := string(files[0].Content)
+	assert.Contains(t, content, `description: "Run the Refactor prompt"`)
+	assert.Contains(t, content, `argument-hint: "<file>"`)
+	assert.Contains(t, content, "Arguments: $ARGUMENTS")
+}
+
+func TestGeminiWorkflow(t *testing.T) {
+	files, err := GeminiWorkflow(testWorkflow(), testResolver(map[string]string{"reviewer": `Say """hi""" \ bye`}))
+	require.NoError(t, err)
+
+	byPath := filesByPath(files)
+	require.Len(t, byPath, 3)
+
+	manifest := byPath[".gemini/extensions/code-review/gemini-extension.json"]
+	assert.Contains(t, manifest, `"name": "code-review"`)
+	assert.Contains(t, manifest, `"contextFileName": "GEMINI.md"`)
+
+	context := byPath[".gemini/extensions/code-review/GEMINI.md"]
+	assert.Contains(t, context, "## Step 1: review")
+	assert.Contains(t, context, "## Step 2: approve\n\nAsk the user: Apply the fixes?")
+	assert.Contains(t, context, "Save the result to `fix.md`.")
+	assert.Contains(t, context, `Say """hi""" \ bye`)
+
+	command := byPath[".gemini/extensions/code-review/commands/code-review.toml"]
+	assert.True(t, strings.HasPrefix(command, "description = \"Review and fix a change\"\nprompt = \"\"\"\n"))
+	assert.Contains(t, command, "{{args}}")
+}
+
+func TestGeminiPromptEscaping(t *testing.T) {
+	prompt := newTestPrompt(core.PromptMetadata{Name: "quote", Description: `Say "hi"`}, `Use """ and \n for $ARGUMENTS`)
+
+	files, err := GeminiPrompt(prompt, nil)
+	require.NoError(t, err)
+
+	command := filesByPath(files)[".gemini/extensions/quote/commands/quote.toml"]
+	assert.Contains(t, command, `description = "Say \"hi\""`)
+	assert.Contains(t, command, `Use ""\" and \\n for {{args}}`)
+}
+
+func TestWriteRefusesOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	files := []File{{Path: "a/one.md", Content: []byte("one")}, {Path: "two.md", Content: []byte("two")}}
+
+	written, err := Write(dir, files, false)
+	require.NoError(t, err)
+	assert.Len(t, written, 2)
+
+	files[0].Content = []byte("changed")
+	_, err = Write(dir, files, false)
+	assert.Error(t, err)
+	data, _ := os.ReadFile(filepath.Join(dir, "a", "one.md"))
+	assert.Equal(t, "one", string(data))
+
+	_, err = Write(dir, files, true)
+	require.NoError(t, err)
+	data, _ = os.ReadFile(filepath.Join(dir, "a", "one.md"))
+	assert.Equal(t, "changed", string(data))
+}
@@ -0,0 +1,143 @@
+package export
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// geminiExtensionsDir is where Gemini CLI loads workspace extensions from
+const geminiExtensionsDir = ".gemini/extensions"
+
+// geminiExtension is the gemini-extension.json manifest
+type geminiExtension struct {
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	ContextFileName string `json:"contextFileName"`
+}
+
+// GeminiWorkflow converts a workflow into a Gemini CLI extension. Gemini has
+// no subagents, so every step's instructions go into the extension's GEMINI.md
+// and a /name command tells Gemini to follow them.
+func GeminiWorkflow(wf *workflow.Workflow, resolve PromptResolver) ([]File, error) {
+	name := slug(wf.Name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", wf.Name)
+	if wf.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", wf.Description)
+	}
+	fmt.Fprintf(&b, "When asked to run the %s workflow, work through the steps below in order. ", wf.Name)
+	b.WriteString("Finish each step before starting the next and carry its results forward.\n\n")
+	writeVariables(&b, wf)
+
+	for i, agent := range wf.Agents {
+		fmt.Fprintf(&b, "## Step %d: %s\n\n", i+1, stepTitle(agent))
+		if text, ok := nonAgentStep(agent); ok {
+			fmt.Fprintf(&b, "%s.\n\n", strings.ToUpper(text[:1])+text[1:])
+			continue
+		}
+
+		if len(agent.DependsOn) > 0 {
+			fmt.Fprintf(&b, "Uses the results of: %s.\n\n", strings.Join(agent.DependsOn, ", "))
+		}
+		prompt, err := agentInstructions(agent, resolve)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", agent.ID, err)
+		}
+		b.WriteString(strings.TrimSpace(prompt))
+		b.WriteString("\n\n")
+		if agent.Output != "" {
+			fmt.Fprintf(&b, "Save the result to `%s`.\n\n", agent.Output)
+		}
+	}
+
+	command := fmt.Sprintf("Run the %s workflow described in your context, step by step.\n\nArguments: {{args}}\n", wf.Name)
+	files, err := geminiExtensionFiles(name, wf.Version, b.String(), workflowDescription(wf), command)
+	if err != nil {
+		return nil, err
+	}
+	return sortedFiles(files), nil
+}
+
+// GeminiPrompt converts a prompt into a Gemini CLI extension with a /name command
+func GeminiPrompt(prompt core.Prompt, resolve PromptResolver) ([]File, error) {
+	content, err := ResolvePrompt(prompt.Content(), resolve)
+	if err != nil {
+		return nil, err
+	}
+	content = strings.ReplaceAll(strings.TrimSpace(content), "$ARGUMENTS", "{{args}}")
+	if !strings.Contains(content, "{{args}}") {
+		content += "\n\nArguments: {{args}}"
+	}
+
+	name := slug(prompt.Name())
+	context := fmt.Sprintf("# %s\n\n%s\n\nRun it with the /%s command.\n", prompt.Name(), promptDescription(prompt), name)
+	files, err := geminiExtensionFiles(name, prompt.Metadata().Version, context, promptDescription(prompt), content+"\n")
+	if err != nil {
+		return nil, err
+	}
+	return sortedFiles(files), nil
+}
+
+// geminiExtensionFiles lays out an extension with a context file and one command
+func geminiExtensionFiles(name, version, context, description, command string) ([]File, error) {
+	if version == "" {
+		version = "1.0.0"
+	}
+	manifest, err := json.MarshalIndent(geminiExtension{
+		Name:            name,
+		Version:         version,
+		ContextFileName: "GEMINI.md",
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Join(geminiExtensionsDir, name)
+
+	return []File{
+		{Path: path.Join(dir, "gemini-extension.json"), Content: append(manifest, '\n')},
+		{Path: path.Join(dir, "GEMINI.md"), Content: []byte(context)},
+		{Path: path.Join(dir, "commands", name+".toml"), Content: GeminiCommand(description, command)},
+	}, nil
+}
+
+// GeminiCommand renders a Gemini CLI custom command, whose prompt gets the
+// command's arguments in place of {{args}}
+func GeminiCommand(description, prompt string) []byte {
+	return []byte(fmt.Sprintf("description = %s\nprompt = %s\n", tomlString(description), tomlMultiline(prompt)))
+}
+
+// tomlString quotes s as a single line TOML basic string
+func tomlString(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// tomlMultiline quotes s as a TOML multi-line basic string
+func tomlMultiline(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"""`, `""\"`)
+	return "\"\"\"\n" + s + "\"\"\""
+}
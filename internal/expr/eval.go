@@ -0,0 +1,495 @@
+package expr
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type evaluator struct {
+	env Env
+}
+
+func (e *evaluator) eval(n node) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		v, ok := e.env.Values[n.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown name %s", n.name)
+		}
+		return normalize(v)
+
+	case *memberNode:
+		object, err := e.eval(n.object)
+		if err != nil {
+			return nil, err
+		}
+		return member(object, n.name)
+
+	case *indexNode:
+		object, err := e.eval(n.object)
+		if err != nil {
+			return nil, err
+		}
+		index, err := e.eval(n.index)
+		if err != nil {
+			return nil, err
+		}
+		return indexValue(object, index)
+
+	case *listNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			v, err := e.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+
+	case *callNode:
+		args := make([]interface{}, 0, len(n.args))
+		for _, arg := range n.args {
+			v, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, v)
+		}
+		v, err := builtins[n.name].call(e, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s(): %w", n.name, err)
+		}
+		return v, nil
+
+	case *unaryNode:
+		x, err := e.eval(n.x)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "-" {
+			f, ok := toNumber(x)
+			if !ok {
+				return nil, fmt.Errorf("cannot negate %s", typeName(x))
+			}
+			return -f, nil
+		}
+		b, ok := toBool(x)
+		if !ok {
+			return nil, fmt.Errorf("'!' needs true or false, got %s", typeName(x))
+		}
+		return !b, nil
+
+	case *binaryNode:
+		return e.evalBinary(n)
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+func (e *evaluator) evalBinary(n *binaryNode) (interface{}, error) {
+	left, err := e.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || short-circuit
+	if n.op == "&&" || n.op == "||" {
+		l, ok := toBool(left)
+		if !ok {
+			return nil, fmt.Errorf("'%s' needs true or false, got %s", n.op, typeName(left))
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := e.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := toBool(right)
+		if !ok {
+			return nil, fmt.Errorf("'%s' needs true or false, got %s", n.op, typeName(right))
+		}
+		return r, nil
+	}
+
+	right, err := e.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		c, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "in":
+		return containsValue(right, left)
+	case "contains":
+		return containsValue(left, right)
+	case "matches":
+		return matchValue(left, right)
+	case "startsWith":
+		return fnStartsWith(e, []interface{}{left, right})
+	case "endsWith":
+		return fnEndsWith(e, []interface{}{left, right})
+	}
+	return arithmetic(n.op, left, right)
+}
+
+// normalize converts Go values into the expression types: nil, bool,
+// float64, string, []interface{} and map[string]interface{}
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, bool, float64, string, []interface{}, map[string]interface{}:
+		return v, nil
+	case Lazy:
+		resolved, err := v()
+		if err != nil {
+			return nil, err
+		}
+		return normalize(resolved)
+	case func() (interface{}, error):
+		return normalize(Lazy(v))
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return items, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return m, nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return normalize(rv.Elem().Interface())
+	}
+	return nil, fmt.Errorf("unsupported value of type %T", v)
+}
+
+// member reads a field of a map; missing fields are nil
+func member(object interface{}, name string) (interface{}, error) {
+	switch object := object.(type) {
+	case map[string]interface{}:
+		return normalize(object[name])
+	case nil:
+		return nil, fmt.Errorf("cannot read .%s of nil", name)
+	}
+	return nil, fmt.Errorf("cannot read .%s of %s", name, typeName(object))
+}
+
+func indexValue(object, index interface{}) (interface{}, error) {
+	switch object := object.(type) {
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map keys are strings, got %s", typeName(index))
+		}
+		return normalize(object[key])
+	case []interface{}, string:
+		f, ok := index.(float64)
+		if !ok || f != math.Trunc(f) {
+			return nil, fmt.Errorf("index must be a whole number, got %s", formatValue(index))
+		}
+		i := int(f)
+		if s, isString := object.(string); isString {
+			runes := []rune(s)
+			if i < 0 {
+				i += len(runes)
+			}
+			if i < 0 || i >= len(runes) {
+				return nil, fmt.Errorf("index %d out of range", int(f))
+			}
+			return string(runes[i]), nil
+		}
+		list := object.([]interface{})
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil, fmt.Errorf("index %d out of range", int(f))
+		}
+		return normalize(list[i])
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(object))
+}
+
+// toBool accepts booleans and the strings "true" and "false", which is how
+// workflow variables set on the command line arrive
+func toBool(v interface{}) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// toNumber accepts numbers and strings holding a number
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equal compares two values. A string holding a number or boolean equals
+// that number or boolean.
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case float64:
+		y, ok := toNumber(b)
+		return ok && x == y
+	case bool:
+		y, ok := toBool(b)
+		return ok && x == y
+	case string:
+		switch b.(type) {
+		case float64, bool:
+			return equal(b, a)
+		case string:
+			return x == b
+		}
+		return false
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			xi, _ := normalize(x[i])
+			yi, _ := normalize(y[i])
+			if !equal(xi, yi) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders numbers numerically and strings lexically
+func compare(a, b interface{}) (int, error) {
+	_, aNum := a.(float64)
+	_, bNum := b.(float64)
+	if aNum || bNum {
+		x, ok1 := toNumber(a)
+		y, ok2 := toNumber(b)
+		if !ok1 || !ok2 {
+			return 0, fmt.Errorf("cannot compare %s with %s", typeName(a), typeName(b))
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	x, ok1 := a.(string)
+	y, ok2 := b.(string)
+	if !ok1 || !ok2 {
+		return 0, fmt.Errorf("cannot compare %s with %s", typeName(a), typeName(b))
+	}
+	return strings.Compare(x, y), nil
+}
+
+// containsValue reports whether a list holds an item, a map has a key or a
+// string contains a substring
+func containsValue(container, item interface{}) (bool, error) {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, v := range c {
+			v, err := normalize(v)
+			if err != nil {
+				return false, err
+			}
+			if equal(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c[key]
+		return found, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			s = formatValue(item)
+		}
+		return strings.Contains(c, s), nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("cannot look inside %s", typeName(container))
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if op == "+" {
+		_, lString := left.(string)
+		_, rString := right.(string)
+		if lString || rString {
+			return formatValue(left) + formatValue(right), nil
+		}
+		if l, ok := left.([]interface{}); ok {
+			if r, ok := right.([]interface{}); ok {
+				return append(append([]interface{}{}, l...), r...), nil
+			}
+		}
+	}
+
+	x, ok1 := left.(float64)
+	y, ok2 := right.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("'%s' needs numbers, got %s and %s", op, typeName(left), typeName(right))
+	}
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return x / y, nil
+	case "%":
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(x, y), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// formatValue renders a value the way string() does
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			item, _ = normalize(item)
+			parts[i] = formatValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			item, _ := normalize(v[k])
+			parts[i] = k + ": " + formatValue(item)
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,511 @@
+package expr
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSourceLen bounds the size of an expression
+const maxSourceLen = 4096
+
+// Program is a compiled expression. Expressions are side-effect free: they
+// read the names in their Env and call the built-in functions, nothing else.
+type Program struct {
+	source string
+	root   node
+}
+
+// Env holds what an expression can see
+type Env struct {
+	// Values are the top-level names. Values may be nil, bool, numbers,
+	// strings, slices, maps with string keys or a Lazy.
+	Values map[string]interface{}
+	// Dir is the directory file functions are confined to, empty for the
+	// current directory
+	Dir string
+}
+
+// Lazy is a value computed only when an expression reads it, e.g. the
+// contents of a file
+type Lazy func() (interface{}, error)
+
+// Compile parses an expression so it can be evaluated many times
+func Compile(source string) (*Program, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > maxSourceLen {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxSourceLen)
+	}
+
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos+1)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// String returns the expression's source
+func (p *Program) String() string {
+	return p.source
+}
+
+// Check reports the first top-level name the expression reads that is not
+// one of names, so typos surface before the expression is evaluated
+func (p *Program) Check(names ...string) error {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	var check func(n node) error
+	check = func(n node) error {
+		switch n := n.(type) {
+		case *identNode:
+			if !known[n.name] {
+				return fmt.Errorf("unknown name %s", n.name)
+			}
+		case *memberNode:
+			return check(n.object)
+		case *indexNode:
+			if err := check(n.object); err != nil {
+				return err
+			}
+			return check(n.index)
+		case *unaryNode:
+			return check(n.x)
+		case *binaryNode:
+			if err := check(n.left); err != nil {
+				return err
+			}
+			return check(n.right)
+		case *callNode:
+			for _, arg := range n.args {
+				if err := check(arg); err != nil {
+					return err
+				}
+			}
+		case *listNode:
+			for _, item := range n.items {
+				if err := check(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return check(p.root)
+}
+
+// Eval evaluates the program. Numbers come back as float64, lists as
+// []interface{} and maps as map[string]interface{}.
+func (p *Program) Eval(env Env) (interface{}, error) {
+	return (&evaluator{env: env}).eval(p.root)
+}
+
+// EvalBool evaluates the program and requires a true or false result
+func (p *Program) EvalBool(env Env) (bool, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := toBool(v)
+	if !ok {
+		return false, fmt.Errorf("expression must be true or false, got %s %s", typeName(v), formatValue(v))
+	}
+	return b, nil
+}
+
+// Eval compiles and evaluates source in one step
+func Eval(source string, env Env) (interface{}, error) {
+	p, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	return p.Eval(env)
+}
+
+// EvalBool compiles source and evaluates it as a condition
+func EvalBool(source string, env Env) (bool, error) {
+	p, err := Compile(source)
+	if err != nil {
+		return false, err
+	}
+	return p.EvalBool(env)
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string // operator or identifier, or the decoded string literal
+	num  float64
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators, longest first so that <= wins over <
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "[", "]", ",", "."}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r >= '0' && r <= '9':
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			text := strings.ReplaceAll(src[start:i], "_", "")
+			n, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", src[start:i], start+1)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], num: n, pos: start})
+		case r == '"' || r == '\'':
+			s, end, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, pos: i})
+			i = end
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// lexString reads a single or double quoted string starting at src[start]
+// and returns its value and the index after the closing quote
+func lexString(src string, start int) (string, int, error) {
+	quote := src[start]
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(src[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string starting at position %d", start+1)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Parser
+
+type node interface{}
+
+type (
+	literalNode struct{ value interface{} }
+	identNode   struct{ name string }
+	memberNode  struct {
+		object node
+		name   string
+	}
+	indexNode struct{ object, index node }
+	callNode  struct {
+		name string
+		args []node
+	}
+	unaryNode struct {
+		op string
+		x  node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	listNode struct{ items []node }
+)
+
+// Binding powers, lowest first
+const (
+	bpOr = iota + 1
+	bpAnd
+	bpEquality
+	bpCompare
+	bpSum
+	bpProduct
+	bpUnary
+)
+
+// infixOperators maps binary operators, including the word forms, to their
+// binding power
+var infixOperators = map[string]int{
+	"||": bpOr, "or": bpOr,
+	"&&": bpAnd, "and": bpAnd,
+	"==": bpEquality, "!=": bpEquality,
+	"<": bpCompare, "<=": bpCompare, ">": bpCompare, ">=": bpCompare,
+	"in": bpCompare, "contains": bpCompare, "matches": bpCompare, "startsWith": bpCompare, "endsWith": bpCompare,
+	"+": bpSum, "-": bpSum,
+	"*": bpProduct, "/": bpProduct, "%": bpProduct,
+}
+
+// canonicalOperators maps word operators to their symbol
+var canonicalOperators = map[string]string{"or": "||", "and": "&&", "not": "!"}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(op string) error {
+	tok := p.next()
+	if tok.kind != tokOp || tok.text != op {
+		return fmt.Errorf("expected %q at position %d, found %s", op, tok.pos+1, tok)
+	}
+	return nil
+}
+
+func (p *parser) parseExpr(minBP int) (node, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokOp && tok.kind != tokIdent {
+			return left, nil
+		}
+
+		// Member access and indexing bind tightest
+		if tok.kind == tokOp && tok.text == "." {
+			p.next()
+			name := p.next()
+			if name.kind != tokIdent {
+				return nil, fmt.Errorf("expected a name after '.' at position %d, found %s", name.pos+1, name)
+			}
+			left = &memberNode{object: left, name: name.text}
+			continue
+		}
+		if tok.kind == tokOp && tok.text == "[" {
+			p.next()
+			index, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			left = &indexNode{object: left, index: index}
+			continue
+		}
+
+		bp, ok := infixOperators[tok.text]
+		if !ok || bp <= minBP {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(bp)
+		if err != nil {
+			return nil, err
+		}
+		op := tok.text
+		if canonical, ok := canonicalOperators[op]; ok {
+			op = canonical
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parsePrefix() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &literalNode{value: tok.num}, nil
+	case tokString:
+		return &literalNode{value: tok.text}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "nil", "null":
+			return &literalNode{value: nil}, nil
+		case "not":
+			return p.parseUnary("!")
+		}
+		// contains, matches, startsWith and endsWith are both operators and functions
+		if next := p.peek(); next.kind == tokOp && next.text == "(" {
+			if _, ok := builtins[tok.text]; ok {
+				return p.parseCall(tok)
+			}
+		}
+		if _, isOp := infixOperators[tok.text]; isOp {
+			return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos+1)
+		}
+		if next := p.peek(); next.kind == tokOp && next.text == "(" {
+			return p.parseCall(tok)
+		}
+		return &identNode{name: tok.text}, nil
+	case tokOp:
+		switch tok.text {
+		case "!", "-":
+			return p.parseUnary(tok.text)
+		case "(":
+			x, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos+1)
+}
+
+func (p *parser) parseUnary(op string) (node, error) {
+	x, err := p.parseExpr(bpUnary)
+	if err != nil {
+		return nil, err
+	}
+	return &unaryNode{op: op, x: x}, nil
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := builtins[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", name.text, name.pos+1)
+	}
+	p.next() // (
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%s() takes %s, got %d", name.text, fn.arity(), len(args))
+	}
+	return &callNode{name: name.text, args: args}, nil
+}
+
+// parseList parses comma separated expressions up to the closing token
+func (p *parser) parseList(closing string) ([]node, error) {
+	var items []node
+	if tok := p.peek(); tok.kind == tokOp && tok.text == closing {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		tok := p.next()
+		if tok.kind == tokOp && tok.text == closing {
+			return items, nil
+		}
+		if tok.kind != tokOp || tok.text != "," {
+			return nil, fmt.Errorf("expected ',' or %q at position %d, found %s", closing, tok.pos+1, tok)
+		}
+	}
+}
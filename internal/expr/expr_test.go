@@ -0,0 +1,177 @@
+package expr
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	env := Env{Values: map[string]interface{}{
+		"vars": map[string]interface{}{
+			"focus":   "security,performance",
+			"retries": "3",
+			"strict":  "true",
+			"tags":    []string{"go", "cli"},
+		},
+		"agents": map[string]interface{}{
+			"analyzer": map[string]interface{}{"status": "completed", "success": true},
+		},
+		"count": 2,
+	}}
+
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{`1 + 2 * 3`, float64(7)},
+		{`(1 + 2) * 3`, float64(9)},
+		{`-count + 10 % 4`, float64(0)},
+		{`"a" + 1`, "a1"},
+		{`vars.retries == 3`, true},
+		{`vars.retries > 2 && vars.strict`, true},
+		{`agents.analyzer.success and not (count >= 3)`, true},
+		{`agents["analyzer"].status == "completed"`, true},
+		{`vars.focus contains "security"`, true},
+		{`"cli" in vars.tags`, true},
+		{`"analyzer" in agents`, true},
+		{`"missing" in vars.tags || false`, false},
+		{`vars.focus matches "^sec"`, true},
+		{`vars.focus startsWith "perf"`, false},
+		{`contains(vars.focus, "perf") && endsWith(vars.focus, "ance")`, true},
+		{`len(split(vars.focus, ",")) == 2`, true},
+		{`upper(trim("  ok "))`, "OK"},
+		{`join(vars.tags, "+")`, "go+cli"},
+		{`max(1, count, 0.5) - min([4, 3])`, float64(-1)},
+		{`vars.missing == nil`, true},
+		{`string(2.50) + "/" + string(true)`, "2.5/true"},
+		{`vars.tags[-1]`, "cli"},
+		{`'it\'s'`, "it's"},
+	}
+	for _, tt := range tests {
+		got, err := Eval(tt.source, env)
+		require.NoError(t, err, tt.source)
+		assert.Equal(t, tt.want, got, tt.source)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	env := Env{Values: map[string]interface{}{"n": 1, "m": map[string]interface{}{}}}
+
+	tests := map[string]string{
+		``:                  "expression is empty",
+		`1 +`:               "unexpected end of expression",
+		`(1`:                `expected ")"`,
+		`"open`:             "unterminated string",
+		`1 $ 2`:             "unexpected character",
+		`system("rm")`:      "unknown function system",
+		`len(1, 2)`:         "len() takes 1 argument, got 2",
+		`undefined > 1`:     "unknown name undefined",
+		`m.a.b`:             "cannot read .b of nil",
+		`n && true`:         "'&&' needs true or false, got number",
+		`n / 0`:             "division by zero",
+		`"a" matches "("`:   "invalid pattern",
+		`n < "x"`:           "cannot compare",
+		`[1, 2][5]`:         "index 5 out of range",
+		`lower(n)`:          "lower(): needs a string, got number",
+		`n contains "x"`:    "cannot look inside number",
+		`read("../secret")`: "outside",
+	}
+	for source, want := range tests {
+		_, err := Eval(source, env)
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), want, source)
+	}
+
+	_, err := EvalBool(`n + 1`, env)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be true or false, got number 2")
+}
+
+func TestShortCircuit(t *testing.T) {
+	env := Env{Values: map[string]interface{}{"ready": false}}
+
+	// The right side would fail if it were evaluated
+	ok, err := EvalBool(`ready && undefined`, env)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = EvalBool(`!ready || undefined`, env)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLazyValues(t *testing.T) {
+	calls := 0
+	env := Env{Values: map[string]interface{}{
+		"report": Lazy(func() (interface{}, error) {
+			calls++
+			return "3 issues found", nil
+		}),
+	}}
+
+	p, err := Compile(`report contains "issues"`)
+	require.NoError(t, err)
+	assert.Equal(t, 0, calls)
+
+	ok, err := p.EvalBool(env)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, calls)
+}
+
+func TestFileFunctions(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.md"), []byte("PASS\nall good\n"), 0644))
+	env := Env{Dir: dir}
+
+	tests := map[string]interface{}{
+		`exists("report.md")`:                               true,
+		`exists("missing.md")`:                              false,
+		`size("report.md")`:                                 float64(14),
+		`read("report.md") startsWith "PASS"`:               true,
+		`len(lines(read("report.md")))`:                     float64(2),
+		`lines(read("report.md"))[1]`:                       "all good",
+		`exists("sub/../report.md")`:                        true,
+		`len(lines(""))`:                                    float64(0),
+		`number("1.5") + number(true)`:                      2.5,
+		`abs(-3) == 3 && replace("a-b", "-", "_") == "a_b"`: true,
+	}
+	for source, want := range tests {
+		got, err := Eval(source, env)
+		require.NoError(t, err, source)
+		assert.Equal(t, want, got, source)
+	}
+
+	for _, source := range []string{`read("/etc/passwd")`, `exists("../x")`} {
+		_, err := Eval(source, env)
+		assert.Error(t, err, source)
+	}
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0644))
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(dir, "link")); err == nil {
+		_, err := Eval(`read("link")`, env)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "outside")
+	}
+}
@@ -0,0 +1,334 @@
+package expr
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxReadBytes is how much of a file read() returns
+	maxReadBytes = 1 << 20
+	// maxPatternLen bounds regular expressions passed to matches
+	maxPatternLen = 1024
+)
+
+type builtin struct {
+	minArgs int
+	maxArgs int // -1 for any number
+	call    func(e *evaluator, args []interface{}) (interface{}, error)
+}
+
+func (b builtin) arity() string {
+	switch {
+	case b.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", b.minArgs)
+	case b.minArgs == b.maxArgs && b.minArgs == 1:
+		return "1 argument"
+	case b.minArgs == b.maxArgs:
+		return fmt.Sprintf("%d arguments", b.minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", b.minArgs, b.maxArgs)
+}
+
+// builtins are the functions expressions can call. Keep the README's
+// expression reference in sync when adding one.
+var builtins = map[string]builtin{
+	// Strings and lists
+	"len":        {1, 1, fnLen},
+	"lower":      stringFunc(strings.ToLower),
+	"upper":      stringFunc(strings.ToUpper),
+	"trim":       stringFunc(strings.TrimSpace),
+	"contains":   {2, 2, func(_ *evaluator, a []interface{}) (interface{}, error) { return containsValue(a[0], a[1]) }},
+	"startsWith": {2, 2, fnStartsWith},
+	"endsWith":   {2, 2, fnEndsWith},
+	"matches":    {2, 2, func(_ *evaluator, a []interface{}) (interface{}, error) { return matchValue(a[0], a[1]) }},
+	"replace":    {3, 3, fnReplace},
+	"split":      {2, 2, fnSplit},
+	"join":       {2, 2, fnJoin},
+	"lines":      {1, 1, fnLines},
+
+	// Conversions and numbers
+	"string": {1, 1, func(_ *evaluator, a []interface{}) (interface{}, error) { return formatValue(a[0]), nil }},
+	"number": {1, 1, fnNumber},
+	"abs":    {1, 1, fnAbs},
+	"min":    {1, -1, func(_ *evaluator, a []interface{}) (interface{}, error) { return extreme(a, -1) }},
+	"max":    {1, -1, func(_ *evaluator, a []interface{}) (interface{}, error) { return extreme(a, 1) }},
+
+	// Files, read-only and confined to the environment's directory
+	"exists": {1, 1, fnExists},
+	"read":   {1, 1, fnRead},
+	"size":   {1, 1, fnSize},
+}
+
+func stringFunc(fn func(string) string) builtin {
+	return builtin{1, 1, func(_ *evaluator, a []interface{}) (interface{}, error) {
+		s, ok := a[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("needs a string, got %s", typeName(a[0]))
+		}
+		return fn(s), nil
+	}}
+}
+
+func stringArgs(args []interface{}) ([]string, error) {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("argument %d must be a string, got %s", i+1, typeName(arg))
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func fnLen(_ *evaluator, a []interface{}) (interface{}, error) {
+	switch v := a[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	case nil:
+		return float64(0), nil
+	}
+	return nil, fmt.Errorf("cannot take the length of %s", typeName(a[0]))
+}
+
+func fnStartsWith(_ *evaluator, a []interface{}) (interface{}, error) {
+	s, err := stringArgs(a)
+	if err != nil {
+		return nil, err
+	}
+	return strings.HasPrefix(s[0], s[1]), nil
+}
+
+func fnEndsWith(_ *evaluator, a []interface{}) (interface{}, error) {
+	s, err := stringArgs(a)
+	if err != nil {
+		return nil, err
+	}
+	return strings.HasSuffix(s[0], s[1]), nil
+}
+
+func fnReplace(_ *evaluator, a []interface{}) (interface{}, error) {
+	s, err := stringArgs(a)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ReplaceAll(s[0], s[1], s[2]), nil
+}
+
+func fnSplit(_ *evaluator, a []interface{}) (interface{}, error) {
+	s, err := stringArgs(a)
+	if err != nil {
+		return nil, err
+	}
+	var parts []interface{}
+	for _, part := range strings.Split(s[0], s[1]) {
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+func fnJoin(_ *evaluator, a []interface{}) (interface{}, error) {
+	list, ok := a[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("needs a list, got %s", typeName(a[0]))
+	}
+	sep, ok := a[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("separator must be a string, got %s", typeName(a[1]))
+	}
+	parts := make([]string, len(list))
+	for i, item := range list {
+		item, err := normalize(item)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = formatValue(item)
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func fnLines(_ *evaluator, a []interface{}) (interface{}, error) {
+	s, ok := a[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("needs a string, got %s", typeName(a[0]))
+	}
+	lines := []interface{}{}
+	if s == "" {
+		return lines, nil
+	}
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	return lines, nil
+}
+
+func fnNumber(_ *evaluator, a []interface{}) (interface{}, error) {
+	if b, ok := a[0].(bool); ok {
+		if b {
+			return float64(1), nil
+		}
+		return float64(0), nil
+	}
+	f, ok := toNumber(a[0])
+	if !ok {
+		return nil, fmt.Errorf("%q is not a number", formatValue(a[0]))
+	}
+	return f, nil
+}
+
+func fnAbs(_ *evaluator, a []interface{}) (interface{}, error) {
+	f, ok := toNumber(a[0])
+	if !ok {
+		return nil, fmt.Errorf("needs a number, got %s", typeName(a[0]))
+	}
+	return math.Abs(f), nil
+}
+
+// extreme returns the smallest (sign -1) or largest (sign 1) of its
+// arguments, or of the single list argument
+func extreme(args []interface{}, sign int) (interface{}, error) {
+	if len(args) == 1 {
+		if list, ok := args[0].([]interface{}); ok {
+			args = list
+		}
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("needs at least one value")
+	}
+
+	var best float64
+	for i, arg := range args {
+		arg, err := normalize(arg)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := toNumber(arg)
+		if !ok {
+			return nil, fmt.Errorf("needs numbers, got %s", typeName(arg))
+		}
+		if i == 0 || (sign < 0 && f < best) || (sign > 0 && f > best) {
+			best = f
+		}
+	}
+	return best, nil
+}
+
+func matchValue(s, pattern interface{}) (bool, error) {
+	str, ok1 := s.(string)
+	pat, ok2 := pattern.(string)
+	if !ok1 || !ok2 {
+		return false, fmt.Errorf("matches needs strings, got %s and %s", typeName(s), typeName(pattern))
+	}
+	if len(pat) > maxPatternLen {
+		return false, fmt.Errorf("pattern is longer than %d characters", maxPatternLen)
+	}
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern %q: %w", pat, err)
+	}
+	return re.MatchString(str), nil
+}
+
+// resolvePath joins a relative path onto the environment's directory and
+// rejects anything that would leave it
+func (e *evaluator) resolvePath(arg interface{}) (string, error) {
+	path, ok := arg.(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("needs a path, got %s", typeName(arg))
+	}
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("path %s must be relative", path)
+	}
+
+	base := e.env.Dir
+	if base == "" {
+		base = "."
+	}
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return "", err
+	}
+	full := filepath.Join(base, path)
+	if !within(base, full) {
+		return "", fmt.Errorf("path %s is outside %s", path, base)
+	}
+
+	// A symlink must not lead out either
+	if resolved, err := filepath.EvalSymlinks(full); err == nil {
+		if realBase, err := filepath.EvalSymlinks(base); err == nil && !within(realBase, resolved) {
+			return "", fmt.Errorf("path %s is outside %s", path, base)
+		}
+	}
+	return full, nil
+}
+
+func within(base, path string) bool {
+	rel, err := filepath.Rel(base, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func fnExists(e *evaluator, a []interface{}) (interface{}, error) {
+	path, err := e.resolvePath(a[0])
+	if err != nil {
+		return nil, err
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
+
+func fnRead(e *evaluator, a []interface{}) (interface{}, error) {
+	path, err := e.resolvePath(a[0])
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxReadBytes))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func fnSize(e *evaluator, a []interface{}) (interface{}, error) {
+	path, err := e.resolvePath(a[0])
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return float64(info.Size()), nil
+}
@@ -0,0 +1,137 @@
+package features
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Experimental features, which stay off until a user enables them
+const (
+	// ParallelWorkflows runs matrix combinations concurrently with --parallel
+	ParallelWorkflows = "parallel-workflows"
+	// Daemon serves the daemon and REST APIs with `opun daemon`, `opun serve`
+	// and `opun lsp`
+	Daemon = "daemon"
+	// ContainerSandbox runs providers in containers with settings.sandbox
+	ContainerSandbox = "container-sandbox"
+)
+
+// EnvVar enables features for a single command, e.g. OPUN_FEATURES=daemon,
+// on top of the ones enabled in the config; "all" enables every feature
+const EnvVar = "OPUN_FEATURES"
+
+// Feature is an experimental subsystem gated behind a flag
+type Feature struct {
+	Name        string
+	Description string
+}
+
+// known are the features that can be enabled
+var known = []Feature{
+	{Name: ContainerSandbox, Description: "Run agents' providers in Docker or Podman containers with settings.sandbox"},
+	{Name: Daemon, Description: "Serve the JSON-RPC and REST APIs for editors and dashboards with opun daemon, opun serve and opun lsp"},
+	{Name: ParallelWorkflows, Description: "Run several matrix combinations at once with opun run --matrix --parallel N"},
+}
+
+var (
+	enabledMu sync.RWMutex
+	enabled   = make(map[string]bool)
+)
+
+// All returns the known features by name
+func All() []Feature {
+	features := append([]Feature(nil), known...)
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return features
+}
+
+// Lookup returns a known feature
+func Lookup(name string) (Feature, bool) {
+	for _, feature := range known {
+		if feature.Name == name {
+			return feature, true
+		}
+	}
+	return Feature{}, false
+}
+
+// Names returns the names of the known features
+func Names() []string {
+	var names []string
+	for _, feature := range All() {
+		names = append(names, feature.Name)
+	}
+	return names
+}
+
+// Set enables or disables a feature, e.g. from the features section of the config
+func Set(name string, on bool) {
+	enabledMu.Lock()
+	defer enabledMu.Unlock()
+	if on {
+		enabled[name] = true
+	} else {
+		delete(enabled, name)
+	}
+}
+
+// Enabled reports whether a feature is enabled in the config or by EnvVar
+func Enabled(name string) bool {
+	enabledMu.RLock()
+	on := enabled[name]
+	enabledMu.RUnlock()
+	return on || FromEnv(name)
+}
+
+// FromEnv reports whether EnvVar enables a feature
+func FromEnv(name string) bool {
+	for _, entry := range strings.Split(os.Getenv(EnvVar), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == name || entry == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// DisabledError is returned when something needs a feature that isn't enabled
+type DisabledError struct {
+	Feature string
+	Use     string // what needed it, e.g. "settings.sandbox"
+}
+
+func (e *DisabledError) Error() string {
+	use := e.Use
+	if use == "" {
+		use = e.Feature
+	}
+	return fmt.Sprintf("%s is experimental and the %s feature is disabled; enable it with `opun features enable %s`", use, e.Feature, e.Feature)
+}
+
+// Require returns a DisabledError unless a feature is enabled. use names
+// what needs the feature in the message.
+func Require(name, use string) error {
+	if Enabled(name) {
+		return nil
+	}
+	return &DisabledError{Feature: name, Use: use}
+}
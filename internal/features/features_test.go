@@ -0,0 +1,70 @@
+package features
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	t.Cleanup(func() { Set(Daemon, false) })
+
+	assert.False(t, Enabled(Daemon))
+
+	Set(Daemon, true)
+	assert.True(t, Enabled(Daemon))
+	assert.False(t, Enabled(ParallelWorkflows))
+
+	Set(Daemon, false)
+	assert.False(t, Enabled(Daemon))
+
+	t.Setenv(EnvVar, " parallel-workflows , daemon")
+	assert.True(t, Enabled(Daemon))
+	assert.True(t, Enabled(ParallelWorkflows))
+	assert.False(t, Enabled(ContainerSandbox))
+
+	t.Setenv(EnvVar, "all")
+	assert.True(t, Enabled(ContainerSandbox))
+}
+
+func TestRequire(t *testing.T) {
+	t.Setenv(EnvVar, "")
+
+	err := Require(ContainerSandbox, "settings.sandbox")
+	var disabled *DisabledError
+	require.True(t, errors.As(err, &disabled))
+	assert.Equal(t, ContainerSandbox, disabled.Feature)
+	assert.Contains(t, err.Error(), "opun features enable container-sandbox")
+
+	t.Setenv(EnvVar, ContainerSandbox)
+	assert.NoError(t, Require(ContainerSandbox, "settings.sandbox"))
+}
+
+func TestLookup(t *testing.T) {
+	feature, ok := Lookup(ParallelWorkflows)
+	require.True(t, ok)
+	assert.NotEmpty(t, feature.Description)
+
+	_, ok = Lookup("warp-drive")
+	assert.False(t, ok)
+	assert.Equal(t, []string{ContainerSandbox, Daemon, ParallelWorkflows}, Names())
+}
@@ -0,0 +1,208 @@
+package i18n
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale every message exists in
+const DefaultLocale = "en"
+
+// LocaleEnv overrides the locale from the config file and the system
+const LocaleEnv = "OPUN_LOCALE"
+
+// builtin holds the message catalogs shipped with Opun. A catalog is a JSON
+// object of message IDs to fmt format strings; English is the reference for
+// every other locale. Translations can also be dropped into
+// ~/.opun/locales/<locale>.json, and may reorder arguments with explicit
+// indexes such as %[2]s.
+//
+//go:embed locales/*.json
+var builtin embed.FS
+
+var (
+	mu         sync.RWMutex
+	locale     string
+	catalogDir string
+	catalogs   = make(map[string]map[string]string)
+)
+
+// Normalize turns a locale such as de_DE.UTF-8 or pt-br into de-DE or pt-BR;
+// the C and POSIX locales are English
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" || tag == "C" || tag == "POSIX" {
+		return DefaultLocale
+	}
+	parts := strings.SplitN(strings.ReplaceAll(tag, "_", "-"), "-", 2)
+	tag = strings.ToLower(parts[0])
+	if len(parts) == 2 && parts[1] != "" {
+		tag += "-" + strings.ToUpper(parts[1])
+	}
+	return tag
+}
+
+// Detect picks the locale: OPUN_LOCALE, then the configured one, then the
+// system's LC_ALL, LC_MESSAGES or LANG
+func Detect(configured string) string {
+	for _, tag := range []string{os.Getenv(LocaleEnv), configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")} {
+		if strings.TrimSpace(tag) != "" {
+			return Normalize(tag)
+		}
+	}
+	return DefaultLocale
+}
+
+// SetLocale selects the locale messages are translated to
+func SetLocale(tag string) {
+	mu.Lock()
+	locale = Normalize(tag)
+	mu.Unlock()
+}
+
+// Locale returns the selected locale, detecting it on first use
+func Locale() string {
+	mu.RLock()
+	current := locale
+	mu.RUnlock()
+	if current == "" {
+		current = Detect("")
+		SetLocale(current)
+	}
+	return current
+}
+
+// SetCatalogDir sets the directory user catalogs are read from
+func SetCatalogDir(dir string) {
+	mu.Lock()
+	catalogDir = dir
+	// User catalogs may now shadow built-in ones
+	catalogs = make(map[string]map[string]string)
+	mu.Unlock()
+}
+
+// catalog returns the messages of a locale, built-in ones overlaid with the
+// user's; nil when there are none
+func catalog(tag string) map[string]string {
+	mu.RLock()
+	messages, loaded := catalogs[tag]
+	dir := catalogDir
+	mu.RUnlock()
+	if loaded {
+		return messages
+	}
+
+	if data, err := builtin.ReadFile("locales/" + tag + ".json"); err == nil {
+		messages = mergeCatalog(messages, data, "built-in "+tag)
+	}
+	if dir != "" {
+		if data, err := os.ReadFile(filepath.Join(dir, tag+".json")); err == nil {
+			messages = mergeCatalog(messages, data, filepath.Join(dir, tag+".json"))
+		}
+	}
+
+	mu.Lock()
+	catalogs[tag] = messages
+	mu.Unlock()
+	return messages
+}
+
+// mergeCatalog adds the messages in data to messages
+func mergeCatalog(messages map[string]string, data []byte, source string) map[string]string {
+	var parsed map[string]string
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring message catalog %s: %v\n", source, err)
+		return messages
+	}
+	if messages == nil {
+		messages = make(map[string]string, len(parsed))
+	}
+	for id, message := range parsed {
+		messages[id] = message
+	}
+	return messages
+}
+
+// lookup finds a message in the locale, its language, then English
+func lookup(id string) (string, bool) {
+	tag := Locale()
+	candidates := []string{tag}
+	if i := strings.Index(tag, "-"); i > 0 {
+		candidates = append(candidates, tag[:i])
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	for _, candidate := range candidates {
+		if message, ok := catalog(candidate)[id]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// T returns the message with the given ID in the selected locale, formatted
+// with args. An unknown ID is returned as is so a missing entry is visible.
+func T(id string, args ...interface{}) string {
+	message, ok := lookup(id)
+	if !ok {
+		message = id
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Available lists the locales with a built-in or user catalog
+func Available() []string {
+	seen := map[string]bool{}
+	if entries, err := builtin.ReadDir("locales"); err == nil {
+		for _, entry := range entries {
+			seen[strings.TrimSuffix(entry.Name(), ".json")] = true
+		}
+	}
+	mu.RLock()
+	dir := catalogDir
+	mu.RUnlock()
+	if dir != "" {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
+					seen[strings.TrimSuffix(entry.Name(), ".json")] = true
+				}
+			}
+		}
+	}
+
+	var tags []string
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
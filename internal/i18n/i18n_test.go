@@ -0,0 +1,81 @@
+package i18n
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "de-DE", Normalize("de_DE.UTF-8"))
+	assert.Equal(t, "pt-BR", Normalize("pt-br"))
+	assert.Equal(t, "fr", Normalize("fr@euro"))
+	assert.Equal(t, "en", Normalize("C"))
+	assert.Equal(t, "en", Normalize("POSIX.UTF-8"))
+	assert.Equal(t, "en", Normalize(""))
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv(LocaleEnv, "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_ES.UTF-8")
+
+	assert.Equal(t, "es-ES", Detect(""))
+	assert.Equal(t, "fr", Detect("fr"))
+
+	t.Setenv(LocaleEnv, "de")
+	assert.Equal(t, "de", Detect("fr"))
+}
+
+func TestT(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{
+		"prompt.answer_yes_no": "Bitte mit j oder n antworten.",
+		"prompt.not_in_range": "%[1]q ist keine Zahl zwischen 1 und %[2]d"
+	}`), 0644))
+	SetCatalogDir(dir)
+	t.Cleanup(func() {
+		SetCatalogDir("")
+		SetLocale(DefaultLocale)
+	})
+
+	t.Run("English", func(t *testing.T) {
+		SetLocale("en_US.UTF-8")
+		assert.Equal(t, "Please answer y or n.", T("prompt.answer_yes_no"))
+		assert.Equal(t, "Enter a number (1-3, q to cancel): ", T("prompt.enter_number", 3))
+	})
+
+	t.Run("Translation with language fallback", func(t *testing.T) {
+		SetLocale("de_AT")
+		assert.Equal(t, "Bitte mit j oder n antworten.", T("prompt.answer_yes_no"))
+		assert.Equal(t, `"x" ist keine Zahl zwischen 1 und 3`, T("prompt.not_in_range", "x", 3))
+		// Untranslated messages fall back to English
+		assert.Equal(t, "nothing to choose from", T("prompt.nothing_to_choose"))
+	})
+
+	t.Run("Unknown ID", func(t *testing.T) {
+		assert.Equal(t, "no.such.message", T("no.such.message"))
+	})
+
+	assert.Equal(t, []string{"de", "en"}, Available())
+}
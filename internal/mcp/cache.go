@@ -0,0 +1,232 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// NoCacheArg is the tool argument that bypasses the result cache
+	NoCacheArg = "_no_cache"
+
+	defaultCacheMaxEntries = 500
+	defaultCacheMaxBytes   = 32 << 20
+)
+
+// CacheConfig configures the tool result cache
+type CacheConfig struct {
+	TTLs       map[string]time.Duration // tool name or glob pattern -> TTL; tools not listed are never cached
+	MaxEntries int
+	MaxBytes   int
+}
+
+type cacheEntry struct {
+	key     string
+	result  string
+	expires time.Time
+}
+
+// ResultCache is an opt-in LRU cache of tool results with per-tool TTLs
+type ResultCache struct {
+	mu      sync.Mutex
+	config  CacheConfig
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+	size    int
+	hits    int64
+	misses  int64
+}
+
+// NewResultCache creates a result cache. Only tools with a configured TTL are cached.
+func NewResultCache(config CacheConfig) *ResultCache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultCacheMaxEntries
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultCacheMaxBytes
+	}
+
+	return &ResultCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// ttlFor returns the TTL for a tool, preferring exact matches over patterns
+func (c *ResultCache) ttlFor(tool string) time.Duration {
+	if ttl, ok := c.config.TTLs[tool]; ok {
+		return ttl
+	}
+	for pattern, ttl := range c.config.TTLs {
+		if matched, _ := filepath.Match(pattern, tool); matched {
+			return ttl
+		}
+	}
+	return 0
+}
+
+// Cacheable reports whether results of a tool are cached
+func (c *ResultCache) Cacheable(tool string) bool {
+	return c.ttlFor(tool) > 0
+}
+
+// CacheKey builds the cache key for a tool call. Arguments are canonicalized
+// (JSON with sorted keys) and the scope separates sessions with different roots.
+func CacheKey(tool, scope string, args map[string]interface{}) string {
+	canonical, _ := json.Marshal(args)
+	sum := sha256.Sum256([]byte(tool + "\x00" + scope + "\x00" + string(canonical)))
+	return tool + ":" + hex.EncodeToString(sum[:])
+}
+
+// Get returns a cached result if present and not expired
+func (c *ResultCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[key]
+	if !exists {
+		c.misses++
+		return "", false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(elem)
+		c.misses++
+		return "", false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits++
+	return entry.result, true
+}
+
+// Put stores a tool result, evicting least recently used entries to stay within bounds
+func (c *ResultCache) Put(tool, key, result string) {
+	ttl := c.ttlFor(tool)
+	if ttl <= 0 || len(result) > c.config.MaxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.entries[key]; exists {
+		c.remove(elem)
+	}
+
+	elem := c.lru.PushFront(&cacheEntry{
+		key:     key,
+		result:  result,
+		expires: time.Now().Add(ttl),
+	})
+	c.entries[key] = elem
+	c.size += len(result)
+
+	for c.lru.Len() > c.config.MaxEntries || c.size > c.config.MaxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// InvalidatePrefix drops all entries for tools starting with prefix
+func (c *ResultCache) InvalidatePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(elem)
+		}
+	}
+}
+
+// Stats returns cache hit/miss counters
+func (c *ResultCache) Stats() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return map[string]interface{}{
+		"entries": c.lru.Len(),
+		"bytes":   c.size,
+		"hits":    c.hits,
+		"misses":  c.misses,
+	}
+}
+
+// remove deletes an entry. Callers must hold the lock.
+func (c *ResultCache) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	c.size -= len(entry.result)
+}
+
+// SetResultCache enables caching of tool results
+func (s *StdioMCPServer) SetResultCache(cache *ResultCache) {
+	s.cache = cache
+}
+
+// callTool dispatches a tool call through the result cache when the tool is cacheable
+func (s *StdioMCPServer) callTool(toolName string, arguments map[string]interface{}, params map[string]interface{}, scope string) (string, error) {
+	noCache, _ := arguments[NoCacheArg].(bool)
+	delete(arguments, NoCacheArg)
+
+	if s.cache == nil || !s.cache.Cacheable(toolName) || hasSideEffects(toolName) {
+		result, err := s.dispatchTool(toolName, arguments, params)
+		s.invalidateAfter(toolName, err)
+		return result, err
+	}
+
+	key := CacheKey(toolName, scope, arguments)
+	if !noCache {
+		if result, ok := s.cache.Get(key); ok {
+			return result, nil
+		}
+	}
+
+	result, err := s.dispatchTool(toolName, arguments, params)
+	if err == nil {
+		s.cache.Put(toolName, key, result)
+	}
+	return result, err
+}
+
+// hasSideEffects reports whether a tool must never be served from the cache
+func hasSideEffects(toolName string) bool {
+	return fsToolNames[toolName] ||
+		strings.HasPrefix(toolName, "workflow_") ||
+		strings.HasPrefix(toolName, "operation_")
+}
+
+// invalidateAfter drops cached file reads once a tool may have modified files
+func (s *StdioMCPServer) invalidateAfter(toolName string, err error) {
+	if s.cache == nil || err != nil {
+		return
+	}
+	if fsToolNames[toolName] || strings.HasPrefix(toolName, "action_") {
+		s.cache.InvalidatePrefix("fs_")
+	}
+}
@@ -0,0 +1,110 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKey(t *testing.T) {
+	a := CacheKey("web_fetch", "/repo", map[string]interface{}{"url": "https://go.dev", "raw": true})
+	b := CacheKey("web_fetch", "/repo", map[string]interface{}{"raw": true, "url": "https://go.dev"})
+	assert.Equal(t, a, b)
+
+	assert.NotEqual(t, a, CacheKey("web_fetch", "/other", map[string]interface{}{"url": "https://go.dev", "raw": true}))
+	assert.NotEqual(t, a, CacheKey("fs_read_file", "/repo", map[string]interface{}{"url": "https://go.dev", "raw": true}))
+}
+
+func TestResultCache(t *testing.T) {
+	t.Run("Only Configured Tools", func(t *testing.T) {
+		cache := NewResultCache(CacheConfig{TTLs: map[string]time.Duration{"fs_*": time.Minute}})
+		assert.True(t, cache.Cacheable("fs_read_file"))
+		assert.False(t, cache.Cacheable("action_build"))
+
+		cache.Put("action_build", "k", "result")
+		_, ok := cache.Get("k")
+		assert.False(t, ok)
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		cache := NewResultCache(CacheConfig{TTLs: map[string]time.Duration{"web_fetch": time.Millisecond}})
+		cache.Put("web_fetch", "k", "page")
+		time.Sleep(5 * time.Millisecond)
+		_, ok := cache.Get("k")
+		assert.False(t, ok)
+	})
+
+	t.Run("Evicts Least Recently Used", func(t *testing.T) {
+		cache := NewResultCache(CacheConfig{
+			TTLs:       map[string]time.Duration{"tool_*": time.Hour},
+			MaxEntries: 2,
+			MaxBytes:   10,
+		})
+
+		cache.Put("tool_a", "a", "1234")
+		cache.Put("tool_b", "b", "1234")
+		_, _ = cache.Get("a")
+		cache.Put("tool_c", "c", "1234")
+
+		_, ok := cache.Get("b")
+		assert.False(t, ok, "least recently used entry should be evicted")
+		_, ok = cache.Get("a")
+		assert.True(t, ok)
+
+		// Results larger than the byte bound are never stored
+		cache.Put("tool_d", "d", "12345678901")
+		_, ok = cache.Get("d")
+		assert.False(t, ok)
+	})
+}
+
+func TestCallToolCaching(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "notes.txt")
+	require.NoError(t, os.WriteFile(file, []byte("v1"), 0644))
+
+	s := NewStdioMCPServer(nil, nil, nil, nil, nil)
+	s.toolExecutor = NewToolExecutor(root)
+	s.SetFSPolicy(FSPolicy{Enabled: true})
+	s.SetResultCache(NewResultCache(CacheConfig{TTLs: map[string]time.Duration{"fs_*": time.Hour}}))
+
+	read := func(args map[string]interface{}) string {
+		result, err := s.callTool("fs_read_file", args, nil, root)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, "v1", read(map[string]interface{}{"path": "notes.txt"}))
+
+	// Changed on disk behind the server's back: the cached result is served
+	require.NoError(t, os.WriteFile(file, []byte("v2"), 0644))
+	assert.Equal(t, "v1", read(map[string]interface{}{"path": "notes.txt"}))
+
+	// _no_cache bypasses and refreshes the cache
+	assert.Equal(t, "v2", read(map[string]interface{}{"path": "notes.txt", NoCacheArg: true}))
+
+	// Writes through Opun are never cached and invalidate file reads
+	_, err := s.callTool("fs_write_file", map[string]interface{}{"path": "notes.txt", "content": "v3"}, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", read(map[string]interface{}{"path": "notes.txt"}))
+}
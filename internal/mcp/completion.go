@@ -0,0 +1,227 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/core"
+)
+
+// maxCompletionValues is the maximum number of values returned per completion,
+// as required by the MCP specification
+const maxCompletionValues = 100
+
+// handleCompletion handles the completion/complete request.
+//
+// Supported references:
+//   - ref/prompt: completes prompt arguments from variable options or file paths
+//   - ref/tool:   completes tool arguments from the input schema enum values
+func (s *StdioMCPServer) handleCompletion(id interface{}, params map[string]interface{}) {
+	ref, _ := params["ref"].(map[string]interface{})
+	argument, _ := params["argument"].(map[string]interface{})
+
+	refType, _ := ref["type"].(string)
+	refName, _ := ref["name"].(string)
+	argName, _ := argument["name"].(string)
+	argValue, _ := argument["value"].(string)
+
+	if argName == "" {
+		s.sendError(id, fmt.Errorf("completion argument name is required"))
+		return
+	}
+
+	var values []string
+	var err error
+
+	switch refType {
+	case "ref/prompt":
+		values, err = s.completePromptArgument(refName, argName, argValue)
+	case "ref/tool":
+		values, err = s.completeToolArgument(refName, argName, argValue)
+	case "ref/resource":
+		// Opun does not expose resources, so there is nothing to complete
+	default:
+		err = fmt.Errorf("unsupported completion reference type: %s", refType)
+	}
+
+	if err != nil {
+		s.sendError(id, err)
+		return
+	}
+
+	s.sendResponse(id, map[string]interface{}{
+		"completion": buildCompletion(values),
+	})
+}
+
+// completePromptArgument suggests values for a prompt argument
+func (s *StdioMCPServer) completePromptArgument(promptName, argName, value string) ([]string, error) {
+	if s.garden == nil {
+		return nil, fmt.Errorf("prompt garden not available")
+	}
+
+	prompt, err := s.garden.GetByName(promptName)
+	if err != nil {
+		return nil, fmt.Errorf("prompt not found: %s", promptName)
+	}
+
+	for _, v := range prompt.Variables() {
+		if v.Name != argName {
+			continue
+		}
+		return s.completeVariable(v, value), nil
+	}
+
+	return nil, fmt.Errorf("prompt %s has no argument %s", promptName, argName)
+}
+
+// completeVariable suggests values for a prompt variable from its metadata
+func (s *StdioMCPServer) completeVariable(v core.PromptVariable, value string) []string {
+	if len(v.Options) > 0 {
+		return filterCompletions(v.Options, value)
+	}
+
+	switch v.Type {
+	case "file":
+		return completeFilePath(s.toolExecutor.workingDir, value)
+	case "boolean":
+		return filterCompletions([]string{"true", "false"}, value)
+	case "prompt":
+		if s.garden == nil {
+			return nil
+		}
+		prompts := s.garden.Summaries()
+		names := make([]string, 0, len(prompts))
+		for _, p := range prompts {
+			names = append(names, p.Name)
+		}
+		return filterCompletions(names, value)
+	}
+
+	if v.DefaultValue != nil {
+		return filterCompletions([]string{fmt.Sprintf("%v", v.DefaultValue)}, value)
+	}
+
+	return nil
+}
+
+// completeToolArgument suggests values for a tool argument from its input schema
+func (s *StdioMCPServer) completeToolArgument(toolName, argName, value string) ([]string, error) {
+	for _, tool := range s.listTools() {
+		if name, _ := tool["name"].(string); name != toolName {
+			continue
+		}
+
+		schema, _ := tool["inputSchema"].(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+		property, ok := properties[argName].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tool %s has no argument %s", toolName, argName)
+		}
+
+		return filterCompletions(enumValues(property["enum"]), value), nil
+	}
+
+	return nil, fmt.Errorf("tool not found: %s", toolName)
+}
+
+// enumValues converts a JSON schema enum into strings
+func enumValues(enum interface{}) []string {
+	switch e := enum.(type) {
+	case []string:
+		return e
+	case []interface{}:
+		values := make([]string, 0, len(e))
+		for _, v := range e {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+		return values
+	}
+	return nil
+}
+
+// filterCompletions returns the candidates that start with value, case-insensitively
+func filterCompletions(candidates []string, value string) []string {
+	prefix := strings.ToLower(value)
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// completeFilePath suggests paths relative to baseDir that complete value.
+// Directories are suffixed with a path separator so clients can keep drilling down.
+// Nothing outside baseDir is offered, as for the filesystem tools.
+func completeFilePath(baseDir, value string) []string {
+	dir, prefix := filepath.Split(value)
+
+	searchDir, err := NewFSTools(baseDir, FSPolicy{}).resolve(dir)
+	if err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(searchDir)
+	if err != nil {
+		return nil
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		// Only offer hidden entries if the user started typing one
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		match := dir + name
+		if entry.IsDir() {
+			match += string(filepath.Separator)
+		}
+		matches = append(matches, match)
+	}
+
+	sort.Strings(matches)
+	return matches
+}
+
+// buildCompletion builds the completion result, truncated to the protocol limit
+func buildCompletion(values []string) map[string]interface{} {
+	total := len(values)
+	if values == nil {
+		values = []string{}
+	}
+	if total > maxCompletionValues {
+		values = values[:maxCompletionValues]
+	}
+
+	return map[string]interface{}{
+		"values":  values,
+		"total":   total,
+		"hasMore": total > maxCompletionValues,
+	}
+}
@@ -0,0 +1,130 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterCompletions(t *testing.T) {
+	candidates := []string{"Go", "golang", "python", "gopher"}
+
+	assert.Equal(t, []string{"Go", "golang", "gopher"}, filterCompletions(candidates, "go"))
+	assert.Equal(t, candidates, filterCompletions(candidates, ""))
+	assert.Empty(t, filterCompletions(candidates, "rust"))
+}
+
+func TestCompleteFilePath(t *testing.T) {
+	baseDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, "src", "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "src", "main.go"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, ".env"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "README.md"), []byte(""), 0644))
+
+	sep := string(filepath.Separator)
+
+	t.Run("Top Level", func(t *testing.T) {
+		assert.Equal(t, []string{"README.md", "src" + sep}, completeFilePath(baseDir, ""))
+	})
+
+	t.Run("Hidden Files On Request", func(t *testing.T) {
+		assert.Equal(t, []string{".env"}, completeFilePath(baseDir, "."))
+	})
+
+	t.Run("Nested Directory", func(t *testing.T) {
+		assert.Equal(t, []string{"src" + sep + "main.go"}, completeFilePath(baseDir, "src"+sep+"m"))
+	})
+
+	t.Run("Missing Directory", func(t *testing.T) {
+		assert.Empty(t, completeFilePath(baseDir, "nope"+sep))
+	})
+
+	t.Run("Outside The Project Root", func(t *testing.T) {
+		assert.Empty(t, completeFilePath(baseDir, ".."+sep))
+		assert.Empty(t, completeFilePath(baseDir, "src"+sep+".."+sep+".."+sep))
+		assert.Empty(t, completeFilePath(baseDir, "/etc/"))
+	})
+}
+
+func TestBuildCompletion(t *testing.T) {
+	values := make([]string, maxCompletionValues+5)
+	for i := range values {
+		values[i] = "v"
+	}
+
+	result := buildCompletion(values)
+	assert.Len(t, result["values"], maxCompletionValues)
+	assert.Equal(t, maxCompletionValues+5, result["total"])
+	assert.Equal(t, true, result["hasMore"])
+
+	empty := buildCompletion(nil)
+	assert.Equal(t, []string{}, empty["values"])
+	assert.Equal(t, false, empty["hasMore"])
+}
+
+func TestHandleCompletion(t *testing.T) {
+	garden, err := promptgarden.NewGarden(t.TempDir())
+	require.NoError(t, err)
+
+	metadata := core.PromptMetadata{
+		Name: "translate",
+		Variables: []core.PromptVariable{
+			{Name: "language", Type: "select", Options: []string{"french", "german", "finnish"}},
+		},
+	}
+	require.NoError(t, garden.Add(promptgarden.NewTemplatePrompt(metadata, "Translate to {{language}}")))
+
+	var out bytes.Buffer
+	server := &StdioMCPServer{
+		garden:       garden,
+		toolExecutor: NewToolExecutor(t.TempDir()),
+		writer:       &out,
+	}
+
+	server.handleRequest(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(1),
+		"method":  "completion/complete",
+		"params": map[string]interface{}{
+			"ref":      map[string]interface{}{"type": "ref/prompt", "name": "translate"},
+			"argument": map[string]interface{}{"name": "language", "value": "f"},
+		},
+	})
+
+	var response struct {
+		Result struct {
+			Completion struct {
+				Values  []string `json:"values"`
+				Total   int      `json:"total"`
+				HasMore bool     `json:"hasMore"`
+			} `json:"completion"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &response))
+	assert.Equal(t, []string{"french", "finnish"}, response.Result.Completion.Values)
+	assert.Equal(t, 2, response.Result.Completion.Total)
+	assert.False(t, response.Result.Completion.HasMore)
+}
@@ -0,0 +1,592 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+const (
+	// maxFSReadSize is the largest file read_file will return
+	maxFSReadSize = 1 << 20
+
+	// maxGrepMatches caps the number of matches grep returns
+	maxGrepMatches = 200
+)
+
+// FSPolicy controls the built-in filesystem tools
+type FSPolicy struct {
+	Enabled  bool     // expose the fs_ tools at all
+	ReadOnly bool     // hide write_file and apply_patch
+	Deny     []string // glob patterns (relative to the root) that can never be accessed
+	AuditLog string   // file that receives one JSON line per call, empty disables auditing
+}
+
+// DefaultFSPolicy returns the policy used when nothing is configured.
+// The tools are read-only until writes are explicitly allowed.
+func DefaultFSPolicy() FSPolicy {
+	policy := FSPolicy{
+		Enabled:  true,
+		ReadOnly: true,
+		Deny:     []string{".git", ".git/*", ".env", ".env.*", "*.pem", "*.key"},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		policy.AuditLog = filepath.Join(home, ".opun", "mcp", "fs-audit.log")
+	}
+	return policy
+}
+
+// FSTools implements the built-in filesystem tools, scoped to a project root
+type FSTools struct {
+	root   string
+	policy FSPolicy
+}
+
+// NewFSTools creates filesystem tools scoped to root
+func NewFSTools(root string, policy FSPolicy) *FSTools {
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return &FSTools{root: root, policy: policy}
+}
+
+// resolve turns a user-supplied path into an absolute path inside the root
+func (t *FSTools) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(t.root, path)
+	}
+	abs = filepath.Clean(abs)
+
+	// Resolve symlinks on the longest existing prefix so links can't escape the root
+	existing := abs
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+	if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+		abs = filepath.Join(append([]string{resolved}, rest...)...)
+	}
+
+	rel, err := filepath.Rel(t.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the project root", path)
+	}
+
+	if t.denied(rel) {
+		return "", fmt.Errorf("access to %s is denied by policy", path)
+	}
+
+	return abs, nil
+}
+
+// denied reports whether a root-relative path matches a deny pattern
+func (t *FSTools) denied(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range t.policy.Deny {
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(rel)); matched {
+			return true
+		}
+		// A denied directory denies everything beneath it
+		if !strings.ContainsAny(pattern, "*?[") && strings.HasPrefix(rel, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFile returns the content of a file, optionally limited to a line range
+func (t *FSTools) ReadFile(path string, startLine, endLine int) (string, error) {
+	abs, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > maxFSReadSize && startLine == 0 && endLine == 0 {
+		return "", fmt.Errorf("%s is too large (%d bytes); read a line range instead", path, info.Size())
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return "", err
+	}
+
+	if startLine == 0 && endLine == 0 {
+		return string(data), nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine == 0 || endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if startLine > endLine {
+		return "", fmt.Errorf("invalid line range %d-%d", startLine, endLine)
+	}
+
+	return strings.Join(lines[startLine-1:endLine], "\n"), nil
+}
+
+// WriteFile writes content to a file, creating parent directories as needed
+func (t *FSTools) WriteFile(path, content string) (string, error) {
+	if t.policy.ReadOnly {
+		return "", fmt.Errorf("filesystem tools are read-only")
+	}
+
+	abs, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(abs)); err != nil {
+		return "", err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(abs); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(abs, []byte(content), mode); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Wrote %d bytes to %s", len(content), path), nil
+}
+
+// ListDir lists the entries of a directory, directories suffixed with a separator
+func (t *FSTools) ListDir(path string) (string, error) {
+	abs, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, entry := range entries {
+		rel, _ := filepath.Rel(t.root, filepath.Join(abs, entry.Name()))
+		if t.denied(rel) {
+			continue
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			name += string(filepath.Separator)
+		}
+		sb.WriteString(name)
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+// Grep searches files under path for a regular expression.
+// include optionally restricts the search to file names matching a glob.
+func (t *FSTools) Grep(pattern, path, include string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	abs, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	errLimit := fmt.Errorf("match limit reached")
+
+	walkErr := filepath.WalkDir(abs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(t.root, p)
+		if d.IsDir() {
+			if p != abs && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || t.denied(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if t.denied(rel) {
+			return nil
+		}
+		if include != "" {
+			if matched, _ := filepath.Match(include, d.Name()); !matched {
+				return nil
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFSReadSize {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			// Skip unreadable and binary files
+			return nil
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), maxFSReadSize)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			if re.MatchString(scanner.Text()) {
+				matches = append(matches, fmt.Sprintf("%s:%d:%s", filepath.ToSlash(rel), lineNum, scanner.Text()))
+				if len(matches) >= maxGrepMatches {
+					return errLimit
+				}
+			}
+		}
+		return nil
+	})
+	if walkErr != nil && walkErr != errLimit {
+		return "", walkErr
+	}
+
+	if len(matches) == 0 {
+		return "No matches found", nil
+	}
+
+	result := strings.Join(matches, "\n")
+	if walkErr == errLimit {
+		result += fmt.Sprintf("\n... (stopped after %d matches)", maxGrepMatches)
+	}
+	return result, nil
+}
+
+// ApplyPatch applies a unified diff to files under the root.
+// All hunks are checked before any file is written.
+func (t *FSTools) ApplyPatch(diff string) (string, error) {
+	if t.policy.ReadOnly {
+		return "", fmt.Errorf("filesystem tools are read-only")
+	}
+
+	patches, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+
+	type change struct {
+		abs     string
+		path    string
+		content string
+		delete  bool
+		oldAbs  string // set for renames: the file to remove once the new one is written
+		oldPath string
+	}
+
+	var changes []change
+	for _, patch := range patches {
+		abs, err := t.resolve(patch.Path())
+		if err != nil {
+			return "", err
+		}
+
+		// Renames read the old path and create the new one
+		source, sourcePath := abs, patch.Path()
+		if patch.IsRename() {
+			if source, err = t.resolve(patch.OldPath); err != nil {
+				return "", err
+			}
+			sourcePath = patch.OldPath
+			if _, err := os.Stat(abs); err == nil {
+				return "", fmt.Errorf("cannot rename %s to %s: file already exists", patch.OldPath, patch.NewPath)
+			}
+		}
+
+		original := ""
+		if !patch.IsNew() {
+			data, err := os.ReadFile(source)
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s: %w", sourcePath, err)
+			}
+			original = string(data)
+		} else if _, err := os.Stat(abs); err == nil {
+			return "", fmt.Errorf("cannot create %s: file already exists", patch.Path())
+		}
+
+		content, err := ApplyPatch(original, patch)
+		if err != nil {
+			return "", err
+		}
+
+		c := change{abs: abs, path: patch.Path(), content: content, delete: patch.IsDelete()}
+		if patch.IsRename() {
+			c.oldAbs, c.oldPath = source, sourcePath
+		}
+		changes = append(changes, c)
+	}
+
+	var summary []string
+	for _, c := range changes {
+		if c.delete {
+			if err := os.Remove(c.abs); err != nil {
+				return "", err
+			}
+			summary = append(summary, "deleted "+c.path)
+			continue
+		}
+
+		if _, err := t.WriteFile(c.path, c.content); err != nil {
+			return "", err
+		}
+
+		if c.oldAbs != "" {
+			if err := os.Remove(c.oldAbs); err != nil {
+				return "", err
+			}
+			summary = append(summary, fmt.Sprintf("renamed %s to %s", c.oldPath, c.path))
+			continue
+		}
+		summary = append(summary, "patched "+c.path)
+	}
+
+	return fmt.Sprintf("Applied patch: %s", strings.Join(summary, ", ")), nil
+}
+
+// fsToolNames lists the built-in filesystem tools and whether they modify files
+var fsToolNames = map[string]bool{
+	"fs_read_file":   false,
+	"fs_list_dir":    false,
+	"fs_grep":        false,
+	"fs_write_file":  true,
+	"fs_apply_patch": true,
+}
+
+// SetFSPolicy configures the built-in filesystem tools
+func (s *StdioMCPServer) SetFSPolicy(policy FSPolicy) {
+	s.fsPolicy = policy
+}
+
+// fsToolDescriptors returns the descriptors for the enabled filesystem tools
+func (s *StdioMCPServer) fsToolDescriptors() []map[string]interface{} {
+	if !s.fsPolicy.Enabled {
+		return nil
+	}
+
+	pathProp := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "string",
+			"description": description,
+		}
+	}
+
+	schemas := map[string]struct {
+		description string
+		properties  map[string]interface{}
+		required    []string
+	}{
+		"fs_read_file": {
+			description: "Read a file in the project",
+			properties: map[string]interface{}{
+				"path":       pathProp("File path relative to the project root"),
+				"start_line": map[string]interface{}{"type": "integer", "description": "First line to read (1-based)"},
+				"end_line":   map[string]interface{}{"type": "integer", "description": "Last line to read (inclusive)"},
+			},
+			required: []string{"path"},
+		},
+		"fs_list_dir": {
+			description: "List the entries of a directory in the project",
+			properties: map[string]interface{}{
+				"path": pathProp("Directory path relative to the project root (default: root)"),
+			},
+		},
+		"fs_grep": {
+			description: "Search project files for a regular expression",
+			properties: map[string]interface{}{
+				"pattern": map[string]interface{}{"type": "string", "description": "Regular expression (Go syntax)"},
+				"path":    pathProp("Directory or file to search (default: root)"),
+				"include": map[string]interface{}{"type": "string", "description": "Only search file names matching this glob, e.g. *.go"},
+			},
+			required: []string{"pattern"},
+		},
+		"fs_write_file": {
+			description: "Create or overwrite a file in the project",
+			properties: map[string]interface{}{
+				"path":    pathProp("File path relative to the project root"),
+				"content": map[string]interface{}{"type": "string", "description": "Full file content"},
+			},
+			required: []string{"path", "content"},
+		},
+		"fs_apply_patch": {
+			description: "Apply a unified diff to files in the project",
+			properties: map[string]interface{}{
+				"patch": map[string]interface{}{"type": "string", "description": "Unified diff (as produced by git diff)"},
+			},
+			required: []string{"patch"},
+		},
+	}
+
+	names := make([]string, 0, len(fsToolNames))
+	for name, writes := range fsToolNames {
+		if writes && s.fsPolicy.ReadOnly {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tools := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		schema := schemas[name]
+		inputSchema := map[string]interface{}{
+			"type":       "object",
+			"properties": schema.properties,
+		}
+		if len(schema.required) > 0 {
+			inputSchema["required"] = schema.required
+		}
+
+		tools = append(tools, s.createToolDescriptor(
+			name,
+			"[Filesystem] "+schema.description,
+			"filesystem",
+			"1.0.0",
+			inputSchema,
+		))
+	}
+
+	return tools
+}
+
+// executeFSTool runs a built-in filesystem tool in the session's working directory
+func (s *StdioMCPServer) executeFSTool(tool string, args map[string]interface{}) (string, error) {
+	if !s.fsPolicy.Enabled {
+		return "", fmt.Errorf("filesystem tools are disabled")
+	}
+	if writes, exists := fsToolNames[tool]; !exists {
+		return "", fmt.Errorf("unknown filesystem tool: %s", tool)
+	} else if writes && s.fsPolicy.ReadOnly {
+		return "", fmt.Errorf("filesystem tools are read-only")
+	}
+
+	s.pendingMu.Lock()
+	root := s.toolExecutor.workingDir
+	client := ""
+	if s.session != nil {
+		client = s.session.ClientName
+	}
+	s.pendingMu.Unlock()
+
+	fsTools := NewFSTools(root, s.fsPolicy)
+
+	path, _ := args["path"].(string)
+	var result string
+	var err error
+
+	switch tool {
+	case "fs_read_file":
+		start, _ := args["start_line"].(float64)
+		end, _ := args["end_line"].(float64)
+		result, err = fsTools.ReadFile(path, int(start), int(end))
+	case "fs_list_dir":
+		result, err = fsTools.ListDir(path)
+	case "fs_grep":
+		pattern, _ := args["pattern"].(string)
+		include, _ := args["include"].(string)
+		result, err = fsTools.Grep(pattern, path, include)
+	case "fs_write_file":
+		content, _ := args["content"].(string)
+		result, err = fsTools.WriteFile(path, content)
+	case "fs_apply_patch":
+		patch, _ := args["patch"].(string)
+		result, err = fsTools.ApplyPatch(patch)
+	}
+
+	s.auditFSCall(client, tool, root, path, err)
+	return result, err
+}
+
+// auditFSCall appends a record of a filesystem tool call to the audit log
+func (s *StdioMCPServer) auditFSCall(client, tool, root, path string, callErr error) {
+	if s.fsPolicy.AuditLog == "" {
+		return
+	}
+
+	entry := map[string]interface{}{
+		"time":   time.Now().Format(time.RFC3339),
+		"client": client,
+		"tool":   tool,
+		"root":   root,
+		"path":   path,
+		"ok":     callErr == nil,
+	}
+	if callErr != nil {
+		entry["error"] = callErr.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(s.fsPolicy.AuditLog)); err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(s.fsPolicy.AuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "%s\n", data)
+}
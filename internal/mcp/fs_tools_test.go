@@ -0,0 +1,164 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFSTools(t *testing.T, policy FSPolicy) (*FSTools, string) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env"), []byte("SECRET=1\n"), 0644))
+	return NewFSTools(root, policy), root
+}
+
+func TestFSToolsScope(t *testing.T) {
+	fsTools, root := newTestFSTools(t, DefaultFSPolicy())
+
+	_, err := fsTools.ReadFile("../outside.txt", 0, 0)
+	assert.ErrorContains(t, err, "outside the project root")
+
+	_, err = fsTools.ReadFile("/etc/passwd", 0, 0)
+	assert.Error(t, err)
+
+	_, err = fsTools.ReadFile(".env", 0, 0)
+	assert.ErrorContains(t, err, "denied by policy")
+
+	// Symlinks can't be used to escape the root
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err == nil {
+		_, err = fsTools.ReadFile("link/secret.txt", 0, 0)
+		assert.ErrorContains(t, err, "outside the project root")
+	}
+}
+
+func writablePolicy() FSPolicy {
+	policy := DefaultFSPolicy()
+	policy.ReadOnly = false
+	return policy
+}
+
+func TestFSToolsReadWrite(t *testing.T) {
+	fsTools, root := newTestFSTools(t, writablePolicy())
+
+	content, err := fsTools.ReadFile("src/main.go", 3, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "func main() {\n\tprintln(\"hello\")", content)
+
+	_, err = fsTools.WriteFile("docs/notes.md", "# Notes\n")
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(root, "docs", "notes.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n", string(data))
+
+	listing, err := fsTools.ListDir("")
+	require.NoError(t, err)
+	assert.Contains(t, listing, "src"+string(filepath.Separator))
+	assert.NotContains(t, listing, ".env")
+
+	matches, err := fsTools.Grep(`println\(`, "", "*.go")
+	require.NoError(t, err)
+	assert.Equal(t, "src/main.go:4:\tprintln(\"hello\")", matches)
+
+	readOnly, _ := newTestFSTools(t, DefaultFSPolicy())
+	_, err = readOnly.WriteFile("file.txt", "x")
+	assert.ErrorContains(t, err, "read-only")
+	_, err = readOnly.ApplyPatch("--- /dev/null\n+++ b/file.txt\n@@ -0,0 +1 @@\n+x\n")
+	assert.ErrorContains(t, err, "read-only")
+}
+
+func TestFSToolsApplyPatch(t *testing.T) {
+	fsTools, root := newTestFSTools(t, writablePolicy())
+
+	patch := `diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -3,3 +3,3 @@
+ func main() {
+-	println("hello")
++	println("hello, world")
+ }
+--- /dev/null
++++ b/README.md
+@@ -0,0 +1,2 @@
++# Demo
++
+`
+
+	result, err := fsTools.ApplyPatch(patch)
+	require.NoError(t, err)
+	assert.Contains(t, result, "patched src/main.go")
+
+	data, err := os.ReadFile(filepath.Join(root, "src", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n\tprintln(\"hello, world\")\n}\n", string(data))
+
+	data, err = os.ReadFile(filepath.Join(root, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Demo\n\n", string(data))
+
+	t.Run("Rejects Mismatched Hunk Without Writing", func(t *testing.T) {
+		bad := `--- a/src/main.go
++++ b/src/main.go
+@@ -1,1 +1,1 @@
+-package lib
++package app
+`
+		_, err := fsTools.ApplyPatch(bad)
+		assert.ErrorContains(t, err, "does not apply")
+
+		data, _ := os.ReadFile(filepath.Join(root, "src", "main.go"))
+		assert.Contains(t, string(data), "package main")
+	})
+
+	t.Run("Renames", func(t *testing.T) {
+		rename := `diff --git a/src/main.go b/cmd/main.go
+--- a/src/main.go
++++ b/cmd/main.go
+@@ -1,1 +1,1 @@
+-package main
++package cmd
+`
+		result, err := fsTools.ApplyPatch(rename)
+		require.NoError(t, err)
+		assert.Contains(t, result, "renamed src/main.go to cmd/main.go")
+
+		data, err := os.ReadFile(filepath.Join(root, "cmd", "main.go"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "package cmd")
+		assert.NoFileExists(t, filepath.Join(root, "src", "main.go"))
+	})
+}
+
+func TestApplyPatchDrift(t *testing.T) {
+	original := "a\nb\nc\nd\ne\n"
+	patches, err := ParseUnifiedDiff("--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n d\n-e\n+E\n")
+	require.NoError(t, err)
+
+	// The hunk claims line 1 but the context is found further down
+	result, err := ApplyPatch(original, patches[0])
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\nd\nE\n", result)
+}
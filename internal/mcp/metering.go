@@ -0,0 +1,407 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/telemetry"
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+// rateLimitWindow is the window over which tool rate limits are enforced
+const rateLimitWindow = time.Minute
+
+// totalStatsFile holds the counters of MCP servers that have exited; a
+// server folds its own file into it when it closes its meter
+const totalStatsFile = "total.json"
+
+// statsLockTimeout bounds how long a closing server waits to fold its counters in
+var statsLockTimeout = 5 * time.Second
+
+// ToolStats holds usage counters for a single tool
+type ToolStats struct {
+	Calls       int64         `json:"calls"`
+	Errors      int64         `json:"errors"`
+	RateLimited int64         `json:"rate_limited"`
+	TotalTime   time.Duration `json:"total_time"`
+	LastCall    time.Time     `json:"last_call"`
+}
+
+// ToolMeter counts tool calls and enforces optional per-tool rate limits
+type ToolMeter struct {
+	mu        sync.Mutex
+	stats     map[string]*ToolStats
+	limits    map[string]int         // tool name or glob pattern -> calls per minute
+	patterns  []string               // glob limit keys, most specific first
+	windows   map[string][]time.Time // recent call times per tool
+	statsFile string
+	tracer    *telemetry.ToolTracer // exports a span per call when tracing is on
+}
+
+// NewToolMeter creates a tool meter with the given rate limits.
+// Limit keys are tool names or glob patterns such as "action_*".
+func NewToolMeter(limits map[string]int) *ToolMeter {
+	if limits == nil {
+		limits = make(map[string]int)
+	}
+
+	var patterns []string
+	for key := range limits {
+		if strings.ContainsAny(key, "*?[") {
+			patterns = append(patterns, key)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		a, b := literalLen(patterns[i]), literalLen(patterns[j])
+		if a != b {
+			return a > b
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	return &ToolMeter{
+		stats:    make(map[string]*ToolStats),
+		limits:   limits,
+		patterns: patterns,
+		windows:  make(map[string][]time.Time),
+		tracer:   telemetry.ToolTracerFromEnv(),
+	}
+}
+
+// literalLen counts the characters of a glob pattern that aren't wildcards,
+// so "action_build_*" is more specific than "action_*"
+func literalLen(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+}
+
+// SetStatsFile makes the meter persist its counters to path after every call,
+// so `opun mcp stats` can report on servers running in other processes
+func (m *ToolMeter) SetStatsFile(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statsFile = path
+}
+
+// Allow checks the rate limit for a tool and reserves a call slot if allowed
+func (m *ToolMeter) Allow(tool string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limit := m.limitFor(tool)
+	if limit <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-rateLimitWindow)
+
+	recent := m.windows[tool][:0]
+	for _, t := range m.windows[tool] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= limit {
+		m.windows[tool] = recent
+		m.toolStats(tool).RateLimited++
+		m.save()
+		return fmt.Errorf("rate limit exceeded for tool %s: %d calls per minute", tool, limit)
+	}
+
+	m.windows[tool] = append(recent, now)
+	return nil
+}
+
+// Record records a completed tool call
+func (m *ToolMeter) Record(tool string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.toolStats(tool)
+	stats.Calls++
+	stats.TotalTime += duration
+	stats.LastCall = time.Now()
+	if err != nil {
+		stats.Errors++
+	}
+
+	if m.tracer != nil {
+		m.tracer.Record(tool, duration, err)
+	}
+	m.save()
+}
+
+// Snapshot returns a copy of the current counters
+func (m *ToolMeter) Snapshot() map[string]ToolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]ToolStats, len(m.stats))
+	for tool, stats := range m.stats {
+		snapshot[tool] = *stats
+	}
+	return snapshot
+}
+
+// limitFor returns the calls-per-minute limit for a tool, preferring exact
+// matches, then the most specific pattern
+func (m *ToolMeter) limitFor(tool string) int {
+	if limit, ok := m.limits[tool]; ok {
+		return limit
+	}
+
+	for _, pattern := range m.patterns {
+		if matched, _ := filepath.Match(pattern, tool); matched {
+			return m.limits[pattern]
+		}
+	}
+
+	return 0
+}
+
+// toolStats returns the counters for a tool, creating them if needed.
+// Callers must hold the lock.
+func (m *ToolMeter) toolStats(tool string) *ToolStats {
+	stats, exists := m.stats[tool]
+	if !exists {
+		stats = &ToolStats{}
+		m.stats[tool] = stats
+	}
+	return stats
+}
+
+// save persists the counters if a stats file is configured.
+// Callers must hold the lock.
+func (m *ToolMeter) save() {
+	if m.statsFile == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(m.stats, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(m.statsFile)); err != nil {
+		return
+	}
+
+	// Stats are best effort and must never break tool calls
+	_ = os.WriteFile(m.statsFile, data, 0644)
+}
+
+// Close folds the meter's counters into the stats directory's total and
+// removes its own stats file, so files of exited servers don't pile up.
+// When the total is busy for too long the file is left for LoadToolStats.
+func (m *ToolMeter) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.statsFile == "" {
+		return nil
+	}
+	statsFile := m.statsFile
+	m.statsFile = ""
+	if len(m.stats) == 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(statsFile)
+	lockData, err := json.Marshal(map[string]interface{}{"pid": os.Getpid()})
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(statsLockTimeout)
+	for {
+		lock, _, err := utils.TryLockFile(filepath.Join(dir, "total.lock"), lockData)
+		if err != nil {
+			return err
+		}
+		if lock != nil {
+			defer lock.Release()
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting to save MCP stats, keeping %s", statsFile)
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+
+	totalPath := filepath.Join(dir, totalStatsFile)
+	total := make(map[string]ToolStats)
+	// #nosec G304 -- the total lives in the stats directory
+	if data, err := os.ReadFile(totalPath); err == nil {
+		if err := json.Unmarshal(data, &total); err != nil {
+			return fmt.Errorf("failed to read %s: %w", totalPath, err)
+		}
+	}
+	for tool, stats := range m.stats {
+		addToolStats(total, tool, *stats)
+	}
+
+	data, err := json.MarshalIndent(total, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(totalPath, data); err != nil {
+		return err
+	}
+	return os.Remove(statsFile)
+}
+
+// ParseRateLimits converts the mcp_rate_limits config map into per-tool limits
+func ParseRateLimits(raw map[string]interface{}) (map[string]int, error) {
+	limits := make(map[string]int, len(raw))
+	for tool, value := range raw {
+		var limit int
+		switch v := value.(type) {
+		case int:
+			limit = v
+		case int64:
+			limit = int(v)
+		case float64:
+			limit = int(v)
+		case string:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate limit for %s: %q", tool, v)
+			}
+			limit = n
+		default:
+			return nil, fmt.Errorf("invalid rate limit for %s: %v", tool, value)
+		}
+
+		if limit < 0 {
+			return nil, fmt.Errorf("invalid rate limit for %s: must not be negative", tool)
+		}
+		limits[tool] = limit
+	}
+	return limits, nil
+}
+
+// StatsDir returns the directory where MCP servers persist tool usage counters
+func StatsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "mcp", "stats"), nil
+}
+
+// LoadToolStats aggregates the counters persisted in dir: the total of servers
+// that have exited and the files of running ones
+func LoadToolStats(dir string) (map[string]ToolStats, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]ToolStats{}, nil
+		}
+		return nil, err
+	}
+
+	total := make(map[string]ToolStats)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		var stats map[string]ToolStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			continue
+		}
+
+		for tool, s := range stats {
+			addToolStats(total, tool, s)
+		}
+	}
+
+	return total, nil
+}
+
+// addToolStats adds a tool's counters to a total
+func addToolStats(total map[string]ToolStats, tool string, s ToolStats) {
+	agg := total[tool]
+	agg.Calls += s.Calls
+	agg.Errors += s.Errors
+	agg.RateLimited += s.RateLimited
+	agg.TotalTime += s.TotalTime
+	if s.LastCall.After(agg.LastCall) {
+		agg.LastCall = s.LastCall
+	}
+	total[tool] = agg
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, stats map[string]ToolStats) {
+	tools := make([]string, 0, len(stats))
+	for tool := range stats {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	metrics := []struct {
+		name  string
+		help  string
+		value func(ToolStats) string
+	}{
+		{"opun_mcp_tool_calls_total", "Total number of MCP tool calls.", func(s ToolStats) string { return strconv.FormatInt(s.Calls, 10) }},
+		{"opun_mcp_tool_errors_total", "Total number of MCP tool calls that failed.", func(s ToolStats) string { return strconv.FormatInt(s.Errors, 10) }},
+		{"opun_mcp_tool_rate_limited_total", "Total number of MCP tool calls rejected by rate limits.", func(s ToolStats) string { return strconv.FormatInt(s.RateLimited, 10) }},
+		{"opun_mcp_tool_duration_seconds_total", "Total time spent executing MCP tool calls.", func(s ToolStats) string { return strconv.FormatFloat(s.TotalTime.Seconds(), 'f', -1, 64) }},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s counter\n", metric.name)
+		for _, tool := range tools {
+			fmt.Fprintf(w, "%s{tool=%q} %s\n", metric.name, tool, metric.value(stats[tool]))
+		}
+	}
+}
+
+// SetToolMeter replaces the meter used to count and rate limit tool calls
+func (s *StdioMCPServer) SetToolMeter(meter *ToolMeter) {
+	s.meter = meter
+}
+
+// SetToolMeter replaces the meter used to count and rate limit tool calls
+func (s *OpunMCPServer) SetToolMeter(meter *ToolMeter) {
+	s.meter = meter
+}
+
+// handleMetrics serves tool usage counters for Prometheus
+func (s *OpunMCPServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	WritePrometheus(w, s.meter.Snapshot())
+}
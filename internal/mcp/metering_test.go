@@ -0,0 +1,139 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolMeterRateLimit(t *testing.T) {
+	meter := NewToolMeter(map[string]int{
+		"action_*":     2,
+		"action_build": 3,
+	})
+
+	// Pattern limit applies to every matching tool independently
+	require.NoError(t, meter.Allow("action_test"))
+	require.NoError(t, meter.Allow("action_test"))
+	assert.Error(t, meter.Allow("action_test"))
+	require.NoError(t, meter.Allow("action_lint"))
+
+	// Exact match takes precedence over the pattern
+	for i := 0; i < 3; i++ {
+		require.NoError(t, meter.Allow("action_build"))
+	}
+	assert.Error(t, meter.Allow("action_build"))
+
+	// Unlimited tools are never rejected
+	for i := 0; i < 10; i++ {
+		require.NoError(t, meter.Allow("prompt_review"))
+	}
+
+	stats := meter.Snapshot()
+	assert.Equal(t, int64(1), stats["action_test"].RateLimited)
+	assert.Equal(t, int64(1), stats["action_build"].RateLimited)
+}
+
+func TestToolMeterPatternSpecificity(t *testing.T) {
+	meter := NewToolMeter(map[string]int{
+		"*":              100,
+		"action_*":       10,
+		"action_deploy*": 1,
+		"action_re*":     5,
+	})
+
+	// The most specific matching pattern wins, whatever the map order
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, 1, meter.limitFor("action_deploy_prod"))
+		assert.Equal(t, 5, meter.limitFor("action_redeploy"))
+		assert.Equal(t, 10, meter.limitFor("action_lint"))
+		assert.Equal(t, 100, meter.limitFor("prompt_review"))
+	}
+}
+
+func TestToolMeterPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	first := NewToolMeter(nil)
+	first.SetStatsFile(filepath.Join(dir, "server-1.json"))
+	first.Record("prompt_review", 2*time.Second, nil)
+	first.Record("prompt_review", time.Second, errors.New("failed"))
+
+	second := NewToolMeter(nil)
+	second.SetStatsFile(filepath.Join(dir, "server-2.json"))
+	second.Record("prompt_review", time.Second, nil)
+	second.Record("workflow_build", time.Minute, nil)
+
+	stats, err := LoadToolStats(dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats["prompt_review"].Calls)
+	assert.Equal(t, int64(1), stats["prompt_review"].Errors)
+	assert.Equal(t, 4*time.Second, stats["prompt_review"].TotalTime)
+	assert.Equal(t, int64(1), stats["workflow_build"].Calls)
+
+	missing, err := LoadToolStats(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	t.Run("Close Folds Into The Total", func(t *testing.T) {
+		require.NoError(t, first.Close())
+		require.NoError(t, second.Close())
+		assert.NoFileExists(t, filepath.Join(dir, "server-1.json"))
+		assert.NoFileExists(t, filepath.Join(dir, "server-2.json"))
+
+		closed, err := LoadToolStats(dir)
+		require.NoError(t, err)
+		assert.Equal(t, stats, closed)
+
+		// Calls after Close aren't written anywhere
+		first.Record("prompt_review", time.Second, nil)
+		assert.NoFileExists(t, filepath.Join(dir, "server-1.json"))
+		require.NoError(t, first.Close())
+	})
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits(map[string]interface{}{
+		"action_*":  10,
+		"tool_fast": "5",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"action_*": 10, "tool_fast": 5}, limits)
+
+	_, err = ParseRateLimits(map[string]interface{}{"action_*": "lots"})
+	assert.Error(t, err)
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var buf bytes.Buffer
+	WritePrometheus(&buf, map[string]ToolStats{
+		"action_test": {Calls: 4, Errors: 1, TotalTime: 1500 * time.Millisecond},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "# TYPE opun_mcp_tool_calls_total counter")
+	assert.Contains(t, out, `opun_mcp_tool_calls_total{tool="action_test"} 4`)
+	assert.Contains(t, out, `opun_mcp_tool_errors_total{tool="action_test"} 1`)
+	assert.Contains(t, out, `opun_mcp_tool_duration_seconds_total{tool="action_test"} 1.5`)
+}
@@ -0,0 +1,375 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// operationRetention is how long finished operations are kept for status queries
+const operationRetention = time.Hour
+
+// OperationStatus represents the status of a long-running operation
+type OperationStatus string
+
+const (
+	OperationQueued    OperationStatus = "queued"
+	OperationRunning   OperationStatus = "running"
+	OperationCompleted OperationStatus = "completed"
+	OperationFailed    OperationStatus = "failed"
+)
+
+// Operation tracks a tool call that runs in the background
+type Operation struct {
+	ID        string          `json:"id"`
+	Tool      string          `json:"tool"`
+	Status    OperationStatus `json:"status"`
+	Progress  int             `json:"progress"`
+	Total     int             `json:"total,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Result    string          `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   *time.Time      `json:"end_time,omitempty"`
+	// QueuePosition is the operation's place in the workflow queue while it is queued
+	QueuePosition int `json:"queue_position,omitempty"`
+}
+
+// OperationManager keeps track of long-running operations
+type OperationManager struct {
+	mu         sync.RWMutex
+	operations map[string]*Operation
+	counter    int
+}
+
+// NewOperationManager creates a new operation manager
+func NewOperationManager() *OperationManager {
+	return &OperationManager{
+		operations: make(map[string]*Operation),
+	}
+}
+
+// Start registers a new running operation for a tool and returns its ID
+func (m *OperationManager) Start(tool string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+
+	m.counter++
+	id := fmt.Sprintf("op-%d-%d", time.Now().Unix(), m.counter)
+	m.operations[id] = &Operation{
+		ID:        id,
+		Tool:      tool,
+		Status:    OperationRunning,
+		StartTime: time.Now(),
+	}
+
+	return id
+}
+
+// Queue records that an operation waits for its turn, at a 1-based position
+func (m *OperationManager) Queue(id string, position int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, exists := m.operations[id]
+	if !exists || (op.Status != OperationRunning && op.Status != OperationQueued) {
+		return
+	}
+
+	op.Status = OperationQueued
+	op.QueuePosition = position
+	op.Message = message
+}
+
+// Update records progress for a running or queued operation; a queued one
+// is now running
+func (m *OperationManager) Update(id string, progress, total int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, exists := m.operations[id]
+	if !exists || (op.Status != OperationRunning && op.Status != OperationQueued) {
+		return
+	}
+
+	op.Status = OperationRunning
+	op.QueuePosition = 0
+	op.Progress = progress
+	if total > 0 {
+		op.Total = total
+	}
+	op.Message = message
+}
+
+// Finish marks an operation as completed or failed
+func (m *OperationManager) Finish(id string, result string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, exists := m.operations[id]
+	if !exists {
+		return
+	}
+
+	endTime := time.Now()
+	op.EndTime = &endTime
+	op.Result = result
+	if err != nil {
+		op.Status = OperationFailed
+		op.Error = err.Error()
+		return
+	}
+
+	op.Status = OperationCompleted
+	if op.Total > 0 {
+		op.Progress = op.Total
+	}
+}
+
+// Get returns a snapshot of an operation
+func (m *OperationManager) Get(id string) (Operation, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	op, exists := m.operations[id]
+	if !exists {
+		return Operation{}, false
+	}
+
+	return *op, true
+}
+
+// List returns snapshots of all known operations, oldest first
+func (m *OperationManager) List() []Operation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ops := make([]Operation, 0, len(m.operations))
+	for _, op := range m.operations {
+		ops = append(ops, *op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].StartTime.Before(ops[j].StartTime)
+	})
+
+	return ops
+}
+
+// prune removes finished operations older than the retention period.
+// Callers must hold the lock.
+func (m *OperationManager) prune() {
+	cutoff := time.Now().Add(-operationRetention)
+	for id, op := range m.operations {
+		if op.EndTime != nil && op.EndTime.Before(cutoff) {
+			delete(m.operations, id)
+		}
+	}
+}
+
+// operationToolDescriptors returns the tools used to poll background operations
+func (s *StdioMCPServer) operationToolDescriptors() []map[string]interface{} {
+	idSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID returned when the operation was started (omit to list all operations)",
+			},
+		},
+	}
+
+	return []map[string]interface{}{
+		s.createToolDescriptor(
+			"operation_status",
+			"[Operation] Check the progress of a long-running operation such as a workflow run",
+			"operation",
+			"1.0.0",
+			idSchema,
+		),
+		s.createToolDescriptor(
+			"operation_result",
+			"[Operation] Get the result of a finished long-running operation",
+			"operation",
+			"1.0.0",
+			map[string]interface{}{
+				"type":       "object",
+				"properties": idSchema["properties"],
+				"required":   []string{"operation_id"},
+			},
+		),
+	}
+}
+
+// executeOperationTool handles the operation_status and operation_result tools
+func (s *StdioMCPServer) executeOperationTool(tool string, args map[string]interface{}) (string, error) {
+	opID, _ := args["operation_id"].(string)
+
+	switch tool {
+	case "operation_status":
+		if opID == "" {
+			data, err := json.MarshalIndent(s.operations.List(), "", "  ")
+			if err != nil {
+				return "", err
+			}
+			return string(data), nil
+		}
+
+		op, exists := s.operations.Get(opID)
+		if !exists {
+			return "", fmt.Errorf("operation not found: %s", opID)
+		}
+		// The result can be large, it is only returned by operation_result
+		op.Result = ""
+		data, err := json.MarshalIndent(op, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+
+	case "operation_result":
+		if opID == "" {
+			return "", fmt.Errorf("operation_id is required")
+		}
+
+		op, exists := s.operations.Get(opID)
+		if !exists {
+			return "", fmt.Errorf("operation not found: %s", opID)
+		}
+
+		switch op.Status {
+		case OperationQueued:
+			return fmt.Sprintf("Operation %s is queued at position %d behind other workflows. Check again later with operation_status.", op.ID, op.QueuePosition), nil
+		case OperationRunning:
+			return fmt.Sprintf("Operation %s is still running (%s). Check again later with operation_status.", op.ID, formatOperationProgress(op)), nil
+		case OperationFailed:
+			return "", fmt.Errorf("operation %s failed: %s", op.ID, op.Error)
+		}
+		return op.Result, nil
+	}
+
+	return "", fmt.Errorf("unknown operation tool: %s", tool)
+}
+
+// startWorkflowOperation runs a workflow in the background and returns immediately.
+// If the client supplied a progress token, progress notifications are streamed for it.
+func (s *StdioMCPServer) startWorkflowOperation(tool string, args map[string]interface{}, progressToken interface{}) (string, error) {
+	if s.workflowMgr == nil {
+		return "", fmt.Errorf("workflow manager not available")
+	}
+
+	workflowName := strings.TrimPrefix(tool, "workflow_")
+	argsStr, _ := args["args"].(string)
+
+	opID := s.operations.Start(tool)
+
+	// Tell the caller up front when the run has to wait its turn
+	started := "started"
+	if queue := s.workflowMgr.QueueStatus(); queue.MaxConcurrent > 0 && queue.Running+queue.Queued >= queue.MaxConcurrent {
+		started = fmt.Sprintf("queued at position %d (%d of %d workflows running)", queue.Queued+1, queue.Running, queue.MaxConcurrent)
+	}
+
+	go func() {
+		total := 0
+		completed := 0
+
+		onEvent := func(event workflow.WorkflowEvent) {
+			switch event.Type {
+			case workflow.EventWorkflowQueued:
+				position, _ := event.Data["position"].(int)
+				s.operations.Queue(opID, position, event.Message)
+				if progressToken != nil {
+					s.sendProgress(progressToken, 0, 0, event.Message)
+				}
+				return
+			case workflow.EventWorkflowStart:
+				if n, ok := event.Data["total_agents"].(int); ok {
+					total = n
+				}
+			case workflow.EventAgentComplete, workflow.EventAgentError:
+				completed++
+			}
+
+			s.operations.Update(opID, completed, total, event.Message)
+			if progressToken != nil {
+				s.sendProgress(progressToken, completed, total, event.Message)
+			}
+		}
+
+		result, err := s.workflowMgr.ExecuteWithProgress(context.Background(), workflowName, map[string]interface{}{
+			"args": argsStr,
+		}, onEvent)
+		if err != nil {
+			s.operations.Finish(opID, "", err)
+			return
+		}
+
+		s.operations.Finish(opID, formatWorkflowResult(workflowName, result), nil)
+	}()
+
+	return fmt.Sprintf("Workflow '%s' %s as operation %s.\nUse operation_status to follow progress and operation_result to fetch the result.", workflowName, started, opID), nil
+}
+
+// formatOperationProgress renders progress as "n/total agents"
+func formatOperationProgress(op Operation) string {
+	if op.Total > 0 {
+		return fmt.Sprintf("%d/%d agents", op.Progress, op.Total)
+	}
+	return fmt.Sprintf("%d agents completed", op.Progress)
+}
+
+// formatWorkflowResult summarizes a finished workflow execution
+func formatWorkflowResult(name string, result interface{}) string {
+	state, ok := result.(*workflow.ExecutionState)
+	if !ok || state == nil {
+		return fmt.Sprintf("Workflow '%s' executed successfully:\n%v", name, result)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Workflow '%s' %s", name, state.Status)
+	if state.EndTime != nil {
+		fmt.Fprintf(&sb, " in %s", state.EndTime.Sub(state.StartTime).Round(time.Second))
+	}
+	sb.WriteString("\n")
+
+	agentIDs := make([]string, 0, len(state.AgentStates))
+	for id := range state.AgentStates {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+
+	for _, id := range agentIDs {
+		agentState := state.AgentStates[id]
+		fmt.Fprintf(&sb, "- %s: %s", id, agentState.Status)
+		if agentState.Error != nil {
+			fmt.Fprintf(&sb, " (%s)", agentState.Error.Message)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
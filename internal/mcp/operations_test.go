@@ -0,0 +1,114 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationManager(t *testing.T) {
+	mgr := NewOperationManager()
+
+	t.Run("Progress And Completion", func(t *testing.T) {
+		id := mgr.Start("workflow_build")
+
+		mgr.Update(id, 1, 3, "Agent 1/3: analyze")
+		op, ok := mgr.Get(id)
+		require.True(t, ok)
+		assert.Equal(t, OperationRunning, op.Status)
+		assert.Equal(t, 1, op.Progress)
+		assert.Equal(t, 3, op.Total)
+		assert.Equal(t, "1/3 agents", formatOperationProgress(op))
+
+		mgr.Finish(id, "done", nil)
+		op, _ = mgr.Get(id)
+		assert.Equal(t, OperationCompleted, op.Status)
+		assert.Equal(t, 3, op.Progress)
+		assert.Equal(t, "done", op.Result)
+		assert.NotNil(t, op.EndTime)
+
+		// Updates after completion are ignored
+		mgr.Update(id, 0, 3, "late event")
+		op, _ = mgr.Get(id)
+		assert.Equal(t, 3, op.Progress)
+	})
+
+	t.Run("Queued", func(t *testing.T) {
+		id := mgr.Start("workflow_lint")
+
+		mgr.Queue(id, 2, "Workflow lint is queued at position 2")
+		op, ok := mgr.Get(id)
+		require.True(t, ok)
+		assert.Equal(t, OperationQueued, op.Status)
+		assert.Equal(t, 2, op.QueuePosition)
+
+		// The first progress event means it started
+		mgr.Update(id, 0, 2, "Starting workflow")
+		op, _ = mgr.Get(id)
+		assert.Equal(t, OperationRunning, op.Status)
+		assert.Zero(t, op.QueuePosition)
+		mgr.Finish(id, "done", nil)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		id := mgr.Start("workflow_deploy")
+		mgr.Finish(id, "", errors.New("agent deploy failed"))
+
+		op, ok := mgr.Get(id)
+		require.True(t, ok)
+		assert.Equal(t, OperationFailed, op.Status)
+		assert.Equal(t, "agent deploy failed", op.Error)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		ops := mgr.List()
+		require.Len(t, ops, 3)
+		assert.Equal(t, "workflow_build", ops[0].Tool)
+	})
+
+	t.Run("Unknown Operation", func(t *testing.T) {
+		_, ok := mgr.Get("op-missing")
+		assert.False(t, ok)
+	})
+}
+
+func TestExecuteOperationTool(t *testing.T) {
+	s := &StdioMCPServer{operations: NewOperationManager()}
+
+	id := s.operations.Start("workflow_build")
+
+	result, err := s.executeOperationTool("operation_result", map[string]interface{}{"operation_id": id})
+	require.NoError(t, err)
+	assert.Contains(t, result, "still running")
+
+	s.operations.Finish(id, "Workflow 'build' completed", nil)
+	result, err = s.executeOperationTool("operation_result", map[string]interface{}{"operation_id": id})
+	require.NoError(t, err)
+	assert.Equal(t, "Workflow 'build' completed", result)
+
+	status, err := s.executeOperationTool("operation_status", map[string]interface{}{"operation_id": id})
+	require.NoError(t, err)
+	assert.Contains(t, status, `"status": "completed"`)
+	assert.NotContains(t, status, "Workflow 'build' completed")
+
+	_, err = s.executeOperationTool("operation_result", map[string]interface{}{})
+	assert.Error(t, err)
+}
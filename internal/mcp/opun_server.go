@@ -29,6 +29,7 @@ import (
 	"github.com/rizome-dev/opun/internal/command"
 	"github.com/rizome-dev/opun/internal/plugin"
 	"github.com/rizome-dev/opun/internal/promptgarden"
+	toolslib "github.com/rizome-dev/opun/internal/tools"
 	"github.com/rizome-dev/opun/pkg/core"
 	"gopkg.in/yaml.v3"
 )
@@ -40,6 +41,9 @@ type OpunMCPServer struct {
 	manager  *plugin.Manager
 	port     int
 	server   *http.Server
+	meter    *ToolMeter
+	sessions *SessionManager
+	actions  *toolslib.Registry
 }
 
 // NewOpunMCPServer creates a new unified MCP server for Opun
@@ -49,6 +53,8 @@ func NewOpunMCPServer(garden *promptgarden.Garden, registry *command.Registry, m
 		registry: registry,
 		manager:  manager,
 		port:     port,
+		meter:    NewToolMeter(nil),
+		sessions: NewSessionManager(nil),
 	}
 }
 
@@ -58,10 +64,13 @@ func (s *OpunMCPServer) Start(ctx context.Context) error {
 
 	// MCP protocol endpoints
 	mux.HandleFunc("/", s.handleRoot)
+	mux.HandleFunc("/initialize", s.handleInitialize)
+	mux.HandleFunc("/session", s.handleSession)
 	mux.HandleFunc("/tools", s.handleTools)
 	mux.HandleFunc("/tool/call", s.handleToolCall)
 	mux.HandleFunc("/prompts/list", s.handlePromptsList)
 	mux.HandleFunc("/prompts/get", s.handlePromptsGet)
+	mux.HandleFunc("/metrics", s.handleMetrics)
 
 	s.server = &http.Server{
 		Addr:              fmt.Sprintf("localhost:%d", s.port),
@@ -113,6 +122,91 @@ func (s *OpunMCPServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleInitialize starts a client session from MCP initialize params.
+// Clients send the returned session ID in the Mcp-Session-Id header.
+func (s *OpunMCPServer) handleInitialize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var params map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session := s.sessions.Create(params)
+
+	response := map[string]interface{}{
+		"sessionId":       session.ID,
+		"protocolVersion": "2024-11-05",
+		"capabilities": map[string]interface{}{
+			"tools":   map[string]interface{}{},
+			"prompts": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    "opun",
+			"version": "1.0.0",
+		},
+	}
+
+	w.Header().Set(SessionHeader, session.ID)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// handleSession returns (GET) or ends (DELETE) the caller's session
+func (s *OpunMCPServer) handleSession(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(SessionHeader)
+	session, exists := s.sessions.Get(id)
+	if !exists {
+		http.Error(w, fmt.Sprintf("unknown or expired session: %s", id), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response := map[string]interface{}{
+			"session": session,
+			"results": session.RecentResults(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+		}
+	case http.MethodDelete:
+		s.sessions.Remove(session.ID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// SetActionRegistry sets the actions exposed as action_ tools
+func (s *OpunMCPServer) SetActionRegistry(actions *toolslib.Registry) {
+	s.actions = actions
+}
+
+// sessionFor returns the session for a request along with the HTTP status to
+// reply with when there is none. Every tool call needs a session so the
+// client's ACL and working directory always apply.
+func (s *OpunMCPServer) sessionFor(r *http.Request) (*Session, int, error) {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("missing %s header: call /initialize first and send the session ID it returns", SessionHeader)
+	}
+
+	session, exists := s.sessions.Get(id)
+	if !exists {
+		return nil, http.StatusNotFound, fmt.Errorf("unknown or expired session: %s", id)
+	}
+	return session, http.StatusOK, nil
+}
+
 // handleTools returns all available tools (MCP tools, actions, commands)
 func (s *OpunMCPServer) handleTools(w http.ResponseWriter, r *http.Request) {
 	tools := []map[string]interface{}{}
@@ -168,6 +262,12 @@ func (s *OpunMCPServer) handleTools(w http.ResponseWriter, r *http.Request) {
 		// which are handled separately through the action registry
 	}
 
+	// Add standardized actions from action registry
+	if s.actions != nil {
+		translator := toolslib.NewTranslator(s.actions)
+		tools = append(tools, translator.GetMCPActions("")...)
+	}
+
 	// Add slash commands as tools
 	if s.registry != nil {
 		commands := s.registry.List()
@@ -224,8 +324,24 @@ func (s *OpunMCPServer) handleToolCall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	session, status, err := s.sessionFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if !session.Allowed(request.Tool) {
+		http.Error(w, fmt.Sprintf("tool %s is not allowed for client %s", request.Tool, session.ClientName), http.StatusForbidden)
+		return
+	}
+
+	if err := s.meter.Allow(request.Tool); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
 	var result string
-	var err error
+	start := time.Now()
 
 	// Determine tool type and execute
 	switch {
@@ -237,10 +353,15 @@ func (s *OpunMCPServer) handleToolCall(w http.ResponseWriter, r *http.Request) {
 		result, err = s.executeCommand(request.Tool, request.Arguments)
 	case strings.HasPrefix(request.Tool, "tool_"):
 		result, err = s.executeMCPTool(request.Tool, request.Arguments)
+	case strings.HasPrefix(request.Tool, "action_"):
+		result, err = s.executeAction(r.Context(), session, request.Tool, request.Arguments)
 	default:
 		err = fmt.Errorf("unknown tool type: %s", request.Tool)
 	}
 
+	s.meter.Record(request.Tool, time.Since(start), err)
+	session.RecordResult(request.Tool, result, err)
+
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -339,6 +460,37 @@ func (s *OpunMCPServer) executeCommand(tool string, args map[string]interface{})
 	return fmt.Sprintf("Execute command: /%s %s", cmd.Name, argsStr), nil
 }
 
+// executeAction runs an action with the session's executor, so commands and
+// steps run in the client's working directory
+func (s *OpunMCPServer) executeAction(ctx context.Context, session *Session, tool string, args map[string]interface{}) (string, error) {
+	if s.actions == nil {
+		return "", fmt.Errorf("action registry not available")
+	}
+
+	actionID := strings.TrimPrefix(tool, "action_")
+	action, err := s.actions.Get(actionID)
+	if err != nil {
+		return "", fmt.Errorf("action not found: %s", actionID)
+	}
+
+	arguments, _ := args["arguments"].(string)
+
+	if result, handled, err := session.Executor.RunAction(ctx, action, arguments); handled {
+		return result, err
+	} else if action.PromptRef != "" {
+		if s.garden == nil {
+			return "", fmt.Errorf("prompt garden not available for action: %s", action.Name)
+		}
+		return s.garden.Execute(action.PromptRef, map[string]interface{}{
+			"args": arguments,
+		})
+	} else if action.WorkflowRef != "" {
+		return "", fmt.Errorf("workflow action '%s' is only available over stdio (opun mcp stdio)", action.Name)
+	}
+
+	return "", fmt.Errorf("action '%s' has no execution method defined", action.Name)
+}
+
 // executeMCPTool executes an MCP tool
 func (s *OpunMCPServer) executeMCPTool(tool string, args map[string]interface{}) (string, error) {
 	// Extract tool name
@@ -462,45 +614,23 @@ func (s *OpunMCPServer) GetInfo() core.MCPServer {
 	}
 }
 
-// handlePromptsList returns the list of available prompts
+// handlePromptsList returns a page of the available prompts, the one after
+// the cursor query parameter when it is given
 func (s *OpunMCPServer) handlePromptsList(w http.ResponseWriter, r *http.Request) {
-	prompts := []map[string]interface{}{}
+	response := map[string]interface{}{
+		"prompts": []map[string]interface{}{},
+	}
 
 	if s.garden != nil {
-		gardenPrompts, err := s.garden.List()
-		if err == nil {
-			for _, p := range gardenPrompts {
-				// Skip templates
-				if strings.HasSuffix(p.Name(), "-template") {
-					continue
-				}
-
-				metadata := p.Metadata()
-
-				// Build arguments from prompt variables
-				arguments := []map[string]interface{}{}
-				for _, v := range p.Variables() {
-					arg := map[string]interface{}{
-						"name":        v.Name,
-						"description": v.Description,
-						"required":    v.Required,
-					}
-					arguments = append(arguments, arg)
-				}
-
-				prompt := map[string]interface{}{
-					"name":        p.Name(),
-					"description": metadata.Description,
-					"arguments":   arguments,
-				}
-
-				prompts = append(prompts, prompt)
-			}
+		prompts, next, err := listPrompts(s.garden, r.URL.Query().Get("cursor"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response["prompts"] = prompts
+		if next != "" {
+			response["nextCursor"] = next
 		}
-	}
-
-	response := map[string]interface{}{
-		"prompts": prompts,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,227 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FilePatch is the set of changes a unified diff makes to one file
+type FilePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []PatchHunk
+}
+
+// IsNew reports whether the patch creates a file
+func (p FilePatch) IsNew() bool {
+	return p.OldPath == "/dev/null"
+}
+
+// IsDelete reports whether the patch deletes a file
+func (p FilePatch) IsDelete() bool {
+	return p.NewPath == "/dev/null"
+}
+
+// IsRename reports whether the patch moves a file to a new path
+func (p FilePatch) IsRename() bool {
+	return !p.IsNew() && !p.IsDelete() && p.OldPath != p.NewPath
+}
+
+// Path returns the path of the file the patch applies to
+func (p FilePatch) Path() string {
+	if p.IsDelete() {
+		return p.OldPath
+	}
+	return p.NewPath
+}
+
+// PatchHunk is a single @@ section of a unified diff
+type PatchHunk struct {
+	OldStart int
+	Lines    []string // each line keeps its ' ', '-' or '+' prefix
+}
+
+// ParseUnifiedDiff parses a unified diff that may touch several files
+func ParseUnifiedDiff(diff string) ([]FilePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+
+	var patches []FilePatch
+	var current *FilePatch
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+				return nil, fmt.Errorf("line %d: expected +++ after ---", i+1)
+			}
+			patches = append(patches, FilePatch{
+				OldPath: parseDiffPath(line[4:]),
+				NewPath: parseDiffPath(lines[i+1][4:]),
+			})
+			current = &patches[len(patches)-1]
+			i++
+
+		case strings.HasPrefix(line, "@@"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk without file header", i+1)
+			}
+			oldStart, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			current.Hunks = append(current.Hunks, PatchHunk{OldStart: oldStart})
+
+		case current != nil && len(current.Hunks) > 0 && line != "" &&
+			(line[0] == ' ' || line[0] == '-' || line[0] == '+'):
+			hunk := &current.Hunks[len(current.Hunks)-1]
+			hunk.Lines = append(hunk.Lines, line)
+
+		case current != nil && len(current.Hunks) > 0 && line == "" && i < len(lines)-1:
+			// Some tools strip the trailing space from empty context lines
+			hunk := &current.Hunks[len(current.Hunks)-1]
+			hunk.Lines = append(hunk.Lines, " ")
+		}
+	}
+
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found in diff")
+	}
+
+	return patches, nil
+}
+
+// ApplyPatch applies a file patch to the original content
+func ApplyPatch(original string, patch FilePatch) (string, error) {
+	var lines []string
+	if original != "" {
+		lines = strings.Split(strings.TrimSuffix(original, "\n"), "\n")
+	}
+
+	// Hunks are applied in order; offset tracks how earlier hunks shifted line numbers
+	offset := 0
+	result := lines
+	for h, hunk := range patch.Hunks {
+		var oldLines, newLines []string
+		for _, l := range hunk.Lines {
+			switch l[0] {
+			case ' ':
+				oldLines = append(oldLines, l[1:])
+				newLines = append(newLines, l[1:])
+			case '-':
+				oldLines = append(oldLines, l[1:])
+			case '+':
+				newLines = append(newLines, l[1:])
+			}
+		}
+
+		start := hunk.OldStart - 1 + offset
+		if len(oldLines) == 0 {
+			// Pure insertion: OldStart is the line after which to insert
+			start = hunk.OldStart + offset
+		}
+
+		pos := findHunk(result, oldLines, start)
+		if pos < 0 {
+			return "", fmt.Errorf("hunk %d does not apply to %s", h+1, patch.Path())
+		}
+
+		updated := make([]string, 0, len(result)-len(oldLines)+len(newLines))
+		updated = append(updated, result[:pos]...)
+		updated = append(updated, newLines...)
+		updated = append(updated, result[pos+len(oldLines):]...)
+		result = updated
+
+		offset += len(newLines) - len(oldLines)
+	}
+
+	if len(result) == 0 {
+		return "", nil
+	}
+	return strings.Join(result, "\n") + "\n", nil
+}
+
+// findHunk locates the old lines of a hunk, starting at the expected position
+// and searching outwards to tolerate line drift
+func findHunk(lines, old []string, expected int) int {
+	if expected < 0 {
+		expected = 0
+	}
+	if expected > len(lines) {
+		expected = len(lines)
+	}
+
+	matches := func(pos int) bool {
+		if pos < 0 || pos+len(old) > len(lines) {
+			return false
+		}
+		for i, l := range old {
+			if lines[pos+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+
+	for delta := 0; delta <= len(lines); delta++ {
+		if matches(expected - delta) {
+			return expected - delta
+		}
+		if delta > 0 && matches(expected+delta) {
+			return expected + delta
+		}
+	}
+	return -1
+}
+
+// parseDiffPath strips the a/ or b/ prefix and any timestamp from a diff header path
+func parseDiffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return s
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// parseHunkHeader extracts the old start line from "@@ -l,n +l,n @@"
+func parseHunkHeader(header string) (int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("invalid hunk header: %s", header)
+	}
+
+	oldRange := strings.TrimPrefix(fields[1], "-")
+	if i := strings.IndexByte(oldRange, ','); i >= 0 {
+		oldRange = oldRange[:i]
+	}
+
+	start, err := strconv.Atoi(oldRange)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hunk header: %s", header)
+	}
+	return start, nil
+}
@@ -0,0 +1,54 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+)
+
+// listPrompts returns a page of MCP prompt descriptors read from the garden
+// index, and the cursor of the next page. Templates are left out, they are
+// only meant to be included.
+func listPrompts(garden *promptgarden.Garden, cursor string) ([]map[string]interface{}, string, error) {
+	page, err := garden.ListPage(promptgarden.ListOptions{
+		Cursor: cursor,
+		Filter: func(p promptgarden.Summary) bool { return !strings.HasSuffix(p.Name, "-template") },
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	prompts := make([]map[string]interface{}, 0, len(page.Prompts))
+	for _, p := range page.Prompts {
+		arguments := []map[string]interface{}{}
+		for _, v := range p.Variables {
+			arguments = append(arguments, map[string]interface{}{
+				"name":        v.Name,
+				"description": v.Description,
+				"required":    v.Required,
+			})
+		}
+		prompts = append(prompts, map[string]interface{}{
+			"name":        p.Name,
+			"description": p.Description,
+			"arguments":   arguments,
+		})
+	}
+	return prompts, page.NextCursor, nil
+}
@@ -0,0 +1,85 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptsListCursor(t *testing.T) {
+	garden, err := promptgarden.NewGarden(t.TempDir())
+	require.NoError(t, err)
+	total := promptgarden.DefaultPageSize + 10
+	for i := 0; i < total; i++ {
+		metadata := core.PromptMetadata{Name: fmt.Sprintf("prompt-%03d", i), Description: "Review"}
+		require.NoError(t, garden.Add(promptgarden.NewTemplatePrompt(metadata, "Review {{file}}")))
+	}
+
+	var out bytes.Buffer
+	server := &StdioMCPServer{garden: garden, writer: &out}
+
+	type listResult struct {
+		Result struct {
+			Prompts []struct {
+				Name      string `json:"name"`
+				Arguments []struct {
+					Name string `json:"name"`
+				} `json:"arguments"`
+			} `json:"prompts"`
+			NextCursor string `json:"nextCursor"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	list := func(params map[string]interface{}) listResult {
+		out.Reset()
+		server.handleRequest(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      float64(1),
+			"method":  "prompts/list",
+			"params":  params,
+		})
+		var response listResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &response))
+		return response
+	}
+
+	first := list(nil)
+	require.Len(t, first.Result.Prompts, promptgarden.DefaultPageSize)
+	assert.Equal(t, "prompt-000", first.Result.Prompts[0].Name)
+	assert.Equal(t, "file", first.Result.Prompts[0].Arguments[0].Name)
+	require.NotEmpty(t, first.Result.NextCursor)
+
+	// The built-in templates are left out of the last page
+	second := list(map[string]interface{}{"cursor": first.Result.NextCursor})
+	require.Len(t, second.Result.Prompts, 10)
+	assert.Equal(t, fmt.Sprintf("prompt-%03d", total-1), second.Result.Prompts[9].Name)
+	assert.Empty(t, second.Result.NextCursor)
+
+	bad := list(map[string]interface{}{"cursor": "???"})
+	require.NotNil(t, bad.Error)
+	assert.Contains(t, bad.Error.Message, "invalid prompt cursor")
+}
@@ -0,0 +1,370 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	subagentpkg "github.com/rizome-dev/opun/pkg/subagent"
+)
+
+// samplingTimeout bounds how long the server waits for the client to answer a sampling request
+const samplingTimeout = 2 * time.Minute
+
+// SamplingRequest describes a completion requested from the connected MCP client
+type SamplingRequest struct {
+	SystemPrompt string
+	Prompt       string
+	MaxTokens    int
+}
+
+// Sampler requests LLM completions from the connected MCP client
+type Sampler interface {
+	// SupportsSampling reports whether the client advertised the sampling capability
+	SupportsSampling() bool
+
+	// CreateMessage asks the client for a completion and returns its text
+	CreateMessage(ctx context.Context, req SamplingRequest) (string, error)
+}
+
+// SupportsSampling reports whether the connected client advertised the sampling capability
+func (s *StdioMCPServer) SupportsSampling() bool {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	_, ok := s.clientCapabilities["sampling"]
+	return ok
+}
+
+// CreateMessage sends a sampling/createMessage request to the client and waits for the reply
+func (s *StdioMCPServer) CreateMessage(ctx context.Context, req SamplingRequest) (string, error) {
+	if !s.SupportsSampling() {
+		return "", fmt.Errorf("client does not support sampling")
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+
+	params := map[string]interface{}{
+		"messages": []map[string]interface{}{
+			{
+				"role": "user",
+				"content": map[string]interface{}{
+					"type": "text",
+					"text": req.Prompt,
+				},
+			},
+		},
+		"maxTokens": maxTokens,
+	}
+	if req.SystemPrompt != "" {
+		params["systemPrompt"] = req.SystemPrompt
+	}
+
+	s.pendingMu.Lock()
+	s.requestSeq++
+	requestID := fmt.Sprintf("opun-sampling-%d", s.requestSeq)
+	replyCh := make(chan map[string]interface{}, 1)
+	s.pending[requestID] = replyCh
+	s.pendingMu.Unlock()
+
+	defer func() {
+		s.pendingMu.Lock()
+		delete(s.pending, requestID)
+		s.pendingMu.Unlock()
+	}()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      requestID,
+		"method":  "sampling/createMessage",
+		"params":  params,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.writeMu.Lock()
+	fmt.Fprintf(s.writer, "%s\n", data)
+	s.writeMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, samplingTimeout)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("sampling request timed out: %w", ctx.Err())
+	case reply := <-replyCh:
+		if errObj, ok := reply["error"].(map[string]interface{}); ok {
+			return "", fmt.Errorf("client rejected sampling request: %v", errObj["message"])
+		}
+
+		result, _ := reply["result"].(map[string]interface{})
+		content, _ := result["content"].(map[string]interface{})
+		text, _ := content["text"].(string)
+		if text == "" {
+			return "", fmt.Errorf("client returned an empty sampling result")
+		}
+		return text, nil
+	}
+}
+
+// handleClientResponse routes a response from the client to the request waiting on it
+func (s *StdioMCPServer) handleClientResponse(message map[string]interface{}) {
+	requestID := fmt.Sprintf("%v", message["id"])
+
+	s.pendingMu.Lock()
+	replyCh, exists := s.pending[requestID]
+	s.pendingMu.Unlock()
+
+	if exists {
+		replyCh <- message
+	}
+}
+
+// SetSubAgentManager exposes subagent delegation over MCP. Routing decisions are
+// made by the connected client through sampling when it supports it.
+func (s *StdioMCPServer) SetSubAgentManager(manager *subagentpkg.Manager) {
+	s.subagentMgr = manager
+	if manager != nil {
+		manager.SetRouter(NewSamplingRouter(s))
+	}
+}
+
+// subagentToolDescriptors returns the subagent delegation tools
+func (s *StdioMCPServer) subagentToolDescriptors() []map[string]interface{} {
+	if s.subagentMgr == nil || len(s.subagentMgr.List()) == 0 {
+		return nil
+	}
+
+	return []map[string]interface{}{
+		s.createToolDescriptor(
+			"subagent_delegate",
+			"[Subagent] Delegate a task to the best suited subagent",
+			"subagent",
+			"1.0.0",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "Description of the task to delegate",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only report which subagent would be selected",
+					},
+				},
+				"required": []string{"task"},
+			},
+		),
+	}
+}
+
+// executeSubAgentTool handles the subagent_ tools
+func (s *StdioMCPServer) executeSubAgentTool(tool string, args map[string]interface{}) (string, error) {
+	if s.subagentMgr == nil {
+		return "", fmt.Errorf("subagent manager not available")
+	}
+	if tool != "subagent_delegate" {
+		return "", fmt.Errorf("unknown subagent tool: %s", tool)
+	}
+
+	taskText, _ := args["task"].(string)
+	if taskText == "" {
+		return "", fmt.Errorf("task is required")
+	}
+
+	task := core.SubAgentTask{
+		ID:          fmt.Sprintf("mcp-%d", time.Now().UnixNano()),
+		Name:        "mcp-delegation",
+		Description: taskText,
+		Input:       taskText,
+	}
+
+	if dryRun, _ := args["dry_run"].(bool); dryRun {
+		agent, err := NewSamplingRouter(s).Route(task, s.subagentMgr.List())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Task would be delegated to subagent '%s'", agent.Name()), nil
+	}
+
+	result, err := s.subagentMgr.Delegate(context.Background(), task)
+	if err != nil {
+		return "", err
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("subagent %s failed: %w", result.AgentName, result.Error)
+	}
+
+	return fmt.Sprintf("Subagent '%s' completed in %s:\n%s", result.AgentName, result.Duration.Round(time.Second), result.Output), nil
+}
+
+// SamplingRouter routes subagent tasks by asking the MCP client's model to pick an agent.
+// It falls back to capability matching when sampling is unavailable or inconclusive.
+type SamplingRouter struct {
+	sampler Sampler
+
+	mu    sync.Mutex
+	stats map[string]int
+}
+
+// NewSamplingRouter creates a router backed by the given sampler
+func NewSamplingRouter(sampler Sampler) *SamplingRouter {
+	return &SamplingRouter{
+		sampler: sampler,
+		stats:   make(map[string]int),
+	}
+}
+
+// Route selects the best subagent for a task
+func (r *SamplingRouter) Route(task core.SubAgentTask, agents []core.SubAgent) (core.SubAgent, error) {
+	candidates := make([]core.SubAgent, 0, len(agents))
+	for _, agent := range agents {
+		if agent.CanHandle(task) {
+			candidates = append(candidates, agent)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no subagent can handle task %s", task.Name)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	if r.sampler != nil && r.sampler.SupportsSampling() {
+		ctx, cancel := context.WithTimeout(context.Background(), samplingTimeout)
+		defer cancel()
+
+		reply, err := r.sampler.CreateMessage(ctx, SamplingRequest{
+			SystemPrompt: "You route tasks to specialized agents. Reply with the name of the single best agent and nothing else.",
+			Prompt:       buildRoutingPrompt(task, candidates),
+			MaxTokens:    50,
+		})
+		if err == nil {
+			if agent := matchAgentName(reply, candidates); agent != nil {
+				r.record("sampled")
+				return agent, nil
+			}
+		}
+	}
+
+	r.record("fallback")
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return r.Score(task, candidates[i]) > r.Score(task, candidates[j])
+	})
+	return candidates[0], nil
+}
+
+// Score rates how well an agent matches a task by capability keywords and priority
+func (r *SamplingRouter) Score(task core.SubAgentTask, agent core.SubAgent) float64 {
+	if !agent.CanHandle(task) {
+		return 0
+	}
+
+	text := strings.ToLower(task.Description + " " + task.Input)
+	score := 1.0
+	for _, capability := range agent.GetCapabilities() {
+		if strings.Contains(text, strings.ToLower(capability)) {
+			score++
+		}
+	}
+
+	return score + float64(agent.Config().Priority)/100
+}
+
+// Learn records execution outcomes for routing statistics
+func (r *SamplingRouter) Learn(task core.SubAgentTask, agent core.SubAgent, result *core.SubAgentResult) {
+	if result != nil && result.Status == core.StatusCompleted {
+		r.record("success")
+	} else {
+		r.record("failure")
+	}
+}
+
+// GetStats returns routing statistics
+func (r *SamplingRouter) GetStats() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]interface{}, len(r.stats))
+	for k, v := range r.stats {
+		stats[k] = v
+	}
+	return stats
+}
+
+func (r *SamplingRouter) record(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[key]++
+}
+
+// buildRoutingPrompt describes the task and candidate agents for the client's model
+func buildRoutingPrompt(task core.SubAgentTask, agents []core.SubAgent) string {
+	var sb strings.Builder
+	sb.WriteString("Choose the agent best suited for this task.\n\nTask:\n")
+	sb.WriteString(task.Description)
+	if task.Input != "" && task.Input != task.Description {
+		sb.WriteString("\n")
+		sb.WriteString(task.Input)
+	}
+
+	sb.WriteString("\n\nAgents:\n")
+	for _, agent := range agents {
+		config := agent.Config()
+		fmt.Fprintf(&sb, "- %s (%s): %s", agent.Name(), agent.Provider(), config.Description)
+		if caps := agent.GetCapabilities(); len(caps) > 0 {
+			fmt.Fprintf(&sb, " [capabilities: %s]", strings.Join(caps, ", "))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\nReply with the agent name only.")
+	return sb.String()
+}
+
+// matchAgentName finds the agent named in a model reply
+func matchAgentName(reply string, agents []core.SubAgent) core.SubAgent {
+	reply = strings.ToLower(strings.Trim(strings.TrimSpace(reply), "`\"'."))
+
+	for _, agent := range agents {
+		if strings.ToLower(agent.Name()) == reply {
+			return agent
+		}
+	}
+
+	// Tolerate replies that wrap the name in a sentence, preferring the longest match
+	var best core.SubAgent
+	for _, agent := range agents {
+		name := strings.ToLower(agent.Name())
+		if strings.Contains(reply, name) && (best == nil || len(name) > len(best.Name())) {
+			best = agent
+		}
+	}
+	return best
+}
@@ -0,0 +1,132 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSubAgent implements the parts of core.SubAgent used by routing
+type stubSubAgent struct {
+	core.SubAgent
+	config core.SubAgentConfig
+}
+
+func (a *stubSubAgent) Name() string                          { return a.config.Name }
+func (a *stubSubAgent) Config() core.SubAgentConfig           { return a.config }
+func (a *stubSubAgent) Provider() core.ProviderType           { return a.config.Provider }
+func (a *stubSubAgent) GetCapabilities() []string             { return a.config.Capabilities }
+func (a *stubSubAgent) CanHandle(task core.SubAgentTask) bool { return true }
+
+type stubSampler struct {
+	supported bool
+	reply     string
+	err       error
+	requests  []SamplingRequest
+}
+
+func (s *stubSampler) SupportsSampling() bool { return s.supported }
+
+func (s *stubSampler) CreateMessage(ctx context.Context, req SamplingRequest) (string, error) {
+	s.requests = append(s.requests, req)
+	return s.reply, s.err
+}
+
+func routingAgents() []core.SubAgent {
+	return []core.SubAgent{
+		&stubSubAgent{config: core.SubAgentConfig{Name: "reviewer", Provider: core.ProviderTypeClaude, Capabilities: []string{"review"}}},
+		&stubSubAgent{config: core.SubAgentConfig{Name: "researcher", Provider: core.ProviderTypeGemini, Capabilities: []string{"research"}}},
+	}
+}
+
+func TestSamplingRouter(t *testing.T) {
+	task := core.SubAgentTask{Name: "task", Description: "Do some research on MCP sampling"}
+
+	t.Run("Uses Client Choice", func(t *testing.T) {
+		sampler := &stubSampler{supported: true, reply: "I would pick `reviewer`."}
+		router := NewSamplingRouter(sampler)
+
+		agent, err := router.Route(task, routingAgents())
+		require.NoError(t, err)
+		assert.Equal(t, "reviewer", agent.Name())
+		require.Len(t, sampler.requests, 1)
+		assert.Contains(t, sampler.requests[0].Prompt, "researcher")
+		assert.Equal(t, 1, router.GetStats()["sampled"])
+	})
+
+	t.Run("Falls Back Without Sampling", func(t *testing.T) {
+		sampler := &stubSampler{supported: false}
+		router := NewSamplingRouter(sampler)
+
+		agent, err := router.Route(task, routingAgents())
+		require.NoError(t, err)
+		assert.Equal(t, "researcher", agent.Name())
+		assert.Empty(t, sampler.requests)
+		assert.Equal(t, 1, router.GetStats()["fallback"])
+	})
+
+	t.Run("Falls Back On Sampling Error", func(t *testing.T) {
+		router := NewSamplingRouter(&stubSampler{supported: true, err: errors.New("declined")})
+
+		agent, err := router.Route(task, routingAgents())
+		require.NoError(t, err)
+		assert.Equal(t, "researcher", agent.Name())
+	})
+}
+
+func TestCreateMessage(t *testing.T) {
+	reader, writer := io.Pipe()
+	s := NewStdioMCPServer(nil, nil, nil, nil, nil)
+	s.writer = writer
+	s.clientCapabilities = map[string]interface{}{"sampling": map[string]interface{}{}}
+
+	// Act as the client: answer the sampling request
+	go func() {
+		line, err := bufio.NewReader(reader).ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		var request map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &request); err != nil {
+			return
+		}
+
+		s.handleRequest(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result": map[string]interface{}{
+				"role":    "assistant",
+				"content": map[string]interface{}{"type": "text", "text": "reviewer"},
+			},
+		})
+	}()
+
+	text, err := s.CreateMessage(context.Background(), SamplingRequest{Prompt: "Pick an agent"})
+	require.NoError(t, err)
+	assert.Equal(t, "reviewer", text)
+	assert.Empty(t, s.pending)
+}
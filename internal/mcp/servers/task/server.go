@@ -246,13 +246,32 @@ func (s *TaskServer) executeTask(ctx context.Context, params map[string]interfac
 		}, nil
 	}
 	
-	return map[string]interface{}{
+	response := map[string]interface{}{
 		"task_id": task.ID,
 		"status":  string(result.Status),
 		"output":  result.Output,
 		"agent":   result.AgentName,
 		"duration": result.Duration.Seconds(),
-	}, nil
+	}
+	if len(result.Artifacts) > 0 {
+		response["artifacts"] = artifactList(result.Artifacts)
+	}
+	return response, nil
+}
+
+// artifactList describes the files a task produced for the client
+func artifactList(artifacts []core.SubAgentArtifact) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(artifacts))
+	for _, a := range artifacts {
+		list = append(list, map[string]interface{}{
+			"name":         a.Name,
+			"path":         a.Path,
+			"change":       a.Change,
+			"content_type": a.ContentType,
+			"size":         a.Size,
+		})
+	}
+	return list
 }
 
 // executeBatch executes multiple tasks in parallel
@@ -307,13 +326,17 @@ func (s *TaskServer) executeBatch(ctx context.Context, params map[string]interfa
 			continue
 		}
 		
-		output = append(output, map[string]interface{}{
+		entry := map[string]interface{}{
 			"task_id":  tasks[i].ID,
 			"status":   string(result.Status),
 			"output":   result.Output,
 			"agent":    result.AgentName,
 			"duration": result.Duration.Seconds(),
-		})
+		}
+		if len(result.Artifacts) > 0 {
+			entry["artifacts"] = artifactList(result.Artifacts)
+		}
+		output = append(output, entry)
 	}
 	
 	return map[string]interface{}{
@@ -0,0 +1,248 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionIdleTimeout is how long an unused session is kept before it is discarded
+	sessionIdleTimeout = time.Hour
+
+	// maxSessionResults is the number of recent tool results kept per session
+	maxSessionResults = 20
+
+	// SessionHeader carries the session ID for HTTP clients
+	SessionHeader = "Mcp-Session-Id"
+)
+
+// SessionResult records a tool call made within a session
+type SessionResult struct {
+	Tool   string    `json:"tool"`
+	Result string    `json:"result,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Session holds the state of a single connected client so that tool executions
+// for different providers don't interfere with each other
+type Session struct {
+	ID            string    `json:"id"`
+	ClientName    string    `json:"client_name"`
+	ClientVersion string    `json:"client_version,omitempty"`
+	WorkingDir    string    `json:"working_dir"`
+	AllowedTools  []string  `json:"allowed_tools,omitempty"` // glob patterns, empty allows everything
+	CreatedAt     time.Time `json:"created_at"`
+
+	Executor *ToolExecutor `json:"-"`
+
+	mu       sync.Mutex
+	lastSeen time.Time
+	results  []SessionResult
+}
+
+// Allowed reports whether the session's ACL permits calling a tool
+func (s *Session) Allowed(tool string) bool {
+	if len(s.AllowedTools) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.AllowedTools {
+		if matched, _ := filepath.Match(pattern, tool); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordResult stores a tool result in the session's recent results
+func (s *Session) RecordResult(tool, result string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := SessionResult{
+		Tool:   tool,
+		Result: result,
+		Time:   time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	s.results = append(s.results, entry)
+	if len(s.results) > maxSessionResults {
+		s.results = s.results[len(s.results)-maxSessionResults:]
+	}
+	s.lastSeen = entry.Time
+}
+
+// RecentResults returns the session's recent tool results, oldest first
+func (s *Session) RecentResults() []SessionResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]SessionResult, len(s.results))
+	copy(results, s.results)
+	return results
+}
+
+// touch marks the session as active
+func (s *Session) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSeen = time.Now()
+}
+
+// idleSince returns when the session was last used
+func (s *Session) idleSince() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSeen
+}
+
+// SessionManager tracks the sessions of connected clients
+type SessionManager struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	acls     map[string][]string // client name -> allowed tool patterns
+}
+
+// NewSessionManager creates a session manager. acls maps client names
+// (from initialize clientInfo) to the tool patterns they may call.
+func NewSessionManager(acls map[string][]string) *SessionManager {
+	if acls == nil {
+		acls = make(map[string][]string)
+	}
+
+	return &SessionManager{
+		sessions: make(map[string]*Session),
+		acls:     acls,
+	}
+}
+
+// Create starts a session from MCP initialize params
+func (m *SessionManager) Create(params map[string]interface{}) *Session {
+	clientInfo, _ := params["clientInfo"].(map[string]interface{})
+	clientName, _ := clientInfo["name"].(string)
+	clientVersion, _ := clientInfo["version"].(string)
+	if clientName == "" {
+		clientName = "unknown"
+	}
+
+	workDir, _ := params["workingDirectory"].(string)
+	if info, err := os.Stat(workDir); workDir == "" || err != nil || !info.IsDir() {
+		workDir, _ = os.Getwd()
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:            newSessionID(),
+		ClientName:    clientName,
+		ClientVersion: clientVersion,
+		WorkingDir:    workDir,
+		CreatedAt:     now,
+		Executor:      NewToolExecutor(workDir),
+		lastSeen:      now,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+
+	if allowed, ok := m.acls[clientName]; ok {
+		session.AllowedTools = allowed
+	} else if allowed, ok := m.acls["*"]; ok {
+		session.AllowedTools = allowed
+	}
+
+	m.sessions[session.ID] = session
+	return session
+}
+
+// Get returns an active session and marks it as used
+func (m *SessionManager) Get(id string) (*Session, bool) {
+	m.mu.RLock()
+	session, exists := m.sessions[id]
+	m.mu.RUnlock()
+
+	if exists {
+		session.touch()
+	}
+	return session, exists
+}
+
+// Remove ends a session
+func (m *SessionManager) Remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+// List returns all active sessions, oldest first
+func (m *SessionManager) List() []*Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions
+}
+
+// prune removes idle sessions. Callers must hold the lock.
+func (m *SessionManager) prune() {
+	cutoff := time.Now().Add(-sessionIdleTimeout)
+	for id, session := range m.sessions {
+		if session.idleSince().Before(cutoff) {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+// newSessionID generates a random, unguessable session ID
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// SetClientACLs restricts which tools each client may call. Keys are client
+// names from initialize clientInfo ("*" applies to any other client), values
+// are tool name glob patterns.
+func (s *StdioMCPServer) SetClientACLs(acls map[string][]string) {
+	s.sessions = NewSessionManager(acls)
+}
+
+// SetClientACLs restricts which tools each client may call, as for the stdio server
+func (s *OpunMCPServer) SetClientACLs(acls map[string][]string) {
+	s.sessions = NewSessionManager(acls)
+}
@@ -0,0 +1,154 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	toolslib "github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initializeParams(client, workDir string) map[string]interface{} {
+	return map[string]interface{}{
+		"protocolVersion":  "2024-11-05",
+		"clientInfo":       map[string]interface{}{"name": client, "version": "1.0"},
+		"workingDirectory": workDir,
+	}
+}
+
+func TestSessionManager(t *testing.T) {
+	mgr := NewSessionManager(map[string][]string{
+		"gemini": {"prompt_*"},
+	})
+
+	claudeDir := t.TempDir()
+	geminiDir := t.TempDir()
+
+	claude := mgr.Create(initializeParams("claude", claudeDir))
+	gemini := mgr.Create(initializeParams("gemini", geminiDir))
+
+	t.Run("Isolated Working Directories", func(t *testing.T) {
+		assert.NotEqual(t, claude.ID, gemini.ID)
+		assert.Equal(t, claudeDir, claude.Executor.workingDir)
+		assert.Equal(t, geminiDir, gemini.Executor.workingDir)
+	})
+
+	t.Run("ACLs", func(t *testing.T) {
+		assert.True(t, claude.Allowed("action_deploy"))
+		assert.True(t, gemini.Allowed("prompt_review"))
+		assert.False(t, gemini.Allowed("action_deploy"))
+	})
+
+	t.Run("Isolated Results", func(t *testing.T) {
+		claude.RecordResult("prompt_review", "looks good", nil)
+		gemini.RecordResult("prompt_review", "", errors.New("failed"))
+
+		require.Len(t, claude.RecentResults(), 1)
+		assert.Equal(t, "looks good", claude.RecentResults()[0].Result)
+		require.Len(t, gemini.RecentResults(), 1)
+		assert.Equal(t, "failed", gemini.RecentResults()[0].Error)
+
+		for i := 0; i < maxSessionResults+5; i++ {
+			claude.RecordResult(fmt.Sprintf("tool_%d", i), "", nil)
+		}
+		results := claude.RecentResults()
+		assert.Len(t, results, maxSessionResults)
+		assert.Equal(t, fmt.Sprintf("tool_%d", maxSessionResults+4), results[len(results)-1].Tool)
+	})
+
+	t.Run("Invalid Working Directory Falls Back", func(t *testing.T) {
+		session := mgr.Create(initializeParams("qwen", "/does/not/exist"))
+		assert.NotEqual(t, "/does/not/exist", session.WorkingDir)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		mgr.Remove(claude.ID)
+		_, exists := mgr.Get(claude.ID)
+		assert.False(t, exists)
+	})
+}
+
+func TestOpunMCPServerSessions(t *testing.T) {
+	server := NewOpunMCPServer(nil, nil, nil, 0)
+	server.SetClientACLs(map[string][]string{"gemini": {"prompt_*"}})
+
+	body, _ := json.Marshal(initializeParams("gemini", t.TempDir()))
+	rec := httptest.NewRecorder()
+	server.handleInitialize(rec, httptest.NewRequest(http.MethodPost, "/initialize", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	sessionID := rec.Header().Get(SessionHeader)
+	require.NotEmpty(t, sessionID)
+
+	callTool := func(sessionID, tool string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"tool": tool, "arguments": map[string]interface{}{}})
+		req := httptest.NewRequest(http.MethodPost, "/tool/call", bytes.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(SessionHeader, sessionID)
+		}
+		rec := httptest.NewRecorder()
+		server.handleToolCall(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusForbidden, callTool(sessionID, "command_help").Code)
+	assert.Equal(t, http.StatusNotFound, callTool("missing", "prompt_review").Code)
+
+	// Clients must initialize a session before calling tools
+	assert.Equal(t, http.StatusBadRequest, callTool("", "command_help").Code)
+
+	req := httptest.NewRequest(http.MethodDelete, "/session", nil)
+	req.Header.Set(SessionHeader, sessionID)
+	rec = httptest.NewRecorder()
+	server.handleSession(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, http.StatusNotFound, callTool(sessionID, "prompt_review").Code)
+}
+
+func TestOpunMCPServerActionsUseSessionWorkingDir(t *testing.T) {
+	actions := toolslib.NewRegistry()
+	require.NoError(t, actions.Register(core.StandardAction{ID: "where", Name: "where", Command: "pwd"}))
+
+	server := NewOpunMCPServer(nil, nil, nil, 0)
+	server.SetActionRegistry(actions)
+
+	workDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	body, _ := json.Marshal(initializeParams("claude", workDir))
+	rec := httptest.NewRecorder()
+	server.handleInitialize(rec, httptest.NewRequest(http.MethodPost, "/initialize", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, _ = json.Marshal(map[string]interface{}{"tool": "action_where", "arguments": map[string]interface{}{}})
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", bytes.NewReader(body))
+	req.Header.Set(SessionHeader, rec.Header().Get(SessionHeader))
+	rec = httptest.NewRecorder()
+	server.handleToolCall(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), workDir)
+}
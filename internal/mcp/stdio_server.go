@@ -25,6 +25,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/rizome-dev/opun/internal/command"
 	"github.com/rizome-dev/opun/internal/plugin"
@@ -32,6 +34,7 @@ import (
 	toolslib "github.com/rizome-dev/opun/internal/tools"
 	"github.com/rizome-dev/opun/internal/workflow"
 	"github.com/rizome-dev/opun/pkg/core"
+	subagentpkg "github.com/rizome-dev/opun/pkg/subagent"
 	"gopkg.in/yaml.v3"
 )
 
@@ -43,8 +46,23 @@ type StdioMCPServer struct {
 	workflowMgr  *workflow.Manager
 	toolRegistry *toolslib.Registry
 	toolExecutor *ToolExecutor
+	subagentMgr  *subagentpkg.Manager
+	operations   *OperationManager
+	meter        *ToolMeter
+	sessions     *SessionManager
+	fsPolicy     FSPolicy
+	webPolicy    WebPolicy
+	cache        *ResultCache
 	reader       *bufio.Reader
 	writer       io.Writer
+	writeMu      sync.Mutex // serializes responses and background notifications
+
+	// Client state: session, capabilities and server-initiated requests (sampling)
+	pendingMu          sync.Mutex
+	session            *Session
+	pending            map[string]chan map[string]interface{}
+	requestSeq         int
+	clientCapabilities map[string]interface{}
 }
 
 // NewStdioMCPServer creates a new stdio-based MCP server
@@ -59,6 +77,12 @@ func NewStdioMCPServer(garden *promptgarden.Garden, registry *command.Registry,
 		workflowMgr:  workflowMgr,
 		toolRegistry: toolRegistry,
 		toolExecutor: NewToolExecutor(workDir),
+		operations:   NewOperationManager(),
+		meter:        NewToolMeter(nil),
+		sessions:     NewSessionManager(nil),
+		fsPolicy:     DefaultFSPolicy(),
+		webPolicy:    DefaultWebPolicy(),
+		pending:      make(map[string]chan map[string]interface{}),
 		reader:       bufio.NewReader(os.Stdin),
 		writer:       os.Stdout,
 	}
@@ -141,7 +165,8 @@ func (s *StdioMCPServer) sendResponse(id interface{}, result interface{}) {
 
 	data, _ := json.Marshal(response)
 	
-	
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	fmt.Fprintf(s.writer, "%s\n", data)
 	// Ensure output is flushed immediately
 	if f, ok := s.writer.(*os.File); ok {
@@ -167,7 +192,8 @@ func (s *StdioMCPServer) sendError(id interface{}, err error) {
 
 	data, _ := json.Marshal(response)
 	
-	
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	fmt.Fprintf(s.writer, "%s\n", data)
 	// Ensure output is flushed immediately
 	if f, ok := s.writer.(*os.File); ok {
@@ -188,6 +214,8 @@ func (s *StdioMCPServer) sendParseError() {
 	}
 
 	data, _ := json.Marshal(response)
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	fmt.Fprintf(s.writer, "%s\n", data)
 	// Ensure output is flushed immediately
 	if f, ok := s.writer.(*os.File); ok {
@@ -195,6 +223,34 @@ func (s *StdioMCPServer) sendParseError() {
 	}
 }
 
+// sendProgress sends a notifications/progress message for a client-supplied progress token
+func (s *StdioMCPServer) sendProgress(token interface{}, progress, total int, message string) {
+	params := map[string]interface{}{
+		"progressToken": token,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/progress",
+		"params":  params,
+	}
+
+	data, _ := json.Marshal(notification)
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	fmt.Fprintf(s.writer, "%s\n", data)
+	if f, ok := s.writer.(*os.File); ok {
+		f.Sync()
+	}
+}
+
 // handleRequest handles a JSON-RPC request
 func (s *StdioMCPServer) handleRequest(request map[string]interface{}) {
 	method, _ := request["method"].(string)
@@ -205,6 +261,12 @@ func (s *StdioMCPServer) handleRequest(request map[string]interface{}) {
 	// If there's no id, this is a notification and we shouldn't respond
 	isNotification := id == nil
 
+	// Responses to our own requests (e.g. sampling) have an id but no method
+	if method == "" && !isNotification {
+		s.handleClientResponse(request)
+		return
+	}
+
 	// Only log errors and important events to stderr
 
 	switch method {
@@ -218,16 +280,21 @@ func (s *StdioMCPServer) handleRequest(request map[string]interface{}) {
 		}
 	case "tools/call":
 		if !isNotification {
-			s.handleToolCall(id, params)
+			// Run tools off the read loop so they can wait on client replies (sampling)
+			go s.handleToolCall(id, params)
 		}
 	case "prompts/list":
 		if !isNotification {
-			s.handlePromptsList(id)
+			s.handlePromptsList(id, params)
 		}
 	case "prompts/get":
 		if !isNotification {
 			s.handlePromptsGet(id, params)
 		}
+	case "completion/complete":
+		if !isNotification {
+			s.handleCompletion(id, params)
+		}
 	case "ping":
 		// Handle ping to keep connection alive
 		if !isNotification {
@@ -244,11 +311,21 @@ func (s *StdioMCPServer) handleRequest(request map[string]interface{}) {
 
 // handleInitialize handles the initialize request
 func (s *StdioMCPServer) handleInitialize(id interface{}, params map[string]interface{}) {
+	capabilities, _ := params["capabilities"].(map[string]interface{})
+	session := s.sessions.Create(params)
+
+	s.pendingMu.Lock()
+	s.clientCapabilities = capabilities
+	s.session = session
+	s.toolExecutor = session.Executor
+	s.pendingMu.Unlock()
+
 	s.sendResponse(id, map[string]interface{}{
 		"protocolVersion": "2024-11-05",
 		"capabilities": map[string]interface{}{
-			"tools":   map[string]interface{}{},
-			"prompts": map[string]interface{}{},
+			"tools":       map[string]interface{}{},
+			"prompts":     map[string]interface{}{},
+			"completions": map[string]interface{}{},
 		},
 		"serverInfo": map[string]interface{}{
 			"name":    "opun",
@@ -273,6 +350,13 @@ func (s *StdioMCPServer) createToolDescriptor(name, description, source, version
 
 // handleToolsList returns all available tools
 func (s *StdioMCPServer) handleToolsList(id interface{}) {
+	s.sendResponse(id, map[string]interface{}{
+		"tools": s.listTools(),
+	})
+}
+
+// listTools builds the descriptors for all available tools
+func (s *StdioMCPServer) listTools() []map[string]interface{} {
 	tools := []map[string]interface{}{}
 
 	// Add workflow tools
@@ -302,24 +386,20 @@ func (s *StdioMCPServer) handleToolsList(id interface{}) {
 
 	// Add prompt tools
 	if s.garden != nil {
-		prompts, err := s.garden.List()
-		if err == nil {
-			for _, p := range prompts {
-				// Skip templates
-				if strings.HasSuffix(p.Name(), "-template") {
-					continue
-				}
-
-				metadata := p.Metadata()
-				tool := s.createToolDescriptor(
-					fmt.Sprintf("prompt_%s", p.Name()),
-					fmt.Sprintf("[Prompt] %s: %s", p.Name(), metadata.Description),
-					"prompt",
-					metadata.Version,
-					s.buildPromptParameters(p),
-				)
-				tools = append(tools, tool)
+		for _, p := range s.garden.Summaries() {
+			// Skip templates
+			if strings.HasSuffix(p.Name, "-template") {
+				continue
 			}
+
+			tool := s.createToolDescriptor(
+				fmt.Sprintf("prompt_%s", p.Name),
+				fmt.Sprintf("[Prompt] %s: %s", p.Name, p.Description),
+				"prompt",
+				p.Version,
+				s.buildPromptParameters(p.Variables),
+			)
+			tools = append(tools, tool)
 		}
 	}
 
@@ -412,9 +492,17 @@ func (s *StdioMCPServer) handleToolsList(id interface{}) {
 		}
 	}
 
-	s.sendResponse(id, map[string]interface{}{
-		"tools": tools,
-	})
+	// Add built-in filesystem tools
+	tools = append(tools, s.fsToolDescriptors()...)
+	tools = append(tools, s.webToolDescriptors()...)
+
+	// Add subagent delegation tools
+	tools = append(tools, s.subagentToolDescriptors()...)
+
+	// Add tools for polling background operations such as workflow runs
+	tools = append(tools, s.operationToolDescriptors()...)
+
+	return tools
 }
 
 // handleToolCall executes a tool
@@ -422,25 +510,31 @@ func (s *StdioMCPServer) handleToolCall(id interface{}, params map[string]interf
 	toolName, _ := params["name"].(string)
 	arguments, _ := params["arguments"].(map[string]interface{})
 
-	var result string
-	var err error
+	s.pendingMu.Lock()
+	session := s.session
+	s.pendingMu.Unlock()
 
-	// Determine tool type and execute
-	switch {
-	case strings.HasPrefix(toolName, "workflow_"):
-		result, err = s.executeWorkflow(toolName, arguments)
-	case strings.HasPrefix(toolName, "prompt_"):
-		result, err = s.executePrompt(toolName, arguments)
-	case strings.HasPrefix(toolName, "command_"):
-		result, err = s.executeCommand(toolName, arguments)
-	case strings.HasPrefix(toolName, "plugin_"):
-		result, err = s.executePlugin(toolName, arguments)
-	case strings.HasPrefix(toolName, "action_"):
-		result, err = s.executeStandardAction(toolName, arguments)
-	case strings.HasPrefix(toolName, "tool_"):
-		result, err = s.executeMCPTool(toolName, arguments)
-	default:
-		err = fmt.Errorf("unknown tool: %s", toolName)
+	if session != nil && !session.Allowed(toolName) {
+		s.sendError(id, fmt.Errorf("tool %s is not allowed for client %s", toolName, session.ClientName))
+		return
+	}
+
+	if err := s.meter.Allow(toolName); err != nil {
+		s.sendError(id, err)
+		return
+	}
+
+	scope := ""
+	if session != nil {
+		scope = session.WorkingDir
+	}
+
+	start := time.Now()
+	result, err := s.callTool(toolName, arguments, params, scope)
+
+	s.meter.Record(toolName, time.Since(start), err)
+	if session != nil {
+		session.RecordResult(toolName, result, err)
 	}
 
 	if err != nil {
@@ -458,29 +552,34 @@ func (s *StdioMCPServer) handleToolCall(id interface{}, params map[string]interf
 	})
 }
 
-// executeWorkflow executes a workflow
-func (s *StdioMCPServer) executeWorkflow(tool string, args map[string]interface{}) (string, error) {
-	if s.workflowMgr == nil {
-		return "", fmt.Errorf("workflow manager not available")
-	}
-
-	// Extract workflow name
-	workflowName := strings.TrimPrefix(tool, "workflow_")
-
-	// Get args string
-	argsStr, _ := args["args"].(string)
-
-	// Execute workflow
-	ctx := context.Background()
-	result, err := s.workflowMgr.Execute(ctx, workflowName, map[string]interface{}{
-		"args": argsStr,
-	})
-	if err != nil {
-		return "", err
+// dispatchTool executes a tool by its name prefix
+func (s *StdioMCPServer) dispatchTool(toolName string, arguments map[string]interface{}, params map[string]interface{}) (string, error) {
+	switch {
+	case strings.HasPrefix(toolName, "workflow_"):
+		// Workflows can take minutes, so they run as background operations
+		meta, _ := params["_meta"].(map[string]interface{})
+		return s.startWorkflowOperation(toolName, arguments, meta["progressToken"])
+	case strings.HasPrefix(toolName, "fs_"):
+		return s.executeFSTool(toolName, arguments)
+	case strings.HasPrefix(toolName, "web_"):
+		return s.executeWebTool(toolName, arguments)
+	case strings.HasPrefix(toolName, "subagent_"):
+		return s.executeSubAgentTool(toolName, arguments)
+	case strings.HasPrefix(toolName, "operation_"):
+		return s.executeOperationTool(toolName, arguments)
+	case strings.HasPrefix(toolName, "prompt_"):
+		return s.executePrompt(toolName, arguments)
+	case strings.HasPrefix(toolName, "command_"):
+		return s.executeCommand(toolName, arguments)
+	case strings.HasPrefix(toolName, "plugin_"):
+		return s.executePlugin(toolName, arguments)
+	case strings.HasPrefix(toolName, "action_"):
+		return s.executeStandardAction(toolName, arguments)
+	case strings.HasPrefix(toolName, "tool_"):
+		return s.executeMCPTool(toolName, arguments)
+	default:
+		return "", fmt.Errorf("unknown tool: %s", toolName)
 	}
-
-	// Format result
-	return fmt.Sprintf("Workflow '%s' executed successfully:\n%v", workflowName, result), nil
 }
 
 // executePrompt executes a prompt
@@ -641,18 +740,8 @@ func (s *StdioMCPServer) executeStandardAction(toolName string, args map[string]
 	// Execute based on action type
 	ctx := context.Background()
 
-	if action.Command != "" {
-		// Validate command before execution
-		if err := s.toolExecutor.ValidateCommand(action.Command); err != nil {
-			return "", fmt.Errorf("command validation failed: %w", err)
-		}
-
-		// Execute system command safely
-		result, err := s.toolExecutor.ExecuteCommand(ctx, action.Command, arguments)
-		if err != nil {
-			return fmt.Sprintf("Action '%s' execution failed: %v\nOutput:\n%s", action.Name, err, result), nil
-		}
-		return fmt.Sprintf("Action '%s' executed successfully:\n%s", action.Name, result), nil
+	if result, handled, err := s.toolExecutor.RunAction(ctx, action, arguments); handled {
+		return result, err
 	} else if action.WorkflowRef != "" {
 		// Execute workflow
 		if s.workflowMgr != nil {
@@ -682,46 +771,27 @@ func (s *StdioMCPServer) executeStandardAction(toolName string, args map[string]
 	return "", fmt.Errorf("action '%s' has no execution method defined", action.Name)
 }
 
-// handlePromptsList returns the list of available prompts
-func (s *StdioMCPServer) handlePromptsList(id interface{}) {
-	prompts := []map[string]interface{}{}
+// handlePromptsList returns a page of the available prompts. Clients pass
+// the nextCursor of a page to get the one after it.
+func (s *StdioMCPServer) handlePromptsList(id interface{}, params map[string]interface{}) {
+	result := map[string]interface{}{
+		"prompts": []map[string]interface{}{},
+	}
 
 	if s.garden != nil {
-		gardenPrompts, err := s.garden.List()
-		if err == nil {
-			for _, p := range gardenPrompts {
-				// Skip templates
-				if strings.HasSuffix(p.Name(), "-template") {
-					continue
-				}
-
-				metadata := p.Metadata()
-
-				// Build arguments from prompt variables
-				arguments := []map[string]interface{}{}
-				for _, v := range p.Variables() {
-					arg := map[string]interface{}{
-						"name":        v.Name,
-						"description": v.Description,
-						"required":    v.Required,
-					}
-					arguments = append(arguments, arg)
-				}
-
-				prompt := map[string]interface{}{
-					"name":        p.Name(),
-					"description": metadata.Description,
-					"arguments":   arguments,
-				}
-
-				prompts = append(prompts, prompt)
-			}
+		cursor, _ := params["cursor"].(string)
+		prompts, next, err := listPrompts(s.garden, cursor)
+		if err != nil {
+			s.sendError(id, err)
+			return
+		}
+		result["prompts"] = prompts
+		if next != "" {
+			result["nextCursor"] = next
 		}
 	}
 
-	s.sendResponse(id, map[string]interface{}{
-		"prompts": prompts,
-	})
+	s.sendResponse(id, result)
 }
 
 // handlePromptsGet returns a specific prompt's content
@@ -756,7 +826,7 @@ func (s *StdioMCPServer) handlePromptsGet(id interface{}, params map[string]inte
 }
 
 // buildPromptParameters builds parameter schema for a prompt
-func (s *StdioMCPServer) buildPromptParameters(p core.Prompt) map[string]interface{} {
+func (s *StdioMCPServer) buildPromptParameters(variables []core.PromptVariable) map[string]interface{} {
 	parameters := map[string]interface{}{
 		"type":       "object",
 		"properties": map[string]interface{}{},
@@ -766,11 +836,15 @@ func (s *StdioMCPServer) buildPromptParameters(p core.Prompt) map[string]interfa
 	properties := parameters["properties"].(map[string]interface{})
 	required := []string{}
 
-	for _, v := range p.Variables() {
-		properties[v.Name] = map[string]interface{}{
+	for _, v := range variables {
+		property := map[string]interface{}{
 			"type":        "string", // Default to string for simplicity
 			"description": v.Description,
 		}
+		if len(v.Options) > 0 {
+			property["enum"] = v.Options
+		}
+		properties[v.Name] = property
 
 		if v.Required {
 			required = append(required, v.Name)
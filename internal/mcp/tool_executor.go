@@ -23,6 +23,9 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	toolslib "github.com/rizome-dev/opun/internal/tools"
+	"github.com/rizome-dev/opun/pkg/core"
 )
 
 // ToolExecutor handles safe execution of tool commands
@@ -91,6 +94,41 @@ func (te *ToolExecutor) ExecuteCommand(ctx context.Context, command string, args
 	return result, nil
 }
 
+// RunAction runs an action's command or steps in the executor's working
+// directory. handled is false for actions that run a workflow or prompt.
+func (te *ToolExecutor) RunAction(ctx context.Context, action *core.StandardAction, arguments string) (result string, handled bool, err error) {
+	if action.Command != "" {
+		// Validate command before execution
+		if err := te.ValidateCommand(action.Command); err != nil {
+			return "", true, fmt.Errorf("command validation failed: %w", err)
+		}
+
+		// Execute system command safely
+		result, err := te.ExecuteCommand(ctx, action.Command, arguments)
+		if err != nil {
+			return fmt.Sprintf("Action '%s' execution failed: %v\nOutput:\n%s", action.Name, err, result), true, nil
+		}
+		return fmt.Sprintf("Action '%s' executed successfully:\n%s", action.Name, result), true, nil
+	}
+
+	if len(action.Steps) > 0 {
+		runner := toolslib.NewStepRunner(te.workingDir)
+		runner.Timeout = te.timeout
+		runner.Validate = te.ValidateCommand
+
+		report, err := runner.Run(ctx, *action, arguments)
+		if report == nil {
+			return "", true, err
+		}
+		if err != nil {
+			return fmt.Sprintf("Action '%s' failed: %v\n%s", action.Name, err, toolslib.FormatStepsReport(report)), true, nil
+		}
+		return fmt.Sprintf("Action '%s' executed successfully:\n%s", action.Name, toolslib.FormatStepsReport(report)), true, nil
+	}
+
+	return "", false, nil
+}
+
 // SetTimeout sets the execution timeout
 func (te *ToolExecutor) SetTimeout(timeout time.Duration) {
 	te.timeout = timeout
@@ -117,7 +155,7 @@ func (te *ToolExecutor) ValidateCommand(command string) error {
 	// Check if command starts with allowed prefixes (configurable)
 	allowedPrefixes := []string{
 		"ls", "grep", "find", "cat", "echo", "pwd", "date",
-		"git", "npm", "yarn", "make", "go", "python", "node",
+		"git", "npm", "yarn", "pnpm", "bun", "make", "task", "go", "python", "node",
 		"rg", "ag", "fd", "bat", "jq", "curl", "wget",
 	}
 
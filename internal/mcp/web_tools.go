@@ -0,0 +1,357 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultWebMaxBytes caps how much of a response body is returned
+	defaultWebMaxBytes = 512 * 1024
+
+	// webFetchTimeout bounds a single fetch or search request
+	webFetchTimeout = 30 * time.Second
+)
+
+// WebPolicy controls the built-in web tools
+type WebPolicy struct {
+	Enabled              bool
+	AllowedDomains       []string // "example.com" also allows subdomains, "*" any public host; empty allows none
+	MaxBytes             int64
+	AllowPrivateNetworks bool // allow fetching localhost and private addresses
+
+	// Optional search API. SearchURL contains a {query} placeholder, e.g.
+	// https://api.search.brave.com/res/v1/web/search?q={query}
+	SearchURL       string
+	SearchAPIKeyEnv string // environment variable holding the API key
+	SearchKeyHeader string // header carrying the key (default: Authorization: Bearer <key>)
+}
+
+// DefaultWebPolicy returns the policy used when nothing is configured: the
+// web tools are off until enabled with a domain allowlist
+func DefaultWebPolicy() WebPolicy {
+	return WebPolicy{
+		MaxBytes: defaultWebMaxBytes,
+	}
+}
+
+// AnyHostWebPolicy returns a policy allowing any public host, for fetches
+// of URLs the user typed rather than ones an agent chose
+func AnyHostWebPolicy() WebPolicy {
+	policy := DefaultWebPolicy()
+	policy.Enabled = true
+	policy.AllowedDomains = []string{"*"}
+	return policy
+}
+
+// anyHost reports whether the allowlist lets through any public host
+func (p WebPolicy) anyHost() bool {
+	for _, domain := range p.AllowedDomains {
+		if domain == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebTools implements the built-in web_fetch and web_search tools
+type WebTools struct {
+	policy WebPolicy
+	client *http.Client
+}
+
+// NewWebTools creates web tools enforcing the given policy
+func NewWebTools(policy WebPolicy) *WebTools {
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = defaultWebMaxBytes
+	}
+
+	t := &WebTools{policy: policy}
+
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		// Check the resolved address so DNS tricks and redirects can't reach internal services
+		Control: func(network, address string, c syscall.RawConn) error {
+			if policy.AllowPrivateNetworks {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("access to private address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	t.client = &http.Client{
+		Timeout: webFetchTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("too many redirects")
+			}
+			return t.checkURL(req.URL)
+		},
+	}
+
+	return t
+}
+
+// checkURL validates the scheme and domain of a URL against the policy
+func (t *WebTools) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme: %s", u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("URL has no host")
+	}
+
+	if t.policy.anyHost() {
+		return nil
+	}
+
+	for _, domain := range t.policy.AllowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "*."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("domain %s is not in the allowed domains", host)
+}
+
+// Fetch downloads a URL and returns its content. HTML is converted to text unless raw is set.
+func (t *WebTools) Fetch(ctx context.Context, rawURL string, raw bool) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if err := t.checkURL(u); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "opun-web-fetch/1.0")
+
+	body, contentType, err := t.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !raw && strings.Contains(contentType, "html") {
+		body = htmlToText(body)
+	}
+
+	return body, nil
+}
+
+// Search queries the configured search API and returns its response
+func (t *WebTools) Search(ctx context.Context, query string) (string, error) {
+	if t.policy.SearchURL == "" {
+		return "", fmt.Errorf("no search API configured")
+	}
+	if query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+
+	searchURL := strings.ReplaceAll(t.policy.SearchURL, "{query}", url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if t.policy.SearchAPIKeyEnv != "" {
+		key := os.Getenv(t.policy.SearchAPIKeyEnv)
+		if key == "" {
+			return "", fmt.Errorf("search API key not set: %s", t.policy.SearchAPIKeyEnv)
+		}
+		if t.policy.SearchKeyHeader != "" {
+			req.Header.Set(t.policy.SearchKeyHeader, key)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+
+	// The search API is configured by the user, so it bypasses the domain allowlist
+	body, _, err := t.do(req)
+	return body, err
+}
+
+// do executes a request and reads the body up to the size cap
+func (t *WebTools) do(req *http.Request) (string, string, error) {
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", "", fmt.Errorf("request failed: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, t.policy.MaxBytes+1))
+	if err != nil {
+		return "", "", err
+	}
+
+	body := string(data)
+	if int64(len(data)) > t.policy.MaxBytes {
+		body = string(data[:t.policy.MaxBytes]) + fmt.Sprintf("\n... (truncated at %d bytes)", t.policy.MaxBytes)
+	}
+
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+var (
+	htmlDropRe    = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head)[^>]*>.*?</(script|style|noscript|svg|head)>`)
+	htmlBlockRe   = regexp.MustCompile(`(?i)</?(p|div|br|li|tr|h[1-6]|pre|section|article|header|footer|table|ul|ol)[^>]*>`)
+	htmlTagRe     = regexp.MustCompile(`<[^>]+>`)
+	blankLinesRe  = regexp.MustCompile(`\n\s*\n+`)
+	inlineSpaceRe = regexp.MustCompile(`[ \t]+`)
+)
+
+// htmlToText reduces an HTML page to readable text
+func htmlToText(page string) string {
+	text := htmlDropRe.ReplaceAllString(page, "")
+	text = htmlBlockRe.ReplaceAllString(text, "\n")
+	text = htmlTagRe.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = inlineSpaceRe.ReplaceAllString(text, " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text)
+}
+
+// isPrivateIP reports whether an address is loopback, private or link-local
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// SetWebPolicy configures the built-in web tools
+func (s *StdioMCPServer) SetWebPolicy(policy WebPolicy) {
+	s.webPolicy = policy
+}
+
+// webToolDescriptors returns the descriptors for the enabled web tools.
+// web_fetch needs a domain allowlist, without one it couldn't fetch anything.
+func (s *StdioMCPServer) webToolDescriptors() []map[string]interface{} {
+	if !s.webPolicy.Enabled {
+		return nil
+	}
+
+	var tools []map[string]interface{}
+	if len(s.webPolicy.AllowedDomains) > 0 {
+		description := "[Web] Fetch a web page or document and return its text"
+		if !s.webPolicy.anyHost() {
+			description += fmt.Sprintf(" (allowed domains: %s)", strings.Join(s.webPolicy.AllowedDomains, ", "))
+		}
+		tools = append(tools, s.createToolDescriptor(
+			"web_fetch",
+			description,
+			"web",
+			"1.0.0",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "http(s) URL to fetch",
+					},
+					"raw": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return HTML as-is instead of converting it to text",
+					},
+				},
+				"required": []string{"url"},
+			},
+		))
+	}
+
+	if s.webPolicy.SearchURL != "" {
+		tools = append(tools, s.createToolDescriptor(
+			"web_search",
+			"[Web] Search the web and return the search API results",
+			"web",
+			"1.0.0",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "Search query",
+					},
+				},
+				"required": []string{"query"},
+			},
+		))
+	}
+
+	return tools
+}
+
+// executeWebTool handles the web_ tools
+func (s *StdioMCPServer) executeWebTool(tool string, args map[string]interface{}) (string, error) {
+	if !s.webPolicy.Enabled {
+		return "", fmt.Errorf("web tools are disabled")
+	}
+
+	web := NewWebTools(s.webPolicy)
+	ctx := context.Background()
+
+	switch tool {
+	case "web_fetch":
+		rawURL, _ := args["url"].(string)
+		raw, _ := args["raw"].(bool)
+		return web.Fetch(ctx, rawURL, raw)
+	case "web_search":
+		query, _ := args["query"].(string)
+		return web.Search(ctx, query)
+	}
+
+	return "", fmt.Errorf("unknown web tool: %s", tool)
+}
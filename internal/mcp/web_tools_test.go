@@ -0,0 +1,136 @@
+package mcp
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebToolsFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Docs</title><style>p{}</style></head><body><h1>Install</h1><p>Run &lt;make&gt;</p><script>alert(1)</script></body></html>`)
+		case "/large":
+			fmt.Fprint(w, strings.Repeat("x", 100))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	web := NewWebTools(WebPolicy{Enabled: true, AllowedDomains: []string{"127.0.0.1"}, AllowPrivateNetworks: true})
+
+	t.Run("Converts HTML To Text", func(t *testing.T) {
+		text, err := web.Fetch(context.Background(), server.URL+"/page", false)
+		require.NoError(t, err)
+		assert.Equal(t, "Install\n\nRun <make>", text)
+	})
+
+	t.Run("Caps Response Size", func(t *testing.T) {
+		web := NewWebTools(WebPolicy{Enabled: true, AllowedDomains: []string{"127.0.0.1"}, MaxBytes: 64, AllowPrivateNetworks: true})
+		text, err := web.Fetch(context.Background(), server.URL+"/large", false)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(text, strings.Repeat("x", 64)+"\n"))
+		assert.Contains(t, text, "truncated at 64 bytes")
+	})
+
+	t.Run("Reports HTTP Errors", func(t *testing.T) {
+		_, err := web.Fetch(context.Background(), server.URL+"/missing", false)
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("Blocks Private Networks By Default", func(t *testing.T) {
+		_, err := NewWebTools(AnyHostWebPolicy()).Fetch(context.Background(), server.URL+"/page", false)
+		assert.ErrorContains(t, err, "private address")
+	})
+
+	t.Run("Allows No Domains By Default", func(t *testing.T) {
+		_, err := NewWebTools(DefaultWebPolicy()).Fetch(context.Background(), server.URL+"/page", false)
+		assert.ErrorContains(t, err, "not in the allowed domains")
+	})
+}
+
+func TestWebToolsCheckURL(t *testing.T) {
+	web := NewWebTools(WebPolicy{Enabled: true, AllowedDomains: []string{"go.dev", "*.github.com"}})
+
+	for rawURL, allowed := range map[string]bool{
+		"https://go.dev/doc":                true,
+		"https://pkg.go.dev/net/http":       true,
+		"https://raw.github.com/x/y":        true,
+		"https://github.com.evil.example/x": false,
+		"https://example.com":               false,
+		"file:///etc/passwd":                false,
+		"ftp://go.dev/archive":              false,
+	} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, allowed, web.checkURL(u) == nil, rawURL)
+	}
+
+	u, err := url.Parse("https://example.com")
+	require.NoError(t, err)
+	assert.NoError(t, NewWebTools(AnyHostWebPolicy()).checkURL(u))
+}
+
+func TestWebToolDescriptors(t *testing.T) {
+	server := &StdioMCPServer{webPolicy: DefaultWebPolicy()}
+	assert.Empty(t, server.webToolDescriptors())
+
+	// Enabling the tools needs an allowlist too
+	server.SetWebPolicy(WebPolicy{Enabled: true})
+	assert.Empty(t, server.webToolDescriptors())
+
+	server.SetWebPolicy(WebPolicy{Enabled: true, AllowedDomains: []string{"go.dev"}})
+	tools := server.webToolDescriptors()
+	require.Len(t, tools, 1)
+	assert.Equal(t, "web_fetch", tools[0]["name"])
+}
+
+func TestWebToolsSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"query":%q}`, r.URL.Query().Get("q"))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPUN_TEST_SEARCH_KEY", "secret")
+	web := NewWebTools(WebPolicy{
+		Enabled:              true,
+		AllowPrivateNetworks: true,
+		SearchURL:            server.URL + "/search?q={query}",
+		SearchAPIKeyEnv:      "OPUN_TEST_SEARCH_KEY",
+		SearchKeyHeader:      "X-Api-Key",
+	})
+
+	result, err := web.Search(context.Background(), "mcp sampling")
+	require.NoError(t, err)
+	assert.Equal(t, `{"query":"mcp sampling"}`, result)
+}
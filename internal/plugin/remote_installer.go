@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/rizome-dev/opun/internal/promptgarden"
+	"github.com/rizome-dev/opun/internal/provenance"
 	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/pkg/plugin"
 	"gopkg.in/yaml.v3"
@@ -163,6 +164,9 @@ func (r *RemoteInstaller) InstallManifest(manifest *RemoteManifest) error {
 	if err := r.recordInstallation(manifest, counts); err != nil {
 		return fmt.Errorf("failed to record installation: %w", err)
 	}
+	if err := r.markManaged(manifest); err != nil {
+		return fmt.Errorf("failed to record installation: %w", err)
+	}
 
 	return nil
 }
@@ -289,3 +293,30 @@ func (r *RemoteInstaller) recordInstallation(manifest *RemoteManifest, counts pl
 	path := filepath.Join(installsDir, filename)
 	return utils.WriteFile(path, data)
 }
+
+// markManaged marks the installed items read-only, so local changes are
+// made to personal copies instead
+func (r *RemoteInstaller) markManaged(manifest *RemoteManifest) error {
+	origin := manifest.sourceURL
+	if origin == "" {
+		origin = manifest.Name
+	}
+
+	var items []provenance.Item
+	add := func(kind, name string) {
+		items = append(items, provenance.Item{Kind: kind, Name: name, Source: provenance.SourceManifest, Origin: origin, ReadOnly: true})
+	}
+	for _, prompt := range manifest.Imports.Prompts {
+		add("prompt", prompt.Name)
+	}
+	for _, workflow := range manifest.Imports.Workflows {
+		add("workflow", workflow.Name)
+	}
+	for _, action := range manifest.Imports.Actions {
+		add("action", action.Name)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return provenance.Mark(filepath.Join(r.baseDir, provenance.File), items...)
+}
@@ -0,0 +1,284 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+// DefaultSimilarity is how alike two prompts must be to count as near duplicates
+const DefaultSimilarity = 0.8
+
+// distinctFile records the groups of similar prompts the user chose to keep apart
+const distinctFile = "distinct.json"
+
+// shingleSize is how many words make up a shingle when comparing prompts
+const shingleSize = 3
+
+// DuplicateMatch is a garden prompt similar to a given one
+type DuplicateMatch struct {
+	Name       string  `json:"name"`
+	Similarity float64 `json:"similarity"`
+	Exact      bool    `json:"exact"` // Same content apart from case and spacing
+}
+
+// DuplicateCluster is a group of prompts that are near duplicates of each other
+type DuplicateCluster struct {
+	Prompts []*Prompt `json:"-"`
+	Names   []string  `json:"prompts"`
+	// Similarity is the lowest similarity of two linked prompts in the cluster
+	Similarity float64 `json:"similarity"`
+	Exact      bool    `json:"exact"` // Every prompt has the same content
+}
+
+// promptFingerprint is what prompts are compared by
+type promptFingerprint struct {
+	hash     string
+	shingles map[string]bool
+}
+
+// normalizePrompt lowercases a prompt and collapses its whitespace
+func normalizePrompt(content string) string {
+	return strings.Join(strings.Fields(strings.ToLower(content)), " ")
+}
+
+func fingerprint(content string) promptFingerprint {
+	normalized := normalizePrompt(content)
+	words := strings.Fields(normalized)
+	shingles := make(map[string]bool)
+	if len(words) < shingleSize {
+		if len(words) > 0 {
+			shingles[normalized] = true
+		}
+	} else {
+		for i := 0; i+shingleSize <= len(words); i++ {
+			shingles[strings.Join(words[i:i+shingleSize], " ")] = true
+		}
+	}
+	return promptFingerprint{hash: ContentDigest([]byte(normalized)), shingles: shingles}
+}
+
+// similarity is the Jaccard index of two prompts' word shingles, 1 for the
+// same normalized content
+func (a promptFingerprint) similarity(b promptFingerprint) float64 {
+	if a.hash == b.hash {
+		return 1
+	}
+	small, large := a.shingles, b.shingles
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	if len(large) == 0 {
+		return 0
+	}
+	shared := 0
+	for shingle := range small {
+		if large[shingle] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(small)+len(large)-shared)
+}
+
+// maxSimilarity bounds the similarity of prompts by their number of shingles,
+// so prompts of very different sizes are never compared in full
+func (a promptFingerprint) maxSimilarity(b promptFingerprint) float64 {
+	x, y := len(a.shingles), len(b.shingles)
+	if x > y {
+		x, y = y, x
+	}
+	if y == 0 {
+		return 0
+	}
+	return float64(x) / float64(y)
+}
+
+// Similarity returns how alike two prompts are, from 0 to 1
+func Similarity(a, b string) float64 {
+	return fingerprint(a).similarity(fingerprint(b))
+}
+
+// SimilarPrompts returns the garden prompts at least threshold alike to
+// content, most similar first. The prompt named skip, e.g. the one being
+// replaced, is left out.
+func (g *Garden) SimilarPrompts(content, skip string, threshold float64) ([]DuplicateMatch, error) {
+	prompts, err := g.ListPrompts()
+	if err != nil {
+		return nil, err
+	}
+	target := fingerprint(content)
+	var matches []DuplicateMatch
+	for _, prompt := range prompts {
+		if prompt.Name == skip {
+			continue
+		}
+		other := fingerprint(prompt.Content)
+		if target.maxSimilarity(other) < threshold && target.hash != other.hash {
+			continue
+		}
+		if sim := target.similarity(other); sim >= threshold {
+			matches = append(matches, DuplicateMatch{Name: prompt.Name, Similarity: sim, Exact: target.hash == other.hash})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches, nil
+}
+
+// FindDuplicates groups the garden's prompts into clusters of near
+// duplicates: prompts at least threshold alike end up in the same cluster.
+// Groups the user chose to keep apart are not reported again.
+func (g *Garden) FindDuplicates(threshold float64) ([]DuplicateCluster, error) {
+	prompts, err := g.ListPrompts()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	distinct, err := g.loadDistinct()
+	if err != nil {
+		return nil, err
+	}
+
+	prints := make([]promptFingerprint, len(prompts))
+	for i, prompt := range prompts {
+		prints[i] = fingerprint(prompt.Content)
+	}
+
+	parent := make([]int, len(prompts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	lowest := make(map[int]float64)
+	type link struct {
+		a, b int
+		sim  float64
+	}
+	var links []link
+	for i := range prompts {
+		for j := i + 1; j < len(prompts); j++ {
+			if prints[i].hash != prints[j].hash && prints[i].maxSimilarity(prints[j]) < threshold {
+				continue
+			}
+			if keptApart(distinct, prompts[i].Name, prompts[j].Name) {
+				continue
+			}
+			if sim := prints[i].similarity(prints[j]); sim >= threshold {
+				links = append(links, link{i, j, sim})
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	for _, l := range links {
+		root := find(l.a)
+		if sim, ok := lowest[root]; !ok || l.sim < sim {
+			lowest[root] = l.sim
+		}
+	}
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range prompts {
+		root := find(i)
+		if _, ok := lowest[root]; !ok {
+			continue
+		}
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(roots))
+	for _, root := range roots {
+		cluster := DuplicateCluster{Similarity: lowest[root], Exact: true}
+		for _, i := range groups[root] {
+			cluster.Prompts = append(cluster.Prompts, prompts[i])
+			cluster.Names = append(cluster.Names, prompts[i].Name)
+			if prints[i].hash != prints[groups[root][0]].hash {
+				cluster.Exact = false
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	// Closest duplicates first
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Similarity > clusters[j].Similarity })
+	return clusters, nil
+}
+
+// KeepDistinct records that the named prompts are meant to be alike, so they
+// are no longer reported as duplicates of each other
+func (g *Garden) KeepDistinct(names []string) error {
+	distinct, err := g.loadDistinct()
+	if err != nil {
+		return err
+	}
+	group := append([]string{}, names...)
+	sort.Strings(group)
+	distinct = append(distinct, group)
+	data, err := json.MarshalIndent(distinct, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFile(filepath.Join(g.storePath, distinctFile), data)
+}
+
+func (g *Garden) loadDistinct() ([][]string, error) {
+	data, err := os.ReadFile(filepath.Join(g.storePath, distinctFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var distinct [][]string
+	if err := json.Unmarshal(data, &distinct); err != nil {
+		return nil, err
+	}
+	return distinct, nil
+}
+
+// keptApart reports whether two prompts are in a group kept distinct
+func keptApart(distinct [][]string, a, b string) bool {
+	for _, group := range distinct {
+		hasA, hasB := false, false
+		for _, name := range group {
+			hasA = hasA || name == a
+			hasB = hasB || name == b
+		}
+		if hasA && hasB {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reviewPrompt = `Review the following diff for bugs, missing tests and unclear names.
+List blocking issues first, then suggestions, each with the file and line.
+Diff: {{diff}}`
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity(reviewPrompt, "  REVIEW the following diff for bugs, missing tests and unclear names.\n\nList blocking issues first, then suggestions, each with the file and line. Diff: {{diff}}"))
+	reworded := reviewPrompt + "\nBe concise."
+	assert.Greater(t, Similarity(reviewPrompt, reworded), DefaultSimilarity)
+	assert.Less(t, Similarity(reviewPrompt, "Summarize this document in three bullet points for an executive audience: {{doc}}"), 0.1)
+	assert.Equal(t, 0.0, Similarity("", reviewPrompt))
+}
+
+func savePrompts(t *testing.T, garden *Garden, prompts map[string]string) {
+	t.Helper()
+	for name, content := range prompts {
+		require.NoError(t, garden.SavePrompt(&Prompt{ID: name, Name: name, Content: content, Metadata: PromptMetadata{Category: "user", Version: "1.0.0"}}))
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	garden, err := NewGarden(t.TempDir())
+	require.NoError(t, err)
+	savePrompts(t, garden, map[string]string{
+		"code-review":   reviewPrompt,
+		"code-review-2": reviewPrompt + "\nBe concise.",
+		"review-copy":   "  " + reviewPrompt + "\n",
+		"summarize":     "Summarize this document in three bullet points: {{doc}}",
+		"summary":       "summarize this document in three bullet points: {{doc}}",
+		"translate":     "Translate {{text}} to French, keeping the formatting.",
+	})
+
+	clusters, err := garden.FindDuplicates(DefaultSimilarity)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, []string{"summarize", "summary"}, clusters[0].Names)
+	assert.True(t, clusters[0].Exact)
+	assert.Equal(t, []string{"code-review", "code-review-2", "review-copy"}, clusters[1].Names)
+	assert.False(t, clusters[1].Exact)
+	assert.Less(t, clusters[1].Similarity, 1.0)
+
+	// Prompts kept apart are not reported again
+	require.NoError(t, garden.KeepDistinct([]string{"summary", "summarize"}))
+	clusters, err = garden.FindDuplicates(DefaultSimilarity)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "code-review", clusters[0].Names[0])
+
+	matches, err := garden.SimilarPrompts(reviewPrompt, "code-review", DefaultSimilarity)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, DuplicateMatch{Name: "review-copy", Similarity: 1, Exact: true}, matches[0])
+	assert.Equal(t, "code-review-2", matches[1].Name)
+}
@@ -32,7 +32,7 @@ import (
 // Garden represents the prompt garden manager
 type Garden struct {
 	storePath string
-	store     core.PromptStore
+	store     *FileStore
 	templates *TemplateEngine
 	mu        sync.RWMutex
 }
@@ -76,6 +76,9 @@ func (g *Garden) Add(prompt core.Prompt) error {
 	if prompt.Name() == "" {
 		return fmt.Errorf("prompt name is required")
 	}
+	if err := validateVariants(prompt.Metadata()); err != nil {
+		return err
+	}
 
 	// Check for duplicates
 	if existing, err := g.store.GetByName(prompt.Name()); err == nil && existing != nil {
@@ -134,23 +137,14 @@ func (g *Garden) Search(query string) ([]core.Prompt, error) {
 	return g.store.Search(query)
 }
 
-// Execute executes a prompt with variables
+// Execute executes a prompt with variables. Prompts with variants run one
+// of them; use ExecuteVariant to learn which.
 func (g *Garden) Execute(nameOrID string, vars map[string]interface{}) (string, error) {
-	// Try to get by name first
-	prompt, err := g.GetByName(nameOrID)
+	rendered, err := g.ExecuteVariant(nameOrID, vars)
 	if err != nil {
-		// Try by ID
-		prompt, err = g.Get(nameOrID)
-		if err != nil {
-			return "", fmt.Errorf("prompt not found: %s", nameOrID)
-		}
+		return "", err
 	}
-
-	// Set include resolver
-	prompt.SetIncludeResolver(g)
-
-	// Execute template
-	return prompt.Template(vars)
+	return rendered.Text, nil
 }
 
 // ImportFromFile imports a prompt from a file
@@ -208,18 +202,17 @@ func (g *Garden) Resolve(promptID string) (core.Prompt, error) {
 	return g.Get(promptID)
 }
 
-// loadBuiltinPrompts loads built-in prompts
-func (g *Garden) loadBuiltinPrompts() error {
-	builtins := []struct {
-		name     string
-		category string
-		content  string
-		tags     []string
-	}{
-		{
-			name:     "planning-template",
-			category: "templates",
-			content: `# Planning Phase
+// builtinPrompts are the prompts every garden starts with
+var builtinPrompts = []struct {
+	name     string
+	category string
+	content  string
+	tags     []string
+}{
+	{
+		name:     "planning-template",
+		category: "templates",
+		content: `# Planning Phase
 
 ## Overview
 {{description}}
@@ -238,12 +231,12 @@ func (g *Garden) loadBuiltinPrompts() error {
 
 ## Risks and Mitigations
 [Identify potential risks and how to mitigate them]`,
-			tags: []string{"planning", "template", "refactor"},
-		},
-		{
-			name:     "review-template",
-			category: "templates",
-			content: `# Code Review
+		tags: []string{"planning", "template", "refactor"},
+	},
+	{
+		name:     "review-template",
+		category: "templates",
+		content: `# Code Review
 
 ## Changes Summary
 {{changes_summary}}
@@ -268,12 +261,12 @@ func (g *Garden) loadBuiltinPrompts() error {
 
 ## Recommendations
 [Provide specific recommendations for improvement]`,
-			tags: []string{"review", "template", "quality"},
-		},
-		{
-			name:     "questions-template",
-			category: "templates",
-			content: `# Clarifying Questions
+		tags: []string{"review", "template", "quality"},
+	},
+	{
+		name:     "questions-template",
+		category: "templates",
+		content: `# Clarifying Questions
 
 Based on the provided {{document_type}}, I have the following questions:
 
@@ -293,11 +286,23 @@ Based on the provided {{document_type}}, I have the following questions:
 {{/each}}
 
 Please provide answers to help create a comprehensive implementation plan.`,
-			tags: []string{"questions", "template", "discovery"},
-		},
+		tags: []string{"questions", "template", "discovery"},
+	},
+}
+
+// IsBuiltin reports whether a prompt is one every garden starts with
+func IsBuiltin(name string) bool {
+	for _, builtin := range builtinPrompts {
+		if builtin.name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	for _, builtin := range builtins {
+// loadBuiltinPrompts loads built-in prompts
+func (g *Garden) loadBuiltinPrompts() error {
+	for _, builtin := range builtinPrompts {
 		metadata := core.PromptMetadata{
 			Name:        builtin.name,
 			Description: fmt.Sprintf("Built-in %s", builtin.name),
@@ -368,6 +373,8 @@ func (g *Garden) importMarkdown(filePath string, data []byte) error {
 					metadata.Name = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
 				} else if strings.HasPrefix(line, "description:") {
 					metadata.Description = strings.TrimSpace(strings.TrimPrefix(line, "description:"))
+				} else if strings.HasPrefix(line, "variant_mode:") {
+					metadata.VariantMode = strings.TrimSpace(strings.TrimPrefix(line, "variant_mode:"))
 				} else if strings.HasPrefix(line, "category:") {
 					metadata.Category = strings.TrimSpace(strings.TrimPrefix(line, "category:"))
 				} else if strings.HasPrefix(line, "tags:") {
@@ -381,6 +388,13 @@ func (g *Garden) importMarkdown(filePath string, data []byte) error {
 		}
 	}
 
+	// <!-- variant: name --> markers split the body into variants; the
+	// first one doubles as the prompt's content
+	if variants := splitVariants(content); len(variants) > 0 {
+		metadata.Variants = variants
+		content = variants[0].Content
+	}
+
 	prompt := NewTemplatePrompt(metadata, content)
 	return g.Add(prompt)
 }
@@ -0,0 +1,144 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+// HistoryFile is the file under the runs directory that records the outcome
+// of every prompt run
+const HistoryFile = "prompt-history.jsonl"
+
+// Outcome is the result of one run of a garden prompt
+type Outcome struct {
+	Time     time.Time `json:"time"`
+	Prompt   string    `json:"prompt"`
+	Variant  string    `json:"variant,omitempty"`
+	Command  string    `json:"command"` // exec or map
+	Provider string    `json:"provider,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Success  bool      `json:"success"`
+	// Duration is in seconds
+	Duration    float64 `json:"duration"`
+	OutputBytes int     `json:"output_bytes,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// VariantStats summarizes the outcomes of one variant of a prompt
+type VariantStats struct {
+	Variant     string  `json:"variant"`
+	Runs        int     `json:"runs"`
+	Successes   int     `json:"successes"`
+	SuccessRate float64 `json:"success_rate"`
+	AvgDuration float64 `json:"avg_duration"`
+	AvgOutput   float64 `json:"avg_output_bytes"`
+}
+
+// RecordOutcome appends an outcome to the history file in dir
+func RecordOutcome(dir string, outcome Outcome) error {
+	if outcome.Time.IsZero() {
+		outcome.Time = time.Now()
+	}
+	if err := utils.EnsureDir(dir); err != nil {
+		return err
+	}
+	data, err := json.Marshal(outcome)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, HistoryFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open prompt history: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// LoadOutcomes reads the recorded outcomes of a prompt from dir, oldest first
+func LoadOutcomes(dir, prompt string) ([]Outcome, error) {
+	f, err := os.Open(filepath.Join(dir, HistoryFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var outcomes []Outcome
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var outcome Outcome
+		if err := json.Unmarshal(scanner.Bytes(), &outcome); err != nil {
+			continue // Skip lines cut short by a crash
+		}
+		if outcome.Prompt == prompt {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes, scanner.Err()
+}
+
+// SummarizeOutcomes groups outcomes by variant, best success rate first
+func SummarizeOutcomes(outcomes []Outcome) []VariantStats {
+	byVariant := make(map[string]*VariantStats)
+	totalOutput := make(map[string]int)
+	for _, outcome := range outcomes {
+		name := outcome.Variant
+		if name == "" {
+			name = DefaultVariant
+		}
+		stats, ok := byVariant[name]
+		if !ok {
+			stats = &VariantStats{Variant: name}
+			byVariant[name] = stats
+		}
+		stats.Runs++
+		if outcome.Success {
+			stats.Successes++
+		}
+		stats.AvgDuration += outcome.Duration
+		totalOutput[name] += outcome.OutputBytes
+	}
+
+	summary := make([]VariantStats, 0, len(byVariant))
+	for name, stats := range byVariant {
+		stats.SuccessRate = float64(stats.Successes) / float64(stats.Runs)
+		stats.AvgDuration /= float64(stats.Runs)
+		stats.AvgOutput = float64(totalOutput[name]) / float64(stats.Runs)
+		summary = append(summary, *stats)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].SuccessRate != summary[j].SuccessRate {
+			return summary[i].SuccessRate > summary[j].SuccessRate
+		}
+		return summary[i].Variant < summary[j].Variant
+	})
+	return summary
+}
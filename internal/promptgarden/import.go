@@ -0,0 +1,112 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ImportedPrompt is what the cleanup agent of opun add prompt --from-url
+// extracts from a fetched page
+type ImportedPrompt struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Content     string   `json:"content"`
+}
+
+// RawPromptURL rewrites links to files on GitHub and to gists so they fetch
+// the raw text instead of the page around it. Other URLs are kept.
+func RawPromptURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("prompt URLs must be http or https, got %q", rawURL)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch strings.ToLower(u.Hostname()) {
+	case "github.com":
+		// github.com/<owner>/<repo>/blob/<ref>/<file>
+		if len(parts) >= 5 && parts[2] == "blob" {
+			return "https://raw.githubusercontent.com/" + strings.Join(append(parts[:2:2], parts[3:]...), "/"), nil
+		}
+	case "gist.github.com":
+		// gist.github.com/<user>/<id>
+		if len(parts) == 2 {
+			return "https://gist.githubusercontent.com/" + parts[0] + "/" + parts[1] + "/raw", nil
+		}
+	}
+	return u.String(), nil
+}
+
+var promptNameRe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// PromptNameFromURL derives a garden name from the file a URL points to
+func PromptNameFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if segment == "" || segment == "raw" {
+			continue
+		}
+		name := strings.TrimSuffix(strings.ToLower(segment), path.Ext(segment))
+		if name = strings.Trim(promptNameRe.ReplaceAllString(name, "-"), "-"); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ParseImportedPrompt reads the JSON object the cleanup agent answers with
+func ParseImportedPrompt(output string) (ImportedPrompt, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return ImportedPrompt{}, fmt.Errorf("no prompt in cleanup output")
+	}
+	var prompt ImportedPrompt
+	if err := json.Unmarshal([]byte(output[start:end+1]), &prompt); err != nil {
+		return ImportedPrompt{}, fmt.Errorf("invalid cleanup output: %w", err)
+	}
+	prompt.Content = strings.TrimSpace(prompt.Content)
+	if prompt.Content == "" {
+		return ImportedPrompt{}, fmt.Errorf("cleanup found no prompt on the page")
+	}
+	prompt.Name = strings.Trim(promptNameRe.ReplaceAllString(strings.ToLower(prompt.Name), "-"), "-")
+	return prompt, nil
+}
+
+// ContentDigest returns the SHA-256 of fetched content, recorded so update
+// checks can tell when the source has changed
+func ContentDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,61 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawPromptURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://github.com/acme/prompts/blob/main/review/go.md": "https://raw.githubusercontent.com/acme/prompts/main/review/go.md",
+		"https://gist.github.com/octocat/0123abcd":               "https://gist.githubusercontent.com/octocat/0123abcd/raw",
+		"https://example.com/prompts/review.txt":                 "https://example.com/prompts/review.txt",
+		"https://github.com/acme/prompts":                        "https://github.com/acme/prompts",
+	} {
+		got, err := RawPromptURL(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := RawPromptURL("file:///etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestPromptNameFromURL(t *testing.T) {
+	assert.Equal(t, "go", PromptNameFromURL("https://raw.githubusercontent.com/acme/prompts/main/review/go.md"))
+	assert.Equal(t, "0123abcd", PromptNameFromURL("https://gist.githubusercontent.com/octocat/0123abcd/raw"))
+	assert.Equal(t, "code-review-v2", PromptNameFromURL("https://example.com/Code%20Review%20v2.txt"))
+	assert.Equal(t, "", PromptNameFromURL("https://example.com/"))
+}
+
+func TestParseImportedPrompt(t *testing.T) {
+	prompt, err := ParseImportedPrompt("Here it is:\n```json\n{\"name\": \"Bug Triage\", \"description\": \"Triages bugs\", \"tags\": [\"triage\"], \"content\": \"  Triage {{issue}}.\\n\"}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, ImportedPrompt{Name: "bug-triage", Description: "Triages bugs", Tags: []string{"triage"}, Content: "Triage {{issue}}."}, prompt)
+
+	_, err = ParseImportedPrompt(`{"name": "empty", "content": " "}`)
+	assert.ErrorContains(t, err, "no prompt")
+	_, err = ParseImportedPrompt("Sorry, I couldn't find one.")
+	assert.Error(t, err)
+}
+
+func TestContentDigest(t *testing.T) {
+	assert.Equal(t, "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", ContentDigest([]byte("hello")))
+}
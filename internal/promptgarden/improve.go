@@ -0,0 +1,249 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Directories under the garden keeping the test cases of prompts and the
+// improved versions waiting for approval
+const (
+	testsDirName   = "tests"
+	pendingDirName = "pending"
+)
+
+// TestCase is an example a prompt should handle well, used to judge an
+// improved version of it
+type TestCase struct {
+	Name string            `yaml:"name" json:"name"`
+	Vars map[string]string `yaml:"vars,omitempty" json:"vars,omitempty"`
+	// Expect describes what a good answer does
+	Expect string `yaml:"expect" json:"expect"`
+}
+
+// Verdict is the evaluator's judgement of an improved prompt
+type Verdict struct {
+	Pass  bool    `json:"pass"`
+	Score float64 `json:"score"`
+	Notes string  `json:"notes,omitempty"`
+}
+
+// PendingVersion is an improved prompt waiting for the user to approve it
+type PendingVersion struct {
+	Prompt      string    `json:"prompt"`
+	BaseVersion string    `json:"base_version,omitempty"`
+	Version     string    `json:"version"`
+	Content     string    `json:"content"`
+	Verdict     Verdict   `json:"verdict"`
+	OutputDir   string    `json:"output_dir,omitempty"`
+	Created     time.Time `json:"created"`
+}
+
+// TestsPath returns the file holding the test cases of a prompt
+func (g *Garden) TestsPath(prompt string) string {
+	return filepath.Join(g.storePath, testsDirName, prompt+".yaml")
+}
+
+// LoadTestCases reads the test cases of a prompt, none when it has no file
+func (g *Garden) LoadTestCases(prompt string) ([]TestCase, error) {
+	data, err := os.ReadFile(g.TestsPath(prompt))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var cases []TestCase
+	if err := yaml.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("invalid test cases for %s: %w", prompt, err)
+	}
+	for i, tc := range cases {
+		if strings.TrimSpace(tc.Expect) == "" {
+			return nil, fmt.Errorf("test case %d of %s has no expect", i+1, prompt)
+		}
+		if tc.Name == "" {
+			cases[i].Name = fmt.Sprintf("case-%d", i+1)
+		}
+	}
+	return cases, nil
+}
+
+// SavePending stores an improved version of a prompt for approval, replacing
+// any earlier one
+func (g *Garden) SavePending(pending *PendingVersion) error {
+	dir := filepath.Join(g.storePath, pendingDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if pending.Created.IsZero() {
+		pending.Created = time.Now()
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(g.pendingPath(pending.Prompt), data, 0644)
+}
+
+// LoadPending returns the improved version of a prompt waiting for approval
+func (g *Garden) LoadPending(prompt string) (*PendingVersion, error) {
+	data, err := os.ReadFile(g.pendingPath(prompt))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no pending version of %s", prompt)
+		}
+		return nil, err
+	}
+	var pending PendingVersion
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("invalid pending version of %s: %w", prompt, err)
+	}
+	return &pending, nil
+}
+
+// ListPending returns the prompts with a version waiting for approval
+func (g *Garden) ListPending() ([]*PendingVersion, error) {
+	entries, err := os.ReadDir(filepath.Join(g.storePath, pendingDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list []*PendingVersion
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		pending, err := g.LoadPending(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		list = append(list, pending)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Prompt < list[j].Prompt })
+	return list, nil
+}
+
+// ApprovePending replaces a prompt's content with its pending version and
+// bumps its version. The prompt's variants are dropped, since the new
+// content was written without them.
+func (g *Garden) ApprovePending(name string) (*Prompt, error) {
+	pending, err := g.LoadPending(name)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := g.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := g.GetPrompt(existing.ID())
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Metadata.Version != pending.BaseVersion {
+		return nil, fmt.Errorf("%s changed since the pending version was proposed (now %s, was %s); run opun prompt improve again", name, prompt.Metadata.Version, pending.BaseVersion)
+	}
+
+	prompt.Content = pending.Content
+	prompt.Metadata.Version = pending.Version
+	prompt.Metadata.VariantMode = ""
+	prompt.Variants = nil
+	if err := g.SavePrompt(prompt); err != nil {
+		return nil, err
+	}
+	return prompt, g.RejectPending(name)
+}
+
+// RejectPending discards the pending version of a prompt
+func (g *Garden) RejectPending(name string) error {
+	if err := os.Remove(g.pendingPath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no pending version of %s", name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (g *Garden) pendingPath(prompt string) string {
+	return filepath.Join(g.storePath, pendingDirName, prompt+".json")
+}
+
+// NextVersion bumps the minor part of a dotted version, "1.2.3" becomes
+// "1.3.0". Prompts without a version are taken to be 1.0.0.
+func NextVersion(version string) string {
+	if version == "" {
+		version = "1.0.0"
+	}
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return version + ".1"
+		}
+	}
+	minor, _ := strconv.Atoi(parts[1])
+	parts[1] = strconv.Itoa(minor + 1)
+	for i := 2; i < len(parts); i++ {
+		parts[i] = "0"
+	}
+	return strings.Join(parts, ".")
+}
+
+// ParseVerdict reads the evaluator's JSON verdict from its answer, which may
+// wrap it in prose or a code fence
+func ParseVerdict(output string) (Verdict, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return Verdict{}, fmt.Errorf("no verdict in evaluator output")
+	}
+	var verdict Verdict
+	if err := json.Unmarshal([]byte(output[start:end+1]), &verdict); err != nil {
+		return Verdict{}, fmt.Errorf("invalid verdict: %w", err)
+	}
+	return verdict, nil
+}
+
+// StripCodeFence returns the body of an answer wrapped in a single markdown
+// code fence, or the trimmed answer otherwise
+func StripCodeFence(output string) string {
+	output = strings.TrimSpace(output)
+	if !strings.HasPrefix(output, "```") || !strings.HasSuffix(output, "```") || len(output) < 6 {
+		return output
+	}
+	body := strings.TrimSuffix(output, "```")
+	if i := strings.Index(body, "\n"); i >= 0 {
+		body = body[i+1:]
+	} else {
+		return output
+	}
+	return strings.TrimSpace(body)
+}
@@ -0,0 +1,115 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingVersionLifecycle(t *testing.T) {
+	garden, err := NewGarden(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, garden.SavePrompt(&Prompt{
+		ID:       "review",
+		Name:     "review",
+		Content:  "Review {{diff}}",
+		Metadata: PromptMetadata{Version: "1.2.3"},
+	}))
+
+	_, err = garden.LoadPending("review")
+	assert.ErrorContains(t, err, "no pending version of review")
+
+	require.NoError(t, garden.SavePending(&PendingVersion{
+		Prompt:      "review",
+		BaseVersion: "1.2.3",
+		Version:     NextVersion("1.2.3"),
+		Content:     "Review {{diff}} carefully",
+		Verdict:     Verdict{Pass: true, Score: 0.9},
+	}))
+	list, err := garden.ListPending()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "1.3.0", list[0].Version)
+	assert.False(t, list[0].Created.IsZero())
+
+	prompt, err := garden.ApprovePending("review")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", prompt.Metadata.Version)
+
+	stored, err := garden.GetPrompt("review")
+	require.NoError(t, err)
+	assert.Equal(t, "Review {{diff}} carefully", stored.Content)
+	list, err = garden.ListPending()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	t.Run("stale pending version", func(t *testing.T) {
+		require.NoError(t, garden.SavePending(&PendingVersion{Prompt: "review", BaseVersion: "1.2.3", Version: "1.3.0", Content: "x"}))
+		_, err := garden.ApprovePending("review")
+		assert.ErrorContains(t, err, "changed since")
+		require.NoError(t, garden.RejectPending("review"))
+		assert.Error(t, garden.RejectPending("review"))
+	})
+}
+
+func TestLoadTestCases(t *testing.T) {
+	garden, err := NewGarden(t.TempDir())
+	require.NoError(t, err)
+
+	cases, err := garden.LoadTestCases("review")
+	require.NoError(t, err)
+	assert.Empty(t, cases)
+
+	path := garden.TestsPath("review")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("- name: empty\n  vars:\n    diff: \"\"\n  expect: Says there is nothing to review\n- expect: Flags the SQL injection\n"), 0644))
+	cases, err = garden.LoadTestCases("review")
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, map[string]string{"diff": ""}, cases[0].Vars)
+	assert.Equal(t, "case-2", cases[1].Name)
+
+	require.NoError(t, os.WriteFile(path, []byte("- name: vague\n"), 0644))
+	_, err = garden.LoadTestCases("review")
+	assert.ErrorContains(t, err, "has no expect")
+}
+
+func TestParseVerdict(t *testing.T) {
+	verdict, err := ParseVerdict("Here you go:\n```json\n{\"pass\": true, \"score\": 0.8, \"notes\": \"ok\"}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{Pass: true, Score: 0.8, Notes: "ok"}, verdict)
+
+	_, err = ParseVerdict("looks good to me")
+	assert.Error(t, err)
+}
+
+func TestNextVersion(t *testing.T) {
+	assert.Equal(t, "1.1.0", NextVersion(""))
+	assert.Equal(t, "1.3.0", NextVersion("1.2.3"))
+	assert.Equal(t, "2.1.0", NextVersion("2"))
+	assert.Equal(t, "beta.1", NextVersion("beta"))
+}
+
+func TestStripCodeFence(t *testing.T) {
+	assert.Equal(t, "Review {{diff}}", StripCodeFence("```markdown\nReview {{diff}}\n```\n"))
+	assert.Equal(t, "Review {{diff}}", StripCodeFence("  Review {{diff}}\n"))
+}
@@ -0,0 +1,210 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/core"
+)
+
+// DefaultPageSize is how many prompts a page holds when no limit is given
+const DefaultPageSize = 50
+
+// MaxPageSize caps the prompts on one page
+const MaxPageSize = 500
+
+// ErrInvalidCursor is returned for a cursor that no page handed out
+var ErrInvalidCursor = errors.New("invalid prompt cursor")
+
+// Summary is a prompt's metadata from the garden index, listed without
+// loading the prompt's content. Get the prompt when the content is needed.
+type Summary struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Category    string                `json:"category,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Version     string                `json:"version,omitempty"`
+	Variables   []core.PromptVariable `json:"variables,omitempty"`
+}
+
+// ListOptions selects a page of prompts. Filters combine; empty ones match
+// everything.
+type ListOptions struct {
+	// Cursor is the NextCursor of the previous page, empty for the first
+	Cursor string
+	// Limit is the page size, DefaultPageSize when zero
+	Limit    int
+	Category string
+	// Tags matches prompts with any of the tags
+	Tags []string
+	// Query matches names, categories and tags, case-insensitively
+	Query string
+	// Filter, when set, drops the prompts it returns false for
+	Filter func(Summary) bool
+}
+
+// Page is one page of prompts, ordered by name
+type Page struct {
+	Prompts []Summary `json:"prompts"`
+	// NextCursor fetches the next page, empty on the last one
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// summary returns the index entry as a summary
+func (e *indexEntry) summary() Summary {
+	return Summary{
+		ID:          e.ID,
+		Name:        e.Name,
+		Description: e.Description,
+		Category:    e.Category,
+		Tags:        e.Tags,
+		Version:     e.Version,
+		Variables:   e.Variables,
+	}
+}
+
+// matches reports whether a summary passes the filters of the options
+func (o ListOptions) matches(s Summary) bool {
+	if o.Category != "" && s.Category != o.Category {
+		return false
+	}
+	if len(o.Tags) > 0 && !hasAnyTag(s.Tags, o.Tags) {
+		return false
+	}
+	if o.Query != "" && !summaryContains(s, strings.ToLower(o.Query)) {
+		return false
+	}
+	return o.Filter == nil || o.Filter(s)
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// summaryContains matches a lowercase query like Search does
+func summaryContains(s Summary, query string) bool {
+	if strings.Contains(strings.ToLower(s.Name), query) || strings.Contains(strings.ToLower(s.Category), query) {
+		return true
+	}
+	for _, tag := range s.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCursor returns the cursor of the page after a prompt. Cursors are
+// positions in name order, so prompts added or removed between pages don't
+// shift the pages that follow.
+func encodeCursor(s Summary) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s.Name + "\x00" + s.ID))
+}
+
+func decodeCursor(cursor string) (name, id string, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	name, id, ok := strings.Cut(string(data), "\x00")
+	if !ok {
+		return "", "", ErrInvalidCursor
+	}
+	return name, id, nil
+}
+
+// Summaries returns the summaries of all prompts, ordered by name
+func (s *FileStore) Summaries() []Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make([]Summary, 0, len(s.index))
+	for _, entry := range s.index {
+		summaries = append(summaries, entry.summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Name != summaries[j].Name {
+			return summaries[i].Name < summaries[j].Name
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries
+}
+
+// ListPage returns a page of prompt summaries from the index
+func (s *FileStore) ListPage(opts ListOptions) (Page, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	var afterName, afterID string
+	if opts.Cursor != "" {
+		var err error
+		if afterName, afterID, err = decodeCursor(opts.Cursor); err != nil {
+			return Page{}, err
+		}
+	}
+
+	page := Page{Prompts: []Summary{}}
+	for _, summary := range s.Summaries() {
+		if opts.Cursor != "" && (summary.Name < afterName || (summary.Name == afterName && summary.ID <= afterID)) {
+			continue
+		}
+		if !opts.matches(summary) {
+			continue
+		}
+		if len(page.Prompts) == limit {
+			page.NextCursor = encodeCursor(page.Prompts[limit-1])
+			break
+		}
+		page.Prompts = append(page.Prompts, summary)
+	}
+	return page, nil
+}
+
+// Summaries returns the summaries of all prompts, ordered by name, without
+// loading their content
+func (g *Garden) Summaries() []Summary {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.store.Summaries()
+}
+
+// ListPage returns a page of prompt summaries without loading their content
+func (g *Garden) ListPage(opts ListOptions) (Page, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.store.ListPage(opts)
+}
@@ -0,0 +1,136 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addPrompts(t *testing.T, garden *Garden, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		metadata := core.PromptMetadata{
+			Name:        fmt.Sprintf("prompt-%03d", i),
+			Description: fmt.Sprintf("Prompt %d", i),
+			Category:    []string{"review", "planning"}[i%2],
+			Tags:        []string{fmt.Sprintf("tag-%d", i%3)},
+		}
+		require.NoError(t, garden.Add(NewTemplatePrompt(metadata, "Look at {{target}}")))
+	}
+}
+
+func TestListPage(t *testing.T) {
+	garden, err := NewGarden(t.TempDir())
+	require.NoError(t, err)
+	addPrompts(t, garden, 25)
+	notTemplate := func(s Summary) bool { return s.Category != "templates" }
+
+	// Walk every page
+	var names []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+		page, err := garden.ListPage(ListOptions{Cursor: cursor, Limit: 10, Filter: notTemplate})
+		require.NoError(t, err)
+		for _, p := range page.Prompts {
+			names = append(names, p.Name)
+		}
+		if page.NextCursor == "" {
+			assert.Equal(t, 2, pages)
+			break
+		}
+		assert.Len(t, page.Prompts, 10)
+		cursor = page.NextCursor
+	}
+	require.Len(t, names, 25)
+	assert.Equal(t, "prompt-000", names[0])
+	assert.Equal(t, "prompt-024", names[24])
+
+	// Summaries come from the index, with variables and descriptions
+	page, err := garden.ListPage(ListOptions{Limit: 1, Filter: notTemplate})
+	require.NoError(t, err)
+	assert.Equal(t, "Prompt 0", page.Prompts[0].Description)
+	require.Len(t, page.Prompts[0].Variables, 1)
+	assert.Equal(t, "target", page.Prompts[0].Variables[0].Name)
+
+	// Prompts added before the cursor don't shift later pages
+	first, err := garden.ListPage(ListOptions{Limit: 5, Filter: notTemplate})
+	require.NoError(t, err)
+	require.NoError(t, garden.Add(NewTemplatePrompt(core.PromptMetadata{Name: "prompt-000a"}, "New")))
+	second, err := garden.ListPage(ListOptions{Cursor: first.NextCursor, Limit: 5, Filter: notTemplate})
+	require.NoError(t, err)
+	assert.Equal(t, "prompt-005", second.Prompts[0].Name)
+
+	// Filters
+	page, err = garden.ListPage(ListOptions{Category: "review", Tags: []string{"tag-0"}, Limit: MaxPageSize + 1})
+	require.NoError(t, err)
+	for _, p := range page.Prompts {
+		assert.Equal(t, "review", p.Category)
+		assert.Equal(t, []string{"tag-0"}, p.Tags)
+	}
+	assert.Len(t, page.Prompts, 5)
+
+	page, err = garden.ListPage(ListOptions{Query: "PROMPT-01"})
+	require.NoError(t, err)
+	assert.Len(t, page.Prompts, 10)
+
+	_, err = garden.ListPage(ListOptions{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestSummariesFromOlderIndex(t *testing.T) {
+	dir := t.TempDir()
+	garden, err := NewGarden(dir)
+	require.NoError(t, err)
+	addPrompts(t, garden, 2)
+
+	// Strip the summaries, as an index written before they existed
+	indexPath := filepath.Join(dir, "index.json")
+	data, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	var index map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &index))
+	for _, entry := range index {
+		for _, field := range []string{"Description", "Version", "Variables", "Summarized"} {
+			delete(entry, field)
+		}
+	}
+	data, err = json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(indexPath, data, 0644))
+
+	garden, err = NewGarden(dir)
+	require.NoError(t, err)
+	page, err := garden.ListPage(ListOptions{Query: "prompt-001"})
+	require.NoError(t, err)
+	require.Len(t, page.Prompts, 1)
+	assert.Equal(t, "Prompt 1", page.Prompts[0].Description)
+	assert.Len(t, page.Prompts[0].Variables, 1)
+
+	// The refreshed index was saved
+	data, err = os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Summarized": true`)
+}
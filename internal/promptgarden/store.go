@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/pkg/core"
@@ -35,13 +36,20 @@ type FileStore struct {
 	mu       sync.RWMutex
 }
 
-// indexEntry stores metadata for quick lookups
+// indexEntry stores metadata for quick lookups and for listing prompts
+// without reading their files
 type indexEntry struct {
-	ID       string
-	Name     string
-	Category string
-	Tags     []string
-	FilePath string
+	ID          string
+	Name        string
+	Category    string
+	Tags        []string
+	FilePath    string
+	Description string                `json:",omitempty"`
+	Version     string                `json:",omitempty"`
+	Variables   []core.PromptVariable `json:",omitempty"`
+	// Summarized is false for entries indexed before the index held
+	// descriptions and variables
+	Summarized bool `json:",omitempty"`
 }
 
 // NewFileStore creates a new file-based prompt store
@@ -95,6 +103,22 @@ func (s *FileStore) Get(id string) (core.Prompt, error) {
 	return s.loadPrompt(entry.FilePath)
 }
 
+// ModTime returns when a prompt's file was last written
+func (s *FileStore) ModTime(id string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.index[id]
+	if !ok {
+		return time.Time{}, fmt.Errorf("prompt not found: %s", id)
+	}
+	info, err := os.Stat(entry.FilePath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // GetByName retrieves a prompt by name
 func (s *FileStore) GetByName(name string) (core.Prompt, error) {
 	s.mu.RLock()
@@ -340,7 +364,25 @@ func (s *FileStore) loadIndex() error {
 		return err
 	}
 
-	return json.Unmarshal(data, &s.index)
+	if err := json.Unmarshal(data, &s.index); err != nil {
+		return err
+	}
+
+	// Fill in the summaries of entries from older indexes once
+	refreshed := false
+	for _, entry := range s.index {
+		if entry.Summarized {
+			continue
+		}
+		if prompt, err := s.loadPrompt(entry.FilePath); err == nil {
+			s.updateIndex(prompt, entry.FilePath)
+			refreshed = true
+		}
+	}
+	if refreshed {
+		return s.saveIndex()
+	}
+	return nil
 }
 
 func (s *FileStore) saveIndex() error {
@@ -357,11 +399,15 @@ func (s *FileStore) saveIndex() error {
 func (s *FileStore) updateIndex(prompt core.Prompt, filePath string) {
 	metadata := prompt.Metadata()
 	s.index[prompt.ID()] = &indexEntry{
-		ID:       prompt.ID(),
-		Name:     prompt.Name(),
-		Category: metadata.Category,
-		Tags:     metadata.Tags,
-		FilePath: filePath,
+		ID:          prompt.ID(),
+		Name:        prompt.Name(),
+		Category:    metadata.Category,
+		Tags:        metadata.Tags,
+		FilePath:    filePath,
+		Description: metadata.Description,
+		Version:     metadata.Version,
+		Variables:   prompt.Variables(),
+		Summarized:  true,
 	}
 }
 
@@ -191,7 +191,6 @@ func (e *TemplateEngine) registerDefaultFuncs() {
 	e.funcMap["contains"] = strings.Contains
 	e.funcMap["hasPrefix"] = strings.HasPrefix
 	e.funcMap["hasSuffix"] = strings.HasSuffix
-	e.funcMap["join"] = strings.Join
 	e.funcMap["split"] = strings.Split
 
 	// Date/time functions
@@ -225,6 +224,8 @@ func (e *TemplateEngine) registerDefaultFuncs() {
 
 		return prompt.Content(), nil
 	}
+
+	e.registerContextFuncs()
 }
 
 // TemplatePrompt implements the Prompt interface with template support
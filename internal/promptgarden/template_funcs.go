@@ -0,0 +1,344 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+const (
+	defaultShellTimeout = 10 * time.Second
+	maxTemplateFileSize = 1 << 20
+)
+
+// deniedTemplateFiles are the secrets {{file}} and {{glob}} never read or
+// list, the same ones the filesystem tools deny
+var deniedTemplateFiles = []string{".env", ".env.*", "*.pem", "*.key"}
+
+// ShellPolicy controls the {{shell}} template function
+type ShellPolicy struct {
+	Enabled bool          // allow prompts to run commands at all
+	Allow   []string      // command prefixes that may run (e.g. "git log"), empty allows any command
+	Timeout time.Duration // maximum run time of a single command
+}
+
+// DefaultShellPolicy returns the policy used when nothing is configured
+func DefaultShellPolicy() ShellPolicy {
+	return ShellPolicy{Timeout: defaultShellTimeout}
+}
+
+var (
+	shellPolicyMu sync.RWMutex
+	shellPolicy   = DefaultShellPolicy()
+)
+
+// SetShellPolicy configures which commands prompt templates may run
+func SetShellPolicy(policy ShellPolicy) {
+	shellPolicyMu.Lock()
+	defer shellPolicyMu.Unlock()
+	shellPolicy = policy
+}
+
+func currentShellPolicy() ShellPolicy {
+	shellPolicyMu.RLock()
+	defer shellPolicyMu.RUnlock()
+	return shellPolicy
+}
+
+// allows reports whether the policy permits running command
+func (p ShellPolicy) allows(command string) bool {
+	if !p.Enabled {
+		return false
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, prefix := range p.Allow {
+		prefix = strings.Join(strings.Fields(prefix), " ")
+		if prefix != "" && (command == prefix || strings.HasPrefix(command, prefix+" ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerContextFuncs registers the helpers prompts use to assemble their own context
+func (e *TemplateEngine) registerContextFuncs() {
+	e.funcMap["file"] = templateFile
+	e.funcMap["glob"] = templateGlob
+	e.funcMap["shell"] = templateShell
+	e.funcMap["head"] = headLines
+	e.funcMap["tail"] = tailLines
+	e.funcMap["join"] = templateJoin
+}
+
+// templateFile reads a file inside the working directory
+func templateFile(path string) (string, error) {
+	resolved, err := workspacePath(path)
+	if err != nil {
+		return "", err
+	}
+	// A link named notes.txt can still point at .env
+	if deniedTemplateFile(path) || deniedTemplateFile(resolved) {
+		return "", fmt.Errorf("%s is denied: prompts can't read secrets", path)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > maxTemplateFileSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", path, maxTemplateFileSize)
+	}
+
+	// #nosec G304 -- path is confined to the working directory
+	content, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// templateGlob lists the files under the working directory that match pattern,
+// where ** matches any number of directories
+func templateGlob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	if _, err := workspacePath(pattern); err != nil {
+		return nil, err
+	}
+
+	matcher, err := utils.GlobRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	root := globRoot(pattern)
+	matches := []string{}
+	err = filepath.WalkDir(filepath.Join(cwd, root), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(cwd, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matcher.MatchString(rel) && !deniedTemplateFile(rel) {
+			matches = append(matches, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globRoot returns the leading directories of pattern that contain no wildcards
+func globRoot(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	var root []string
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+		root = append(root, part)
+	}
+	if len(root) == 0 {
+		return "."
+	}
+	return strings.Join(root, "/")
+}
+
+// deniedTemplateFile reports whether path names a file prompts may not read
+func deniedTemplateFile(path string) bool {
+	base := filepath.Base(path)
+	for _, pattern := range deniedTemplateFiles {
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// workspacePath resolves path and rejects anything outside the working directory
+func workspacePath(path string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if real, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = real
+	}
+
+	resolved := path
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(cwd, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	// Resolve symlinks on the longest existing prefix so links can't escape
+	// the working directory
+	existing := resolved
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+	if real, err := filepath.EvalSymlinks(existing); err == nil {
+		resolved = filepath.Join(append([]string{real}, rest...)...)
+	}
+
+	rel, err := filepath.Rel(cwd, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the working directory", path)
+	}
+	return resolved, nil
+}
+
+// templateShell runs a command allowed by the shell policy and returns its output.
+// The command is split on whitespace and run directly, without a shell, so pipes,
+// redirects and substitutions are never interpreted.
+func templateShell(command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("shell: empty command")
+	}
+	command = strings.Join(args, " ")
+
+	policy := currentShellPolicy()
+	if !policy.allows(command) {
+		if !policy.Enabled {
+			return "", fmt.Errorf("shell: commands are disabled in prompt templates (set prompt_shell.enabled)")
+		}
+		return "", fmt.Errorf("shell: %q is not in prompt_shell.allow", command)
+	}
+
+	timeout := policy.Timeout
+	if timeout <= 0 {
+		timeout = defaultShellTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	// #nosec G204 -- command is checked against the configured shell policy
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("shell: %q timed out after %s", command, timeout)
+		}
+		return "", fmt.Errorf("shell: %q failed: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// headLines returns the first n lines of s
+func headLines(n int, s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	if n < 0 {
+		n = 0
+	}
+	if n >= len(lines) {
+		return s
+	}
+	return strings.TrimRight(strings.Join(lines[:n], ""), "\n")
+}
+
+// tailLines returns the last n lines of s
+func tailLines(n int, s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if n < 0 {
+		n = 0
+	}
+	if n >= len(lines) {
+		return strings.TrimRight(s, "\n")
+	}
+	return strings.Join(lines[len(lines)-n:], "\n")
+}
+
+// templateJoin joins a list with a separator. It accepts both the strings.Join
+// argument order ({{join .items ", "}}) and the pipeline order ({{glob "*.go" | join ", "}}).
+func templateJoin(a, b interface{}) (string, error) {
+	if sep, ok := b.(string); ok {
+		if items, ok := stringList(a); ok {
+			return strings.Join(items, sep), nil
+		}
+	}
+	if sep, ok := a.(string); ok {
+		if items, ok := stringList(b); ok {
+			return strings.Join(items, sep), nil
+		}
+	}
+	return "", fmt.Errorf("join expects a list and a separator")
+}
+
+func stringList(v interface{}) ([]string, bool) {
+	switch items := v.(type) {
+	case []string:
+		return items, true
+	case []interface{}:
+		result := make([]string, len(items))
+		for i, item := range items {
+			result[i] = fmt.Sprintf("%v", item)
+		}
+		return result, true
+	}
+	return nil, false
+}
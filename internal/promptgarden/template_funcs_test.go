@@ -0,0 +1,161 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chdirTemp switches into a fresh directory for the duration of the test
+func chdirTemp(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestTemplateFuncs_File(t *testing.T) {
+	dir := chdirTemp(t)
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "one\ntwo\nthree\nfour\n")
+
+	engine := NewTemplateEngine()
+
+	result, err := engine.Execute(`{{file "notes.txt" | head 2}}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo", result)
+
+	result, err = engine.Execute(`{{file "notes.txt" | tail 1}}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "four", result)
+
+	_, err = templateFile("../outside.txt")
+	assert.Error(t, err)
+	_, err = templateFile(filepath.Join(filepath.Dir(dir), "outside.txt"))
+	assert.Error(t, err)
+
+	// Secrets can't be read, directly or through a link
+	writeTestFile(t, filepath.Join(dir, ".env"), "TOKEN=secret")
+	writeTestFile(t, filepath.Join(dir, "certs", "server.key"), "secret")
+	for _, path := range []string{".env", "certs/server.key"} {
+		_, err = templateFile(path)
+		assert.Error(t, err, path)
+	}
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Symlink(".env", filepath.Join(dir, "config.txt")))
+		_, err = templateFile("config.txt")
+		assert.Error(t, err)
+
+		// Links can't escape the working directory
+		outside := t.TempDir()
+		writeTestFile(t, filepath.Join(outside, "passwd"), "root")
+		require.NoError(t, os.Symlink(outside, filepath.Join(dir, "escape")))
+		_, err = templateFile("escape/passwd")
+		assert.Error(t, err)
+	}
+}
+
+func TestTemplateFuncs_Glob(t *testing.T) {
+	dir := chdirTemp(t)
+	writeTestFile(t, filepath.Join(dir, "schema.sql"), "")
+	writeTestFile(t, filepath.Join(dir, "db", "migrations", "001.sql"), "")
+	writeTestFile(t, filepath.Join(dir, "db", "README.md"), "")
+	writeTestFile(t, filepath.Join(dir, ".git", "hooks.sql"), "")
+	writeTestFile(t, filepath.Join(dir, "db", ".env"), "")
+	writeTestFile(t, filepath.Join(dir, "db", "server.pem"), "")
+
+	matches, err := templateGlob("**/*.sql")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db/migrations/001.sql", "schema.sql"}, matches)
+
+	matches, err = templateGlob("db/*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db/README.md"}, matches)
+
+	matches, err = templateGlob("missing/**/*.sql")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	engine := NewTemplateEngine()
+	result, err := engine.Execute(`Files: {{glob "**/*.sql" | join ", "}}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Files: db/migrations/001.sql, schema.sql", result)
+
+	_, err = templateGlob("../**/*.sql")
+	assert.Error(t, err)
+}
+
+func TestTemplateFuncs_Join(t *testing.T) {
+	engine := NewTemplateEngine()
+	vars := map[string]interface{}{"items": []string{"a", "b"}}
+
+	result, err := engine.Execute(`{{join .items "-"}} {{.items | join "+"}}`, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "a-b a+b", result)
+}
+
+func TestTemplateFuncs_Shell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses echo from PATH")
+	}
+	t.Cleanup(func() { SetShellPolicy(DefaultShellPolicy()) })
+
+	_, err := templateShell("echo hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disabled")
+
+	SetShellPolicy(ShellPolicy{Enabled: true, Allow: []string{"echo hello"}})
+
+	out, err := templateShell("echo   hello world")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+
+	_, err = templateShell("echo goodbye")
+	assert.Error(t, err)
+	_, err = templateShell("echo helloworld")
+	assert.Error(t, err)
+
+	// Metacharacters are passed as arguments rather than interpreted
+	out, err = templateShell("echo hello ; echo $HOME")
+	require.NoError(t, err)
+	assert.Equal(t, "hello ; echo $HOME", out)
+
+	engine := NewTemplateEngine()
+	result, err := engine.Execute(`Log: {{shell "echo hello there"}}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Log: hello there", result)
+}
+
+func TestHeadTailLines(t *testing.T) {
+	assert.Equal(t, "a\nb", headLines(2, "a\nb\nc\n"))
+	assert.Equal(t, "a\nb\nc\n", headLines(5, "a\nb\nc\n"))
+	assert.Equal(t, "", headLines(0, "a\nb"))
+	assert.Equal(t, "b\nc", tailLines(2, "a\nb\nc\n"))
+	assert.Equal(t, "a\nb\nc", tailLines(5, "a\nb\nc\n"))
+}
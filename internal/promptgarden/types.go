@@ -26,6 +26,8 @@ type Prompt struct {
 	Name     string         `json:"name"`
 	Content  string         `json:"content"`
 	Metadata PromptMetadata `json:"metadata"`
+	// Variants are alternative bodies runs pick from
+	Variants []core.PromptVariant `json:"variants,omitempty"`
 }
 
 // PromptMetadata contains metadata about a prompt
@@ -35,6 +37,7 @@ type PromptMetadata struct {
 	Version     string   `json:"version,omitempty"`
 	Description string   `json:"description,omitempty"`
 	Author      string   `json:"author,omitempty"`
+	VariantMode string   `json:"variant_mode,omitempty"`
 }
 
 // ListPrompts is a helper method for Garden
@@ -58,7 +61,9 @@ func (g *Garden) ListPrompts() ([]*Prompt, error) {
 				Version:     metadata.Version,
 				Description: metadata.Description,
 				Author:      metadata.Author,
+				VariantMode: metadata.VariantMode,
 			},
+			Variants: metadata.Variants,
 		}
 	}
 
@@ -77,10 +82,16 @@ func (g *Garden) SavePrompt(prompt *Prompt) error {
 		Tags:        prompt.Metadata.Tags,
 		Author:      prompt.Metadata.Author,
 		Version:     prompt.Metadata.Version,
+		Variants:    prompt.Variants,
+		VariantMode: prompt.Metadata.VariantMode,
 	}
 
 	corePrompt := NewTemplatePrompt(metadata, prompt.Content)
 
+	if err := validateVariants(metadata); err != nil {
+		return err
+	}
+
 	// Try to update first if it exists
 	if _, err := g.Get(prompt.ID); err == nil {
 		// Prompt exists, update it
@@ -111,7 +122,9 @@ func (g *Garden) GetPrompt(id string) (*Prompt, error) {
 			Version:     metadata.Version,
 			Description: metadata.Description,
 			Author:      metadata.Author,
+			VariantMode: metadata.VariantMode,
 		},
+		Variants: metadata.Variants,
 	}, nil
 }
 
@@ -0,0 +1,140 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// UsageFile is the file in the garden that records every use of a prompt
+const UsageFile = "usage.jsonl"
+
+// Ways a prompt gets used
+const (
+	UsageExecute  = "execute"  // Rendered with Execute, e.g. by prompt exec, map, MCP prompt_ tools and slash commands
+	UsageWorkflow = "workflow" // Referenced by a running workflow with promptgarden://
+)
+
+// usageRecord is one use of a prompt
+type usageRecord struct {
+	Time   time.Time `json:"time"`
+	Prompt string    `json:"prompt"`
+	Source string    `json:"source"`
+}
+
+// PromptUsage is how often and how recently a prompt was used
+type PromptUsage struct {
+	Prompt   string         `json:"prompt"`
+	Count    int            `json:"count"`
+	LastUsed time.Time      `json:"last_used"`
+	Sources  map[string]int `json:"sources"` // Uses by source, see UsageExecute
+}
+
+// RecordUsage counts a use of a prompt. Uses are appended to a log so
+// processes using the garden at the same time don't overwrite each other.
+func (g *Garden) RecordUsage(prompt, source string) error {
+	data, err := json.Marshal(usageRecord{Time: time.Now(), Prompt: prompt, Source: source})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(g.storePath, UsageFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open prompt usage: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Usage returns the recorded use of each prompt, by name. Prompts that were
+// never used are missing.
+func (g *Garden) Usage() (map[string]PromptUsage, error) {
+	usage := make(map[string]PromptUsage)
+	f, err := os.Open(filepath.Join(g.storePath, UsageFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return usage, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record usageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // Skip lines cut short by a crash
+		}
+		stats := usage[record.Prompt]
+		stats.Prompt = record.Prompt
+		stats.Count++
+		if record.Time.After(stats.LastUsed) {
+			stats.LastUsed = record.Time
+		}
+		if stats.Sources == nil {
+			stats.Sources = make(map[string]int)
+		}
+		stats.Sources[record.Source]++
+		usage[record.Prompt] = stats
+	}
+	return usage, scanner.Err()
+}
+
+// UnusedPrompt is a prune suggestion: a prompt not used for a while
+type UnusedPrompt struct {
+	Summary
+	LastUsed time.Time `json:"last_used,omitempty"` // Zero when it was never used
+	// Modified is when the prompt was last added or changed
+	Modified time.Time `json:"modified"`
+}
+
+// UnusedSince lists the prompts neither used nor changed since cutoff,
+// least recently used first. Built-in prompts are left out.
+func (g *Garden) UnusedSince(cutoff time.Time) ([]UnusedPrompt, error) {
+	usage, err := g.Usage()
+	if err != nil {
+		return nil, err
+	}
+	var unused []UnusedPrompt
+	for _, summary := range g.Summaries() {
+		if IsBuiltin(summary.Name) {
+			continue
+		}
+		lastUsed := usage[summary.Name].LastUsed
+		if lastUsed.After(cutoff) {
+			continue
+		}
+		modified, err := g.store.ModTime(summary.ID)
+		if err != nil || modified.After(cutoff) {
+			continue
+		}
+		unused = append(unused, UnusedPrompt{Summary: summary, LastUsed: lastUsed, Modified: modified})
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		if !unused[i].LastUsed.Equal(unused[j].LastUsed) {
+			return unused[i].LastUsed.Before(unused[j].LastUsed)
+		}
+		return unused[i].Name < unused[j].Name
+	})
+	return unused, nil
+}
@@ -0,0 +1,65 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptUsage(t *testing.T) {
+	dir := t.TempDir()
+	garden, err := NewGarden(dir)
+	require.NoError(t, err)
+	savePrompts(t, garden, map[string]string{"greet": "Hello {{name}}!", "stale": "Old prompt", "fresh": "New prompt"})
+
+	_, err = garden.Execute("greet", map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	_, err = garden.ExecuteVariant("greet", nil)
+	require.NoError(t, err)
+	require.NoError(t, garden.RecordUsage("stale", UsageWorkflow))
+	_, err = garden.Execute("missing", nil)
+	require.Error(t, err)
+
+	usage, err := garden.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage["greet"].Count)
+	assert.Equal(t, map[string]int{UsageExecute: 2}, usage["greet"].Sources)
+	assert.WithinDuration(t, time.Now(), usage["greet"].LastUsed, time.Minute)
+	assert.Equal(t, 1, usage["stale"].Count)
+	assert.NotContains(t, usage, "missing")
+
+	// Prompts used or changed since the cutoff aren't suggested, nor are built-ins
+	old := time.Now().Add(-200 * 24 * time.Hour)
+	for _, name := range []string{"greet", "stale"} {
+		prompt, err := garden.GetByName(name)
+		require.NoError(t, err)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, prompt.ID()+".json"), old, old))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, UsageFile), []byte(`{"time":"2020-01-01T00:00:00Z","prompt":"stale","source":"workflow"}`+"\n"+`{"time":"`+time.Now().Format(time.RFC3339)+`","prompt":"greet","source":"execute"}`+"\n"), 0644))
+
+	unused, err := garden.UnusedSince(time.Now().Add(-90 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, unused, 1)
+	assert.Equal(t, "stale", unused[0].Name)
+	assert.Equal(t, 2020, unused[0].LastUsed.Year())
+}
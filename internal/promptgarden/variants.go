@@ -0,0 +1,229 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Variant selection modes
+const (
+	VariantRandom     = "random"
+	VariantRoundRobin = "round_robin"
+)
+
+// DefaultVariant names the body of a prompt that has no variants, and the
+// text before the first variant marker of an imported markdown prompt
+const DefaultVariant = "default"
+
+// variantStateFile keeps the round-robin position of each prompt
+const variantStateFile = "variants.json"
+
+// variantMarker starts a variant in an imported markdown prompt
+var variantMarker = regexp.MustCompile(`(?m)^<!--\s*variant:\s*([\w.-]+)\s*-->[ \t]*\n?`)
+
+// Rendered is an executed prompt and the variant it was rendered from
+type Rendered struct {
+	Text string
+	// Variant is empty for prompts without variants
+	Variant string
+}
+
+// PromptBody is the content of a prompt file and the variants it defines
+type PromptBody struct {
+	Content     string
+	Variants    []core.PromptVariant
+	VariantMode string
+}
+
+// ParsePromptBody reads a prompt file. YAML files define content, variants
+// and variant_mode as fields; other files are plain text, split into
+// variants by <!-- variant: name --> markers. The first variant doubles as
+// the content when there's none of its own.
+func ParsePromptBody(path string, data []byte) (PromptBody, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" {
+		body := PromptBody{Content: string(data), Variants: splitVariants(string(data))}
+		if len(body.Variants) > 0 {
+			body.Content = body.Variants[0].Content
+		}
+		return body, nil
+	}
+
+	var file struct {
+		Content     string `yaml:"content"`
+		VariantMode string `yaml:"variant_mode"`
+		Variants    []struct {
+			Name    string `yaml:"name"`
+			Content string `yaml:"content"`
+		} `yaml:"variants"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return PromptBody{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	body := PromptBody{Content: file.Content, VariantMode: file.VariantMode}
+	for _, v := range file.Variants {
+		body.Variants = append(body.Variants, core.PromptVariant{Name: v.Name, Content: v.Content})
+	}
+	if body.Content == "" && len(body.Variants) > 0 {
+		body.Content = body.Variants[0].Content
+	}
+	if body.Content == "" {
+		return PromptBody{}, fmt.Errorf("%s has neither content nor variants", path)
+	}
+	return body, nil
+}
+
+// ExecuteVariant executes a prompt like Execute, picking one of its variants
+// when it has any
+func (g *Garden) ExecuteVariant(nameOrID string, vars map[string]interface{}) (Rendered, error) {
+	prompt, err := g.GetByName(nameOrID)
+	if err != nil {
+		prompt, err = g.Get(nameOrID)
+		if err != nil {
+			return Rendered{}, fmt.Errorf("prompt not found: %s", nameOrID)
+		}
+	}
+	// Usage is best effort; a read-only garden still runs its prompts
+	_ = g.RecordUsage(prompt.Name(), UsageExecute)
+
+	metadata := prompt.Metadata()
+	if len(metadata.Variants) == 0 {
+		prompt.SetIncludeResolver(g)
+		text, err := prompt.Template(vars)
+		return Rendered{Text: text}, err
+	}
+
+	variant, err := g.pickVariant(metadata)
+	if err != nil {
+		return Rendered{}, err
+	}
+	body := variantPrompt(metadata, variant)
+	body.SetIncludeResolver(g)
+	text, err := body.Template(vars)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("variant %s: %w", variant.Name, err)
+	}
+	return Rendered{Text: text, Variant: variant.Name}, nil
+}
+
+// pickVariant chooses the variant of a prompt to run
+func (g *Garden) pickVariant(metadata core.PromptMetadata) (core.PromptVariant, error) {
+	variants := metadata.Variants
+	switch metadata.VariantMode {
+	case "", VariantRandom:
+		return variants[rand.Intn(len(variants))], nil
+	case VariantRoundRobin:
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		// The position is kept on disk so separate runs take turns
+		path := filepath.Join(g.storePath, variantStateFile)
+		state := make(map[string]int)
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, &state)
+		}
+		next := state[metadata.Name] % len(variants)
+		state[metadata.Name] = next + 1
+		data, err := json.MarshalIndent(state, "", "  ")
+		if err != nil {
+			return core.PromptVariant{}, err
+		}
+		if err := utils.WriteFile(path, data); err != nil {
+			return core.PromptVariant{}, fmt.Errorf("failed to save variant state: %w", err)
+		}
+		return variants[next], nil
+	default:
+		return core.PromptVariant{}, fmt.Errorf("prompt %s: unknown variant_mode %q (use %s or %s)", metadata.Name, metadata.VariantMode, VariantRandom, VariantRoundRobin)
+	}
+}
+
+// variantPrompt builds a template prompt from one variant's body. Variables
+// are extracted from the body, keeping the prompt's own definitions (and
+// defaults) for the ones it declares.
+func variantPrompt(metadata core.PromptMetadata, variant core.PromptVariant) *TemplatePrompt {
+	declared := make(map[string]core.PromptVariable, len(metadata.Variables))
+	for _, v := range metadata.Variables {
+		declared[v.Name] = v
+	}
+	variables := extractVariables(variant.Content)
+	for i, v := range variables {
+		if d, ok := declared[v.Name]; ok {
+			variables[i] = d
+		}
+	}
+
+	metadata.Variables = variables
+	metadata.Variants = nil
+	return NewTemplatePrompt(metadata, variant.Content)
+}
+
+// splitVariants splits a markdown body on <!-- variant: name --> markers.
+// Text before the first marker becomes the default variant when it isn't
+// blank; a body without markers has no variants.
+func splitVariants(content string) []core.PromptVariant {
+	markers := variantMarker.FindAllStringSubmatchIndex(content, -1)
+	if len(markers) == 0 {
+		return nil
+	}
+
+	var variants []core.PromptVariant
+	if lead := strings.TrimSpace(content[:markers[0][0]]); lead != "" {
+		variants = append(variants, core.PromptVariant{Name: DefaultVariant, Content: lead})
+	}
+	for i, m := range markers {
+		end := len(content)
+		if i+1 < len(markers) {
+			end = markers[i+1][0]
+		}
+		variants = append(variants, core.PromptVariant{
+			Name:    content[m[2]:m[3]],
+			Content: strings.TrimSpace(content[m[1]:end]),
+		})
+	}
+	return variants
+}
+
+// validateVariants checks that variant names are set and unique
+func validateVariants(metadata core.PromptMetadata) error {
+	seen := make(map[string]bool, len(metadata.Variants))
+	for _, v := range metadata.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("prompt %s: variant name is required", metadata.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("prompt %s: duplicate variant %s", metadata.Name, v.Name)
+		}
+		seen[v.Name] = true
+	}
+	switch metadata.VariantMode {
+	case "", VariantRandom, VariantRoundRobin:
+		return nil
+	default:
+		return fmt.Errorf("prompt %s: unknown variant_mode %q (use %s or %s)", metadata.Name, metadata.VariantMode, VariantRandom, VariantRoundRobin)
+	}
+}
@@ -0,0 +1,160 @@
+package promptgarden
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteVariant(t *testing.T) {
+	garden, err := NewGarden(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, garden.SavePrompt(&Prompt{
+		ID:       "greet",
+		Name:     "greet",
+		Content:  "Hello {{name}}",
+		Metadata: PromptMetadata{VariantMode: VariantRoundRobin},
+		Variants: []core.PromptVariant{
+			{Name: "short", Content: "Hi {{name}}"},
+			{Name: "formal", Content: "Good day, {{name}}."},
+		},
+	}))
+
+	vars := map[string]interface{}{"name": "Ada"}
+	first, err := garden.ExecuteVariant("greet", vars)
+	require.NoError(t, err)
+	assert.Equal(t, Rendered{Text: "Hi Ada", Variant: "short"}, first)
+
+	second, err := garden.ExecuteVariant("greet", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "formal", second.Variant)
+	assert.Equal(t, "Good day, Ada.", second.Text)
+
+	// Round-robin survives reopening the garden
+	reopened, err := NewGarden(garden.storePath)
+	require.NoError(t, err)
+	third, err := reopened.Execute("greet", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Ada", third)
+
+	t.Run("without variants", func(t *testing.T) {
+		require.NoError(t, garden.SavePrompt(&Prompt{ID: "plain", Name: "plain", Content: "Plain {{name}}"}))
+		rendered, err := garden.ExecuteVariant("plain", vars)
+		require.NoError(t, err)
+		assert.Equal(t, Rendered{Text: "Plain Ada"}, rendered)
+	})
+
+	t.Run("duplicate variants are rejected", func(t *testing.T) {
+		err := garden.SavePrompt(&Prompt{
+			ID:       "dup",
+			Name:     "dup",
+			Content:  "a",
+			Variants: []core.PromptVariant{{Name: "a", Content: "a"}, {Name: "a", Content: "b"}},
+		})
+		assert.ErrorContains(t, err, "duplicate variant a")
+	})
+}
+
+func TestParsePromptBody(t *testing.T) {
+	t.Run("markers", func(t *testing.T) {
+		body, err := ParsePromptBody("review.md", []byte("Review this.\n<!-- variant: strict -->\nReview this strictly.\n\n<!-- variant: kind -->\nReview this kindly.\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []core.PromptVariant{
+			{Name: DefaultVariant, Content: "Review this."},
+			{Name: "strict", Content: "Review this strictly."},
+			{Name: "kind", Content: "Review this kindly."},
+		}, body.Variants)
+		assert.Equal(t, "Review this.", body.Content)
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		body, err := ParsePromptBody("review.txt", []byte("Review this."))
+		require.NoError(t, err)
+		assert.Equal(t, "Review this.", body.Content)
+		assert.Empty(t, body.Variants)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		data := []byte(`variant_mode: round_robin
+variants:
+  - name: a
+    content: First {{x}}
+  - name: b
+    content: Second {{x}}
+`)
+		body, err := ParsePromptBody("review.yaml", data)
+		require.NoError(t, err)
+		assert.Equal(t, VariantRoundRobin, body.VariantMode)
+		assert.Len(t, body.Variants, 2)
+		assert.Equal(t, "First {{x}}", body.Content)
+
+		_, err = ParsePromptBody("empty.yaml", []byte("variant_mode: random\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestImportMarkdownVariants(t *testing.T) {
+	garden, err := NewGarden(t.TempDir())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nname: summary\nvariant_mode: round_robin\n---\n<!-- variant: brief -->\nSummarize briefly.\n<!-- variant: detailed -->\nSummarize in detail.\n"), 0644))
+	require.NoError(t, garden.ImportFromFile(path))
+
+	prompt, err := garden.GetByName("summary")
+	require.NoError(t, err)
+	assert.Equal(t, VariantRoundRobin, prompt.Metadata().VariantMode)
+	assert.Len(t, prompt.Metadata().Variants, 2)
+
+	rendered, err := garden.ExecuteVariant("summary", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Rendered{Text: "Summarize briefly.", Variant: "brief"}, rendered)
+}
+
+func TestOutcomeHistory(t *testing.T) {
+	dir := t.TempDir()
+	outcomes := []Outcome{
+		{Prompt: "review", Variant: "a", Success: true, Duration: 2, OutputBytes: 100},
+		{Prompt: "review", Variant: "a", Success: false, Duration: 4, Error: "timeout"},
+		{Prompt: "review", Variant: "b", Success: true, Duration: 1, OutputBytes: 50},
+		{Prompt: "other", Variant: "a", Success: true},
+	}
+	for _, outcome := range outcomes {
+		require.NoError(t, RecordOutcome(dir, outcome))
+	}
+
+	loaded, err := LoadOutcomes(dir, "review")
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+	assert.False(t, loaded[0].Time.IsZero())
+
+	summary := SummarizeOutcomes(loaded)
+	require.Len(t, summary, 2)
+	assert.Equal(t, VariantStats{Variant: "b", Runs: 1, Successes: 1, SuccessRate: 1, AvgDuration: 1, AvgOutput: 50}, summary[0])
+	assert.Equal(t, VariantStats{Variant: "a", Runs: 2, Successes: 1, SuccessRate: 0.5, AvgDuration: 3, AvgOutput: 50}, summary[1])
+
+	missing, err := LoadOutcomes(t.TempDir(), "review")
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
@@ -0,0 +1,164 @@
+package provenance
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the provenance registry's file name in the Opun directory
+const File = "provenance.yaml"
+
+// Sources items are installed from
+const (
+	SourceRemote   = "remote"
+	SourceManifest = "manifest"
+	SourceURL      = "url"
+)
+
+// ForkPrefix starts the name of a personal copy of a managed item
+const ForkPrefix = "my-"
+
+// Item records where an installed item came from. Items without a record
+// are the user's own.
+type Item struct {
+	// Kind is prompt, workflow or action
+	Kind   string `yaml:"kind" json:"kind"`
+	Name   string `yaml:"name" json:"name"`
+	Source string `yaml:"source" json:"source"`
+	// Origin is the remote and namespace, e.g. origin/backend, the manifest URL
+	// or the URL a prompt was imported from
+	Origin string `yaml:"origin" json:"origin"`
+	// ReadOnly items should only change through their source
+	ReadOnly bool `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+	// Digest is the SHA-256 of the content fetched from a URL, to tell when it changed
+	Digest      string    `yaml:"digest,omitempty" json:"digest,omitempty"`
+	InstalledAt time.Time `yaml:"installed_at" json:"installed_at"`
+}
+
+// Path returns the provenance registry, ~/.opun/provenance.yaml
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", File), nil
+}
+
+// Load reads the provenance registry, sorted by kind and name. A missing
+// file is an empty registry.
+func Load(path string) ([]Item, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// Mark records the source of items, replacing earlier records of them
+func Mark(path string, marked ...Item) error {
+	items, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range marked {
+		if item.InstalledAt.IsZero() {
+			item.InstalledAt = time.Now()
+		}
+		replaced := false
+		for i := range items {
+			if items[i].Kind == item.Kind && items[i].Name == item.Name {
+				items[i] = item
+				replaced = true
+			}
+		}
+		if !replaced {
+			items = append(items, item)
+		}
+	}
+	return write(path, items)
+}
+
+// Unmark forgets where an item came from, making it the user's own
+func Unmark(path, kind, name string) error {
+	items, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if item.Kind != kind || item.Name != name {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(items) {
+		return nil
+	}
+	return write(path, kept)
+}
+
+// Find returns the record of an item, nil for the user's own items
+func Find(path, kind, name string) (*Item, error) {
+	items, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Kind == kind && items[i].Name == name {
+			return &items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Describe says where a managed item comes from, e.g. "remote origin/backend"
+func (i Item) Describe() string {
+	return i.Source + " " + i.Origin
+}
+
+func write(path string, items []Item) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,59 @@
+package provenance
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+
+	item, err := Find(path, "workflow", "review")
+	require.NoError(t, err)
+	assert.Nil(t, item, "a missing registry has no managed items")
+
+	require.NoError(t, Mark(path,
+		Item{Kind: "workflow", Name: "review", Source: SourceRemote, Origin: "origin/team", ReadOnly: true},
+		Item{Kind: "prompt", Name: "review", Source: SourceManifest, Origin: "https://example.com/plugin.yaml"},
+	))
+
+	item, err = Find(path, "workflow", "review")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.True(t, item.ReadOnly)
+	assert.False(t, item.InstalledAt.IsZero())
+	assert.Equal(t, "remote origin/team", item.Describe())
+
+	// Marking again replaces the record
+	require.NoError(t, Mark(path, Item{Kind: "workflow", Name: "review", Source: SourceRemote, Origin: "origin/team"}))
+	items, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "prompt", items[0].Kind)
+	assert.False(t, items[1].ReadOnly)
+
+	require.NoError(t, Unmark(path, "workflow", "review"))
+	item, err = Find(path, "workflow", "review")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+	require.NoError(t, Unmark(path, "workflow", "missing"))
+}
@@ -0,0 +1,260 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// authProbeTimeout bounds each probe command so a hung CLI can't stall preflight
+const authProbeTimeout = 15 * time.Second
+
+// AuthProbe describes how to check whether a provider CLI is logged in
+type AuthProbe struct {
+	// VersionArgs verify the CLI is installed and runs at all
+	VersionArgs []string
+	// StatusArgs ask the CLI for its login state; exit code 0 means logged in.
+	// Any other result is inconclusive, since status commands are unreliable.
+	StatusArgs []string
+	// EnvVars are API key variables that authenticate without a login session
+	EnvVars []string
+	// CredentialFiles are paths relative to the home directory that hold a login session.
+	// They are consulted when the status command doesn't confirm a login.
+	CredentialFiles []string
+	// LoginHint tells the user how to log in
+	LoginHint string
+}
+
+// authProbes are the built-in probes for supported providers
+var authProbes = map[string]AuthProbe{
+	"claude": {
+		VersionArgs:     []string{"--version"},
+		StatusArgs:      []string{"auth", "status"},
+		EnvVars:         []string{"ANTHROPIC_API_KEY"},
+		CredentialFiles: []string{filepath.Join(".claude", ".credentials.json")},
+		LoginHint:       "run 'claude' and use /login, or set ANTHROPIC_API_KEY",
+	},
+	"gemini": {
+		VersionArgs:     []string{"--version"},
+		StatusArgs:      []string{"auth", "print"},
+		EnvVars:         []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"},
+		CredentialFiles: []string{filepath.Join(".gemini", "oauth_creds.json")},
+		LoginHint:       "run 'gemini' and sign in, or set GEMINI_API_KEY",
+	},
+	"qwen": {
+		VersionArgs:     []string{"--version"},
+		EnvVars:         []string{"OPENAI_API_KEY", "DASHSCOPE_API_KEY"},
+		CredentialFiles: []string{filepath.Join(".qwen", "oauth_creds.json")},
+		LoginHint:       "run 'qwen' and sign in, or set OPENAI_API_KEY",
+	},
+}
+
+// AuthStatus is the result of probing a provider's login state
+type AuthStatus struct {
+	Provider      string
+	Installed     bool
+	Authenticated bool
+	// Unverified is set when no probe confirmed a login. The session may
+	// still work, e.g. with credentials kept in the macOS Keychain, so the
+	// provider is used with a warning.
+	Unverified bool
+	// Method describes how the provider is authenticated (env var, session, ...)
+	Method string
+	// Reason explains why the provider is not ready or its login is unverified
+	Reason    string
+	LoginHint string
+}
+
+// Ready reports whether the provider can be used
+func (s AuthStatus) Ready() bool {
+	return s.Installed && (s.Authenticated || s.Unverified)
+}
+
+// commandRunner runs a command and returns its combined output
+type commandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+// AuthChecker probes provider CLIs for their login state
+type AuthChecker struct {
+	run       commandRunner
+	lookupEnv func(string) (string, bool)
+	lookupCmd func(provider string) ([]string, error)
+	homeDir   string
+	probes    map[string]AuthProbe
+}
+
+// NewAuthChecker creates an auth checker using the real provider CLIs
+func NewAuthChecker() *AuthChecker {
+	homeDir, _ := os.UserHomeDir()
+	return &AuthChecker{
+		run:       runCommand,
+		lookupEnv: os.LookupEnv,
+		lookupCmd: ResolveCommand,
+		homeDir:   homeDir,
+		probes:    authProbes,
+	}
+}
+
+// Check probes a single provider
+func (c *AuthChecker) Check(ctx context.Context, provider string) AuthStatus {
+	status := AuthStatus{Provider: provider}
+
+	// The mock provider never needs a login
+	if provider == "mock" {
+		status.Installed = true
+		status.Authenticated = true
+		status.Method = "none"
+		return status
+	}
+
+	probe, ok := c.probes[provider]
+	if !ok {
+		status.Reason = fmt.Sprintf("unsupported provider: %s", provider)
+		return status
+	}
+	status.LoginHint = probe.LoginHint
+
+	argv, err := c.lookupCmd(provider)
+	if err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+	// The command may be a wrapper such as "npx claude-code"
+	command := strings.Join(argv, " ")
+	name, baseArgs := argv[0], argv[1:]
+
+	if _, err := c.run(ctx, name, append(baseArgs, probe.VersionArgs...)...); err != nil {
+		status.Reason = fmt.Sprintf("%s is installed but failed to run: %v", command, err)
+		return status
+	}
+	status.Installed = true
+
+	for _, env := range probe.EnvVars {
+		if value, ok := c.lookupEnv(env); ok && strings.TrimSpace(value) != "" {
+			status.Authenticated = true
+			status.Method = "$" + env
+			return status
+		}
+	}
+
+	// A failed status command is inconclusive: older CLIs don't have one and
+	// others report errors for working sessions, so check credential files too
+	reason := "no login session or saved credentials found"
+	if len(probe.StatusArgs) > 0 {
+		output, err := c.run(ctx, name, append(baseArgs, probe.StatusArgs...)...)
+		if err == nil {
+			status.Authenticated = true
+			status.Method = "login session"
+			return status
+		}
+		if line := firstLine(output); line != "" && !isUnknownCommandOutput(output) {
+			reason = line
+		}
+	}
+
+	for _, file := range probe.CredentialFiles {
+		if _, err := os.Stat(filepath.Join(c.homeDir, file)); err == nil {
+			status.Authenticated = true
+			status.Method = "saved credentials"
+			return status
+		}
+	}
+
+	// Credentials may live where Opun can't see them, such as the Keychain
+	status.Unverified = true
+	status.Reason = reason
+	return status
+}
+
+// CheckAll probes each provider once, in order
+func (c *AuthChecker) CheckAll(ctx context.Context, providers []string) []AuthStatus {
+	var statuses []AuthStatus
+	seen := make(map[string]bool)
+	for _, provider := range providers {
+		if provider == "" || seen[provider] {
+			continue
+		}
+		seen[provider] = true
+		statuses = append(statuses, c.Check(ctx, provider))
+	}
+	return statuses
+}
+
+// Version returns the version a provider CLI reports, e.g. "1.0.35 (Claude Code)"
+func (c *AuthChecker) Version(ctx context.Context, provider string) (string, error) {
+	provider = strings.ToLower(provider)
+	probe, ok := c.probes[provider]
+	if !ok {
+		return "", fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	argv, err := c.lookupCmd(provider)
+	if err != nil {
+		return "", err
+	}
+	output, err := c.run(ctx, argv[0], append(argv[1:], probe.VersionArgs...)...)
+	if err != nil {
+		return "", fmt.Errorf("%s failed to report its version: %v", strings.Join(argv, " "), err)
+	}
+	return firstLine(output), nil
+}
+
+// runCommand runs a probe command with a timeout
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, authProbeTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Never let a probe wait for interactive input
+	cmd.Stdin = nil
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return out.String(), fmt.Errorf("timed out after %s", authProbeTimeout)
+	}
+	return out.String(), err
+}
+
+// isUnknownCommandOutput reports whether a CLI rejected the status subcommand itself
+func isUnknownCommandOutput(output string) bool {
+	lower := strings.ToLower(output)
+	for _, marker := range []string{"unknown command", "unknown argument", "unrecognized", "unknown option", "invalid command"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first non-empty line of output
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
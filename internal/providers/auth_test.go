@@ -0,0 +1,173 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthChecker builds a checker whose commands are answered from outputs,
+// keyed by the joined command line. Missing keys succeed with no output.
+func fakeAuthChecker(t *testing.T, outputs map[string]string, failing map[string]bool, env map[string]string) *AuthChecker {
+	return &AuthChecker{
+		run: func(ctx context.Context, name string, args ...string) (string, error) {
+			line := strings.Join(append([]string{name}, args...), " ")
+			if failing[line] {
+				return outputs[line], errors.New("exit status 1")
+			}
+			return outputs[line], nil
+		},
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		lookupCmd: func(provider string) ([]string, error) {
+			return []string{provider}, nil
+		},
+		homeDir: t.TempDir(),
+		probes:  authProbes,
+	}
+}
+
+func TestAuthChecker_Check(t *testing.T) {
+	t.Run("logged in via status command", func(t *testing.T) {
+		c := fakeAuthChecker(t, nil, nil, nil)
+		status := c.Check(context.Background(), "claude")
+		assert.True(t, status.Ready())
+		assert.Equal(t, "login session", status.Method)
+	})
+
+	t.Run("api key env var", func(t *testing.T) {
+		c := fakeAuthChecker(t, nil, map[string]bool{"gemini auth print": true}, map[string]string{"GEMINI_API_KEY": "key"})
+		status := c.Check(context.Background(), "gemini")
+		assert.True(t, status.Ready())
+		assert.Equal(t, "$GEMINI_API_KEY", status.Method)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		c := fakeAuthChecker(t,
+			map[string]string{"claude auth status": "\nSession expired, please log in\n"},
+			map[string]bool{"claude auth status": true},
+			nil)
+		status := c.Check(context.Background(), "claude")
+		assert.True(t, status.Installed)
+		assert.False(t, status.Authenticated)
+		// Unconfirmed logins warn instead of blocking
+		assert.True(t, status.Unverified)
+		assert.True(t, status.Ready())
+		assert.Equal(t, "Session expired, please log in", status.Reason)
+		assert.NotEmpty(t, status.LoginHint)
+	})
+
+	t.Run("failed status command falls back to credential files", func(t *testing.T) {
+		c := fakeAuthChecker(t,
+			map[string]string{"claude auth status": "Not logged in"},
+			map[string]bool{"claude auth status": true},
+			nil)
+		credFile := filepath.Join(c.homeDir, ".claude", ".credentials.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(credFile), 0755))
+		require.NoError(t, os.WriteFile(credFile, []byte("{}"), 0600))
+
+		status := c.Check(context.Background(), "claude")
+		assert.True(t, status.Authenticated)
+		assert.False(t, status.Unverified)
+		assert.Equal(t, "saved credentials", status.Method)
+	})
+
+	t.Run("falls back to credential files without status command", func(t *testing.T) {
+		c := fakeAuthChecker(t,
+			map[string]string{"gemini auth print": "Unknown argument: auth"},
+			map[string]bool{"gemini auth print": true},
+			nil)
+		status := c.Check(context.Background(), "gemini")
+		assert.False(t, status.Authenticated)
+		assert.True(t, status.Unverified)
+		assert.Equal(t, "no login session or saved credentials found", status.Reason)
+
+		credFile := filepath.Join(c.homeDir, ".gemini", "oauth_creds.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(credFile), 0755))
+		require.NoError(t, os.WriteFile(credFile, []byte("{}"), 0600))
+
+		status = c.Check(context.Background(), "gemini")
+		assert.True(t, status.Authenticated)
+		assert.Equal(t, "saved credentials", status.Method)
+	})
+
+	t.Run("broken install", func(t *testing.T) {
+		c := fakeAuthChecker(t, nil, map[string]bool{"claude --version": true}, nil)
+		status := c.Check(context.Background(), "claude")
+		assert.False(t, status.Installed)
+		assert.Contains(t, status.Reason, "failed to run")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		c := fakeAuthChecker(t, nil, nil, nil)
+		c.lookupCmd = func(provider string) ([]string, error) {
+			return nil, errors.New("claude command not found")
+		}
+		status := c.Check(context.Background(), "claude")
+		assert.False(t, status.Installed)
+		assert.Equal(t, "claude command not found", status.Reason)
+	})
+
+	t.Run("npx wrapper", func(t *testing.T) {
+		c := fakeAuthChecker(t, nil, map[string]bool{"npx claude-code auth status": true}, nil)
+		c.lookupCmd = func(provider string) ([]string, error) {
+			return []string{"npx", "claude-code"}, nil
+		}
+		status := c.Check(context.Background(), "claude")
+		assert.True(t, status.Installed)
+		assert.False(t, status.Authenticated)
+		assert.True(t, status.Unverified)
+	})
+
+	t.Run("mock and unsupported providers", func(t *testing.T) {
+		c := fakeAuthChecker(t, nil, nil, nil)
+		assert.True(t, c.Check(context.Background(), "mock").Ready())
+		assert.Contains(t, c.Check(context.Background(), "unknown").Reason, "unsupported provider")
+	})
+}
+
+func TestAuthChecker_CheckAllDeduplicates(t *testing.T) {
+	c := fakeAuthChecker(t, nil, nil, nil)
+	statuses := c.CheckAll(context.Background(), []string{"claude", "", "gemini", "claude"})
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "claude", statuses[0].Provider)
+	assert.Equal(t, "gemini", statuses[1].Provider)
+}
+
+func TestAuthChecker_Version(t *testing.T) {
+	c := fakeAuthChecker(t, map[string]string{"claude --version": "\n1.0.35 (Claude Code)\n"}, map[string]bool{"gemini --version": true}, nil)
+
+	version, err := c.Version(context.Background(), "Claude")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.35 (Claude Code)", version)
+
+	_, err = c.Version(context.Background(), "gemini")
+	assert.Error(t, err)
+
+	_, err = c.Version(context.Background(), "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Binary configures how a provider CLI is started, for machines where it
+// isn't on PATH under its own name
+type Binary struct {
+	// Path is the provider executable, absolute or looked up in PATH
+	Path string `mapstructure:"path" yaml:"path,omitempty"`
+	// Wrapper is a command the provider is run through, e.g.
+	// [mise, exec, --] or [devcontainer, exec, --workspace-folder, .]
+	Wrapper []string `mapstructure:"wrapper" yaml:"wrapper,omitempty"`
+}
+
+// IsZero reports whether the binary changes nothing
+func (b Binary) IsZero() bool {
+	return b.Path == "" && len(b.Wrapper) == 0
+}
+
+// Validate checks a configured binary can be resolved
+func (b Binary) Validate() error {
+	if len(b.Wrapper) > 0 && strings.TrimSpace(b.Wrapper[0]) == "" {
+		return fmt.Errorf("wrapper has no command")
+	}
+	return nil
+}
+
+// defaultCommands are the command lines tried in order for a provider with
+// no configured binary
+var defaultCommands = map[string][][]string{
+	"claude": {{"claude"}, {"npx", "claude-code"}},
+	"gemini": {{"gemini"}},
+	"qwen":   {{"qwen"}},
+}
+
+// notFoundHints are the errors returned when none of a provider's default
+// commands is installed
+var notFoundHints = map[string]string{
+	"claude": "claude command not found, please install Claude CLI",
+	"gemini": "gemini command not found, please install Gemini CLI",
+	"qwen":   "qwen command not found, please install Qwen Code CLI",
+}
+
+var (
+	binariesMu sync.Mutex
+	binaries   = make(map[string]Binary)
+	// resolved caches command lines, as a provider is only looked up the
+	// first time it is started
+	resolved = make(map[string][]string)
+	// lookPath finds an executable; swapped in tests
+	lookPath = exec.LookPath
+)
+
+// SetBinary configures how a provider is started, e.g. with one from the
+// providers section of the config
+func SetBinary(provider string, binary Binary) {
+	provider = strings.ToLower(provider)
+	binariesMu.Lock()
+	defer binariesMu.Unlock()
+	binaries[provider] = binary
+	delete(resolved, provider)
+}
+
+// BinaryFor returns the configured binary of a provider
+func BinaryFor(provider string) Binary {
+	binariesMu.Lock()
+	defer binariesMu.Unlock()
+	return binaries[strings.ToLower(provider)]
+}
+
+// ResolveCommand returns the command line that starts a provider CLI: its
+// configured path and wrapper, else the first default command installed.
+// It is looked up on first use and remembered after that.
+func ResolveCommand(provider string) ([]string, error) {
+	provider = strings.ToLower(provider)
+	binariesMu.Lock()
+	defer binariesMu.Unlock()
+
+	if argv, ok := resolved[provider]; ok {
+		return append([]string{}, argv...), nil
+	}
+	argv, err := resolveCommand(provider, binaries[provider])
+	if err != nil {
+		return nil, err
+	}
+	resolved[provider] = argv
+	return append([]string{}, argv...), nil
+}
+
+// resolveCommand looks up the command line for a provider
+func resolveCommand(provider string, binary Binary) ([]string, error) {
+	candidates, known := defaultCommands[provider]
+	if !known {
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	if !binary.IsZero() {
+		path := provider
+		if binary.Path != "" {
+			path = expandBinaryPath(binary.Path)
+		}
+		// The wrapper finds the provider in the environment it sets up,
+		// so only the wrapper has to be on this machine's PATH
+		if len(binary.Wrapper) > 0 {
+			if _, err := lookPath(binary.Wrapper[0]); err != nil {
+				return nil, fmt.Errorf("%s wrapper %s not found: %w", provider, binary.Wrapper[0], err)
+			}
+			return append(append([]string{}, binary.Wrapper...), path), nil
+		}
+		if _, err := lookPath(path); err != nil {
+			return nil, fmt.Errorf("%s binary %s not found: %w", provider, path, err)
+		}
+		return []string{path}, nil
+	}
+
+	for _, argv := range candidates {
+		if _, err := lookPath(argv[0]); err == nil {
+			return append([]string{}, argv...), nil
+		}
+	}
+	return nil, errors.New(notFoundHints[provider])
+}
+
+// expandBinaryPath expands ~ and environment variables in a configured path
+func expandBinaryPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	return path
+}
@@ -0,0 +1,115 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLookPath makes only the given executables findable for a test
+func fakeLookPath(t *testing.T, found ...string) *[]string {
+	var looked []string
+	previous := lookPath
+	lookPath = func(name string) (string, error) {
+		looked = append(looked, name)
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() {
+		lookPath = previous
+		binariesMu.Lock()
+		binaries = make(map[string]Binary)
+		resolved = make(map[string][]string)
+		binariesMu.Unlock()
+	})
+	return &looked
+}
+
+func TestResolveCommand(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		fakeLookPath(t, "gemini", "npx")
+		argv, err := ResolveCommand("gemini")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gemini"}, argv)
+
+		argv, err = ResolveCommand("claude")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"npx", "claude-code"}, argv)
+
+		_, err = ResolveCommand("qwen")
+		assert.EqualError(t, err, "qwen command not found, please install Qwen Code CLI")
+		_, err = ResolveCommand("mock")
+		assert.EqualError(t, err, "unsupported provider: mock")
+	})
+
+	t.Run("path", func(t *testing.T) {
+		fakeLookPath(t, "/opt/tools/claude")
+		SetBinary("Claude", Binary{Path: "/opt/tools/claude"})
+		argv, err := ResolveCommand("claude")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/opt/tools/claude"}, argv)
+
+		SetBinary("gemini", Binary{Path: "/opt/tools/gemini"})
+		_, err = ResolveCommand("gemini")
+		assert.ErrorContains(t, err, "gemini binary /opt/tools/gemini not found")
+	})
+
+	t.Run("wrapper", func(t *testing.T) {
+		looked := fakeLookPath(t, "mise")
+		SetBinary("claude", Binary{Wrapper: []string{"mise", "exec", "--"}})
+		argv, err := ResolveCommand("claude")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mise", "exec", "--", "claude"}, argv)
+		// Only the wrapper has to be installed here
+		assert.Equal(t, []string{"mise"}, *looked)
+
+		SetBinary("qwen", Binary{Path: "qwen-code", Wrapper: []string{"devcontainer", "exec", "--workspace-folder", "."}})
+		_, err = ResolveCommand("qwen")
+		assert.ErrorContains(t, err, "qwen wrapper devcontainer not found")
+	})
+
+	t.Run("resolved once", func(t *testing.T) {
+		looked := fakeLookPath(t, "gemini")
+		for i := 0; i < 3; i++ {
+			argv, err := ResolveCommand("gemini")
+			require.NoError(t, err)
+			argv[0] = "changed"
+		}
+		assert.Equal(t, []string{"gemini"}, *looked)
+
+		// Configuring the binary again looks it up again
+		SetBinary("gemini", Binary{Wrapper: []string{"gemini"}})
+		argv, err := ResolveCommand("gemini")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gemini", "gemini"}, argv)
+		assert.Len(t, *looked, 2)
+	})
+}
+
+func TestBinaryValidate(t *testing.T) {
+	assert.True(t, Binary{}.IsZero())
+	assert.NoError(t, Binary{Path: "~/bin/claude"}.Validate())
+	assert.Error(t, Binary{Wrapper: []string{" ", "exec"}}.Validate())
+}
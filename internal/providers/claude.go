@@ -37,6 +37,7 @@ type ClaudeProvider struct {
 	clipboard        utils.Clipboard
 	injectionManager *config.InjectionManager
 	environment      *config.ProviderEnvironment
+	releaseSession   func() // unregisters the session directory's shutdown cleanup
 	subAgents        map[string]core.SubAgent
 }
 
@@ -72,9 +73,9 @@ func (p *ClaudeProvider) Validate() error {
 
 // GetPTYCommand returns the command to start Claude
 func (p *ClaudeProvider) GetPTYCommand() (*exec.Cmd, error) {
-	config := p.Config()
+	cfg := p.Config()
 	// #nosec G204 -- executing configured provider command
-	cmd := exec.Command(config.Command, config.Args...)
+	cmd := exec.Command(cfg.Command, config.WithClaudeHooks(string(p.Type()), cfg.Args)...)
 
 	// Apply injected environment if available
 	if p.environment != nil {
@@ -88,11 +89,11 @@ func (p *ClaudeProvider) GetPTYCommand() (*exec.Cmd, error) {
 		}
 	} else {
 		// Fall back to config settings
-		if config.WorkingDir != "" {
-			cmd.Dir = config.WorkingDir
+		if cfg.WorkingDir != "" {
+			cmd.Dir = cfg.WorkingDir
 		}
 		// Set environment variables from config
-		for k, v := range config.Environment {
+		for k, v := range cfg.Environment {
 			cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", k, v))
 		}
 	}
@@ -125,6 +126,7 @@ func (p *ClaudeProvider) PrepareSession(ctx context.Context, sessionID string) e
 	if err := os.MkdirAll(sessionDir, 0755); err != nil {
 		return err
 	}
+	p.releaseSession = utils.RegisterTempDir(sessionDir)
 
 	// Prepare provider environment if injection manager is available
 	if p.injectionManager != nil {
@@ -150,6 +152,10 @@ func (p *ClaudeProvider) CleanupSession(ctx context.Context, sessionID string) e
 	}
 
 	// Clean up session directory
+	if p.releaseSession != nil {
+		p.releaseSession()
+		p.releaseSession = nil
+	}
 	sessionDir := filepath.Join(os.TempDir(), "opun", "sessions", sessionID)
 	return os.RemoveAll(sessionDir)
 }
@@ -375,51 +381,44 @@ func (p *ClaudeProvider) getCommand() (string, []string) {
 	// Add any additional args from config
 	args = append(args, config.Args...)
 
-	// Get the claude command
-	cmd := p.getClaudeCommand()
-
-	// If using npx, add claude-code
-	if cmd == "npx" {
-		args = append([]string{"claude-code"}, args...)
-	}
+	// Get the claude command, which may be a wrapper such as "npx claude-code"
+	argv := p.getClaudeCommand()
 
-	return cmd, args
+	return argv[0], append(argv[1:], args...)
 }
 
 // checkClaudeCLI checks if the Claude CLI is available
 func (p *ClaudeProvider) checkClaudeCLI() error {
-	// Try 'claude' command first
-	if _, err := exec.LookPath("claude"); err == nil {
-		return nil
+	argv, err := ResolveCommand("claude")
+	if err != nil {
+		return err
 	}
 
-	// Try 'npx claude-code' as fallback
-	if _, err := exec.LookPath("npx"); err == nil {
-		// Check if claude-code package is available
+	// The npx fallback only works if the claude-code package is available
+	if len(argv) == 2 && argv[0] == "npx" && argv[1] == "claude-code" && BinaryFor("claude").IsZero() {
 		cmd := exec.Command("npx", "--no-install", "claude-code", "--version")
-		if err := cmd.Run(); err == nil {
-			return nil
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("claude CLI not found in PATH")
 		}
 	}
 
-	return fmt.Errorf("claude CLI not found in PATH")
+	return nil
 }
 
-// getClaudeCommand returns the command to run Claude
-func (p *ClaudeProvider) getClaudeCommand() string {
+// getClaudeCommand returns the command line to run Claude
+func (p *ClaudeProvider) getClaudeCommand() []string {
 	config := p.Config()
 	// Check for override in config
 	if cmd, ok := config.Settings["command"].(string); ok && cmd != "" {
-		return cmd
+		return []string{cmd}
 	}
 
-	// Try 'claude' first
-	if _, err := exec.LookPath("claude"); err == nil {
-		return "claude"
+	if argv, err := ResolveCommand("claude"); err == nil {
+		return argv
 	}
 
 	// Fallback to npx
-	return "npx"
+	return []string{"npx", "claude-code"}
 }
 
 // isInteractiveMode checks if we're in interactive mode
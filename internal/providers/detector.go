@@ -16,41 +16,17 @@ package providers
 // along with this program; if not, write to the Free Software
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
-import (
-	"fmt"
-	"os/exec"
-)
+import "strings"
 
 // Detector detects available provider commands
 type Detector struct{}
 
-// DetectCommand detects the command to use for a provider
+// DetectCommand detects the command to use for a provider, as resolved by
+// ResolveCommand and joined with spaces
 func (d *Detector) DetectCommand(provider string) (string, error) {
-	switch provider {
-	case "claude":
-		// Try claude command first
-		if _, err := exec.LookPath("claude"); err == nil {
-			return "claude", nil
-		}
-		// Fall back to npx claude-code
-		if _, err := exec.LookPath("npx"); err == nil {
-			return "npx claude-code", nil
-		}
-		return "", fmt.Errorf("claude command not found, please install Claude CLI")
-
-	case "gemini":
-		if _, err := exec.LookPath("gemini"); err == nil {
-			return "gemini", nil
-		}
-		return "", fmt.Errorf("gemini command not found, please install Gemini CLI")
-
-	case "qwen":
-		if _, err := exec.LookPath("qwen"); err == nil {
-			return "qwen", nil
-		}
-		return "", fmt.Errorf("qwen command not found, please install Qwen Code CLI")
-
-	default:
-		return "", fmt.Errorf("unsupported provider: %s", provider)
+	argv, err := ResolveCommand(provider)
+	if err != nil {
+		return "", err
 	}
+	return strings.Join(argv, " "), nil
 }
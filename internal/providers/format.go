@@ -0,0 +1,185 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// File reference styles of a format profile
+const (
+	// FileRefsAt keeps @path references as they are
+	FileRefsAt = "at"
+	// FileRefsRelative writes @path relative to the working directory, as
+	// Gemini resolves references within its workspace
+	FileRefsRelative = "relative"
+	// FileRefsPath drops the @, for providers that don't read referenced files
+	FileRefsPath = "path"
+)
+
+// FormatProfile is how prompts are rendered for a provider, which renders
+// markdown, code fences and @-references its own way
+type FormatProfile struct {
+	// FileRefs is the style of @path references, FileRefsAt when empty
+	FileRefs string `json:"file_refs,omitempty" yaml:"file_refs,omitempty" mapstructure:"file_refs"`
+	// HeadingOffset shifts markdown headings outside code fences, 1 turning
+	// # into ##; levels stay between 1 and 6
+	HeadingOffset int `json:"heading_offset,omitempty" yaml:"heading_offset,omitempty" mapstructure:"heading_offset"`
+	// EscapeLeading are characters the provider's input treats as commands
+	// at the start of a prompt, such as # for Claude's memory mode. A prompt
+	// starting with one gets a backslash in front.
+	EscapeLeading []string `json:"escape_leading,omitempty" yaml:"escape_leading,omitempty" mapstructure:"escape_leading"`
+}
+
+// Validate checks the profile's file reference style
+func (p FormatProfile) Validate() error {
+	switch p.FileRefs {
+	case "", FileRefsAt, FileRefsRelative, FileRefsPath:
+		return nil
+	}
+	return fmt.Errorf("unknown file_refs %q, use %s, %s or %s", p.FileRefs, FileRefsAt, FileRefsRelative, FileRefsPath)
+}
+
+// defaultFormatProfiles are the built-in profiles of the provider CLIs
+var defaultFormatProfiles = map[string]FormatProfile{
+	// # saves a memory and ! runs a shell command in Claude's input
+	"claude": {FileRefs: FileRefsAt, EscapeLeading: []string{"#", "!"}},
+	// ! toggles shell mode in Gemini's input, and Qwen Code is a fork of it
+	"gemini": {FileRefs: FileRefsRelative, EscapeLeading: []string{"!"}},
+	"qwen":   {FileRefs: FileRefsRelative, EscapeLeading: []string{"!"}},
+}
+
+var (
+	formatProfilesMu sync.RWMutex
+	formatProfiles   = make(map[string]FormatProfile)
+)
+
+// SetFormatProfile replaces a provider's built-in format profile, e.g. with
+// one from the providers section of the config
+func SetFormatProfile(provider string, profile FormatProfile) {
+	formatProfilesMu.Lock()
+	defer formatProfilesMu.Unlock()
+	formatProfiles[strings.ToLower(provider)] = profile
+}
+
+// FormatProfileFor returns the format profile prompts for a provider are
+// rendered with: the configured one, else the built-in one
+func FormatProfileFor(provider string) FormatProfile {
+	provider = strings.ToLower(provider)
+	formatProfilesMu.RLock()
+	profile, ok := formatProfiles[provider]
+	formatProfilesMu.RUnlock()
+	if ok {
+		return profile
+	}
+	return defaultFormatProfiles[provider]
+}
+
+var (
+	// fileRefPattern matches @path references that look like files, not
+	// mentions or email addresses
+	fileRefPattern = regexp.MustCompile(`(^|[\s(])@((?:[~./]|[A-Za-z]:\\)?[\w.~-]*[/.\\][\w~-][^\s)]*)`)
+	headingPattern = regexp.MustCompile(`^(#{1,6})(\s)`)
+)
+
+// Apply renders a prompt with the profile. workDir is what relative
+// references are relative to.
+func (p FormatProfile) Apply(prompt, workDir string) string {
+	if p.FileRefs != "" && p.FileRefs != FileRefsAt {
+		prompt = fileRefPattern.ReplaceAllStringFunc(prompt, func(match string) string {
+			m := fileRefPattern.FindStringSubmatch(match)
+			path := trimRefPath(m[2])
+			return m[1] + p.fileRef(path, workDir) + m[2][len(path):]
+		})
+	}
+	if p.HeadingOffset != 0 {
+		prompt = shiftHeadings(prompt, p.HeadingOffset)
+	}
+	for _, c := range p.EscapeLeading {
+		if c != "" && strings.HasPrefix(prompt, c) {
+			prompt = `\` + prompt
+			break
+		}
+	}
+	return prompt
+}
+
+// FileReferences returns the paths of the @path references in a prompt, in
+// order and without duplicates
+func FileReferences(prompt string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, m := range fileRefPattern.FindAllStringSubmatch(prompt, -1) {
+		path := trimRefPath(m[2])
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// trimRefPath drops punctuation ending a sentence, which isn't part of the path
+func trimRefPath(path string) string {
+	return strings.TrimRight(path, ".,;:!?")
+}
+
+// fileRef writes one reference in the profile's style
+func (p FormatProfile) fileRef(path, workDir string) string {
+	switch p.FileRefs {
+	case FileRefsPath:
+		return path
+	case FileRefsRelative:
+		if workDir != "" && filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+				return "@" + filepath.ToSlash(rel)
+			}
+		}
+	}
+	return "@" + path
+}
+
+// shiftHeadings moves markdown headings outside code fences by offset levels
+func shiftHeadings(prompt string, offset int) string {
+	lines := strings.Split(prompt, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		m := headingPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		level := len(m[1]) + offset
+		if level < 1 {
+			level = 1
+		} else if level > 6 {
+			level = 6
+		}
+		lines[i] = strings.Repeat("#", level) + line[len(m[1]):]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatProfileApply(t *testing.T) {
+	prompt := "# Review\n\nRead @/work/repo/out/plan.md and @/tmp/notes.md, then mail bob@example.com or @alice.\n\n```md\n# kept\n```\n## Steps"
+
+	gemini := FormatProfile{FileRefs: FileRefsRelative, HeadingOffset: 1}
+	assert.Equal(t, "## Review\n\nRead @out/plan.md and @/tmp/notes.md, then mail bob@example.com or @alice.\n\n```md\n# kept\n```\n### Steps",
+		gemini.Apply(prompt, "/work/repo"))
+
+	plain := FormatProfile{FileRefs: FileRefsPath, HeadingOffset: -3}
+	assert.Equal(t, "# Review\n\nRead /work/repo/out/plan.md and /tmp/notes.md, then mail bob@example.com or @alice.\n\n```md\n# kept\n```\n# Steps",
+		plain.Apply(prompt, "/work/repo"))
+
+	claude := FormatProfileFor("claude")
+	assert.Equal(t, `\`+prompt, claude.Apply(prompt, "/work/repo"))
+	assert.Equal(t, "Fix it", claude.Apply("Fix it", ""))
+	assert.Equal(t, "plan", FormatProfile{}.Apply("plan", ""))
+}
+
+func TestFormatProfileFor(t *testing.T) {
+	assert.Equal(t, FileRefsRelative, FormatProfileFor("Gemini").FileRefs)
+	assert.Equal(t, FormatProfile{}, FormatProfileFor("mock"))
+
+	SetFormatProfile("qwen", FormatProfile{FileRefs: FileRefsPath})
+	defer SetFormatProfile("qwen", defaultFormatProfiles["qwen"])
+	assert.Equal(t, FileRefsPath, FormatProfileFor("qwen").FileRefs)
+
+	require.NoError(t, FormatProfile{FileRefs: FileRefsAt}.Validate())
+	assert.Error(t, FormatProfile{FileRefs: "quoted"}.Validate())
+}
+
+func TestFileReferences(t *testing.T) {
+	prompt := "Compare @out/a.md with @/tmp/b.txt, and @out/a.md again. Ask @bob, not bob@example.com."
+	assert.Equal(t, []string{"out/a.md", "/tmp/b.txt"}, FileReferences(prompt))
+	assert.Empty(t, FileReferences("No references here"))
+}
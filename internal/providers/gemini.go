@@ -37,6 +37,7 @@ type GeminiProvider struct {
 	clipboard        utils.Clipboard
 	injectionManager *config.InjectionManager
 	environment      *config.ProviderEnvironment
+	releaseSession   func() // unregisters the session directory's shutdown cleanup
 }
 
 // NewGeminiProvider creates a new Gemini provider
@@ -123,6 +124,7 @@ func (p *GeminiProvider) PrepareSession(ctx context.Context, sessionID string) e
 	if err := os.MkdirAll(sessionDir, 0755); err != nil {
 		return err
 	}
+	p.releaseSession = utils.RegisterTempDir(sessionDir)
 
 	// Prepare provider environment if injection manager is available
 	if p.injectionManager != nil {
@@ -148,6 +150,10 @@ func (p *GeminiProvider) CleanupSession(ctx context.Context, sessionID string) e
 	}
 
 	// Clean up session directory
+	if p.releaseSession != nil {
+		p.releaseSession()
+		p.releaseSession = nil
+	}
 	sessionDir := filepath.Join(os.TempDir(), "opun", "sessions", sessionID)
 	return os.RemoveAll(sessionDir)
 }
@@ -307,21 +313,22 @@ func (p *GeminiProvider) getCommand() (string, []string) {
 	// Add any additional args from config
 	args = append(args, config.Args...)
 
-	// Get the gemini command
-	cmd := p.getGeminiCommand()
+	// Get the gemini command, which may be a wrapper
+	argv := p.getGeminiCommand()
 
-	return cmd, args
+	return argv[0], append(argv[1:], args...)
 }
 
 // checkGeminiCLI checks if the Gemini CLI is available
 func (p *GeminiProvider) checkGeminiCLI() error {
-	// Try 'gemini' command
-	if _, err := exec.LookPath("gemini"); err != nil {
-		return fmt.Errorf("gemini CLI not found in PATH")
+	argv, err := ResolveCommand("gemini")
+	if err != nil {
+		return err
 	}
 
 	// Verify it works
-	cmd := exec.Command("gemini", "--version")
+	// #nosec G204 -- argv is the resolved provider command
+	cmd := exec.Command(argv[0], append(argv[1:], "--version")...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("gemini CLI found but not working: %w", err)
 	}
@@ -329,13 +336,16 @@ func (p *GeminiProvider) checkGeminiCLI() error {
 	return nil
 }
 
-// getGeminiCommand returns the command to run Gemini
-func (p *GeminiProvider) getGeminiCommand() string {
+// getGeminiCommand returns the command line to run Gemini
+func (p *GeminiProvider) getGeminiCommand() []string {
 	config := p.Config()
 	// Check for override in config
 	if cmd, ok := config.Settings["command"].(string); ok && cmd != "" {
-		return cmd
+		return []string{cmd}
 	}
 
-	return "gemini"
+	if argv, err := ResolveCommand("gemini"); err == nil {
+		return argv
+	}
+	return []string{"gemini"}
 }
@@ -0,0 +1,223 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/subagent/gemini"
+)
+
+func init() {
+	gemini.SetHeadlessRunner(runGeminiSubAgent)
+}
+
+// Kinds of headless failures, as the provider reported them
+const (
+	FailureAuth      = "auth"
+	FailureQuota     = "quota"
+	FailureTurnLimit = "turn_limit"
+	FailureTimeout   = "timeout"
+	FailureInput     = "input"
+	FailureConfig    = "config"
+	FailureSandbox   = "sandbox"
+	FailureTool      = "tool"
+	FailureCancelled = "cancelled"
+)
+
+// geminiExitCodes are the exit codes Gemini CLI's fatal errors use
+var geminiExitCodes = map[int]string{
+	41:  FailureAuth,
+	42:  FailureInput,
+	44:  FailureSandbox,
+	52:  FailureConfig,
+	53:  FailureTurnLimit,
+	54:  FailureTool,
+	130: FailureCancelled,
+}
+
+// geminiErrorHints are phrases in Gemini's errors and stderr that tell the
+// kind of failure when the exit code doesn't
+var geminiErrorHints = []struct {
+	phrase string
+	kind   string
+}{
+	{"resource_exhausted", FailureQuota},
+	{"quota", FailureQuota},
+	{"rate limit", FailureQuota},
+	{"429", FailureQuota},
+	{"deadline_exceeded", FailureTimeout},
+	{"timed out", FailureTimeout},
+	{"unauthenticated", FailureAuth},
+	{"permission_denied", FailureAuth},
+	{"api key", FailureAuth},
+	{"login", FailureAuth},
+	{"401", FailureAuth},
+	{"403", FailureAuth},
+}
+
+// HeadlessError is a failed headless provider run, with the kind of
+// failure found from its exit code, error output and stderr
+type HeadlessError struct {
+	Provider string
+	ExitCode int
+	// Kind is one of the Failure kinds, empty when it isn't known
+	Kind    string
+	Message string
+}
+
+func (e *HeadlessError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = fmt.Sprintf("exit status %d", e.ExitCode)
+	}
+	return fmt.Sprintf("%s failed: %s", e.Provider, message)
+}
+
+// geminiOutput is what `gemini -p --output-format json` prints
+type geminiOutput struct {
+	Response *string `json:"response"`
+	Stats    struct {
+		Models map[string]struct {
+			API struct {
+				TotalRequests  int `json:"totalRequests"`
+				TotalLatencyMS int `json:"totalLatencyMs"`
+			} `json:"api"`
+			Tokens struct {
+				Prompt     int `json:"prompt"`
+				Candidates int `json:"candidates"`
+				Total      int `json:"total"`
+				Cached     int `json:"cached"`
+				Thoughts   int `json:"thoughts"`
+			} `json:"tokens"`
+		} `json:"models"`
+	} `json:"stats"`
+	Error *struct {
+		Type    string      `json:"type"`
+		Message string      `json:"message"`
+		Code    interface{} `json:"code"`
+	} `json:"error"`
+}
+
+// ParseGeminiJSON reads Gemini's JSON output: the response is the answer,
+// and the stats give the model and usage. Usage adds up every model Gemini
+// called, and Model is the one that used the most tokens. An error object
+// is returned as a HeadlessError. Output that isn't JSON, such as from a
+// Gemini too old for it, is returned as is.
+func ParseGeminiJSON(data []byte) (HeadlessResult, error) {
+	text := strings.TrimSpace(string(data))
+	var out geminiOutput
+	// Gemini may print warnings before the JSON object
+	if start := strings.Index(text, "{"); start < 0 || json.Unmarshal([]byte(text[start:]), &out) != nil || (out.Response == nil && out.Error == nil) {
+		return HeadlessResult{Output: text}, nil
+	}
+
+	var result HeadlessResult
+	if out.Response != nil {
+		result.Output = strings.TrimSpace(*out.Response)
+	}
+
+	names := make([]string, 0, len(out.Stats.Models))
+	for name := range out.Stats.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	most := -1
+	for _, name := range names {
+		stats := out.Stats.Models[name]
+		if stats.Tokens.Total > most {
+			result.Model, most = name, stats.Tokens.Total
+		}
+		if result.Usage == nil {
+			result.Usage = &Usage{}
+		}
+		// Gemini's prompt tokens include the cached ones
+		result.Usage.Add(Usage{
+			InputTokens:          stats.Tokens.Prompt - stats.Tokens.Cached,
+			CacheReadInputTokens: stats.Tokens.Cached,
+			OutputTokens:         stats.Tokens.Candidates + stats.Tokens.Thoughts,
+			DurationMS:           stats.API.TotalLatencyMS,
+			Turns:                stats.API.TotalRequests,
+		})
+	}
+
+	if out.Error != nil {
+		err := &HeadlessError{Provider: "gemini", Message: out.Error.Message}
+		if code, ok := out.Error.Code.(float64); ok {
+			err.ExitCode = int(code)
+		}
+		err.Kind = geminiFailureKind(err.ExitCode, out.Error.Type+" "+out.Error.Message)
+		if err.Message == "" {
+			err.Message = out.Error.Type
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+// geminiFailureKind finds the kind of a Gemini failure from its exit code,
+// or else from what its error says
+func geminiFailureKind(exitCode int, message string) string {
+	if kind, ok := geminiExitCodes[exitCode]; ok {
+		return kind
+	}
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "authentication"):
+		return FailureAuth
+	case strings.Contains(message, "turnlimit") || strings.Contains(message, "max session turns"):
+		return FailureTurnLimit
+	}
+	for _, hint := range geminiErrorHints {
+		if strings.Contains(message, hint.phrase) {
+			return hint.kind
+		}
+	}
+	return ""
+}
+
+// runGeminiSubAgent runs a Gemini subagent's task headlessly, returning the
+// model, usage and kind of failure Gemini reported as metadata
+func runGeminiSubAgent(ctx context.Context, model, prompt string) (string, map[string]interface{}, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	result, err := RunHeadlessStream(ctx, "gemini", model, prompt, workDir, nil)
+
+	metadata := make(map[string]interface{})
+	if result.Model != "" {
+		metadata["model"] = result.Model
+	}
+	if result.Usage != nil {
+		metadata["usage"] = *result.Usage
+	}
+	var failed *HeadlessError
+	if errors.As(err, &failed) {
+		metadata["exit_code"] = failed.ExitCode
+		if failed.Kind != "" {
+			metadata["failure"] = failed.Kind
+		}
+	}
+	return result.Output, metadata, err
+}
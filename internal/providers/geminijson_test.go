@@ -0,0 +1,90 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const geminiJSON = `{
+  "response": "The function returns early on nil input.\n",
+  "stats": {
+    "models": {
+      "gemini-2.5-pro": {
+        "api": {"totalRequests": 2, "totalErrors": 0, "totalLatencyMs": 5300},
+        "tokens": {"prompt": 1200, "candidates": 80, "total": 1400, "cached": 200, "thoughts": 120, "tool": 0}
+      },
+      "gemini-2.5-flash": {
+        "api": {"totalRequests": 1, "totalErrors": 0, "totalLatencyMs": 700},
+        "tokens": {"prompt": 300, "candidates": 10, "total": 310, "cached": 0, "thoughts": 0, "tool": 0}
+      }
+    },
+    "tools": {"totalCalls": 0},
+    "files": {"totalLinesAdded": 0, "totalLinesRemoved": 0}
+  }
+}`
+
+func TestParseGeminiJSON(t *testing.T) {
+	result, err := ParseGeminiJSON([]byte("Loaded cached credentials.\n" + geminiJSON))
+	require.NoError(t, err)
+	assert.Equal(t, "The function returns early on nil input.", result.Output)
+	assert.Equal(t, "gemini-2.5-pro", result.Model)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, Usage{InputTokens: 1300, CacheReadInputTokens: 200, OutputTokens: 210, DurationMS: 6000, Turns: 3}, *result.Usage)
+
+	// An older Gemini printing plain text
+	result, err = ParseGeminiJSON([]byte("Just text {with braces}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Just text {with braces}", result.Output)
+	assert.Nil(t, result.Usage)
+}
+
+func TestParseGeminiJSON_Error(t *testing.T) {
+	_, err := ParseGeminiJSON([]byte(`{"error": {"type": "FatalAuthenticationError", "message": "Please set an Auth method", "code": 41}}`))
+	var failed *HeadlessError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, FailureAuth, failed.Kind)
+	assert.Equal(t, 41, failed.ExitCode)
+	assert.Equal(t, "gemini failed: Please set an Auth method", err.Error())
+
+	_, err = ParseGeminiJSON([]byte(`{"error": {"type": "Error", "message": "[API Error: RESOURCE_EXHAUSTED: Quota exceeded for quota metric]", "code": 1}}`))
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, FailureQuota, failed.Kind)
+}
+
+func TestGeminiRunError(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 53").Run()
+	require.Error(t, exitErr)
+
+	err := geminiRunError(exitErr, nil, "Reached max session turns for this session.\n")
+	var failed *HeadlessError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, 53, failed.ExitCode)
+	assert.Equal(t, FailureTurnLimit, failed.Kind)
+	assert.Equal(t, "gemini failed: Reached max session turns for this session.", err.Error())
+
+	exitErr = exec.Command("sh", "-c", "exit 1").Run()
+	err = geminiRunError(exitErr, nil, "Unknown arguments: output-format, outputFormat")
+	require.True(t, errors.As(err, &failed))
+	assert.Empty(t, failed.Kind)
+	assert.True(t, unknownOutputFormat(failed.Message))
+}
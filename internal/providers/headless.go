@@ -0,0 +1,262 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// headlessFormat is how a headless command prints its answer
+type headlessFormat int
+
+const (
+	formatText headlessFormat = iota
+	// formatClaudeStream is Claude's --output-format stream-json events
+	formatClaudeStream
+	// formatGeminiJSON is Gemini's --output-format json object
+	formatGeminiJSON
+)
+
+// HeadlessCommand returns the command and args that run a single prompt
+// non-interactively and print the answer to stdout. A fresh probe of the
+// provider decides the print flag, and fails early when there is none.
+func HeadlessCommand(provider, model, prompt string) (string, []string, error) {
+	name, args, _, err := headlessCommand(provider, model, prompt, true, nil)
+	return name, args, err
+}
+
+// headlessCommand is HeadlessCommand, also reporting how the command prints
+// its answer. Claude streams its events and Gemini prints a JSON object,
+// unless structured is false or a fresh probe found no JSON output support.
+// extraArgs, such as an agent's model parameters, follow the print mode args.
+func headlessCommand(provider, model, prompt string, structured bool, extraArgs []string) (string, []string, headlessFormat, error) {
+	printFlag := "-p"
+	if features, ok := CachedFeatures(provider); ok {
+		if !features.Print {
+			return "", nil, formatText, fmt.Errorf("%s %s has no non-interactive print mode, update it and re-run 'opun providers'", provider, features.Version)
+		}
+		printFlag = features.PrintFlag
+		structured = structured && features.JSON
+	}
+
+	args, format, err := headlessArgs(provider, model, prompt, printFlag, structured)
+	if err != nil {
+		return "", nil, formatText, err
+	}
+	if provider == "mock" {
+		return "echo", args, format, nil
+	}
+	args = append(args, extraArgs...)
+
+	// The command may be a wrapper such as "npx claude-code"
+	argv, err := ResolveCommand(provider)
+	if err != nil {
+		return "", nil, formatText, err
+	}
+	return argv[0], append(argv[1:], args...), format, nil
+}
+
+// ContainerHeadlessCommand returns the command line that runs a prompt
+// non-interactively in a container image with the provider CLI installed
+// under its own name, followed by extraArgs. Its output is read with
+// ParseHeadlessOutput.
+func ContainerHeadlessCommand(provider, model, prompt, workDir string, extraArgs ...string) ([]string, error) {
+	prompt = FormatProfileFor(provider).Apply(prompt, workDir)
+	args, _, err := headlessArgs(provider, model, prompt, "-p", true)
+	if err != nil {
+		return nil, err
+	}
+	if provider == "mock" {
+		return append([]string{"echo"}, args...), nil
+	}
+	return append(append([]string{provider}, args...), extraArgs...), nil
+}
+
+// ParseHeadlessOutput reads the answer from the output of a command built by
+// ContainerHeadlessCommand
+func ParseHeadlessOutput(provider string, output []byte) (HeadlessResult, error) {
+	var (
+		result HeadlessResult
+		err    error
+	)
+	switch provider {
+	case "claude":
+		result, err = ParseClaudeStream(bytes.NewReader(output), nil)
+	case "gemini":
+		result, err = ParseGeminiJSON(output)
+	default:
+		result.Output = string(output)
+	}
+	result.Output = strings.TrimSpace(result.Output)
+	return result, err
+}
+
+// headlessArgs returns a provider's print mode arguments and how it prints
+// its answer
+func headlessArgs(provider, model, prompt, printFlag string, structured bool) ([]string, headlessFormat, error) {
+	var (
+		args   []string
+		format = formatText
+	)
+
+	switch provider {
+	case "claude":
+		args = []string{printFlag, prompt}
+		if model != "" {
+			args = append(args, "--model", model)
+		}
+		if structured {
+			// stream-json needs --verbose in print mode
+			args = append(args, "--output-format", "stream-json", "--verbose")
+			format = formatClaudeStream
+		}
+	case "gemini", "qwen":
+		args = []string{printFlag, prompt}
+		if model != "" {
+			args = append(args, "-m", model)
+		}
+		if provider == "gemini" && structured {
+			args = append(args, "--output-format", "json")
+			format = formatGeminiJSON
+		}
+	case "mock":
+		return []string{fmt.Sprintf("Mock response to: %s", prompt)}, formatText, nil
+	default:
+		return nil, formatText, fmt.Errorf("unsupported provider: %s", provider)
+	}
+	return args, format, nil
+}
+
+// RunHeadless runs a prompt on a provider without a PTY and returns its answer
+func RunHeadless(ctx context.Context, provider, model, prompt, workDir string) (string, error) {
+	result, err := RunHeadlessStream(ctx, provider, model, prompt, workDir, nil)
+	return result.Output, err
+}
+
+// RunHeadlessStream runs a prompt on a provider without a PTY. Claude's
+// stream-json events are reported to onProgress, which may be nil, as they
+// arrive, and its final result message is the answer, with its usage.
+// Gemini's JSON output gives the answer, model and usage, and its failures
+// are HeadlessErrors classified by exit code and stderr. Other providers'
+// answers are their printed text. The prompt is rendered with the
+// provider's format profile. extraArgs, such as an agent's model parameters
+// or system prompt flags, are passed to the provider CLI.
+func RunHeadlessStream(ctx context.Context, provider, model, prompt, workDir string, onProgress func(StreamProgress), extraArgs ...string) (HeadlessResult, error) {
+	result, err := runHeadless(ctx, provider, model, prompt, workDir, true, onProgress, extraArgs)
+	var failed *HeadlessError
+	if errors.As(err, &failed) && failed.Kind == "" && unknownOutputFormat(failed.Message) {
+		// A Gemini without --output-format, not yet probed
+		return runHeadless(ctx, provider, model, prompt, workDir, false, onProgress, extraArgs)
+	}
+	return result, err
+}
+
+// runHeadless runs a headless command once
+func runHeadless(ctx context.Context, provider, model, prompt, workDir string, structured bool, onProgress func(StreamProgress), extraArgs []string) (HeadlessResult, error) {
+	prompt = FormatProfileFor(provider).Apply(prompt, workDir)
+	name, args, format, err := headlessCommand(provider, model, prompt, structured, extraArgs)
+	if err != nil {
+		return HeadlessResult{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = workDir
+	cmd.Stderr = &stderr
+
+	var (
+		result   HeadlessResult
+		parseErr error
+	)
+	switch format {
+	case formatClaudeStream:
+		pipe, err := cmd.StdoutPipe()
+		if err != nil {
+			return HeadlessResult{}, err
+		}
+		if err := cmd.Start(); err != nil {
+			return HeadlessResult{}, fmt.Errorf("%s failed: %w", provider, err)
+		}
+		result, parseErr = ParseClaudeStream(pipe, onProgress)
+		// Let the process exit even if parsing stopped early
+		_, _ = io.Copy(io.Discard, pipe)
+		err = cmd.Wait()
+	case formatGeminiJSON:
+		cmd.Stdout = &stdout
+		err = cmd.Run()
+		result, parseErr = ParseGeminiJSON(stdout.Bytes())
+	default:
+		cmd.Stdout = &stdout
+		err = cmd.Run()
+		result.Output = stdout.String()
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if format == formatGeminiJSON {
+			return result, geminiRunError(err, parseErr, stderr.String())
+		}
+		if parseErr != nil {
+			return result, parseErr
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return result, fmt.Errorf("%s failed: %s", provider, msg)
+		}
+		return result, fmt.Errorf("%s failed: %w", provider, err)
+	}
+	if parseErr != nil {
+		return result, parseErr
+	}
+
+	result.Output = strings.TrimSpace(result.Output)
+	return result, nil
+}
+
+// geminiRunError classifies a Gemini run that exited with an error. The
+// JSON error object is preferred, then stderr.
+func geminiRunError(runErr, parseErr error, stderr string) error {
+	var failed *HeadlessError
+	if !errors.As(parseErr, &failed) {
+		failed = &HeadlessError{Provider: "gemini", Message: strings.TrimSpace(stderr)}
+		if failed.Message == "" {
+			failed.Message = runErr.Error()
+		}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) && failed.ExitCode == 0 {
+		failed.ExitCode = exitErr.ExitCode()
+	}
+	if failed.Kind == "" {
+		failed.Kind = geminiFailureKind(failed.ExitCode, failed.Message+" "+stderr)
+	}
+	return failed
+}
+
+// unknownOutputFormat reports whether a provider rejected --output-format
+func unknownOutputFormat(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "output-format") &&
+		(strings.Contains(message, "unknown") || strings.Contains(message, "invalid") || strings.Contains(message, "unrecognized"))
+}
@@ -0,0 +1,253 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProbeFile is the cache of the last probe in the Opun directory
+const ProbeFile = "providers.json"
+
+// ProbeTTL is how long a cached probe is trusted
+const ProbeTTL = 24 * time.Hour
+
+// probeTimeout bounds each command a probe runs
+const probeTimeout = 10 * time.Second
+
+// KnownProviders are the provider CLIs Opun can probe
+var KnownProviders = []string{"claude", "gemini", "qwen"}
+
+// Features are what an installed provider CLI supports, as found by running
+// it with --version and --help
+type Features struct {
+	Provider  string `json:"provider"`
+	Command   string `json:"command,omitempty"`
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+	// Print is a non-interactive mode that prints the answer, run with PrintFlag
+	Print     bool   `json:"print"`
+	PrintFlag string `json:"print_flag,omitempty"`
+	// JSON is structured output, e.g. --output-format json
+	JSON   bool `json:"json"`
+	Resume bool `json:"resume"`
+	MCP    bool `json:"mcp"`
+	// Models are the model names the help text offers, when it lists any
+	Models   []string  `json:"models,omitempty"`
+	ProbedAt time.Time `json:"probed_at"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Capabilities returns the workflow capabilities the features provide
+func (f Features) Capabilities() []string {
+	var capabilities []string
+	if f.MCP {
+		capabilities = append(capabilities, "mcp")
+	}
+	if f.Resume {
+		capabilities = append(capabilities, "session-continuation")
+	}
+	if f.JSON {
+		capabilities = append(capabilities, "json-output")
+	}
+	return capabilities
+}
+
+var (
+	helpFlagPattern    = regexp.MustCompile(`(?:^|[\s,\[])(--[a-z][a-z0-9-]*|-[a-zA-Z])\b`)
+	mcpCommandPattern  = regexp.MustCompile(`(?m)^\s+(?:\S+\s+)?mcp\b`)
+	modelLinePattern   = regexp.MustCompile(`(?m)^.*--model\b.*$`)
+	quotedModelPattern = regexp.MustCompile(`["'` + "`" + `]([a-z][a-z0-9.-]+)["'` + "`" + `]`)
+	versionPattern     = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?(?:[-+][0-9A-Za-z.-]+)?`)
+)
+
+// ParseHelp reads the features a provider's --help output advertises
+func ParseHelp(help string) Features {
+	flags := make(map[string]bool)
+	for _, m := range helpFlagPattern.FindAllStringSubmatch(help, -1) {
+		flags[m[1]] = true
+	}
+
+	var f Features
+	switch {
+	case flags["-p"]:
+		f.PrintFlag = "-p"
+	case flags["--print"]:
+		f.PrintFlag = "--print"
+	case flags["--prompt"]:
+		f.PrintFlag = "--prompt"
+	}
+	f.Print = f.PrintFlag != ""
+	f.JSON = flags["--json"] || (flags["--output-format"] && strings.Contains(help, "json"))
+	f.Resume = flags["--resume"] || flags["--continue"]
+	f.MCP = flags["--mcp-config"] || flags["--allowed-mcp-server-names"] || mcpCommandPattern.MatchString(help)
+
+	seen := make(map[string]bool)
+	for _, line := range modelLinePattern.FindAllString(help, -1) {
+		for _, m := range quotedModelPattern.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				f.Models = append(f.Models, m[1])
+			}
+		}
+	}
+	return f
+}
+
+// ParseVersion finds the version number in a provider's --version output
+func ParseVersion(output string) string {
+	return versionPattern.FindString(output)
+}
+
+// Probe runs a provider CLI to find out what it supports. A provider that
+// isn't installed is reported, not an error.
+func Probe(ctx context.Context, provider string) Features {
+	f := Features{Provider: provider, ProbedAt: time.Now()}
+
+	argv, err := ResolveCommand(provider)
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	f.Command = strings.Join(argv, " ")
+	f.Installed = true
+
+	version, err := runProbe(ctx, argv, "--version")
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	help, err := runProbe(ctx, argv, "--help")
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+
+	parsed := ParseHelp(help)
+	parsed.Provider, parsed.Command, parsed.Installed, parsed.ProbedAt = f.Provider, f.Command, true, f.ProbedAt
+	parsed.Version = ParseVersion(version)
+	return parsed
+}
+
+// runProbe runs a provider command with one flag and returns its output
+func runProbe(ctx context.Context, argv []string, flag string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	// #nosec G204 -- argv is the resolved provider command
+	cmd := exec.CommandContext(ctx, argv[0], append(argv[1:], flag)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil && len(output) == 0 {
+		return "", fmt.Errorf("%s %s failed: %w", strings.Join(argv, " "), flag, err)
+	}
+	return string(output), nil
+}
+
+// ProbePath returns the probe cache, ~/.opun/providers.json
+func ProbePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", ProbeFile), nil
+}
+
+// SaveProbes adds probe results to the cache, replacing earlier results for
+// the same providers
+func SaveProbes(path string, probes []Features) error {
+	cached, err := LoadProbes(path)
+	if err != nil {
+		cached = make(map[string]Features)
+	}
+	for _, f := range probes {
+		cached[f.Provider] = f
+	}
+
+	list := make([]Features, 0, len(cached))
+	for _, f := range cached {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Provider < list[j].Provider })
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadProbes reads the probe cache by provider. A missing file is an empty cache.
+func LoadProbes(path string) (map[string]Features, error) {
+	probes := make(map[string]Features)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return probes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Features
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, f := range list {
+		probes[f.Provider] = f
+	}
+	return probes, nil
+}
+
+// CachedFeatures returns a provider's cached probe if it is fresh; probes
+// are refreshed by 'opun providers'
+func CachedFeatures(provider string) (Features, bool) {
+	path, err := ProbePath()
+	if err != nil {
+		return Features{}, false
+	}
+	probes, err := LoadProbes(path)
+	if err != nil {
+		return Features{}, false
+	}
+	f, ok := probes[strings.ToLower(provider)]
+	if !ok || !f.Installed || f.Error != "" || time.Since(f.ProbedAt) > ProbeTTL {
+		return Features{}, false
+	}
+	return f, true
+}
+
+// CachedCapabilities returns the capabilities fresh probes found, by provider
+func CachedCapabilities() map[string][]string {
+	capabilities := make(map[string][]string)
+	for _, provider := range KnownProviders {
+		if f, ok := CachedFeatures(provider); ok {
+			capabilities[provider] = f.Capabilities()
+		}
+	}
+	return capabilities
+}
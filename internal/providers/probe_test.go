@@ -0,0 +1,105 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const claudeHelp = `Usage: claude [options] [command] [prompt]
+
+Options:
+  -p, --print                       Print response and exit (useful for pipes)
+  --output-format <format>          Output format (only works with --print): "text" (default), "json", or "stream-json"
+  --mcp-config <configs...>         Load MCP servers from JSON files or strings
+  -c, --continue                    Continue the most recent conversation
+  -r, --resume [sessionId]          Resume a conversation
+  --model <model>                   Model for the current session. Provide an alias for the latest model (e.g. 'sonnet' or 'opus')
+
+Commands:
+  config                            Manage configuration
+  mcp                               Configure and manage MCP servers
+`
+
+const oldHelp = `Usage: assistant [options]
+
+Options:
+  --model <name>    Model to use
+  -d, --debug       Debug mode
+`
+
+func TestParseHelp(t *testing.T) {
+	f := ParseHelp(claudeHelp)
+	assert.True(t, f.Print)
+	assert.Equal(t, "-p", f.PrintFlag)
+	assert.True(t, f.JSON)
+	assert.True(t, f.Resume)
+	assert.True(t, f.MCP)
+	assert.Equal(t, []string{"sonnet", "opus"}, f.Models)
+	assert.Equal(t, []string{"mcp", "session-continuation", "json-output"}, f.Capabilities())
+
+	f = ParseHelp(oldHelp)
+	assert.False(t, f.Print)
+	assert.False(t, f.JSON)
+	assert.False(t, f.Resume)
+	assert.False(t, f.MCP)
+	assert.Empty(t, f.Models)
+	assert.Empty(t, f.Capabilities())
+}
+
+func TestParseVersion(t *testing.T) {
+	assert.Equal(t, "1.0.3", ParseVersion("1.0.3 (Claude Code)\n"))
+	assert.Equal(t, "0.1.9-nightly.2", ParseVersion("gemini v0.1.9-nightly.2"))
+	assert.Empty(t, ParseVersion("unknown"))
+}
+
+func TestProbeCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path, err := ProbePath()
+	require.NoError(t, err)
+
+	probes, err := LoadProbes(path)
+	require.NoError(t, err)
+	assert.Empty(t, probes)
+
+	claude := ParseHelp(claudeHelp)
+	claude.Provider, claude.Installed, claude.ProbedAt = "claude", true, time.Now()
+	gemini := Features{Provider: "gemini", Installed: true, ProbedAt: time.Now().Add(-2 * ProbeTTL)}
+	require.NoError(t, SaveProbes(path, []Features{claude, gemini}))
+	require.NoError(t, SaveProbes(path, []Features{{Provider: "qwen"}}))
+
+	probes, err = LoadProbes(path)
+	require.NoError(t, err)
+	assert.Len(t, probes, 3, "saving merges into the cache")
+	assert.Equal(t, filepath.Join(home, ".opun", ProbeFile), path)
+
+	f, ok := CachedFeatures("claude")
+	require.True(t, ok)
+	assert.Equal(t, "-p", f.PrintFlag)
+	_, ok = CachedFeatures("gemini")
+	assert.False(t, ok, "stale probe")
+	_, ok = CachedFeatures("qwen")
+	assert.False(t, ok, "not installed")
+
+	assert.Equal(t, map[string][]string{"claude": {"mcp", "session-continuation", "json-output"}}, CachedCapabilities())
+}
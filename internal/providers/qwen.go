@@ -37,6 +37,7 @@ type QwenProvider struct {
 	clipboard        utils.Clipboard
 	injectionManager *config.InjectionManager
 	environment      *config.ProviderEnvironment
+	releaseSession   func() // unregisters the session directory's shutdown cleanup
 }
 
 // NewQwenProvider creates a new Qwen provider
@@ -124,6 +125,7 @@ func (p *QwenProvider) PrepareSession(ctx context.Context, sessionID string) err
 	if err := os.MkdirAll(sessionDir, 0755); err != nil {
 		return err
 	}
+	p.releaseSession = utils.RegisterTempDir(sessionDir)
 
 	// Prepare provider environment if injection manager is available
 	if p.injectionManager != nil {
@@ -149,6 +151,10 @@ func (p *QwenProvider) CleanupSession(ctx context.Context, sessionID string) err
 	}
 
 	// Clean up session directory
+	if p.releaseSession != nil {
+		p.releaseSession()
+		p.releaseSession = nil
+	}
 	sessionDir := filepath.Join(os.TempDir(), "opun", "sessions", sessionID)
 	return os.RemoveAll(sessionDir)
 }
@@ -309,21 +315,22 @@ func (p *QwenProvider) getCommand() (string, []string) {
 	// Add any additional args from config
 	args = append(args, config.Args...)
 
-	// Get the qwen command
-	cmd := p.getQwenCommand()
+	// Get the qwen command, which may be a wrapper
+	argv := p.getQwenCommand()
 
-	return cmd, args
+	return argv[0], append(argv[1:], args...)
 }
 
 // checkQwenCLI checks if the Qwen CLI is available
 func (p *QwenProvider) checkQwenCLI() error {
-	// Try 'qwen' command
-	if _, err := exec.LookPath("qwen"); err != nil {
-		return fmt.Errorf("qwen CLI not found in PATH")
+	argv, err := ResolveCommand("qwen")
+	if err != nil {
+		return err
 	}
 
 	// Verify it works
-	cmd := exec.Command("qwen", "--version")
+	// #nosec G204 -- argv is the resolved provider command
+	cmd := exec.Command(argv[0], append(argv[1:], "--version")...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("qwen CLI found but not working: %w", err)
 	}
@@ -331,14 +338,17 @@ func (p *QwenProvider) checkQwenCLI() error {
 	return nil
 }
 
-// getQwenCommand returns the command to run Qwen
-func (p *QwenProvider) getQwenCommand() string {
+// getQwenCommand returns the command line to run Qwen
+func (p *QwenProvider) getQwenCommand() []string {
 	config := p.Config()
 	// Check for override in config
 	if cmd, ok := config.Settings["command"].(string); ok && cmd != "" {
-		return cmd
+		return []string{cmd}
 	}
 
-	return "qwen"
+	if argv, err := ResolveCommand("qwen"); err == nil {
+		return argv
+	}
+	return []string{"qwen"}
 }
 
@@ -0,0 +1,147 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// StartupStep is one step of a startup script: when Expect appears in the
+// session's output, Send is typed
+type StartupStep struct {
+	// Expect is a regular expression matched against the output since the
+	// previous step, with ANSI escape sequences removed
+	Expect string `json:"expect" yaml:"expect" mapstructure:"expect"`
+	// Send are the keys to type. <enter>, <esc>, <tab>, <space>, <backspace>
+	// and the arrow keys <up>, <down>, <left> and <right> are named keys.
+	Send string `json:"send" yaml:"send" mapstructure:"send"`
+}
+
+// StartupScript answers the dialogs a provider TUI shows before its input
+// prompt, such as a folder trust dialog on first run. Its steps run in order
+// before the prompt is typed; once the input prompt is ready, steps whose
+// text hasn't appeared are skipped.
+type StartupScript []StartupStep
+
+// startupKeys replaces the named keys of a step's Send
+var startupKeys = strings.NewReplacer(
+	"<enter>", "\r",
+	"<esc>", "\x1b",
+	"<tab>", "\t",
+	"<space>", " ",
+	"<backspace>", "\x7f",
+	"<up>", "\x1b[A",
+	"<down>", "\x1b[B",
+	"<right>", "\x1b[C",
+	"<left>", "\x1b[D",
+)
+
+// ansiPattern matches ANSI escape sequences in terminal output
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]|\x1b\][^\x07]*\x07`)
+
+// StripANSI removes ANSI escape sequences from terminal output
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// Validate checks that every step has a valid pattern and keys to send
+func (s StartupScript) Validate() error {
+	_, err := s.Runner()
+	return err
+}
+
+// Runner compiles the script to run it against a session. An empty script
+// has a nil runner, which is always done.
+func (s StartupScript) Runner() (*StartupRunner, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	r := &StartupRunner{}
+	for i, step := range s {
+		if step.Expect == "" || step.Send == "" {
+			return nil, fmt.Errorf("startup step %d needs expect and send", i+1)
+		}
+		expect, err := regexp.Compile(step.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("startup step %d: invalid expect: %w", i+1, err)
+		}
+		r.expect = append(r.expect, expect)
+		r.send = append(r.send, startupKeys.Replace(step.Send))
+	}
+	return r, nil
+}
+
+// StartupRunner tracks a startup script through a session's output
+type StartupRunner struct {
+	expect []*regexp.Regexp
+	send   []string
+	next   int
+	offset int
+}
+
+// Feed matches the current step against output, everything the session has
+// printed so far, and returns the keys to type when it appears
+func (r *StartupRunner) Feed(output string) string {
+	if r.Done() || len(output) < r.offset {
+		return ""
+	}
+	if !r.expect[r.next].MatchString(StripANSI(output[r.offset:])) {
+		return ""
+	}
+	keys := r.send[r.next]
+	r.next++
+	r.offset = len(output)
+	return keys
+}
+
+// Offset is where the output after the last answered step starts; the input
+// prompt is only looked for from there
+func (r *StartupRunner) Offset() int {
+	if r == nil {
+		return 0
+	}
+	return r.offset
+}
+
+// Done reports whether every step has been answered
+func (r *StartupRunner) Done() bool {
+	return r == nil || r.next >= len(r.expect)
+}
+
+var (
+	startupScriptsMu sync.RWMutex
+	startupScripts   = make(map[string]StartupScript)
+)
+
+// SetStartupScript sets the startup script of a provider, e.g. from the
+// providers section of the config
+func SetStartupScript(provider string, script StartupScript) {
+	startupScriptsMu.Lock()
+	defer startupScriptsMu.Unlock()
+	startupScripts[strings.ToLower(provider)] = script
+}
+
+// StartupScriptFor returns a provider's startup script; providers have none
+// unless one is configured
+func StartupScriptFor(provider string) StartupScript {
+	startupScriptsMu.RLock()
+	defer startupScriptsMu.RUnlock()
+	return startupScripts[strings.ToLower(provider)]
+}
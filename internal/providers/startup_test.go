@@ -0,0 +1,65 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupRunner(t *testing.T) {
+	script := StartupScript{
+		{Expect: `Do you trust the files`, Send: "<enter>"},
+		{Expect: `Select a workspace`, Send: "<down><enter>"},
+	}
+	r, err := script.Runner()
+	require.NoError(t, err)
+
+	output := "Welcome\n\x1b[1mDo you\x1b[0m trust"
+	assert.Empty(t, r.Feed(output))
+	output += " the files in this folder?\n❯ 1. Yes, proceed"
+	assert.Equal(t, "\r", r.Feed(output))
+	assert.Equal(t, len(output), r.Offset())
+
+	// The next step only matches output after the answered one
+	assert.Empty(t, r.Feed(output))
+	assert.False(t, r.Done())
+	output += "\nSelect a workspace:\n  repo\n  other"
+	assert.Equal(t, "\x1b[B\r", r.Feed(output))
+	assert.True(t, r.Done())
+	assert.Empty(t, r.Feed(output+"Do you trust the files"))
+}
+
+func TestStartupScriptValidate(t *testing.T) {
+	assert.NoError(t, StartupScript{}.Validate())
+	assert.Error(t, StartupScript{{Expect: "trust"}}.Validate())
+	assert.Error(t, StartupScript{{Expect: "(", Send: "y"}}.Validate())
+
+	var r *StartupRunner
+	assert.True(t, r.Done())
+	assert.Empty(t, r.Feed("anything"))
+	assert.Zero(t, r.Offset())
+}
+
+func TestStartupScriptFor(t *testing.T) {
+	assert.Empty(t, StartupScriptFor("qwen"))
+	SetStartupScript("Qwen", StartupScript{{Expect: "trust", Send: "y"}})
+	defer SetStartupScript("qwen", nil)
+	assert.Len(t, StartupScriptFor("qwen"), 1)
+}
@@ -0,0 +1,220 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxStreamLine bounds one line of Claude's stream-json output; tool
+// results and long answers arrive as single lines
+const maxStreamLine = 16 * 1024 * 1024
+
+// Usage is what a headless step cost, as the provider reported it
+type Usage struct {
+	InputTokens              int     `json:"input_tokens"`
+	OutputTokens             int     `json:"output_tokens"`
+	CacheCreationInputTokens int     `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int     `json:"cache_read_input_tokens,omitempty"`
+	CostUSD                  float64 `json:"cost_usd,omitempty"`
+	DurationMS               int     `json:"duration_ms,omitempty"`
+	Turns                    int     `json:"turns,omitempty"`
+}
+
+// Add sums usage, e.g. of all steps of a run
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+	u.CostUSD += other.CostUSD
+	u.DurationMS += other.DurationMS
+	u.Turns += other.Turns
+}
+
+// Progress kinds of a streamed headless step
+const (
+	ProgressStarted = "started"
+	ProgressText    = "text"
+	ProgressTool    = "tool"
+)
+
+// StreamProgress is one thing a streaming provider did while answering
+type StreamProgress struct {
+	Kind string `json:"kind"`
+	// Text is the model for started, the text for text and the tool's
+	// target, such as a file or command, for tool
+	Text string `json:"text,omitempty"`
+	Tool string `json:"tool,omitempty"`
+}
+
+// HeadlessResult is the answer of a headless step and what the provider
+// reported about it. Usage is nil when the provider doesn't report it.
+type HeadlessResult struct {
+	Output    string `json:"output"`
+	SessionID string `json:"session_id,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Usage     *Usage `json:"usage,omitempty"`
+}
+
+// claudeStreamEvent is one line of `claude -p --output-format stream-json`
+type claudeStreamEvent struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	SessionID string `json:"session_id"`
+	Model     string `json:"model"`
+	Message   struct {
+		Content []struct {
+			Type  string                 `json:"type"`
+			Text  string                 `json:"text"`
+			Name  string                 `json:"name"`
+			Input map[string]interface{} `json:"input"`
+		} `json:"content"`
+	} `json:"message"`
+	// Set on the final result event
+	Result       *string  `json:"result"`
+	IsError      bool     `json:"is_error"`
+	TotalCostUSD float64  `json:"total_cost_usd"`
+	DurationMS   int      `json:"duration_ms"`
+	NumTurns     int      `json:"num_turns"`
+	Usage        *Usage   `json:"usage"`
+	Errors       []string `json:"errors"`
+}
+
+// ParseClaudeStream reads Claude's stream-json output as it arrives,
+// reporting progress to onProgress, which may be nil. The result event's
+// text is the answer; without one, the assistant's text is. Output that
+// isn't stream-json, such as from a Claude too old for it, is returned as is.
+func ParseClaudeStream(r io.Reader, onProgress func(StreamProgress)) (HeadlessResult, error) {
+	var (
+		result    HeadlessResult
+		texts     []string
+		raw       strings.Builder
+		final     *claudeStreamEvent
+		streaming bool
+	)
+	report := func(p StreamProgress) {
+		if onProgress != nil {
+			onProgress(p)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var event claudeStreamEvent
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &event) != nil || event.Type == "" {
+			raw.WriteString(line + "\n")
+			continue
+		}
+		streaming = true
+		if event.SessionID != "" {
+			result.SessionID = event.SessionID
+		}
+
+		switch event.Type {
+		case "system":
+			if event.Subtype == "init" {
+				result.Model = event.Model
+				report(StreamProgress{Kind: ProgressStarted, Text: event.Model})
+			}
+		case "assistant":
+			for _, block := range event.Message.Content {
+				switch block.Type {
+				case "text":
+					if text := strings.TrimSpace(block.Text); text != "" {
+						texts = append(texts, text)
+						report(StreamProgress{Kind: ProgressText, Text: text})
+					}
+				case "tool_use":
+					report(StreamProgress{Kind: ProgressTool, Tool: block.Name, Text: toolTarget(block.Input)})
+				}
+			}
+		case "result":
+			e := event
+			final = &e
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read stream-json output: %w", err)
+	}
+
+	if !streaming {
+		result.Output = strings.TrimSpace(raw.String())
+		return result, nil
+	}
+	if final == nil {
+		result.Output = strings.Join(texts, "\n\n")
+		return result, nil
+	}
+
+	if final.Usage != nil || final.TotalCostUSD > 0 {
+		usage := Usage{}
+		if final.Usage != nil {
+			usage = *final.Usage
+		}
+		usage.CostUSD = final.TotalCostUSD
+		usage.DurationMS = final.DurationMS
+		usage.Turns = final.NumTurns
+		result.Usage = &usage
+	}
+	if final.Result != nil {
+		result.Output = strings.TrimSpace(*final.Result)
+	} else {
+		result.Output = strings.Join(texts, "\n\n")
+	}
+	if final.IsError || strings.HasPrefix(final.Subtype, "error") {
+		reason := final.Subtype
+		if len(final.Errors) > 0 {
+			reason = strings.Join(final.Errors, "; ")
+		} else if result.Output != "" {
+			reason = result.Output
+		}
+		return result, fmt.Errorf("claude failed: %s", reason)
+	}
+	return result, nil
+}
+
+// toolTarget picks what a tool call works on, such as its file, command or
+// pattern, for progress lines
+func toolTarget(input map[string]interface{}) string {
+	for _, key := range []string{"file_path", "path", "command", "pattern", "url", "query", "description"} {
+		if value, ok := input[key].(string); ok && value != "" {
+			return firstLine(value)
+		}
+	}
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := input[key].(string); ok && value != "" {
+			return firstLine(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package providers
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const claudeStream = `{"type":"system","subtype":"init","session_id":"s1","model":"claude-sonnet-4","tools":["Read","Bash"]}
+{"type":"assistant","session_id":"s1","message":{"content":[{"type":"text","text":"Let me look at the code."}]}}
+{"type":"assistant","session_id":"s1","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"main.go"}}]}}
+{"type":"user","session_id":"s1","message":{"content":[{"type":"tool_result","content":"package main"}]}}
+{"type":"assistant","session_id":"s1","message":{"content":[{"type":"text","text":"It prints hello."}]}}
+{"type":"result","subtype":"success","is_error":false,"session_id":"s1","result":"It prints hello.","total_cost_usd":0.0123,"duration_ms":4200,"num_turns":2,"usage":{"input_tokens":120,"output_tokens":30,"cache_read_input_tokens":900}}
+`
+
+func TestParseClaudeStream(t *testing.T) {
+	var progress []StreamProgress
+	result, err := ParseClaudeStream(strings.NewReader(claudeStream), func(p StreamProgress) {
+		progress = append(progress, p)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "It prints hello.", result.Output)
+	assert.Equal(t, "s1", result.SessionID)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, Usage{InputTokens: 120, OutputTokens: 30, CacheReadInputTokens: 900, CostUSD: 0.0123, DurationMS: 4200, Turns: 2}, *result.Usage)
+	assert.Equal(t, []StreamProgress{
+		{Kind: ProgressStarted, Text: "claude-sonnet-4"},
+		{Kind: ProgressText, Text: "Let me look at the code."},
+		{Kind: ProgressTool, Tool: "Read", Text: "main.go"},
+		{Kind: ProgressText, Text: "It prints hello."},
+	}, progress)
+}
+
+func TestParseClaudeStream_Error(t *testing.T) {
+	stream := `{"type":"system","subtype":"init","session_id":"s2"}
+{"type":"result","subtype":"error_max_turns","is_error":true,"session_id":"s2","num_turns":10,"usage":{"input_tokens":5,"output_tokens":1}}
+`
+	result, err := ParseClaudeStream(strings.NewReader(stream), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error_max_turns")
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, 10, result.Usage.Turns)
+}
+
+func TestParseClaudeStream_Fallbacks(t *testing.T) {
+	// An older Claude printing plain text
+	result, err := ParseClaudeStream(strings.NewReader("Hello there.\n{not json}\n"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there.\n{not json}", result.Output)
+	assert.Nil(t, result.Usage)
+
+	// A stream cut off before its result event
+	stream := `{"type":"assistant","message":{"content":[{"type":"text","text":"First."}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Second."}]}}
+`
+	result, err = ParseClaudeStream(strings.NewReader(stream), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "First.\n\nSecond.", result.Output)
+	assert.Nil(t, result.Usage)
+}
+
+func TestUsageAdd(t *testing.T) {
+	total := Usage{InputTokens: 1, OutputTokens: 2, CostUSD: 0.5}
+	total.Add(Usage{InputTokens: 10, OutputTokens: 20, CacheReadInputTokens: 3, CostUSD: 0.25, Turns: 1})
+	assert.Equal(t, Usage{InputTokens: 11, OutputTokens: 22, CacheReadInputTokens: 3, CostUSD: 0.75, Turns: 1}, total)
+}
@@ -19,10 +19,10 @@ package providers
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/rizome-dev/opun/internal/providers"
 	"github.com/rizome-dev/opun/internal/pty"
 )
 
@@ -39,14 +39,10 @@ func NewClaudePTYProvider() *ClaudePTYProvider {
 
 // StartSession starts a new Claude session
 func (p *ClaudePTYProvider) StartSession(ctx context.Context, workingDir string) error {
-	// Try to find claude command
-	command := "claude"
-	var args []string
-
-	if _, err := exec.LookPath(command); err != nil {
-		// Try npx fallback
-		command = "npx"
-		args = []string{"claude-code"}
+	// Find the claude command, which may be a wrapper such as npx
+	command, args := "npx", []string{"claude-code"}
+	if argv, err := providers.ResolveCommand("claude"); err == nil {
+		command, args = argv[0], argv[1:]
 	}
 
 	config := pty.SessionConfig{
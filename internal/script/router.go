@@ -0,0 +1,123 @@
+package script
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/core"
+)
+
+// Router routes subagent tasks with a script. The script sees the task as
+// task and the capable subagents as agents, and returns the name of the one
+// to use; returning nothing leaves the choice to the fallback router.
+type Router struct {
+	script   *Script
+	fallback core.TaskRouter
+	limits   Limits
+}
+
+// NewRouter creates a router running script, deferring to fallback for
+// scoring, learning and tasks the script doesn't decide
+func NewRouter(script *Script, fallback core.TaskRouter) *Router {
+	return &Router{script: script, fallback: fallback}
+}
+
+// Route runs the script to pick a subagent for a task
+func (r *Router) Route(task core.SubAgentTask, agents []core.SubAgent) (core.SubAgent, error) {
+	candidates := make([]core.SubAgent, 0, len(agents))
+	described := make([]interface{}, 0, len(agents))
+	for _, agent := range agents {
+		if !agent.CanHandle(task) {
+			continue
+		}
+		config := agent.Config()
+		candidates = append(candidates, agent)
+		described = append(described, map[string]interface{}{
+			"name":         agent.Name(),
+			"provider":     string(config.Provider),
+			"model":        config.Model,
+			"capabilities": agent.GetCapabilities(),
+			"priority":     config.Priority,
+			"score":        r.Score(task, agent),
+		})
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no capable agents found for task %s", task.Name)
+	}
+
+	result, err := r.script.Run(context.Background(), map[string]interface{}{
+		"task": map[string]interface{}{
+			"name":        task.Name,
+			"description": task.Description,
+			"input":       task.Input,
+			"priority":    task.Priority,
+			"constraints": task.Constraints,
+			"variables":   task.Variables,
+		},
+		"agents": described,
+	}, "", r.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	name, ok := result.(string)
+	if result != nil && !ok {
+		return nil, fmt.Errorf("routing script %s must return an agent name, got %T", r.script.Name(), result)
+	}
+	if name == "" {
+		if r.fallback == nil {
+			return candidates[0], nil
+		}
+		return r.fallback.Route(task, candidates)
+	}
+	for _, agent := range candidates {
+		if strings.EqualFold(agent.Name(), name) {
+			return agent, nil
+		}
+	}
+	return nil, fmt.Errorf("routing script %s chose %q, which is not a capable agent for task %s", r.script.Name(), name, task.Name)
+}
+
+// Score defers to the fallback router
+func (r *Router) Score(task core.SubAgentTask, agent core.SubAgent) float64 {
+	if r.fallback == nil {
+		return 0
+	}
+	return r.fallback.Score(task, agent)
+}
+
+// Learn defers to the fallback router
+func (r *Router) Learn(task core.SubAgentTask, agent core.SubAgent, result *core.SubAgentResult) {
+	if r.fallback != nil {
+		r.fallback.Learn(task, agent, result)
+	}
+}
+
+// GetStats returns the fallback router's statistics and the script's name
+func (r *Router) GetStats() map[string]interface{} {
+	stats := make(map[string]interface{})
+	if r.fallback != nil {
+		for k, v := range r.fallback.GetStats() {
+			stats[k] = v
+		}
+	}
+	stats["script"] = r.script.Name()
+	return stats
+}
@@ -0,0 +1,394 @@
+package script
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/expr"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Extension is the file extension of scripts in the scripts directory
+const Extension = ".star"
+
+// Default limits for a script run
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultMaxSteps = 100000
+)
+
+// maxScriptSize bounds the size of a script file
+const maxScriptSize = 256 << 10
+
+// Limits sandbox a script run. Zero values use the defaults.
+type Limits struct {
+	Timeout  time.Duration // wall clock time for the whole run
+	MaxSteps int           // Starlark execution steps, counting every loop iteration
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.Timeout <= 0 {
+		l.Timeout = DefaultTimeout
+	}
+	if l.MaxSteps <= 0 {
+		l.MaxSteps = DefaultMaxSteps
+	}
+	return l
+}
+
+// Script is a compiled hook script. Scripts are Starlark, run by the embedded
+// go.starlark.net interpreter: a script defines main(), which reads the
+// inputs as global names and returns the result. Besides the Starlark
+// built-ins, scripts get read(path) and exists(path) for files in the
+// working directory; they can't write files, run commands, load modules or
+// reach the network.
+type Script struct {
+	name   string
+	source string
+}
+
+var (
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// fileOptions are the Starlark dialect scripts are written in: the
+	// standard one, with while loops and sets allowed
+	fileOptions = &syntax.FileOptions{While: true, Set: true}
+
+	// readProgram and existsProgram back the file built-ins, so scripts
+	// share the path confinement of workflow conditions
+	readProgram   = mustCompile("read(path)")
+	existsProgram = mustCompile("exists(path)")
+)
+
+func mustCompile(source string) *expr.Program {
+	p, err := expr.Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Dir returns the directory scripts are loaded from, ~/.opun/scripts
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "scripts"), nil
+}
+
+// Load compiles the script registered under name, <name>.star in the
+// scripts directory
+func Load(name string) (*Script, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFrom(dir, name)
+}
+
+// LoadFrom compiles the script registered under name in dir
+func LoadFrom(dir, name string) (*Script, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid script name %q: use letters, digits, - and _", name)
+	}
+	path := filepath.Join(dir, name+Extension)
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("script %s not found in %s", name, dir)
+		}
+		return nil, err
+	}
+	if info.Size() > maxScriptSize {
+		return nil, fmt.Errorf("script %s is larger than %d KB", name, maxScriptSize>>10)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(name, string(data))
+}
+
+// List returns the names of the scripts in dir
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), Extension)
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == Extension && namePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Compile checks a script's source: it must parse and define main(). Names
+// are resolved when the script runs, against the inputs of that run.
+func Compile(name, source string) (*Script, error) {
+	f, _, err := starlark.SourceProgramOptions(fileOptions, name+Extension, source, func(string) bool { return true })
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", name, err)
+	}
+	for _, stmt := range f.Stmts {
+		if def, ok := stmt.(*syntax.DefStmt); ok && def.Name.Name == "main" {
+			return &Script{name: name, source: source}, nil
+		}
+	}
+	return nil, fmt.Errorf("script %s: no main() function", name)
+}
+
+// Name returns the name the script was registered under
+func (s *Script) Name() string {
+	return s.name
+}
+
+// Run calls the script's main() with vars as global names and returns what
+// it returns, nil for None. dir confines the file built-ins, empty for the
+// current directory.
+func (s *Script) Run(ctx context.Context, vars map[string]interface{}, dir string, limits Limits) (interface{}, error) {
+	limits = limits.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, limits.Timeout)
+	defer cancel()
+
+	predeclared := starlark.StringDict{
+		"read":   fileBuiltin("read", readProgram, dir),
+		"exists": fileBuiltin("exists", existsProgram, dir),
+	}
+	for name, value := range vars {
+		v, err := toStarlark(value)
+		if err != nil {
+			return nil, fmt.Errorf("script %s: input %s: %w", s.name, name, err)
+		}
+		predeclared[name] = v
+	}
+
+	_, prog, err := starlark.SourceProgramOptions(fileOptions, s.name+Extension, s.source, predeclared.Has)
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", s.name, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("script %s: %w", s.name, err)
+	}
+	thread := &starlark.Thread{
+		Name:  s.name,
+		Print: func(*starlark.Thread, string) {},
+	}
+	thread.SetMaxExecutionSteps(uint64(limits.MaxSteps))
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	result, err := s.call(thread, prog, predeclared)
+	if err != nil {
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return nil, fmt.Errorf("script %s: exceeded its %s time limit", s.name, limits.Timeout)
+		case ctx.Err() != nil:
+			return nil, fmt.Errorf("script %s: %w", s.name, ctx.Err())
+		case thread.ExecutionSteps() >= uint64(limits.MaxSteps):
+			return nil, fmt.Errorf("script %s: exceeded its limit of %d steps", s.name, limits.MaxSteps)
+		}
+		return nil, fmt.Errorf("script %s: %s", s.name, describe(err))
+	}
+	return fromStarlark(result)
+}
+
+// call runs the script's top level, then its main()
+func (s *Script) call(thread *starlark.Thread, prog *starlark.Program, predeclared starlark.StringDict) (starlark.Value, error) {
+	globals, err := prog.Init(thread, predeclared)
+	if err != nil {
+		return nil, err
+	}
+	main, ok := globals["main"].(starlark.Callable)
+	if !ok {
+		return nil, errors.New("main is not a function")
+	}
+	return starlark.Call(thread, main, nil, nil)
+}
+
+// describe puts the script position of a Starlark error in front of its
+// message, leaving out the built-in frames
+func describe(err error) string {
+	var evalErr *starlark.EvalError
+	if !errors.As(err, &evalErr) {
+		return err.Error()
+	}
+	for i := len(evalErr.CallStack) - 1; i >= 0; i-- {
+		if pos := evalErr.CallStack[i].Pos; pos.Line > 0 {
+			return fmt.Sprintf("%s: %s", pos, evalErr.Msg)
+		}
+	}
+	return evalErr.Msg
+}
+
+// fileBuiltin exposes a file function of workflow conditions to scripts
+func fileBuiltin(name string, program *expr.Program, dir string) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+			return nil, err
+		}
+		result, err := program.Eval(expr.Env{Values: map[string]interface{}{"path": path}, Dir: dir})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		return toStarlark(result)
+	})
+}
+
+// toStarlark converts an input to a Starlark value
+func toStarlark(value interface{}) (starlark.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case starlark.Value:
+		return v, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case fmt.Stringer:
+		return starlark.String(v.String()), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return starlark.Bool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return starlark.MakeInt64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return starlark.MakeUint64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return starlark.Float(rv.Float()), nil
+	case reflect.String:
+		return starlark.String(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		elems := make([]starlark.Value, rv.Len())
+		for i := range elems {
+			elem, err := toStarlark(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = elem
+		}
+		return starlark.NewList(elems), nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", rv.Type().Key())
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		dict := starlark.NewDict(len(keys))
+		for _, key := range keys {
+			elem, err := toStarlark(rv.MapIndex(key).Interface())
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(key.String()), elem); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return starlark.None, nil
+		}
+		return toStarlark(rv.Elem().Interface())
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+// fromStarlark converts a script's result back to Go values
+func fromStarlark(value starlark.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		if n, ok := v.Int64(); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("integer %s is too large", v)
+	case starlark.Float:
+		if math.IsInf(float64(v), 0) || math.IsNaN(float64(v)) {
+			return nil, fmt.Errorf("result %s is not a finite number", v)
+		}
+		return float64(v), nil
+	case starlark.Indexable:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := fromStarlark(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case *starlark.Dict:
+		m := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("dict key %s is not a string", item[0])
+			}
+			elem, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			m[key] = elem
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported result type %s", value.Type())
+}
@@ -0,0 +1,247 @@
+package script
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, source string, vars map[string]interface{}) (interface{}, error) {
+	t.Helper()
+	s, err := Compile("test", source)
+	require.NoError(t, err)
+	return s.Run(context.Background(), vars, t.TempDir(), Limits{})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Statements", func(t *testing.T) {
+		result, err := run(t, `
+# Number the lines that mention TODO
+def main():
+    out = ""
+    n = 0
+    for line in text.splitlines():
+        if "TODO" not in line:
+            continue
+        n += 1
+        out += "%d. %s\n" % (n, line.strip())
+        if n == 2:
+            break
+    return out
+`, map[string]interface{}{"text": "a\nTODO one\nb\n  TODO two\nTODO three"})
+		require.NoError(t, err)
+		assert.Equal(t, "1. TODO one\n2. TODO two\n", result)
+	})
+
+	t.Run("Elif And Else", func(t *testing.T) {
+		source := `
+def kind(n):
+    if n > 100:
+        return "large"
+    elif n > 10:
+        return "medium"
+    return "small"
+
+def main():
+    return kind(size)
+`
+		for size, want := range map[int]string{500: "large", 50: "medium", 5: "small"} {
+			result, err := run(t, source, map[string]interface{}{"size": size})
+			require.NoError(t, err)
+			assert.Equal(t, want, result)
+		}
+	})
+
+	t.Run("Inputs And Results", func(t *testing.T) {
+		result, err := run(t, `
+def main():
+    return {"id": agent["id"], "keys": sorted(agent.keys()), "tags": tags + ["new"], "n": len(tags)}
+`, map[string]interface{}{
+			"agent": map[string]interface{}{"id": "writer", "name": "Writer"},
+			"tags":  []string{"a", "b"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"id":   "writer",
+			"keys": []interface{}{"id", "name"},
+			"tags": []interface{}{"a", "b", "new"},
+			"n":    int64(2),
+		}, result)
+	})
+
+	t.Run("No Return", func(t *testing.T) {
+		result, err := run(t, "def main():\n    x = 1", nil)
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Fail", func(t *testing.T) {
+		_, err := run(t, "def main():\n    if len(output) < 10:\n        fail(\"output too short: \" + output)", map[string]interface{}{"output": "tiny"})
+		require.Error(t, err)
+		assert.Equal(t, "script test: test.star:3:13: fail: output too short: tiny", err.Error())
+	})
+
+	t.Run("Unknown Name", func(t *testing.T) {
+		_, err := run(t, "def main():\n    return missing + 1", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test.star:2:12: undefined: missing")
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644))
+		s, err := Compile("files", `
+def main():
+    if exists("missing.txt"):
+        return "unexpected"
+    return read("go.mod").strip()
+`)
+		require.NoError(t, err)
+		result, err := s.Run(context.Background(), nil, dir, Limits{})
+		require.NoError(t, err)
+		assert.Equal(t, "module x", result)
+
+		escape, err := Compile("escape", "def main():\n    return read(\"../secret\")")
+		require.NoError(t, err)
+		_, err = escape.Run(context.Background(), nil, dir, Limits{})
+		assert.ErrorContains(t, err, "is outside")
+	})
+
+	t.Run("No Load", func(t *testing.T) {
+		_, err := run(t, "load(\"other.star\", \"x\")\ndef main():\n    return x", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestLimits(t *testing.T) {
+	s, err := Compile("loop", "def main():\n    while True:\n        pass")
+	require.NoError(t, err)
+
+	t.Run("Steps", func(t *testing.T) {
+		_, err := s.Run(context.Background(), nil, "", Limits{MaxSteps: 50})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "limit of 50 steps")
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		_, err := s.Run(context.Background(), nil, "", Limits{Timeout: 50 * time.Millisecond, MaxSteps: math.MaxInt})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeded its 50ms time limit")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.Run(ctx, nil, "", Limits{Timeout: time.Second})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCompileErrors(t *testing.T) {
+	for source, want := range map[string]string{
+		"def main()\n    return 1":                "bad.star:2:1: got newline, want ':'",
+		"def main():\nreturn 1":                   "bad.star:2:7: got return, want indent",
+		"if x:\n    y = 1\ndef main():\n    pass": "bad.star:1:1: if statement not within a function",
+		"def helper():\n    return 1":             "no main() function",
+	} {
+		_, err := Compile("bad", source)
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), want, source)
+	}
+}
+
+func TestLoadFrom(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "upper.star"), []byte("def main():\n    return prompt.upper()\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a script"), 0644))
+
+	s, err := LoadFrom(dir, "upper")
+	require.NoError(t, err)
+	result, err := s.Run(context.Background(), map[string]interface{}{"prompt": "hi"}, "", Limits{})
+	require.NoError(t, err)
+	assert.Equal(t, "HI", result)
+
+	_, err = LoadFrom(dir, "missing")
+	assert.ErrorContains(t, err, "script missing not found")
+	_, err = LoadFrom(dir, "../upper")
+	assert.ErrorContains(t, err, "invalid script name")
+
+	names, err := List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upper"}, names)
+}
+
+// stubSubAgent implements the parts of core.SubAgent used by routing
+type stubSubAgent struct {
+	core.SubAgent
+	config core.SubAgentConfig
+}
+
+func (a *stubSubAgent) Name() string                          { return a.config.Name }
+func (a *stubSubAgent) Config() core.SubAgentConfig           { return a.config }
+func (a *stubSubAgent) GetCapabilities() []string             { return a.config.Capabilities }
+func (a *stubSubAgent) CanHandle(task core.SubAgentTask) bool { return true }
+
+// firstRouter always picks the first agent
+type firstRouter struct{}
+
+func (firstRouter) Route(task core.SubAgentTask, agents []core.SubAgent) (core.SubAgent, error) {
+	return agents[0], nil
+}
+func (firstRouter) Score(core.SubAgentTask, core.SubAgent) float64               { return 1 }
+func (firstRouter) Learn(core.SubAgentTask, core.SubAgent, *core.SubAgentResult) {}
+func (firstRouter) GetStats() map[string]interface{}                             { return map[string]interface{}{"routed": 1} }
+
+func TestRouter(t *testing.T) {
+	agents := []core.SubAgent{
+		&stubSubAgent{config: core.SubAgentConfig{Name: "reviewer", Provider: core.ProviderTypeClaude, Capabilities: []string{"review"}}},
+		&stubSubAgent{config: core.SubAgentConfig{Name: "researcher", Provider: core.ProviderTypeGemini, Capabilities: []string{"research"}}},
+	}
+	s, err := Compile("route", `
+def main():
+    for agent in agents:
+        if agent["capabilities"][0] in task["description"].lower():
+            return agent["name"]
+    if task["name"] == "bogus":
+        return "nobody"
+`)
+	require.NoError(t, err)
+	router := NewRouter(s, firstRouter{})
+
+	agent, err := router.Route(core.SubAgentTask{Name: "t", Description: "Do some Research"}, agents)
+	require.NoError(t, err)
+	assert.Equal(t, "researcher", agent.Name())
+
+	// Nothing returned, the fallback decides
+	agent, err = router.Route(core.SubAgentTask{Name: "t", Description: "Write docs"}, agents)
+	require.NoError(t, err)
+	assert.Equal(t, "reviewer", agent.Name())
+
+	_, err = router.Route(core.SubAgentTask{Name: "bogus"}, agents)
+	assert.ErrorContains(t, err, `chose "nobody"`)
+
+	assert.Equal(t, map[string]interface{}{"routed": 1, "script": "route"}, router.GetStats())
+}
@@ -25,6 +25,18 @@ import (
 	"github.com/rizome-dev/opun/pkg/core"
 )
 
+// HeadlessRunner runs a prompt with Gemini CLI non-interactively, returning
+// its answer and metadata such as the model and token usage
+type HeadlessRunner func(ctx context.Context, model, prompt string) (string, map[string]interface{}, error)
+
+var headlessRunner HeadlessRunner
+
+// SetHeadlessRunner sets how tasks of non-interactive agents without a PTY
+// provider run. The providers package sets it to Gemini's JSON output mode.
+func SetHeadlessRunner(run HeadlessRunner) {
+	headlessRunner = run
+}
+
 // GeminiAdapter adapts Gemini's programmatic SubAgentScope system
 type GeminiAdapter struct {
 	config   core.SubAgentConfig
@@ -160,6 +172,10 @@ func (a *GeminiAdapter) Execute(ctx context.Context, task core.SubAgentTask) (*c
 	startTime := time.Now()
 	a.status = core.StatusRunning
 	
+	if a.provider == nil && !a.config.Interactive && headlessRunner != nil {
+		return a.executeHeadless(ctx, task, startTime)
+	}
+	
 	// Build the execution command for Gemini
 	execCommand := a.buildExecutionCommand(task)
 	
@@ -202,6 +218,45 @@ func (a *GeminiAdapter) Execute(ctx context.Context, task core.SubAgentTask) (*c
 	}, nil
 }
 
+// executeHeadless runs a task with Gemini CLI's non-interactive JSON mode.
+// The model and usage Gemini reports, and the kind of a failure, are kept
+// in the result's metadata.
+func (a *GeminiAdapter) executeHeadless(ctx context.Context, task core.SubAgentTask, startTime time.Time) (*core.SubAgentResult, error) {
+	if a.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.config.Timeout)
+		defer cancel()
+	}
+	
+	prompt := a.buildSystemPrompt() + "\nTask: " + task.Description
+	if task.Input != "" {
+		prompt += "\n\n" + task.Input
+	}
+	output, metadata, err := headlessRunner(ctx, a.config.Model, prompt)
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["provider"] = "gemini"
+	metadata["method"] = "headless_json"
+	
+	result := &core.SubAgentResult{
+		TaskID:    task.ID,
+		AgentName: a.config.Name,
+		Status:    core.StatusCompleted,
+		Output:    output,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		Duration:  time.Since(startTime),
+		Metadata:  metadata,
+	}
+	if err != nil {
+		result.Status = core.StatusFailed
+		result.Error = err
+	}
+	a.status = result.Status
+	return result, err
+}
+
 // ExecuteAsync executes a task asynchronously
 func (a *GeminiAdapter) ExecuteAsync(ctx context.Context, task core.SubAgentTask) (<-chan *core.SubAgentResult, error) {
 	resultChan := make(chan *core.SubAgentResult, 1)
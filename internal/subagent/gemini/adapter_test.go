@@ -18,7 +18,9 @@ package gemini
 
 import (
 	"context"
+	"errors"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
@@ -307,6 +309,40 @@ func TestGeminiAdapter_Capabilities(t *testing.T) {
 	})
 }
 
+func TestGeminiAdapter_HeadlessExecution(t *testing.T) {
+	var gotModel, gotPrompt string
+	SetHeadlessRunner(func(ctx context.Context, model, prompt string) (string, map[string]interface{}, error) {
+		gotModel, gotPrompt = model, prompt
+		if strings.Contains(prompt, "fail") {
+			return "", map[string]interface{}{"failure": "quota"}, errors.New("gemini failed: Quota exceeded")
+		}
+		return "Looks good", map[string]interface{}{"model": "gemini-2.5-pro"}, nil
+	})
+	defer SetHeadlessRunner(nil)
+
+	adapter := NewGeminiAdapter(core.SubAgentConfig{
+		Name:     "reviewer",
+		Provider: core.ProviderTypeGemini,
+		Model:    "gemini-2.5-pro",
+	})
+	require.NoError(t, adapter.Initialize(adapter.Config()))
+
+	result, err := adapter.Execute(context.Background(), core.SubAgentTask{ID: "t1", Description: "Review the diff", Input: "+ x := 1"})
+	require.NoError(t, err)
+	assert.Equal(t, core.StatusCompleted, result.Status)
+	assert.Equal(t, "Looks good", result.Output)
+	assert.Equal(t, "gemini-2.5-pro", gotModel)
+	assert.Contains(t, gotPrompt, "Task: Review the diff")
+	assert.Contains(t, gotPrompt, "+ x := 1")
+	assert.Equal(t, "gemini-2.5-pro", result.Metadata["model"])
+	assert.Equal(t, "headless_json", result.Metadata["method"])
+
+	result, err = adapter.Execute(context.Background(), core.SubAgentTask{ID: "t2", Description: "fail"})
+	require.Error(t, err)
+	assert.Equal(t, core.StatusFailed, result.Status)
+	assert.Equal(t, "quota", result.Metadata["failure"])
+}
+
 func TestGeminiAdapter_ProviderIntegration(t *testing.T) {
 	adapter := NewGeminiAdapter(core.SubAgentConfig{
 		Name:     "provider-gemini",
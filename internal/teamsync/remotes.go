@@ -0,0 +1,171 @@
+package teamsync
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRemote is the name of a remote added without --name
+const DefaultRemote = "origin"
+
+// DefaultBranch is the branch synced when a remote doesn't name one
+const DefaultBranch = "main"
+
+// Roles on a remote. Items pulled as a member are read-only locally;
+// maintainers may change them in place.
+const (
+	RoleMember     = "member"
+	RoleMaintainer = "maintainer"
+)
+
+// Remote is a shared git repository prompts, workflows and actions are
+// synced with
+type Remote struct {
+	Name   string `yaml:"name" json:"name"`
+	URL    string `yaml:"url" json:"url"`
+	Branch string `yaml:"branch,omitempty" json:"branch,omitempty"`
+	// Namespaces are the top-level directories of the repository to sync;
+	// empty syncs all of them
+	Namespaces []string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+	// Role is member (default) or maintainer
+	Role string `yaml:"role,omitempty" json:"role,omitempty"`
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// RemotesPath returns the remote registry file, ~/.opun/remotes.yaml
+func RemotesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "remotes.yaml"), nil
+}
+
+// LoadRemotes reads the remote registry, sorted by name. A missing file is an empty registry.
+func LoadRemotes(path string) ([]Remote, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var remotes []Remote
+	if err := yaml.Unmarshal(data, &remotes); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+// SaveRemote adds or replaces a remote in the registry
+func SaveRemote(path string, remote Remote) error {
+	if !validName.MatchString(remote.Name) {
+		return fmt.Errorf("invalid remote name %q", remote.Name)
+	}
+	if remote.URL == "" {
+		return fmt.Errorf("remote url is required")
+	}
+	if remote.Role != "" && remote.Role != RoleMember && remote.Role != RoleMaintainer {
+		return fmt.Errorf("invalid role %q (use %s or %s)", remote.Role, RoleMember, RoleMaintainer)
+	}
+	for _, ns := range remote.Namespaces {
+		if !validName.MatchString(ns) {
+			return fmt.Errorf("invalid namespace %q", ns)
+		}
+	}
+
+	remotes, err := LoadRemotes(path)
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range remotes {
+		if remotes[i].Name == remote.Name {
+			remotes[i] = remote
+			replaced = true
+		}
+	}
+	if !replaced {
+		remotes = append(remotes, remote)
+	}
+	return writeRemotes(path, remotes)
+}
+
+// RemoveRemote deletes a remote from the registry
+func RemoveRemote(path, name string) error {
+	remotes, err := LoadRemotes(path)
+	if err != nil {
+		return err
+	}
+
+	kept := remotes[:0]
+	for _, remote := range remotes {
+		if remote.Name != name {
+			kept = append(kept, remote)
+		}
+	}
+	if len(kept) == len(remotes) {
+		return fmt.Errorf("remote %s not found", name)
+	}
+	return writeRemotes(path, kept)
+}
+
+// FindRemote returns a registered remote by name. An empty name picks the
+// only remote, or origin when there are several.
+func FindRemote(path, name string) (*Remote, error) {
+	remotes, err := LoadRemotes(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("no remotes configured, add one with 'opun remote add <git-url>'")
+	}
+	if name == "" {
+		if len(remotes) == 1 {
+			return &remotes[0], nil
+		}
+		name = DefaultRemote
+	}
+	for i := range remotes {
+		if remotes[i].Name == name {
+			return &remotes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("remote %s not found", name)
+}
+
+func writeRemotes(path string, remotes []Remote) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(remotes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,202 @@
+package teamsync
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/promptgarden"
+)
+
+// Kinds of items that are synced, named like their directory in a namespace
+const (
+	KindPrompts   = "prompts"
+	KindWorkflows = "workflows"
+	KindActions   = "actions"
+)
+
+// Kinds lists the synced item kinds
+var Kinds = []string{KindPrompts, KindWorkflows, KindActions}
+
+// Store reads and writes the items of one side of a sync
+type Store interface {
+	// List returns the names of the items of a kind
+	List(kind string) ([]string, error)
+	// Read returns an item's content, or an error wrapping os.ErrNotExist
+	Read(kind, name string) ([]byte, error)
+	Write(kind, name string, content []byte) error
+	Delete(kind, name string) error
+}
+
+// fileExt is the extension items of a kind are stored with in a repository
+func fileExt(kind string) string {
+	if kind == KindPrompts {
+		return ".json"
+	}
+	return ".yaml"
+}
+
+// LocalStore is the user's installed items: workflows and actions as YAML
+// files under ~/.opun and prompts in the prompt garden
+type LocalStore struct {
+	dir    string
+	garden *promptgarden.Garden
+}
+
+// NewLocalStore opens the items installed under an Opun directory, usually ~/.opun
+func NewLocalStore(opunDir string) (*LocalStore, error) {
+	garden, err := promptgarden.NewGarden(filepath.Join(opunDir, "promptgarden"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to access prompt garden: %w", err)
+	}
+	return &LocalStore{dir: opunDir, garden: garden}, nil
+}
+
+// List implements Store
+func (s *LocalStore) List(kind string) ([]string, error) {
+	if kind == KindPrompts {
+		prompts, err := s.garden.ListPrompts()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(prompts))
+		for _, prompt := range prompts {
+			names = append(names, prompt.Name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	return listDir(filepath.Join(s.dir, kind), ".yaml")
+}
+
+// Read implements Store. Prompts are read as JSON without their local ID.
+func (s *LocalStore) Read(kind, name string) ([]byte, error) {
+	if kind != KindPrompts {
+		return os.ReadFile(filepath.Join(s.dir, kind, name+".yaml"))
+	}
+	prompt, err := s.prompt(name)
+	if err != nil {
+		return nil, err
+	}
+	prompt.ID = ""
+	data, err := json.MarshalIndent(prompt, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Write implements Store
+func (s *LocalStore) Write(kind, name string, content []byte) error {
+	if kind != KindPrompts {
+		dir := filepath.Join(s.dir, kind)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, name+".yaml"), content, 0644)
+	}
+
+	var prompt promptgarden.Prompt
+	if err := json.Unmarshal(content, &prompt); err != nil {
+		return fmt.Errorf("invalid prompt %s: %w", name, err)
+	}
+	prompt.Name = name
+	prompt.ID = name
+	if existing, err := s.prompt(name); err == nil {
+		prompt.ID = existing.ID
+	}
+	return s.garden.SavePrompt(&prompt)
+}
+
+// Delete implements Store
+func (s *LocalStore) Delete(kind, name string) error {
+	if kind != KindPrompts {
+		return os.Remove(filepath.Join(s.dir, kind, name+".yaml"))
+	}
+	prompt, err := s.prompt(name)
+	if err != nil {
+		return err
+	}
+	return s.garden.DeletePrompt(prompt.ID)
+}
+
+// prompt returns a garden prompt by name, with an os.ErrNotExist error when
+// there is none
+func (s *LocalStore) prompt(name string) (*promptgarden.Prompt, error) {
+	existing, err := s.garden.GetByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", name, os.ErrNotExist)
+	}
+	return s.garden.GetPrompt(existing.ID())
+}
+
+// repoStore is one namespace of a repository checkout
+type repoStore struct {
+	dir string
+}
+
+// List implements Store
+func (s repoStore) List(kind string) ([]string, error) {
+	return listDir(filepath.Join(s.dir, kind), fileExt(kind))
+}
+
+// Read implements Store
+func (s repoStore) Read(kind, name string) ([]byte, error) {
+	return os.ReadFile(s.path(kind, name))
+}
+
+// Write implements Store
+func (s repoStore) Write(kind, name string, content []byte) error {
+	if err := os.MkdirAll(filepath.Join(s.dir, kind), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(kind, name), content, 0644)
+}
+
+// Delete implements Store
+func (s repoStore) Delete(kind, name string) error {
+	return os.Remove(s.path(kind, name))
+}
+
+func (s repoStore) path(kind, name string) string {
+	return filepath.Join(s.dir, kind, name+fileExt(kind))
+}
+
+// listDir returns the names of the files in dir with an extension
+func listDir(dir, ext string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ext))
+	}
+	sort.Strings(names)
+	return names, nil
+}
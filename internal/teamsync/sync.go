@@ -0,0 +1,597 @@
+package teamsync
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/provenance"
+)
+
+// What a sync did to an item
+const (
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+	ChangeMerged  = "merged"
+	// ChangeKept is a conflict resolved by keeping the local version, which
+	// the next push publishes
+	ChangeKept = "kept"
+	// ChangeLocal is an item only changed locally, waiting to be pushed
+	ChangeLocal  = "local"
+	ChangePushed = "pushed"
+)
+
+// stateFile keeps what was synced last, next to the repository checkout
+const stateFile = "state.json"
+
+// Conflict is an item changed both locally and in the repository since the
+// last sync, or found on both sides before it was ever synced
+type Conflict struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Local     []byte
+	Remote    []byte
+}
+
+// Resolver decides what to keep locally for a conflicting item: the
+// Remote content, the Local one, or a merge of both
+type Resolver func(c Conflict) ([]byte, error)
+
+// Change is what a pull or push did to one item
+type Change struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Action    string `json:"action"`
+}
+
+// Report lists the changes of a pull or push
+type Report struct {
+	Changes  []Change `json:"changes"`
+	Commit   string   `json:"commit,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ItemRef names an item of a kind, e.g. workflows/review
+type ItemRef struct {
+	Kind string
+	Name string
+}
+
+// Syncer syncs the local items with the namespaces of one remote
+type Syncer struct {
+	Remote Remote
+	// Dir holds the repository checkout and the sync state
+	Dir   string
+	Local Store
+	// Resolve is asked about conflicts on pull; without one they fail the pull
+	Resolve Resolver
+	// Provenance is the registry synced items are marked managed in; empty
+	// skips marking
+	Provenance string
+}
+
+// syncState records each item's content hashes as of the last sync, so a
+// later sync can tell which side changed it
+type syncState struct {
+	Commit string               `json:"commit,omitempty"`
+	Items  map[string]itemState `json:"items"`
+}
+
+type itemState struct {
+	Namespace string `json:"namespace"`
+	// Local is the hash of the installed item; empty when the local version
+	// is meant to replace the repository's on the next push
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote"`
+}
+
+// remoteItem is an item found in a namespace of the repository
+type remoteItem struct {
+	namespace string
+	content   []byte
+}
+
+// NewSyncer creates a syncer keeping its checkout in ~/.opun/sync/<remote>
+func NewSyncer(remote Remote, local Store) (*Syncer, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Syncer{
+		Remote:     remote,
+		Dir:        filepath.Join(home, ".opun", "sync", remote.Name),
+		Local:      local,
+		Provenance: filepath.Join(home, ".opun", provenance.File),
+	}, nil
+}
+
+// ParseItemRef parses kind/name, accepting singular kinds like workflow/review
+func ParseItemRef(s string) (ItemRef, error) {
+	kind, name, ok := strings.Cut(s, "/")
+	if ok && !strings.HasSuffix(kind, "s") {
+		kind += "s"
+	}
+	for _, k := range Kinds {
+		if ok && kind == k && validName.MatchString(name) {
+			return ItemRef{Kind: kind, Name: name}, nil
+		}
+	}
+	return ItemRef{}, fmt.Errorf("invalid item %q, expected prompt/<name>, workflow/<name> or action/<name>", s)
+}
+
+// Pull updates the local items from the repository. Items only changed in
+// the repository are installed, items only changed locally are left for the
+// next push, and items changed on both sides go to Resolve.
+func (s *Syncer) Pull(ctx context.Context) (*Report, error) {
+	if err := s.checkout(ctx); err != nil {
+		return nil, err
+	}
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+	items, report, err := s.remoteItems()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range sortedKeys(items) {
+		item := items[key]
+		ref := refOf(key)
+		change := Change{Kind: ref.Kind, Name: ref.Name, Namespace: item.namespace}
+		remoteHash := hash(item.content)
+		st, tracked := state.Items[key]
+
+		local, err := s.Local.Read(ref.Kind, ref.Name)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		localExists := err == nil
+
+		switch {
+		case !localExists:
+			if err := s.install(state, key, item, item.content); err != nil {
+				return nil, err
+			}
+			change.Action = ChangeAdded
+
+		case bytes.Equal(local, item.content):
+			state.Items[key] = itemState{Namespace: item.namespace, Local: hash(local), Remote: remoteHash}
+			continue
+
+		case tracked && hash(local) == st.Local:
+			if st.Remote == remoteHash {
+				continue
+			}
+			if err := s.install(state, key, item, item.content); err != nil {
+				return nil, err
+			}
+			change.Action = ChangeUpdated
+
+		case tracked && st.Remote == remoteHash:
+			change.Action = ChangeLocal
+
+		default:
+			if s.Resolve == nil {
+				return nil, fmt.Errorf("%s/%s changed both locally and in %s", ref.Kind, ref.Name, s.Remote.Name)
+			}
+			content, err := s.Resolve(Conflict{Kind: ref.Kind, Name: ref.Name, Namespace: item.namespace, Local: local, Remote: item.content})
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case bytes.Equal(content, local):
+				state.Items[key] = itemState{Namespace: item.namespace, Remote: remoteHash}
+				change.Action = ChangeKept
+			case bytes.Equal(content, item.content):
+				if err := s.install(state, key, item, content); err != nil {
+					return nil, err
+				}
+				change.Action = ChangeUpdated
+			default:
+				if err := s.install(state, key, item, content); err != nil {
+					return nil, err
+				}
+				// The merge exists only locally until it is pushed
+				st := state.Items[key]
+				st.Local = ""
+				state.Items[key] = st
+				change.Action = ChangeMerged
+			}
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	// Items deleted from the repository are deleted locally unless they were changed
+	for _, key := range sortedKeys(state.Items) {
+		if _, ok := items[key]; ok {
+			continue
+		}
+		st := state.Items[key]
+		ref := refOf(key)
+		delete(state.Items, key)
+		if !s.syncsNamespace(st.Namespace) {
+			continue
+		}
+		local, err := s.Local.Read(ref.Kind, ref.Name)
+		if err != nil || hash(local) != st.Local {
+			continue
+		}
+		if err := s.Local.Delete(ref.Kind, ref.Name); err != nil {
+			return nil, err
+		}
+		if s.Provenance != "" {
+			if err := provenance.Unmark(s.Provenance, ref.kindName(), ref.Name); err != nil {
+				return nil, err
+			}
+		}
+		report.Changes = append(report.Changes, Change{Kind: ref.Kind, Name: ref.Name, Namespace: st.Namespace, Action: ChangeDeleted})
+	}
+
+	if err := s.markManaged(state, report); err != nil {
+		return nil, err
+	}
+	if state.Commit, err = s.head(ctx); err != nil {
+		return nil, err
+	}
+	report.Commit = state.Commit
+	return report, s.saveState(state)
+}
+
+// Push publishes the synced items changed locally, and the items in add to
+// the given namespace, as one commit. It fails when the repository changed
+// any of them since the last pull.
+func (s *Syncer) Push(ctx context.Context, add []ItemRef, namespace, message string) (*Report, error) {
+	if err := s.checkout(ctx); err != nil {
+		return nil, err
+	}
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+	items, report, err := s.remoteItems()
+	if err != nil {
+		return nil, err
+	}
+
+	type pushItem struct {
+		ref       ItemRef
+		namespace string
+		content   []byte
+	}
+	var pushes []pushItem
+	for _, key := range sortedKeys(state.Items) {
+		st := state.Items[key]
+		ref := refOf(key)
+		local, err := s.Local.Read(ref.Kind, ref.Name)
+		if err != nil {
+			// Deletions aren't pushed, items are deleted in the repository itself
+			continue
+		}
+		if hash(local) != st.Local {
+			pushes = append(pushes, pushItem{ref: ref, namespace: st.Namespace, content: local})
+		}
+	}
+	for _, ref := range add {
+		key := ref.Kind + "/" + ref.Name
+		if _, ok := state.Items[key]; ok {
+			continue
+		}
+		if namespace == "" {
+			return nil, fmt.Errorf("pass --namespace to choose where to add %s", key)
+		}
+		if !s.syncsNamespace(namespace) {
+			return nil, fmt.Errorf("remote %s doesn't sync namespace %s", s.Remote.Name, namespace)
+		}
+		local, err := s.Local.Read(ref.Kind, ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		pushes = append(pushes, pushItem{ref: ref, namespace: namespace, content: local})
+	}
+
+	for _, p := range pushes {
+		key := p.ref.Kind + "/" + p.ref.Name
+		remote, ok := items[key]
+		if !ok {
+			continue
+		}
+		st, tracked := state.Items[key]
+		if !tracked || hash(remote.content) != st.Remote {
+			return nil, fmt.Errorf("%s changed in %s since the last pull; run 'opun pull' first", key, s.Remote.Name)
+		}
+	}
+	if len(pushes) == 0 {
+		return report, nil
+	}
+
+	repo := s.repoDir()
+	for _, p := range pushes {
+		if err := (repoStore{dir: filepath.Join(repo, p.namespace)}).Write(p.ref.Kind, p.ref.Name, p.content); err != nil {
+			return nil, err
+		}
+		state.Items[p.ref.Kind+"/"+p.ref.Name] = itemState{Namespace: p.namespace, Local: hash(p.content), Remote: hash(p.content)}
+		report.Changes = append(report.Changes, Change{Kind: p.ref.Kind, Name: p.ref.Name, Namespace: p.namespace, Action: ChangePushed})
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Update %d item(s) from opun", len(pushes))
+	}
+	if _, err := git(ctx, repo, "add", "-A"); err != nil {
+		return nil, err
+	}
+	if _, err := git(ctx, repo, "commit", "-q", "-m", message); err != nil {
+		return nil, err
+	}
+	if _, err := git(ctx, repo, "push", "-q", "origin", "HEAD:refs/heads/"+s.branch()); err != nil {
+		return nil, fmt.Errorf("push to %s rejected, run 'opun pull' and try again: %w", s.Remote.Name, err)
+	}
+
+	if err := s.markManaged(state, report); err != nil {
+		return nil, err
+	}
+	if state.Commit, err = s.head(ctx); err != nil {
+		return nil, err
+	}
+	report.Commit = state.Commit
+	return report, s.saveState(state)
+}
+
+// markManaged records the items a sync installed or published as managed by
+// the remote, read-only unless the user maintains it
+func (s *Syncer) markManaged(state *syncState, report *Report) error {
+	if s.Provenance == "" {
+		return nil
+	}
+	var items []provenance.Item
+	for _, change := range report.Changes {
+		if change.Action == ChangeDeleted {
+			continue
+		}
+		ref := ItemRef{Kind: change.Kind, Name: change.Name}
+		items = append(items, provenance.Item{
+			Kind:     ref.kindName(),
+			Name:     ref.Name,
+			Source:   provenance.SourceRemote,
+			Origin:   s.Remote.Name + "/" + state.Items[change.Kind+"/"+change.Name].Namespace,
+			ReadOnly: s.Remote.Role != RoleMaintainer,
+		})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return provenance.Mark(s.Provenance, items...)
+}
+
+// install writes an item locally and records it as synced
+func (s *Syncer) install(state *syncState, key string, item remoteItem, content []byte) error {
+	ref := refOf(key)
+	if err := s.Local.Write(ref.Kind, ref.Name, content); err != nil {
+		return fmt.Errorf("failed to install %s: %w", key, err)
+	}
+	// Stores may normalize what they write, so hash what reads back
+	installed, err := s.Local.Read(ref.Kind, ref.Name)
+	if err != nil {
+		return err
+	}
+	state.Items[key] = itemState{Namespace: item.namespace, Local: hash(installed), Remote: hash(item.content)}
+	return nil
+}
+
+// remoteItems reads the items of the synced namespaces, keyed by kind/name.
+// An item found in several namespaces is taken from the first.
+func (s *Syncer) remoteItems() (map[string]remoteItem, *Report, error) {
+	report := &Report{}
+	namespaces, err := s.namespaces()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make(map[string]remoteItem)
+	for _, ns := range namespaces {
+		store := repoStore{dir: filepath.Join(s.repoDir(), ns)}
+		for _, kind := range Kinds {
+			names, err := store.List(kind)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, name := range names {
+				key := kind + "/" + name
+				if existing, ok := items[key]; ok {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("%s is in namespaces %s and %s, using %s", key, existing.namespace, ns, existing.namespace))
+					continue
+				}
+				content, err := store.Read(kind, name)
+				if err != nil {
+					return nil, nil, err
+				}
+				items[key] = remoteItem{namespace: ns, content: content}
+			}
+		}
+	}
+	return items, report, nil
+}
+
+// namespaces returns the remote's namespaces, or every top-level directory
+// of the repository when it doesn't select any
+func (s *Syncer) namespaces() ([]string, error) {
+	if len(s.Remote.Namespaces) > 0 {
+		return s.Remote.Namespaces, nil
+	}
+	entries, err := os.ReadDir(s.repoDir())
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			namespaces = append(namespaces, entry.Name())
+		}
+	}
+	return namespaces, nil
+}
+
+func (s *Syncer) syncsNamespace(ns string) bool {
+	if len(s.Remote.Namespaces) == 0 {
+		return validName.MatchString(ns)
+	}
+	for _, n := range s.Remote.Namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// checkout clones the repository on first use, then resets the checkout to
+// the remote branch. An empty repository or missing branch checks out empty.
+func (s *Syncer) checkout(ctx context.Context) error {
+	repo := s.repoDir()
+	if _, err := os.Stat(filepath.Join(repo, ".git")); err != nil {
+		if err := os.MkdirAll(s.Dir, 0755); err != nil {
+			return err
+		}
+		// "--" keeps a URL starting with a dash from being read as an option
+		if _, err := git(ctx, s.Dir, "clone", "-q", "--", s.Remote.URL, repo); err != nil {
+			return fmt.Errorf("failed to clone %s: %w", s.Remote.URL, err)
+		}
+	} else if _, err := git(ctx, repo, "remote", "set-url", "--", "origin", s.Remote.URL); err != nil {
+		return err
+	}
+
+	if _, err := git(ctx, repo, "fetch", "-q", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", s.Remote.URL, err)
+	}
+	branch := s.branch()
+	if _, err := git(ctx, repo, "rev-parse", "-q", "--verify", "refs/remotes/origin/"+branch); err == nil {
+		if _, err := git(ctx, repo, "checkout", "-q", "-B", branch, "origin/"+branch); err != nil {
+			return err
+		}
+		if _, err := git(ctx, repo, "reset", "-q", "--hard", "origin/"+branch); err != nil {
+			return err
+		}
+	} else {
+		if _, err := git(ctx, repo, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+			return err
+		}
+		if _, err := git(ctx, repo, "read-tree", "--empty"); err != nil {
+			return err
+		}
+	}
+	_, err := git(ctx, repo, "clean", "-q", "-fdx")
+	return err
+}
+
+// head returns the checked out commit, empty for an empty repository
+func (s *Syncer) head(ctx context.Context) (string, error) {
+	out, err := git(ctx, s.repoDir(), "rev-parse", "-q", "--verify", "HEAD")
+	if err != nil {
+		return "", nil
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (s *Syncer) branch() string {
+	if s.Remote.Branch != "" {
+		return s.Remote.Branch
+	}
+	return DefaultBranch
+}
+
+func (s *Syncer) repoDir() string {
+	return filepath.Join(s.Dir, "repo")
+}
+
+func (s *Syncer) loadState() (*syncState, error) {
+	state := &syncState{Items: make(map[string]itemState)}
+	data, err := os.ReadFile(filepath.Join(s.Dir, stateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid sync state: %w", err)
+	}
+	if state.Items == nil {
+		state.Items = make(map[string]itemState)
+	}
+	return state, nil
+}
+
+func (s *Syncer) saveState(state *syncState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.Dir, stateFile), data, 0644)
+}
+
+// git runs a git command in dir and returns its output
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// kindName is the singular kind, e.g. workflow
+func (r ItemRef) kindName() string {
+	return strings.TrimSuffix(r.Kind, "s")
+}
+
+func refOf(key string) ItemRef {
+	kind, name, _ := strings.Cut(key, "/")
+	return ItemRef{Kind: kind, Name: name}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,272 @@
+package teamsync
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is an in-memory Store
+type memStore map[string][]byte
+
+func (m memStore) List(kind string) ([]string, error) {
+	var names []string
+	for key := range m {
+		if ref := refOf(key); ref.Kind == kind {
+			names = append(names, ref.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m memStore) Read(kind, name string) ([]byte, error) {
+	content, ok := m[kind+"/"+name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+func (m memStore) Write(kind, name string, content []byte) error {
+	m[kind+"/"+name] = content
+	return nil
+}
+
+func (m memStore) Delete(kind, name string) error {
+	delete(m, kind+"/"+name)
+	return nil
+}
+
+func newTestRemote(t *testing.T) Remote {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "opun")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "opun@example.com")
+	}
+	url := filepath.Join(t.TempDir(), "shared.git")
+	_, err := git(context.Background(), t.TempDir(), "init", "-q", "--bare", url)
+	require.NoError(t, err)
+	return Remote{Name: "origin", URL: url, Namespaces: []string{"team"}}
+}
+
+func actions(report *Report) map[string]string {
+	got := make(map[string]string)
+	for _, change := range report.Changes {
+		got[change.Kind+"/"+change.Name] = change.Action
+	}
+	return got
+}
+
+func TestSyncPullPush(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t)
+
+	alice := memStore{"workflows/review": []byte("name: review\n"), "prompts/explain": []byte(`{"name":"explain"}`)}
+	aliceSync := &Syncer{Remote: remote, Dir: t.TempDir(), Local: alice}
+	bob := memStore{}
+	bobSync := &Syncer{Remote: remote, Dir: t.TempDir(), Local: bob}
+
+	// Pulling an empty repository does nothing
+	report, err := aliceSync.Pull(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+
+	_, err = aliceSync.Push(ctx, []ItemRef{{Kind: KindWorkflows, Name: "review"}}, "", "")
+	assert.ErrorContains(t, err, "--namespace")
+	_, err = aliceSync.Push(ctx, []ItemRef{{Kind: KindWorkflows, Name: "review"}}, "other", "")
+	assert.ErrorContains(t, err, "doesn't sync namespace other")
+
+	report, err = aliceSync.Push(ctx, []ItemRef{{Kind: KindWorkflows, Name: "review"}, {Kind: KindPrompts, Name: "explain"}}, "team", "Share review")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"workflows/review": ChangePushed, "prompts/explain": ChangePushed}, actions(report))
+	assert.NotEmpty(t, report.Commit)
+
+	report, err = bobSync.Pull(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"workflows/review": ChangeAdded, "prompts/explain": ChangeAdded}, actions(report))
+	assert.Equal(t, "name: review\n", string(bob["workflows/review"]))
+
+	// Bob's change reaches Alice, who changed nothing
+	bob["workflows/review"] = []byte("name: review\ndescription: bob\n")
+	_, err = bobSync.Push(ctx, nil, "", "")
+	require.NoError(t, err)
+	report, err = aliceSync.Pull(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"workflows/review": ChangeUpdated}, actions(report))
+	assert.Equal(t, "name: review\ndescription: bob\n", string(alice["workflows/review"]))
+
+	t.Run("changes on both sides", func(t *testing.T) {
+		bob["workflows/review"] = []byte("name: review\ndescription: bob 2\n")
+		_, err := bobSync.Push(ctx, nil, "", "")
+		require.NoError(t, err)
+
+		alice["workflows/review"] = []byte("name: review\ndescription: alice\n")
+		_, err = aliceSync.Push(ctx, nil, "", "")
+		assert.ErrorContains(t, err, "run 'opun pull' first")
+
+		var conflicts []Conflict
+		aliceSync.Resolve = func(c Conflict) ([]byte, error) {
+			conflicts = append(conflicts, c)
+			return c.Local, nil
+		}
+		report, err := aliceSync.Pull(ctx)
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, "name: review\ndescription: bob 2\n", string(conflicts[0].Remote))
+		assert.Equal(t, map[string]string{"workflows/review": ChangeKept}, actions(report))
+
+		// Keeping the local version publishes it on the next push
+		report, err = aliceSync.Push(ctx, nil, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"workflows/review": ChangePushed}, actions(report))
+		_, err = bobSync.Pull(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "name: review\ndescription: alice\n", string(bob["workflows/review"]))
+	})
+
+	t.Run("deleted in the repository", func(t *testing.T) {
+		repo := bobSync.repoDir()
+		require.NoError(t, os.Remove(filepath.Join(repo, "team", "prompts", "explain.json")))
+		_, err := git(ctx, repo, "commit", "-qam", "Remove explain")
+		require.NoError(t, err)
+		_, err = git(ctx, repo, "push", "-q", "origin", "HEAD:refs/heads/main")
+		require.NoError(t, err)
+
+		report, err := aliceSync.Pull(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"prompts/explain": ChangeDeleted}, actions(report))
+		_, ok := alice["prompts/explain"]
+		assert.False(t, ok)
+	})
+}
+
+func TestSyncMarksProvenance(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t)
+
+	maintainer := remote
+	maintainer.Role = RoleMaintainer
+	mainSync := &Syncer{Remote: maintainer, Dir: t.TempDir(), Local: memStore{"workflows/review": []byte("name: review\n")}, Provenance: filepath.Join(t.TempDir(), provenance.File)}
+	_, err := mainSync.Push(ctx, []ItemRef{{Kind: KindWorkflows, Name: "review"}}, "team", "")
+	require.NoError(t, err)
+
+	item, err := provenance.Find(mainSync.Provenance, "workflow", "review")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.False(t, item.ReadOnly)
+
+	memberSync := &Syncer{Remote: remote, Dir: t.TempDir(), Local: memStore{}, Provenance: filepath.Join(t.TempDir(), provenance.File)}
+	_, err = memberSync.Pull(ctx)
+	require.NoError(t, err)
+
+	item, err = provenance.Find(memberSync.Provenance, "workflow", "review")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.True(t, item.ReadOnly)
+	assert.Equal(t, provenance.SourceRemote, item.Source)
+	assert.Equal(t, "origin/team", item.Origin)
+}
+
+func TestParseItemRef(t *testing.T) {
+	ref, err := ParseItemRef("workflow/review")
+	require.NoError(t, err)
+	assert.Equal(t, ItemRef{Kind: KindWorkflows, Name: "review"}, ref)
+
+	ref, err = ParseItemRef("prompts/explain")
+	require.NoError(t, err)
+	assert.Equal(t, ItemRef{Kind: KindPrompts, Name: "explain"}, ref)
+
+	for _, invalid := range []string{"review", "tool/x", "workflow/../x"} {
+		_, err := ParseItemRef(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRemoteRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remotes.yaml")
+
+	_, err := FindRemote(path, "")
+	assert.ErrorContains(t, err, "no remotes configured")
+
+	require.NoError(t, SaveRemote(path, Remote{Name: "origin", URL: "git@example.com:org/opun-config.git"}))
+	require.NoError(t, SaveRemote(path, Remote{Name: "design", URL: "https://example.com/design.git", Namespaces: []string{"ux"}}))
+	assert.Error(t, SaveRemote(path, Remote{Name: "bad", URL: "x", Namespaces: []string{"../up"}}))
+
+	remote, err := FindRemote(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, "origin", remote.Name)
+	remote, err = FindRemote(path, "design")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ux"}, remote.Namespaces)
+
+	require.NoError(t, RemoveRemote(path, "origin"))
+	remote, err = FindRemote(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, "design", remote.Name)
+	assert.Error(t, RemoveRemote(path, "origin"))
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Write(KindWorkflows, "review", []byte("name: review\n")))
+	names, err := store.List(KindWorkflows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"review"}, names)
+
+	require.NoError(t, store.Write(KindPrompts, "explain", []byte(`{"name":"explain","content":"Explain {{topic}}","metadata":{"version":"1.0.0"}}`)))
+	content, err := store.Read(KindPrompts, "explain")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"content": "Explain {{topic}}"`)
+	assert.Contains(t, string(content), `"id": ""`)
+
+	// Writing it back updates the same prompt
+	require.NoError(t, store.Write(KindPrompts, "explain", content))
+	again, err := store.Read(KindPrompts, "explain")
+	require.NoError(t, err)
+	assert.Equal(t, content, again)
+
+	require.NoError(t, store.Delete(KindPrompts, "explain"))
+	_, err = store.Read(KindPrompts, "explain")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSyncOptionLikeURL(t *testing.T) {
+	remote := newTestRemote(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	remote.URL = "--upload-pack=touch " + marker
+
+	_, err := (&Syncer{Remote: remote, Dir: t.TempDir(), Local: memStore{}}).Pull(context.Background())
+	assert.Error(t, err)
+	assert.NoFileExists(t, marker)
+}
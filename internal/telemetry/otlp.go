@@ -0,0 +1,405 @@
+package telemetry
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables. TargetEnv is how opun hands its trace target to the
+// processes it starts, such as the MCP server a provider launches; the OTEL_
+// variables are the standard OpenTelemetry ones.
+const (
+	TargetEnv         = "OPUN_OTEL"
+	TraceParentEnv    = "TRACEPARENT"
+	otlpEndpointEnv   = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otlpTracesEnv     = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	otlpHeadersEnv    = "OTEL_EXPORTER_OTLP_HEADERS"
+	otlpTracesHeaders = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+)
+
+// Span kinds and status codes from the OTLP protocol
+const (
+	SpanKindInternal = 1
+	SpanKindClient   = 3
+
+	statusOK    = 1
+	statusError = 2
+)
+
+// exportTimeout bounds an export to an OTLP endpoint
+const exportTimeout = 10 * time.Second
+
+// ServiceVersion is reported as service.version on every trace, set by main
+var ServiceVersion = "dev"
+
+// Span is one timed operation of a trace
+type Span struct {
+	TraceID      string // 32 hex characters
+	SpanID       string // 16 hex characters
+	ParentSpanID string // empty for the root span
+	Name         string
+	Kind         int
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Events       []SpanEvent
+	Error        string // non-empty marks the span as failed
+}
+
+// SpanEvent is something that happened at a point during a span
+type SpanEvent struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// NewTraceID returns a random trace ID
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+// NewSpanID returns a random span ID
+func NewSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TraceParent formats a W3C traceparent header value
+func TraceParent(traceID, spanID string) string {
+	return fmt.Sprintf("00-%s-%s-01", traceID, spanID)
+}
+
+// ParseTraceParent extracts the trace and parent span IDs from a W3C
+// traceparent value
+func ParseTraceParent(value string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(parts[1] + parts[2]); err != nil {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// Exporter sends finished spans somewhere
+type Exporter interface {
+	Export(spans []Span) error
+}
+
+// TargetFromEnv returns the trace target set by a parent opun process or the
+// standard OTLP endpoint variables, empty when tracing is off
+func TargetFromEnv() string {
+	if target := os.Getenv(TargetEnv); target != "" {
+		return target
+	}
+	if endpoint := os.Getenv(otlpTracesEnv); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv(otlpEndpointEnv); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	return ""
+}
+
+// NewExporter creates an exporter for a target: an http(s) URL of an OTLP
+// traces endpoint (a bare host:port gets /v1/traces), or file:/path to
+// append OTLP JSON, one export request per line
+func NewExporter(target string) (Exporter, error) {
+	switch {
+	case strings.HasPrefix(target, "file:"):
+		path := strings.TrimPrefix(target, "file:")
+		if path == "" {
+			return nil, fmt.Errorf("trace file target needs a path, e.g. file:traces.jsonl")
+		}
+		return &FileExporter{path: path}, nil
+	case strings.HasPrefix(target, "http://"), strings.HasPrefix(target, "https://"):
+		endpoint := target
+		if rest := target[strings.Index(target, "//")+2:]; !strings.Contains(rest, "/") {
+			endpoint += "/v1/traces"
+		}
+		return &HTTPExporter{
+			endpoint: endpoint,
+			headers:  parseHeaders(firstEnv(otlpTracesHeaders, otlpHeadersEnv)),
+			client:   &http.Client{Timeout: exportTimeout},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported trace target %q (use http(s)://host:4318 or file:/path)", target)
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format: key=value pairs
+// separated by commas
+func parseHeaders(raw string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// HTTPExporter posts spans to an OTLP/HTTP endpoint using the JSON encoding
+type HTTPExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// Export sends spans to the endpoint
+func (x *HTTPExporter) Export(spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(EncodeSpans(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, x.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range x.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export traces: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to export traces: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// FileExporter appends OTLP JSON export requests to a file, one per line,
+// the format the OpenTelemetry collector's file receiver reads
+type FileExporter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Export appends spans to the file
+func (x *FileExporter) Export(spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	line, err := json.Marshal(EncodeSpans(spans))
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if dir := filepath.Dir(x.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(x.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// EncodeSpans builds an OTLP ExportTraceServiceRequest in its JSON form
+func EncodeSpans(spans []Span) map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		s := map[string]interface{}{
+			"traceId":           span.TraceID,
+			"spanId":            span.SpanID,
+			"name":              span.Name,
+			"kind":              span.Kind,
+			"startTimeUnixNano": unixNano(span.Start),
+			"endTimeUnixNano":   unixNano(span.End),
+			"attributes":        encodeAttributes(span.Attributes),
+			"status":            map[string]interface{}{"code": statusOK},
+		}
+		if span.ParentSpanID != "" {
+			s["parentSpanId"] = span.ParentSpanID
+		}
+		if span.Error != "" {
+			s["status"] = map[string]interface{}{"code": statusError, "message": span.Error}
+		}
+		if len(span.Events) > 0 {
+			events := make([]map[string]interface{}, 0, len(span.Events))
+			for _, event := range span.Events {
+				events = append(events, map[string]interface{}{
+					"name":         event.Name,
+					"timeUnixNano": unixNano(event.Time),
+					"attributes":   encodeAttributes(event.Attributes),
+				})
+			}
+			s["events"] = events
+		}
+		encoded = append(encoded, s)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": encodeAttributes(map[string]interface{}{
+						"service.name":    "opun",
+						"service.version": ServiceVersion,
+					}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "github.com/rizome-dev/opun", "version": ServiceVersion},
+						"spans": encoded,
+					},
+				},
+			},
+		},
+	}
+}
+
+// unixNano formats a time the way OTLP JSON encodes 64-bit integers
+func unixNano(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// encodeAttributes converts attributes to OTLP key/value pairs, sorted by key
+func encodeAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	encoded := make([]map[string]interface{}, 0, len(keys))
+	for _, k := range keys {
+		var value map[string]interface{}
+		switch v := attrs[k].(type) {
+		case nil:
+			continue
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": k, "value": value})
+	}
+	return encoded
+}
+
+// ToolTracer exports a span for every tool call. Calls join the trace named
+// by TRACEPARENT, which opun sets for the providers an agent starts, so the
+// tool spans become children of the agent's span.
+type ToolTracer struct {
+	exporter Exporter
+	traceID  string
+	parentID string
+}
+
+// ToolTracerFromEnv returns a tool tracer when tracing is configured in the
+// environment, otherwise nil
+func ToolTracerFromEnv() *ToolTracer {
+	target := TargetFromEnv()
+	if target == "" {
+		return nil
+	}
+	exporter, err := NewExporter(target)
+	if err != nil {
+		return nil
+	}
+
+	t := &ToolTracer{exporter: exporter}
+	if traceID, parentID, ok := ParseTraceParent(os.Getenv(TraceParentEnv)); ok {
+		t.traceID, t.parentID = traceID, parentID
+	}
+	return t
+}
+
+// Record exports a span for a finished tool call. Export happens in the
+// background and is best effort, it must never slow down or fail a call.
+func (t *ToolTracer) Record(tool string, duration time.Duration, callErr error) {
+	end := time.Now()
+	traceID := t.traceID
+	if traceID == "" {
+		traceID = NewTraceID()
+	}
+
+	span := Span{
+		TraceID:      traceID,
+		SpanID:       NewSpanID(),
+		ParentSpanID: t.parentID,
+		Name:         "tool " + tool,
+		Kind:         SpanKindInternal,
+		Start:        end.Add(-duration),
+		End:          end,
+		Attributes:   map[string]interface{}{"gen_ai.tool.name": tool},
+	}
+	if callErr != nil {
+		span.Error = callErr.Error()
+	}
+	go func() { _ = t.exporter.Export([]Span{span}) }()
+}
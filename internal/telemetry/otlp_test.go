@@ -0,0 +1,218 @@
+package telemetry
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceParent(t *testing.T) {
+	traceID, spanID := NewTraceID(), NewSpanID()
+	assert.Len(t, traceID, 32)
+	assert.Len(t, spanID, 16)
+
+	gotTrace, gotSpan, ok := ParseTraceParent(TraceParent(traceID, spanID))
+	require.True(t, ok)
+	assert.Equal(t, traceID, gotTrace)
+	assert.Equal(t, spanID, gotSpan)
+
+	for _, bad := range []string{"", "00-abc-def-01", "00-" + traceID + "-zzzzzzzzzzzzzzzz-01"} {
+		_, _, ok := ParseTraceParent(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestTargetFromEnv(t *testing.T) {
+	t.Setenv(TargetEnv, "")
+	t.Setenv(otlpTracesEnv, "")
+	t.Setenv(otlpEndpointEnv, "")
+	assert.Empty(t, TargetFromEnv())
+
+	t.Setenv(otlpEndpointEnv, "http://collector:4318/")
+	assert.Equal(t, "http://collector:4318/v1/traces", TargetFromEnv())
+
+	t.Setenv(otlpTracesEnv, "http://traces:4318/v1/traces")
+	assert.Equal(t, "http://traces:4318/v1/traces", TargetFromEnv())
+
+	t.Setenv(TargetEnv, "file:traces.jsonl")
+	assert.Equal(t, "file:traces.jsonl", TargetFromEnv())
+}
+
+func TestNewExporter(t *testing.T) {
+	exporter, err := NewExporter("http://localhost:4318")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:4318/v1/traces", exporter.(*HTTPExporter).endpoint)
+
+	exporter, err = NewExporter("https://otel.example.com/custom/path")
+	require.NoError(t, err)
+	assert.Equal(t, "https://otel.example.com/custom/path", exporter.(*HTTPExporter).endpoint)
+
+	_, err = NewExporter("file:")
+	assert.Error(t, err)
+	_, err = NewExporter("grpc://localhost:4317")
+	assert.Error(t, err)
+}
+
+func TestEncodeSpans(t *testing.T) {
+	start := time.Unix(100, 0)
+	encoded := EncodeSpans([]Span{{
+		TraceID:      "0af7651916cd43dd8448eb211c80319c",
+		SpanID:       "b7ad6b7169203331",
+		ParentSpanID: "00f067aa0ba902b7",
+		Name:         "agent review",
+		Kind:         SpanKindClient,
+		Start:        start,
+		End:          start.Add(time.Second),
+		Attributes:   map[string]interface{}{"gen_ai.system": "claude", "opun.agent.index": 1, "empty": nil},
+		Events:       []SpanEvent{{Name: "retry", Time: start}},
+		Error:        "provider exited",
+	}})
+
+	data, err := json.Marshal(encoded)
+	require.NoError(t, err)
+	var decoded struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID      string `json:"traceId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Start        string `json:"startTimeUnixNano"`
+					Attributes   []struct {
+						Key   string                 `json:"key"`
+						Value map[string]interface{} `json:"value"`
+					} `json:"attributes"`
+					Events []interface{} `json:"events"`
+					Status struct {
+						Code    int    `json:"code"`
+						Message string `json:"message"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	span := decoded.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+	assert.Equal(t, "100000000000", span.Start)
+	require.Len(t, span.Attributes, 2)
+	assert.Equal(t, "gen_ai.system", span.Attributes[0].Key)
+	assert.Equal(t, "claude", span.Attributes[0].Value["stringValue"])
+	assert.Equal(t, "1", span.Attributes[1].Value["intValue"])
+	assert.Len(t, span.Events, 1)
+	assert.Equal(t, statusError, span.Status.Code)
+	assert.Equal(t, "provider exited", span.Status.Message)
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "run.jsonl")
+	exporter, err := NewExporter("file:" + path)
+	require.NoError(t, err)
+
+	span := Span{TraceID: NewTraceID(), SpanID: NewSpanID(), Name: "workflow test"}
+	require.NoError(t, exporter.Export([]Span{span}))
+	require.NoError(t, exporter.Export([]Span{span}))
+	require.NoError(t, exporter.Export(nil))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := splitLines(string(data))
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"name":"workflow test"`)
+}
+
+func TestHTTPExporter(t *testing.T) {
+	var body []byte
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		auth = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	t.Setenv(otlpTracesHeaders, "")
+	t.Setenv(otlpHeadersEnv, "Authorization=Bearer secret, x-empty")
+	exporter, err := NewExporter(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export([]Span{{TraceID: NewTraceID(), SpanID: NewSpanID(), Name: "agent a"}}))
+	assert.Equal(t, "Bearer secret", auth)
+	assert.Contains(t, string(body), `"name":"agent a"`)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	exporter, err = NewExporter(failing.URL)
+	require.NoError(t, err)
+	err = exporter.Export([]Span{{Name: "agent a"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+}
+
+func TestToolTracer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.jsonl")
+	traceID, parentID := NewTraceID(), NewSpanID()
+
+	t.Setenv(TargetEnv, "")
+	t.Setenv(otlpTracesEnv, "")
+	t.Setenv(otlpEndpointEnv, "")
+	assert.Nil(t, ToolTracerFromEnv())
+
+	t.Setenv(TargetEnv, "file:"+path)
+	t.Setenv(TraceParentEnv, TraceParent(traceID, parentID))
+	tracer := ToolTracerFromEnv()
+	require.NotNil(t, tracer)
+
+	tracer.Record("search", 20*time.Millisecond, errors.New("no results"))
+
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		return err == nil && len(splitLines(string(data))) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"traceId":"`+traceID+`"`)
+	assert.Contains(t, string(data), `"parentSpanId":"`+parentID+`"`)
+	assert.Contains(t, string(data), `"name":"tool search"`)
+	assert.Contains(t, string(data), `"message":"no results"`)
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
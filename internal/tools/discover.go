@@ -0,0 +1,301 @@
+package tools
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Script sources recognised by DiscoverScripts
+const (
+	ScriptSourcePackageJSON = "package.json"
+	ScriptSourceMakefile    = "Makefile"
+	ScriptSourceTaskfile    = "Taskfile"
+)
+
+// DiscoveredScript is a project command that can be registered as an action
+type DiscoveredScript struct {
+	Name        string // script, target or task name
+	Command     string // command line that runs it
+	Description string
+	Source      string // one of the ScriptSource constants
+	File        string // file the script was found in
+}
+
+// npm lifecycle scripts run implicitly by the package manager
+var npmLifecycleScripts = map[string]bool{
+	"install": true, "preinstall": true, "postinstall": true,
+	"prepare": true, "prepublish": true, "prepublishOnly": true,
+	"prepack": true, "postpack": true, "dependencies": true,
+}
+
+var (
+	makeTargetRegex = regexp.MustCompile(`^([^\s:#=%.$][^:#=%$]*?)\s*:([^=].*)?$`)
+	makeDocRegex    = regexp.MustCompile(`##\s*(.+)$`)
+	actionIDRegex   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// DiscoverScripts scans dir for package.json scripts, Makefile targets and
+// Taskfile tasks. A missing file is not an error; a malformed one is.
+func DiscoverScripts(dir string) ([]DiscoveredScript, error) {
+	var scripts []DiscoveredScript
+
+	found, err := discoverPackageScripts(dir)
+	if err != nil {
+		return nil, err
+	}
+	scripts = append(scripts, found...)
+
+	for _, name := range []string{"GNUmakefile", "Makefile", "makefile"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		found, err := discoverMakeTargets(path)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, found...)
+		break
+	}
+
+	for _, name := range []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		found, err := discoverTasks(path)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, found...)
+		break
+	}
+
+	return scripts, nil
+}
+
+// ActionID returns the action ID a script is registered under, e.g. npm-test or make-build
+func (s DiscoveredScript) ActionID() string {
+	prefix := strings.Fields(s.Command)[0]
+	id := strings.ToLower(prefix + "-" + s.Name)
+	return strings.Trim(actionIDRegex.ReplaceAllString(id, "-"), "-")
+}
+
+// Action converts the script into a command action
+func (s DiscoveredScript) Action() core.StandardAction {
+	description := s.Description
+	if description == "" {
+		description = fmt.Sprintf("Run %s from %s", s.Command, s.Source)
+	}
+	return core.StandardAction{
+		ID:          s.ActionID(),
+		Name:        s.Name,
+		Description: description,
+		Category:    "project",
+		Version:     "1.0.0",
+		Command:     s.Command,
+	}
+}
+
+// discoverPackageScripts reads the scripts of a package.json, run with the
+// package manager whose lockfile is present
+func discoverPackageScripts(dir string) ([]DiscoveredScript, error) {
+	path := filepath.Join(dir, "package.json")
+	// #nosec G304 -- reading the project's own package.json
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	runner := "npm run"
+	switch {
+	case fileExistsIn(dir, "pnpm-lock.yaml"):
+		runner = "pnpm run"
+	case fileExistsIn(dir, "yarn.lock"):
+		runner = "yarn run"
+	case fileExistsIn(dir, "bun.lockb"), fileExistsIn(dir, "bun.lock"):
+		runner = "bun run"
+	}
+
+	var scripts []DiscoveredScript
+	for name, command := range pkg.Scripts {
+		if npmLifecycleScripts[name] || isNpmHook(name, pkg.Scripts) {
+			continue
+		}
+		scripts = append(scripts, DiscoveredScript{
+			Name:        name,
+			Command:     runner + " " + name,
+			Description: fmt.Sprintf("Runs `%s`", command),
+			Source:      ScriptSourcePackageJSON,
+			File:        path,
+		})
+	}
+
+	sort.Slice(scripts, func(i, j int) bool { return scripts[i].Name < scripts[j].Name })
+	return scripts, nil
+}
+
+// isNpmHook reports whether name is a pre/post hook of another script
+func isNpmHook(name string, scripts map[string]string) bool {
+	for _, prefix := range []string{"pre", "post"} {
+		if base := strings.TrimPrefix(name, prefix); base != name {
+			if _, ok := scripts[base]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discoverMakeTargets lists the explicit targets of a Makefile. A "## text"
+// comment on the target line, or a comment on the line above, becomes the description.
+func discoverMakeTargets(path string) ([]DiscoveredScript, error) {
+	// #nosec G304 -- reading the project's own Makefile
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := make(map[string]bool)
+	var scripts []DiscoveredScript
+	var comment string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") {
+			comment = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+		if strings.HasPrefix(line, "\t") || strings.TrimSpace(line) == "" {
+			comment = ""
+			continue
+		}
+
+		match := makeTargetRegex.FindStringSubmatch(line)
+		if match == nil {
+			comment = ""
+			continue
+		}
+
+		description := comment
+		if doc := makeDocRegex.FindStringSubmatch(match[2]); doc != nil {
+			description = strings.TrimSpace(doc[1])
+		}
+		comment = ""
+
+		for _, target := range strings.Fields(match[1]) {
+			if seen[target] || strings.ContainsAny(target, "/\\") {
+				continue
+			}
+			seen[target] = true
+			scripts = append(scripts, DiscoveredScript{
+				Name:        target,
+				Command:     "make " + target,
+				Description: description,
+				Source:      ScriptSourceMakefile,
+				File:        path,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return scripts, nil
+}
+
+// discoverTasks lists the public tasks of a go-task Taskfile
+func discoverTasks(path string) ([]DiscoveredScript, error) {
+	// #nosec G304 -- reading the project's own Taskfile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var taskfile struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &taskfile); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if taskfile.Tasks.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var scripts []DiscoveredScript
+	content := taskfile.Tasks.Content
+	for i := 0; i+1 < len(content); i += 2 {
+		name := content[i].Value
+
+		var task struct {
+			Desc     string `yaml:"desc"`
+			Summary  string `yaml:"summary"`
+			Internal bool   `yaml:"internal"`
+		}
+		if content[i+1].Kind == yaml.MappingNode {
+			if err := content[i+1].Decode(&task); err != nil {
+				return nil, fmt.Errorf("failed to parse task %s in %s: %w", name, path, err)
+			}
+		}
+		if task.Internal {
+			continue
+		}
+
+		description := task.Desc
+		if description == "" {
+			description = strings.TrimSpace(strings.SplitN(task.Summary, "\n", 2)[0])
+		}
+		scripts = append(scripts, DiscoveredScript{
+			Name:        name,
+			Command:     "task " + name,
+			Description: description,
+			Source:      ScriptSourceTaskfile,
+			File:        path,
+		})
+	}
+
+	return scripts, nil
+}
+
+func fileExistsIn(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
@@ -0,0 +1,115 @@
+package tools
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverScripts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	write("package.json", `{
+  "name": "app",
+  "scripts": {
+    "build": "tsc -p .",
+    "pretest": "npm run build",
+    "test": "vitest run",
+    "postinstall": "patch-package",
+    "lint:fix": "eslint --fix ."
+  }
+}`)
+	write("pnpm-lock.yaml", "")
+	write("Makefile", `BIN := bin/app
+.PHONY: build test
+
+# Build the binary
+build: deps
+	go build -o $(BIN) ./cmd/app
+
+test: ## Run the unit tests
+	go test ./...
+
+deps lint:
+	go mod download
+
+bin/app: build
+
+%.o: %.c
+	cc -c $<
+`)
+	write("Taskfile.yml", `version: '3'
+tasks:
+  release:
+    desc: Cut a release
+    cmds:
+      - goreleaser release
+  helper:
+    internal: true
+    cmds: [echo hi]
+  fmt: gofmt -w .
+`)
+
+	scripts, err := DiscoverScripts(dir)
+	require.NoError(t, err)
+
+	byID := map[string]DiscoveredScript{}
+	var ids []string
+	for _, s := range scripts {
+		byID[s.ActionID()] = s
+		ids = append(ids, s.ActionID())
+	}
+
+	assert.Equal(t, []string{
+		"pnpm-build", "pnpm-lint-fix", "pnpm-test",
+		"make-build", "make-test", "make-deps", "make-lint",
+		"task-release", "task-fmt",
+	}, ids)
+
+	assert.Equal(t, "pnpm run lint:fix", byID["pnpm-lint-fix"].Command)
+	assert.Equal(t, "Runs `vitest run`", byID["pnpm-test"].Description)
+	assert.Equal(t, "Build the binary", byID["make-build"].Description)
+	assert.Equal(t, "Run the unit tests", byID["make-test"].Description)
+	assert.Equal(t, "", byID["make-deps"].Description)
+	assert.Equal(t, "task release", byID["task-release"].Command)
+	assert.Equal(t, "Cut a release", byID["task-release"].Description)
+
+	action := byID["make-deps"].Action()
+	assert.Equal(t, "make-deps", action.ID)
+	assert.Equal(t, "make deps", action.Command)
+	assert.Equal(t, "project", action.Category)
+	assert.Equal(t, "Run make deps from Makefile", action.Description)
+}
+
+func TestDiscoverScripts_Empty(t *testing.T) {
+	scripts, err := DiscoverScripts(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, scripts)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{"), 0644))
+	_, err = DiscoverScripts(dir)
+	assert.Error(t, err)
+}
@@ -34,9 +34,14 @@ type ToolConfig struct {
 	Category    string `yaml:"category"`
 
 	// Execution method (one of these)
-	Command     string `yaml:"command,omitempty"`
-	WorkflowRef string `yaml:"workflow,omitempty"`
-	PromptRef   string `yaml:"prompt,omitempty"`
+	Command     string            `yaml:"command,omitempty"`
+	WorkflowRef string            `yaml:"workflow,omitempty"`
+	PromptRef   string            `yaml:"prompt,omitempty"`
+	Steps       []core.ActionStep `yaml:"steps,omitempty"`
+
+	// Shared working directory and environment
+	WorkDir string            `yaml:"workdir,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
 
 	// Provider constraints
 	Providers []string `yaml:"providers,omitempty"`
@@ -115,6 +120,9 @@ func (l *Loader) LoadFile(path string) error {
 		Command:     config.Command,
 		WorkflowRef: config.WorkflowRef,
 		PromptRef:   config.PromptRef,
+		Steps:       config.Steps,
+		WorkDir:     config.WorkDir,
+		Env:         config.Env,
 		Providers:   config.Providers,
 	}
 
@@ -134,8 +142,11 @@ func (l *Loader) LoadFile(path string) error {
 	}
 
 	// Ensure at least one execution method is defined
-	if action.Command == "" && action.WorkflowRef == "" && action.PromptRef == "" {
-		return fmt.Errorf("action must have at least one execution method (command, workflow, or prompt)")
+	if action.Command == "" && action.WorkflowRef == "" && action.PromptRef == "" && len(action.Steps) == 0 {
+		return fmt.Errorf("action must have at least one execution method (command, steps, workflow, or prompt)")
+	}
+	if err := ValidateSteps(action.Steps); err != nil {
+		return err
 	}
 
 	// Register the action
@@ -153,6 +164,9 @@ func (l *Loader) SaveAction(action core.StandardAction) error {
 		Command:     action.Command,
 		WorkflowRef: action.WorkflowRef,
 		PromptRef:   action.PromptRef,
+		Steps:       action.Steps,
+		WorkDir:     action.WorkDir,
+		Env:         action.Env,
 		Providers:   action.Providers,
 	}
 
@@ -0,0 +1,322 @@
+package tools
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/expr"
+	"github.com/rizome-dev/opun/pkg/core"
+)
+
+// Step conditions, judged on the outcome of the previous step that ran
+const (
+	StepIfSuccess = "success"
+	StepIfFailure = "failure"
+	StepIfAlways  = "always"
+)
+
+// StepResult records what happened to one step of a multi-step action
+type StepResult struct {
+	Name     string `json:"name"`
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output,omitempty"`
+	Skipped  bool   `json:"skipped,omitempty"`
+	Failed   bool   `json:"failed,omitempty"`
+}
+
+// StepsReport is the outcome of running a multi-step action
+type StepsReport struct {
+	Steps      []StepResult `json:"steps"`
+	RolledBack []StepResult `json:"rolled_back,omitempty"`
+}
+
+// StepRunner executes multi-step actions. Steps share a working directory and
+// environment; when a step fails, the rollback commands of the steps that
+// already completed run in reverse order so the action applies all or nothing.
+type StepRunner struct {
+	WorkDir  string                     // base directory, the action's workdir is resolved against it
+	Timeout  time.Duration              // per step limit, zero means none
+	Output   io.Writer                  // receives step output as it runs, optional
+	Validate func(command string) error // rejects commands before anything runs, optional
+}
+
+// NewStepRunner creates a step runner rooted at workDir
+func NewStepRunner(workDir string) *StepRunner {
+	return &StepRunner{WorkDir: workDir}
+}
+
+// ValidateSteps checks the step definitions of an action
+func ValidateSteps(steps []core.ActionStep) error {
+	for i, step := range steps {
+		if strings.TrimSpace(step.Command) == "" {
+			return fmt.Errorf("step %d has no command", i+1)
+		}
+		switch step.If {
+		case "", StepIfSuccess, StepIfFailure, StepIfAlways:
+		default:
+			program, err := expr.Compile(step.If)
+			if err == nil {
+				err = program.Check(stepConditionNames...)
+			}
+			if err != nil {
+				return fmt.Errorf("step %d: invalid if %q (use success, failure, always or an expression): %w", i+1, step.If, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Run executes the steps of action, passing args to every step through the
+// ARGUMENTS environment variable
+func (r *StepRunner) Run(ctx context.Context, action core.StandardAction, args string) (*StepsReport, error) {
+	if len(action.Steps) == 0 {
+		return nil, fmt.Errorf("action %s has no steps", action.ID)
+	}
+	if err := ValidateSteps(action.Steps); err != nil {
+		return nil, err
+	}
+	if r.Validate != nil {
+		for i, step := range action.Steps {
+			for _, command := range []string{step.Command, step.Rollback} {
+				if command == "" {
+					continue
+				}
+				if err := r.Validate(command); err != nil {
+					return nil, fmt.Errorf("step %s: %w", stepName(step, i), err)
+				}
+			}
+		}
+	}
+
+	dir := r.WorkDir
+	if action.WorkDir != "" {
+		dir = action.WorkDir
+		if !filepath.IsAbs(dir) && r.WorkDir != "" {
+			dir = filepath.Join(r.WorkDir, dir)
+		}
+	}
+
+	env := os.Environ()
+	for k, v := range action.Env {
+		env = append(env, k+"="+v)
+	}
+	env = append(env, "ARGUMENTS="+args)
+
+	report := &StepsReport{}
+	var completed []int
+	prev := StepResult{}
+
+	for i, step := range action.Steps {
+		result := StepResult{Name: stepName(step, i), Command: step.Command}
+
+		run, err := stepShouldRun(step.If, prev, args, dir)
+		if err != nil {
+			stepErr := fmt.Errorf("step %s: %w", result.Name, err)
+			return report, r.rollback(dir, env, action.Steps, completed, report, stepErr)
+		}
+		if !run {
+			result.Skipped = true
+			report.Steps = append(report.Steps, result)
+			continue
+		}
+
+		stepEnv := append(append([]string{}, env...), "OPUN_PREV_EXIT_CODE="+strconv.Itoa(prev.ExitCode))
+		result.Output, result.ExitCode = r.runCommand(ctx, dir, stepEnv, step.Command)
+		ok := exitCodeAllowed(step.ExitCodes, result.ExitCode)
+		result.Failed = !ok
+		report.Steps = append(report.Steps, result)
+		prev = result
+
+		if ok {
+			completed = append(completed, i)
+			continue
+		}
+		if step.ContinueOnError && ctx.Err() == nil {
+			continue
+		}
+
+		stepErr := fmt.Errorf("step %s failed with exit code %d", result.Name, result.ExitCode)
+		if ctx.Err() != nil {
+			stepErr = fmt.Errorf("step %s interrupted: %w", result.Name, ctx.Err())
+		}
+		return report, r.rollback(dir, env, action.Steps, completed, report, stepErr)
+	}
+
+	return report, nil
+}
+
+// rollback undoes the completed steps in reverse order. It runs detached from
+// the action's context so an interrupted action still gets cleaned up.
+func (r *StepRunner) rollback(dir string, env []string, steps []core.ActionStep, completed []int, report *StepsReport, cause error) error {
+	var failures []string
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := steps[completed[i]]
+		if step.Rollback == "" {
+			continue
+		}
+
+		result := StepResult{Name: stepName(step, completed[i]), Command: step.Rollback}
+		result.Output, result.ExitCode = r.runCommand(context.Background(), dir, env, step.Rollback)
+		result.Failed = result.ExitCode != 0
+		report.RolledBack = append(report.RolledBack, result)
+		if result.Failed {
+			failures = append(failures, fmt.Sprintf("%s (exit code %d)", result.Name, result.ExitCode))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w; rollback failed for: %s", cause, strings.Join(failures, ", "))
+	}
+	return cause
+}
+
+// runCommand runs command through the platform shell and returns its combined
+// output and exit code, -1 when it could not be started or was killed
+func (r *StepRunner) runCommand(ctx context.Context, dir string, env []string, command string) (string, int) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	shell, flag := "/bin/sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	var output bytes.Buffer
+	var out io.Writer = &output
+	if r.Output != nil {
+		out = io.MultiWriter(&output, r.Output)
+	}
+
+	// #nosec G204 -- the command comes from the action definition
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err := cmd.Run()
+	if err == nil {
+		return output.String(), 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return output.String(), exitErr.ExitCode()
+	}
+	if output.Len() > 0 {
+		output.WriteString("\n")
+	}
+	output.WriteString(err.Error())
+	return output.String(), -1
+}
+
+// FormatStepsReport renders a report as plain text, one block per step
+func FormatStepsReport(report *StepsReport) string {
+	var b strings.Builder
+	writeStep := func(prefix string, step StepResult) {
+		status := "ok"
+		switch {
+		case step.Skipped:
+			status = "skipped"
+		case step.Failed:
+			status = fmt.Sprintf("failed, exit code %d", step.ExitCode)
+		}
+		fmt.Fprintf(&b, "%s %s (%s): %s\n", prefix, step.Name, status, step.Command)
+		if output := strings.TrimSpace(step.Output); output != "" {
+			b.WriteString(output + "\n")
+		}
+	}
+
+	for _, step := range report.Steps {
+		writeStep("step", step)
+	}
+	for _, step := range report.RolledBack {
+		writeStep("rollback", step)
+	}
+	return b.String()
+}
+
+// stepConditionNames are the names an expression in a step's if can read
+var stepConditionNames = []string{"previous", "args"}
+
+// stepShouldRun evaluates a step's if condition against the previous step.
+// Besides success, failure and always, if can be an expression over
+// previous.success, previous.exit_code, previous.output and args, with file
+// functions confined to the action's directory.
+func stepShouldRun(condition string, prev StepResult, args, dir string) (bool, error) {
+	switch condition {
+	case StepIfAlways:
+		return true, nil
+	case StepIfFailure:
+		return prev.Failed, nil
+	case "", StepIfSuccess:
+		return !prev.Failed, nil
+	}
+
+	run, err := expr.EvalBool(condition, expr.Env{
+		Values: map[string]interface{}{
+			"previous": map[string]interface{}{
+				"name":      prev.Name,
+				"success":   !prev.Failed,
+				"exit_code": prev.ExitCode,
+				"output":    prev.Output,
+			},
+			"args": args,
+		},
+		Dir: dir,
+	})
+	if err != nil {
+		return false, fmt.Errorf("if %q: %w", condition, err)
+	}
+	return run, nil
+}
+
+// exitCodeAllowed reports whether code counts as success for a step
+func exitCodeAllowed(allowed []int, code int) bool {
+	if len(allowed) == 0 {
+		return code == 0
+	}
+	for _, c := range allowed {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func stepName(step core.ActionStep, index int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
@@ -0,0 +1,206 @@
+package tools
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("steps use POSIX shell commands")
+	}
+
+	t.Run("SharedWorkDirAndEnv", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+
+		action := core.StandardAction{
+			ID:      "env",
+			WorkDir: "sub",
+			Env:     map[string]string{"GREETING": "hello"},
+			Steps: []core.ActionStep{
+				{Name: "write", Command: `echo "$GREETING $ARGUMENTS" > out.txt`},
+				{Name: "read", Command: "cat out.txt"},
+			},
+		}
+
+		report, err := NewStepRunner(dir).Run(context.Background(), action, "world")
+		require.NoError(t, err)
+		require.Len(t, report.Steps, 2)
+		assert.Equal(t, "hello world\n", report.Steps[1].Output)
+		assert.FileExists(t, filepath.Join(dir, "sub", "out.txt"))
+	})
+
+	t.Run("RollbackInReverseOrder", func(t *testing.T) {
+		dir := t.TempDir()
+		action := core.StandardAction{
+			ID: "atomic",
+			Steps: []core.ActionStep{
+				{Name: "a", Command: "touch a", Rollback: "rm a && echo a >> undone"},
+				{Name: "b", Command: "touch b", Rollback: "rm b && echo b >> undone"},
+				{Name: "c", Command: "exit 3", Rollback: "echo c >> undone"},
+				{Name: "d", Command: "touch d"},
+			},
+		}
+
+		report, err := NewStepRunner(dir).Run(context.Background(), action, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "step c failed with exit code 3")
+
+		require.Len(t, report.Steps, 3)
+		assert.True(t, report.Steps[2].Failed)
+		assert.Equal(t, 3, report.Steps[2].ExitCode)
+		require.Len(t, report.RolledBack, 2)
+		assert.Equal(t, "b", report.RolledBack[0].Name)
+		assert.Equal(t, "a", report.RolledBack[1].Name)
+
+		undone, err := os.ReadFile(filepath.Join(dir, "undone"))
+		require.NoError(t, err)
+		assert.Equal(t, "b\na\n", string(undone))
+		assert.NoFileExists(t, filepath.Join(dir, "a"))
+		assert.NoFileExists(t, filepath.Join(dir, "d"))
+	})
+
+	t.Run("ExitCodesAndConditions", func(t *testing.T) {
+		dir := t.TempDir()
+		action := core.StandardAction{
+			ID: "conditions",
+			Steps: []core.ActionStep{
+				{Name: "diff", Command: "exit 1", ExitCodes: []int{0, 1}},
+				{Name: "check", Command: "exit 2", ContinueOnError: true},
+				{Name: "on-success", Command: "echo success"},
+				{Name: "on-failure", Command: `echo "failed with $OPUN_PREV_EXIT_CODE"`, If: StepIfFailure},
+				{Name: "always", Command: "echo always", If: StepIfAlways},
+			},
+		}
+
+		report, err := NewStepRunner(dir).Run(context.Background(), action, "")
+		require.NoError(t, err)
+		require.Len(t, report.Steps, 5)
+		assert.False(t, report.Steps[0].Failed)
+		assert.True(t, report.Steps[1].Failed)
+		assert.True(t, report.Steps[2].Skipped)
+		assert.Equal(t, "failed with 2\n", report.Steps[3].Output)
+		assert.Equal(t, "always\n", report.Steps[4].Output)
+	})
+
+	t.Run("ExpressionConditions", func(t *testing.T) {
+		dir := t.TempDir()
+		action := core.StandardAction{
+			ID: "expressions",
+			Steps: []core.ActionStep{
+				{Name: "lint", Command: "echo '3 warnings'; exit 3", ExitCodes: []int{0, 3}},
+				{Name: "report", Command: "echo report > report.txt", If: `previous.exit_code == 3 && previous.output contains "warnings"`},
+				{Name: "strict", Command: "echo strict", If: `args == "--strict"`},
+				{Name: "publish", Command: "echo publish", If: `exists("report.txt")`},
+			},
+		}
+
+		report, err := NewStepRunner(dir).Run(context.Background(), action, "")
+		require.NoError(t, err)
+		require.Len(t, report.Steps, 4)
+		assert.False(t, report.Steps[1].Skipped)
+		assert.True(t, report.Steps[2].Skipped)
+		assert.Equal(t, "publish\n", report.Steps[3].Output)
+
+		action.Steps = []core.ActionStep{
+			{Name: "build", Command: "touch built", Rollback: "rm built"},
+			{Name: "check", Command: "echo check", If: `previous.output > 1`},
+		}
+		_, err = NewStepRunner(dir).Run(context.Background(), action, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "step check: if")
+		assert.NoFileExists(t, filepath.Join(dir, "built"))
+	})
+
+	t.Run("ValidateBeforeRunning", func(t *testing.T) {
+		dir := t.TempDir()
+		runner := NewStepRunner(dir)
+		runner.Validate = func(command string) error {
+			if strings.HasPrefix(command, "rm") {
+				return fmt.Errorf("not allowed")
+			}
+			return nil
+		}
+
+		action := core.StandardAction{
+			ID: "validate",
+			Steps: []core.ActionStep{
+				{Command: "touch created"},
+				{Command: "echo ok", Rollback: "rm created"},
+			},
+		}
+
+		report, err := runner.Run(context.Background(), action, "")
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Contains(t, err.Error(), "step #2")
+		assert.NoFileExists(t, filepath.Join(dir, "created"))
+	})
+}
+
+func TestValidateSteps(t *testing.T) {
+	assert.NoError(t, ValidateSteps([]core.ActionStep{{Command: "true", If: StepIfAlways}}))
+	assert.Error(t, ValidateSteps([]core.ActionStep{{Command: " "}}))
+	assert.NoError(t, ValidateSteps([]core.ActionStep{{Command: "true", If: "previous.exit_code != 0 || args != ''"}}))
+	assert.Error(t, ValidateSteps([]core.ActionStep{{Command: "true", If: "sometimes"}}))
+	assert.Error(t, ValidateSteps([]core.ActionStep{{Command: "true", If: "previous.success &&"}}))
+}
+
+func TestLoader_LoadStepsAction(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "release.yaml")
+	content := `name: Release
+workdir: app
+env:
+  BRANCH: release
+steps:
+  - name: branch
+    command: git checkout -b $BRANCH
+    rollback: git checkout - && git branch -D $BRANCH
+  - command: go generate ./...
+    exit_codes: [0, 1]
+    continue_on_error: true
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	loader := NewLoader(dir)
+	require.NoError(t, loader.LoadFile(path))
+
+	action, err := loader.GetRegistry().Get("release")
+	require.NoError(t, err)
+	assert.Equal(t, "app", action.WorkDir)
+	assert.Equal(t, "release", action.Env["BRANCH"])
+	require.Len(t, action.Steps, 2)
+	assert.Equal(t, "git checkout - && git branch -D $BRANCH", action.Steps[0].Rollback)
+	assert.Equal(t, []int{0, 1}, action.Steps[1].ExitCodes)
+	assert.True(t, action.Steps[1].ContinueOnError)
+
+	require.NoError(t, os.WriteFile(path, []byte("name: Bad\nsteps:\n  - command: true\n    if: maybe\n"), 0644))
+	assert.Error(t, NewLoader(dir).LoadFile(path))
+}
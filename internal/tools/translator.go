@@ -45,7 +45,16 @@ func (t *Translator) ToClaudeMarkdown(action core.StandardAction) (string, error
 	content.WriteString(fmt.Sprintf("%s\n\n", action.Description))
 
 	// Determine how to execute the action
-	if action.Command != "" {
+	if len(action.Steps) > 0 {
+		content.WriteString("## Steps\n\n")
+		content.WriteString("Run all steps through Opun so failures roll back the completed steps:\n")
+		content.WriteString("```bash\n")
+		content.WriteString(fmt.Sprintf("opun action run %s $ARGUMENTS\n", action.ID))
+		content.WriteString("```\n\n")
+		for i, step := range action.Steps {
+			content.WriteString(fmt.Sprintf("%d. `%s`\n", i+1, step.Command))
+		}
+	} else if action.Command != "" {
 		content.WriteString("## Command\n\n")
 		content.WriteString("```bash\n")
 		content.WriteString(action.Command)
@@ -117,7 +126,13 @@ func (t *Translator) ToMCPAction(action core.StandardAction) map[string]interfac
 	}
 
 	// Add execution metadata
-	if action.Command != "" {
+	if len(action.Steps) > 0 {
+		commands := make([]string, len(action.Steps))
+		for i, step := range action.Steps {
+			commands[i] = step.Command
+		}
+		mcpTool["metadata"].(map[string]interface{})["steps"] = commands
+	} else if action.Command != "" {
 		mcpTool["metadata"].(map[string]interface{})["command"] = action.Command
 	} else if action.WorkflowRef != "" {
 		mcpTool["metadata"].(map[string]interface{})["workflow"] = action.WorkflowRef
@@ -17,23 +17,95 @@ package utils
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"os"
+	"sort"
 	"sync"
+	"time"
 )
 
+// Phase orders shutdown handlers. Phases run lowest first so that running
+// work stops before the servers it talks to, and files go last; handlers of
+// the same phase run in reverse order of registration.
+type Phase int
+
+const (
+	// PhaseSessions stops PTY sessions and provider processes
+	PhaseSessions Phase = iota
+	// PhaseServers stops MCP servers and the daemon
+	PhaseServers
+	// PhaseDefault is where RegisterCleanup and RegisterCloser handlers run
+	PhaseDefault
+	// PhaseFiles removes temporary workspaces and files
+	PhaseFiles
+)
+
+const (
+	// DefaultHandlerTimeout bounds a handler registered without a timeout
+	DefaultHandlerTimeout = 3 * time.Second
+
+	// ShutdownTimeout bounds a whole shutdown
+	ShutdownTimeout = 10 * time.Second
+)
+
+// ShutdownFunc releases a resource. It should return once ctx is done.
+type ShutdownFunc func(ctx context.Context) error
+
+// shutdownHandler is a registered ShutdownFunc
+type shutdownHandler struct {
+	id      int
+	name    string
+	phase   Phase
+	timeout time.Duration
+	fn      ShutdownFunc
+}
+
 // CleanupManager manages cleanup functions for graceful shutdown
 type CleanupManager struct {
 	mu       sync.Mutex
-	cleanups []func()
+	cleanups []*shutdownHandler
+	nextID   int
 }
 
 var globalCleanup = &CleanupManager{}
 
-// RegisterCleanup registers a cleanup function to be called on shutdown
-func RegisterCleanup(fn func()) {
+// RegisterShutdown registers a named handler to run on shutdown in phase,
+// given at most timeout (DefaultHandlerTimeout when zero). It returns a
+// function that unregisters the handler, for resources released normally.
+func RegisterShutdown(name string, phase Phase, timeout time.Duration, fn ShutdownFunc) func() {
 	globalCleanup.mu.Lock()
 	defer globalCleanup.mu.Unlock()
-	globalCleanup.cleanups = append(globalCleanup.cleanups, fn)
+
+	globalCleanup.nextID++
+	id := globalCleanup.nextID
+	globalCleanup.cleanups = append(globalCleanup.cleanups, &shutdownHandler{
+		id:      id,
+		name:    name,
+		phase:   phase,
+		timeout: timeout,
+		fn:      fn,
+	})
+
+	return func() {
+		globalCleanup.mu.Lock()
+		defer globalCleanup.mu.Unlock()
+		for i, h := range globalCleanup.cleanups {
+			if h.id == id {
+				globalCleanup.cleanups = append(globalCleanup.cleanups[:i], globalCleanup.cleanups[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// RegisterCleanup registers a cleanup function to be called on shutdown
+func RegisterCleanup(fn func()) {
+	RegisterShutdown("cleanup", PhaseDefault, 0, func(context.Context) error {
+		fn()
+		return nil
+	})
 }
 
 // RegisterCloser registers an io.Closer to be closed on shutdown
@@ -43,16 +115,81 @@ func RegisterCloser(closer io.Closer) {
 	})
 }
 
-// RunCleanup runs all registered cleanup functions
-func RunCleanup() {
+// RegisterTempDir registers a temporary directory to be removed on shutdown
+func RegisterTempDir(path string) func() {
+	return RegisterShutdown("remove "+path, PhaseFiles, 0, func(context.Context) error {
+		return os.RemoveAll(path)
+	})
+}
+
+// Shutdown runs all registered handlers phase by phase and returns their
+// errors. A handler that panics or outlives its timeout is reported and
+// left behind; the rest still run.
+func Shutdown(ctx context.Context) []error {
 	globalCleanup.mu.Lock()
-	defer globalCleanup.mu.Unlock()
+	handlers := globalCleanup.cleanups
+	globalCleanup.cleanups = nil
+	globalCleanup.mu.Unlock()
 
-	// Run cleanups in reverse order (LIFO)
-	for i := len(globalCleanup.cleanups) - 1; i >= 0; i-- {
-		globalCleanup.cleanups[i]()
+	sort.SliceStable(handlers, func(i, j int) bool {
+		if handlers[i].phase != handlers[j].phase {
+			return handlers[i].phase < handlers[j].phase
+		}
+		return handlers[i].id > handlers[j].id
+	})
+
+	var errs []error
+	for _, h := range handlers {
+		if err := runHandler(ctx, h); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
 	}
+	return errs
+}
 
-	// Clear the cleanup list
-	globalCleanup.cleanups = nil
+// runHandler runs one handler within its timeout
+func runHandler(ctx context.Context, h *shutdownHandler) error {
+	timeout := h.timeout
+	if timeout <= 0 {
+		timeout = DefaultHandlerTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("gave up after %s", timeout)
+	}
+}
+
+// RunCleanup runs all registered handlers, reporting failures on stderr
+func RunCleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	for _, err := range Shutdown(ctx) {
+		fmt.Fprintf(os.Stderr, "Cleanup failed: %v\n", err)
+	}
+}
+
+// CleanupOnPanic runs the shutdown handlers when the calling goroutine
+// panics, then panics again. Defer it first thing in main and in goroutines
+// that own sessions or servers.
+func CleanupOnPanic() {
+	if r := recover(); r != nil {
+		RunCleanup()
+		panic(r)
+	}
 }
@@ -17,10 +17,16 @@ package utils
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockCloser struct {
@@ -173,3 +179,80 @@ func TestRegisterCloserWithReadCloser(t *testing.T) {
 
 	assert.True(t, rc.closed)
 }
+
+func TestShutdownPhases(t *testing.T) {
+	// Reset global state
+	globalCleanup.mu.Lock()
+	globalCleanup.cleanups = nil
+	globalCleanup.mu.Unlock()
+
+	var order []string
+	record := func(name string) ShutdownFunc {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	RegisterShutdown("temp dir", PhaseFiles, 0, record("temp dir"))
+	RegisterShutdown("daemon", PhaseServers, 0, record("daemon"))
+	RegisterShutdown("agent a", PhaseSessions, 0, record("agent a"))
+	RegisterCleanup(func() { order = append(order, "cleanup") })
+	RegisterShutdown("agent b", PhaseSessions, 0, record("agent b"))
+	unregister := RegisterShutdown("agent c", PhaseSessions, 0, record("agent c"))
+	unregister()
+
+	assert.Empty(t, Shutdown(context.Background()))
+	assert.Equal(t, []string{"agent b", "agent a", "daemon", "cleanup", "temp dir"}, order)
+}
+
+func TestShutdownFailures(t *testing.T) {
+	// Reset global state
+	globalCleanup.mu.Lock()
+	globalCleanup.cleanups = nil
+	globalCleanup.mu.Unlock()
+
+	ran := false
+	RegisterShutdown("last", PhaseFiles, 0, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	RegisterShutdown("stuck", PhaseServers, 50*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	RegisterShutdown("panics", PhaseSessions, 0, func(context.Context) error {
+		panic("boom")
+	})
+	RegisterShutdown("fails", PhaseSessions, 0, func(context.Context) error {
+		return errors.New("already closed")
+	})
+
+	start := time.Now()
+	errs := Shutdown(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.True(t, ran)
+	require.Len(t, errs, 3)
+	assert.Equal(t, "fails: already closed", errs[0].Error())
+	assert.Equal(t, "panics: panic: boom", errs[1].Error())
+	assert.Contains(t, errs[2].Error(), "stuck: gave up after")
+}
+
+func TestRegisterTempDir(t *testing.T) {
+	// Reset global state
+	globalCleanup.mu.Lock()
+	globalCleanup.cleanups = nil
+	globalCleanup.mu.Unlock()
+
+	dir := filepath.Join(t.TempDir(), "session")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	kept := filepath.Join(t.TempDir(), "kept")
+	require.NoError(t, os.MkdirAll(kept, 0755))
+
+	RegisterTempDir(dir)
+	RegisterTempDir(kept)()
+	RunCleanup()
+
+	assert.NoDirExists(t, dir)
+	assert.DirExists(t, kept)
+}
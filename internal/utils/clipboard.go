@@ -19,6 +19,7 @@ package utils
 import (
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"runtime"
 )
@@ -46,13 +47,15 @@ func (c *SystemClipboard) Copy(text string) error {
 		// macOS
 		cmd = exec.Command("pbcopy")
 	case "linux":
-		// Try different Linux clipboard utilities
-		if _, err := exec.LookPath("xclip"); err == nil {
+		// Try different Linux clipboard utilities, Wayland's first
+		if _, err := exec.LookPath("wl-copy"); err == nil && os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmd = exec.Command("wl-copy")
+		} else if _, err := exec.LookPath("xclip"); err == nil {
 			cmd = exec.Command("xclip", "-selection", "clipboard")
 		} else if _, err := exec.LookPath("xsel"); err == nil {
 			cmd = exec.Command("xsel", "--clipboard", "--input")
 		} else {
-			return fmt.Errorf("no clipboard utility found (xclip or xsel)")
+			return fmt.Errorf("no clipboard utility found (wl-copy, xclip or xsel)")
 		}
 	case "windows":
 		// Windows PowerShell
@@ -90,13 +93,15 @@ func (c *SystemClipboard) Paste() (string, error) {
 		// macOS
 		cmd = exec.Command("pbpaste")
 	case "linux":
-		// Try different Linux clipboard utilities
-		if _, err := exec.LookPath("xclip"); err == nil {
+		// Try different Linux clipboard utilities, Wayland's first
+		if _, err := exec.LookPath("wl-paste"); err == nil && os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmd = exec.Command("wl-paste", "--no-newline")
+		} else if _, err := exec.LookPath("xclip"); err == nil {
 			cmd = exec.Command("xclip", "-selection", "clipboard", "-out")
 		} else if _, err := exec.LookPath("xsel"); err == nil {
 			cmd = exec.Command("xsel", "--clipboard", "--output")
 		} else {
-			return "", fmt.Errorf("no clipboard utility found (xclip or xsel)")
+			return "", fmt.Errorf("no clipboard utility found (wl-paste, xclip or xsel)")
 		}
 	case "windows":
 		// Windows PowerShell
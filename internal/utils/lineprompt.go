@@ -0,0 +1,228 @@
+package utils
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rizome-dev/opun/internal/i18n"
+	"golang.org/x/term"
+)
+
+// NoTUIEnv makes interactive flows use plain line-based prompts, set by --no-tui
+const NoTUIEnv = "OPUN_NO_TUI"
+
+// ErrCancelled is returned when a line prompt is cancelled with q
+var ErrCancelled = errors.New("cancelled")
+
+// PlainUI reports whether interactive flows should use numbered menus and
+// line prompts instead of full-screen TUIs: with --no-tui, on a dumb
+// terminal, or when stdin or stdout isn't a terminal. Plain prompts work
+// with screen readers, in basic shells and with answers piped in.
+func PlainUI() bool {
+	if v := os.Getenv(NoTUIEnv); v != "" && v != "0" && v != "false" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	return !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// LinePrompter asks questions one line at a time
+type LinePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewLinePrompter creates a prompter reading answers from in
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{in: bufio.NewReader(in), out: out}
+}
+
+var (
+	stdPrompter     *LinePrompter
+	stdPrompterOnce sync.Once
+)
+
+// StdPrompter returns the prompter for stdin and stdout. It's shared so
+// input buffered by one question isn't lost to the next.
+func StdPrompter() *LinePrompter {
+	stdPrompterOnce.Do(func() {
+		stdPrompter = NewLinePrompter(os.Stdin, os.Stdout)
+	})
+	return stdPrompter
+}
+
+// readLine reads one answer, failing once input has ended
+func (p *LinePrompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.New(i18n.T("prompt.input_ended"))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Ask asks for a line of text, returning defaultValue for an empty answer
+func (p *LinePrompter) Ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// Confirm asks a yes/no question until it gets an answer
+func (p *LinePrompter) Confirm(question string, defaultYes bool) (bool, error) {
+	hint := "y/N"
+	if defaultYes {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s (%s): ", question, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, i18n.T("prompt.answer_yes_no"))
+	}
+}
+
+// Select shows a numbered menu and returns the index of the chosen option.
+// The answer is a number or an option's text; q cancels.
+func (p *LinePrompter) Select(question string, options []string) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New(i18n.T("prompt.nothing_to_choose"))
+	}
+
+	fmt.Fprintln(p.out, question)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		fmt.Fprint(p.out, i18n.T("prompt.enter_number", len(options)))
+		answer, err := p.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(answer, "q") {
+			return 0, ErrCancelled
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		for i, option := range options {
+			if answer != "" && strings.EqualFold(answer, option) {
+				return i, nil
+			}
+		}
+		fmt.Fprintln(p.out, i18n.T("prompt.not_a_choice", answer))
+	}
+}
+
+// SelectMany shows a numbered menu with the options in selected marked and
+// returns the indexes chosen. The answer is a list of numbers such as
+// "1,3 5", "all" or "none"; an empty answer keeps the marked options.
+func (p *LinePrompter) SelectMany(question string, options []string, selected []bool) ([]int, error) {
+	fmt.Fprintln(p.out, question)
+	var marked []int
+	for i, option := range options {
+		mark := " "
+		if i < len(selected) && selected[i] {
+			mark = "x"
+			marked = append(marked, i)
+		}
+		fmt.Fprintf(p.out, "  %d) [%s] %s\n", i+1, mark, option)
+	}
+
+	for {
+		fmt.Fprint(p.out, i18n.T("prompt.enter_numbers"))
+		answer, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+		indexes, err := parseSelection(answer, len(options), marked)
+		if err == nil {
+			return indexes, nil
+		}
+		if errors.Is(err, ErrCancelled) {
+			return nil, err
+		}
+		fmt.Fprintln(p.out, err)
+	}
+}
+
+// parseSelection parses a SelectMany answer into sorted, unique indexes
+func parseSelection(answer string, count int, marked []int) ([]int, error) {
+	switch strings.ToLower(answer) {
+	case "":
+		return marked, nil
+	case "q":
+		return nil, ErrCancelled
+	case "none":
+		return []int{}, nil
+	case "all":
+		all := make([]int, count)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+
+	chosen := make([]bool, count)
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > count {
+			return nil, errors.New(i18n.T("prompt.not_in_range", field, count))
+		}
+		chosen[n-1] = true
+	}
+
+	indexes := []int{}
+	for i, ok := range chosen {
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
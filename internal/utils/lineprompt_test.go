@@ -0,0 +1,105 @@
+package utils
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinePrompterAsk(t *testing.T) {
+	var out bytes.Buffer
+	p := NewLinePrompter(strings.NewReader("main\n\n"), &out)
+
+	answer, err := p.Ask("Branch", "develop")
+	require.NoError(t, err)
+	assert.Equal(t, "main", answer)
+
+	answer, err = p.Ask("Branch", "develop")
+	require.NoError(t, err)
+	assert.Equal(t, "develop", answer)
+	assert.Equal(t, "Branch [develop]: Branch [develop]: ", out.String())
+
+	_, err = p.Ask("Branch", "")
+	assert.EqualError(t, err, "no answer: input ended")
+}
+
+func TestLinePrompterConfirm(t *testing.T) {
+	var out bytes.Buffer
+	p := NewLinePrompter(strings.NewReader("maybe\nYES\n\n"), &out)
+
+	ok, err := p.Confirm("Delete it?", false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, out.String(), "Please answer y or n.")
+
+	ok, err = p.Confirm("Delete it?", false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLinePrompterSelect(t *testing.T) {
+	var out bytes.Buffer
+	p := NewLinePrompter(strings.NewReader("7\n2\ngemini\nq\n"), &out)
+	options := []string{"claude", "gemini", "qwen"}
+
+	i, err := p.Select("Pick a provider", options)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+	assert.Contains(t, out.String(), "  1) claude\n  2) gemini\n  3) qwen\n")
+	assert.Contains(t, out.String(), `"7" is not one of the choices.`)
+
+	i, err = p.Select("Pick a provider", options)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	_, err = p.Select("Pick a provider", options)
+	assert.ErrorIs(t, err, ErrCancelled)
+}
+
+func TestLinePrompterSelectMany(t *testing.T) {
+	var out bytes.Buffer
+	p := NewLinePrompter(strings.NewReader("\n3, 1 3\nx\nnone\n"), &out)
+	options := []string{"memory", "filesystem", "git"}
+
+	indexes, err := p.SelectMany("Servers", options, []bool{true, false, true})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, indexes)
+	assert.Contains(t, out.String(), "  1) [x] memory\n  2) [ ] filesystem\n")
+
+	indexes, err = p.SelectMany("Servers", options, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, indexes)
+
+	indexes, err = p.SelectMany("Servers", options, nil)
+	require.NoError(t, err)
+	assert.Empty(t, indexes)
+	assert.Contains(t, out.String(), `"x" is not a number between 1 and 3`)
+}
+
+func TestPlainUI(t *testing.T) {
+	t.Setenv(NoTUIEnv, "1")
+	assert.True(t, PlainUI())
+
+	t.Setenv(NoTUIEnv, "")
+	t.Setenv("TERM", "dumb")
+	assert.True(t, PlainUI())
+}
@@ -0,0 +1,134 @@
+package utils
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// LockFile is an exclusive lock held by creating a file. The file holds
+// its holder as JSON with at least a pid field, so a lock left behind by a
+// process that no longer exists can be taken over.
+type LockFile struct {
+	path string
+	data []byte
+}
+
+// lockPID is the part of a lock file every holder writes
+type lockPID struct {
+	PID int `json:"pid"`
+}
+
+// TryLockFile takes the lock at path, writing data, the JSON of its holder,
+// into it. When a live process holds the lock it returns a nil lock and the
+// holder's data, which is empty while that holder is still writing it.
+func TryLockFile(path string, data []byte) (*LockFile, []byte, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, err
+	}
+
+	// A stale lock is removed and taken on the next attempt
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.Write(data)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, nil, fmt.Errorf("failed to write lock %s: %w", path, err)
+			}
+			return &LockFile{path: path, data: data}, nil, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, nil, fmt.Errorf("failed to take lock %s: %w", path, err)
+		}
+
+		// #nosec G304 -- lock files live in the Opun directory
+		current, err := os.ReadFile(path)
+		if err != nil {
+			// Gone already, try again
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, nil
+		}
+		if LockFileAlive(current) {
+			return nil, current, nil
+		}
+		RemoveStaleLockFile(path, current)
+	}
+	// #nosec G304 -- lock files live in the Opun directory
+	current, _ := os.ReadFile(path)
+	return nil, current, nil
+}
+
+// Release gives the lock up. A nil lock is a no-op.
+func (l *LockFile) Release() {
+	if l == nil {
+		return
+	}
+	// Only remove the lock if it's still ours
+	// #nosec G304 -- l.path is the lock file taken in TryLockFile
+	if data, err := os.ReadFile(l.path); err == nil && bytes.Equal(data, l.data) {
+		_ = os.Remove(l.path)
+	}
+}
+
+// LockFileAlive reports whether the process that wrote a lock file's data
+// still runs. Data that can't be read yet, because its holder is still
+// writing it, counts as alive.
+func LockFileAlive(data []byte) bool {
+	var holder lockPID
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return true
+	}
+	return ProcessAlive(holder.PID)
+}
+
+// RemoveStaleLockFile removes a dead process's lock unless another process
+// took it over in the meantime
+func RemoveStaleLockFile(path string, stale []byte) {
+	// #nosec G304 -- lock files live in the Opun directory
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, stale) {
+		_ = os.Remove(path)
+	}
+}
+
+// ProcessAlive reports whether a process with the given PID exists
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// On Windows FindProcess already fails for processes that have exited
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,65 @@
+package utils
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "build.json")
+	mine := []byte(fmt.Sprintf(`{"pid": %d, "name": "first"}`, os.Getpid()))
+
+	lock, held, err := TryLockFile(path, mine)
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	assert.Nil(t, held)
+
+	// A live holder keeps it
+	other, held, err := TryLockFile(path, []byte(`{"pid": 1, "name": "second"}`))
+	require.NoError(t, err)
+	assert.Nil(t, other)
+	assert.Equal(t, mine, held)
+
+	lock.Release()
+	assert.NoFileExists(t, path)
+	lock.Release()
+	(*LockFile)(nil).Release()
+
+	t.Run("Takes Over Stale Locks", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"pid": 999999}`), 0644))
+		lock, held, err := TryLockFile(path, mine)
+		require.NoError(t, err)
+		require.NotNil(t, lock)
+		assert.Nil(t, held)
+		lock.Release()
+	})
+
+	t.Run("Release Leaves Other Holders Alone", func(t *testing.T) {
+		lock, _, err := TryLockFile(path, mine)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(`{"pid": 1}`), 0644))
+		lock.Release()
+		assert.FileExists(t, path)
+	})
+}
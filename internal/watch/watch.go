@@ -0,0 +1,234 @@
+package watch
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+)
+
+const (
+	// DefaultInterval is how often the tree is polled for changes
+	DefaultInterval = 500 * time.Millisecond
+
+	// DefaultDebounce is how long the tree has to be quiet before a trigger
+	DefaultDebounce = 2 * time.Second
+)
+
+// skippedDirs are never watched, on top of hidden directories
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// fileState is what a scan remembers about a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher polls a directory tree for changes to files matching its globs.
+// Polling keeps it dependency free and works the same on every platform and
+// file system, at the cost of noticing changes up to Interval late.
+type Watcher struct {
+	Root     string
+	Interval time.Duration
+	Debounce time.Duration
+
+	// OnChange is called with the changes seen by each poll, before the
+	// debounce period is over. It's optional.
+	OnChange func(changed []string)
+
+	include []*regexp.Regexp
+	ignore  []*regexp.Regexp
+	files   map[string]fileState
+}
+
+// New creates a watcher for the files under root matching any of the
+// include globs, or every file when there are none. Globs are relative to
+// root and use forward slashes, e.g. "**/*.go" or "docs/**".
+func New(root string, include, ignore []string) (*Watcher, error) {
+	w := &Watcher{
+		Root:     root,
+		Interval: DefaultInterval,
+		Debounce: DefaultDebounce,
+	}
+
+	var err error
+	if w.include, err = compileGlobs(include); err != nil {
+		return nil, err
+	}
+	if w.ignore, err = compileGlobs(ignore); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := utils.GlobRegexp(strings.TrimPrefix(filepath.ToSlash(pattern), "./"))
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Scan walks the tree and returns the files added, modified or removed
+// since the previous scan, sorted. The first scan reports every file.
+func (w *Watcher) Scan() ([]string, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(w.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear while we walk, that's a change we'll see next time
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(w.Root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] || w.ignored(rel+"/") || w.ignored(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if w.ignored(rel) || !w.included(rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files[rel] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", w.Root, err)
+	}
+
+	var changed []string
+	for path, state := range files {
+		if old, ok := w.files[path]; !ok || old != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := files[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+
+	w.files = files
+	return changed, nil
+}
+
+// Run polls the tree until ctx is done and calls trigger with the changed
+// files once the tree has been quiet for the debounce period. Changes made
+// while trigger runs are dropped, so a pipeline writing into the watched
+// tree doesn't set itself off again.
+func (w *Watcher) Run(ctx context.Context, trigger func(ctx context.Context, changed []string)) error {
+	if w.files == nil {
+		if _, err := w.Scan(); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+	var quietAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		changed, err := w.Scan()
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 {
+			for _, path := range changed {
+				pending[path] = true
+			}
+			quietAt = time.Now().Add(w.Debounce)
+			if w.OnChange != nil {
+				w.OnChange(changed)
+			}
+		}
+
+		if len(pending) == 0 || time.Now().Before(quietAt) {
+			continue
+		}
+
+		paths := make([]string, 0, len(pending))
+		for path := range pending {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		pending = make(map[string]bool)
+
+		trigger(ctx, paths)
+
+		if _, err := w.Scan(); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Watcher) included(rel string) bool {
+	if len(w.include) == 0 {
+		return true
+	}
+	return matchAny(w.include, rel)
+}
+
+func (w *Watcher) ignored(rel string) bool {
+	return matchAny(w.ignore, rel)
+}
+
+func matchAny(patterns []*regexp.Regexp, rel string) bool {
+	for _, re := range patterns {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
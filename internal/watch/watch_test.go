@@ -0,0 +1,118 @@
+package watch
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.go"), "package main")
+	writeFile(t, filepath.Join(root, "pkg", "util.go"), "package pkg")
+	writeFile(t, filepath.Join(root, "README.md"), "# readme")
+	writeFile(t, filepath.Join(root, "dist", "gen.go"), "package dist")
+	writeFile(t, filepath.Join(root, "pkg", "util_test.go"), "package pkg")
+	writeFile(t, filepath.Join(root, ".git", "hooks.go"), "package git")
+	writeFile(t, filepath.Join(root, "node_modules", "x", "y.go"), "package y")
+
+	w, err := New(root, []string{"**/*.go"}, []string{"dist/**", "**/*_test.go"})
+	require.NoError(t, err)
+
+	changed, err := w.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "pkg/util.go"}, changed)
+
+	changed, err = w.Scan()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	writeFile(t, filepath.Join(root, "pkg", "util.go"), "package pkg // edited")
+	writeFile(t, filepath.Join(root, "cmd", "new.go"), "package cmd")
+	writeFile(t, filepath.Join(root, "README.md"), "# edited")
+	require.NoError(t, os.Remove(filepath.Join(root, "main.go")))
+
+	changed, err = w.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cmd/new.go", "main.go", "pkg/util.go"}, changed)
+}
+
+func TestNewRejectsBadGlob(t *testing.T) {
+	_, err := New(t.TempDir(), []string{"[abc"}, nil)
+	assert.Error(t, err)
+}
+
+func TestRunDebounces(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.txt"), "a")
+
+	w, err := New(root, nil, nil)
+	require.NoError(t, err)
+	w.Interval = 10 * time.Millisecond
+	w.Debounce = 100 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var triggers [][]string
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, func(ctx context.Context, changed []string) {
+			mu.Lock()
+			triggers = append(triggers, changed)
+			mu.Unlock()
+			// Outputs written by the pipeline don't trigger another run
+			writeFile(t, filepath.Join(root, "out.txt"), "output")
+		})
+	}()
+
+	// Let Run take its baseline scan first
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, filepath.Join(root, "a.txt"), "edited")
+	time.Sleep(30 * time.Millisecond)
+	writeFile(t, filepath.Join(root, "b.txt"), "b")
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(triggers) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, triggers, 1)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, triggers[0])
+}
@@ -0,0 +1,81 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// agentPrompt is what an agent's provider receives
+type agentPrompt struct {
+	// Args are the provider flags for the agent's model parameters and
+	// system prompt
+	Args []string
+	// Prompt is the composed prompt, fitted to the context window
+	Prompt string
+	// PolicyText is what the prompt policy checks, the system prompt included
+	PolicyText string
+	// Size is the prompt's estimated size against the provider's budget
+	Size PromptSize
+}
+
+// prepareAgentPrompt takes an agent's processed task prompt through the
+// steps interactive, headless and matrix runs share: model parameter and
+// system prompt flags, findings instructions, the system prompt for providers
+// without a flag and the prompt guard. flags is false for runners that take
+// no provider flags; they get the system prompt ahead of the task instead.
+// The provider's format profile is left to the launch: headless runs apply
+// it in the providers package and interactive runs just before sending.
+func prepareAgentPrompt(agent *workflow.Agent, systemPrompt, prompt string, guard *workflow.PromptGuard, flags bool) (agentPrompt, error) {
+	var p agentPrompt
+
+	// An empty provider puts the system prompt in the prompt
+	flagProvider := ""
+	if flags {
+		flagProvider = agent.Provider
+		p.Args = modelParamArgs(agent)
+		if unsupported := unsupportedModelParams(agent); len(unsupported) > 0 {
+			fmt.Printf("⚠️  %s ignored: not supported by %s\n", strings.Join(unsupported, ", "), agent.Provider)
+		}
+		p.Args = append(p.Args, systemPromptArgs(flagProvider, systemPrompt)...)
+	}
+
+	prompt = withFindingsInstructions(agent, prompt)
+	prompt = withSystemPrompt(flagProvider, systemPrompt, prompt)
+
+	// Make sure the composed prompt fits the provider's context window
+	prompt, size, err := guardPrompt(agent.Provider, prompt, guard)
+	p.Size = size
+	if err != nil {
+		return p, err
+	}
+	if size.Trimmed > 0 {
+		fmt.Printf("✂️  Trimmed %d context block(s) to fit the context window (~%d/%d tokens)\n", size.Trimmed, size.Tokens, size.Budget)
+	} else if size.Budget > 0 && size.Tokens > size.Budget {
+		fmt.Printf("⚠️  Prompt is ~%d tokens, over the %d token budget for %s; the provider may truncate it\n", size.Tokens, size.Budget, agent.Provider)
+	}
+	p.Prompt = prompt
+
+	p.PolicyText = prompt
+	if systemPrompt != "" && nativeSystemPrompt(flagProvider) {
+		p.PolicyText = systemPrompt + "\n\n" + prompt
+	}
+	return p, nil
+}
@@ -0,0 +1,240 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+const (
+	// defaultOutputNudges is how often an agent is reminded to save its output
+	defaultOutputNudges = 1
+	// outputGrace is how long a provider gets to finish writing its output
+	// file after the session ends
+	outputGrace = 2 * time.Second
+)
+
+// validateArtifacts checks an agent's produces contract and output nudges
+func validateArtifacts(agent *workflow.Agent) error {
+	if len(agent.Produces) > 0 && !isProviderStep(agent) {
+		return fmt.Errorf("produces is only supported on agent steps")
+	}
+	if agent.Settings.OutputNudges != nil && *agent.Settings.OutputNudges < 0 {
+		return fmt.Errorf("output_nudges can't be negative")
+	}
+	for _, artifact := range agent.Produces {
+		if strings.TrimSpace(artifact.File) == "" {
+			return fmt.Errorf("produces entries need a file")
+		}
+		if artifact.MinBytes < 0 {
+			return fmt.Errorf("produces %s: min_bytes can't be negative", artifact.File)
+		}
+	}
+	return nil
+}
+
+// artifactRequired reports whether an artifact must exist
+func artifactRequired(artifact workflow.Artifact) bool {
+	return artifact.Required == nil || *artifact.Required
+}
+
+// checkArtifacts returns how the files on disk fall short of the contract,
+// nothing when it's met. expand fills in {{variables}} in the file names.
+func checkArtifacts(produces []workflow.Artifact, expand func(string) string) []string {
+	var problems []string
+	for _, artifact := range produces {
+		path := expand(artifact.File)
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			if artifactRequired(artifact) {
+				problems = append(problems, fmt.Sprintf("%s was not written", path))
+			}
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s can't be read: %v", path, err))
+		case info.IsDir():
+			problems = append(problems, fmt.Sprintf("%s is a directory, not a file", path))
+		case info.Size() < artifact.MinBytes:
+			problems = append(problems, fmt.Sprintf("%s is %d bytes, expected at least %d", path, info.Size(), artifact.MinBytes))
+		}
+	}
+	return problems
+}
+
+// outputNudges returns how many times an agent is reminded to save its output
+func outputNudges(agent *workflow.Agent) int {
+	if agent.Settings.OutputNudges == nil {
+		return defaultOutputNudges
+	}
+	return *agent.Settings.OutputNudges
+}
+
+// waitForOutput reports whether path exists and isn't empty, polling for up
+// to grace while the provider finishes writing
+func waitForOutput(path string, grace time.Duration) bool {
+	deadline := time.Now().Add(grace)
+	for {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Size() > 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// outputReminder asks an agent that didn't save its output to do so
+func outputReminder(path string) string {
+	return fmt.Sprintf("IMPORTANT: a previous attempt at this task ended without saving the results to `%s`. Make sure you write your complete results to that file.", path)
+}
+
+// artifactReminder is added to an agent's prompt when it's run again
+// because its contract wasn't met
+func artifactReminder(problems []string) string {
+	var b strings.Builder
+	b.WriteString("IMPORTANT: a previous attempt at this task finished without writing the expected files:\n")
+	for _, problem := range problems {
+		b.WriteString("- " + problem + "\n")
+	}
+	b.WriteString("Make sure every one of these files is written, with complete content, before you finish.")
+	return b.String()
+}
+
+// executeAgentWithArtifacts runs an agent and checks what it wrote. A
+// missing output file gets the agent nudged to save it up to output_nudges
+// times; an unmet produces contract gets it run again with a reminder up to
+// retry_count times, after which the step fails.
+func (e *InteractiveExecutor) executeAgentWithArtifacts(ctx context.Context, agent *workflow.Agent, agentIndex int) error {
+	started := time.Now()
+	if err := e.runInteractiveAgent(ctx, agent, agentIndex); err != nil {
+		return err
+	}
+	if !isProviderStep(agent) || agent.SubAgent != nil {
+		return nil
+	}
+
+	nudges, retries := 0, 0
+	for {
+		if outputPath := e.agentOutputPath(agent); outputPath != "" && !waitForOutput(outputPath, outputGrace) {
+			if nudges < outputNudges(agent) {
+				nudges++
+				fmt.Printf("\n📝 %s finished without saving %s, reminding it (%d/%d)\n", agent.Name, outputPath, nudges, outputNudges(agent))
+				e.recordDecision(agent, DecisionNudge, "reminded", fmt.Sprintf("%s not saved (%d/%d)", outputPath, nudges, outputNudges(agent)))
+				if err := e.rerunAgent(ctx, agent, agentIndex, started, outputReminder(outputPath), nudges+retries); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("⚠️  %s didn't save its output to %s\n", agent.Name, outputPath)
+			e.recordDecision(agent, DecisionNudge, "gave up", fmt.Sprintf("%s not saved", outputPath))
+		}
+
+		problems := checkArtifacts(agent.Produces, e.expandVariables)
+		if len(problems) == 0 {
+			if len(agent.Produces) > 0 {
+				e.recordDecision(agent, DecisionProduces, "passed", "")
+			}
+			if err := e.scriptOutput(ctx, agent); err != nil {
+				return e.failAgent(agent, err)
+			}
+			if err := e.agentFindings(agent); err != nil {
+				return e.failAgent(agent, err)
+			}
+			return nil
+		}
+
+		fmt.Printf("\n📦 %s didn't produce its expected outputs:\n", agent.Name)
+		for _, problem := range problems {
+			fmt.Printf("   • %s\n", problem)
+		}
+		if retries >= agent.Settings.RetryCount {
+			e.recordDecision(agent, DecisionProduces, "failed", strings.Join(problems, "; "))
+			return e.failAgent(agent, Classify(ErrorGateFailed, fmt.Errorf("expected outputs missing: %s", strings.Join(problems, "; "))))
+		}
+
+		retries++
+		fmt.Printf("🔁 Retrying %s with a reminder (%d/%d)\n", agent.Name, retries, agent.Settings.RetryCount)
+		e.recordDecision(agent, DecisionRetry, fmt.Sprintf("retry %d/%d", retries, agent.Settings.RetryCount), strings.Join(problems, "; "))
+		if err := e.rerunAgent(ctx, agent, agentIndex, started, artifactReminder(problems), nudges+retries); err != nil {
+			return err
+		}
+	}
+}
+
+// failAgent marks an agent whose session already finished as failed
+func (e *InteractiveExecutor) failAgent(agent *workflow.Agent, err error) error {
+	e.mu.Lock()
+	state := e.state.AgentStates[agent.ID]
+	e.mu.Unlock()
+	if state == nil {
+		return err
+	}
+	return e.handleAgentError(agent, state, err)
+}
+
+// rerunAgent runs an agent again with a reminder. Providers that can resume
+// a conversation get just the reminder in the session they left; the others
+// start over with the reminder after the original prompt.
+func (e *InteractiveExecutor) rerunAgent(ctx context.Context, agent *workflow.Agent, agentIndex int, started time.Time, reminder string, rerun int) error {
+	e.emit(workflow.EventAgentRetry, agent.ID, fmt.Sprintf("%s retrying with a reminder", agent.Name), map[string]interface{}{
+		"attempt": rerun + 1,
+	})
+
+	retry := *agent
+	retry.ContinueSession = true
+	e.recordSession(agent, started)
+	if continuesSession(&retry, agent) {
+		retry.Prompt = reminder
+	} else {
+		retry.ContinueSession = agent.ContinueSession
+		retry.Prompt = agent.Prompt + "\n\n" + reminder
+	}
+	if err := e.runInteractiveAgent(ctx, &retry, agentIndex); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if state := e.state.AgentStates[agent.ID]; state != nil {
+		state.Attempts = rerun + 1
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// runWithArtifacts runs a headless agent and, while its produces contract
+// isn't met, runs it again with a reminder up to its retry_count
+func runWithArtifacts(agent *workflow.Agent, prompt string, expand func(string) string, run func(prompt string) (string, error)) (string, error) {
+	output, err := run(prompt)
+	for attempt := 1; err == nil && len(agent.Produces) > 0; attempt++ {
+		problems := checkArtifacts(agent.Produces, expand)
+		if len(problems) == 0 {
+			break
+		}
+		if attempt > agent.Settings.RetryCount {
+			return output, Classify(ErrorGateFailed, fmt.Errorf("expected outputs missing: %s", strings.Join(problems, "; ")))
+		}
+		output, err = run(prompt + "\n\n" + artifactReminder(problems))
+	}
+	return output, err
+}
@@ -0,0 +1,147 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.md"), []byte(strings.Repeat("x", 300)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "short.md"), []byte("tiny"), 0644))
+
+	optional := false
+	expand := func(s string) string {
+		return filepath.Join(dir, substituteVariables(s, map[string]interface{}{"name": "report"}))
+	}
+
+	assert.Empty(t, checkArtifacts([]workflow.Artifact{
+		{File: "{{name}}.md", MinBytes: 200},
+		{File: "notes.md", Required: &optional},
+	}, expand))
+
+	problems := checkArtifacts([]workflow.Artifact{
+		{File: "missing.md"},
+		{File: "short.md", MinBytes: 200},
+		{File: "short.md", Required: &optional, MinBytes: 10},
+	}, expand)
+	require.Len(t, problems, 3)
+	assert.Contains(t, problems[0], "missing.md was not written")
+	assert.Contains(t, problems[1], "4 bytes, expected at least 200")
+	assert.Contains(t, problems[2], "4 bytes, expected at least 10")
+}
+
+func TestValidateArtifacts(t *testing.T) {
+	assert.NoError(t, validateArtifacts(&workflow.Agent{Provider: "claude", Produces: []workflow.Artifact{{File: "report.md"}}}))
+	assert.Error(t, validateArtifacts(&workflow.Agent{Provider: "claude", Produces: []workflow.Artifact{{File: " "}}}))
+	assert.Error(t, validateArtifacts(&workflow.Agent{Provider: "claude", Produces: []workflow.Artifact{{File: "a", MinBytes: -1}}}))
+	assert.Error(t, validateArtifacts(&workflow.Agent{Type: "wait", Duration: "1s", Produces: []workflow.Artifact{{File: "a"}}}))
+
+	negative := -1
+	assert.Error(t, validateArtifacts(&workflow.Agent{Provider: "claude", Settings: workflow.AgentSettings{OutputNudges: &negative}}))
+}
+
+func TestRunWithArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.md")
+	agent := &workflow.Agent{
+		ID:       "writer",
+		Produces: []workflow.Artifact{{File: path}},
+		Settings: workflow.AgentSettings{RetryCount: 1},
+	}
+
+	// The file only appears on the reminded attempt
+	var prompts []string
+	output, err := runWithArtifacts(agent, "write the report", func(s string) string { return s }, func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 2 {
+			require.NoError(t, os.WriteFile(path, []byte("done"), 0644))
+		}
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", output)
+	require.Len(t, prompts, 2)
+	assert.Equal(t, "write the report", prompts[0])
+	assert.Contains(t, prompts[1], "write the report\n\nIMPORTANT")
+	assert.Contains(t, prompts[1], path+" was not written")
+
+	// Without retries left the step fails
+	require.NoError(t, os.Remove(path))
+	agent.Settings.RetryCount = 0
+	calls := 0
+	_, err = runWithArtifacts(agent, "write the report", func(s string) string { return s }, func(string) (string, error) {
+		calls++
+		return "ok", nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected outputs missing")
+	assert.Equal(t, 1, calls)
+}
+
+func TestParseProduces(t *testing.T) {
+	parser := NewParser(t.TempDir())
+	wf, err := parser.Parse([]byte(`
+name: report
+agents:
+  - id: writer
+    provider: claude
+    prompt: Write report.md
+    produces:
+      - file: report.md
+        min_bytes: 200
+      - file: notes.md
+        required: false
+`))
+	require.NoError(t, err)
+	produces := wf.Agents[0].Produces
+	require.Len(t, produces, 2)
+	assert.Equal(t, int64(200), produces[0].MinBytes)
+	assert.True(t, artifactRequired(produces[0]))
+	assert.False(t, artifactRequired(produces[1]))
+
+	_, err = parser.Parse([]byte(`
+name: report
+agents:
+  - id: writer
+    provider: claude
+    prompt: Write report.md
+    produces:
+      - min_bytes: 200
+`))
+	assert.Error(t, err)
+}
+
+func TestOutputNudges(t *testing.T) {
+	agent := &workflow.Agent{Provider: "claude"}
+	assert.Equal(t, defaultOutputNudges, outputNudges(agent))
+
+	none := 0
+	agent.Settings.OutputNudges = &none
+	assert.Equal(t, 0, outputNudges(agent))
+
+	assert.Contains(t, outputReminder("/out/review.md"), "`/out/review.md`")
+}
+
+func TestWaitForOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "review.md")
+	assert.False(t, waitForOutput(path, 0))
+
+	// An empty file doesn't count as saved output
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	assert.False(t, waitForOutput(path, 0))
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("findings"), 0644)
+	}()
+	assert.True(t, waitForOutput(path, 2*time.Second))
+}
@@ -0,0 +1,221 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+)
+
+// attachScrollback is how much recent output is replayed when a client attaches
+const attachScrollback = 64 * 1024
+
+// Frame types sent by attach clients
+const (
+	AttachFrameData   byte = 'd'
+	AttachFrameResize byte = 'r'
+)
+
+// AttachServer exposes the terminal of a detached workflow run on a unix
+// socket. Output is kept in a scrollback buffer and forwarded to the attached
+// client; client input is written to the run's stdin. One client is attached
+// at a time, a new client takes over from the previous one.
+type AttachServer struct {
+	mu         sync.Mutex
+	socketPath string
+	listener   net.Listener
+	client     net.Conn
+	scrollback []byte
+	rows, cols uint16
+	resize     func(rows, cols uint16)
+}
+
+// ListenAttach starts listening for attach clients on socketPath
+func ListenAttach(socketPath string) (*AttachServer, error) {
+	// A stale socket from a crashed run would make Listen fail
+	_ = os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+
+	return &AttachServer{
+		socketPath: socketPath,
+		listener:   listener,
+	}, nil
+}
+
+// SocketPath returns the path clients connect to
+func (s *AttachServer) SocketPath() string {
+	return s.socketPath
+}
+
+// Write records output and forwards it to the attached client, if any
+func (s *AttachServer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scrollback = append(s.scrollback, p...)
+	if len(s.scrollback) > attachScrollback {
+		s.scrollback = s.scrollback[len(s.scrollback)-attachScrollback:]
+	}
+
+	if s.client != nil {
+		if _, err := s.client.Write(p); err != nil {
+			// The client went away, keep running detached
+			s.client.Close()
+			s.client = nil
+		}
+	}
+
+	return len(p), nil
+}
+
+// SetResizeTarget sets the function that applies client terminal sizes.
+// The last known size is applied immediately.
+func (s *AttachServer) SetResizeTarget(fn func(rows, cols uint16)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resize = fn
+	if fn != nil && s.rows > 0 && s.cols > 0 {
+		fn(s.rows, s.cols)
+	}
+}
+
+// Serve accepts attach clients until the server is closed, writing their
+// input to input
+func (s *AttachServer) Serve(input io.Writer) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.client != nil {
+			s.client.Close()
+		}
+		s.client = conn
+		// Replay recent output so the client sees the current screen
+		_, _ = conn.Write(s.scrollback)
+		s.mu.Unlock()
+
+		go s.handleClient(conn, input)
+	}
+}
+
+// handleClient reads frames from a client until it disconnects
+func (s *AttachServer) handleClient(conn net.Conn, input io.Writer) {
+	defer func() {
+		s.mu.Lock()
+		if s.client == conn {
+			s.client = nil
+		}
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		frameType, payload, err := readAttachFrame(conn)
+		if err != nil {
+			return
+		}
+
+		switch frameType {
+		case AttachFrameData:
+			if _, err := input.Write(payload); err != nil {
+				return
+			}
+		case AttachFrameResize:
+			if len(payload) != 4 {
+				continue
+			}
+			s.mu.Lock()
+			s.rows = binary.BigEndian.Uint16(payload[0:2])
+			s.cols = binary.BigEndian.Uint16(payload[2:4])
+			if s.resize != nil {
+				s.resize(s.rows, s.cols)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close stops accepting clients, disconnects the current one and removes the socket
+func (s *AttachServer) Close() error {
+	s.mu.Lock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	_ = os.Remove(s.socketPath)
+	return err
+}
+
+// WriteAttachFrame sends a frame from an attach client to the server
+func WriteAttachFrame(w io.Writer, frameType byte, payload []byte) error {
+	header := make([]byte, 5)
+	header[0] = frameType
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err := w.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AttachResizePayload encodes a terminal size for an AttachFrameResize frame
+func AttachResizePayload(rows, cols uint16) []byte {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint16(payload[0:2], rows)
+	binary.BigEndian.PutUint16(payload[2:4], cols)
+	return payload
+}
+
+// readAttachFrame reads one client frame
+func readAttachFrame(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > 1<<20 {
+		return 0, nil, fmt.Errorf("attach frame too large: %d bytes", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// SetAttachServer makes agent PTYs follow the size of attached clients
+func (e *InteractiveExecutor) SetAttachServer(server *AttachServer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.attach = server
+}
@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAttachServer(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "run.sock")
+	server, err := ListenAttach(socketPath)
+	require.NoError(t, err)
+
+	input := &lockedBuffer{}
+	go server.Serve(input)
+
+	// Output produced before anyone attached is replayed
+	_, err = server.Write([]byte("hello "))
+	require.NoError(t, err)
+
+	resized := make(chan [2]uint16, 1)
+	server.SetResizeTarget(func(rows, cols uint16) {
+		resized <- [2]uint16{rows, cols}
+	})
+
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	replay := make([]byte, len("hello "))
+	_, err = io.ReadFull(conn, replay)
+	require.NoError(t, err)
+	assert.Equal(t, "hello ", string(replay))
+
+	require.NoError(t, WriteAttachFrame(conn, AttachFrameResize, AttachResizePayload(40, 120)))
+	select {
+	case size := <-resized:
+		assert.Equal(t, [2]uint16{40, 120}, size)
+	case <-time.After(2 * time.Second):
+		t.Fatal("resize was not applied")
+	}
+
+	require.NoError(t, WriteAttachFrame(conn, AttachFrameData, []byte("ls\r")))
+	assert.Eventually(t, func() bool { return input.String() == "ls\r" }, 2*time.Second, 10*time.Millisecond)
+
+	// Live output reaches the attached client
+	_, err = server.Write([]byte("world"))
+	require.NoError(t, err)
+	live := make([]byte, len("world"))
+	_, err = io.ReadFull(conn, live)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(live))
+
+	require.NoError(t, server.Close())
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err, "clients are disconnected when the run ends")
+	assert.NoFileExists(t, socketPath)
+}
+
+func TestAttachScrollbackIsBounded(t *testing.T) {
+	server, err := ListenAttach(filepath.Join(t.TempDir(), "run.sock"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	_, _ = server.Write(bytes.Repeat([]byte("a"), attachScrollback))
+	_, _ = server.Write([]byte("tail"))
+	assert.Len(t, server.scrollback, attachScrollback)
+	assert.True(t, bytes.HasSuffix(server.scrollback, []byte("tail")))
+}
@@ -0,0 +1,53 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"embed"
+	"sort"
+	"strings"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// PromptImproveWorkflow is the built-in workflow behind opun prompt improve
+const PromptImproveWorkflow = "prompt-improve"
+
+// PromptImportWorkflow is the built-in workflow behind opun add prompt --from-url --cleanup
+const PromptImportWorkflow = "prompt-import"
+
+// BuiltinWorkflow returns the definition of a workflow shipped with Opun.
+// Workflows installed under the same name take precedence.
+func BuiltinWorkflow(name string) ([]byte, bool) {
+	data, err := builtinFS.ReadFile("builtin/" + name + ".yaml")
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// BuiltinWorkflows lists the names of the workflows shipped with Opun
+func BuiltinWorkflows() []string {
+	entries, _ := builtinFS.ReadDir("builtin")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,24 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinWorkflowsParse(t *testing.T) {
+	names := BuiltinWorkflows()
+	assert.Contains(t, names, PromptImproveWorkflow)
+
+	for _, name := range names {
+		data, ok := BuiltinWorkflow(name)
+		require.True(t, ok)
+		wf, err := NewParser(t.TempDir()).Parse(data)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, wf.Name)
+	}
+
+	_, ok := BuiltinWorkflow("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,555 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// BundleFormat is the layout version of run bundles
+const BundleFormat = 1
+
+// Files of a run bundle
+const (
+	BundleInfoFile       = "bundle.json"
+	BundleWorkflowFile   = "workflow.yaml"
+	BundleManifestFile   = ManifestFile
+	BundleInspectionFile = "inspect.json"
+	BundleLogFile        = "run.log"
+	bundleOutputsDir     = "outputs"
+)
+
+// Limits keeping bundles small enough to attach to an issue
+const (
+	maxBundleFileBytes  = 1 << 20
+	maxBundleTotalBytes = 20 << 20
+)
+
+// BundleInfo describes a run bundle
+type BundleInfo struct {
+	Format      int       `json:"format"`
+	RunID       string    `json:"run_id"`
+	Workflow    string    `json:"workflow"`
+	OpunVersion string    `json:"opun_version"`
+	CreatedAt   time.Time `json:"created_at"`
+	Files       []string  `json:"files"`
+	// Skipped lists the files left out and why
+	Skipped []string `json:"skipped,omitempty"`
+	// Redactions counts the secrets scrubbed from the bundle, by pattern
+	Redactions map[string]int `json:"redactions,omitempty"`
+	Notes      []string       `json:"notes,omitempty"`
+}
+
+// RunBundle is an opened run bundle
+type RunBundle struct {
+	Info     BundleInfo
+	Workflow *workflow.Workflow
+	Manifest RunManifest
+	// Inspection is nil when the run wasn't inspected
+	Inspection *RunInspection
+	Files      map[string][]byte
+}
+
+// ReplayStep compares how an agent ran in the recorded run and in its replay
+type ReplayStep struct {
+	Agent    string `json:"agent"`
+	Name     string `json:"name"`
+	Recorded string `json:"recorded"`
+	Replayed string `json:"replayed"`
+	Output   string `json:"output,omitempty"`
+}
+
+// ReplayReport is the outcome of replaying a bundle
+type ReplayReport struct {
+	Result MatrixResult `json:"result"`
+	Steps  []ReplayStep `json:"steps"`
+	// Changes lists what was changed in the workflow to replay it safely
+	Changes []string `json:"changes,omitempty"`
+}
+
+// bundleSanitizer scrubs secrets and the user's home directory from
+// everything put in a bundle
+type bundleSanitizer struct {
+	redactor *Redactor
+	home     string
+}
+
+func newBundleSanitizer() *bundleSanitizer {
+	redactor, _ := NewRedactor(&workflow.Redact{Enabled: true})
+	home, _ := os.UserHomeDir()
+	if home == "/" {
+		home = ""
+	}
+	return &bundleSanitizer{redactor: redactor, home: home}
+}
+
+func (s *bundleSanitizer) sanitize(text string) string {
+	text, _ = s.redactor.Redact(text)
+	if s.home != "" {
+		text = strings.ReplaceAll(text, s.home, "~")
+	}
+	return text
+}
+
+// bundleFile is a file to archive
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// CreateRunBundle writes a sanitized archive of a recorded run: the workflow
+// it ran, its manifest and inspection, its text outputs and its log. Secrets
+// are redacted with every built-in pattern and the home directory is
+// replaced by ~. fallback is bundled when the run's workflow wasn't kept.
+func CreateRunBundle(archive, runID string, fallback *workflow.Workflow) (*BundleInfo, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+	record, err := LoadRunRecord(dir, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &BundleInfo{
+		Format:      BundleFormat,
+		RunID:       record.RunID,
+		Workflow:    record.Workflow,
+		OpunVersion: OpunVersion,
+		CreatedAt:   time.Now(),
+	}
+	s := newBundleSanitizer()
+	var files []bundleFile
+	var total int
+	add := func(name, text string) {
+		data := []byte(s.sanitize(text))
+		total += len(data)
+		files = append(files, bundleFile{name, data})
+		info.Files = append(info.Files, name)
+	}
+
+	definition, err := loadRunDefinition(runID)
+	if err != nil {
+		return nil, err
+	}
+	if definition == nil {
+		if fallback == nil {
+			return nil, fmt.Errorf("the workflow of run %s was not kept", runID)
+		}
+		definition = fallback
+		if WorkflowHash(fallback) != record.WorkflowHash {
+			info.Notes = append(info.Notes, "workflow.yaml is the current definition, which differs from the one the run used")
+		}
+	}
+	data, err := yaml.Marshal(sanitizeDefinition(definition))
+	if err != nil {
+		return nil, err
+	}
+	add(BundleWorkflowFile, string(data))
+
+	if data, err = json.MarshalIndent(record.RunManifest, "", "  "); err != nil {
+		return nil, err
+	}
+	add(BundleManifestFile, string(data))
+
+	if path, err := InspectionPath(runID); err == nil {
+		// #nosec G304 -- inspections live in the run history
+		if data, err := os.ReadFile(path); err == nil {
+			add(BundleInspectionFile, string(data))
+		}
+	}
+
+	if runsDir, err := RunsDir(); err == nil {
+		// Detached runs log to the runs directory; keep the end of long logs
+		// #nosec G304 -- logs live in the runs directory
+		if data, err := os.ReadFile(filepath.Join(runsDir, pathSafe(runID)+".log")); err == nil {
+			if len(data) > maxBundleFileBytes {
+				data = data[len(data)-maxBundleFileBytes:]
+				info.Notes = append(info.Notes, "run.log was cut to its last 1MB")
+			}
+			add(BundleLogFile, string(data))
+		}
+	}
+
+	for _, output := range bundleOutputs(record) {
+		name := path.Join(bundleOutputsDir, output.rel)
+		data, err := os.ReadFile(output.path)
+		switch {
+		case err != nil:
+			info.Skipped = append(info.Skipped, fmt.Sprintf("%s (%v)", name, err))
+		case len(data) > maxBundleFileBytes:
+			info.Skipped = append(info.Skipped, name+" (larger than 1MB)")
+		case !utf8.Valid(data):
+			info.Skipped = append(info.Skipped, name+" (binary)")
+		case total+len(data) > maxBundleTotalBytes:
+			info.Skipped = append(info.Skipped, name+" (bundle size limit)")
+		default:
+			add(name, string(data))
+		}
+	}
+
+	if report := s.redactor.Report(); report.Total > 0 {
+		info.Redactions = report.Patterns
+	}
+	if data, err = json.MarshalIndent(info, "", "  "); err != nil {
+		return nil, err
+	}
+	files = append([]bundleFile{{BundleInfoFile, data}}, files...)
+	return info, writeBundleArchive(archive, files)
+}
+
+// loadRunDefinition reads the workflow a run ran, nil when it wasn't kept
+func loadRunDefinition(runID string) (*workflow.Workflow, error) {
+	path, err := DefinitionPath(runID)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- definitions live in the run history
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var wf workflow.Workflow
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("invalid workflow of run %s: %w", runID, err)
+	}
+	return &wf, nil
+}
+
+// sanitizeDefinition copies a workflow, hiding the defaults of secret-looking
+// variables like manifests hide their values
+func sanitizeDefinition(wf *workflow.Workflow) *workflow.Workflow {
+	copied := *wf
+	copied.Variables = make([]workflow.Variable, len(wf.Variables))
+	for i, v := range wf.Variables {
+		if v.DefaultValue != nil && secretVariablePattern.MatchString(v.Name) {
+			v.DefaultValue = "[REDACTED]"
+		}
+		copied.Variables[i] = v
+	}
+	return &copied
+}
+
+// bundleOutput is an output file of a run and its path in the bundle
+type bundleOutput struct {
+	path, rel string
+}
+
+// bundleOutputs lists a run's output files: everything in its output
+// directory, or the agents' outputs when it had none
+func bundleOutputs(record *RunRecord) []bundleOutput {
+	var outputs []bundleOutput
+	if record.OutputDir != "" {
+		_ = filepath.WalkDir(record.OutputDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(record.OutputDir, path)
+			// The record's manifest is bundled already
+			if err == nil && rel != ManifestFile {
+				outputs = append(outputs, bundleOutput{path, filepath.ToSlash(rel)})
+			}
+			return nil
+		})
+		return outputs
+	}
+
+	for _, agent := range record.Agents {
+		if agent.Output != "" {
+			outputs = append(outputs, bundleOutput{agent.Output, pathSafe(agent.ID) + "/" + filepath.Base(agent.Output)})
+		}
+	}
+	return outputs
+}
+
+// writeBundleArchive writes files to a tar.gz archive
+func writeBundleArchive(archive string, files []bundleFile) error {
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(len(file.data)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// OpenRunBundle reads a run bundle. Bundles come from other people's
+// machines, so only regular files are read and their size is capped.
+func OpenRunBundle(archive string) (*RunBundle, error) {
+	// #nosec G304 -- archive is the bundle the user asked to open
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a run bundle: %w", archive, err)
+	}
+	tr := tar.NewReader(gz)
+
+	b := &RunBundle{Files: make(map[string][]byte)}
+	var total int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a run bundle: %w", archive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if total += header.Size; header.Size > maxBundleTotalBytes || total > maxBundleTotalBytes {
+			return nil, fmt.Errorf("bundle is larger than %dMB", maxBundleTotalBytes>>20)
+		}
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, tr, header.Size); err != nil {
+			return nil, err
+		}
+		b.Files[path.Clean(header.Name)] = buf.Bytes()
+	}
+
+	if err := b.decode(BundleInfoFile, json.Unmarshal, &b.Info); err != nil {
+		return nil, err
+	}
+	if b.Info.Format > BundleFormat {
+		return nil, fmt.Errorf("bundle format %d is newer than this version of Opun supports", b.Info.Format)
+	}
+	b.Workflow = &workflow.Workflow{}
+	if err := b.decode(BundleWorkflowFile, yaml.Unmarshal, b.Workflow); err != nil {
+		return nil, err
+	}
+	if err := b.decode(BundleManifestFile, json.Unmarshal, &b.Manifest); err != nil {
+		return nil, err
+	}
+	if _, ok := b.Files[BundleInspectionFile]; ok {
+		b.Inspection = &RunInspection{}
+		if err := b.decode(BundleInspectionFile, json.Unmarshal, b.Inspection); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// decode unmarshals one of the bundle's files
+func (b *RunBundle) decode(name string, unmarshal func([]byte, interface{}) error, v interface{}) error {
+	data, ok := b.Files[name]
+	if !ok {
+		return fmt.Errorf("bundle has no %s", name)
+	}
+	if err := unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s in bundle: %w", name, err)
+	}
+	return nil
+}
+
+// Outputs returns the names of the bundled output files
+func (b *RunBundle) Outputs() []string {
+	var names []string
+	for name := range b.Files {
+		if strings.HasPrefix(name, bundleOutputsDir+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MockWorkflow returns the bundled workflow set up to replay on the mock
+// provider, and what was changed. Nothing from the bundle runs on the host:
+// wait conditions, hook scripts and artifact checks are dropped, and every
+// agent runs locally whatever target the bundle names.
+func (b *RunBundle) MockWorkflow() (*workflow.Workflow, []string) {
+	wf := *b.Workflow
+	wf.Matrix = nil
+	wf.Settings.Sandbox = nil
+	wf.Settings.Lock = ""
+	wf.Settings.Storage = nil
+	wf.Settings.Email = nil
+	wf.Settings.Kubernetes = nil
+	dropped := make(map[string]bool)
+	if wf.Settings.Target != "" {
+		dropped["targets"] = true
+		wf.Settings.Target = ""
+	}
+
+	changes := []string{"every agent runs on the mock provider"}
+	wf.Agents = make([]workflow.Agent, len(b.Workflow.Agents))
+	for i, agent := range b.Workflow.Agents {
+		agent.Provider, agent.Model, agent.Requires = "mock", "", nil
+		agent.Sandbox = nil
+		if agent.Target != "" {
+			dropped["targets"] = true
+			agent.Target = ""
+		}
+		if isWaitStep(&agent) {
+			if agent.Until != nil {
+				dropped["wait conditions"] = true
+			}
+			agent.Duration, agent.Until = "0s", nil
+		}
+		if agent.Scripts != nil {
+			dropped["hook scripts"] = true
+			agent.Scripts = nil
+		}
+		if len(agent.Produces) > 0 {
+			dropped["artifact checks"] = true
+			agent.Produces = nil
+		}
+		if isIssueStep(&agent) && agent.Issue != nil {
+			// Replays never file real tickets
+			issue := *agent.Issue
+			issue.DryRun = true
+			agent.Issue = &issue
+			dropped["issue steps"] = true
+		}
+		wf.Agents[i] = agent
+	}
+	for _, what := range []string{"wait conditions", "hook scripts", "artifact checks"} {
+		if dropped[what] {
+			changes = append(changes, what+" are skipped")
+		}
+	}
+	if dropped["issue steps"] {
+		changes = append(changes, "issue steps are dry runs")
+	}
+	if dropped["targets"] {
+		changes = append(changes, "every agent runs locally")
+	}
+	return &wf, changes
+}
+
+// Replay runs the bundled workflow headlessly on the mock provider with the
+// run's variables, writing its outputs to outputDir, so orchestration bugs
+// can be reproduced without provider accounts
+func (b *RunBundle) Replay(ctx context.Context, outputDir string) (*ReplayReport, error) {
+	wf, changes := b.MockWorkflow()
+	// Bundles are decoded as they are; the checks a workflow file gets, such
+	// as outputs staying inside the output directory, apply to the replay.
+	// The hook scripts they'd also look for are already dropped.
+	if err := NewParser("").validate(wf); err != nil {
+		return nil, fmt.Errorf("invalid workflow in bundle: %w", err)
+	}
+	vars := make(map[string]interface{})
+	for _, v := range wf.Variables {
+		if v.DefaultValue != nil {
+			vars[v.Name] = v.DefaultValue
+		}
+	}
+	for name, value := range b.Manifest.Variables {
+		vars[name] = value
+	}
+
+	runner := NewMatrixRunner(outputDir, 1)
+	result, err := runner.RunHeadless(ctx, wf, vars)
+	if err != nil && result.Name == "" {
+		return nil, err
+	}
+
+	recorded := make(map[string]string)
+	for _, agent := range b.Manifest.Agents {
+		recorded[agent.ID] = agent.Status
+	}
+	report := &ReplayReport{Result: result, Changes: changes}
+	stopped := false
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		step := ReplayStep{Agent: agent.ID, Name: agent.Name, Recorded: recorded[agent.ID], Output: result.Outputs[agent.ID]}
+		switch {
+		case stopped:
+			step.Replayed = "not run"
+		case failedReplayStep(result.Error, agent.ID):
+			step.Replayed = string(workflow.StatusFailed)
+			stopped = true
+		case step.Output != "" || isWaitStep(agent) || isInputStep(agent):
+			step.Replayed = string(workflow.StatusCompleted)
+		default:
+			step.Replayed = string(workflow.StatusFailed)
+		}
+		if step.Recorded == "" {
+			step.Recorded = "-"
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	return report, nil
+}
+
+// failedReplayStep reports whether a headless run failed at an agent
+func failedReplayStep(runErr, id string) bool {
+	for _, prefix := range []string{"agent ", "wait step ", "input step "} {
+		if strings.HasPrefix(runErr, prefix+id+": ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Mismatches counts the steps whose replay ended differently from the run
+func (r *ReplayReport) Mismatches() int {
+	n := 0
+	for _, step := range r.Steps {
+		if step.Recorded != step.Replayed {
+			n++
+		}
+	}
+	return n
+}
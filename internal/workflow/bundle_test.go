@@ -0,0 +1,155 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBundle(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	wf := &workflow.Workflow{
+		Name: "review",
+		Variables: []workflow.Variable{
+			{Name: "focus", DefaultValue: "security"},
+			{Name: "api_token", DefaultValue: "abc123"},
+		},
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "claude", Model: "opus", Prompt: "Plan a {{focus}} review"},
+			{ID: "pause", Type: workflow.StepTypeWait, Until: &workflow.WaitCondition{Command: "touch pwned"}},
+			{ID: "fix", Provider: "gemini", Prompt: "Fix {{plan.output}}", Scripts: &workflow.AgentScripts{Prompt: "expand"}},
+		},
+	}
+
+	outputDir := filepath.Join(home, "out")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	key := "sk-ant-" + strings.Repeat("a", 30)
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "plan.md"), []byte("Use "+key+" from "+outputDir), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "image.png"), []byte{0xff, 0xfe, 0x00}, 0644))
+
+	m := newRunManifest(wf, &workflow.ExecutionState{
+		Status:    workflow.StatusCompleted,
+		Variables: map[string]interface{}{"focus": "perf", "api_token": "abc123"},
+		AgentStates: map[string]*workflow.AgentState{
+			"plan":  {Status: workflow.StatusCompleted},
+			"pause": {Status: workflow.StatusCompleted},
+			"fix":   {Status: workflow.StatusFailed},
+		},
+	}, map[string]string{"plan": filepath.Join(outputDir, "plan.md")}, nil, RunInventory{})
+	m.RunID = "7-4000"
+	dir, err := HistoryDir()
+	require.NoError(t, err)
+	require.NoError(t, saveRunRecord(dir, m, outputDir))
+	path, err := DefinitionPath("7-4000")
+	require.NoError(t, err)
+	require.NoError(t, saveRunDefinition(path, wf))
+
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	info, err := CreateRunBundle(archive, "7-4000", nil)
+	require.NoError(t, err)
+	assert.Contains(t, info.Files, BundleWorkflowFile)
+	assert.Contains(t, info.Files, "outputs/plan.md")
+	assert.Equal(t, []string{"outputs/image.png (binary)"}, info.Skipped)
+	assert.Equal(t, 1, info.Redactions["anthropic_key"])
+
+	bundle, err := OpenRunBundle(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "7-4000", bundle.Info.RunID)
+	assert.Equal(t, "[REDACTED]", bundle.Workflow.Variables[1].DefaultValue)
+	assert.Equal(t, "[REDACTED]", bundle.Manifest.Variables["api_token"])
+	assert.Equal(t, []string{"outputs/plan.md"}, bundle.Outputs())
+	plan := string(bundle.Files["outputs/plan.md"])
+	assert.NotContains(t, plan, key)
+	assert.NotContains(t, plan, home)
+	assert.Contains(t, plan, "~/out")
+
+	replayDir := t.TempDir()
+	t.Chdir(replayDir)
+	report, err := bundle.Replay(context.Background(), replayDir)
+	require.NoError(t, err)
+	assert.Contains(t, report.Changes, "wait conditions are skipped")
+	assert.Contains(t, report.Changes, "hook scripts are skipped")
+	require.Len(t, report.Steps, 3)
+	for _, step := range report.Steps {
+		assert.Equal(t, "completed", step.Replayed, step.Agent)
+	}
+	assert.Equal(t, 1, report.Mismatches())
+	assert.NoFileExists(t, filepath.Join(replayDir, "pwned"))
+
+	output, err := os.ReadFile(report.Steps[0].Output)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "Mock response to: Plan a perf review")
+}
+
+func TestCreateRunBundleNeedsWorkflow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir, err := HistoryDir()
+	require.NoError(t, err)
+	require.NoError(t, saveRunRecord(dir, &RunManifest{RunID: "7-4001", Workflow: "review", WorkflowHash: "sha256:old"}, ""))
+
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	_, err = CreateRunBundle(archive, "7-4001", nil)
+	assert.ErrorContains(t, err, "was not kept")
+
+	info, err := CreateRunBundle(archive, "7-4001", &workflow.Workflow{Name: "review"})
+	require.NoError(t, err)
+	require.Len(t, info.Notes, 1)
+	assert.Contains(t, info.Notes[0], "differs")
+}
+
+func TestReplayHostileBundle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	previous := kubectlCommand
+	t.Cleanup(func() { kubectlCommand = previous })
+	var kubectlCalls int
+	kubectlCommand = func(ctx context.Context, stdin []byte, args []string) (string, error) {
+		kubectlCalls++
+		return "", nil
+	}
+
+	root := t.TempDir()
+	replayDir := filepath.Join(root, "a", "b", "replay")
+	t.Chdir(root)
+
+	// A bundle can't pick the cluster, image or secrets a replay uses
+	bundle := &RunBundle{
+		Workflow: &workflow.Workflow{
+			Name: "hostile",
+			Settings: workflow.Settings{
+				Target:     "k8s",
+				Kubernetes: &workflow.Kubernetes{Image: "evil/image", EnvSecret: "prod-keys"},
+			},
+			Agents: []workflow.Agent{{ID: "plan", Provider: "claude", Prompt: "Plan", Target: "k8s://kube-system"}},
+		},
+	}
+	report, err := bundle.Replay(context.Background(), replayDir)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", report.Steps[0].Replayed)
+	assert.Contains(t, report.Changes, "every agent runs locally")
+	assert.Zero(t, kubectlCalls)
+
+	// Outputs can't leave the replay directory, as written or once the
+	// bundle's variables are substituted
+	bundle.Workflow = &workflow.Workflow{
+		Name:   "hostile",
+		Agents: []workflow.Agent{{ID: "plan", Provider: "claude", Prompt: "Plan", Output: "../../pwned.md"}},
+	}
+	_, err = bundle.Replay(context.Background(), replayDir)
+	assert.ErrorContains(t, err, "invalid workflow in bundle")
+
+	bundle.Workflow.Agents[0].Output = "{{d}}/pwned.md"
+	bundle.Manifest.Variables = map[string]interface{}{"d": "../../.."}
+	report, err = bundle.Replay(context.Background(), replayDir)
+	require.NoError(t, err)
+	assert.Contains(t, report.Result.Error, "must be a path inside the output directory")
+	assert.NoFileExists(t, filepath.Join(root, "pwned.md"))
+}
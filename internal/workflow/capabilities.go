@@ -0,0 +1,199 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// providerCapabilities are the capabilities each provider CLI declares.
+// Context sizes are matched separately against the provider's context window.
+var providerCapabilities = map[string][]string{
+	"claude": {"vision", "code-execution", "file-edit", "web-search", "mcp", "session-continuation", "system-prompt", "reasoning", "json-output"},
+	"gemini": {"vision", "code-execution", "file-edit", "web-search", "mcp"},
+	"qwen":   {"code-execution", "file-edit", "mcp"},
+}
+
+// defaultProviderPreference is the order providers are tried in when the
+// user hasn't set one
+var defaultProviderPreference = []string{"claude", "gemini"}
+
+// contextCapabilityPattern matches context size requirements like 200k-context or 1m-context
+var contextCapabilityPattern = regexp.MustCompile(`^(\d+)([km]?)-context$`)
+
+// ProviderSelection configures how providers are chosen for agents that list
+// required capabilities instead of a provider
+type ProviderSelection struct {
+	// Preference is the order providers are considered in
+	Preference []string `mapstructure:"preference" yaml:"preference"`
+	// Costs weighs providers against each other; the cheapest capable provider wins (default 1)
+	Costs map[string]float64 `mapstructure:"costs" yaml:"costs"`
+	// Capabilities adds to the capabilities a provider declares
+	Capabilities map[string][]string `mapstructure:"capabilities" yaml:"capabilities"`
+	// Probed are the capabilities 'opun providers' found the installed
+	// version of each provider to have
+	Probed map[string][]string `mapstructure:"-" yaml:"-"`
+
+	// lookPath reports whether a provider is installed; nil resolves it
+	// through the provider registry
+	lookPath func(string) (string, error)
+}
+
+// validateCapabilities checks an agent only requires known capabilities
+func validateCapabilities(required []string) error {
+	for _, capability := range required {
+		if _, ok := contextCapabilitySize(capability); ok {
+			continue
+		}
+		known := false
+		for _, capabilities := range providerCapabilities {
+			if contains(capabilities, capability) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown capability %q", capability)
+		}
+	}
+	return nil
+}
+
+// contextCapabilitySize returns the context window in tokens a capability
+// like 200k-context requires
+func contextCapabilitySize(capability string) (int, bool) {
+	m := contextCapabilityPattern.FindStringSubmatch(strings.ToLower(capability))
+	if m == nil {
+		return 0, false
+	}
+	size, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	switch m[2] {
+	case "k":
+		size *= 1000
+	case "m":
+		size *= 1000000
+	}
+	return size, true
+}
+
+// missingCapabilities returns the required capabilities a provider lacks
+func (s ProviderSelection) missingCapabilities(provider string, required []string) []string {
+	provider = strings.ToLower(provider)
+	declared := append(append([]string{}, providerCapabilities[provider]...), s.Capabilities[provider]...)
+	declared = append(declared, s.Probed[provider]...)
+
+	var missing []string
+	for _, capability := range required {
+		if size, ok := contextCapabilitySize(capability); ok {
+			if ContextWindow(provider) < size {
+				missing = append(missing, capability)
+			}
+			continue
+		}
+		if !contains(declared, capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
+
+// Select returns the provider for the required capabilities: the cheapest
+// installed provider that has all of them, ties going to the preferred one
+func (s ProviderSelection) Select(required []string) (string, error) {
+	preference := s.Preference
+	if len(preference) == 0 {
+		preference = defaultProviderPreference
+	}
+	lookPath := s.lookPath
+	if lookPath == nil {
+		lookPath = resolveProvider
+	}
+
+	var candidates []string
+	for _, provider := range preference {
+		provider = strings.ToLower(provider)
+		if len(s.missingCapabilities(provider, required)) > 0 {
+			continue
+		}
+		if _, err := lookPath(provider); err != nil {
+			continue
+		}
+		candidates = append(candidates, provider)
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no installed provider has %s (tried %s)", strings.Join(required, ", "), strings.Join(preference, ", "))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return s.cost(candidates[i]) < s.cost(candidates[j])
+	})
+	return candidates[0], nil
+}
+
+// cost returns a provider's cost weight
+func (s ProviderSelection) cost(provider string) float64 {
+	if cost, ok := s.Costs[provider]; ok {
+		return cost
+	}
+	return 1
+}
+
+// SelectProviders fills in the provider of agents that list required
+// capabilities instead of one, and checks agents that set both are capable
+func SelectProviders(wf *workflow.Workflow, selection ProviderSelection) error {
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		if len(agent.Requires) == 0 || !isProviderStep(agent) {
+			continue
+		}
+
+		if agent.Provider != "" {
+			if missing := selection.missingCapabilities(agent.Provider, agent.Requires); len(missing) > 0 {
+				return fmt.Errorf("agent %s: provider %s does not have %s", agent.ID, agent.Provider, strings.Join(missing, ", "))
+			}
+			continue
+		}
+
+		provider, err := selection.Select(agent.Requires)
+		if err != nil {
+			return fmt.Errorf("agent %s: %w", agent.ID, err)
+		}
+		agent.Provider = provider
+		fmt.Printf("🧭 %s: using %s for %s\n", agent.ID, provider, strings.Join(agent.Requires, ", "))
+	}
+	return nil
+}
+
+// resolveProvider finds a provider's command line, with any configured
+// binary and wrapper
+func resolveProvider(provider string) (string, error) {
+	argv, err := providers.ResolveCommand(provider)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(argv, " "), nil
+}
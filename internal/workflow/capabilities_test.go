@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installed returns a lookPath that finds only the given providers
+func installed(providers ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if contains(providers, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	assert.NoError(t, validateCapabilities([]string{"vision", "200k-context", "1m-context"}))
+	assert.EqualError(t, validateCapabilities([]string{"telepathy"}), `unknown capability "telepathy"`)
+}
+
+func TestProviderSelectionSelect(t *testing.T) {
+	selection := ProviderSelection{lookPath: installed("claude", "gemini")}
+
+	provider, err := selection.Select([]string{"vision"})
+	require.NoError(t, err)
+	assert.Equal(t, "claude", provider, "default preference")
+
+	provider, err = selection.Select([]string{"1m-context"})
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", provider, "only gemini has a 1m context window")
+
+	selection.Costs = map[string]float64{"claude": 3}
+	provider, err = selection.Select([]string{"vision"})
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", provider, "cheaper")
+
+	selection = ProviderSelection{Preference: []string{"qwen", "gemini"}, lookPath: installed("qwen", "gemini")}
+	provider, err = selection.Select([]string{"code-execution"})
+	require.NoError(t, err)
+	assert.Equal(t, "qwen", provider, "user preference")
+
+	_, err = selection.Select([]string{"reasoning"})
+	assert.Error(t, err)
+
+	selection.Capabilities = map[string][]string{"qwen": {"reasoning"}}
+	provider, err = selection.Select([]string{"reasoning"})
+	require.NoError(t, err)
+	assert.Equal(t, "qwen", provider, "user-declared capability")
+
+	selection = ProviderSelection{Preference: []string{"qwen", "gemini"}, lookPath: installed("qwen", "gemini")}
+	_, err = selection.Select([]string{"json-output"})
+	assert.Error(t, err)
+	selection.Probed = map[string][]string{"gemini": {"mcp", "json-output"}}
+	provider, err = selection.Select([]string{"json-output"})
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", provider, "probed capability")
+
+	_, err = ProviderSelection{lookPath: installed()}.Select([]string{"vision"})
+	assert.Error(t, err, "nothing installed")
+}
+
+func TestSelectProviders(t *testing.T) {
+	wf := &workflow.Workflow{Agents: []workflow.Agent{
+		{ID: "look", Requires: []string{"vision"}, Prompt: "Describe the screenshot"},
+		{ID: "fixed", Provider: "gemini", Prompt: "Summarize"},
+		{ID: "wait", Type: workflow.StepTypeWait, Duration: "1s"},
+	}}
+	require.NoError(t, SelectProviders(wf, ProviderSelection{lookPath: installed("gemini")}))
+	assert.Equal(t, "gemini", wf.Agents[0].Provider)
+	assert.Equal(t, "gemini", wf.Agents[1].Provider)
+
+	wf = &workflow.Workflow{Agents: []workflow.Agent{
+		{ID: "big", Provider: "claude", Requires: []string{"1m-context"}, Prompt: "Read everything"},
+	}}
+	err := SelectProviders(wf, ProviderSelection{lookPath: installed("claude")})
+	assert.EqualError(t, err, "agent big: provider claude does not have 1m-context")
+}
@@ -0,0 +1,230 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosEnv turns chaos on for runs started without --chaos, e.g. in CI or
+// the background half of a detached run
+const ChaosEnv = "OPUN_CHAOS"
+
+// Kinds of injected failures
+const (
+	ChaosDelay     = "delay"
+	ChaosExit      = "exit"
+	ChaosMalformed = "malformed"
+)
+
+// Defaults used by --chaos without settings
+const (
+	defaultChaosDelay = time.Second
+	defaultChaosRate  = 0.1
+)
+
+// Chaos injects failures into provider calls so retry, gate, failover and
+// resume handling can be exercised: random delays, providers exiting partway
+// through their answer and malformed answers. Rates are probabilities from
+// 0 to 1; a seed makes the faults repeatable.
+type Chaos struct {
+	Seed          int64
+	MaxDelay      time.Duration
+	ExitRate      float64
+	MalformedRate float64
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	events []ChaosEvent
+}
+
+// ChaosEvent is a failure injected into a provider call
+type ChaosEvent struct {
+	Agent    string `json:"agent,omitempty"`
+	Provider string `json:"provider"`
+	Kind     string `json:"kind"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// ParseChaos reads chaos settings such as "delay=2s,exit=0.2,malformed=0.1,seed=42".
+// An empty spec or "default" uses a 1s delay and 10% exits and malformed answers.
+func ParseChaos(spec string) (*Chaos, error) {
+	c := &Chaos{
+		Seed:          time.Now().UnixNano(),
+		MaxDelay:      defaultChaosDelay,
+		ExitRate:      defaultChaosRate,
+		MalformedRate: defaultChaosRate,
+	}
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "default" {
+		c.rng = rand.New(rand.NewSource(c.Seed)) // #nosec G404 -- faults, not secrets
+		return c, nil
+	}
+
+	// Settings given replace the defaults, the rest are off
+	c.MaxDelay, c.ExitRate, c.MalformedRate = 0, 0, 0
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid chaos setting %q, expected key=value", field)
+		}
+		var err error
+		switch key {
+		case "seed":
+			c.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "delay":
+			c.MaxDelay, err = time.ParseDuration(value)
+		case "exit":
+			c.ExitRate, err = parseChaosRate(value)
+		case "malformed":
+			c.MalformedRate, err = parseChaosRate(value)
+		default:
+			return nil, fmt.Errorf("unknown chaos setting %q, expected delay, exit, malformed or seed", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid chaos setting %s: %w", key, err)
+		}
+	}
+	c.rng = rand.New(rand.NewSource(c.Seed)) // #nosec G404 -- faults, not secrets
+	return c, nil
+}
+
+// parseChaosRate reads a probability between 0 and 1
+func parseChaosRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("%v is not between 0 and 1", rate)
+	}
+	return rate, nil
+}
+
+// String describes the settings, e.g. for the run banner
+func (c *Chaos) String() string {
+	return fmt.Sprintf("delay up to %s, %.0f%% exits, %.0f%% malformed answers, seed %d",
+		c.MaxDelay, c.ExitRate*100, c.MalformedRate*100, c.Seed)
+}
+
+// Events returns the failures injected so far
+func (c *Chaos) Events() []ChaosEvent {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ChaosEvent(nil), c.events...)
+}
+
+// draw picks the faults of one provider call
+func (c *Chaos) draw() (delay time.Duration, exit, malformed bool, cut float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.MaxDelay > 0 {
+		delay = time.Duration(c.rng.Int63n(int64(c.MaxDelay)))
+	}
+	exit = c.rng.Float64() < c.ExitRate
+	malformed = c.rng.Float64() < c.MalformedRate
+	return delay, exit, malformed, c.rng.Float64()
+}
+
+// record keeps an injected failure
+func (c *Chaos) record(event ChaosEvent) ChaosEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+	return event
+}
+
+// run calls a headless provider with faults injected, returning the ones it
+// injected
+func (c *Chaos) run(ctx context.Context, run headlessRunner, agent, provider, model, prompt string) (string, []ChaosEvent, error) {
+	delay, exit, malformed, cut := c.draw()
+	var events []ChaosEvent
+
+	if delay > 0 {
+		events = append(events, c.record(ChaosEvent{Agent: agent, Provider: provider, Kind: ChaosDelay, Detail: delay.Round(time.Millisecond).String()}))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", events, ctx.Err()
+		}
+	}
+
+	output, err := run(ctx, provider, model, prompt)
+	if err != nil {
+		return output, events, err
+	}
+
+	switch {
+	case exit:
+		events = append(events, c.record(ChaosEvent{Agent: agent, Provider: provider, Kind: ChaosExit}))
+		return truncateChaos(output, cut), events, fmt.Errorf("%s exited before finishing its answer (chaos)", provider)
+	case malformed:
+		events = append(events, c.record(ChaosEvent{Agent: agent, Provider: provider, Kind: ChaosMalformed}))
+		return malformChaos(output, cut), events, nil
+	}
+	return output, events, nil
+}
+
+// truncateChaos cuts an answer partway through
+func truncateChaos(output string, cut float64) string {
+	runes := []rune(output)
+	return string(runes[:int(float64(len(runes))*cut)])
+}
+
+// malformChaos cuts an answer and appends what a confused provider might
+// print: stray terminal codes and unterminated JSON
+func malformChaos(output string, cut float64) string {
+	return truncateChaos(output, cut) + "\x1b[?1049h�{\"result\": [\"unterminated"
+}
+
+// mockCommand returns the shell script the interactive mock provider runs
+// with faults injected
+func (c *Chaos) mockCommand(agent string) string {
+	delay, exit, malformed, _ := c.draw()
+	var script strings.Builder
+	if delay > 0 {
+		c.record(ChaosEvent{Agent: agent, Provider: "mock", Kind: ChaosDelay, Detail: delay.Round(time.Millisecond).String()})
+		fmt.Fprintf(&script, "sleep %.3f; ", delay.Seconds())
+	}
+	script.WriteString("echo 'Mock provider ready'; ")
+	if malformed {
+		c.record(ChaosEvent{Agent: agent, Provider: "mock", Kind: ChaosMalformed})
+		script.WriteString(`printf '\033[?1049h\357\277\275{"result": ["unterminated\n'; `)
+	}
+	if exit {
+		c.record(ChaosEvent{Agent: agent, Provider: "mock", Kind: ChaosExit})
+		// Exit once the prompt arrives, as a provider crashing mid-session would
+		script.WriteString("read -r line; echo 'Mock provider exited mid-session (chaos)'; exit 3")
+	} else {
+		script.WriteString("cat")
+	}
+	return script.String()
+}
+
+// SetChaos injects failures into the run's mock provider sessions
+func (e *InteractiveExecutor) SetChaos(c *Chaos) {
+	e.chaos = c
+}
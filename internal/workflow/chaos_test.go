@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChaos(t *testing.T) {
+	c, err := ParseChaos("")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, c.MaxDelay)
+	assert.Equal(t, 0.1, c.ExitRate)
+
+	c, err = ParseChaos("exit=0.5, seed=7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), c.Seed)
+	assert.Equal(t, 0.5, c.ExitRate)
+	assert.Zero(t, c.MaxDelay)
+	assert.Zero(t, c.MalformedRate)
+
+	for _, spec := range []string{"exit", "exit=2", "delay=soon", "crash=0.1"} {
+		_, err := ParseChaos(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestChaosRun(t *testing.T) {
+	answer := func(ctx context.Context, provider, model, prompt string) (string, error) {
+		return `{"result": "done"}`, nil
+	}
+
+	c, err := ParseChaos("exit=1,seed=1")
+	require.NoError(t, err)
+	output, events, err := c.run(context.Background(), answer, "plan", "mock", "", "Plan")
+	assert.ErrorContains(t, err, "mock exited before finishing its answer (chaos)")
+	assert.Less(t, len(output), len(`{"result": "done"}`))
+	require.Len(t, events, 1)
+	assert.Equal(t, ChaosEvent{Agent: "plan", Provider: "mock", Kind: ChaosExit}, events[0])
+
+	c, err = ParseChaos("malformed=1,seed=1")
+	require.NoError(t, err)
+	output, events, err = c.run(context.Background(), answer, "plan", "mock", "", "Plan")
+	require.NoError(t, err)
+	_, jsonErr := parseOutputJSON(output)
+	assert.Error(t, jsonErr)
+	assert.Equal(t, ChaosMalformed, events[0].Kind)
+
+	// Provider errors pass through untouched
+	failing := func(ctx context.Context, provider, model, prompt string) (string, error) {
+		return "", errors.New("not logged in")
+	}
+	_, events, err = c.run(context.Background(), failing, "plan", "mock", "", "Plan")
+	assert.EqualError(t, err, "not logged in")
+	assert.Empty(t, events)
+	assert.Len(t, c.Events(), 1)
+
+	// Delays give up when the run is cancelled
+	c, err = ParseChaos("delay=1h,seed=1")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, events, err = c.run(ctx, answer, "plan", "mock", "", "Plan")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, ChaosDelay, events[0].Kind)
+}
+
+func TestChaosMockCommand(t *testing.T) {
+	c, err := ParseChaos("exit=1,malformed=1,seed=1")
+	require.NoError(t, err)
+	script := c.mockCommand("plan")
+	assert.Contains(t, script, "Mock provider ready")
+	assert.Contains(t, script, "unterminated")
+	assert.Contains(t, script, "exit 3")
+	assert.NotContains(t, script, "cat")
+	assert.Len(t, c.Events(), 2)
+
+	var nilChaos *Chaos
+	assert.Nil(t, nilChaos.Events())
+}
@@ -0,0 +1,553 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// File comparison statuses
+const (
+	CompareAdded     = "added"
+	CompareRemoved   = "removed"
+	CompareChanged   = "changed"
+	CompareUnchanged = "unchanged"
+)
+
+// maxDiffCells bounds the line diff table; larger files are only reported as changed
+const maxDiffCells = 4_000_000
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+// RunComparison is the difference between two runs of a workflow
+type RunComparison struct {
+	RunA string `json:"run_a"`
+	RunB string `json:"run_b"`
+	// Changes lists differences in the runs' manifests, e.g. provider versions
+	Changes []string `json:"changes,omitempty"`
+	// Environment lists differences in the machines and terminals the runs happened in
+	Environment []string         `json:"environment,omitempty"`
+	Files       []FileComparison `json:"files"`
+	Regressions []string         `json:"regressions,omitempty"`
+}
+
+// FileComparison is the difference of one artifact between two runs
+type FileComparison struct {
+	Path        string     `json:"path"`
+	Status      string     `json:"status"`
+	Diff        string     `json:"diff,omitempty"`
+	JSONChanges []string   `json:"json_changes,omitempty"`
+	Tests       *TestDelta `json:"tests,omitempty"`
+}
+
+// TestDelta compares the test results in a JUnit XML artifact
+type TestDelta struct {
+	PassedA      int      `json:"passed_a"`
+	FailedA      int      `json:"failed_a"`
+	PassedB      int      `json:"passed_b"`
+	FailedB      int      `json:"failed_b"`
+	NewlyFailing []string `json:"newly_failing,omitempty"`
+	NewlyPassing []string `json:"newly_passing,omitempty"`
+}
+
+// HasRegressions reports whether run B is worse than run A
+func (c *RunComparison) HasRegressions() bool {
+	return len(c.Regressions) > 0
+}
+
+// CompareRuns compares the artifacts of two run output directories
+func CompareRuns(dirA, dirB string) (*RunComparison, error) {
+	filesA, err := runArtifacts(dirA)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := runArtifacts(dirB)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &RunComparison{RunA: dirA, RunB: dirB}
+	c.Changes, c.Environment = compareManifests(filepath.Join(dirA, ManifestFile), filepath.Join(dirB, ManifestFile))
+
+	paths := make(map[string]bool)
+	for path := range filesA {
+		paths[path] = true
+	}
+	for path := range filesB {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	for _, path := range sorted {
+		file := FileComparison{Path: path}
+		switch {
+		case !filesA[path]:
+			file.Status = CompareAdded
+		case !filesB[path]:
+			file.Status = CompareRemoved
+			c.Regressions = append(c.Regressions, fmt.Sprintf("%s is missing from %s", path, dirB))
+		default:
+			a, err := os.ReadFile(filepath.Join(dirA, path))
+			if err != nil {
+				return nil, err
+			}
+			b, err := os.ReadFile(filepath.Join(dirB, path))
+			if err != nil {
+				return nil, err
+			}
+			c.Regressions = append(c.Regressions, compareArtifact(&file, a, b)...)
+		}
+		c.Files = append(c.Files, file)
+	}
+	return c, nil
+}
+
+// runArtifacts lists the files of a run directory, relative to it
+func runArtifacts(dir string) (map[string]bool, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a run directory", dir)
+	}
+
+	files := make(map[string]bool)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		// The manifest is compared separately
+		if rel == ManifestFile || strings.HasSuffix(rel, ".tmp") {
+			return nil
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return files, err
+}
+
+// compareArtifact fills in how an artifact changed and returns any regressions
+func compareArtifact(file *FileComparison, a, b []byte) []string {
+	if bytes.Equal(a, b) {
+		file.Status = CompareUnchanged
+		return nil
+	}
+	file.Status = CompareChanged
+
+	var regressions []string
+	if suitesA, okA := parseJUnit(a); okA {
+		if suitesB, okB := parseJUnit(b); okB {
+			file.Tests = compareTests(suitesA, suitesB)
+			for _, name := range file.Tests.NewlyFailing {
+				regressions = append(regressions, fmt.Sprintf("%s: test %s now fails", file.Path, name))
+			}
+			return regressions
+		}
+	}
+
+	var jsonA, jsonB interface{}
+	if json.Unmarshal(a, &jsonA) == nil && json.Unmarshal(b, &jsonB) == nil {
+		var changes []jsonChange
+		diffJSON("$", jsonA, jsonB, &changes)
+		for _, change := range changes {
+			file.JSONChanges = append(file.JSONChanges, change.String())
+			if change.isRegression() {
+				regressions = append(regressions, fmt.Sprintf("%s: %s", file.Path, change))
+			}
+		}
+		return regressions
+	}
+
+	file.Diff = UnifiedDiff(string(a), string(b))
+	return nil
+}
+
+// jsonChange is one structural difference between two JSON documents
+type jsonChange struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+	// Added and Removed mark keys or items that exist on one side only
+	Added, Removed bool
+}
+
+func (c jsonChange) String() string {
+	switch {
+	case c.Added:
+		return fmt.Sprintf("+ %s = %s", c.Path, compactJSON(c.New))
+	case c.Removed:
+		return fmt.Sprintf("- %s = %s", c.Path, compactJSON(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s → %s", c.Path, compactJSON(c.Old), compactJSON(c.New))
+	}
+}
+
+var (
+	successValues = map[string]bool{"completed": true, "passed": true, "pass": true, "success": true, "succeeded": true, "ok": true}
+	failureValues = map[string]bool{"failed": true, "fail": true, "failure": true, "error": true, "aborted": true}
+)
+
+// isRegression reports whether a value went from a success state to a failure state
+func (c jsonChange) isRegression() bool {
+	old, okOld := c.Old.(string)
+	now, okNew := c.New.(string)
+	return okOld && okNew && successValues[strings.ToLower(old)] && failureValues[strings.ToLower(now)]
+}
+
+// diffJSON records the structural differences between two decoded JSON values
+func diffJSON(path string, a, b interface{}, changes *[]jsonChange) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			childPath := path + "." + k
+			oldValue, inA := av[k]
+			newValue, inB := bv[k]
+			switch {
+			case !inA:
+				*changes = append(*changes, jsonChange{Path: childPath, New: newValue, Added: true})
+			case !inB:
+				*changes = append(*changes, jsonChange{Path: childPath, Old: oldValue, Removed: true})
+			default:
+				diffJSON(childPath, oldValue, newValue, changes)
+			}
+		}
+		return
+
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				*changes = append(*changes, jsonChange{Path: childPath, New: bv[i], Added: true})
+			case i >= len(bv):
+				*changes = append(*changes, jsonChange{Path: childPath, Old: av[i], Removed: true})
+			default:
+				diffJSON(childPath, av[i], bv[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, jsonChange{Path: path, Old: a, New: b})
+	}
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// junitSuites is the subset of JUnit XML needed to compare test results
+type junitSuites struct {
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name  string      `xml:"name,attr"`
+	Cases []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string    `xml:"name,attr"`
+	ClassName string    `xml:"classname,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+// parseJUnit parses a JUnit XML report, with or without a testsuites root.
+// It returns test names mapped to whether they passed; skipped tests are left out.
+func parseJUnit(data []byte) (map[string]bool, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("<")) || !bytes.Contains(trimmed, []byte("<testsuite")) {
+		return nil, false
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal(trimmed, &suites); err != nil || len(suites.Suites) == 0 {
+		var suite junitSuite
+		if err := xml.Unmarshal(trimmed, &suite); err != nil {
+			return nil, false
+		}
+		suites.Suites = []junitSuite{suite}
+	}
+
+	results := make(map[string]bool)
+	for _, suite := range suites.Suites {
+		for _, tc := range suite.Cases {
+			if tc.Skipped != nil {
+				continue
+			}
+			name := tc.Name
+			if tc.ClassName != "" {
+				name = tc.ClassName + "." + tc.Name
+			}
+			results[name] = tc.Failure == nil && tc.Error == nil
+		}
+	}
+	return results, true
+}
+
+// compareTests compares two sets of test results
+func compareTests(a, b map[string]bool) *TestDelta {
+	delta := &TestDelta{}
+	for _, passed := range a {
+		if passed {
+			delta.PassedA++
+		} else {
+			delta.FailedA++
+		}
+	}
+	for name, passed := range b {
+		if passed {
+			delta.PassedB++
+		} else {
+			delta.FailedB++
+		}
+		before, existed := a[name]
+		switch {
+		case !passed && (!existed || before):
+			delta.NewlyFailing = append(delta.NewlyFailing, name)
+		case passed && existed && !before:
+			delta.NewlyPassing = append(delta.NewlyPassing, name)
+		}
+	}
+	sort.Strings(delta.NewlyFailing)
+	sort.Strings(delta.NewlyPassing)
+	return delta
+}
+
+// compareManifests describes what changed between two run manifests, and
+// between the environments the runs happened in
+func compareManifests(pathA, pathB string) ([]string, []string) {
+	a, errA := readManifest(pathA)
+	b, errB := readManifest(pathB)
+	if errA != nil || errB != nil {
+		return nil, nil
+	}
+
+	var changes []string
+	changed := func(what string, old, now interface{}) {
+		if !reflect.DeepEqual(old, now) {
+			changes = append(changes, fmt.Sprintf("%s: %v → %v", what, old, now))
+		}
+	}
+
+	changed("workflow", a.Workflow, b.Workflow)
+	changed("workflow hash", a.WorkflowHash, b.WorkflowHash)
+	changed("opun version", a.OpunVersion, b.OpunVersion)
+	changed("status", a.Status, b.Status)
+
+	providers := make(map[string]bool)
+	for name := range a.Providers {
+		providers[name] = true
+	}
+	for name := range b.Providers {
+		providers[name] = true
+	}
+	for _, name := range sortedKeys(providers) {
+		changed(name+" version", a.Providers[name], b.Providers[name])
+	}
+
+	models := func(m *RunManifest) map[string]string {
+		byAgent := make(map[string]string)
+		for _, agent := range m.Agents {
+			byAgent[agent.ID] = agent.Provider + "/" + agent.Model
+		}
+		return byAgent
+	}
+	modelsA, modelsB := models(a), models(b)
+	agents := make(map[string]bool)
+	for id := range modelsA {
+		agents[id] = true
+	}
+	for id := range modelsB {
+		agents[id] = true
+	}
+	for _, id := range sortedKeys(agents) {
+		changed("agent "+id, modelsA[id], modelsB[id])
+	}
+
+	vars := make(map[string]bool)
+	for name := range a.Variables {
+		vars[name] = true
+	}
+	for name := range b.Variables {
+		vars[name] = true
+	}
+	for _, name := range sortedKeys(vars) {
+		changed("variable "+name, a.Variables[name], b.Variables[name])
+	}
+	return changes, CompareEnvironments(a.Environment, b.Environment)
+}
+
+func readManifest(path string) (*RunManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m RunManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UnifiedDiff returns a line diff of two texts in unified format, without file headers
+func UnifiedDiff(a, b string) string {
+	linesA := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	linesB := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	if len(linesA)*len(linesB) > maxDiffCells {
+		return fmt.Sprintf("(too large to diff: %d → %d lines)\n", len(linesA), len(linesB))
+	}
+
+	// Longest common subsequence table, lcs[i][j] for linesA[i:] and linesB[j:]
+	lcs := make([][]int, len(linesA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(linesB)+1)
+	}
+	for i := len(linesA) - 1; i >= 0; i-- {
+		for j := len(linesB) - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// Walk the table into an edit script
+	type edit struct {
+		op   byte // ' ', '-' or '+'
+		line string
+		a, b int // line numbers before the edit
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(linesA) || j < len(linesB) {
+		switch {
+		case i < len(linesA) && j < len(linesB) && linesA[i] == linesB[j]:
+			edits = append(edits, edit{' ', linesA[i], i, j})
+			i++
+			j++
+		case j < len(linesB) && (i == len(linesA) || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{'+', linesB[j], i, j})
+			j++
+		default:
+			edits = append(edits, edit{'-', linesA[i], i, j})
+			i++
+		}
+	}
+
+	// Group changes into hunks with context
+	var sb strings.Builder
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+		from := start - diffContext
+		if from < 0 {
+			from = 0
+		}
+		end := start
+		for k := start; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				end = k
+			} else if k-end > 2*diffContext {
+				break
+			}
+		}
+		to := end + diffContext + 1
+		if to > len(edits) {
+			to = len(edits)
+		}
+
+		countA, countB := 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				countA++
+			}
+			if e.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", edits[from].a+1, countA, edits[from].b+1, countB)
+		for _, e := range edits[from:to] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.line)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRunFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestCompareRuns(t *testing.T) {
+	runA := writeRunFiles(t, map[string]string{
+		"manifest.json": `{"workflow":"review","workflow_hash":"sha256:a","providers":{"claude":"1.0.30"},"agents":[{"id":"plan","provider":"claude","model":"sonnet"}]}`,
+		"plan.md":       "# Plan\none\ntwo\nthree\n",
+		"same.txt":      "unchanged\n",
+		"report.json":   `{"status":"completed","score":7,"tags":["a"]}`,
+		"old.md":        "gone\n",
+	})
+	runB := writeRunFiles(t, map[string]string{
+		"manifest.json": `{"workflow":"review","workflow_hash":"sha256:a","providers":{"claude":"1.0.35"},"agents":[{"id":"plan","provider":"claude","model":"opus"}]}`,
+		"plan.md":       "# Plan\none\n2\nthree\n",
+		"same.txt":      "unchanged\n",
+		"report.json":   `{"status":"failed","score":7,"tags":["a","b"]}`,
+		"new.md":        "fresh\n",
+	})
+
+	c, err := CompareRuns(runA, runB)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"claude version: 1.0.30 → 1.0.35",
+		"agent plan: claude/sonnet → claude/opus",
+	}, c.Changes)
+
+	statuses := make(map[string]string)
+	files := make(map[string]FileComparison)
+	for _, file := range c.Files {
+		statuses[file.Path] = file.Status
+		files[file.Path] = file
+	}
+	assert.Equal(t, map[string]string{
+		"plan.md":     CompareChanged,
+		"same.txt":    CompareUnchanged,
+		"report.json": CompareChanged,
+		"old.md":      CompareRemoved,
+		"new.md":      CompareAdded,
+	}, statuses)
+
+	assert.Equal(t, "@@ -1,4 +1,4 @@\n # Plan\n one\n-two\n+2\n three\n", files["plan.md"].Diff)
+	assert.Equal(t, []string{
+		`~ $.status: "completed" → "failed"`,
+		`+ $.tags[1] = "b"`,
+	}, files["report.json"].JSONChanges)
+
+	assert.True(t, c.HasRegressions())
+	assert.Len(t, c.Regressions, 2)
+	assert.Contains(t, c.Regressions[0], "old.md is missing")
+	assert.Contains(t, c.Regressions[1], "report.json")
+}
+
+func TestCompareJUnit(t *testing.T) {
+	runA := writeRunFiles(t, map[string]string{"junit.xml": `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="pkg">
+    <testcase classname="pkg" name="TestA"/>
+    <testcase classname="pkg" name="TestB"><failure message="boom"/></testcase>
+    <testcase classname="pkg" name="TestC"><skipped/></testcase>
+  </testsuite>
+</testsuites>`})
+	runB := writeRunFiles(t, map[string]string{"junit.xml": `<testsuite name="pkg">
+  <testcase classname="pkg" name="TestA"><failure message="boom"/></testcase>
+  <testcase classname="pkg" name="TestB"/>
+  <testcase classname="pkg" name="TestD"/>
+</testsuite>`})
+
+	c, err := CompareRuns(runA, runB)
+	require.NoError(t, err)
+	require.Len(t, c.Files, 1)
+
+	assert.Equal(t, &TestDelta{
+		PassedA:      1,
+		FailedA:      1,
+		PassedB:      2,
+		FailedB:      1,
+		NewlyFailing: []string{"pkg.TestA"},
+		NewlyPassing: []string{"pkg.TestB"},
+	}, c.Files[0].Tests)
+	assert.Equal(t, []string{"junit.xml: test pkg.TestA now fails"}, c.Regressions)
+}
+
+func TestCompareRunsErrors(t *testing.T) {
+	_, err := CompareRuns(filepath.Join(t.TempDir(), "missing"), t.TempDir())
+	assert.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	_, err = CompareRuns(file, t.TempDir())
+	assert.ErrorContains(t, err, "not a run directory")
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	b := "1\nx\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\ny\n15\n"
+
+	assert.Equal(t, "@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n@@ -11,5 +11,5 @@\n 11\n 12\n 13\n-14\n+y\n 15\n", UnifiedDiff(a, b))
+	assert.Empty(t, UnifiedDiff("same\n", "same\n"))
+}
@@ -0,0 +1,176 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/expr"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// maxConditionOutputBytes is how much of an agent's output a condition sees
+const maxConditionOutputBytes = 1 << 20
+
+// conditionNames are the top-level names every condition can read
+var conditionNames = []string{"vars", "agents", "workflow", "run_id"}
+
+// validateCondition checks that an agent's condition is a valid expression
+// that only reads names conditions provide
+func validateCondition(wf *workflow.Workflow, condition string) error {
+	if condition == "" {
+		return nil
+	}
+
+	names := append([]string{}, conditionNames...)
+	for _, agent := range wf.Agents {
+		names = append(names, agent.ID)
+	}
+
+	program, err := expr.Compile(condition)
+	if err == nil {
+		err = program.Check(names...)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid condition: %w", err)
+	}
+	return nil
+}
+
+// conditionEnv is what conditions can reference: vars, agents by ID with
+// their status, success, failed, skipped and output, and the workflow name
+// and run ID. Agents are also reachable by bare ID, e.g. analyzer.success.
+func (e *InteractiveExecutor) conditionEnv() expr.Env {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make(map[string]workflow.ExecutionStatus, len(e.state.AgentStates))
+	for id, state := range e.state.AgentStates {
+		statuses[id] = state.Status
+	}
+	return buildConditionEnv(e.workflow, e.state.Variables, statuses, e.outputs, e.runID)
+}
+
+// buildConditionEnv builds the condition environment of a run from its
+// variables, the status of the agents so far and their output files; the
+// interactive and headless runs share it
+func buildConditionEnv(wf *workflow.Workflow, variables map[string]interface{}, statuses map[string]workflow.ExecutionStatus, outputs map[string]string, runID string) expr.Env {
+	vars := make(map[string]interface{})
+	for _, v := range wf.Variables {
+		if v.DefaultValue != nil {
+			vars[v.Name] = v.DefaultValue
+		}
+	}
+	for k, v := range variables {
+		vars[k] = v
+	}
+
+	agents := make(map[string]interface{})
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		status, ok := statuses[agent.ID]
+		if !ok {
+			status = workflow.StatusPending
+		}
+
+		outputPath := outputs[agent.ID]
+		agents[agent.ID] = map[string]interface{}{
+			"status":      string(status),
+			"success":     status == workflow.StatusCompleted,
+			"failed":      status == workflow.StatusFailed || status == workflow.StatusTimedOut,
+			"timed_out":   status == workflow.StatusTimedOut,
+			"skipped":     status == workflow.StatusSkipped,
+			"output_path": outputPath,
+			"output":      expr.Lazy(func() (interface{}, error) { return readConditionOutput(outputPath) }),
+		}
+	}
+
+	values := map[string]interface{}{
+		"vars":     vars,
+		"agents":   agents,
+		"workflow": wf.Name,
+		"run_id":   runID,
+	}
+	for id, agent := range agents {
+		if _, taken := values[id]; !taken {
+			values[id] = agent
+		}
+	}
+	return expr.Env{Values: values}
+}
+
+// readConditionOutput reads an agent's output file; agents that wrote
+// nothing have an empty output
+func readConditionOutput(path string) (interface{}, error) {
+	if path == "" {
+		return "", nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxConditionOutputBytes))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// shouldRunAgent evaluates the agent's condition; agents without one always run
+func (e *InteractiveExecutor) shouldRunAgent(agent *workflow.Agent) (bool, error) {
+	if agent.Condition == "" {
+		return true, nil
+	}
+	run, err := expr.EvalBool(agent.Condition, e.conditionEnv())
+	if err != nil {
+		e.recordDecision(agent, DecisionCondition, "error", fmt.Sprintf("%s: %v", agent.Condition, err))
+		return false, fmt.Errorf("condition %q: %w", agent.Condition, err)
+	}
+	outcome := "run"
+	if !run {
+		outcome = "skip"
+	}
+	e.recordDecision(agent, DecisionCondition, outcome, agent.Condition)
+	return run, nil
+}
+
+// skipAgent records an agent whose condition was false
+func (e *InteractiveExecutor) skipAgent(agent *workflow.Agent, index int) {
+	now := time.Now()
+	e.mu.Lock()
+	e.state.AgentStates[agent.ID] = &workflow.AgentState{
+		AgentID:   agent.ID,
+		Status:    workflow.StatusSkipped,
+		StartTime: &now,
+		EndTime:   &now,
+	}
+	e.mu.Unlock()
+
+	fmt.Printf("⏭️  Skipping %s: condition %q is false\n", agent.Name, agent.Condition)
+	e.emit(workflow.EventAgentSkipped, agent.ID, fmt.Sprintf("%s skipped", agent.Name), map[string]interface{}{
+		"index":     index,
+		"condition": agent.Condition,
+	})
+}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCondition(t *testing.T) {
+	wf := &workflow.Workflow{Agents: []workflow.Agent{{ID: "analyzer"}, {ID: "security-review"}}}
+
+	assert.NoError(t, validateCondition(wf, ""))
+	assert.NoError(t, validateCondition(wf, `analyzer.success && vars.focus contains "security"`))
+	assert.NoError(t, validateCondition(wf, `agents["security-review"].skipped || run_id != ""`))
+
+	err := validateCondition(wf, `analyser.success`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown name analyser")
+
+	err = validateCondition(wf, `'{{focus}}'.includes('security')`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid condition")
+}
+
+func TestParseRejectsInvalidCondition(t *testing.T) {
+	_, err := NewParser(t.TempDir()).Parse([]byte(`name: conditional
+agents:
+  - id: review
+    provider: claude
+    prompt: Review it
+    condition: "review.success &&"
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent review: invalid condition")
+}
+
+func TestShouldRunAgent(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "analysis.md")
+	require.NoError(t, os.WriteFile(report, []byte("1 CRITICAL issue\n"), 0644))
+
+	executor := NewInteractiveExecutor()
+	executor.workflow = &workflow.Workflow{
+		Name:      "review",
+		Variables: []workflow.Variable{{Name: "depth", DefaultValue: "quick"}},
+		Agents: []workflow.Agent{
+			{ID: "analyzer", Output: "analysis.md"},
+			{ID: "fixer"},
+			{ID: "summary"},
+		},
+	}
+	executor.state = &workflow.ExecutionState{
+		Variables: map[string]interface{}{"focus": "security"},
+		AgentStates: map[string]*workflow.AgentState{
+			"analyzer": {AgentID: "analyzer", Status: workflow.StatusCompleted},
+		},
+	}
+	executor.outputs["analyzer"] = report
+
+	tests := map[string]bool{
+		``:                 true,
+		`analyzer.success`: true,
+		`agents.analyzer.output contains "CRITICAL"`:        true,
+		`fixer.status == "pending"`:                         true,
+		`fixer.success || fixer.failed`:                     false,
+		`vars.depth == "quick" && vars.focus == "security"`: true,
+		`workflow == "review"`:                              true,
+		`len(summary.output) > 0`:                           false,
+	}
+	for condition, want := range tests {
+		run, err := executor.shouldRunAgent(&workflow.Agent{ID: "next", Condition: condition})
+		require.NoError(t, err, condition)
+		assert.Equal(t, want, run, condition)
+	}
+
+	_, err := executor.shouldRunAgent(&workflow.Agent{ID: "next", Condition: `vars.focus`})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be true or false")
+}
+
+func TestSkipAgent(t *testing.T) {
+	executor := NewInteractiveExecutor()
+	executor.state = &workflow.ExecutionState{AgentStates: map[string]*workflow.AgentState{}}
+
+	var events []workflow.WorkflowEvent
+	executor.SetEventHandler(func(event workflow.WorkflowEvent) {
+		events = append(events, event)
+	})
+
+	executor.skipAgent(&workflow.Agent{ID: "fixer", Name: "Fixer", Condition: "false"}, 1)
+
+	assert.Equal(t, workflow.StatusSkipped, executor.state.AgentStates["fixer"].Status)
+	require.Len(t, events, 1)
+	assert.Equal(t, workflow.EventAgentSkipped, events[0].Type)
+	assert.Equal(t, "fixer", events[0].AgentID)
+}
@@ -0,0 +1,487 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// What is shown of the files an agent changed, settings.show_diff
+const (
+	ShowDiffSummary = "summary" // list the changed files and save the diff (default)
+	ShowDiffPage    = "page"    // also page through the diff at a terminal
+	ShowDiffOff     = "off"
+)
+
+// DecisionDiff records the files an agent changed
+const DecisionDiff = "diff"
+
+// validateShowDiff checks a workflow's show_diff setting
+func validateShowDiff(settings workflow.Settings) error {
+	switch settings.ShowDiff {
+	case "", ShowDiffSummary, ShowDiffPage, ShowDiffOff:
+		return nil
+	}
+	return fmt.Errorf("show_diff must be summary, page or off, got %q", settings.ShowDiff)
+}
+
+// FileChange is a file an agent changed
+type FileChange struct {
+	Path string
+	// Status is M, A or D for tracked files, ? for new untracked ones
+	Status  string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// AgentDiff is what an agent changed in a git working tree
+type AgentDiff struct {
+	Root  string
+	Files []FileChange
+	// Patch is the unified diff of the tracked files
+	Patch string
+}
+
+// Totals returns the lines added and deleted across all files
+func (d *AgentDiff) Totals() (added, deleted int) {
+	for _, f := range d.Files {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	return added, deleted
+}
+
+// diffBaseline is the git working tree before an agent ran
+type diffBaseline struct {
+	root      string
+	commit    string
+	untracked []string
+	// skip is a directory inside the tree whose files aren't the agent's
+	skip string
+}
+
+// captureDiffBaseline records the working tree of the repository workDir
+// is in, without touching the index or branches. It returns nil outside git
+// and in repositories without commits.
+func captureDiffBaseline(workDir string) *diffBaseline {
+	root, err := git(workDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	root = strings.TrimSpace(root)
+
+	// stash create prints nothing when the working tree matches HEAD
+	commit, err := git(root, "stash", "create")
+	if err != nil {
+		return nil
+	}
+	if commit = strings.TrimSpace(commit); commit == "" {
+		if commit, err = git(root, "rev-parse", "HEAD"); err != nil {
+			return nil
+		}
+		commit = strings.TrimSpace(commit)
+	}
+	untracked, err := gitUntracked(root)
+	if err != nil {
+		return nil
+	}
+	return &diffBaseline{root: root, commit: commit, untracked: untracked}
+}
+
+// diff returns what changed in the working tree since the baseline
+func (b *diffBaseline) diff() (*AgentDiff, error) {
+	d := &AgentDiff{Root: b.root}
+
+	statuses, err := git(b.root, "diff", "--name-status", "--no-renames", "-z", b.commit)
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[string]string)
+	fields := strings.Split(statuses, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status[fields[i+1]] = fields[i]
+	}
+
+	numstat, err := git(b.root, "diff", "--numstat", "--no-renames", "-z", b.commit)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(numstat, "\x00") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 || b.skipped(parts[2]) {
+			continue
+		}
+		change := FileChange{Path: parts[2], Status: status[parts[2]]}
+		if parts[0] == "-" {
+			change.Binary = true
+		} else {
+			change.Added, _ = strconv.Atoi(parts[0])
+			change.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		d.Files = append(d.Files, change)
+	}
+
+	if len(d.Files) > 0 {
+		args := []string{"diff", "--no-color", "--no-renames", b.commit, "--"}
+		for _, f := range d.Files {
+			args = append(args, f.Path)
+		}
+		if d.Patch, err = git(b.root, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	untracked, err := gitUntracked(b.root)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range newFiles(untracked, b.untracked) {
+		if !b.skipped(rel) {
+			d.Files = append(d.Files, FileChange{Path: rel, Status: "?"})
+		}
+	}
+	sort.SliceStable(d.Files, func(i, j int) bool { return d.Files[i].Path < d.Files[j].Path })
+	return d, nil
+}
+
+// skipped reports whether a changed file is under the skipped directory
+func (b *diffBaseline) skipped(rel string) bool {
+	if b.skip == "" {
+		return false
+	}
+	return rel == b.skip || strings.HasPrefix(rel, b.skip+"/")
+}
+
+// diffBaseline records the working tree before an agent runs, when its
+// changes are to be shown afterwards
+func (e *InteractiveExecutor) diffBaseline(agent *workflow.Agent) *diffBaseline {
+	if e.workflow.Settings.ShowDiff == ShowDiffOff || !isProviderStep(agent) {
+		return nil
+	}
+	if target, err := ParseTarget(agentTarget(e.workflow, agent)); err != nil || target != nil {
+		return nil
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	baseline := captureDiffBaseline(workDir)
+	if baseline == nil {
+		return nil
+	}
+	// Outputs, handoffs and manifests opun writes aren't the agent's changes
+	if e.outputDir != "" {
+		if abs, err := filepath.Abs(e.outputDir); err == nil {
+			if rel, err := filepath.Rel(baseline.root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				baseline.skip = filepath.ToSlash(rel)
+			}
+		}
+	}
+	return baseline
+}
+
+// reviewAgentDiff shows what an agent changed since its baseline: a summary
+// of the files, saved as a patch with the run, and with show_diff: page the
+// diff itself in a pager that can open the files in $EDITOR
+func (e *InteractiveExecutor) reviewAgentDiff(agent *workflow.Agent, baseline *diffBaseline) {
+	if baseline == nil {
+		return
+	}
+	d, err := baseline.diff()
+	if err != nil {
+		fmt.Printf("⚠️  Failed to diff the changes of %s: %v\n", agent.Name, err)
+		return
+	}
+	if len(d.Files) == 0 {
+		return
+	}
+
+	path, err := e.saveAgentDiff(agent, d)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to save the changes of %s: %v\n", agent.Name, err)
+	}
+	printDiffSummary(agent.Name, d)
+	if path != "" {
+		fmt.Printf("💾 Diff saved to %s\n", path)
+	}
+	added, deleted := d.Totals()
+	e.recordDecision(agent, DecisionDiff, fmt.Sprintf("%d files changed", len(d.Files)), fmt.Sprintf("+%d -%d %s", added, deleted, path))
+
+	if e.workflow.Settings.ShowDiff == ShowDiffPage && d.Patch != "" && !utils.PlainUI() {
+		if err := pageDiff(agent.Name, d, baseline.diff); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+}
+
+// saveAgentDiff writes an agent's patch to diffs/<agent>.diff in the output
+// directory, or with the run's history when there is none
+func (e *InteractiveExecutor) saveAgentDiff(agent *workflow.Agent, d *AgentDiff) (string, error) {
+	if d.Patch == "" {
+		return "", nil
+	}
+	dir := filepath.Join(e.outputDir, "diffs")
+	if e.outputDir == "" {
+		history, err := HistoryDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(history, "diffs", pathSafe(e.runID))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, pathSafe(agent.ID)+".diff")
+	patch := d.Patch
+	if e.redactor != nil {
+		patch, _ = e.redactor.Redact(patch)
+	}
+	if err := os.WriteFile(path, []byte(patch), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+var (
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	diffDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	diffFileStyle   = lipgloss.NewStyle().Bold(true)
+	diffDimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// printDiffSummary lists the files an agent changed with their line counts
+func printDiffSummary(name string, d *AgentDiff) {
+	added, deleted := d.Totals()
+	fmt.Printf("\n📝 %s changed %d file(s) (%s %s):\n", name, len(d.Files),
+		diffAddStyle.Render(fmt.Sprintf("+%d", added)), diffDeleteStyle.Render(fmt.Sprintf("-%d", deleted)))
+
+	width := 0
+	for _, f := range d.Files {
+		if len(f.Path) > width {
+			width = len(f.Path)
+		}
+	}
+	for _, f := range d.Files {
+		var counts string
+		switch {
+		case f.Status == "?":
+			counts = diffDimStyle.Render("new, untracked")
+		case f.Binary:
+			counts = diffDimStyle.Render("binary")
+		default:
+			counts = diffAddStyle.Render(fmt.Sprintf("+%d", f.Added)) + " " + diffDeleteStyle.Render(fmt.Sprintf("-%d", f.Deleted))
+		}
+		fmt.Printf("   %s %-*s  %s\n", f.Status, width, f.Path, counts)
+	}
+}
+
+// colorizeDiff colors a unified diff the way git does at a terminal,
+// returning the lines where each file starts
+func colorizeDiff(patch string) (string, []int) {
+	lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+	var starts []int
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			starts = append(starts, i)
+			lines[i] = diffFileStyle.Render(line)
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "),
+			strings.HasPrefix(line, "index "), strings.HasPrefix(line, "new file mode"),
+			strings.HasPrefix(line, "deleted file mode"):
+			lines[i] = diffFileStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffDeleteStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n"), starts
+}
+
+// diffFileAt returns the file whose diff is shown at a line of the patch
+func diffFileAt(d *AgentDiff, starts []int, line int) (int, string) {
+	index := 0
+	for i, start := range starts {
+		if start <= line {
+			index = i
+		}
+	}
+	tracked := make([]string, 0, len(d.Files))
+	for _, f := range d.Files {
+		if f.Status != "?" {
+			tracked = append(tracked, f.Path)
+		}
+	}
+	if index >= len(tracked) {
+		return index, ""
+	}
+	return index, tracked[index]
+}
+
+// editorCommand returns the editor to open files in: $VISUAL, then $EDITOR,
+// then vi (notepad on Windows)
+func editorCommand(getenv func(string) string, goos string) []string {
+	editor := getenv("VISUAL")
+	if editor == "" {
+		editor = getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if goos == "windows" {
+			editor = "notepad"
+		}
+	}
+	return strings.Fields(editor)
+}
+
+// diffViewModel pages through an agent's diff. n and p jump between files,
+// e opens the file on screen in $EDITOR and q continues the workflow.
+type diffViewModel struct {
+	name     string
+	diff     *AgentDiff
+	starts   []int
+	viewport viewport.Model
+	ready    bool
+	// reload diffs the working tree again after the file was edited
+	reload func() (*AgentDiff, error)
+	err    error
+}
+
+// editorFinishedMsg is sent when the editor opened from the diff exits
+type editorFinishedMsg struct{ err error }
+
+func newDiffViewModel(name string, d *AgentDiff, reload func() (*AgentDiff, error)) diffViewModel {
+	return diffViewModel{name: name, diff: d, reload: reload}
+}
+
+// setDiff shows a diff, keeping the scroll position
+func (m *diffViewModel) setDiff(d *AgentDiff) {
+	m.diff = d
+	content, starts := colorizeDiff(d.Patch)
+	m.starts = starts
+	m.viewport.SetContent(content)
+}
+
+func (m diffViewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m diffViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, msg.Height-2)
+			m.setDiff(m.diff)
+			m.ready = true
+		} else {
+			m.viewport.Width, m.viewport.Height = msg.Width, msg.Height-2
+		}
+		return m, nil
+
+	case editorFinishedMsg:
+		m.err = msg.err
+		if m.reload != nil {
+			if d, err := m.reload(); err == nil && d.Patch != "" {
+				m.setDiff(d)
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "enter", "ctrl+c":
+			return m, tea.Quit
+		case "n":
+			for _, start := range m.starts {
+				if start > m.viewport.YOffset {
+					m.viewport.SetYOffset(start)
+					break
+				}
+			}
+			return m, nil
+		case "p":
+			for i := len(m.starts) - 1; i >= 0; i-- {
+				if m.starts[i] < m.viewport.YOffset {
+					m.viewport.SetYOffset(m.starts[i])
+					break
+				}
+			}
+			return m, nil
+		case "g":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G":
+			m.viewport.GotoBottom()
+			return m, nil
+		case "e":
+			_, file := diffFileAt(m.diff, m.starts, m.viewport.YOffset)
+			if file == "" {
+				return m, nil
+			}
+			editor := editorCommand(os.Getenv, runtime.GOOS)
+			// #nosec G204 -- the editor is the operator's own $VISUAL or $EDITOR
+			cmd := exec.Command(editor[0], append(editor[1:], filepath.Join(m.diff.Root, filepath.FromSlash(file)))...)
+			return m, tea.ExecProcess(cmd, func(err error) tea.Msg { return editorFinishedMsg{err} })
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m diffViewModel) View() string {
+	if !m.ready {
+		return ""
+	}
+	index, file := diffFileAt(m.diff, m.starts, m.viewport.YOffset)
+	header := diffFileStyle.Render(fmt.Sprintf("%s changed %d file(s)", m.name, len(m.diff.Files)))
+	if file != "" {
+		header += diffDimStyle.Render(fmt.Sprintf(" · %d/%d %s", index+1, len(m.starts), file))
+	}
+	footer := diffDimStyle.Render(fmt.Sprintf("%3.f%% · ↑/↓ scroll · n/p next/previous file · e edit · q continue", m.viewport.ScrollPercent()*100))
+	if m.err != nil {
+		footer = diffDeleteStyle.Render(fmt.Sprintf("editor failed: %v", m.err))
+	}
+	return header + "\n" + m.viewport.View() + "\n" + footer
+}
+
+// pageDiff shows an agent's diff full screen until the operator continues
+func pageDiff(name string, d *AgentDiff, reload func() (*AgentDiff, error)) error {
+	p := tea.NewProgram(newDiffViewModel(name, d, reload), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("diff viewer failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	work := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		_, err := git(work, args...)
+		require.NoError(t, err)
+	}
+	writeTestFile(t, filepath.Join(work, "main.go"), "package main\n\nfunc main() {}\n")
+	writeTestFile(t, filepath.Join(work, "old.go"), "package old\n")
+	_, err := git(work, "add", ".")
+	require.NoError(t, err)
+	_, err = git(work, "commit", "-q", "-m", "initial")
+	require.NoError(t, err)
+
+	// Work in progress before the agent runs isn't part of its changes
+	writeTestFile(t, filepath.Join(work, "wip.txt"), "mine\n")
+	baseline := captureDiffBaseline(work)
+	require.NotNil(t, baseline)
+	baseline.skip = "outputs"
+
+	d, err := baseline.diff()
+	require.NoError(t, err)
+	assert.Empty(t, d.Files)
+
+	// The agent edits, deletes and creates files
+	writeTestFile(t, filepath.Join(work, "main.go"), "package main\n\nfunc main() {\n\trun()\n}\n")
+	require.NoError(t, os.Remove(filepath.Join(work, "old.go")))
+	writeTestFile(t, filepath.Join(work, "new.go"), "package main\n")
+	writeTestFile(t, filepath.Join(work, "outputs", "review.md"), "written by opun\n")
+
+	d, err = baseline.diff()
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{
+		{Path: "main.go", Status: "M", Added: 3, Deleted: 1},
+		{Path: "new.go", Status: "?"},
+		{Path: "old.go", Status: "D", Deleted: 1},
+	}, d.Files)
+	assert.Contains(t, d.Patch, "+\trun()")
+	assert.Contains(t, d.Patch, "deleted file mode")
+
+	colored, starts := colorizeDiff(d.Patch)
+	assert.Contains(t, colored, "run()")
+	require.Len(t, starts, 2)
+	index, file := diffFileAt(d, starts, starts[1]+1)
+	assert.Equal(t, 1, index)
+	assert.Equal(t, "old.go", file)
+	_, file = diffFileAt(d, starts, 0)
+	assert.Equal(t, "main.go", file)
+}
+
+func TestReviewAgentDiffSavesPatch(t *testing.T) {
+	e := NewInteractiveExecutor()
+	e.workflow = &workflow.Workflow{Name: "fix"}
+	e.outputDir = t.TempDir()
+	agent := &workflow.Agent{ID: "build", Name: "Build"}
+
+	path, err := e.saveAgentDiff(agent, &AgentDiff{Patch: "diff --git a/x b/x\n"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(e.outputDir, "diffs", "build.diff"), path)
+	assert.Equal(t, "diff --git a/x b/x\n", readTestFile(t, path))
+
+	path, err = e.saveAgentDiff(agent, &AgentDiff{Files: []FileChange{{Path: "new.go", Status: "?"}}})
+	require.NoError(t, err)
+	assert.Empty(t, path, "untracked files alone have no patch")
+}
+
+func TestDiffViewModel(t *testing.T) {
+	patch := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-c\n+d\n"
+	d := &AgentDiff{Root: "/repo", Patch: patch, Files: []FileChange{
+		{Path: "a.go", Status: "M", Added: 1, Deleted: 1},
+		{Path: "b.go", Status: "M", Added: 1, Deleted: 1},
+	}}
+
+	var m tea.Model = newDiffViewModel("Build", d, nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 5})
+	assert.Contains(t, m.View(), "1/2 a.go")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.Contains(t, m.View(), "2/2 b.go")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	assert.Contains(t, m.View(), "1/2 a.go")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	assert.NotNil(t, cmd, "e opens the editor")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	assert.NotNil(t, cmd)
+}
+
+func TestEditorCommand(t *testing.T) {
+	env := map[string]string{"EDITOR": "code --wait"}
+	getenv := func(key string) string { return env[key] }
+	assert.Equal(t, []string{"code", "--wait"}, editorCommand(getenv, "linux"))
+	env["VISUAL"] = "nvim"
+	assert.Equal(t, []string{"nvim"}, editorCommand(getenv, "linux"))
+	env = nil
+	assert.Equal(t, []string{"notepad"}, editorCommand(getenv, "windows"))
+}
+
+func TestParseShowDiff(t *testing.T) {
+	_, err := NewParser(t.TempDir()).Parse([]byte(`
+name: fix
+settings:
+  show_diff: always
+agents:
+  - id: a
+    provider: claude
+    prompt: hi
+`))
+	assert.ErrorContains(t, err, "show_diff must be summary, page or off")
+}
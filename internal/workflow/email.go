@@ -0,0 +1,507 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Email TLS modes
+const (
+	EmailSTARTTLS = "starttls"
+	EmailTLS      = "tls"
+	EmailNoTLS    = "none"
+)
+
+// maxEmailAttachments caps the total size of the files attached to a report
+const maxEmailAttachments = 10 << 20
+
+// emailTimeout bounds talking to the SMTP server
+const emailTimeout = 30 * time.Second
+
+const defaultEmailSubject = `[opun] {{.Workflow}} {{.Status}}`
+
+const defaultEmailBody = `Workflow {{.Workflow}} {{.Status}} in {{.Duration}}.
+Run: {{.RunID}}
+{{- if .Error}}
+Error: {{.Error}}
+{{- end}}
+{{- if .CostUSD}}
+Cost: ${{printf "%.4f" .CostUSD}}
+{{- end}}
+
+Steps:
+{{- range .Steps}}
+  - {{.ID}}{{if .Provider}} ({{.Provider}}){{end}}: {{.Status}}
+{{- end}}
+{{- if .OutputDir}}
+
+Outputs: {{.OutputDir}}
+{{- end}}
+{{- if .Links}}
+
+Links:
+{{- range $file, $url := .Links}}
+  - {{$file}}: {{$url}}
+{{- end}}
+{{- end}}
+`
+
+// RunReport is what the email sent when a run ends is rendered from
+type RunReport struct {
+	Workflow  string
+	RunID     string
+	Status    string
+	Error     string
+	Duration  time.Duration
+	CostUSD   float64
+	Steps     []ReportStep
+	OutputDir string
+	// Links are signed URLs to uploaded artifacts, by path in the output directory
+	Links map[string]string
+	// Final is the output attached when settings.email.attach is unset
+	Final string
+}
+
+// ReportStep is a step of a reported run, or a combination of a matrix run
+type ReportStep struct {
+	ID       string
+	Name     string
+	Provider string
+	Status   string
+	Output   string
+}
+
+// ManifestReport returns the report of a run from its manifest
+func ManifestReport(m *RunManifest, outputDir string) RunReport {
+	report := RunReport{
+		Workflow:  m.Workflow,
+		RunID:     m.RunID,
+		Status:    m.Status,
+		Error:     m.Error,
+		OutputDir: outputDir,
+	}
+	if m.FinishedAt != nil {
+		report.Duration = m.FinishedAt.Sub(m.StartedAt).Round(time.Second)
+	}
+	if usage := m.Usage(); usage != nil {
+		report.CostUSD = usage.CostUSD
+	}
+	for _, agent := range m.Agents {
+		report.Steps = append(report.Steps, ReportStep{
+			ID:       agent.ID,
+			Name:     agent.Name,
+			Provider: agent.Provider,
+			Status:   agent.Status,
+			Output:   agent.Output,
+		})
+		if agent.Output != "" {
+			report.Final = agent.Output
+		}
+	}
+	if m.Storage != nil {
+		report.Links = m.Storage.URLs
+	}
+	return report
+}
+
+// HeadlessReport returns the report of a headless run
+func HeadlessReport(wf *workflow.Workflow, runID, outputDir string, result MatrixResult, stored *StoredArtifacts) RunReport {
+	report := RunReport{
+		Workflow:  wf.Name,
+		RunID:     runID,
+		Status:    result.Status,
+		Error:     result.Error,
+		Duration:  time.Duration(result.Duration * float64(time.Second)).Round(time.Second),
+		OutputDir: outputDir,
+		Final:     result.Final,
+	}
+	for _, usage := range result.Usage {
+		report.CostUSD += usage.CostUSD
+	}
+	statuses := result.AgentStatuses(wf)
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		report.Steps = append(report.Steps, ReportStep{
+			ID:       agent.ID,
+			Name:     agent.Name,
+			Provider: agent.Provider,
+			Status:   string(statuses[agent.ID]),
+			Output:   result.Outputs[agent.ID],
+		})
+	}
+	if stored != nil {
+		report.Links = stored.URLs
+	}
+	return report
+}
+
+// MatrixReport returns the report of a matrix run, one step per combination.
+// The matrix summary is attached by default.
+func MatrixReport(wf *workflow.Workflow, runID, outputDir string, results []MatrixResult, stored *StoredArtifacts) RunReport {
+	report := RunReport{
+		Workflow:  wf.Name,
+		RunID:     runID,
+		Status:    string(workflow.StatusCompleted),
+		OutputDir: outputDir,
+		Final:     filepath.Join(outputDir, "matrix.md"),
+	}
+	failed := 0
+	for _, result := range results {
+		report.Steps = append(report.Steps, ReportStep{
+			ID:     result.Name,
+			Name:   result.Name,
+			Status: result.Status,
+			Output: result.Final,
+		})
+		if d := time.Duration(result.Duration * float64(time.Second)); d > report.Duration {
+			report.Duration = d
+		}
+		for _, usage := range result.Usage {
+			report.CostUSD += usage.CostUSD
+		}
+		switch {
+		case result.Status == string(workflow.StatusAborted):
+			report.Status = result.Status
+		case result.Error != "":
+			failed++
+			if report.Status != string(workflow.StatusAborted) {
+				report.Status = string(workflow.StatusFailed)
+			}
+		}
+	}
+	report.Duration = report.Duration.Round(time.Second)
+	if failed > 0 {
+		report.Error = fmt.Sprintf("%d of %d matrix combinations failed", failed, len(results))
+	}
+	if stored != nil {
+		report.Links = stored.URLs
+	}
+	return report
+}
+
+// validateEmail checks the parts of an email configuration a workflow can
+// get wrong on its own; the server may still come from the config
+func validateEmail(e *workflow.Email) error {
+	if e == nil || e.Disabled {
+		return nil
+	}
+	switch e.TLS {
+	case "", EmailSTARTTLS, EmailTLS, EmailNoTLS:
+	default:
+		return fmt.Errorf("email tls must be starttls, tls or none, got %q", e.TLS)
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		return fmt.Errorf("invalid email port %d", e.Port)
+	}
+	for _, status := range e.On {
+		switch workflow.ExecutionStatus(status) {
+		case workflow.StatusCompleted, workflow.StatusFailed, workflow.StatusAborted:
+		default:
+			return fmt.Errorf("email on must list completed, failed or aborted, got %q", status)
+		}
+	}
+	if _, err := template.New("subject").Parse(e.Subject); err != nil {
+		return fmt.Errorf("invalid email subject: %w", err)
+	}
+	if _, err := template.New("body").Parse(e.Body); err != nil {
+		return fmt.Errorf("invalid email body: %w", err)
+	}
+	return nil
+}
+
+// emailWanted reports whether a run that ended with status sends a report
+func emailWanted(e *workflow.Email, status string) bool {
+	if e == nil || e.Disabled || len(e.To) == 0 {
+		return false
+	}
+	if len(e.On) == 0 {
+		return true
+	}
+	for _, on := range e.On {
+		if on == status {
+			return true
+		}
+	}
+	return false
+}
+
+// emailAttachment is a file attached to a report
+type emailAttachment struct {
+	Name string
+	Data []byte
+}
+
+// renderEmail renders the subject, body and attachments of a report
+func renderEmail(e *workflow.Email, report RunReport) (string, string, []emailAttachment, error) {
+	subject, err := renderEmailTemplate("subject", e.Subject, defaultEmailSubject, report)
+	if err != nil {
+		return "", "", nil, err
+	}
+	// Headers are one line
+	subject = strings.Join(strings.Fields(subject), " ")
+	body, err := renderEmailTemplate("body", e.Body, defaultEmailBody, report)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var files []string
+	if e.Attach == nil {
+		if report.Final != "" {
+			files = append(files, report.Final)
+		}
+	} else {
+		for _, name := range e.Attach {
+			files = append(files, reportFile(report, name))
+		}
+	}
+
+	var attachments []emailAttachment
+	size := 0
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if file == "" || seen[file] {
+			continue
+		}
+		seen[file] = true
+		// #nosec G304 -- outputs of the run being reported
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("⚠️  Not attaching %s: %v\n", filepath.Base(file), err)
+			continue
+		}
+		if size+len(data) > maxEmailAttachments {
+			fmt.Printf("⚠️  Not attaching %s: report attachments are limited to %d MB\n", filepath.Base(file), maxEmailAttachments>>20)
+			continue
+		}
+		size += len(data)
+		attachments = append(attachments, emailAttachment{Name: filepath.Base(file), Data: data})
+	}
+	return subject, body, attachments, nil
+}
+
+// reportFile resolves an attach entry: a step ID, or a file in the output directory
+func reportFile(report RunReport, name string) string {
+	for _, step := range report.Steps {
+		if step.ID == name {
+			return step.Output
+		}
+	}
+	if report.OutputDir != "" && filepath.IsLocal(name) {
+		return filepath.Join(report.OutputDir, name)
+	}
+	return ""
+}
+
+// renderEmailTemplate renders text, or fallback when text is empty, with the report
+func renderEmailTemplate(name, text, fallback string, report RunReport) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid email %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("failed to render email %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// buildEmailMessage returns a MIME message with the body as plain text and
+// the attachments after it
+func buildEmailMessage(e *workflow.Email, subject, body string, attachments []emailAttachment) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	if len(e.Cc) > 0 {
+		fmt.Fprintf(&msg, "Cc: %s\r\n", strings.Join(e.Cc, ", "))
+	}
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	text := strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	if len(attachments) == 0 {
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		msg.WriteString(text)
+		return msg.Bytes()
+	}
+
+	boundary := emailBoundary()
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(text)
+	msg.WriteString("\r\n")
+	for _, attachment := range attachments {
+		contentType := mime.TypeByExtension(filepath.Ext(attachment.Name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		fmt.Fprintf(&msg, "--%s\r\n", boundary)
+		fmt.Fprintf(&msg, "Content-Type: %s\r\n", contentType)
+		msg.WriteString("Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&msg, "Content-Disposition: attachment; filename=%q\r\n\r\n", attachment.Name)
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			msg.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		msg.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+	return msg.Bytes()
+}
+
+// emailBoundary returns a MIME boundary that won't show up in the parts
+func emailBoundary() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "opun-" + hex.EncodeToString(b)
+}
+
+// emailSender hands a message to the SMTP server of e
+var emailSender = func(ctx context.Context, e *workflow.Email, recipients []string, msg []byte) error {
+	port := e.Port
+	if port == 0 {
+		port = 587
+		if e.TLS == EmailTLS {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: emailTimeout}
+
+	var conn net.Conn
+	var err error
+	if e.TLS == EmailTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: e.Host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.TLS == "" || e.TLS == EmailSTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s doesn't support STARTTLS; set email tls to none to send in the clear", e.Host)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: e.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		passwordEnv := e.PasswordEnv
+		if passwordEnv == "" {
+			passwordEnv = "SMTP_PASSWORD"
+		}
+		password := os.Getenv(passwordEnv)
+		if password == "" {
+			return fmt.Errorf("set %s to the SMTP password of %s", passwordEnv, e.Username)
+		}
+		if err := client.Auth(smtp.PlainAuth("", e.Username, password, e.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.From); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// SendRunReport emails the report of a run when e asks for one for its
+// status. Failures are returned for the caller to warn about; the run's
+// result doesn't depend on them.
+func SendRunReport(ctx context.Context, e *workflow.Email, report RunReport) error {
+	if !emailWanted(e, report.Status) {
+		return nil
+	}
+	if e.Host == "" {
+		return fmt.Errorf("email needs an SMTP host")
+	}
+	if e.From == "" {
+		return fmt.Errorf("email needs a from address")
+	}
+
+	subject, body, attachments, err := renderEmail(e, report)
+	if err != nil {
+		return err
+	}
+	msg := buildEmailMessage(e, subject, body, attachments)
+	recipients := append(append([]string{}, e.To...), e.Cc...)
+	if err := emailSender(context.WithoutCancel(ctx), e, recipients, msg); err != nil {
+		return fmt.Errorf("failed to send report email: %w", err)
+	}
+	fmt.Printf("📧 Report emailed to %s\n", strings.Join(e.To, ", "))
+	return nil
+}
+
+// emailReport emails the report of the run when settings.email asks for one
+func (e *InteractiveExecutor) emailReport(ctx context.Context) {
+	if e.workflow == nil || e.state == nil || e.workflow.Settings.Email == nil {
+		return
+	}
+	m := e.runManifest()
+	if err := SendRunReport(ctx, e.workflow.Settings.Email, ManifestReport(m, e.outputDir)); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
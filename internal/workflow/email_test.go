@@ -0,0 +1,201 @@
+package workflow
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// sentEmail is a message handed to the fake sender
+type sentEmail struct {
+	recipients []string
+	msg        string
+}
+
+func fakeEmailSender(t *testing.T) *[]sentEmail {
+	t.Helper()
+	var sent []sentEmail
+	original := emailSender
+	emailSender = func(ctx context.Context, e *workflow.Email, recipients []string, msg []byte) error {
+		sent = append(sent, sentEmail{recipients: recipients, msg: string(msg)})
+		return nil
+	}
+	t.Cleanup(func() { emailSender = original })
+	return &sent
+}
+
+func TestEmailYAML(t *testing.T) {
+	var settings workflow.Settings
+	require.NoError(t, yaml.Unmarshal([]byte("email: false\n"), &settings))
+	assert.True(t, settings.Email.Disabled)
+
+	settings = workflow.Settings{}
+	require.NoError(t, yaml.Unmarshal([]byte("email:\n  to: [team@example.com]\n  on: [failed]\n  attach: []\n"), &settings))
+	assert.Equal(t, []string{"team@example.com"}, settings.Email.To)
+	assert.Equal(t, []string{"failed"}, settings.Email.On)
+	assert.NotNil(t, settings.Email.Attach)
+}
+
+func TestValidateEmail(t *testing.T) {
+	assert.NoError(t, validateEmail(nil))
+	assert.NoError(t, validateEmail(&workflow.Email{To: []string{"a@example.com"}, On: []string{"failed"}}))
+	assert.ErrorContains(t, validateEmail(&workflow.Email{TLS: "ssl"}), "email tls")
+	assert.ErrorContains(t, validateEmail(&workflow.Email{Port: 70000}), "invalid email port")
+	assert.ErrorContains(t, validateEmail(&workflow.Email{On: []string{"done"}}), "email on")
+	assert.ErrorContains(t, validateEmail(&workflow.Email{Subject: "{{.Workflow"}), "invalid email subject")
+}
+
+func TestSendRunReport(t *testing.T) {
+	sent := fakeEmailSender(t)
+	dir := t.TempDir()
+	review := filepath.Join(dir, "review.md")
+	require.NoError(t, os.WriteFile(review, []byte("Looks good.\n"), 0644))
+
+	finished := time.Now()
+	m := &RunManifest{
+		RunID:      "run-1",
+		Workflow:   "review",
+		Status:     "failed",
+		StartedAt:  finished.Add(-90 * time.Second),
+		FinishedAt: &finished,
+		Error:      "deploy failed",
+		Agents: []ManifestAgent{
+			{ID: "review", Provider: "claude", Status: "completed", Output: review},
+			{ID: "deploy", Provider: "codex", Status: "failed"},
+		},
+		Storage: &StoredArtifacts{URLs: map[string]string{"review.md": "https://store.example.com/review.md?sig=1"}},
+	}
+	report := ManifestReport(m, dir)
+	assert.Equal(t, review, report.Final)
+	assert.Equal(t, 90*time.Second, report.Duration)
+
+	email := &workflow.Email{Host: "smtp.example.com", From: "opun@example.com", To: []string{"team@example.com"}, Cc: []string{"lead@example.com"}}
+	require.NoError(t, SendRunReport(context.Background(), email, report))
+	require.Len(t, *sent, 1)
+	got := (*sent)[0]
+	assert.Equal(t, []string{"team@example.com", "lead@example.com"}, got.recipients)
+	assert.Contains(t, got.msg, "Subject: [opun] review failed\r\n")
+	assert.Contains(t, got.msg, "Cc: lead@example.com\r\n")
+	assert.Contains(t, got.msg, "Error: deploy failed\r\n")
+	assert.Contains(t, got.msg, "  - deploy (codex): failed\r\n")
+	assert.Contains(t, got.msg, "  - review.md: https://store.example.com/review.md?sig=1\r\n")
+	assert.Contains(t, got.msg, `filename="review.md"`)
+	assert.Contains(t, got.msg, base64.StdEncoding.EncodeToString([]byte("Looks good.\n")))
+
+	// Only the statuses listed in on send, and attach: [] attaches nothing
+	email.On = []string{"completed"}
+	require.NoError(t, SendRunReport(context.Background(), email, report))
+	assert.Len(t, *sent, 1)
+	email.On, email.Attach = []string{"failed"}, []string{}
+	email.Subject, email.Body = "{{.Workflow}}: {{.Status}}", "See {{.RunID}}"
+	require.NoError(t, SendRunReport(context.Background(), email, report))
+	require.Len(t, *sent, 2)
+	assert.Contains(t, (*sent)[1].msg, "Subject: review: failed\r\n")
+	assert.True(t, strings.HasSuffix((*sent)[1].msg, "\r\n\r\nSee run-1"))
+	assert.NotContains(t, (*sent)[1].msg, "multipart")
+
+	email.Host = ""
+	assert.ErrorContains(t, SendRunReport(context.Background(), email, report), "SMTP host")
+	assert.NoError(t, SendRunReport(context.Background(), &workflow.Email{Disabled: true, To: []string{"a@example.com"}}, report))
+}
+
+func TestMatrixReport(t *testing.T) {
+	wf := &workflow.Workflow{Name: "sweep"}
+	report := MatrixReport(wf, "run-2", "/tmp/out", []MatrixResult{
+		{Name: "go=1.23", Status: "completed", Duration: 3},
+		{Name: "go=1.24", Status: "failed", Duration: 5, Error: "boom"},
+	}, nil)
+	assert.Equal(t, "failed", report.Status)
+	assert.Equal(t, "1 of 2 matrix combinations failed", report.Error)
+	assert.Equal(t, 5*time.Second, report.Duration)
+	assert.Equal(t, filepath.Join("/tmp/out", "matrix.md"), report.Final)
+	assert.Equal(t, "go=1.24", report.Steps[1].ID)
+}
+
+// fakeSMTPServer accepts one message in the clear and returns what it received
+func fakeSMTPServer(t *testing.T) (int, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var log strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			log.WriteString(strings.TrimSpace(line) + "\n")
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(command, "AUTH"):
+				reply("235 ok")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					log.WriteString(line)
+				}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				received <- log.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestEmailSenderSMTP(t *testing.T) {
+	port, received := fakeSMTPServer(t)
+	t.Setenv("OPUN_TEST_SMTP_PASSWORD", "secret")
+	email := &workflow.Email{
+		Host:        "localhost",
+		Port:        port,
+		TLS:         EmailNoTLS,
+		Username:    "opun",
+		PasswordEnv: "OPUN_TEST_SMTP_PASSWORD",
+		From:        "opun@example.com",
+	}
+	require.NoError(t, emailSender(context.Background(), email, []string{"team@example.com"}, []byte("Subject: hi\r\n\r\nhello\r\n")))
+	log := <-received
+	assert.Contains(t, log, "AUTH PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00opun\x00secret")))
+	assert.Contains(t, log, "MAIL FROM:<opun@example.com>")
+	assert.Contains(t, log, "RCPT TO:<team@example.com>")
+	assert.Contains(t, log, "hello\r\n")
+
+	// STARTTLS is required unless turned off
+	email.TLS = ""
+	port, _ = fakeSMTPServer(t)
+	email.Port = port
+	assert.ErrorContains(t, emailSender(context.Background(), email, []string{"team@example.com"}, nil), "doesn't support STARTTLS")
+}
@@ -0,0 +1,147 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// environmentVars are the variables recorded in a run's environment, besides
+// those with an environmentPrefixes prefix
+var environmentVars = []string{
+	"SHELL", "TERM", "TERM_PROGRAM", "COLORTERM", "NO_COLOR", "FORCE_COLOR",
+	"LANG", "LC_ALL", "LC_CTYPE", "TZ", "CI", "COLUMNS", "LINES", "NODE_OPTIONS",
+}
+
+// environmentPrefixes select the provider and Opun variables recorded in a
+// run's environment
+var environmentPrefixes = []string{"OPUN_", "CLAUDE_", "ANTHROPIC_", "GEMINI_", "GOOGLE_", "QWEN_", "DASHSCOPE_", "OPENAI_"}
+
+// RunEnvironment is the machine and terminal a run happened in, recorded to
+// tell why the same workflow behaves differently elsewhere
+type RunEnvironment struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Kernel   string `json:"kernel,omitempty"`
+	Shell    string `json:"shell,omitempty"`
+	Terminal string `json:"terminal,omitempty"`
+	// TerminalSize is columns x rows of stdout, empty when it isn't a terminal
+	TerminalSize string `json:"terminal_size,omitempty"`
+	Locale       string `json:"locale,omitempty"`
+	// Env holds the relevant variables; secret-looking ones are redacted
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// CaptureEnvironment records the environment of the current process
+func CaptureEnvironment() *RunEnvironment {
+	env := &RunEnvironment{
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Shell:    os.Getenv("SHELL"),
+		Terminal: os.Getenv("TERM"),
+		Env:      environmentVariables(os.Environ()),
+	}
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		env.Kernel = strings.TrimSpace(string(release))
+	}
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		env.TerminalSize = fmt.Sprintf("%dx%d", width, height)
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			env.Locale = locale
+			break
+		}
+	}
+	return env
+}
+
+// environmentVariables picks the relevant variables out of KEY=value pairs
+func environmentVariables(environ []string) map[string]string {
+	vars := make(map[string]string)
+	for _, pair := range environ {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || !relevantVariable(name) {
+			continue
+		}
+		if secretVariablePattern.MatchString(name) {
+			value = "[REDACTED]"
+		}
+		vars[name] = value
+	}
+	return vars
+}
+
+func relevantVariable(name string) bool {
+	for _, known := range environmentVars {
+		if name == known {
+			return true
+		}
+	}
+	for _, prefix := range environmentPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareEnvironments lists the differences between the environments of two
+// runs. Runs from before environments were recorded have nothing to compare.
+func CompareEnvironments(a, b *RunEnvironment) []string {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	var changes []string
+	changed := func(what, old, now string) {
+		if old != now {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", what, orUnset(old), orUnset(now)))
+		}
+	}
+	changed("os", a.OS, b.OS)
+	changed("arch", a.Arch, b.Arch)
+	changed("kernel", a.Kernel, b.Kernel)
+	changed("shell", a.Shell, b.Shell)
+	changed("terminal", a.Terminal, b.Terminal)
+	changed("terminal size", a.TerminalSize, b.TerminalSize)
+	changed("locale", a.Locale, b.Locale)
+
+	names := make(map[string]bool)
+	for name := range a.Env {
+		names[name] = true
+	}
+	for name := range b.Env {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		changed("$"+name, a.Env[name], b.Env[name])
+	}
+	return changes
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironmentVariables(t *testing.T) {
+	vars := environmentVariables([]string{
+		"TERM=xterm-256color",
+		"LANG=en_US.UTF-8",
+		"HOME=/home/dev",
+		"ANTHROPIC_API_KEY=sk-ant-123",
+		"CLAUDE_CODE_USE_BEDROCK=1",
+		"GEMINI_MODEL=gemini-2.5-pro",
+		"PATH=/usr/bin",
+	})
+	assert.Equal(t, map[string]string{
+		"TERM":                    "xterm-256color",
+		"LANG":                    "en_US.UTF-8",
+		"ANTHROPIC_API_KEY":       "[REDACTED]",
+		"CLAUDE_CODE_USE_BEDROCK": "1",
+		"GEMINI_MODEL":            "gemini-2.5-pro",
+	}, vars)
+}
+
+func TestCaptureEnvironment(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "C.UTF-8")
+	t.Setenv("OPUN_TEST_VAR", "yes")
+
+	env := CaptureEnvironment()
+	assert.NotEmpty(t, env.OS)
+	assert.NotEmpty(t, env.Arch)
+	assert.Equal(t, "C.UTF-8", env.Locale)
+	assert.Equal(t, "yes", env.Env["OPUN_TEST_VAR"])
+}
+
+func TestCompareEnvironments(t *testing.T) {
+	a := &RunEnvironment{
+		OS: "linux", Arch: "amd64", Terminal: "xterm-256color", TerminalSize: "200x50", Locale: "en_US.UTF-8",
+		Env: map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1", "ANTHROPIC_API_KEY": "[REDACTED]"},
+	}
+	b := &RunEnvironment{
+		OS: "darwin", Arch: "amd64", Terminal: "dumb", Locale: "en_US.UTF-8",
+		Env: map[string]string{"TERM": "dumb", "CI": "true", "ANTHROPIC_API_KEY": "[REDACTED]"},
+	}
+
+	assert.Equal(t, []string{
+		"os: linux → darwin",
+		"terminal: xterm-256color → dumb",
+		"terminal size: 200x50 → (unset)",
+		"$CI: (unset) → true",
+		"$NO_COLOR: 1 → (unset)",
+		"$TERM: xterm-256color → dumb",
+	}, CompareEnvironments(a, b))
+
+	assert.Nil(t, CompareEnvironments(a, nil))
+	assert.Empty(t, CompareEnvironments(a, a))
+}
+
+func TestCompareRunsEnvironment(t *testing.T) {
+	runA := writeRunFiles(t, map[string]string{
+		"manifest.json": `{"workflow":"review","environment":{"os":"linux","arch":"amd64","locale":"en_US.UTF-8"}}`,
+	})
+	runB := writeRunFiles(t, map[string]string{
+		"manifest.json": `{"workflow":"review","environment":{"os":"linux","arch":"amd64","locale":"C"}}`,
+	})
+
+	c, err := CompareRuns(runA, runB)
+	require.NoError(t, err)
+	assert.Empty(t, c.Changes)
+	assert.Equal(t, []string{"locale: en_US.UTF-8 → C"}, c.Environment)
+}
@@ -0,0 +1,167 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// ErrorClass is the kind of failure that ended a run, so wrappers can
+// branch on it through the exit code or the run manifest
+type ErrorClass string
+
+// Error classes
+const (
+	ErrorGeneric          ErrorClass = "error"
+	ErrorProviderNotFound ErrorClass = "provider_not_found"
+	ErrorAuth             ErrorClass = "auth"
+	ErrorTimeout          ErrorClass = "timeout"
+	ErrorGateFailed       ErrorClass = "gate_failed"
+	ErrorBudgetExceeded   ErrorClass = "budget_exceeded"
+	ErrorLocked           ErrorClass = "locked"
+	ErrorUserAborted      ErrorClass = "user_aborted"
+	ErrorInvalidVariable  ErrorClass = "invalid_variable"
+)
+
+// exitCodes are the process exit codes of each error class
+var exitCodes = map[ErrorClass]int{
+	ErrorGeneric:          1,
+	ErrorProviderNotFound: 3,
+	ErrorAuth:             4,
+	ErrorTimeout:          5,
+	ErrorGateFailed:       6,
+	ErrorBudgetExceeded:   7,
+	ErrorLocked:           8,
+	ErrorUserAborted:      130,
+	ErrorInvalidVariable:  2,
+}
+
+// ExitCode returns the exit code of an error class
+func (c ErrorClass) ExitCode() int {
+	if code, ok := exitCodes[c]; ok {
+		return code
+	}
+	return 1
+}
+
+// classifiedError is an error tagged with its class
+type classifiedError struct {
+	class ErrorClass
+	err   error
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// Classify tags err with a class; wrapping it with %w keeps the class
+func Classify(class ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{class: class, err: err}
+}
+
+// authHints are phrases providers use when they aren't logged in
+var authHints = []string{
+	"not logged in",
+	"please log in",
+	"please login",
+	"unauthorized",
+	"authentication",
+	"invalid api key",
+	"api key not",
+	"invalid x-api-key",
+	"401",
+}
+
+// headlessFailureClasses are the classes of the failures headless
+// providers report
+var headlessFailureClasses = map[string]ErrorClass{
+	providers.FailureAuth:      ErrorAuth,
+	providers.FailureQuota:     ErrorBudgetExceeded,
+	providers.FailureTurnLimit: ErrorBudgetExceeded,
+	providers.FailureTimeout:   ErrorTimeout,
+	providers.FailureCancelled: ErrorUserAborted,
+}
+
+// ClassOf returns the class of err: the one it was tagged with, the one a
+// headless provider reported, or one guessed from what it wraps and says.
+// Nil errors have no class.
+func ClassOf(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		return classified.class
+	}
+	var headless *providers.HeadlessError
+	if errors.As(err, &headless) {
+		if class, ok := headlessFailureClasses[headless.Kind]; ok {
+			return class
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorUserAborted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "executable file not found"):
+		return ErrorProviderNotFound
+	case strings.Contains(message, "timed out"):
+		return ErrorTimeout
+	}
+	for _, hint := range authHints {
+		if strings.Contains(message, hint) {
+			return ErrorAuth
+		}
+	}
+	return ErrorGeneric
+}
+
+// ExitCode returns the process exit code for err, 0 when it's nil
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return ClassOf(err).ExitCode()
+}
+
+// Execute executes a workflow with interactive sessions. A failure is
+// recorded with its class in the run manifest.
+func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow, variables map[string]interface{}) error {
+	err := e.execute(ctx, wf, variables)
+	if err != nil {
+		e.mu.Lock()
+		e.runErr = err
+		e.mu.Unlock()
+		e.writeRunManifest()
+	}
+	e.storeArtifacts(ctx)
+	e.emailReport(ctx)
+	return err
+}
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassOf(t *testing.T) {
+	assert.Equal(t, ErrorClass(""), ClassOf(nil))
+	assert.Nil(t, Classify(ErrorTimeout, nil))
+
+	// Tags survive wrapping
+	tagged := Classify(ErrorBudgetExceeded, errors.New("prompt is ~300000 tokens"))
+	wrapped := fmt.Errorf("agent writer failed: %w", fmt.Errorf("workflow execution failed: %w", tagged))
+	assert.Equal(t, ErrorBudgetExceeded, ClassOf(wrapped))
+	assert.Equal(t, "agent writer failed: workflow execution failed: prompt is ~300000 tokens", wrapped.Error())
+
+	assert.Equal(t, ErrorUserAborted, ClassOf(fmt.Errorf("run: %w", context.Canceled)))
+	assert.Equal(t, ErrorTimeout, ClassOf(fmt.Errorf("run: %w", context.DeadlineExceeded)))
+	assert.Equal(t, ErrorProviderNotFound, ClassOf(errors.New(`exec: "gemini": executable file not found in $PATH`)))
+	assert.Equal(t, ErrorAuth, ClassOf(errors.New("claude: Invalid API key · Please run /login")))
+	assert.Equal(t, ErrorTimeout, ClassOf(errors.New("validator timed out after 30s")))
+	assert.Equal(t, ErrorGeneric, ClassOf(errors.New("something broke")))
+
+	// Failures headless providers report keep their kind through wrapping
+	quota := &providers.HeadlessError{Provider: "gemini", ExitCode: 1, Kind: providers.FailureQuota, Message: "Quota exceeded"}
+	assert.Equal(t, ErrorBudgetExceeded, ClassOf(fmt.Errorf("agent plan failed: %w", quota)))
+	assert.Equal(t, ErrorAuth, ClassOf(&providers.HeadlessError{Provider: "gemini", ExitCode: 41, Kind: providers.FailureAuth}))
+	assert.Equal(t, ErrorGeneric, ClassOf(&providers.HeadlessError{Provider: "gemini", ExitCode: 42, Kind: providers.FailureInput, Message: "bad input"}))
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("something broke")))
+	assert.Equal(t, 3, ExitCode(Classify(ErrorProviderNotFound, errors.New("gemini command not found"))))
+	assert.Equal(t, 4, ExitCode(Classify(ErrorAuth, errors.New("not logged in"))))
+	assert.Equal(t, 5, ExitCode(Classify(ErrorTimeout, errors.New("timed out"))))
+	assert.Equal(t, 6, ExitCode(Classify(ErrorGateFailed, errors.New("prompt blocked by policy"))))
+	assert.Equal(t, 7, ExitCode(Classify(ErrorBudgetExceeded, errors.New("over budget"))))
+	assert.Equal(t, 130, ExitCode(Classify(ErrorUserAborted, errors.New("interrupted"))))
+	assert.Equal(t, 1, ErrorClass("unknown").ExitCode())
+}
+
+func TestRunManifestRecordsErrorClass(t *testing.T) {
+	wf := &workflow.Workflow{Name: "review", Agents: []workflow.Agent{{ID: "writer", Provider: "gemini", Prompt: "Write"}}}
+	state := &workflow.ExecutionState{
+		StartTime: time.Now(),
+		Status:    workflow.StatusFailed,
+		AgentStates: map[string]*workflow.AgentState{
+			"writer": {AgentID: "writer", Status: workflow.StatusFailed, Error: &workflow.ExecutionError{Class: string(ErrorProviderNotFound)}},
+		},
+	}
+
+	m := newRunManifest(wf, state, nil, nil, RunInventory{})
+	require.Len(t, m.Agents, 1)
+	assert.Equal(t, string(ErrorProviderNotFound), m.Agents[0].ErrorClass)
+}
@@ -0,0 +1,146 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// SetEventHandler registers a handler that is notified of workflow progress
+// events (workflow start/complete/error, agent start/complete/error)
+func (e *InteractiveExecutor) SetEventHandler(handler func(workflow.WorkflowEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.eventHandler = handler
+}
+
+// emit notifies the registered event handler, if any
+func (e *InteractiveExecutor) emit(eventType workflow.EventType, agentID, message string, data map[string]interface{}) {
+	e.mu.Lock()
+	handler := e.eventHandler
+	e.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+
+	handler(workflow.WorkflowEvent{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		AgentID:   agentID,
+		Message:   message,
+		Data:      data,
+	})
+}
+
+// agentStartData is the data of an agent start event
+func agentStartData(agent *workflow.Agent, index int) map[string]interface{} {
+	stepType := agent.Type
+	if isProviderStep(agent) {
+		stepType = workflow.StepTypeAgent
+	}
+	return map[string]interface{}{
+		"index":    index,
+		"name":     agent.Name,
+		"type":     stepType,
+		"provider": agent.Provider,
+		"model":    agent.Model,
+	}
+}
+
+// agentEndData is the data of an agent complete or error event: the agent's
+// index plus its estimated prompt size and resource usage when known
+func (e *InteractiveExecutor) agentEndData(agent *workflow.Agent, index int) map[string]interface{} {
+	data := map[string]interface{}{"index": index}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if tokens, ok := e.promptTokens[agent.ID]; ok {
+		data["prompt_tokens"] = tokens
+	}
+	if usage := e.resources[agent.ID]; usage != nil {
+		data["duration_seconds"] = usage.DurationSeconds
+		if usage.CPUSeconds > 0 {
+			data["cpu_seconds"] = usage.CPUSeconds
+		}
+		if usage.PeakMemoryMB > 0 {
+			data["peak_memory_mb"] = usage.PeakMemoryMB
+		}
+		if usage.Killed {
+			data["killed"] = true
+		}
+	}
+	return data
+}
+
+// SetOutputEvents enables output_chunk events carrying the providers' raw
+// terminal output. They are off by default since they are frequent.
+func (e *InteractiveExecutor) SetOutputEvents(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.outputEvents = enabled
+}
+
+// emitOutput sends an agent's terminal output as an output_chunk event when
+// output events are enabled
+func (e *InteractiveExecutor) emitOutput(agentID string, data []byte) {
+	e.mu.Lock()
+	enabled := e.outputEvents
+	e.mu.Unlock()
+
+	if !enabled {
+		return
+	}
+	text := string(data)
+	if e.redactor != nil {
+		// Best effort: a secret split across two chunks is not caught
+		text, _ = e.redactor.Redact(text)
+	}
+	e.emit(workflow.EventOutputChunk, agentID, "", map[string]interface{}{"text": text})
+}
+
+// outputEventWriter emits everything written to it as output_chunk events
+type outputEventWriter struct {
+	executor *InteractiveExecutor
+	agentID  string
+}
+
+func (w outputEventWriter) Write(p []byte) (int, error) {
+	w.executor.emitOutput(w.agentID, p)
+	return len(p), nil
+}
+
+// EventHandler is notified of workflow events
+type EventHandler = func(workflow.WorkflowEvent)
+
+// CombineEventHandlers returns a handler that passes events to each non-nil handler in turn
+func CombineEventHandlers(handlers ...EventHandler) EventHandler {
+	var active []EventHandler
+	for _, handler := range handlers {
+		if handler != nil {
+			active = append(active, handler)
+		}
+	}
+
+	return func(event workflow.WorkflowEvent) {
+		for _, handler := range active {
+			handler(event)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// streamEventNames maps workflow events to the names used in event streams
+var streamEventNames = map[workflow.EventType]string{
+	workflow.EventWorkflowQueued:   "run_queued",
+	workflow.EventWorkflowStart:    "run_started",
+	workflow.EventWorkflowComplete: "run_completed",
+	workflow.EventWorkflowError:    "run_failed",
+	workflow.EventAgentStart:       "agent_started",
+	workflow.EventAgentComplete:    "agent_completed",
+	workflow.EventAgentError:       "agent_failed",
+	workflow.EventAgentRetry:       "agent_retrying",
+	workflow.EventAgentSkipped:     "agent_skipped",
+	workflow.EventVariableSet:      "variable_set",
+	workflow.EventVariableNeeded:   "variable_needed",
+	workflow.EventOutputCreated:    "output_created",
+	workflow.EventOutputChunk:      "output_chunk",
+}
+
+// StreamEvent is one line of an event stream
+type StreamEvent struct {
+	Event    string                 `json:"event"`
+	Time     time.Time              `json:"time"`
+	RunID    string                 `json:"run_id,omitempty"`
+	Workflow string                 `json:"workflow,omitempty"`
+	Agent    string                 `json:"agent,omitempty"`
+	Message  string                 `json:"message,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// EventStream writes workflow events as newline-delimited JSON so editor
+// plugins can follow a run without parsing its terminal output
+type EventStream struct {
+	mu       sync.Mutex
+	w        io.WriteCloser
+	enc      *json.Encoder
+	runID    string
+	workflow string
+	failed   bool
+}
+
+// OpenEventStream opens an event stream target: fd:N writes to an inherited
+// file descriptor, unix:/path connects to a Unix socket the reader listens on
+func OpenEventStream(target string) (*EventStream, error) {
+	kind, value, ok := strings.Cut(target, ":")
+	if !ok || value == "" {
+		return nil, fmt.Errorf("invalid event stream %q: expected fd:N or unix:/path", target)
+	}
+
+	var w io.WriteCloser
+	switch kind {
+	case "fd":
+		fd, err := strconv.Atoi(value)
+		if err != nil || fd < 1 {
+			return nil, fmt.Errorf("invalid event stream file descriptor: %s", value)
+		}
+		file := os.NewFile(uintptr(fd), "event-stream")
+		if file == nil {
+			return nil, fmt.Errorf("file descriptor %d is not open", fd)
+		}
+		if _, err := file.Stat(); err != nil {
+			return nil, fmt.Errorf("file descriptor %d is not open: %w", fd, err)
+		}
+		w = file
+	case "unix":
+		conn, err := net.DialTimeout("unix", value, 5*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to event stream socket: %w", err)
+		}
+		w = conn
+	default:
+		return nil, fmt.Errorf("invalid event stream %q: expected fd:N or unix:/path", target)
+	}
+
+	return NewEventStream(w), nil
+}
+
+// NewEventStream creates an event stream writing to w
+func NewEventStream(w io.WriteCloser) *EventStream {
+	return &EventStream{w: w, enc: json.NewEncoder(w)}
+}
+
+// SetRun records the run ID and workflow name sent with every event
+func (s *EventStream) SetRun(runID, workflowName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runID = runID
+	s.workflow = workflowName
+}
+
+// HandleEvent writes an event to the stream. Once a write fails, for example
+// because the reader went away, the stream stops writing rather than
+// interrupting the run.
+func (s *EventStream) HandleEvent(event workflow.WorkflowEvent) {
+	name, ok := streamEventNames[event.Type]
+	if !ok {
+		name = string(event.Type)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed {
+		return
+	}
+	if err := s.enc.Encode(StreamEvent{
+		Event:    name,
+		Time:     event.Timestamp,
+		RunID:    s.runID,
+		Workflow: s.workflow,
+		Agent:    event.AgentID,
+		Message:  event.Message,
+		Data:     event.Data,
+	}); err != nil {
+		s.failed = true
+	}
+}
+
+// Close closes the stream
+func (s *EventStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Close()
+}
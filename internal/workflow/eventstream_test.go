@@ -0,0 +1,192 @@
+package workflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pinnedFiles holds files whose descriptors were handed to an event stream
+var pinnedFiles []*os.File
+
+// bufferCloser collects stream output
+type bufferCloser struct {
+	strings.Builder
+	closed bool
+	err    error
+}
+
+func (b *bufferCloser) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.Builder.Write(p)
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func decodeStream(t *testing.T, output string) []StreamEvent {
+	var events []StreamEvent
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var event StreamEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEventStream(t *testing.T) {
+	out := &bufferCloser{}
+	stream := NewEventStream(out)
+	stream.SetRun("run-1", "review")
+
+	now := time.Now()
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowStart, Timestamp: now, Data: map[string]interface{}{"total_agents": 2}})
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, Timestamp: now, AgentID: "analyze"})
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventOutputChunk, Timestamp: now, AgentID: "analyze", Data: map[string]interface{}{"text": "\x1b[1mhi\x1b[0m"}})
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventVariableNeeded, Timestamp: now, AgentID: "ask", Message: "Which env?", Data: map[string]interface{}{"variable": "env"}})
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentComplete, Timestamp: now, AgentID: "analyze"})
+	stream.HandleEvent(workflow.WorkflowEvent{Type: "custom", Timestamp: now})
+
+	events := decodeStream(t, out.String())
+	require.Len(t, events, 6)
+
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Event
+		assert.Equal(t, "run-1", event.RunID)
+		assert.Equal(t, "review", event.Workflow)
+	}
+	assert.Equal(t, []string{"run_started", "agent_started", "output_chunk", "variable_needed", "agent_completed", "custom"}, names)
+	assert.Equal(t, "analyze", events[1].Agent)
+	assert.Equal(t, "\x1b[1mhi\x1b[0m", events[2].Data["text"])
+	assert.Equal(t, "Which env?", events[3].Message)
+	assert.Equal(t, "env", events[3].Data["variable"])
+
+	require.NoError(t, stream.Close())
+	assert.True(t, out.closed)
+}
+
+func TestEventStreamStopsAfterWriteError(t *testing.T) {
+	out := &bufferCloser{err: errors.New("broken pipe")}
+	stream := NewEventStream(out)
+
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart})
+	out.err = nil
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentComplete})
+	assert.Empty(t, out.String(), "a failed stream stays closed")
+}
+
+func TestOpenEventStream(t *testing.T) {
+	for _, target := range []string{"", "fd", "fd:", "fd:abc", "fd:0", "tcp:localhost:1", "unix:"} {
+		_, err := OpenEventStream(target)
+		assert.Error(t, err, target)
+	}
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	// The stream owns the descriptor; keep w reachable so its finalizer never closes it again
+	pinnedFiles = append(pinnedFiles, w)
+
+	stream, err := OpenEventStream("fd:" + strconv.Itoa(int(w.Fd())))
+	require.NoError(t, err)
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, AgentID: "a"})
+	require.NoError(t, stream.Close())
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"event":"agent_started"`)
+}
+
+func TestOpenEventStreamUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not available on every Windows version")
+	}
+
+	socket := filepath.Join(t.TempDir(), "events.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		lines <- line
+	}()
+
+	stream, err := OpenEventStream("unix:" + socket)
+	require.NoError(t, err)
+	stream.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowComplete})
+	require.NoError(t, stream.Close())
+
+	select {
+	case line := <-lines:
+		assert.Contains(t, line, `"event":"run_completed"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	_, err = OpenEventStream("unix:" + filepath.Join(t.TempDir(), "missing.sock"))
+	assert.Error(t, err)
+}
+
+func TestExecutorStreamEvents(t *testing.T) {
+	var events []workflow.WorkflowEvent
+	e := NewInteractiveExecutor()
+	e.SetEventHandler(CombineEventHandlers(nil, func(event workflow.WorkflowEvent) {
+		events = append(events, event)
+	}))
+
+	e.emitOutput("a", []byte("hidden"))
+	assert.Empty(t, events, "output events are opt-in")
+
+	e.SetOutputEvents(true)
+	_, err := outputEventWriter{e, "a"}.Write([]byte("shown"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, workflow.EventOutputChunk, events[0].Type)
+	assert.Equal(t, "shown", events[0].Data["text"])
+
+	e.state = &workflow.ExecutionState{
+		Variables:   map[string]interface{}{"env": "staging"},
+		AgentStates: make(map[string]*workflow.AgentState),
+	}
+	e.ask = func(question string, options []string, value string) (string, error) {
+		return "prod", nil
+	}
+	require.NoError(t, e.executeInput(&workflow.Agent{
+		ID:       "target",
+		Type:     workflow.StepTypeInput,
+		Prompt:   "Deploy where instead of {{env}}?",
+		Options:  []string{"prod", "dev"},
+		Variable: "env",
+	}))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, workflow.EventVariableNeeded, events[1].Type)
+	assert.Equal(t, "target", events[1].AgentID)
+	assert.Equal(t, "Deploy where instead of staging?", events[1].Message)
+	assert.Equal(t, "env", events[1].Data["variable"])
+	assert.Equal(t, "staging", events[1].Data["current"])
+}
@@ -0,0 +1,42 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// RequireFeatures checks that the experimental features a workflow uses are
+// enabled, so a run fails before any agent starts rather than halfway.
+// settings.parallel predates the feature flags and needs none.
+func RequireFeatures(wf *workflow.Workflow) error {
+	if usesSandbox(wf) {
+		return features.Require(features.ContainerSandbox, "sandbox")
+	}
+	return nil
+}
+
+// usesSandbox reports whether any agent of a workflow runs in a container
+func usesSandbox(wf *workflow.Workflow) bool {
+	for i := range wf.Agents {
+		if resolveSandbox(wf.Settings.Sandbox, wf.Agents[i].Sandbox) != nil {
+			return true
+		}
+	}
+	return false
+}
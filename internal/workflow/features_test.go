@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireFeatures(t *testing.T) {
+	t.Setenv(features.EnvVar, "")
+
+	plain := &workflow.Workflow{Agents: []workflow.Agent{{ID: "a"}}}
+	assert.NoError(t, RequireFeatures(plain))
+
+	// settings.parallel predates the flags and keeps working
+	parallel := &workflow.Workflow{Settings: workflow.Settings{Parallel: true}, Agents: []workflow.Agent{{ID: "a"}}}
+	assert.NoError(t, RequireFeatures(parallel))
+
+	sandboxed := &workflow.Workflow{
+		Settings: workflow.Settings{Parallel: true},
+		Agents:   []workflow.Agent{{ID: "a", Sandbox: &workflow.Sandbox{Runtime: "docker"}}},
+	}
+	err := RequireFeatures(sandboxed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "enable container-sandbox")
+
+	// Turning the sandbox off for every agent needs no feature
+	unsandboxed := &workflow.Workflow{
+		Settings: workflow.Settings{Sandbox: &workflow.Sandbox{Runtime: "docker"}},
+		Agents:   []workflow.Agent{{ID: "a", Sandbox: &workflow.Sandbox{Runtime: "none"}}},
+	}
+	assert.NoError(t, RequireFeatures(unsandboxed))
+
+	t.Setenv(features.EnvVar, features.ContainerSandbox)
+	assert.NoError(t, RequireFeatures(sandboxed))
+}
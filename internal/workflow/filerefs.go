@@ -0,0 +1,104 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// What happens when a prompt references files that aren't there
+const (
+	MissingRefsWarn   = "warn"
+	MissingRefsFail   = "fail"
+	MissingRefsIgnore = "ignore"
+)
+
+// validateMissingRefs checks a workflow's missing_refs setting
+func validateMissingRefs(settings workflow.Settings) error {
+	switch settings.MissingRefs {
+	case "", MissingRefsWarn, MissingRefsFail, MissingRefsIgnore:
+		return nil
+	}
+	return fmt.Errorf("missing_refs must be warn, fail or ignore, got %q", settings.MissingRefs)
+}
+
+// missingFileRefs returns the @path references in a prompt that don't
+// exist or can't be read. Relative paths are relative to workDir.
+func missingFileRefs(prompt, workDir string) []string {
+	var missing []string
+	for _, ref := range providers.FileReferences(prompt) {
+		path := ref
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		// #nosec G304 -- only opened to check that it can be read
+		f, err := os.Open(path)
+		if err != nil {
+			missing = append(missing, ref)
+			continue
+		}
+		_ = f.Close()
+	}
+	return missing
+}
+
+// checkFileRefs looks for @path references to files that aren't there
+// before a prompt is sent, so the model isn't left to guess at them. It
+// warns, or with missing_refs: fail returns a gate error; it returns the
+// missing references either way.
+func checkFileRefs(settings workflow.Settings, agentID, prompt, workDir string) ([]string, error) {
+	if settings.MissingRefs == MissingRefsIgnore {
+		return nil, nil
+	}
+	missing := missingFileRefs(prompt, workDir)
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	if settings.MissingRefs == MissingRefsFail {
+		return missing, Classify(ErrorGateFailed, fmt.Errorf("prompt for %s references missing files: %s", agentID, strings.Join(missing, ", ")))
+	}
+	fmt.Printf("⚠️  Prompt for %s references missing files: %s\n", agentID, strings.Join(missing, ", "))
+	return missing, nil
+}
+
+// checkAgentFileRefs runs checkFileRefs for an agent of the run and records
+// what it found
+func (e *InteractiveExecutor) checkAgentFileRefs(agent *workflow.Agent, prompt string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	missing, err := checkFileRefs(e.workflow.Settings, agent.ID, prompt, workDir)
+	switch {
+	case err != nil:
+		e.recordDecision(agent, DecisionFileRefs, MissingRefsFail, strings.Join(missing, ", "))
+	case len(missing) > 0:
+		e.recordDecision(agent, DecisionFileRefs, MissingRefsWarn, strings.Join(missing, ", "))
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.md"), []byte("plan"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+
+	prompt := "Follow @plan.md and look at @src/ and @" + filepath.Join(dir, "plan.md") + ". Also read @notes/todo.md and @/nonexistent/spec.txt, and ask @bob."
+	assert.Equal(t, []string{"notes/todo.md", "/nonexistent/spec.txt"}, missingFileRefs(prompt, dir))
+}
+
+func TestCheckFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	prompt := "Summarize @missing.md"
+
+	missing, err := checkFileRefs(workflow.Settings{}, "writer", prompt, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"missing.md"}, missing)
+
+	missing, err = checkFileRefs(workflow.Settings{MissingRefs: MissingRefsIgnore}, "writer", prompt, dir)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	_, err = checkFileRefs(workflow.Settings{MissingRefs: MissingRefsFail}, "writer", prompt, dir)
+	require.Error(t, err)
+	assert.Equal(t, ErrorGateFailed, ClassOf(err))
+	assert.Contains(t, err.Error(), "missing.md")
+
+	assert.Error(t, validateMissingRefs(workflow.Settings{MissingRefs: "skip"}))
+}
+
+func TestMatrixRunnerMissingRefs(t *testing.T) {
+	wf := &workflow.Workflow{
+		Name:     "refs",
+		Settings: workflow.Settings{MissingRefs: MissingRefsFail},
+		Agents:   []workflow.Agent{{ID: "review", Provider: "claude", Prompt: "Review @does/not/exist.go"}},
+	}
+
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	ran := false
+	runner.SetRunner(func(ctx context.Context, provider, model, prompt string) (string, error) {
+		ran = true
+		return "ok", nil
+	})
+
+	result, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.Error(t, err)
+	assert.False(t, ran)
+	assert.Equal(t, ErrorGateFailed, result.ErrorClass)
+}
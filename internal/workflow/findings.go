@@ -0,0 +1,309 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Finding severities, as SARIF levels
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// sarifSchema is the SARIF version findings are exported as
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// findingsInstructions is added to the prompt of agents with findings on
+const findingsInstructions = `After your review, list every finding in a fenced json code block at the end of your answer, in this format:
+
+` + "```json" + `
+{"findings": [{"file": "path/relative/to/repo.go", "line": 42, "end_line": 44, "severity": "error|warning|note", "rule": "short-rule-id", "title": "Short title", "message": "What is wrong and how to fix it"}]}
+` + "```" + `
+
+Use an empty list when there are no findings.`
+
+// Finding is one issue a review agent reported
+type Finding struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	EndLine  int    `json:"end_line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Message  string `json:"message"`
+}
+
+// GitHubAnnotation is a finding as a GitHub check run annotation
+type GitHubAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+// findingsEnabled reports whether an agent reports findings
+func findingsEnabled(agent *workflow.Agent) bool {
+	return agent.Findings != nil && agent.Findings.Enabled
+}
+
+// withFindingsInstructions asks agents with findings on to list them as JSON
+func withFindingsInstructions(agent *workflow.Agent, prompt string) string {
+	if !findingsEnabled(agent) {
+		return prompt
+	}
+	return prompt + "\n\n" + findingsInstructions
+}
+
+// ParseFindings reads the findings from an agent's output: the last fenced
+// json block, or the whole output when it is JSON, holding either a list of
+// findings or an object with a findings list. Severities are normalized and
+// paths made relative to workDir.
+func ParseFindings(output, workDir string) ([]Finding, error) {
+	candidates := []string{strings.TrimSpace(output)}
+	if blocks := jsonBlockPattern.FindAllStringSubmatch(output, -1); len(blocks) > 0 {
+		candidates = nil
+		for i := len(blocks) - 1; i >= 0; i-- {
+			candidates = append(candidates, blocks[i][1])
+		}
+	}
+
+	for _, candidate := range candidates {
+		var findings []Finding
+		if err := json.Unmarshal([]byte(candidate), &findings); err != nil {
+			var wrapped struct {
+				Findings *[]Finding `json:"findings"`
+			}
+			if err := json.Unmarshal([]byte(candidate), &wrapped); err != nil || wrapped.Findings == nil {
+				continue
+			}
+			findings = *wrapped.Findings
+		}
+
+		kept := make([]Finding, 0, len(findings))
+		for _, f := range findings {
+			if strings.TrimSpace(f.Message) == "" {
+				continue
+			}
+			f.Severity = normalizeSeverity(f.Severity)
+			f.File = relativeFindingPath(f.File, workDir)
+			if f.EndLine < f.Line {
+				f.EndLine = f.Line
+			}
+			kept = append(kept, f)
+		}
+		return kept, nil
+	}
+	return nil, fmt.Errorf("no findings JSON in the output")
+}
+
+// normalizeSeverity maps the severities agents use to SARIF levels
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "error", "critical", "high", "blocker", "failure":
+		return SeverityError
+	case "note", "info", "notice", "suggestion", "nit":
+		return SeverityNote
+	default:
+		return SeverityWarning
+	}
+}
+
+// relativeFindingPath makes a finding's path a slash-separated path
+// relative to the repository, as SARIF and GitHub expect
+func relativeFindingPath(path, workDir string) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(path) && workDir != "" {
+		if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// SARIF renders findings as a SARIF 2.1.0 log of one run of tool. Findings
+// without a rule get defaultRule.
+func SARIF(tool, defaultRule string, findings []Finding) ([]byte, error) {
+	if tool == "" {
+		tool = "opun"
+	}
+
+	type rule struct {
+		ID               string            `json:"id"`
+		ShortDescription map[string]string `json:"shortDescription,omitempty"`
+	}
+	rules := make(map[string]rule)
+	results := make([]map[string]interface{}, 0, len(findings))
+	for _, f := range findings {
+		ruleID := f.Rule
+		if ruleID == "" {
+			ruleID = defaultRule
+		}
+		if _, ok := rules[ruleID]; !ok {
+			r := rule{ID: ruleID}
+			if f.Title != "" {
+				r.ShortDescription = map[string]string{"text": f.Title}
+			}
+			rules[ruleID] = r
+		}
+
+		result := map[string]interface{}{
+			"ruleId":  ruleID,
+			"level":   f.Severity,
+			"message": map[string]string{"text": f.Message},
+		}
+		if f.File != "" {
+			location := map[string]interface{}{
+				"artifactLocation": map[string]string{"uri": f.File},
+			}
+			if f.Line > 0 {
+				region := map[string]int{"startLine": f.Line, "endLine": f.EndLine}
+				if f.Column > 0 {
+					region["startColumn"] = f.Column
+				}
+				location["region"] = region
+			}
+			result["locations"] = []interface{}{map[string]interface{}{"physicalLocation": location}}
+		}
+		results = append(results, result)
+	}
+
+	ruleList := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		ruleList = append(ruleList, r)
+	}
+	sort.Slice(ruleList, func(i, j int) bool { return ruleList[i].ID < ruleList[j].ID })
+
+	log := map[string]interface{}{
+		"$schema": sarifSchema,
+		"version": "2.1.0",
+		"runs": []interface{}{map[string]interface{}{
+			"tool": map[string]interface{}{
+				"driver": map[string]interface{}{
+					"name":           tool,
+					"informationUri": "https://github.com/rizome-dev/opun",
+					"version":        OpunVersion,
+					"rules":          ruleList,
+				},
+			},
+			"results": results,
+		}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// GitHubAnnotations converts findings to check run annotations. Findings
+// without a file can't be annotated and are left out.
+func GitHubAnnotations(findings []Finding) []GitHubAnnotation {
+	annotations := make([]GitHubAnnotation, 0, len(findings))
+	for _, f := range findings {
+		if f.File == "" {
+			continue
+		}
+		line := f.Line
+		if line < 1 {
+			line = 1
+		}
+		annotations = append(annotations, GitHubAnnotation{
+			Path:            f.File,
+			StartLine:       line,
+			EndLine:         max(f.EndLine, line),
+			AnnotationLevel: GitHubAnnotationLevel(f.Severity),
+			Title:           f.Title,
+			Message:         f.Message,
+		})
+	}
+	return annotations
+}
+
+// GitHubAnnotationLevel maps a finding severity to a check run annotation level
+func GitHubAnnotationLevel(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "failure"
+	case SeverityNote:
+		return "notice"
+	default:
+		return "warning"
+	}
+}
+
+// writeFindings parses an agent's findings from its output and writes them
+// to dir as <agent>.sarif and <agent>.annotations.json. Output without
+// findings JSON is reported and written as no findings, so upload steps
+// still find the files.
+func writeFindings(agent *workflow.Agent, output, dir string) ([]Finding, error) {
+	workDir, _ := os.Getwd()
+	findings, err := ParseFindings(output, workDir)
+	if err != nil {
+		fmt.Printf("⚠️  %s: %v, recording no findings\n", agent.ID, err)
+	}
+
+	sarif, err := SARIF(agent.Findings.Tool, agent.ID, findings)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, agent.ID+".sarif"), append(sarif, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write findings: %w", err)
+	}
+	annotations, err := json.MarshalIndent(GitHubAnnotations(findings), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, agent.ID+".annotations.json"), append(annotations, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write findings: %w", err)
+	}
+
+	fmt.Printf("🔎 %s reported %d findings (%s)\n", agent.ID, len(findings), filepath.Join(dir, agent.ID+".sarif"))
+	return findings, nil
+}
+
+// agentFindings converts the findings in an interactive agent's output file,
+// writing them next to it
+func (e *InteractiveExecutor) agentFindings(agent *workflow.Agent) error {
+	if !findingsEnabled(agent) {
+		return nil
+	}
+	outputPath := e.agentOutputPath(agent)
+	if outputPath == "" {
+		return nil
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = writeFindings(agent, string(content), filepath.Dir(outputPath))
+	return err
+}
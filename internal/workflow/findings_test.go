@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const reviewOutput = "## Review\n\nTwo problems.\n\n```json\n{\"draft\": true}\n```\n\n```json\n" +
+	`{"findings": [
+  {"file": "/repo/internal/api.go", "line": 12, "severity": "critical", "rule": "nil-deref", "title": "Nil dereference", "message": "resp can be nil here"},
+  {"file": "./README.md", "severity": "nit", "message": "Typo in the install section"},
+  {"file": "main.go", "line": 3, "message": ""}
+]}` + "\n```\n"
+
+func TestParseFindings(t *testing.T) {
+	findings, err := ParseFindings(reviewOutput, "/repo")
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, Finding{File: "internal/api.go", Line: 12, EndLine: 12, Severity: SeverityError, Rule: "nil-deref", Title: "Nil dereference", Message: "resp can be nil here"}, findings[0])
+	assert.Equal(t, Finding{File: "README.md", Severity: SeverityNote, Message: "Typo in the install section"}, findings[1])
+
+	// A bare list works too, and no findings is not an error
+	findings, err = ParseFindings(`[{"file": "a.go", "line": 1, "severity": "medium", "message": "Unused"}]`, "")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarning, findings[0].Severity)
+	findings, err = ParseFindings("```json\n{\"findings\": []}\n```", "")
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	_, err = ParseFindings("Looks good to me!", "")
+	assert.Error(t, err)
+}
+
+func TestFindingsExports(t *testing.T) {
+	findings, err := ParseFindings(reviewOutput, "/repo")
+	require.NoError(t, err)
+
+	data, err := SARIF("", "review", findings)
+	require.NoError(t, err)
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	run := sarif.Runs[0]
+	assert.Equal(t, "opun", run.Tool.Driver.Name)
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "nil-deref", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "review", run.Tool.Driver.Rules[1].ID)
+	require.Len(t, run.Results, 2)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "internal/api.go", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 12, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Nil(t, run.Results[1].Locations[0].PhysicalLocation.Region)
+
+	annotations := GitHubAnnotations(append(findings, Finding{Severity: SeverityWarning, Message: "General remark"}))
+	assert.Equal(t, []GitHubAnnotation{
+		{Path: "internal/api.go", StartLine: 12, EndLine: 12, AnnotationLevel: "failure", Title: "Nil dereference", Message: "resp can be nil here"},
+		{Path: "README.md", StartLine: 1, EndLine: 1, AnnotationLevel: "notice", Message: "Typo in the install section"},
+	}, annotations)
+}
+
+func TestFindingsUnmarshalYAML(t *testing.T) {
+	var agent workflow.Agent
+	require.NoError(t, yaml.Unmarshal([]byte("findings: true"), &agent))
+	assert.True(t, agent.Findings.Enabled)
+
+	agent = workflow.Agent{}
+	require.NoError(t, yaml.Unmarshal([]byte("findings:\n  tool: security-review\n"), &agent))
+	assert.Equal(t, workflow.Findings{Enabled: true, Tool: "security-review"}, *agent.Findings)
+}
+
+func TestMatrixRunnerFindings(t *testing.T) {
+	t.Chdir(t.TempDir())
+	wf := &workflow.Workflow{
+		Name: "review",
+		Agents: []workflow.Agent{
+			{ID: "review", Provider: "mock", Prompt: "Review the diff", Findings: &workflow.Findings{Enabled: true}},
+			{ID: "summary", Provider: "mock", Prompt: "Summarize"},
+		},
+	}
+
+	var prompts []string
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.run = func(ctx context.Context, provider, model, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 1 {
+			return reviewOutput, nil
+		}
+		return "All done", nil
+	}
+	result, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, prompts[0], "list every finding in a fenced json code block")
+	assert.NotContains(t, prompts[1], "fenced json code block")
+	assert.Len(t, result.Findings["review"], 2)
+	assert.NotContains(t, result.Findings, "summary")
+
+	dir := filepath.Dir(result.Outputs["review"])
+	assert.FileExists(t, filepath.Join(dir, "review.sarif"))
+	data, err := os.ReadFile(filepath.Join(dir, "review.annotations.json"))
+	require.NoError(t, err)
+	var annotations []GitHubAnnotation
+	require.NoError(t, json.Unmarshal(data, &annotations))
+	assert.Len(t, annotations, 2)
+}
@@ -0,0 +1,156 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+const (
+	// defaultSummaryThreshold is the output size in bytes above which outputs are summarized
+	defaultSummaryThreshold = 8 * 1024
+	// defaultSummaryWords is the target length of a handoff brief
+	defaultSummaryWords = 200
+)
+
+// handoffSummarizer runs a summarization prompt on a provider and returns the brief
+type handoffSummarizer func(ctx context.Context, provider, model, prompt string) (string, error)
+
+// runHeadlessPrompt runs a prompt with the provider CLI in headless mode
+func runHeadlessPrompt(ctx context.Context, provider, model, prompt string) (string, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return providers.RunHeadless(ctx, provider, model, prompt, workDir)
+}
+
+// streamHeadlessPrompt runs a prompt with the provider CLI in headless mode,
+// passing it args and reporting progress for providers that stream
+func streamHeadlessPrompt(ctx context.Context, provider, model, prompt string, args []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return providers.HeadlessResult{}, err
+	}
+	return providers.RunHeadlessStream(ctx, provider, model, prompt, workDir, onProgress, args...)
+}
+
+// summaryPath returns where the brief for an output file is stored
+func summaryPath(outputPath string) string {
+	ext := filepath.Ext(outputPath)
+	return strings.TrimSuffix(outputPath, ext) + ".summary" + ext
+}
+
+// buildSummaryPrompt asks a provider to compress an agent's output into a brief
+func buildSummaryPrompt(agentName, content string, maxWords int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Summarize the following output of the workflow step \"%s\" in at most %d words. ", agentName, maxWords)
+	sb.WriteString("Keep decisions, file paths, names and open questions; drop everything else. ")
+	sb.WriteString("Reply with the summary only, as a markdown bullet list.\n\n---\n")
+	sb.WriteString(content)
+	return sb.String()
+}
+
+// summarizeHandoff compresses an agent's output into a brief when it exceeds
+// the configured threshold. The brief is written next to the output and used
+// for {{agent.output}} references; the full text stays at the original path.
+func (e *InteractiveExecutor) summarizeHandoff(ctx context.Context, agent *workflow.Agent) {
+	settings := e.workflow.Settings.HandoffSummary
+	if settings == nil || !settings.Enabled {
+		return
+	}
+
+	outputPath, ok := e.outputs[agent.ID]
+	if !ok {
+		return
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		return
+	}
+
+	threshold := settings.Threshold
+	if threshold <= 0 {
+		threshold = defaultSummaryThreshold
+	}
+	if len(content) <= threshold {
+		return
+	}
+
+	provider := settings.Provider
+	if provider == "" {
+		provider = agent.Provider
+	}
+	maxWords := settings.MaxWords
+	if maxWords <= 0 {
+		maxWords = defaultSummaryWords
+	}
+
+	fmt.Printf("🗜️  Summarizing %s output (%d bytes) with %s...\n", agent.Name, len(content), provider)
+
+	summarize := e.summarizer
+	if summarize == nil {
+		summarize = runHeadlessPrompt
+	}
+	brief, err := summarize(ctx, provider, settings.Model, buildSummaryPrompt(agent.Name, string(content), maxWords))
+	if err != nil || strings.TrimSpace(brief) == "" {
+		// Later agents still get the full output
+		fmt.Printf("⚠️  Could not summarize %s output, passing it on in full: %v\n", agent.Name, err)
+		return
+	}
+
+	briefPath := summaryPath(outputPath)
+	if err := os.WriteFile(briefPath, []byte(strings.TrimSpace(brief)+"\n"), 0644); err != nil {
+		fmt.Printf("⚠️  Could not save summary of %s output: %v\n", agent.Name, err)
+		return
+	}
+
+	e.summaries[agent.ID] = briefPath
+	fmt.Printf("📄 Summary saved to: %s\n", briefPath)
+}
+
+// handoffEntry describes a finished agent for the handoff context
+func (e *InteractiveExecutor) handoffEntry(agent *workflow.Agent) string {
+	entry := fmt.Sprintf("Agent %s (%s) completed", agent.Name, agent.Provider)
+	if briefPath, ok := e.summaries[agent.ID]; ok {
+		entry += fmt.Sprintf(" - summary: @%s (full output: %s)", briefPath, e.outputs[agent.ID])
+	}
+	return entry
+}
+
+// replaceOutputReferences turns {{id.output}} references into @file references.
+// Summarized outputs point at their brief; {{id.output_full}} always points at the full text.
+func (e *InteractiveExecutor) replaceOutputReferences(prompt string) string {
+	for id, outputPath := range e.outputs {
+		prompt = strings.ReplaceAll(prompt, fmt.Sprintf("{{%s.output_full}}", id), "@"+outputPath)
+
+		replacement := "@" + outputPath
+		if briefPath, ok := e.summaries[id]; ok {
+			replacement = fmt.Sprintf("@%s (summary; full output at %s)", briefPath, outputPath)
+		}
+		prompt = strings.ReplaceAll(prompt, fmt.Sprintf("{{%s.output}}", id), replacement)
+	}
+	return prompt
+}
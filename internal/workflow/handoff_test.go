@@ -0,0 +1,93 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHandoffExecutor(t *testing.T, settings *workflow.HandoffSummary, output string) (*InteractiveExecutor, *workflow.Agent) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "analysis.md")
+	require.NoError(t, os.WriteFile(outputPath, []byte(output), 0644))
+
+	agent := &workflow.Agent{ID: "analyze", Name: "Analyzer", Provider: "claude"}
+	executor := NewInteractiveExecutor()
+	executor.workflow = &workflow.Workflow{Settings: workflow.Settings{HandoffSummary: settings}}
+	executor.outputs[agent.ID] = outputPath
+	return executor, agent
+}
+
+func TestSummarizeHandoff(t *testing.T) {
+	large := strings.Repeat("finding ", 2000)
+
+	t.Run("summarizes outputs above the threshold", func(t *testing.T) {
+		executor, agent := newHandoffExecutor(t, &workflow.HandoffSummary{Enabled: true, Provider: "gemini", Model: "flash", MaxWords: 50}, large)
+
+		var gotProvider, gotModel, gotPrompt string
+		executor.summarizer = func(ctx context.Context, provider, model, prompt string) (string, error) {
+			gotProvider, gotModel, gotPrompt = provider, model, prompt
+			return "- key finding\n", nil
+		}
+
+		executor.summarizeHandoff(context.Background(), agent)
+
+		assert.Equal(t, "gemini", gotProvider)
+		assert.Equal(t, "flash", gotModel)
+		assert.Contains(t, gotPrompt, "at most 50 words")
+		assert.Contains(t, gotPrompt, "finding finding")
+
+		outputPath := executor.outputs[agent.ID]
+		briefPath := summaryPath(outputPath)
+		assert.Equal(t, briefPath, executor.summaries[agent.ID])
+		brief, err := os.ReadFile(briefPath)
+		require.NoError(t, err)
+		assert.Equal(t, "- key finding\n", string(brief))
+
+		assert.Equal(t, "Read @"+briefPath+" (summary; full output at "+outputPath+") or @"+outputPath,
+			executor.replaceOutputReferences("Read {{analyze.output}} or {{analyze.output_full}}"))
+		assert.Contains(t, executor.handoffEntry(agent), "summary: @"+briefPath)
+	})
+
+	t.Run("small outputs are passed through", func(t *testing.T) {
+		executor, agent := newHandoffExecutor(t, &workflow.HandoffSummary{Enabled: true}, "short")
+		executor.summarizer = func(ctx context.Context, provider, model, prompt string) (string, error) {
+			t.Fatal("summarizer should not run")
+			return "", nil
+		}
+
+		executor.summarizeHandoff(context.Background(), agent)
+		assert.Empty(t, executor.summaries)
+		assert.Equal(t, "@"+executor.outputs[agent.ID], executor.replaceOutputReferences("{{analyze.output}}"))
+		assert.Equal(t, "Agent Analyzer (claude) completed", executor.handoffEntry(agent))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		executor, agent := newHandoffExecutor(t, nil, large)
+		executor.summarizeHandoff(context.Background(), agent)
+		assert.Empty(t, executor.summaries)
+	})
+
+	t.Run("falls back to the full output on failure", func(t *testing.T) {
+		executor, agent := newHandoffExecutor(t, &workflow.HandoffSummary{Enabled: true, Threshold: 10}, large)
+		executor.summarizer = func(ctx context.Context, provider, model, prompt string) (string, error) {
+			assert.Equal(t, "claude", provider, "defaults to the agent's provider")
+			return "", errors.New("not logged in")
+		}
+
+		executor.summarizeHandoff(context.Background(), agent)
+		assert.Empty(t, executor.summaries)
+	})
+}
+
+func TestSummaryPath(t *testing.T) {
+	assert.Equal(t, "/out/plan.summary.md", summaryPath("/out/plan.md"))
+	assert.Equal(t, "/out/plan.summary", summaryPath("/out/plan"))
+}
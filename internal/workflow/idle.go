@@ -0,0 +1,207 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Actions when a provider session produces no output for idle_timeout
+const (
+	IdleNotify    = "notify"
+	IdlePrompt    = "prompt"
+	IdleTerminate = "terminate"
+)
+
+// idleCheckInterval is how often a session is checked for going quiet
+const idleCheckInterval = 5 * time.Second
+
+// idlePromptText is typed into a quiet session when on_idle is prompt
+const idlePromptText = "Are you still working on this? If you're done, save your results and finish; if you're stuck, say what you need."
+
+// validateIdleSettings checks an agent's idle timeout and action
+func validateIdleSettings(settings workflow.AgentSettings) error {
+	if settings.IdleTimeout != "" {
+		d, err := time.ParseDuration(settings.IdleTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid idle_timeout %q: %w", settings.IdleTimeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("idle_timeout must be positive, got %s", settings.IdleTimeout)
+		}
+	}
+	switch settings.OnIdle {
+	case "", IdleNotify, IdlePrompt, IdleTerminate:
+		return nil
+	default:
+		return fmt.Errorf("on_idle must be notify, prompt or terminate, got %q", settings.OnIdle)
+	}
+}
+
+// idleWatcher notices when a provider session stops producing output and
+// applies the agent's on_idle action once per quiet spell. A nil watcher,
+// for agents without an idle_timeout, does nothing.
+type idleWatcher struct {
+	agent   string
+	timeout time.Duration
+	action  string
+	prompt  func(text string)
+	kill    func()
+	now     func() time.Time
+
+	mu       sync.Mutex
+	last     time.Time
+	fired    bool
+	timedOut bool
+
+	stop chan struct{}
+	done chan struct{} // closed when checking ends, nil until started
+}
+
+// newIdleWatcher creates a watcher for an agent's session; prompt types into
+// the session and kill stops the provider. It returns nil when the agent has
+// no idle_timeout.
+func newIdleWatcher(agent *workflow.Agent, prompt func(text string), kill func()) *idleWatcher {
+	timeout, err := time.ParseDuration(agent.Settings.IdleTimeout)
+	if err != nil || timeout <= 0 {
+		return nil
+	}
+	action := agent.Settings.OnIdle
+	if action == "" {
+		action = IdleNotify
+	}
+	return &idleWatcher{
+		agent:   agent.Name,
+		timeout: timeout,
+		action:  action,
+		prompt:  prompt,
+		kill:    kill,
+		now:     time.Now,
+		last:    time.Now(),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Touch records output from the session
+func (w *idleWatcher) Touch() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.last = w.now()
+	w.fired = false
+	w.mu.Unlock()
+}
+
+// Write records output from the session, so the watcher can sit behind an
+// io.MultiWriter on the provider's output
+func (w *idleWatcher) Write(p []byte) (int, error) {
+	w.Touch()
+	return len(p), nil
+}
+
+// Start checks the session until Stop is called
+func (w *idleWatcher) Start(interval time.Duration) {
+	if w == nil {
+		return
+	}
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+}
+
+// check applies the idle action when the session has been quiet too long
+func (w *idleWatcher) check() {
+	w.mu.Lock()
+	idle := w.now().Sub(w.last)
+	if idle < w.timeout || w.fired || w.timedOut {
+		w.mu.Unlock()
+		return
+	}
+	w.fired = true
+	if w.action == IdleTerminate {
+		w.timedOut = true
+	}
+	w.mu.Unlock()
+
+	// The terminal is usually in raw mode, so lines end in \r\n
+	quiet := idle.Round(time.Second)
+	switch w.action {
+	case IdleTerminate:
+		fmt.Printf("\r\n🛑 %s has produced no output for %s; stopping the provider\r\n", w.agent, quiet)
+		w.kill()
+	case IdlePrompt:
+		fmt.Printf("\r\n⏳ %s has produced no output for %s; checking in\r\n", w.agent, quiet)
+		w.prompt(idlePromptText)
+	default:
+		fmt.Printf("\a\r\n⏳ %s has produced no output for %s\r\n", w.agent, quiet)
+	}
+}
+
+// Stop ends checking
+func (w *idleWatcher) Stop() {
+	if w == nil {
+		return
+	}
+	close(w.stop)
+	if w.done != nil {
+		<-w.done
+	}
+}
+
+// TimedOut reports whether the provider was stopped for being idle
+func (w *idleWatcher) TimedOut() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.timedOut
+}
+
+// typeIntoSession types text into a provider's terminal the way injected
+// prompts are typed, then submits it
+func typeIntoSession(w io.Writer, text string) {
+	for _, char := range text {
+		_, _ = w.Write([]byte(string(char)))
+		time.Sleep(5 * time.Millisecond)
+	}
+	_, _ = w.Write([]byte("\r"))
+}
+
+// timeoutAgent marks an agent whose session was stopped for being idle
+func (e *InteractiveExecutor) timeoutAgent(agent *workflow.Agent, state *workflow.AgentState) error {
+	err := e.handleAgentError(agent, state, Classify(ErrorTimeout, fmt.Errorf("timed out after %s without output", agent.Settings.IdleTimeout)))
+	state.Status = workflow.StatusTimedOut
+	return err
+}
@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateIdleSettings(t *testing.T) {
+	assert.NoError(t, validateIdleSettings(workflow.AgentSettings{}))
+	assert.NoError(t, validateIdleSettings(workflow.AgentSettings{IdleTimeout: "10m", OnIdle: IdlePrompt}))
+	assert.Error(t, validateIdleSettings(workflow.AgentSettings{IdleTimeout: "soon"}))
+	assert.Error(t, validateIdleSettings(workflow.AgentSettings{IdleTimeout: "-1m"}))
+	assert.Error(t, validateIdleSettings(workflow.AgentSettings{IdleTimeout: "10m", OnIdle: "panic"}))
+}
+
+func TestIdleWatcher(t *testing.T) {
+	assert.Nil(t, newIdleWatcher(&workflow.Agent{}, nil, nil))
+
+	var nilWatcher *idleWatcher
+	nilWatcher.Touch()
+	assert.False(t, nilWatcher.TimedOut())
+
+	clock := time.Now()
+	var prompts []string
+	kills := 0
+	newWatcher := func(action string) *idleWatcher {
+		w := newIdleWatcher(&workflow.Agent{Name: "writer", Settings: workflow.AgentSettings{IdleTimeout: "10m", OnIdle: action}},
+			func(text string) { prompts = append(prompts, text) },
+			func() { kills++ })
+		require.NotNil(t, w)
+		w.now = func() time.Time { return clock }
+		w.Touch()
+		return w
+	}
+
+	// Prompts once per quiet spell
+	w := newWatcher(IdlePrompt)
+	clock = clock.Add(5 * time.Minute)
+	w.check()
+	assert.Empty(t, prompts)
+	clock = clock.Add(6 * time.Minute)
+	w.check()
+	w.check()
+	assert.Equal(t, []string{idlePromptText}, prompts)
+	_, _ = w.Write([]byte("thinking..."))
+	clock = clock.Add(11 * time.Minute)
+	w.check()
+	assert.Len(t, prompts, 2)
+	assert.False(t, w.TimedOut())
+
+	// Terminate stops the provider and marks the session timed out
+	w = newWatcher(IdleTerminate)
+	clock = clock.Add(11 * time.Minute)
+	w.check()
+	assert.Equal(t, 1, kills)
+	assert.True(t, w.TimedOut())
+
+	// Notify is the default and only prints
+	w = newWatcher("")
+	assert.Equal(t, IdleNotify, w.action)
+	clock = clock.Add(11 * time.Minute)
+	w.check()
+	assert.Equal(t, 1, kills)
+	assert.Len(t, prompts, 2)
+}
+
+func TestTypeIntoSession(t *testing.T) {
+	var buf bytes.Buffer
+	typeIntoSession(&buf, "still there?")
+	assert.Equal(t, "still there?\r", buf.String())
+}
@@ -0,0 +1,315 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// maxInputAttempts is how many times an input step asks for a valid answer
+const maxInputAttempts = 3
+
+// operatorPrompt asks the operator a question, optionally with a fixed set of answers
+type operatorPrompt func(question string, options []string, current string) (string, error)
+
+// isInputStep reports whether a step asks the operator for input
+func isInputStep(agent *workflow.Agent) bool {
+	return agent.Type == workflow.StepTypeInput
+}
+
+// validateInputStep checks that an input step has a question and a variable
+func validateInputStep(agent *workflow.Agent) error {
+	if agent.Prompt == "" {
+		return fmt.Errorf("input step needs a prompt to show the operator")
+	}
+	if agent.Variable == "" {
+		return fmt.Errorf("input step needs a variable to store the answer in")
+	}
+	return nil
+}
+
+// inputStepModel asks the operator a question, answered with free-form text
+// or by picking one of the options
+type inputStepModel struct {
+	question string
+	options  []string
+	cursor   int
+	text     textarea.Model
+	value    string
+	err      error
+}
+
+func newInputStepModel(question string, options []string, current string) inputStepModel {
+	m := inputStepModel{
+		question: question,
+		options:  options,
+	}
+
+	if len(options) > 0 {
+		for i, option := range options {
+			if option == current {
+				m.cursor = i
+			}
+		}
+		return m
+	}
+
+	ta := textarea.New()
+	ta.Placeholder = "Type or paste your answer"
+	// Pasted logs and stack traces can be long
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.SetWidth(80)
+	ta.SetHeight(8)
+	ta.SetValue(current)
+	ta.Focus()
+	m.text = ta
+	return m
+}
+
+func (m inputStepModel) Init() tea.Cmd {
+	if len(m.options) > 0 {
+		return nil
+	}
+	return textarea.Blink
+}
+
+func (m inputStepModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.err = fmt.Errorf("cancelled")
+			return m, tea.Quit
+		}
+
+		if len(m.options) > 0 {
+			switch msg.String() {
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.options)-1 {
+					m.cursor++
+				}
+			case "enter":
+				m.value = m.options[m.cursor]
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		if msg.Type == tea.KeyCtrlD {
+			// Don't accept an empty answer
+			if strings.TrimSpace(m.text.Value()) == "" {
+				return m, nil
+			}
+			m.value = m.text.Value()
+			return m, tea.Quit
+		}
+	}
+
+	if len(m.options) > 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.text, cmd = m.text.Update(msg)
+	return m, cmd
+}
+
+func (m inputStepModel) View() string {
+	if m.err != nil || m.value != "" {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("62")).
+		Bold(true)
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(m.question) + "\n\n")
+
+	if len(m.options) > 0 {
+		for i, option := range m.options {
+			if i == m.cursor {
+				s.WriteString(selectedStyle.Render("> "+option) + "\n")
+			} else {
+				s.WriteString("  " + option + "\n")
+			}
+		}
+		s.WriteString("\n" + helpStyle.Render("(↑/↓ to choose, Enter to confirm, Esc to cancel)"))
+		return s.String()
+	}
+
+	s.WriteString(m.text.View() + "\n\n")
+	s.WriteString(helpStyle.Render("(Ctrl+D to submit, Esc to cancel)"))
+	return s.String()
+}
+
+// askOperator shows an input step's question and returns the answer
+func askOperator(question string, options []string, current string) (string, error) {
+	if utils.PlainUI() {
+		if len(options) > 0 {
+			i, err := utils.StdPrompter().Select(question, options)
+			if err != nil {
+				return "", err
+			}
+			return options[i], nil
+		}
+		return utils.StdPrompter().Ask(question, current)
+	}
+
+	p := tea.NewProgram(newInputStepModel(question, options, current))
+	m, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+
+	model, ok := m.(inputStepModel)
+	if !ok {
+		return "", fmt.Errorf("unexpected model type")
+	}
+	if model.err != nil {
+		return "", model.err
+	}
+	return model.value, nil
+}
+
+// promptForVariablesPlain asks for each variable on its own line, feeding the
+// answers through the variable prompt model so defaults, booleans and
+// required variables behave as they do in the TUI
+func promptForVariablesPlain(variables []promptVariable) (map[string]interface{}, error) {
+	m := initialVariablePromptModel(variables)
+	for {
+		v := m.variables[m.currentIndex]
+		question := v.Name
+		if v.Description != "" {
+			question += " (" + v.Description + ")"
+		}
+		if v.Required {
+			question += " *"
+		}
+		current := m.inputs[m.currentIndex].Value()
+		if current == "" && v.DefaultValue != nil {
+			current = fmt.Sprintf("%v", v.DefaultValue)
+		}
+
+		answer, err := utils.StdPrompter().Ask(question, current)
+		if err != nil {
+			return nil, err
+		}
+		index := m.currentIndex
+		m.inputs[index].SetValue(answer)
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(variablePromptModel)
+		if cmd != nil && index == len(m.variables)-1 {
+			return m.values, nil
+		}
+		if m.currentIndex == index {
+			if m.invalid != "" {
+				fmt.Printf("✗ %s\n", m.invalid)
+			} else {
+				fmt.Printf("%s is required\n", v.Name)
+			}
+		}
+	}
+}
+
+// executeInput runs an input step: it pauses the workflow, asks the operator
+// a question and stores the answer as a workflow variable for later agents
+func (e *InteractiveExecutor) executeInput(agent *workflow.Agent) error {
+	startTime := time.Now()
+	agentState := &workflow.AgentState{
+		AgentID:   agent.ID,
+		StartTime: &startTime,
+		Status:    workflow.StatusRunning,
+		Attempts:  1,
+	}
+
+	e.mu.Lock()
+	e.state.AgentStates[agent.ID] = agentState
+	e.state.CurrentAgent = agent.Name
+	current := ""
+	if value, ok := e.state.Variables[agent.Variable]; ok && value != nil {
+		current = fmt.Sprintf("%v", value)
+	}
+	e.mu.Unlock()
+
+	if err := validateInputStep(agent); err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	e.emit(workflow.EventVariableNeeded, agent.ID, e.expandVariables(agent.Prompt), map[string]interface{}{
+		"variable": agent.Variable,
+		"options":  agent.Options,
+		"current":  current,
+	})
+
+	ask := e.ask
+	if ask == nil {
+		ask = askOperator
+	}
+	answer, err := ask(e.expandVariables(agent.Prompt), agent.Options, current)
+	// Answers a declared variable's validation rejects are asked for again
+	if variable, declared := workflowVariable(e.workflow, agent.Variable); declared {
+		for attempt := 1; err == nil; attempt++ {
+			invalid := checkVariable(variable, answer)
+			if invalid == nil {
+				break
+			}
+			if attempt == maxInputAttempts {
+				err = Classify(ErrorInvalidVariable, invalid)
+				break
+			}
+			fmt.Printf("✗ %v\n", invalid)
+			answer, err = ask(e.expandVariables(agent.Prompt), agent.Options, answer)
+		}
+	}
+	if err != nil {
+		return e.handleAgentError(agent, agentState, fmt.Errorf("input step %s: %w", agent.ID, err))
+	}
+
+	e.mu.Lock()
+	if e.state.Variables == nil {
+		e.state.Variables = make(map[string]interface{})
+	}
+	e.state.Variables[agent.Variable] = answer
+	e.mu.Unlock()
+
+	endTime := time.Now()
+	agentState.Status = workflow.StatusCompleted
+	agentState.EndTime = &endTime
+	agentState.Output = answer
+	fmt.Printf("✅ Saved answer as {{%s}}\n", agent.Variable)
+	return nil
+}
@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sendKeys(m tea.Model, keys ...tea.KeyMsg) tea.Model {
+	for _, key := range keys {
+		m, _ = m.Update(key)
+	}
+	return m
+}
+
+func TestInputStepModelText(t *testing.T) {
+	m := sendKeys(newInputStepModel("Paste the error", nil, ""),
+		tea.KeyMsg{Type: tea.KeyCtrlD},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("panic: nil map")},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("at main.go:12")},
+	).(inputStepModel)
+	assert.Empty(t, m.value, "empty answers and newlines don't submit")
+
+	m = sendKeys(m, tea.KeyMsg{Type: tea.KeyCtrlD}).(inputStepModel)
+	assert.Equal(t, "panic: nil map\nat main.go:12", m.value)
+	assert.NoError(t, m.err)
+}
+
+func TestInputStepModelOptions(t *testing.T) {
+	options := []string{"staging", "production", "dev"}
+
+	m := newInputStepModel("Which environment?", options, "production")
+	assert.Equal(t, 1, m.cursor)
+
+	m = sendKeys(m,
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyUp},
+		tea.KeyMsg{Type: tea.KeyUp},
+		tea.KeyMsg{Type: tea.KeyUp},
+		tea.KeyMsg{Type: tea.KeyEnter},
+	).(inputStepModel)
+	assert.Equal(t, "staging", m.value)
+
+	m = sendKeys(newInputStepModel("Which environment?", options, ""), tea.KeyMsg{Type: tea.KeyEsc}).(inputStepModel)
+	assert.Error(t, m.err)
+	assert.Empty(t, m.value)
+}
+
+func TestExecuteInput(t *testing.T) {
+	e := NewInteractiveExecutor()
+	e.state = &workflow.ExecutionState{
+		Variables:   map[string]interface{}{"env": "staging", "staging_error": "old"},
+		AgentStates: make(map[string]*workflow.AgentState),
+	}
+
+	var asked, current string
+	e.ask = func(question string, options []string, value string) (string, error) {
+		asked, current = question, value
+		return "connection refused", nil
+	}
+
+	agent := &workflow.Agent{
+		ID:       "error",
+		Name:     "Staging error",
+		Type:     workflow.StepTypeInput,
+		Prompt:   "Paste the error from {{env}}",
+		Variable: "staging_error",
+	}
+	require.NoError(t, e.executeInput(agent))
+
+	assert.Equal(t, "Paste the error from staging", asked)
+	assert.Equal(t, "old", current)
+	assert.Equal(t, "connection refused", e.state.Variables["staging_error"])
+	assert.Equal(t, workflow.StatusCompleted, e.state.AgentStates["error"].Status)
+
+	e.ask = func(string, []string, string) (string, error) {
+		return "", errors.New("cancelled")
+	}
+	err := e.executeInput(agent)
+	assert.ErrorContains(t, err, "cancelled")
+	assert.Equal(t, workflow.StatusFailed, e.state.AgentStates["error"].Status)
+}
+
+func TestParseInputStep(t *testing.T) {
+	p := NewParser(t.TempDir())
+
+	wf, err := p.Parse([]byte(`
+name: triage
+agents:
+  - id: env
+    type: input
+    prompt: Which environment failed?
+    variable: env
+    options: [staging, production]
+  - id: fix
+    provider: claude
+    prompt: Fix the {{env}} failure
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging", "production"}, wf.Agents[0].Options)
+
+	_, err = p.Parse([]byte(`
+name: triage
+agents:
+  - id: env
+    type: input
+    prompt: Which environment failed?
+`))
+	assert.ErrorContains(t, err, "needs a variable")
+}
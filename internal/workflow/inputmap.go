@@ -0,0 +1,447 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Types an input can be declared as
+const (
+	InputTypeString  = "string"
+	InputTypeNumber  = "number"
+	InputTypeBoolean = "boolean"
+	InputTypeList    = "list"
+	InputTypeObject  = "object"
+)
+
+var (
+	// inputExpressionPattern matches the {{...}} references in an input's value
+	inputExpressionPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+	// inputPromptPattern matches {{input.name}} and {{inputs}} in a prompt
+	inputPromptPattern = regexp.MustCompile(`\{\{\s*(inputs|input\.[A-Za-z0-9_-]+)\s*\}\}`)
+	// jsonBlockPattern matches a fenced JSON block in an output
+	jsonBlockPattern = regexp.MustCompile("(?s)```(?:json)?\\s*\\n(.*?)\\n```")
+)
+
+// inputSpec is one entry of an agent's input mapping. An entry is either the
+// value itself or a map with value, type and required.
+type inputSpec struct {
+	Value    interface{}
+	Type     string
+	Required bool
+}
+
+// parseInputSpec reads an entry of an agent's input mapping
+func parseInputSpec(raw interface{}) (inputSpec, error) {
+	spec := inputSpec{Value: raw, Required: true}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return spec, nil
+	}
+	value, ok := m["value"]
+	if !ok {
+		// A map without a value is a literal object
+		return spec, nil
+	}
+	spec.Value = value
+	for key, v := range m {
+		switch key {
+		case "value":
+		case "type":
+			spec.Type, _ = v.(string)
+			switch spec.Type {
+			case InputTypeString, InputTypeNumber, InputTypeBoolean, InputTypeList, InputTypeObject:
+			default:
+				return spec, fmt.Errorf("unknown type %v, expected string, number, boolean, list or object", v)
+			}
+		case "required":
+			if spec.Required, ok = v.(bool); !ok {
+				return spec, fmt.Errorf("required must be true or false")
+			}
+		default:
+			return spec, fmt.Errorf("unknown field %s", key)
+		}
+	}
+	return spec, nil
+}
+
+// validateInputMapping checks an agent's input mapping. Outputs and
+// artifacts can only be taken from agents that run before it.
+func validateInputMapping(agent *workflow.Agent, earlier map[string]bool) error {
+	for _, name := range sortedInputNames(agent.Input) {
+		spec, err := parseInputSpec(agent.Input[name])
+		if err != nil {
+			return fmt.Errorf("input %s: %w", name, err)
+		}
+		text, ok := spec.Value.(string)
+		if !ok {
+			continue
+		}
+		for _, m := range inputExpressionPattern.FindAllStringSubmatch(text, -1) {
+			parts := strings.Split(m[1], ".")
+			if len(parts) < 2 || (parts[1] != "output" && parts[1] != "output_full" && parts[1] != "artifacts") {
+				continue
+			}
+			if parts[0] == agent.ID || !earlier[parts[0]] {
+				return fmt.Errorf("input %s: %s refers to %s, which doesn't run before this agent", name, m[0], parts[0])
+			}
+			if len(parts) > 2 && (parts[1] != "output" || parts[2] != "json") {
+				return fmt.Errorf("input %s: invalid reference %s, expected %s.output.json.<path>", name, m[0], parts[0])
+			}
+		}
+	}
+	return nil
+}
+
+// inputSource resolves the references in input values
+type inputSource struct {
+	vars map[string]interface{}
+	// output returns the output file of an agent that ran
+	output func(id string) (string, bool)
+	// artifacts returns the files an agent that ran produced
+	artifacts func(id string) ([]string, bool)
+}
+
+// resolveInputs resolves an agent's input mapping and checks each value
+// against its declared type
+func resolveInputs(agent *workflow.Agent, src inputSource) (map[string]interface{}, error) {
+	inputs := make(map[string]interface{}, len(agent.Input))
+	for _, name := range sortedInputNames(agent.Input) {
+		spec, err := parseInputSpec(agent.Input[name])
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		value, found, err := resolveInputValue(spec.Value, src)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		if !found {
+			if spec.Required {
+				return nil, fmt.Errorf("input %s: %v has nothing to resolve to", name, spec.Value)
+			}
+			continue
+		}
+		if value, err = convertInput(value, spec.Type); err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		inputs[name] = value
+	}
+	return inputs, nil
+}
+
+// resolveInputValue resolves an input value. A value that is one reference
+// keeps the type it resolves to; references inside longer text are
+// rendered as text.
+func resolveInputValue(value interface{}, src inputSource) (interface{}, bool, error) {
+	text, ok := value.(string)
+	if !ok {
+		return value, true, nil
+	}
+	if m := inputExpressionPattern.FindStringSubmatch(text); m != nil && m[0] == strings.TrimSpace(text) {
+		return resolveInputReference(m[1], src)
+	}
+
+	var resolveErr error
+	found := true
+	text = inputExpressionPattern.ReplaceAllStringFunc(text, func(match string) string {
+		ref := inputExpressionPattern.FindStringSubmatch(match)[1]
+		v, ok, err := resolveInputReference(ref, src)
+		if err != nil && resolveErr == nil {
+			resolveErr = err
+		}
+		if !ok {
+			found = false
+			return match
+		}
+		return renderInput(v)
+	})
+	return text, found, resolveErr
+}
+
+// resolveInputReference resolves a variable, {{id.output}},
+// {{id.output.json.path}} or {{id.artifacts}}
+func resolveInputReference(ref string, src inputSource) (interface{}, bool, error) {
+	parts := strings.Split(ref, ".")
+	if len(parts) >= 2 {
+		switch parts[1] {
+		case "output", "output_full":
+			path, ok := src.output(parts[0])
+			if !ok {
+				return nil, false, nil
+			}
+			// #nosec G304 -- output file of an earlier agent of the run
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", ref, err)
+			}
+			if len(parts) == 2 {
+				return strings.TrimSpace(string(data)), true, nil
+			}
+			doc, err := parseOutputJSON(string(data))
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: output of %s is not JSON: %w", ref, parts[0], err)
+			}
+			v, err := lookupJSONPath(doc, parts[3:])
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", ref, err)
+			}
+			return v, true, nil
+		case "artifacts":
+			files, ok := src.artifacts(parts[0])
+			if !ok {
+				return nil, false, nil
+			}
+			list := make([]interface{}, len(files))
+			for i, file := range files {
+				list[i] = file
+			}
+			return list, true, nil
+		}
+	}
+	v, ok := src.vars[ref]
+	return v, ok, nil
+}
+
+// parseOutputJSON reads an output as JSON: the whole output, or its first
+// fenced JSON block when the agent wrapped it in prose
+func parseOutputJSON(text string) (interface{}, error) {
+	var doc interface{}
+	err := json.Unmarshal([]byte(strings.TrimSpace(text)), &doc)
+	if err == nil {
+		return doc, nil
+	}
+	if m := jsonBlockPattern.FindStringSubmatch(text); m != nil {
+		if blockErr := json.Unmarshal([]byte(m[1]), &doc); blockErr == nil {
+			return doc, nil
+		}
+	}
+	return nil, err
+}
+
+// lookupJSONPath walks object keys and list indices
+func lookupJSONPath(doc interface{}, path []string) (interface{}, error) {
+	v := doc
+	for i, key := range path {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("no %s in the output", strings.Join(path[:i+1], "."))
+			}
+			v = next
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("%s is not an index of a list of %d", strings.Join(path[:i+1], "."), len(node))
+			}
+			v = node[index]
+		default:
+			return nil, fmt.Errorf("%s is not an object or a list", strings.Join(path[:i], "."))
+		}
+	}
+	return v, nil
+}
+
+// convertInput checks a resolved value against the declared type. Text is
+// converted to numbers and booleans, since outputs and variables are text.
+func convertInput(value interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case "":
+		return value, nil
+	case InputTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case InputTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return n, nil
+			}
+		}
+	case InputTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	case InputTypeList:
+		if list, ok := value.([]interface{}); ok {
+			return list, nil
+		}
+	case InputTypeObject:
+		if m, ok := value.(map[string]interface{}); ok {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %s", typ, inputTypeOf(value))
+}
+
+// inputTypeOf names the type of a resolved value
+func inputTypeOf(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return InputTypeString
+	case float64, int:
+		return InputTypeNumber
+	case bool:
+		return InputTypeBoolean
+	case []interface{}:
+		return InputTypeList
+	case map[string]interface{}:
+		return InputTypeObject
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// renderInput renders a resolved value in a prompt: text as it is, anything
+// else as JSON
+func renderInput(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// renderInputReferences replaces {{input.name}} with an input's value and
+// {{inputs}} with all of them as JSON
+func renderInputReferences(prompt string, inputs map[string]interface{}) string {
+	return inputPromptPattern.ReplaceAllStringFunc(prompt, func(match string) string {
+		ref := inputPromptPattern.FindStringSubmatch(match)[1]
+		if ref == "inputs" {
+			return renderInput(inputs)
+		}
+		value, ok := inputs[strings.TrimPrefix(ref, "input.")]
+		if !ok {
+			return match
+		}
+		return renderInput(value)
+	})
+}
+
+// sortedInputNames returns the names of an input mapping in order, so
+// errors are repeatable
+func sortedInputNames(input map[string]interface{}) []string {
+	names := make([]string, 0, len(input))
+	for name := range input {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// workflowAgent returns the agent with the given ID, nil when there's none
+func workflowAgent(wf *workflow.Workflow, id string) *workflow.Agent {
+	for i := range wf.Agents {
+		if wf.Agents[i].ID == id {
+			return &wf.Agents[i]
+		}
+	}
+	return nil
+}
+
+// producedFiles returns an agent's output file and the produces files it
+// wrote
+func producedFiles(agent *workflow.Agent, output string, expand func(string) string) []string {
+	var files []string
+	if output != "" {
+		if _, err := os.Stat(output); err == nil {
+			files = append(files, output)
+		}
+	}
+	for _, artifact := range agent.Produces {
+		path := expand(artifact.File)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// renderAgentInputs resolves an agent's input mapping against the run so far
+// and renders the {{input.name}} references in its prompt
+func (e *InteractiveExecutor) renderAgentInputs(agent *workflow.Agent, prompt string) (string, map[string]interface{}, error) {
+	if len(agent.Input) == 0 {
+		return prompt, nil, nil
+	}
+
+	inputs, err := resolveInputs(agent, e.inputSource())
+	if err != nil {
+		return "", nil, err
+	}
+
+	e.mu.Lock()
+	e.inspectStep(agent).Inputs = redactVariables(inputs)
+	e.mu.Unlock()
+	return renderInputReferences(prompt, inputs), inputs, nil
+}
+
+// inputSource resolves references against the run so far: its variables and
+// the outputs and artifacts of the agents that completed
+func (e *InteractiveExecutor) inputSource() inputSource {
+	e.mu.Lock()
+	vars := make(map[string]interface{}, len(e.state.Variables))
+	for name, value := range e.state.Variables {
+		vars[name] = value
+	}
+	outputs := make(map[string]string, len(e.outputs))
+	for id, path := range e.outputs {
+		outputs[id] = path
+	}
+	ran := make(map[string]bool)
+	for id, state := range e.state.AgentStates {
+		ran[id] = state.Status == workflow.StatusCompleted
+	}
+	e.mu.Unlock()
+
+	return inputSource{
+		vars: vars,
+		output: func(id string) (string, bool) {
+			path, ok := outputs[id]
+			return path, ok && ran[id]
+		},
+		artifacts: func(id string) ([]string, bool) {
+			other := workflowAgent(e.workflow, id)
+			if other == nil || !ran[id] {
+				return nil, false
+			}
+			return producedFiles(other, outputs[id], e.expandVariables), true
+		},
+	}
+}
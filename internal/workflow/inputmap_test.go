@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveInputs(t *testing.T) {
+	dir := t.TempDir()
+	plan := filepath.Join(dir, "plan.md")
+	require.NoError(t, os.WriteFile(plan, []byte("Here is the plan:\n```json\n{\"spec\": {\"title\": \"Fix login\"}, \"steps\": [\"a\", \"b\"], \"count\": \"3\"}\n```\n"), 0644))
+	report := filepath.Join(dir, "report.txt")
+	require.NoError(t, os.WriteFile(report, []byte("ok"), 0644))
+
+	src := inputSource{
+		vars: map[string]interface{}{"focus": "security"},
+		output: func(id string) (string, bool) {
+			return plan, id == "planner"
+		},
+		artifacts: func(id string) ([]string, bool) {
+			return []string{plan, report}, id == "analyzer"
+		},
+	}
+
+	agent := &workflow.Agent{ID: "fix", Input: map[string]interface{}{
+		"spec":   "{{planner.output.json.spec}}",
+		"first":  "{{planner.output.json.steps.0}}",
+		"count":  map[string]interface{}{"value": "{{planner.output.json.count}}", "type": "number"},
+		"files":  map[string]interface{}{"value": "{{analyzer.artifacts}}", "type": "list"},
+		"title":  "{{focus}} fix: {{planner.output.json.spec.title}}",
+		"limit":  5,
+		"ticket": map[string]interface{}{"value": "{{ticket}}", "required": false},
+	}}
+	inputs, err := resolveInputs(agent, src)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "Fix login"}, inputs["spec"])
+	assert.Equal(t, "a", inputs["first"])
+	assert.Equal(t, 3.0, inputs["count"])
+	assert.Equal(t, []interface{}{plan, report}, inputs["files"])
+	assert.Equal(t, "security fix: Fix login", inputs["title"])
+	assert.Equal(t, 5, inputs["limit"])
+	assert.NotContains(t, inputs, "ticket")
+
+	prompt := renderInputReferences("Implement {{input.spec}} ({{input.title}}) {{input.missing}}", inputs)
+	assert.Contains(t, prompt, `"title": "Fix login"`)
+	assert.Contains(t, prompt, "(security fix: Fix login)")
+	assert.Contains(t, prompt, "{{input.missing}}")
+
+	for name, tc := range map[string]struct {
+		input interface{}
+		err   string
+	}{
+		"type":     {map[string]interface{}{"value": "{{planner.output.json.steps}}", "type": "object"}, "expected object, got list"},
+		"path":     {"{{planner.output.json.spec.owner}}", "no spec.owner in the output"},
+		"missing":  {"{{ticket}}", "has nothing to resolve to"},
+		"not run":  {"{{reviewer.output}}", "has nothing to resolve to"},
+		"bad type": {map[string]interface{}{"value": "x", "type": "date"}, "unknown type"},
+	} {
+		_, err := resolveInputs(&workflow.Agent{ID: "fix", Input: map[string]interface{}{"x": tc.input}}, src)
+		assert.ErrorContains(t, err, tc.err, name)
+	}
+}
+
+func TestValidateInputMapping(t *testing.T) {
+	parse := func(input string) error {
+		_, err := NewParser("").Parse([]byte(`
+name: review
+agents:
+  - id: planner
+    provider: claude
+    prompt: Plan
+  - id: fix
+    provider: claude
+    prompt: "Fix {{input.spec}}"
+    input:
+      spec: ` + input + `
+  - id: check
+    provider: claude
+    prompt: Check
+`))
+		return err
+	}
+
+	assert.NoError(t, parse(`"{{planner.output.json.spec}}"`))
+	assert.NoError(t, parse(`{value: "{{planner.artifacts}}", type: list}`))
+	assert.ErrorContains(t, parse(`"{{check.output}}"`), "doesn't run before this agent")
+	assert.ErrorContains(t, parse(`"{{fix.output}}"`), "doesn't run before this agent")
+	assert.ErrorContains(t, parse(`"{{planner.output.spec}}"`), "invalid reference")
+	assert.ErrorContains(t, parse(`{value: x, type: date}`), "unknown type")
+}
+
+func TestMatrixRunnerInputs(t *testing.T) {
+	wf := &workflow.Workflow{
+		Name: "review",
+		Agents: []workflow.Agent{
+			{ID: "planner", Provider: "claude", Prompt: "Plan"},
+			{ID: "fix", Provider: "claude", Prompt: "Fix {{input.spec}} in {{input.count}} steps", Input: map[string]interface{}{
+				"spec":  "{{planner.output.json.spec}}",
+				"count": map[string]interface{}{"value": "{{planner.output.json.count}}", "type": "number"},
+			}},
+		},
+	}
+
+	var prompts []string
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.run = func(ctx context.Context, provider, model, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if strings.HasPrefix(prompt, "Plan") {
+			return `{"spec": "login", "count": 2}`, nil
+		}
+		return "done", nil
+	}
+	_, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Fix login in 2 steps", prompts[1])
+
+	wf.Agents[1].Input["count"] = map[string]interface{}{"value": "{{planner.output.json.spec}}", "type": "number"}
+	result, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.Error(t, err)
+	assert.Contains(t, result.Error, "agent fix: input count: expected number, got string")
+}
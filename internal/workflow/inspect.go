@@ -0,0 +1,209 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// inspectDirName is the directory under the run history keeping what every
+// step of a run was given, for `opun inspect`
+const inspectDirName = "inspect"
+
+// Kinds of decisions recorded for a step
+const (
+	DecisionCondition   = "condition"
+	DecisionPromptGuard = "prompt_guard"
+	DecisionPolicy      = "policy"
+	DecisionNudge       = "output_nudge"
+	DecisionProduces    = "produces"
+	DecisionRetry       = "retry"
+	DecisionFileRefs    = "file_refs"
+	DecisionReady       = "ready"
+)
+
+// outputReferencePattern matches {{id.output}} and {{id.output_full}} references
+var outputReferencePattern = regexp.MustCompile(`\{\{([A-Za-z0-9_-]+)\.(output|output_full)\}\}`)
+
+// RunInspection is what each step of a run was given and the decisions
+// taken about it, so a step's behavior can be reconstructed afterwards
+type RunInspection struct {
+	RunID    string            `json:"run_id"`
+	Workflow string            `json:"workflow"`
+	Steps    []*StepInspection `json:"steps"`
+}
+
+// StepInspection is one agent's prompts, one per attempt, and decisions
+type StepInspection struct {
+	Agent    string `json:"agent"`
+	Name     string `json:"name,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	// Inputs is the resolved input mapping, secret-looking names hidden
+	Inputs    map[string]interface{} `json:"inputs,omitempty"`
+	Attempts  []PromptAttempt        `json:"attempts,omitempty"`
+	Decisions []Decision             `json:"decisions,omitempty"`
+}
+
+// PromptAttempt is a prompt as it was injected into a provider
+type PromptAttempt struct {
+	Attempt int       `json:"attempt"`
+	Time    time.Time `json:"time"`
+	// Variables are the workflow variables when the prompt was rendered, secret-looking names hidden
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	// References are the output references in the prompt template and what they resolved to
+	References map[string]string `json:"references,omitempty"`
+	// Prompt is the fully rendered prompt, scrubbed when settings.redact is on
+	Prompt string `json:"prompt"`
+	Tokens int    `json:"tokens,omitempty"`
+}
+
+// Decision is a choice the executor made about a step, e.g. skipping it on
+// its condition or retrying it because its outputs were missing
+type Decision struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Outcome string    `json:"outcome"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// InspectionPath returns where a run's inspection is kept
+func InspectionPath(runID string) (string, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return "", err
+	}
+	return HistoryInspectionPath(dir, runID), nil
+}
+
+// HistoryInspectionPath returns where a run's inspection is kept in a run
+// history directory
+func HistoryInspectionPath(dir, runID string) string {
+	return filepath.Join(dir, inspectDirName, pathSafe(runID)+".json")
+}
+
+// LoadInspection reads a run's inspection from path
+func LoadInspection(path string) (*RunInspection, error) {
+	// #nosec G304 -- inspections live in the run history
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var inspection RunInspection
+	if err := json.Unmarshal(data, &inspection); err != nil {
+		return nil, fmt.Errorf("invalid inspection %s: %w", path, err)
+	}
+	return &inspection, nil
+}
+
+// Step returns the inspection of an agent, given by ID or name
+func (r *RunInspection) Step(idOrName string) *StepInspection {
+	for _, step := range r.Steps {
+		if step.Agent == idOrName || step.Name == idOrName {
+			return step
+		}
+	}
+	return nil
+}
+
+// inspectStep returns an agent's inspection, adding it on first use.
+// Callers must hold e.mu.
+func (e *InteractiveExecutor) inspectStep(agent *workflow.Agent) *StepInspection {
+	if e.inspection == nil {
+		e.inspection = &RunInspection{RunID: e.runID, Workflow: e.workflow.Name}
+	}
+	for _, step := range e.inspection.Steps {
+		if step.Agent == agent.ID {
+			return step
+		}
+	}
+	step := &StepInspection{Agent: agent.ID, Name: agent.Name, Provider: agent.Provider, Model: agent.Model}
+	e.inspection.Steps = append(e.inspection.Steps, step)
+	return step
+}
+
+// recordPrompt records the prompt an agent is about to get, rendered from
+// template, with the variables and output references it was rendered from
+func (e *InteractiveExecutor) recordPrompt(agent *workflow.Agent, template, prompt string) {
+	references := make(map[string]string)
+	for _, m := range outputReferencePattern.FindAllStringSubmatch(template, -1) {
+		resolved := e.replaceOutputReferences(m[0])
+		if resolved == m[0] {
+			resolved = "(unresolved)"
+		}
+		references[m[0]] = resolved
+	}
+	if e.redactor != nil {
+		prompt, _ = e.redactor.scrub(prompt)
+	}
+
+	e.mu.Lock()
+	step := e.inspectStep(agent)
+	step.Attempts = append(step.Attempts, PromptAttempt{
+		Attempt:    len(step.Attempts) + 1,
+		Time:       time.Now(),
+		Variables:  redactVariables(e.state.Variables),
+		References: references,
+		Prompt:     prompt,
+		Tokens:     e.promptTokens[agent.ID],
+	})
+	e.mu.Unlock()
+	e.saveInspection()
+}
+
+// recordDecision records a decision taken about an agent
+func (e *InteractiveExecutor) recordDecision(agent *workflow.Agent, kind, outcome, detail string) {
+	e.mu.Lock()
+	step := e.inspectStep(agent)
+	step.Decisions = append(step.Decisions, Decision{Time: time.Now(), Kind: kind, Outcome: outcome, Detail: detail})
+	e.mu.Unlock()
+	e.saveInspection()
+}
+
+// saveInspection writes the run's inspection to the run history
+func (e *InteractiveExecutor) saveInspection() {
+	if e.runID == "" {
+		return
+	}
+	path, err := InspectionPath(e.runID)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	data, err := json.MarshalIndent(e.inspection, "", "  ")
+	e.mu.Unlock()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	// Prompts may quote private code, keep them to the user
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInspection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	redactor, err := NewRedactor(&workflow.Redact{Enabled: true})
+	require.NoError(t, err)
+	e := NewInteractiveExecutor()
+	e.runID = "7-3000"
+	e.redactor = redactor
+	e.workflow = &workflow.Workflow{Name: "review"}
+	e.state = &workflow.ExecutionState{Variables: map[string]interface{}{"focus": "security", "api_token": "abc"}}
+	e.outputs["plan"] = "/tmp/out/plan.md"
+
+	fixer := &workflow.Agent{ID: "fix", Name: "Fixer", Provider: "claude", Condition: `vars.focus == "security"`}
+	run, err := e.shouldRunAgent(fixer)
+	require.NoError(t, err)
+	assert.True(t, run)
+
+	key := "sk-ant-" + strings.Repeat("a", 30)
+	e.recordPrompt(fixer, "Fix {{plan.output}} and {{check.output}}", "Fix @/tmp/out/plan.md with "+key)
+	e.recordDecision(fixer, DecisionRetry, "retry 1/2", "missing report.md")
+	e.recordPrompt(fixer, "Fix again", "Fix again")
+
+	path, err := InspectionPath("7-3000")
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	inspection, err := LoadInspection(path)
+	require.NoError(t, err)
+	assert.Equal(t, "review", inspection.Workflow)
+	step := inspection.Step("Fixer")
+	require.NotNil(t, step)
+	assert.Equal(t, "fix", step.Agent)
+
+	require.Len(t, step.Decisions, 2)
+	assert.Equal(t, DecisionCondition, step.Decisions[0].Kind)
+	assert.Equal(t, "run", step.Decisions[0].Outcome)
+	assert.Equal(t, DecisionRetry, step.Decisions[1].Kind)
+
+	require.Len(t, step.Attempts, 2)
+	first := step.Attempts[0]
+	assert.Equal(t, 1, first.Attempt)
+	assert.Equal(t, "security", first.Variables["focus"])
+	assert.Equal(t, "[REDACTED]", first.Variables["api_token"])
+	assert.Equal(t, "@/tmp/out/plan.md", first.References["{{plan.output}}"])
+	assert.Equal(t, "(unresolved)", first.References["{{check.output}}"])
+	assert.NotContains(t, first.Prompt, key)
+	assert.Equal(t, 0, redactor.Report().Total, "inspection scrubbing isn't counted as output redaction")
+	assert.Equal(t, 2, step.Attempts[1].Attempt)
+
+	assert.Nil(t, inspection.Step("missing"))
+}
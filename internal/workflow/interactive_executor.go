@@ -19,6 +19,7 @@ package workflow
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -36,6 +37,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/creack/pty"
+	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/pkg/core"
 	"github.com/rizome-dev/opun/pkg/workflow"
 	"golang.org/x/term"
@@ -67,6 +71,66 @@ type InteractiveExecutor struct {
 	ctrlCCount    int
 	lastCtrlCTime time.Time
 	ctrlCMutex    sync.Mutex
+
+	// Optional handler notified of workflow progress events
+	eventHandler func(workflow.WorkflowEvent)
+	outputEvents bool
+
+	// Last agent that finished and its provider session, for continue_session
+	previousAgent *workflow.Agent
+	sessionID     string
+
+	// Summary files of large agent outputs, by agent ID
+	summaries  map[string]string
+	summarizer handoffSummarizer
+
+	// Attach server of a detached run, if any
+	attach *AttachServer
+
+	// Run manifest inputs
+	inventory        RunInventory
+	providerVersions map[string]string
+	versionLookup    providerVersionLookup
+	environment      *RunEnvironment
+
+	// Asks the operator for input steps; nil uses the terminal form
+	ask operatorPrompt
+
+	// Resolves promptgarden:// references in system prompts
+	promptResolver PromptResolver
+
+	// Scrubs secrets from agent outputs; nil when redaction is off
+	redactor *Redactor
+
+	// Checked against every prompt before it is sent; nil allows everything
+	promptPolicy *PromptPolicy
+
+	// Run ID that workspace snapshots are stored under
+	runID string
+
+	// Provider resource usage by agent ID, for the run manifest
+	resources map[string]*ResourceUsage
+
+	// Error that ended the run, for the run manifest
+	runErr error
+
+	// Estimated prompt size in tokens by agent ID
+	promptTokens map[string]int
+
+	// Traces the run; providers get its trace context. Nil when tracing is off.
+	tracer *RunTracer
+
+	// Records started providers so `opun recover` can find them after a crash
+	sessions *SessionRegistry
+
+	// What every step was given and the decisions taken, for `opun inspect`
+	inspection *RunInspection
+
+	// Where settings.storage uploaded the run's artifacts
+	stored *StoredArtifacts
+
+	// Failures injected into mock provider sessions with --chaos
+	chaos *Chaos
 }
 
 // NewInteractiveExecutor creates a new interactive workflow executor
@@ -74,13 +138,30 @@ func NewInteractiveExecutor() *InteractiveExecutor {
 	return &InteractiveExecutor{
 		outputs:        make(map[string]string),
 		handoffContext: make([]string, 0),
+		summaries:      make(map[string]string),
 	}
 }
 
-// Execute executes a workflow with interactive sessions
-func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow, variables map[string]interface{}) error {
+// execute executes a workflow with interactive sessions
+func (e *InteractiveExecutor) execute(ctx context.Context, wf *workflow.Workflow, variables map[string]interface{}) error {
 	e.workflow = wf
 
+	redactor, err := NewRedactor(wf.Settings.Redact)
+	if err != nil {
+		return err
+	}
+	e.redactor = redactor
+
+	// Reject invalid --var values before any agent runs
+	if err := ValidateVariables(wf, variables); err != nil {
+		return err
+	}
+
+	// Experimental features the workflow uses must be enabled
+	if err := RequireFeatures(wf); err != nil {
+		return err
+	}
+
 	// Create a context that can be canceled on interrupt
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -104,12 +185,12 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 		CurrentAgent: "",
 	}
 
-	// Process output directory with timestamp
+	// Expand the output directory's placeholders
+	if e.runID == "" {
+		e.runID = NewRunID()
+	}
 	if wf.Settings.OutputDir != "" {
-		outputDir := wf.Settings.OutputDir
-		timestamp := time.Now().Format("20060102-150405")
-		outputDir = strings.ReplaceAll(outputDir, "{{timestamp}}", timestamp)
-		e.outputDir = outputDir
+		e.outputDir = claimOutputDir(ExpandOutputPath(wf.Settings.OutputDir, e.outputPathVars()), e.runID)
 
 		// Create output directory
 		if err := os.MkdirAll(e.outputDir, 0755); err != nil {
@@ -118,6 +199,17 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 		fmt.Printf("📁 Output directory: %s\n", e.outputDir)
 	}
 
+	// Record what this run used so it can be audited and reproduced
+	e.writeRunManifest()
+	defer e.writeRunManifest()
+
+	e.sessions = openSessionRegistry(e.runID, wf.Name, e.outputDir)
+	defer e.sessions.Close()
+	// A run interrupted by a signal never returns here
+	defer utils.RegisterShutdown("run "+e.runID, utils.PhaseFiles, 0, func(context.Context) error {
+		return e.sessions.abort()
+	})()
+
 	// Print workflow header
 	fmt.Printf("\n🚀 Starting interactive workflow: %s\n", wf.Name)
 	if wf.Description != "" {
@@ -139,21 +231,44 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 		}
 	}()
 
+	e.emit(workflow.EventWorkflowStart, "", fmt.Sprintf("Starting workflow %s", wf.Name), map[string]interface{}{
+		"total_agents": len(wf.Agents),
+		"run_id":       e.runID,
+		"output_dir":   e.outputDir,
+	})
+
 	// Execute agents sequentially
 	for i, agent := range wf.Agents {
 		// Check for cancellation before starting each agent
 		select {
 		case <-ctx.Done():
 			e.state.Status = workflow.StatusAborted
-			return fmt.Errorf("workflow canceled by user")
+			e.emit(workflow.EventWorkflowError, "", "workflow canceled by user", nil)
+			return Classify(ErrorUserAborted, fmt.Errorf("workflow canceled by user"))
 		default:
 		}
 
 		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		fmt.Printf("🤖 Agent %d/%d: %s\n", i+1, len(wf.Agents), agent.Name)
-		fmt.Printf("   Provider: %s | Model: %s\n", agent.Provider, agent.Model)
+		if isProviderStep(&agent) {
+			fmt.Printf("   Provider: %s | Model: %s\n", agent.Provider, agent.Model)
+		} else {
+			fmt.Printf("   Step: %s\n", agent.Type)
+		}
 		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
 
+		// Skip the agent when its condition is false
+		run, err := e.shouldRunAgent(&agent)
+		if err != nil {
+			e.state.Status = workflow.StatusFailed
+			e.emit(workflow.EventWorkflowError, "", fmt.Sprintf("agent %s: %v", agent.Name, err), nil)
+			return fmt.Errorf("agent %s: %w", agent.Name, err)
+		}
+		if !run {
+			e.skipAgent(&agent, i)
+			continue
+		}
+
 		// Extract variables used in this agent's prompt
 		usedVars := e.extractVariablesFromPrompt(agent.Prompt)
 
@@ -177,6 +292,7 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 						Required:     v.Required,
 						DefaultValue: v.DefaultValue,
 						CurrentValue: currentVal,
+						Validation:   v.Validation,
 					})
 				}
 			}
@@ -199,25 +315,46 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 			}
 		}
 
-		if err := e.executeInteractiveAgent(ctx, &agent, i); err != nil {
+		e.emit(workflow.EventAgentStart, agent.ID, fmt.Sprintf("Agent %d/%d: %s", i+1, len(wf.Agents), agent.Name), agentStartData(&agent, i))
+
+		baseline := e.diffBaseline(&agent)
+		agentStart := time.Now()
+		if err := e.executeAgentWithArtifacts(ctx, &agent, i); err != nil {
+			e.emit(workflow.EventAgentError, agent.ID, err.Error(), e.agentEndData(&agent, i))
 			// Check if error is due to cancellation
 			if ctx.Err() != nil {
 				e.state.Status = workflow.StatusAborted
-				return fmt.Errorf("workflow canceled during agent %s", agent.Name)
+				e.emit(workflow.EventWorkflowError, "", fmt.Sprintf("workflow canceled during agent %s", agent.Name), nil)
+				return Classify(ErrorUserAborted, fmt.Errorf("workflow canceled during agent %s", agent.Name))
 			}
+			e.state.Status = workflow.StatusFailed
+			e.emit(workflow.EventWorkflowError, "", fmt.Sprintf("agent %s failed: %v", agent.Name, err), nil)
 			return fmt.Errorf("agent %s failed: %w", agent.Name, err)
 		}
 
+		// Show what the agent changed before the next step runs
+		e.reviewAgentDiff(&agent, baseline)
+
 		// Record output file path if agent has output configured
-		if agent.Output != "" && e.outputDir != "" {
-			outputPath := filepath.Join(e.outputDir, agent.Output)
+		if outputPath := e.agentOutputPath(&agent); outputPath != "" {
 			e.outputs[agent.ID] = outputPath
 			fmt.Printf("💾 Output will be saved to: %s\n", outputPath)
 			fmt.Printf("📌 Next agents can reference this as: {{%s.output}}\n", agent.ID)
 		}
 
 		// Add handoff context for this agent
-		e.handoffContext = append(e.handoffContext, fmt.Sprintf("Agent %s (%s) completed", agent.Name, agent.Provider))
+		// Compress large outputs so later prompts stay small
+		if isProviderStep(&agent) {
+			// Scrub secrets before the output is summarized or referenced
+			e.redactOutput(&agent)
+			e.summarizeHandoff(ctx, &agent)
+			e.handoffContext = append(e.handoffContext, e.handoffEntry(&agent))
+			e.recordSession(&agent, agentStart)
+		}
+
+		data := e.agentEndData(&agent, i)
+		data["output"] = e.outputs[agent.ID]
+		e.emit(workflow.EventAgentComplete, agent.ID, fmt.Sprintf("%s completed", agent.Name), data)
 	}
 
 	// Update final state
@@ -225,12 +362,26 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 	e.state.Status = workflow.StatusCompleted
 	e.state.EndTime = &endTime
 
+	e.emit(workflow.EventWorkflowComplete, "", fmt.Sprintf("Workflow %s completed", wf.Name), nil)
+
 	fmt.Printf("\n✨ Workflow completed successfully!\n")
+	fmt.Printf("⭐ Rate it with: opun feedback %s --rating 1-5\n", e.runID)
 	return nil
 }
 
 // executeInteractiveAgent executes a single agent interactively
 func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent *workflow.Agent, agentIndex int) error {
+	// Wait, input and issue steps don't start a provider
+	if isWaitStep(agent) {
+		return e.executeWait(ctx, agent)
+	}
+	if isInputStep(agent) {
+		return e.executeInput(agent)
+	}
+	if isIssueStep(agent) {
+		return e.executeIssue(ctx, agent)
+	}
+
 	// Check if this is a subagent delegation
 	if agent.SubAgent != nil {
 		return e.executeSubAgent(ctx, agent, agentIndex)
@@ -261,27 +412,124 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	e.state.CurrentAgent = agent.Name
 	e.mu.Unlock()
 
+	// Resolve where the agent runs
+	remote, err := e.remoteTarget(agent)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
 	// Get provider command
-	providerCmd, providerArgs, err := e.getProviderCommandAndArgs(agent.Provider)
+	var providerCmd string
+	var providerArgs []string
+	if remote != nil {
+		// The provider only needs to be installed on the remote machine
+		providerCmd = agent.Provider
+	} else {
+		providerCmd, providerArgs, err = e.getProviderCommandAndArgs(agent.Provider)
+		if err != nil {
+			return e.handleAgentError(agent, agentState, err)
+		}
+	}
+
+	// Give the agent its role; providers without a system prompt flag get it ahead of the task
+	systemPrompt, err := e.resolveSystemPrompt(agent)
 	if err != nil {
 		return e.handleAgentError(agent, agentState, err)
 	}
 
+	// Resume the previous agent's conversation instead of starting a fresh one
+	if continuesSession(agent, e.previousAgent) {
+		providerArgs = append(providerArgs, sessionContinuationArgs(agent.Provider, e.sessionID)...)
+		fmt.Printf("🔗 Continuing %s session from %s\n", agent.Provider, e.previousAgent.Name)
+	} else if agent.ContinueSession {
+		fmt.Printf("⚠️  continue_session ignored: the previous agent must use the same provider (%s) and it must support session continuation\n", agent.Provider)
+	}
+
 	// Process prompt template
 	prompt, err := e.processPromptWithHandoff(agent.Prompt, agentIndex)
 	if err != nil {
 		return e.handleAgentError(agent, agentState, fmt.Errorf("failed to process prompt: %w", err))
 	}
+	prompt, err = e.scriptPrompt(ctx, agent, prompt)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Apply the agent's model parameters and system prompt, and fit the
+	// prompt to the context window, as headless runs do
+	prepared, err := prepareAgentPrompt(agent, systemPrompt, prompt, e.workflow.Settings.PromptGuard, true)
+	e.recordPromptSize(agent, prepared.Size, err)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+	providerArgs = append(providerArgs, prepared.Args...)
+	// Run Opun's hooks in Claude sessions on this machine; remote and
+	// sandboxed sessions can't reach the opun binary
+	if remote == nil && resolveSandbox(e.workflow.Settings.Sandbox, agent.Sandbox) == nil {
+		providerArgs = config.WithClaudeHooks(agent.Provider, providerArgs)
+	}
+	prompt = prepared.Prompt
+	e.recordPrompt(agent, agent.Prompt, prompt)
+
+	// Stop prompts that match the prompt policy before they reach the provider
+	policyText := prepared.PolicyText
+	// References to files that aren't there leave the model guessing
+	if remote == nil {
+		if err := e.checkAgentFileRefs(agent, prompt); err != nil {
+			return e.handleAgentError(agent, agentState, err)
+		}
+	}
+	if err := e.enforcePromptPolicy(ctx, agent, policyText); err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Save the workspace so the agent's changes can be rolled back
+	if err := e.snapshotAgent(agent, remote); err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
 
 	// Debug: Show processed prompt summary
 	if len(e.outputs) > 0 {
 		fmt.Printf("📎 Prompt includes references to %d previous output(s)\n", len(e.outputs))
 	}
 
+	// Render the prompt the way the provider reads it
+	if workDir, err := os.Getwd(); err == nil {
+		prompt = providers.FormatProfileFor(agent.Provider).Apply(prompt, workDir)
+	}
+
+	// Launch the provider with the prompt instead of typing it in
+	injectByFlag := agent.Settings.PromptInjection == InjectFlag
+	if injectByFlag {
+		providerArgs = append(providerArgs, promptLaunchArgs(agent.Provider, prompt)...)
+	}
+
+	// Run the provider inside a container when sandboxing is configured
+	providerCmd, providerArgs, err = e.sandboxCommand(agent, providerCmd, providerArgs)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Run the provider over ssh and bring its outputs back when it finishes
+	if remote != nil {
+		providerCmd, providerArgs, err = e.remoteCommand(remote, providerCmd, providerArgs)
+		if err != nil {
+			return e.handleAgentError(agent, agentState, err)
+		}
+		defer func() {
+			if err := e.syncOutputs(remote, false); err != nil {
+				fmt.Printf("⚠️  Failed to sync outputs from %s: %v\n", remote.Host, err)
+			}
+		}()
+	}
+
 	// Create command - use direct command instead of shell
 	// #nosec G204 -- providerCmd is from a hardcoded list of known AI provider commands
 	cmd := exec.Command(providerCmd, providerArgs...)
 	cmd.Env = os.Environ()
+	if e.tracer != nil {
+		cmd.Env = append(cmd.Env, e.tracer.ProviderEnv(agent.ID)...)
+	}
 
 	// Start PTY
 	ptmx, err := pty.Start(cmd)
@@ -290,6 +538,39 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	}
 	defer ptmx.Close()
 
+	// Sample the provider's CPU time and memory and enforce max_memory_mb
+	monitor := newResourceMonitor(agent, cmd.Process.Pid, func() {
+		// The provider leads its own session; stop the processes it started too
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	})
+	monitor.Start(resourceSampleInterval)
+	defer func() { e.recordResources(agent, monitor.Stop()) }()
+
+	// Act on idle_timeout when the session stops producing output
+	idle := newIdleWatcher(agent, func(text string) { typeIntoSession(ptmx, text) }, func() {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	})
+	idle.Start(idleCheckInterval)
+	defer idle.Stop()
+
+	e.sessions.AddProvider(agent, cmd.Process.Pid, providerCmd)
+	defer e.sessions.RemoveProvider(cmd.Process.Pid)
+
+	// Don't leave the provider running if opun shuts down mid-session
+	unregister := utils.RegisterShutdown("agent "+agent.ID, utils.PhaseSessions, providerStopTimeout, func(ctx context.Context) error {
+		stopProviderGroup(ctx, cmd.Process.Pid)
+		return ptmx.Close()
+	})
+	defer unregister()
+
+	// Follow the terminal size of clients attached to a detached run
+	if e.attach != nil {
+		e.attach.SetResizeTarget(func(rows, cols uint16) {
+			_ = pty.Setsize(ptmx, &pty.Winsize{Rows: rows, Cols: cols})
+		})
+		defer e.attach.SetResizeTarget(nil)
+	}
+
 	// Handle pty size changes only if running in a terminal
 	if term.IsTerminal(int(os.Stdin.Fd())) {
 		ch := make(chan os.Signal, 1)
@@ -312,6 +593,12 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 		if err != nil {
 			return e.handleAgentError(agent, agentState, fmt.Errorf("failed to set raw mode: %w", err))
 		}
+		e.sessions.SetRawTerminal(true)
+		defer e.sessions.SetRawTerminal(false)
+		saved := oldState
+		defer utils.RegisterShutdown("restore terminal", utils.PhaseSessions, 0, func(context.Context) error {
+			return term.Restore(int(os.Stdin.Fd()), saved)
+		})()
 		defer func() {
 			if oldState != nil {
 				if err := term.Restore(int(os.Stdin.Fd()), oldState); err != nil {
@@ -322,11 +609,70 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 		}()
 	}
 
+	// Answer the provider's first-run dialogs before the prompt is typed
+	startup, err := newStartupWatcher(agent, ptmx)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
 	// Track output and inject prompt when ready
 	outputBuffer := &strings.Builder{}
 	promptInjected := false
 	promptMutex := &sync.Mutex{}
 
+	// injectPrompt types the prompt once the session is ready for it; a
+	// prompt passed at launch only ends the startup script. Called with
+	// promptMutex held.
+	injectPrompt := func(delay, charDelay time.Duration) {
+		promptInjected = true
+		startup.Stop()
+		if !injectByFlag {
+			go typePrompt(ptmx, prompt, delay, charDelay)
+		}
+	}
+
+	// Fall back when the provider's input prompt isn't detected in time,
+	// e.g. because an update changed how it looks
+	var (
+		notReady      string
+		awaitingEnter bool
+		fallback      bool
+	)
+	if !injectByFlag && agent.Provider != "mock" {
+		timeout := readyTimeout(agent)
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
+		readyTimer := time.AfterFunc(timeout, func() {
+			promptMutex.Lock()
+			defer promptMutex.Unlock()
+			if promptInjected {
+				return
+			}
+			fallback = true
+			reason := fmt.Sprintf("input prompt not detected after %s", timeout)
+			if alternateReady(outputBuffer.String()[startup.Offset():]) {
+				e.recordDecision(agent, DecisionReady, "alternate pattern", reason)
+				injectPrompt(0, 5*time.Millisecond)
+				return
+			}
+
+			// The terminal is usually in raw mode, so lines end in \r\n
+			notReady = notReadyAction(agent, interactive)
+			e.recordDecision(agent, DecisionReady, notReady, reason)
+			switch notReady {
+			case ReadyAsk:
+				awaitingEnter = true
+				fmt.Printf("\a\r\n⏳ %s's input prompt wasn't detected after %s; press Enter to type the prompt now\r\n", agent.Name, timeout)
+			case ReadyType:
+				fmt.Printf("\r\n⏳ %s's input prompt wasn't detected after %s; typing the prompt anyway\r\n", agent.Name, timeout)
+				injectPrompt(0, 5*time.Millisecond)
+			default:
+				fmt.Printf("\r\n⏳ %s's input prompt wasn't detected after %s; stopping the provider\r\n", agent.Name, timeout)
+				_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			}
+		})
+		defer readyTimer.Stop()
+	}
+
 	// Simple bidirectional copy with context cancellation
 	errChan := make(chan error, 2)
 	doneChan := make(chan struct{})
@@ -339,15 +685,18 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 			if n > 0 {
 				// Write to stdout
 				os.Stdout.Write(buf[:n])
+				idle.Touch()
+				e.emitOutput(agent.ID, buf[:n])
 
 				// Accumulate output for ready detection
 				promptMutex.Lock()
 				outputBuffer.Write(buf[:n])
 
-				currentOutput := outputBuffer.String()
-
 				// Check if we should inject prompt based on provider
 				if !promptInjected {
+					startup.Write(buf[:n])
+					// Only output after the last answered dialog can show the prompt
+					currentOutput := outputBuffer.String()[startup.Offset():]
 					switch agent.Provider {
 					case "claude":
 						// Claude prompt detection - original logic
@@ -356,16 +705,8 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 							if strings.Contains(currentOutput, "│\u00a0>") ||
 								strings.Contains(currentOutput, "│ >") ||
 								strings.Contains(currentOutput, "\u00a0>\u00a0") {
-								promptInjected = true
 								// Small delay to ensure UI is ready
-								go func() {
-									time.Sleep(500 * time.Millisecond)
-									// Type the prompt character by character
-									for _, char := range prompt {
-										ptmx.Write([]byte(string(char)))
-										time.Sleep(5 * time.Millisecond)
-									}
-								}()
+								injectPrompt(500*time.Millisecond, 5*time.Millisecond)
 							}
 						}
 
@@ -378,18 +719,17 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 						// Check for the prompt pattern in stripped output
 						// The actual pattern is "│ > " with a space between pipe and arrow
 						if strings.Contains(strippedOutput, "│ > ") {
-							promptInjected = true
-							// Longer delay to ensure UI is fully ready and won't cut off the beginning
-							go func() {
-								time.Sleep(2 * time.Second)
-								// Type the prompt character by character
-								for _, char := range prompt {
-									ptmx.Write([]byte(string(char)))
-									time.Sleep(10 * time.Millisecond) // Also slow down typing a bit
-								}
-							}()
+							// Longer delay to ensure UI is fully ready and won't cut off the beginning,
+							// and slower typing
+							injectPrompt(2*time.Second, 10*time.Millisecond)
 						}
 					}
+
+					// After ready_timeout, output that looks like any input prompt will do
+					if !promptInjected && fallback && alternateReady(currentOutput) {
+						e.recordDecision(agent, DecisionReady, "alternate pattern", "input prompt detected late")
+						injectPrompt(500*time.Millisecond, 5*time.Millisecond)
+					}
 				}
 				promptMutex.Unlock()
 			}
@@ -421,6 +761,16 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 					return
 				}
 
+				// Enter types the prompt when the operator was asked to
+				promptMutex.Lock()
+				if awaitingEnter && !promptInjected && bytes.ContainsAny(buf[:n], "\r\n") {
+					awaitingEnter = false
+					injectPrompt(0, 5*time.Millisecond)
+					promptMutex.Unlock()
+					continue
+				}
+				promptMutex.Unlock()
+
 				// First check for Ctrl+C to count (non-blocking)
 				for i := 0; i < n; i++ {
 					if buf[i] == 0x03 { // Ctrl+C
@@ -471,6 +821,25 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	select {
 	case err := <-errChan:
 		close(doneChan)
+		if monitor.Killed() {
+			return e.handleAgentError(agent, agentState, Classify(ErrorBudgetExceeded, fmt.Errorf("provider stopped after going over max_memory_mb (%d)", agent.Settings.MaxMemoryMB)))
+		}
+		if idle.TimedOut() {
+			return e.timeoutAgent(agent, agentState)
+		}
+		promptMutex.Lock()
+		stoppedFor := notReady
+		if promptInjected {
+			stoppedFor = ""
+		}
+		promptMutex.Unlock()
+		switch stoppedFor {
+		case ReadyRetry:
+			// runInteractiveAgent starts the session over
+			return errPromptNotInjected
+		case ReadyFail:
+			return e.handleAgentError(agent, agentState, Classify(ErrorTimeout, fmt.Errorf("input prompt not detected after %s", readyTimeout(agent))))
+		}
 		if err != nil && err != io.EOF {
 			return e.handleAgentError(agent, agentState, err)
 		}
@@ -516,6 +885,36 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	return nil
 }
 
+const (
+	// providerStopGrace is how long a provider gets to exit after SIGTERM
+	providerStopGrace = 500 * time.Millisecond
+
+	// providerStopTimeout bounds stopping a provider during shutdown
+	providerStopTimeout = 2 * time.Second
+)
+
+// stopProviderGroup asks a provider and the processes it started to exit,
+// then kills whatever is left when the grace period or ctx runs out
+func stopProviderGroup(ctx context.Context, pid int) {
+	_ = syscall.Kill(-pid, syscall.SIGTERM)
+	select {
+	case <-time.After(providerStopGrace):
+	case <-ctx.Done():
+	}
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// killOrphan stops a provider, and what it started, left running by a
+// crashed run
+func killOrphan(pid int) error {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		return err
+	}
+	time.Sleep(providerStopGrace)
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+	return nil
+}
+
 // executeSubAgent executes an agent via subagent delegation
 func (e *InteractiveExecutor) executeSubAgent(ctx context.Context, agent *workflow.Agent, agentIndex int) error {
 	// Initialize agent state
@@ -555,11 +954,13 @@ func (e *InteractiveExecutor) executeSubAgent(ctx context.Context, agent *workfl
 		Variables:   e.state.Variables,
 	}
 
-	// Add workflow context
-	if agent.Input != nil {
-		for k, v := range agent.Input {
-			task.Context[k] = v
-		}
+	// Add the resolved input mapping as workflow context
+	_, inputs, err := e.renderAgentInputs(agent, "")
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+	for k, v := range inputs {
+		task.Context[k] = v
 	}
 
 	// Get the subagent manager (this will need to be injected properly)
@@ -574,8 +975,7 @@ func (e *InteractiveExecutor) executeSubAgent(ctx context.Context, agent *workfl
 	agentState.Output = "Subagent execution placeholder output"
 	
 	// Store output for next agents
-	if agent.Output != "" && e.outputDir != "" {
-		outputPath := filepath.Join(e.outputDir, agent.Output)
+	if outputPath := e.agentOutputPath(agent); outputPath != "" {
 		e.outputs[agent.ID] = outputPath
 	}
 
@@ -597,23 +997,26 @@ func (e *InteractiveExecutor) processPromptWithHandoff(prompt string, agentIndex
 		result = strings.ReplaceAll(result, placeholder, replacement)
 	}
 
-	// Replace {{agent.output}} references with @filepath
-	for id, outputPath := range e.outputs {
-		placeholder := fmt.Sprintf("{{%s.output}}", id)
-		// Convert to @ syntax for providers to read the file
-		replacement := fmt.Sprintf("@%s", outputPath)
-		result = strings.ReplaceAll(result, placeholder, replacement)
+	// Replace {{agent.output}} references with @filepath so providers read the file
+	result = e.replaceOutputReferences(result)
+
+	// Fill in {{input.name}} from the agent's input mapping
+	result, _, err := e.renderAgentInputs(&agent, result)
+	if err != nil {
+		return "", err
 	}
 
 	// Add output saving instructions if agent has output configured
-	if agent.Output != "" && e.outputDir != "" {
-		outputPath := filepath.Join(e.outputDir, agent.Output)
+	if outputPath := e.agentOutputPath(&agent); outputPath != "" {
+		// Providers don't always create missing directories, e.g. per-agent ones
+		_ = os.MkdirAll(filepath.Dir(outputPath), 0755)
 		outputInstructions := fmt.Sprintf("\n\n📝 **IMPORTANT**: Please save your complete analysis/results to the file:\n`%s`\n\nUse your file writing capabilities to save the output before finishing.\n", outputPath)
 		result = outputInstructions + result
 	}
 
 	// Add handoff context if this is not the first agent
-	if agentIndex > 0 && len(e.handoffContext) > 0 {
+	// A continued session already holds the earlier conversation
+	if agentIndex > 0 && len(e.handoffContext) > 0 && !continuesSession(&agent, e.previousAgent) {
 		handoff := "\n\n---\n🤝 WORKFLOW CONTEXT:\n"
 		handoff += fmt.Sprintf("You are agent %d in a sequential workflow.\n", agentIndex+1)
 		handoff += "Previous agents completed:\n"
@@ -637,29 +1040,26 @@ func (e *InteractiveExecutor) processPromptWithHandoff(prompt string, agentIndex
 // getProviderCommandAndArgs returns the command and args to start a provider
 func (e *InteractiveExecutor) getProviderCommandAndArgs(provider string) (string, []string, error) {
 	switch provider {
-	case "claude":
-		// Try claude command first
-		if _, err := exec.LookPath("claude"); err == nil {
-			return "claude", []string{}, nil
-		}
-		// Fall back to npx claude-code
-		if _, err := exec.LookPath("npx"); err == nil {
-			return "npx", []string{"claude-code"}, nil
-		}
-		return "", nil, fmt.Errorf("claude command not found, please install Claude CLI")
-
-	case "gemini":
-		if _, err := exec.LookPath("gemini"); err == nil {
-			return "gemini", []string{}, nil
+	case "claude", "gemini":
+		// Configured binaries and wrappers are resolved by the provider registry
+		argv, err := providers.ResolveCommand(provider)
+		if err != nil {
+			return "", nil, Classify(ErrorProviderNotFound, err)
 		}
-		return "", nil, fmt.Errorf("gemini command not found, please install Gemini CLI")
+		return argv[0], argv[1:], nil
 
 	case "mock":
 		// For mock provider, use a simple echo command for testing
+		if e.chaos != nil {
+			e.mu.Lock()
+			agent := e.state.CurrentAgent
+			e.mu.Unlock()
+			return "/bin/sh", []string{"-c", e.chaos.mockCommand(agent)}, nil
+		}
 		return "/bin/sh", []string{"-c", "echo 'Mock provider ready'; cat"}, nil
 
 	default:
-		return "", nil, fmt.Errorf("unsupported provider: %s", provider)
+		return "", nil, Classify(ErrorProviderNotFound, fmt.Errorf("unsupported provider: %s", provider))
 	}
 }
 
@@ -673,6 +1073,7 @@ func (e *InteractiveExecutor) handleAgentError(agent *workflow.Agent, state *wor
 		Message:   err.Error(),
 		Timestamp: endTime,
 		Fatal:     !agent.Settings.ContinueOnError,
+		Class:     string(ClassOf(err)),
 	}
 
 	if agent.Settings.ContinueOnError {
@@ -733,6 +1134,8 @@ type variablePromptModel struct {
 	inputs       []textinput.Model
 	values       map[string]interface{}
 	err          error
+	// invalid explains why the last value entered was rejected
+	invalid string
 }
 
 // promptVariable represents a workflow variable for prompting
@@ -743,6 +1146,7 @@ type promptVariable struct {
 	Required     bool
 	DefaultValue interface{}
 	CurrentValue interface{}
+	Validation   *workflow.VariableValidation
 }
 
 func initialVariablePromptModel(variables []promptVariable) variablePromptModel {
@@ -826,6 +1230,13 @@ func (m variablePromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			v := m.variables[m.currentIndex]
 			val := strings.TrimSpace(m.inputs[m.currentIndex].Value())
 
+			// Invalid values are asked for again, with what is wrong
+			m.invalid = ""
+			if err := checkVariable(workflow.Variable{Name: v.Name, Type: v.Type, Validation: v.Validation}, val); err != nil {
+				m.invalid = err.Error()
+				return m, nil
+			}
+
 			if val != "" {
 				// Convert based on type
 				switch v.Type {
@@ -892,6 +1303,9 @@ func (m variablePromptModel) View() string {
 		BorderForeground(lipgloss.Color("240")).
 		Padding(0, 1)
 
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196"))
+
 	var s strings.Builder
 	s.WriteString(titleStyle.Render("Configure Workflow Variables") + "\n\n")
 
@@ -908,7 +1322,11 @@ func (m variablePromptModel) View() string {
 		if i == m.currentIndex {
 			style = activeStyle
 		}
-		s.WriteString(style.Render(m.inputs[i].View()) + "\n\n")
+		s.WriteString(style.Render(m.inputs[i].View()) + "\n")
+		if i == m.currentIndex && m.invalid != "" {
+			s.WriteString(errorStyle.Render("✗ "+m.invalid) + "\n")
+		}
+		s.WriteString("\n")
 	}
 
 	s.WriteString(varStyle.Render("(Tab to navigate, Enter to confirm, Esc to cancel)"))
@@ -922,6 +1340,10 @@ func promptForVariables(variables []promptVariable) (map[string]interface{}, err
 		return make(map[string]interface{}), nil
 	}
 
+	if utils.PlainUI() {
+		return promptForVariablesPlain(variables)
+	}
+
 	p := tea.NewProgram(initialVariablePromptModel(variables))
 	m, err := p.Run()
 	if err != nil {
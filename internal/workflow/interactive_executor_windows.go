@@ -20,6 +20,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/creack/pty"
+	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/internal/utils"
 	"github.com/rizome-dev/opun/pkg/workflow"
 	"golang.org/x/term"
 )
@@ -50,6 +53,66 @@ type InteractiveExecutor struct {
 	ctrlCCount    int
 	lastCtrlCTime time.Time
 	ctrlCMutex    sync.Mutex
+
+	// Optional handler notified of workflow progress events
+	eventHandler func(workflow.WorkflowEvent)
+	outputEvents bool
+
+	// Last agent that finished and its provider session, for continue_session
+	previousAgent *workflow.Agent
+	sessionID     string
+
+	// Summary files of large agent outputs, by agent ID
+	summaries  map[string]string
+	summarizer handoffSummarizer
+
+	// Attach server of a detached run, if any
+	attach *AttachServer
+
+	// Run manifest inputs
+	inventory        RunInventory
+	providerVersions map[string]string
+	versionLookup    providerVersionLookup
+	environment      *RunEnvironment
+
+	// Asks the operator for input steps; nil uses the terminal form
+	ask operatorPrompt
+
+	// Resolves promptgarden:// references in system prompts
+	promptResolver PromptResolver
+
+	// Scrubs secrets from agent outputs; nil when redaction is off
+	redactor *Redactor
+
+	// Checked against every prompt before it is sent; nil allows everything
+	promptPolicy *PromptPolicy
+
+	// Run ID that workspace snapshots are stored under
+	runID string
+
+	// Provider resource usage by agent ID, for the run manifest
+	resources map[string]*ResourceUsage
+
+	// Error that ended the run, for the run manifest
+	runErr error
+
+	// Estimated prompt size in tokens by agent ID
+	promptTokens map[string]int
+
+	// Traces the run; providers get its trace context. Nil when tracing is off.
+	tracer *RunTracer
+
+	// Records started providers so `opun recover` can find them after a crash
+	sessions *SessionRegistry
+
+	// What every step was given and the decisions taken, for `opun inspect`
+	inspection *RunInspection
+
+	// Where settings.storage uploaded the run's artifacts
+	stored *StoredArtifacts
+
+	// Failures injected into mock provider sessions with --chaos
+	chaos *Chaos
 }
 
 // NewInteractiveExecutor creates a new interactive workflow executor
@@ -57,13 +120,30 @@ func NewInteractiveExecutor() *InteractiveExecutor {
 	return &InteractiveExecutor{
 		outputs:        make(map[string]string),
 		handoffContext: make([]string, 0),
+		summaries:      make(map[string]string),
 	}
 }
 
-// Execute executes a workflow with interactive sessions
-func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow, variables map[string]interface{}) error {
+// execute executes a workflow with interactive sessions
+func (e *InteractiveExecutor) execute(ctx context.Context, wf *workflow.Workflow, variables map[string]interface{}) error {
 	e.workflow = wf
 
+	redactor, err := NewRedactor(wf.Settings.Redact)
+	if err != nil {
+		return err
+	}
+	e.redactor = redactor
+
+	// Reject invalid --var values before any agent runs
+	if err := ValidateVariables(wf, variables); err != nil {
+		return err
+	}
+
+	// Experimental features the workflow uses must be enabled
+	if err := RequireFeatures(wf); err != nil {
+		return err
+	}
+
 	// Create a context that can be canceled on interrupt
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -87,12 +167,12 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 		CurrentAgent: "",
 	}
 
-	// Process output directory with timestamp
+	// Expand the output directory's placeholders
+	if e.runID == "" {
+		e.runID = NewRunID()
+	}
 	if wf.Settings.OutputDir != "" {
-		outputDir := wf.Settings.OutputDir
-		timestamp := time.Now().Format("20060102-150405")
-		outputDir = strings.ReplaceAll(outputDir, "{{timestamp}}", timestamp)
-		e.outputDir = outputDir
+		e.outputDir = claimOutputDir(ExpandOutputPath(wf.Settings.OutputDir, e.outputPathVars()), e.runID)
 
 		// Create output directory
 		if err := os.MkdirAll(e.outputDir, 0755); err != nil {
@@ -101,6 +181,17 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 		fmt.Printf("📁 Output directory: %s\n", e.outputDir)
 	}
 
+	// Record what this run used so it can be audited and reproduced
+	e.writeRunManifest()
+	defer e.writeRunManifest()
+
+	e.sessions = openSessionRegistry(e.runID, wf.Name, e.outputDir)
+	defer e.sessions.Close()
+	// A run interrupted by a signal never returns here
+	defer utils.RegisterShutdown("run "+e.runID, utils.PhaseFiles, 0, func(context.Context) error {
+		return e.sessions.abort()
+	})()
+
 	// Print workflow header
 	fmt.Printf("\n🚀 Starting interactive workflow: %s\n", wf.Name)
 	if wf.Description != "" {
@@ -122,21 +213,44 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 		}
 	}()
 
+	e.emit(workflow.EventWorkflowStart, "", fmt.Sprintf("Starting workflow %s", wf.Name), map[string]interface{}{
+		"total_agents": len(wf.Agents),
+		"run_id":       e.runID,
+		"output_dir":   e.outputDir,
+	})
+
 	// Execute agents sequentially
 	for i, agent := range wf.Agents {
 		// Check for cancellation before starting each agent
 		select {
 		case <-ctx.Done():
 			e.state.Status = workflow.StatusAborted
-			return fmt.Errorf("workflow canceled by user")
+			e.emit(workflow.EventWorkflowError, "", "workflow canceled by user", nil)
+			return Classify(ErrorUserAborted, fmt.Errorf("workflow canceled by user"))
 		default:
 		}
 
 		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		fmt.Printf("🤖 Agent %d/%d: %s\n", i+1, len(wf.Agents), agent.Name)
-		fmt.Printf("   Provider: %s | Model: %s\n", agent.Provider, agent.Model)
+		if isProviderStep(&agent) {
+			fmt.Printf("   Provider: %s | Model: %s\n", agent.Provider, agent.Model)
+		} else {
+			fmt.Printf("   Step: %s\n", agent.Type)
+		}
 		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
 
+		// Skip the agent when its condition is false
+		run, err := e.shouldRunAgent(&agent)
+		if err != nil {
+			e.state.Status = workflow.StatusFailed
+			e.emit(workflow.EventWorkflowError, "", fmt.Sprintf("agent %s: %v", agent.Name, err), nil)
+			return fmt.Errorf("agent %s: %w", agent.Name, err)
+		}
+		if !run {
+			e.skipAgent(&agent, i)
+			continue
+		}
+
 		// Extract variables used in this agent's prompt
 		usedVars := e.extractVariablesFromPrompt(agent.Prompt)
 
@@ -160,6 +274,7 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 						Required:     v.Required,
 						DefaultValue: v.DefaultValue,
 						CurrentValue: currentVal,
+						Validation:   v.Validation,
 					})
 				}
 			}
@@ -182,25 +297,46 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 			}
 		}
 
-		if err := e.executeInteractiveAgent(ctx, &agent, i); err != nil {
+		e.emit(workflow.EventAgentStart, agent.ID, fmt.Sprintf("Agent %d/%d: %s", i+1, len(wf.Agents), agent.Name), agentStartData(&agent, i))
+
+		baseline := e.diffBaseline(&agent)
+		agentStart := time.Now()
+		if err := e.executeAgentWithArtifacts(ctx, &agent, i); err != nil {
+			e.emit(workflow.EventAgentError, agent.ID, err.Error(), e.agentEndData(&agent, i))
 			// Check if error is due to cancellation
 			if ctx.Err() != nil {
 				e.state.Status = workflow.StatusAborted
-				return fmt.Errorf("workflow canceled during agent %s", agent.Name)
+				e.emit(workflow.EventWorkflowError, "", fmt.Sprintf("workflow canceled during agent %s", agent.Name), nil)
+				return Classify(ErrorUserAborted, fmt.Errorf("workflow canceled during agent %s", agent.Name))
 			}
+			e.state.Status = workflow.StatusFailed
+			e.emit(workflow.EventWorkflowError, "", fmt.Sprintf("agent %s failed: %v", agent.Name, err), nil)
 			return fmt.Errorf("agent %s failed: %w", agent.Name, err)
 		}
 
+		// Show what the agent changed before the next step runs
+		e.reviewAgentDiff(&agent, baseline)
+
 		// Record output file path if agent has output configured
-		if agent.Output != "" && e.outputDir != "" {
-			outputPath := filepath.Join(e.outputDir, agent.Output)
+		if outputPath := e.agentOutputPath(&agent); outputPath != "" {
 			e.outputs[agent.ID] = outputPath
 			fmt.Printf("💾 Output will be saved to: %s\n", outputPath)
 			fmt.Printf("📌 Next agents can reference this as: {{%s.output}}\n", agent.ID)
 		}
 
 		// Add handoff context for this agent
-		e.handoffContext = append(e.handoffContext, fmt.Sprintf("Agent %s (%s) completed", agent.Name, agent.Provider))
+		// Compress large outputs so later prompts stay small
+		if isProviderStep(&agent) {
+			// Scrub secrets before the output is summarized or referenced
+			e.redactOutput(&agent)
+			e.summarizeHandoff(ctx, &agent)
+			e.handoffContext = append(e.handoffContext, e.handoffEntry(&agent))
+			e.recordSession(&agent, agentStart)
+		}
+
+		data := e.agentEndData(&agent, i)
+		data["output"] = e.outputs[agent.ID]
+		e.emit(workflow.EventAgentComplete, agent.ID, fmt.Sprintf("%s completed", agent.Name), data)
 	}
 
 	// Update final state
@@ -208,12 +344,26 @@ func (e *InteractiveExecutor) Execute(ctx context.Context, wf *workflow.Workflow
 	e.state.Status = workflow.StatusCompleted
 	e.state.EndTime = &endTime
 
+	e.emit(workflow.EventWorkflowComplete, "", fmt.Sprintf("Workflow %s completed", wf.Name), nil)
+
 	fmt.Printf("\n✨ Workflow completed successfully!\n")
+	fmt.Printf("⭐ Rate it with: opun feedback %s --rating 1-5\n", e.runID)
 	return nil
 }
 
 // executeInteractiveAgent executes a single agent interactively
 func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent *workflow.Agent, agentIndex int) error {
+	// Wait, input and issue steps don't start a provider
+	if isWaitStep(agent) {
+		return e.executeWait(ctx, agent)
+	}
+	if isInputStep(agent) {
+		return e.executeInput(agent)
+	}
+	if isIssueStep(agent) {
+		return e.executeIssue(ctx, agent)
+	}
+
 	// Reset Ctrl+C count for new agent
 	e.ctrlCMutex.Lock()
 	e.ctrlCCount = 0
@@ -239,22 +389,119 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	e.state.CurrentAgent = agent.Name
 	e.mu.Unlock()
 
+	// Resolve where the agent runs
+	remote, err := e.remoteTarget(agent)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
 	// Get provider command
-	providerCmd, providerArgs, err := e.getProviderCommandAndArgs(agent.Provider)
+	var providerCmd string
+	var providerArgs []string
+	if remote != nil {
+		// The provider only needs to be installed on the remote machine
+		providerCmd = agent.Provider
+	} else {
+		providerCmd, providerArgs, err = e.getProviderCommandAndArgs(agent.Provider)
+		if err != nil {
+			return e.handleAgentError(agent, agentState, err)
+		}
+	}
+
+	// Give the agent its role; providers without a system prompt flag get it ahead of the task
+	systemPrompt, err := e.resolveSystemPrompt(agent)
 	if err != nil {
 		return e.handleAgentError(agent, agentState, err)
 	}
 
+	// Resume the previous agent's conversation instead of starting a fresh one
+	if continuesSession(agent, e.previousAgent) {
+		providerArgs = append(providerArgs, sessionContinuationArgs(agent.Provider, e.sessionID)...)
+		fmt.Printf("🔗 Continuing %s session from %s\n", agent.Provider, e.previousAgent.Name)
+	} else if agent.ContinueSession {
+		fmt.Printf("⚠️  continue_session ignored: the previous agent must use the same provider (%s) and it must support session continuation\n", agent.Provider)
+	}
+
 	// Process prompt template
 	prompt, err := e.processPromptWithHandoff(agent.Prompt, agentIndex)
 	if err != nil {
 		return e.handleAgentError(agent, agentState, fmt.Errorf("failed to process prompt: %w", err))
 	}
+	prompt, err = e.scriptPrompt(ctx, agent, prompt)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Apply the agent's model parameters and system prompt, and fit the
+	// prompt to the context window, as headless runs do
+	prepared, err := prepareAgentPrompt(agent, systemPrompt, prompt, e.workflow.Settings.PromptGuard, true)
+	e.recordPromptSize(agent, prepared.Size, err)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+	providerArgs = append(providerArgs, prepared.Args...)
+	// Run Opun's hooks in Claude sessions on this machine; remote and
+	// sandboxed sessions can't reach the opun binary
+	if remote == nil && resolveSandbox(e.workflow.Settings.Sandbox, agent.Sandbox) == nil {
+		providerArgs = config.WithClaudeHooks(agent.Provider, providerArgs)
+	}
+	prompt = prepared.Prompt
+	e.recordPrompt(agent, agent.Prompt, prompt)
+
+	// Stop prompts that match the prompt policy before they reach the provider
+	policyText := prepared.PolicyText
+	// References to files that aren't there leave the model guessing
+	if remote == nil {
+		if err := e.checkAgentFileRefs(agent, prompt); err != nil {
+			return e.handleAgentError(agent, agentState, err)
+		}
+	}
+	if err := e.enforcePromptPolicy(ctx, agent, policyText); err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Save the workspace so the agent's changes can be rolled back
+	if err := e.snapshotAgent(agent, remote); err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Render the prompt the way the provider reads it
+	if workDir, err := os.Getwd(); err == nil {
+		prompt = providers.FormatProfileFor(agent.Provider).Apply(prompt, workDir)
+	}
+
+	// Launch the provider with the prompt instead of typing it in
+	injectByFlag := agent.Settings.PromptInjection == InjectFlag
+	if injectByFlag {
+		providerArgs = append(providerArgs, promptLaunchArgs(agent.Provider, prompt)...)
+	}
+
+	// Run the provider inside a container when sandboxing is configured
+	providerCmd, providerArgs, err = e.sandboxCommand(agent, providerCmd, providerArgs)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	// Run the provider over ssh and bring its outputs back when it finishes
+	if remote != nil {
+		providerCmd, providerArgs, err = e.remoteCommand(remote, providerCmd, providerArgs)
+		if err != nil {
+			return e.handleAgentError(agent, agentState, err)
+		}
+		defer func() {
+			if err := e.syncOutputs(remote, false); err != nil {
+				fmt.Printf("⚠️  Failed to sync outputs from %s: %v\n", remote.Host, err)
+			}
+		}()
+	}
 
 	// Create command - use direct command instead of shell
 	// #nosec G204 -- providerCmd is from a hardcoded list of known AI provider commands
 	cmd := exec.Command(providerCmd, providerArgs...)
 	cmd.Env = os.Environ()
+	if e.tracer != nil {
+		cmd.Env = append(cmd.Env, e.tracer.ProviderEnv(agent.ID)...)
+	}
 
 	// Start PTY
 	ptmx, err := pty.Start(cmd)
@@ -263,6 +510,30 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	}
 	defer ptmx.Close()
 
+	// Sample the provider's CPU time and memory and enforce max_memory_mb
+	monitor := newResourceMonitor(agent, cmd.Process.Pid, func() {
+		_ = cmd.Process.Kill()
+	})
+	monitor.Start(resourceSampleInterval)
+	defer func() { e.recordResources(agent, monitor.Stop()) }()
+
+	// Act on idle_timeout when the session stops producing output
+	idle := newIdleWatcher(agent, func(text string) { typeIntoSession(ptmx, text) }, func() {
+		_ = cmd.Process.Kill()
+	})
+	idle.Start(idleCheckInterval)
+	defer idle.Stop()
+
+	e.sessions.AddProvider(agent, cmd.Process.Pid, providerCmd)
+	defer e.sessions.RemoveProvider(cmd.Process.Pid)
+
+	// Don't leave the provider running if opun shuts down mid-session
+	unregister := utils.RegisterShutdown("agent "+agent.ID, utils.PhaseSessions, 0, func(context.Context) error {
+		_ = cmd.Process.Kill()
+		return ptmx.Close()
+	})
+	defer unregister()
+
 	// On Windows, we don't need to handle SIGWINCH for resizing
 	// The Windows Console API handles this automatically with ConPTY
 
@@ -279,6 +550,12 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 		if err != nil {
 			return e.handleAgentError(agent, agentState, fmt.Errorf("failed to set raw mode: %w", err))
 		}
+		e.sessions.SetRawTerminal(true)
+		defer e.sessions.SetRawTerminal(false)
+		saved := oldState
+		defer utils.RegisterShutdown("restore terminal", utils.PhaseSessions, 0, func(context.Context) error {
+			return term.Restore(int(os.Stdin.Fd()), saved)
+		})()
 		defer func() {
 			if oldState != nil {
 				term.Restore(int(os.Stdin.Fd()), oldState)
@@ -286,24 +563,33 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 		}()
 	}
 
+	// Answer the provider's first-run dialogs before the prompt is typed
+	startup, err := newStartupWatcher(agent, ptmx)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
 	// Schedule prompt injection after provider is ready
 	go func() {
 		// Simple delay-based approach that doesn't interfere with I/O
+		delay := 3 * time.Second
 		switch agent.Provider {
 		case "claude":
 			// Claude is usually ready after 2-3 seconds
-			time.Sleep(3 * time.Second)
 		case "gemini":
 			// Gemini may need more time if auth/theme selection is required
-			time.Sleep(5 * time.Second)
-		default:
-			time.Sleep(3 * time.Second)
+			delay = 5 * time.Second
+		}
+		time.Sleep(delay)
+		// Give the provider as long again after each answered dialog
+		for startup.SentWithin(delay) {
+			time.Sleep(delay)
 		}
+		startup.Stop()
 
 		// Type the prompt character by character
-		for _, char := range prompt {
-			ptmx.Write([]byte(string(char)))
-			time.Sleep(5 * time.Millisecond)
+		if !injectByFlag {
+			typePrompt(ptmx, prompt, 0, 5*time.Millisecond)
 		}
 	}()
 
@@ -313,7 +599,7 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 
 	// Copy PTY output to stdout
 	go func() {
-		_, err := io.Copy(os.Stdout, ptmx)
+		_, err := io.Copy(io.MultiWriter(os.Stdout, outputEventWriter{e, agent.ID}, idle, startup), ptmx)
 		select {
 		case errChan <- err:
 		case <-doneChan:
@@ -384,6 +670,12 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	select {
 	case err := <-errChan:
 		close(doneChan)
+		if monitor.Killed() {
+			return e.handleAgentError(agent, agentState, Classify(ErrorBudgetExceeded, fmt.Errorf("provider stopped after going over max_memory_mb (%d)", agent.Settings.MaxMemoryMB)))
+		}
+		if idle.TimedOut() {
+			return e.timeoutAgent(agent, agentState)
+		}
 		if err != nil && err != io.EOF {
 			return e.handleAgentError(agent, agentState, err)
 		}
@@ -426,6 +718,15 @@ func (e *InteractiveExecutor) executeInteractiveAgent(ctx context.Context, agent
 	return nil
 }
 
+// killOrphan stops a provider left running by a crashed run
+func killOrphan(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
 // processPromptWithHandoff processes prompt template and adds handoff context
 func (e *InteractiveExecutor) processPromptWithHandoff(prompt string, agentIndex int) (string, error) {
 	// Get the current agent to add output instructions
@@ -441,23 +742,26 @@ func (e *InteractiveExecutor) processPromptWithHandoff(prompt string, agentIndex
 		result = strings.ReplaceAll(result, placeholder, replacement)
 	}
 
-	// Replace {{agent.output}} references with @filepath
-	for id, outputPath := range e.outputs {
-		placeholder := fmt.Sprintf("{{%s.output}}", id)
-		// Convert to @ syntax for providers to read the file
-		replacement := fmt.Sprintf("@%s", outputPath)
-		result = strings.ReplaceAll(result, placeholder, replacement)
+	// Replace {{agent.output}} references with @filepath so providers read the file
+	result = e.replaceOutputReferences(result)
+
+	// Fill in {{input.name}} from the agent's input mapping
+	result, _, err := e.renderAgentInputs(&agent, result)
+	if err != nil {
+		return "", err
 	}
 
 	// Add output saving instructions if agent has output configured
-	if agent.Output != "" && e.outputDir != "" {
-		outputPath := filepath.Join(e.outputDir, agent.Output)
+	if outputPath := e.agentOutputPath(&agent); outputPath != "" {
+		// Providers don't always create missing directories, e.g. per-agent ones
+		_ = os.MkdirAll(filepath.Dir(outputPath), 0755)
 		outputInstructions := fmt.Sprintf("\n\n📝 **IMPORTANT**: Please save your complete analysis/results to the file:\n`%s`\n\nUse your file writing capabilities to save the output before finishing.\n", outputPath)
 		result = outputInstructions + result
 	}
 
 	// Add handoff context if this is not the first agent
-	if agentIndex > 0 && len(e.handoffContext) > 0 {
+	// A continued session already holds the earlier conversation
+	if agentIndex > 0 && len(e.handoffContext) > 0 && !continuesSession(&agent, e.previousAgent) {
 		handoff := "\n\n---\n🤝 WORKFLOW CONTEXT:\n"
 		handoff += fmt.Sprintf("You are agent %d in a sequential workflow.\n", agentIndex+1)
 		handoff += "Previous agents completed:\n"
@@ -481,41 +785,21 @@ func (e *InteractiveExecutor) processPromptWithHandoff(prompt string, agentIndex
 // getProviderCommandAndArgs returns the command and args to start a provider
 func (e *InteractiveExecutor) getProviderCommandAndArgs(provider string) (string, []string, error) {
 	switch provider {
-	case "claude":
-		// Try claude command first
-		if _, err := exec.LookPath("claude"); err == nil {
-			return "claude", []string{}, nil
-		}
-		// On Windows, try claude.exe specifically
-		if _, err := exec.LookPath("claude.exe"); err == nil {
-			return "claude.exe", []string{}, nil
-		}
-		// Fall back to npx claude-code
-		if _, err := exec.LookPath("npx"); err == nil {
-			return "npx", []string{"claude-code"}, nil
-		}
-		// On Windows, try npx.cmd specifically
-		if _, err := exec.LookPath("npx.cmd"); err == nil {
-			return "npx.cmd", []string{"claude-code"}, nil
-		}
-		return "", nil, fmt.Errorf("claude command not found, please install Claude CLI")
-
-	case "gemini":
-		if _, err := exec.LookPath("gemini"); err == nil {
-			return "gemini", []string{}, nil
-		}
-		// On Windows, try gemini.exe specifically
-		if _, err := exec.LookPath("gemini.exe"); err == nil {
-			return "gemini.exe", []string{}, nil
+	case "claude", "gemini":
+		// Configured binaries and wrappers are resolved by the provider
+		// registry; LookPath finds .exe and .cmd through PATHEXT
+		argv, err := providers.ResolveCommand(provider)
+		if err != nil {
+			return "", nil, Classify(ErrorProviderNotFound, err)
 		}
-		return "", nil, fmt.Errorf("gemini command not found, please install Gemini CLI")
+		return argv[0], argv[1:], nil
 
 	case "mock":
 		// For mock provider on Windows, use cmd with echo
 		return "cmd", []string{"/c", "echo Mock provider ready && more"}, nil
 
 	default:
-		return "", nil, fmt.Errorf("unsupported provider: %s", provider)
+		return "", nil, Classify(ErrorProviderNotFound, fmt.Errorf("unsupported provider: %s", provider))
 	}
 }
 
@@ -529,6 +813,7 @@ func (e *InteractiveExecutor) handleAgentError(agent *workflow.Agent, state *wor
 		Message:   err.Error(),
 		Timestamp: endTime,
 		Fatal:     !agent.Settings.ContinueOnError,
+		Class:     string(ClassOf(err)),
 	}
 
 	if agent.Settings.ContinueOnError {
@@ -589,6 +874,8 @@ type variablePromptModel struct {
 	inputs       []textinput.Model
 	values       map[string]interface{}
 	err          error
+	// invalid explains why the last value entered was rejected
+	invalid string
 }
 
 // promptVariable represents a workflow variable for prompting
@@ -599,6 +886,7 @@ type promptVariable struct {
 	Required     bool
 	DefaultValue interface{}
 	CurrentValue interface{}
+	Validation   *workflow.VariableValidation
 }
 
 func initialVariablePromptModel(variables []promptVariable) variablePromptModel {
@@ -682,6 +970,13 @@ func (m variablePromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			v := m.variables[m.currentIndex]
 			val := strings.TrimSpace(m.inputs[m.currentIndex].Value())
 
+			// Invalid values are asked for again, with what is wrong
+			m.invalid = ""
+			if err := checkVariable(workflow.Variable{Name: v.Name, Type: v.Type, Validation: v.Validation}, val); err != nil {
+				m.invalid = err.Error()
+				return m, nil
+			}
+
 			if val != "" {
 				// Convert based on type
 				switch v.Type {
@@ -748,6 +1043,9 @@ func (m variablePromptModel) View() string {
 		BorderForeground(lipgloss.Color("240")).
 		Padding(0, 1)
 
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196"))
+
 	var s strings.Builder
 	s.WriteString(titleStyle.Render("Configure Workflow Variables") + "\n\n")
 
@@ -764,7 +1062,11 @@ func (m variablePromptModel) View() string {
 		if i == m.currentIndex {
 			style = activeStyle
 		}
-		s.WriteString(style.Render(m.inputs[i].View()) + "\n\n")
+		s.WriteString(style.Render(m.inputs[i].View()) + "\n")
+		if i == m.currentIndex && m.invalid != "" {
+			s.WriteString(errorStyle.Render("✗ "+m.invalid) + "\n")
+		}
+		s.WriteString("\n")
 	}
 
 	s.WriteString(varStyle.Render("(Tab to navigate, Enter to confirm, Esc to cancel)"))
@@ -778,6 +1080,10 @@ func promptForVariables(variables []promptVariable) (map[string]interface{}, err
 		return make(map[string]interface{}), nil
 	}
 
+	if utils.PlainUI() {
+		return promptForVariablesPlain(variables)
+	}
+
 	p := tea.NewProgram(initialVariablePromptModel(variables))
 	m, err := p.Run()
 	if err != nil {
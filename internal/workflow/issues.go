@@ -0,0 +1,582 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Issue trackers issue steps file tickets in
+const (
+	IssueTrackerJira   = "jira"
+	IssueTrackerLinear = "linear"
+)
+
+const (
+	// defaultJiraTokenEnv and defaultLinearTokenEnv hold the API tokens
+	// when the tracker doesn't name a variable
+	defaultJiraTokenEnv   = "JIRA_API_TOKEN"
+	defaultLinearTokenEnv = "LINEAR_API_KEY"
+	// defaultLinearAPI is Linear's GraphQL endpoint
+	defaultLinearAPI = "https://api.linear.app/graphql"
+	// defaultJiraIssueType is the type of Jira issues that don't set one
+	defaultJiraIssueType = "Task"
+	// maxIssueErrorBody caps the response body quoted in an error
+	maxIssueErrorBody = 500
+)
+
+// issueHTTPClient sends issue tracker requests
+var issueHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// linearPriorities maps Linear's priority names to its numbers
+var linearPriorities = map[string]int{"none": 0, "urgent": 1, "high": 2, "medium": 3, "normal": 3, "low": 4}
+
+// IssueResult is what an issue step did, written as its output so later
+// steps can use {{step.output.json.key}} and {{step.output.json.url}}
+type IssueResult struct {
+	Tracker string `json:"tracker"`
+	Action  string `json:"action"` // created or updated
+	Key     string `json:"key,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Title   string `json:"title,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+	// Request is what a dry run would have sent
+	Request interface{} `json:"request,omitempty"`
+}
+
+// isIssueStep reports whether a step files an issue
+func isIssueStep(agent *workflow.Agent) bool {
+	return agent.Type == workflow.StepTypeIssue
+}
+
+// validateIssueStep checks an issue step's tracker and fields
+func validateIssueStep(agent *workflow.Agent) error {
+	issue := agent.Issue
+	if issue == nil {
+		return fmt.Errorf("issue step needs an issue section")
+	}
+	switch issue.Tracker {
+	case IssueTrackerJira, IssueTrackerLinear:
+	case "":
+		return fmt.Errorf("issue step needs a tracker: jira or linear")
+	default:
+		return fmt.Errorf("unknown issue tracker %q, expected jira or linear", issue.Tracker)
+	}
+	if issue.Key == "" && issue.Title == "" {
+		return fmt.Errorf("issue step needs a title to create an issue, or a key to update one")
+	}
+	if issue.Tracker == IssueTrackerLinear && issue.Priority != "" && !strings.Contains(issue.Priority, "{{") {
+		if _, err := linearPriority(issue.Priority); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderIssue resolves the references in an issue step's fields. Labels
+// that resolve to a list add every item, and empty labels are dropped.
+func renderIssue(spec *workflow.Issue, src inputSource) (workflow.Issue, error) {
+	issue := *spec
+	issue.Labels = nil
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"key", &issue.Key},
+		{"project", &issue.Project},
+		{"type", &issue.Type},
+		{"title", &issue.Title},
+		{"body", &issue.Body},
+		{"priority", &issue.Priority},
+	}
+	for _, field := range fields {
+		if *field.value == "" {
+			continue
+		}
+		value, err := renderIssueField(*field.value, src)
+		if err != nil {
+			return issue, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.value = strings.TrimSpace(renderInput(value))
+	}
+	issue.Title, _, _ = strings.Cut(issue.Title, "\n")
+
+	for _, label := range spec.Labels {
+		value, err := renderIssueField(label, src)
+		if err != nil {
+			return issue, fmt.Errorf("labels: %w", err)
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			if text := strings.TrimSpace(renderInput(item)); text != "" {
+				issue.Labels = append(issue.Labels, text)
+			}
+		}
+	}
+
+	if issue.Key == "" && issue.Title == "" {
+		return issue, fmt.Errorf("title is empty, an issue can't be created without one")
+	}
+	return issue, nil
+}
+
+// renderIssueField resolves one field, failing on references that resolve
+// to nothing
+func renderIssueField(text string, src inputSource) (interface{}, error) {
+	value, found, err := resolveInputValue(text, src)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s has nothing to resolve to", text)
+	}
+	return value, nil
+}
+
+// redactIssue scrubs secrets from the text an issue sends out
+func redactIssue(r *Redactor, issue *workflow.Issue) {
+	if r == nil {
+		return
+	}
+	issue.Title, _ = r.Redact(issue.Title)
+	issue.Body, _ = r.Redact(issue.Body)
+}
+
+// fileIssue creates or updates an issue, or only reports what it would send
+// for dry runs
+func fileIssue(ctx context.Context, trackers *workflow.IssueTrackers, issue workflow.Issue) (IssueResult, error) {
+	if trackers == nil {
+		trackers = &workflow.IssueTrackers{}
+	}
+	dryRun := issue.DryRun || trackers.DryRun
+
+	switch issue.Tracker {
+	case IssueTrackerJira:
+		if trackers.Jira == nil || trackers.Jira.URL == "" {
+			return IssueResult{}, fmt.Errorf("jira isn't configured, set issues.jira.url in the workflow settings or ~/.opun/config.yaml")
+		}
+		return fileJiraIssue(ctx, trackers.Jira, issue, dryRun)
+	case IssueTrackerLinear:
+		tracker := trackers.Linear
+		if tracker == nil {
+			tracker = &workflow.LinearTracker{}
+		}
+		return fileLinearIssue(ctx, tracker, issue, dryRun)
+	}
+	return IssueResult{}, fmt.Errorf("unknown issue tracker %q", issue.Tracker)
+}
+
+// issueToken reads a tracker's API token from its environment variable
+func issueToken(tokenEnv, fallback string) (string, error) {
+	if tokenEnv == "" {
+		tokenEnv = fallback
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("%s is not set, it must hold the API token", tokenEnv)
+	}
+	return token, nil
+}
+
+// fileJiraIssue creates or updates a Jira issue through the REST API
+func fileJiraIssue(ctx context.Context, tracker *workflow.JiraTracker, issue workflow.Issue, dryRun bool) (IssueResult, error) {
+	site := strings.TrimRight(tracker.URL, "/")
+	result := IssueResult{Tracker: IssueTrackerJira, Key: issue.Key, Title: issue.Title, DryRun: dryRun}
+
+	fields := map[string]interface{}{}
+	if issue.Title != "" {
+		fields["summary"] = issue.Title
+	}
+	if issue.Body != "" {
+		fields["description"] = issue.Body
+	}
+	if issue.Priority != "" {
+		fields["priority"] = map[string]string{"name": issue.Priority}
+	}
+
+	var (
+		method, path string
+		payload      map[string]interface{}
+	)
+	if issue.Key == "" {
+		project := issue.Project
+		if project == "" {
+			project = tracker.Project
+		}
+		if project == "" {
+			return result, fmt.Errorf("jira issue needs a project, set it on the step or issues.jira.project")
+		}
+		issueType := issue.Type
+		if issueType == "" {
+			issueType = tracker.IssueType
+		}
+		if issueType == "" {
+			issueType = defaultJiraIssueType
+		}
+		fields["project"] = map[string]string{"key": project}
+		fields["issuetype"] = map[string]string{"name": issueType}
+		if len(issue.Labels) > 0 {
+			fields["labels"] = issue.Labels
+		}
+		result.Action = "created"
+		method, path, payload = http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}
+	} else {
+		if issue.Type != "" {
+			fields["issuetype"] = map[string]string{"name": issue.Type}
+		}
+		payload = map[string]interface{}{"fields": fields}
+		// Labels are added to the ones the issue has
+		if len(issue.Labels) > 0 {
+			adds := make([]map[string]string, len(issue.Labels))
+			for i, label := range issue.Labels {
+				adds[i] = map[string]string{"add": label}
+			}
+			payload["update"] = map[string]interface{}{"labels": adds}
+		}
+		result.Action = "updated"
+		result.URL = site + "/browse/" + issue.Key
+		method, path = http.MethodPut, "/rest/api/2/issue/"+issue.Key
+	}
+
+	if dryRun {
+		result.Request = map[string]interface{}{"method": method, "url": site + path, "body": payload}
+		return result, nil
+	}
+
+	token, err := issueToken(tracker.TokenEnv, defaultJiraTokenEnv)
+	if err != nil {
+		return result, err
+	}
+	header := http.Header{}
+	if tracker.Email != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(tracker.Email+":"+token)))
+	} else {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := sendIssueRequest(ctx, IssueTrackerJira, method, site+path, header, payload, &created); err != nil {
+		return result, err
+	}
+	if created.Key != "" {
+		result.Key = created.Key
+		result.URL = site + "/browse/" + created.Key
+	}
+	return result, nil
+}
+
+// fileLinearIssue creates or updates a Linear issue through the GraphQL API
+func fileLinearIssue(ctx context.Context, tracker *workflow.LinearTracker, issue workflow.Issue, dryRun bool) (IssueResult, error) {
+	endpoint := tracker.APIURL
+	if endpoint == "" {
+		endpoint = defaultLinearAPI
+	}
+	result := IssueResult{Tracker: IssueTrackerLinear, Key: issue.Key, Title: issue.Title, DryRun: dryRun, Action: "updated"}
+	team := issue.Project
+	if team == "" {
+		team = tracker.Team
+	}
+	if issue.Key == "" {
+		result.Action = "created"
+		if team == "" {
+			return result, fmt.Errorf("linear issue needs a team, set project on the step or issues.linear.team")
+		}
+	}
+
+	input := map[string]interface{}{}
+	if issue.Title != "" {
+		input["title"] = issue.Title
+	}
+	if issue.Body != "" {
+		input["description"] = issue.Body
+	}
+	if issue.Priority != "" {
+		priority, err := linearPriority(issue.Priority)
+		if err != nil {
+			return result, err
+		}
+		input["priority"] = priority
+	}
+
+	if dryRun {
+		// Teams and labels are looked up by key and name when sent for real
+		request := map[string]interface{}{"url": endpoint, "input": input}
+		if issue.Key == "" {
+			request["team"] = team
+		} else {
+			request["id"] = issue.Key
+		}
+		if len(issue.Labels) > 0 {
+			request["labels"] = issue.Labels
+		}
+		result.Request = request
+		return result, nil
+	}
+
+	token, err := issueToken(tracker.TokenEnv, defaultLinearTokenEnv)
+	if err != nil {
+		return result, err
+	}
+	graphql := func(query string, variables map[string]interface{}, out interface{}) error {
+		var response struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		header := http.Header{}
+		header.Set("Authorization", token)
+		body := map[string]interface{}{"query": query, "variables": variables}
+		if err := sendIssueRequest(ctx, IssueTrackerLinear, http.MethodPost, endpoint, header, body, &response); err != nil {
+			return err
+		}
+		if len(response.Errors) > 0 {
+			return fmt.Errorf("linear: %s", response.Errors[0].Message)
+		}
+		return json.Unmarshal(response.Data, out)
+	}
+
+	if issue.Key == "" {
+		var teams struct {
+			Teams struct {
+				Nodes []struct {
+					ID string `json:"id"`
+				} `json:"nodes"`
+			} `json:"teams"`
+		}
+		if err := graphql(`query($key: String!) { teams(filter: {key: {eq: $key}}) { nodes { id } } }`, map[string]interface{}{"key": team}, &teams); err != nil {
+			return result, err
+		}
+		if len(teams.Teams.Nodes) == 0 {
+			return result, fmt.Errorf("linear: no team with key %s", team)
+		}
+		input["teamId"] = teams.Teams.Nodes[0].ID
+	}
+
+	if len(issue.Labels) > 0 {
+		var labels struct {
+			IssueLabels struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"issueLabels"`
+		}
+		if err := graphql(`query($names: [String!]) { issueLabels(filter: {name: {in: $names}}) { nodes { id name } } }`, map[string]interface{}{"names": issue.Labels}, &labels); err != nil {
+			return result, err
+		}
+		found := make(map[string]bool)
+		var ids []string
+		for _, label := range labels.IssueLabels.Nodes {
+			found[label.Name] = true
+			ids = append(ids, label.ID)
+		}
+		for _, label := range issue.Labels {
+			if !found[label] {
+				fmt.Printf("⚠️  Linear has no label %q, skipping it\n", label)
+			}
+		}
+		if len(ids) > 0 {
+			// Updates add the labels to the ones the issue has
+			if issue.Key == "" {
+				input["labelIds"] = ids
+			} else {
+				input["addedLabelIds"] = ids
+			}
+		}
+	}
+
+	var payload struct {
+		Issue struct {
+			Identifier string `json:"identifier"`
+			URL        string `json:"url"`
+		} `json:"issue"`
+	}
+	if issue.Key == "" {
+		var response struct {
+			IssueCreate *struct {
+				Issue json.RawMessage `json:"issue"`
+			} `json:"issueCreate"`
+		}
+		if err := graphql(`mutation($input: IssueCreateInput!) { issueCreate(input: $input) { success issue { identifier url } } }`, map[string]interface{}{"input": input}, &response); err != nil {
+			return result, err
+		}
+		if response.IssueCreate != nil {
+			_ = json.Unmarshal(response.IssueCreate.Issue, &payload.Issue)
+		}
+	} else {
+		var response struct {
+			IssueUpdate *struct {
+				Issue json.RawMessage `json:"issue"`
+			} `json:"issueUpdate"`
+		}
+		if err := graphql(`mutation($id: String!, $input: IssueUpdateInput!) { issueUpdate(id: $id, input: $input) { success issue { identifier url } } }`, map[string]interface{}{"id": issue.Key, "input": input}, &response); err != nil {
+			return result, err
+		}
+		if response.IssueUpdate != nil {
+			_ = json.Unmarshal(response.IssueUpdate.Issue, &payload.Issue)
+		}
+	}
+	if payload.Issue.Identifier == "" {
+		return result, fmt.Errorf("linear didn't return the issue")
+	}
+	result.Key = payload.Issue.Identifier
+	result.URL = payload.Issue.URL
+	return result, nil
+}
+
+// linearPriority reads a Linear priority given as 0-4 or its name
+func linearPriority(priority string) (int, error) {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if n, err := strconv.Atoi(priority); err == nil && n >= 0 && n <= 4 {
+		return n, nil
+	}
+	if n, ok := linearPriorities[priority]; ok {
+		return n, nil
+	}
+	return 0, fmt.Errorf("invalid linear priority %q, expected 0-4, urgent, high, medium, low or none", priority)
+}
+
+// sendIssueRequest sends a JSON request to an issue tracker and decodes
+// the response into out
+func sendIssueRequest(ctx context.Context, tracker, method, url string, header http.Header, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := issueHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", tracker, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", tracker, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text := strings.TrimSpace(string(respBody))
+		if len(text) > maxIssueErrorBody {
+			text = text[:maxIssueErrorBody] + "…"
+		}
+		return fmt.Errorf("%s returned %s: %s", tracker, resp.Status, text)
+	}
+	if out == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// printIssueResult reports what an issue step did
+func printIssueResult(result IssueResult) {
+	tracker := "Jira"
+	if result.Tracker == IssueTrackerLinear {
+		tracker = "Linear"
+	}
+	if result.DryRun {
+		verb := "create"
+		if result.Action == "updated" {
+			verb = "update"
+		}
+		request, _ := json.MarshalIndent(result.Request, "   ", "  ")
+		fmt.Printf("🧪 Dry run: would %s %s issue %s\n   %s\n", verb, tracker, strings.TrimSpace(result.Key+" "+result.Title), request)
+		return
+	}
+	fmt.Printf("🎫 %s %s issue %s: %s\n", strings.ToUpper(result.Action[:1])+result.Action[1:], tracker, result.Key, result.URL)
+}
+
+// writeIssueResult writes an issue step's result as its JSON output
+func writeIssueResult(path string, result IssueResult) (string, error) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return string(data), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return string(data), os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// executeIssue runs an issue step of an interactive run. Its result is
+// written to the step's output file when it has one.
+func (e *InteractiveExecutor) executeIssue(ctx context.Context, agent *workflow.Agent) error {
+	startTime := time.Now()
+	agentState := &workflow.AgentState{
+		AgentID:   agent.ID,
+		StartTime: &startTime,
+		Status:    workflow.StatusRunning,
+		Attempts:  1,
+	}
+
+	e.mu.Lock()
+	e.state.AgentStates[agent.ID] = agentState
+	e.state.CurrentAgent = agent.Name
+	e.mu.Unlock()
+
+	if err := validateIssueStep(agent); err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+	issue, err := renderIssue(agent.Issue, e.inputSource())
+	if err != nil {
+		return e.handleAgentError(agent, agentState, fmt.Errorf("issue step %s: %w", agent.ID, err))
+	}
+	redactIssue(e.redactor, &issue)
+
+	result, err := fileIssue(ctx, e.workflow.Settings.Issues, issue)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, fmt.Errorf("issue step %s: %w", agent.ID, err))
+	}
+	printIssueResult(result)
+	output, err := writeIssueResult(e.agentOutputPath(agent), result)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, fmt.Errorf("issue step %s: %w", agent.ID, err))
+	}
+
+	endTime := time.Now()
+	agentState.Status = workflow.StatusCompleted
+	agentState.EndTime = &endTime
+	agentState.Output = output
+	return nil
+}
@@ -0,0 +1,420 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// kubernetesScheme selects Kubernetes Jobs as a step's target, as k8s or
+// k8s://<namespace>
+const kubernetesScheme = "k8s"
+
+const (
+	defaultKubernetesNamespace = "default"
+	defaultKubernetesMount     = "/workspace"
+	defaultKubernetesTimeout   = 30 * time.Minute
+
+	// kubernetesStateDir holds a step's output and exit code in its pod,
+	// which waits up to kubernetesReleaseGrace for Opun to copy them
+	kubernetesStateDir     = "/tmp/opun"
+	kubernetesReleaseGrace = 10 * time.Minute
+
+	// kubernetesJobTTL is how long the cluster keeps a finished Job that
+	// Opun didn't delete, in seconds
+	kubernetesJobTTL = 3600
+)
+
+// kubernetesPollInterval is how often a Job's pod is checked
+var kubernetesPollInterval = 2 * time.Second
+
+// kubernetesScript runs the provider command given as its arguments, keeps
+// its output and exit code, then waits to be released so Opun can copy them
+// and the step's artifacts out of the pod
+var kubernetesScript = fmt.Sprintf(`mkdir -p %[1]s
+"$@" >%[1]s/stdout 2>%[1]s/stderr
+echo $? >%[1]s/exit.tmp && mv %[1]s/exit.tmp %[1]s/exit
+i=0
+while [ ! -f %[1]s/release ] && [ $i -lt %[2]d ]; do sleep 1; i=$((i+1)); done
+exit "$(cat %[1]s/exit)"`, kubernetesStateDir, int(kubernetesReleaseGrace.Seconds()))
+
+// kubernetesWaitingFailures are container waiting reasons that won't resolve
+// by waiting longer
+var kubernetesWaitingFailures = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+var kubernetesNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// IsKubernetesTarget reports whether a target runs steps as Kubernetes Jobs
+func IsKubernetesTarget(target string) bool {
+	return target == kubernetesScheme || strings.HasPrefix(target, kubernetesScheme+"://")
+}
+
+// kubectlCommand runs kubectl and returns its standard output
+var kubectlCommand = func(ctx context.Context, stdin []byte, args []string) (string, error) {
+	// #nosec G204 -- kubectl with arguments built from the kubernetes settings
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("kubectl failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("kubectl failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// kubernetesNamespace returns the namespace a k8s target runs in
+func kubernetesNamespace(target string, settings *workflow.Kubernetes) string {
+	if ns := strings.TrimPrefix(target, kubernetesScheme+"://"); ns != target && ns != "" {
+		return ns
+	}
+	if settings != nil && settings.Namespace != "" {
+		return settings.Namespace
+	}
+	return defaultKubernetesNamespace
+}
+
+// kubernetesTimeout returns the longest a step's Job may run
+func kubernetesTimeout(settings *workflow.Kubernetes) (time.Duration, error) {
+	if settings.Timeout == "" {
+		return defaultKubernetesTimeout, nil
+	}
+	d, err := time.ParseDuration(settings.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid kubernetes timeout %q", settings.Timeout)
+	}
+	return d, nil
+}
+
+// validateKubernetes checks the kubernetes settings when a step targets k8s
+func validateKubernetes(wf *workflow.Workflow) error {
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		target := agentTarget(wf, agent)
+		if !isProviderStep(agent) || !IsKubernetesTarget(target) {
+			continue
+		}
+		settings := wf.Settings.Kubernetes
+		if settings == nil || settings.Image == "" || settings.Volume == "" {
+			return fmt.Errorf("agent %s: target %s needs settings.kubernetes with an image and a volume", agent.ID, target)
+		}
+		if _, err := kubernetesTimeout(settings); err != nil {
+			return err
+		}
+		if ns := kubernetesNamespace(target, settings); kubernetesName(ns, 63) != ns {
+			return fmt.Errorf("agent %s: invalid kubernetes namespace %q", agent.ID, ns)
+		}
+		if settings.MountPath != "" && !path.IsAbs(settings.MountPath) {
+			return fmt.Errorf("kubernetes mount_path must be absolute, got %q", settings.MountPath)
+		}
+	}
+	return nil
+}
+
+// kubernetesName turns s into a lowercase DNS label of at most max characters
+func kubernetesName(s string, max int) string {
+	name := kubernetesNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	if len(name) > max {
+		name = name[:max]
+	}
+	return strings.Trim(name, "-")
+}
+
+// kubernetesJob runs a headless step as a Kubernetes Job. The pod mounts the
+// repository from a volume claim, runs the provider's print mode and keeps
+// the answer until Opun has read it and copied the step's artifacts back.
+type kubernetesJob struct {
+	settings  *workflow.Kubernetes
+	namespace string
+	name      string
+	labels    map[string]string
+	// workDir is the local repository, mounted at mountPath in the pod
+	workDir   string
+	mountPath string
+	timeout   time.Duration
+	poll      time.Duration
+}
+
+// newKubernetesJob prepares the Job for one run of an agent
+func newKubernetesJob(wf *workflow.Workflow, agent *workflow.Agent) (*kubernetesJob, error) {
+	if err := validateKubernetes(&workflow.Workflow{Settings: wf.Settings, Agents: []workflow.Agent{*agent}}); err != nil {
+		return nil, err
+	}
+	settings := wf.Settings.Kubernetes
+	timeout, _ := kubernetesTimeout(settings)
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	name := "opun-" + kubernetesName(wf.Name+"-"+agent.ID, 40)
+	name = strings.TrimSuffix(name, "-") + "-" + hex.EncodeToString(suffix)
+
+	mountPath := settings.MountPath
+	if mountPath == "" {
+		mountPath = defaultKubernetesMount
+	}
+	return &kubernetesJob{
+		settings:  settings,
+		namespace: kubernetesNamespace(agentTarget(wf, agent), settings),
+		name:      name,
+		labels: map[string]string{
+			"app.kubernetes.io/managed-by": "opun",
+			"opun.dev/workflow":            kubernetesName(wf.Name, 63),
+			"opun.dev/agent":               kubernetesName(agent.ID, 63),
+		},
+		workDir:   workDir,
+		mountPath: mountPath,
+		timeout:   timeout,
+		poll:      kubernetesPollInterval,
+	}, nil
+}
+
+// kubectl runs kubectl in the Job's context and namespace
+func (j *kubernetesJob) kubectl(ctx context.Context, stdin []byte, args ...string) (string, error) {
+	var global []string
+	if j.settings.Context != "" {
+		global = append(global, "--context", j.settings.Context)
+	}
+	global = append(global, "--namespace", j.namespace)
+	return kubectlCommand(ctx, stdin, append(global, args...))
+}
+
+// manifest returns the Job running command in the pod
+func (j *kubernetesJob) manifest(command []string) map[string]interface{} {
+	container := map[string]interface{}{
+		"name":       "step",
+		"image":      j.settings.Image,
+		"command":    append([]string{"sh", "-c", kubernetesScript, "opun"}, command...),
+		"workingDir": j.mountPath,
+		"volumeMounts": []interface{}{
+			map[string]interface{}{"name": "repository", "mountPath": j.mountPath, "subPath": j.settings.SubPath},
+		},
+	}
+	if j.settings.EnvSecret != "" {
+		container["envFrom"] = []interface{}{
+			map[string]interface{}{"secretRef": map[string]interface{}{"name": j.settings.EnvSecret}},
+		}
+	}
+	resources := make(map[string]interface{})
+	if j.settings.CPU != "" {
+		resources["cpu"] = j.settings.CPU
+	}
+	if j.settings.Memory != "" {
+		resources["memory"] = j.settings.Memory
+	}
+	if len(resources) > 0 {
+		container["resources"] = map[string]interface{}{"requests": resources, "limits": resources}
+	}
+
+	pod := map[string]interface{}{
+		"restartPolicy": "Never",
+		"containers":    []interface{}{container},
+		"volumes": []interface{}{
+			map[string]interface{}{"name": "repository", "persistentVolumeClaim": map[string]interface{}{"claimName": j.settings.Volume}},
+		},
+	}
+	if j.settings.ServiceAccount != "" {
+		pod["serviceAccountName"] = j.settings.ServiceAccount
+	}
+	if len(j.settings.NodeSelector) > 0 {
+		pod["nodeSelector"] = j.settings.NodeSelector
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": j.name, "labels": j.labels},
+		"spec": map[string]interface{}{
+			// Opun retries steps itself
+			"backoffLimit":            0,
+			"activeDeadlineSeconds":   int((j.timeout + kubernetesReleaseGrace).Seconds()),
+			"ttlSecondsAfterFinished": kubernetesJobTTL,
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": j.labels},
+				"spec":     pod,
+			},
+		},
+	}
+}
+
+// containerPath maps a local path in the repository to the pod
+func (j *kubernetesJob) containerPath(local string) (string, bool) {
+	rel := local
+	if filepath.IsAbs(local) {
+		var err error
+		if rel, err = filepath.Rel(j.workDir, local); err != nil {
+			return "", false
+		}
+	}
+	rel = filepath.Clean(rel)
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return path.Join(j.mountPath, filepath.ToSlash(rel)), true
+}
+
+// run creates the Job, waits for the provider to finish and reads its answer
+func (j *kubernetesJob) run(ctx context.Context, provider, model, prompt string, args, pull []string) (providers.HeadlessResult, error) {
+	command, err := providers.ContainerHeadlessCommand(provider, model, prompt, j.mountPath, args...)
+	if err != nil {
+		return providers.HeadlessResult{}, err
+	}
+	manifest, err := json.Marshal(j.manifest(command))
+	if err != nil {
+		return providers.HeadlessResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	fmt.Printf("☸️  Running %s as Kubernetes Job %s/%s\n", provider, j.namespace, j.name)
+	if _, err := j.kubectl(ctx, manifest, "create", "-f", "-"); err != nil {
+		return providers.HeadlessResult{}, err
+	}
+	defer j.cleanup()
+
+	pod, code, err := j.wait(ctx)
+	if err != nil {
+		return providers.HeadlessResult{}, err
+	}
+	defer func() {
+		// Let the pod exit now that its results are read
+		_, _ = j.kubectl(context.Background(), nil, "exec", pod, "--", "touch", kubernetesStateDir+"/release")
+	}()
+
+	stdout, err := j.kubectl(ctx, nil, "exec", pod, "--", "cat", kubernetesStateDir+"/stdout")
+	if err != nil {
+		return providers.HeadlessResult{}, err
+	}
+	result, parseErr := providers.ParseHeadlessOutput(provider, []byte(stdout))
+	if code != 0 {
+		stderr, _ := j.kubectl(ctx, nil, "exec", pod, "--", "cat", kubernetesStateDir+"/stderr")
+		if stderr == "" && parseErr != nil {
+			stderr = parseErr.Error()
+		}
+		return result, fmt.Errorf("%s failed in job %s with exit code %d: %s", provider, j.name, code, stderr)
+	}
+	if parseErr != nil {
+		return result, parseErr
+	}
+
+	for _, local := range pull {
+		remote, ok := j.containerPath(local)
+		if !ok {
+			fmt.Printf("⚠️  %s is outside the repository, not copied from Job %s\n", local, j.name)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+			return result, err
+		}
+		// A missing file is reported by the artifact check afterwards
+		_, _ = j.kubectl(ctx, nil, "cp", pod+":"+remote, local)
+	}
+	return result, nil
+}
+
+// wait waits for the provider in the Job's pod to exit and returns the pod
+// and the provider's exit code
+func (j *kubernetesJob) wait(ctx context.Context) (string, int, error) {
+	ticker := time.NewTicker(j.poll)
+	defer ticker.Stop()
+
+	pod := ""
+	for {
+		if pod == "" {
+			name, err := j.kubectl(ctx, nil, "get", "pods", "--selector", "job-name="+j.name, "--output", "jsonpath={.items[0].metadata.name}")
+			if err == nil {
+				pod = name
+			}
+		}
+		if pod != "" {
+			status, err := j.kubectl(ctx, nil, "get", "pod", pod, "--output", "jsonpath={.status.phase} {.status.containerStatuses[0].state.waiting.reason}")
+			if err != nil {
+				return "", 0, err
+			}
+			phase, reason, _ := strings.Cut(status, " ")
+			switch {
+			case kubernetesWaitingFailures[reason]:
+				return "", 0, fmt.Errorf("job %s can't start: %s", j.name, reason)
+			case phase == "Failed" || phase == "Succeeded":
+				logs, _ := j.kubectl(ctx, nil, "logs", pod, "--tail", "20")
+				return "", 0, fmt.Errorf("job %s stopped before Opun read its result (%s): %s", j.name, phase, logs)
+			case phase == "Running":
+				if exit, err := j.kubectl(ctx, nil, "exec", pod, "--", "cat", kubernetesStateDir+"/exit"); err == nil {
+					code, err := strconv.Atoi(strings.TrimSpace(exit))
+					if err != nil {
+						return "", 0, fmt.Errorf("job %s wrote an invalid exit code: %q", j.name, exit)
+					}
+					return pod, code, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", 0, Classify(ErrorTimeout, fmt.Errorf("job %s didn't finish within %s", j.name, j.timeout))
+			}
+			return "", 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanup deletes the Job and its pod unless they are kept
+func (j *kubernetesJob) cleanup() {
+	if j.settings.Keep {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := j.kubectl(ctx, nil, "delete", "job", j.name, "--ignore-not-found", "--wait=false", "--cascade=background"); err != nil {
+		fmt.Printf("⚠️  Failed to delete Kubernetes Job %s: %v\n", j.name, err)
+	}
+}
@@ -0,0 +1,208 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster answers kubectl commands for one Job
+type fakeCluster struct {
+	calls    []string
+	manifest string
+	phases   []string
+	exit     string
+	stdout   string
+	stderr   string
+	files    map[string]string
+}
+
+func (c *fakeCluster) install(t *testing.T) {
+	t.Helper()
+	previousCommand, previousPoll := kubectlCommand, kubernetesPollInterval
+	kubernetesPollInterval = time.Millisecond
+	kubectlCommand = func(ctx context.Context, stdin []byte, args []string) (string, error) {
+		// Skip the global flags
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[2:]
+		}
+		command := strings.Join(args, " ")
+		c.calls = append(c.calls, command)
+		switch {
+		case args[0] == "create":
+			c.manifest = string(stdin)
+		case strings.HasPrefix(command, "get pods"):
+			return "step-pod", nil
+		case strings.HasPrefix(command, "get pod step-pod"):
+			phase := c.phases[0]
+			if len(c.phases) > 1 {
+				c.phases = c.phases[1:]
+			}
+			return phase, nil
+		case strings.HasSuffix(command, "/exit"):
+			if c.phases[0] != "Running" {
+				return "", fmt.Errorf("kubectl failed: no such file")
+			}
+			return c.exit, nil
+		case strings.HasSuffix(command, "/stdout"):
+			return c.stdout, nil
+		case strings.HasSuffix(command, "/stderr"):
+			return c.stderr, nil
+		case args[0] == "cp":
+			remote := strings.TrimPrefix(args[1], "step-pod:")
+			content, ok := c.files[remote]
+			if !ok {
+				return "", fmt.Errorf("kubectl failed: %s: no such file", remote)
+			}
+			return "", os.WriteFile(args[2], []byte(content), 0644)
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { kubectlCommand, kubernetesPollInterval = previousCommand, previousPoll })
+}
+
+func TestKubernetesTargets(t *testing.T) {
+	assert.True(t, IsKubernetesTarget("k8s"))
+	assert.True(t, IsKubernetesTarget("k8s://batch"))
+	assert.False(t, IsKubernetesTarget("ssh://build@host"))
+	assert.True(t, IsRemoteTarget("k8s"))
+
+	settings := &workflow.Kubernetes{Namespace: "opun"}
+	assert.Equal(t, "batch", kubernetesNamespace("k8s://batch", settings))
+	assert.Equal(t, "opun", kubernetesNamespace("k8s", settings))
+	assert.Equal(t, "default", kubernetesNamespace("k8s", nil))
+
+	_, err := ParseTarget("k8s://batch")
+	assert.ErrorContains(t, err, "headless steps only")
+
+	assert.Equal(t, "review-fix-ci", kubernetesName("Review/Fix CI-", 63))
+	assert.Equal(t, "abc", kubernetesName("abc-def", 4))
+}
+
+func TestValidateKubernetes(t *testing.T) {
+	wf := &workflow.Workflow{
+		Settings: workflow.Settings{Target: "k8s"},
+		Agents:   []workflow.Agent{{ID: "review", Provider: "claude", Prompt: "Review"}},
+	}
+	assert.ErrorContains(t, validateKubernetes(wf), "needs settings.kubernetes")
+
+	wf.Settings.Kubernetes = &workflow.Kubernetes{Image: "ghcr.io/acme/claude", Volume: "repo"}
+	assert.NoError(t, validateKubernetes(wf))
+
+	wf.Settings.Kubernetes.Timeout = "soon"
+	assert.Error(t, validateKubernetes(wf))
+	wf.Settings.Kubernetes.Timeout = ""
+
+	wf.Agents[0].Target = "k8s://Batch_Jobs"
+	assert.ErrorContains(t, validateKubernetes(wf), "invalid kubernetes namespace")
+
+	// Local steps need no cluster settings
+	assert.NoError(t, validateKubernetes(&workflow.Workflow{Agents: wf.Agents[:0]}))
+}
+
+func TestKubernetesJobManifest(t *testing.T) {
+	wf := &workflow.Workflow{
+		Name: "review",
+		Settings: workflow.Settings{Kubernetes: &workflow.Kubernetes{
+			Context: "prod", Namespace: "opun", Image: "ghcr.io/acme/claude", Volume: "repo", SubPath: "src",
+			EnvSecret: "provider-keys", CPU: "2", Memory: "4Gi", ServiceAccount: "runner", Timeout: "10m",
+		}},
+	}
+	agent := &workflow.Agent{ID: "fix", Provider: "claude", Prompt: "Fix it", Target: "k8s"}
+	job, err := newKubernetesJob(wf, agent)
+	require.NoError(t, err)
+	assert.Regexp(t, `^opun-review-fix-[0-9a-f]{8}$`, job.name)
+	assert.Equal(t, "opun", job.namespace)
+
+	manifest := job.manifest([]string{"claude", "-p", "Fix it"})
+	spec := manifest["spec"].(map[string]interface{})
+	assert.Equal(t, 0, spec["backoffLimit"])
+	assert.Equal(t, int((10*time.Minute + kubernetesReleaseGrace).Seconds()), spec["activeDeadlineSeconds"])
+	pod := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, "runner", pod["serviceAccountName"])
+	container := pod["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "ghcr.io/acme/claude", container["image"])
+	assert.Equal(t, "/workspace", container["workingDir"])
+	assert.Equal(t, []string{"claude", "-p", "Fix it"}, container["command"].([]string)[4:])
+	assert.Contains(t, fmt.Sprint(container["envFrom"]), "provider-keys")
+	assert.Contains(t, fmt.Sprint(container["resources"]), "cpu:2")
+	assert.Contains(t, fmt.Sprint(pod["volumes"]), "claimName:repo")
+
+	remote, ok := job.containerPath("out/review.md")
+	assert.True(t, ok)
+	assert.Equal(t, "/workspace/out/review.md", remote)
+	_, ok = job.containerPath("../elsewhere.md")
+	assert.False(t, ok)
+}
+
+func TestKubernetesHeadlessRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cluster := &fakeCluster{
+		phases: []string{"Pending ContainerCreating", "Running", "Running"},
+		exit:   "0",
+		stdout: "Mock response",
+		files:  map[string]string{"/workspace/notes/plan.md": "# Plan\n"},
+	}
+	cluster.install(t)
+
+	wf := &workflow.Workflow{
+		Name: "plan",
+		Settings: workflow.Settings{
+			Target:     "k8s://batch",
+			Kubernetes: &workflow.Kubernetes{Image: "ghcr.io/acme/claude", Volume: "repo"},
+		},
+		Agents: []workflow.Agent{{
+			ID: "plan", Provider: "mock", Prompt: "Plan it",
+			Produces: []workflow.Artifact{{File: "notes/plan.md"}},
+		}},
+	}
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	result, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+
+	output, err := os.ReadFile(result.Final)
+	require.NoError(t, err)
+	assert.Equal(t, "Mock response\n", string(output))
+	pulled, err := os.ReadFile(filepath.Join("notes", "plan.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Plan\n", string(pulled))
+
+	assert.Contains(t, cluster.manifest, `"kind":"Job"`)
+	assert.Contains(t, cluster.manifest, "Mock response to: Plan it")
+	assert.Contains(t, cluster.calls, "exec step-pod -- touch /tmp/opun/release")
+	assert.Regexp(t, `^delete job opun-plan-plan-[0-9a-f]{8} `, cluster.calls[len(cluster.calls)-1])
+}
+
+func TestKubernetesJobFailures(t *testing.T) {
+	t.Chdir(t.TempDir())
+	wf := &workflow.Workflow{
+		Name:     "plan",
+		Settings: workflow.Settings{Kubernetes: &workflow.Kubernetes{Image: "ghcr.io/acme/claude", Volume: "repo", Keep: true}},
+	}
+	agent := &workflow.Agent{ID: "plan", Provider: "mock", Prompt: "Plan it", Target: "k8s"}
+
+	cluster := &fakeCluster{phases: []string{"Running"}, exit: "2", stderr: "not logged in"}
+	cluster.install(t)
+	job, err := newKubernetesJob(wf, agent)
+	require.NoError(t, err)
+	_, err = job.run(context.Background(), "mock", "", "Plan it", nil, nil)
+	assert.ErrorContains(t, err, "exit code 2: not logged in")
+	for _, call := range cluster.calls {
+		assert.False(t, strings.HasPrefix(call, "delete"), "kept jobs aren't deleted")
+	}
+
+	cluster = &fakeCluster{phases: []string{"Pending ImagePullBackOff"}}
+	cluster.install(t)
+	job, err = newKubernetesJob(wf, agent)
+	require.NoError(t, err)
+	_, err = job.run(context.Background(), "mock", "", "Plan it", nil, nil)
+	assert.ErrorContains(t, err, "can't start: ImagePullBackOff")
+}
@@ -0,0 +1,259 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Actions when another run holds a workflow's lock
+const (
+	LockWait = "wait"
+	LockFail = "fail"
+)
+
+// lockPollInterval is how often a queued run checks whether its lock is free
+var lockPollInterval = time.Second
+
+var lockNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// LockHolder is the run holding a named lock, persisted so queued runs and
+// `opun status` can see who has it
+type LockHolder struct {
+	Name       string    `json:"name"`
+	RunID      string    `json:"run_id,omitempty"`
+	PID        int       `json:"pid"`
+	Workflow   string    `json:"workflow"`
+	WorkDir    string    `json:"work_dir,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// RunLock is a named lock this process holds
+type RunLock struct {
+	lock *utils.LockFile
+}
+
+// LockedError is returned when a run won't wait for a lock another run holds
+type LockedError struct {
+	Holder LockHolder
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("lock %q is held by workflow %s (run %s, pid %d) since %s",
+		e.Holder.Name, e.Holder.Workflow, e.Holder.RunID, e.Holder.PID, e.Holder.AcquiredAt.Format(time.Kitchen))
+}
+
+// LocksDir returns the directory holding the named locks of running workflows
+func LocksDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "locks"), nil
+}
+
+// validateLockSettings checks a workflow's lock name, on_locked action and timeout
+func validateLockSettings(settings workflow.Settings) error {
+	if settings.Lock == "" {
+		if settings.OnLocked != "" || settings.LockTimeout != "" {
+			return fmt.Errorf("on_locked and lock_timeout need a lock name in settings.lock")
+		}
+		return nil
+	}
+	if !lockNamePattern.MatchString(settings.Lock) {
+		return fmt.Errorf("invalid lock name %q: use letters, digits, '.', '_' and '-'", settings.Lock)
+	}
+	switch settings.OnLocked {
+	case "", LockWait, LockFail:
+	default:
+		return fmt.Errorf("on_locked must be wait or fail, got %q", settings.OnLocked)
+	}
+	if settings.LockTimeout != "" {
+		d, err := time.ParseDuration(settings.LockTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid lock_timeout %q: %w", settings.LockTimeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("lock_timeout must be positive, got %s", settings.LockTimeout)
+		}
+	}
+	return nil
+}
+
+// AcquireLock takes a workflow's lock for holder. Without settings.lock it
+// returns a nil lock. While another run holds the lock, the run fails with a
+// LockedError (on_locked: fail) or waits for it, up to lock_timeout; onWait
+// is called once with the holder when the run starts waiting.
+func AcquireLock(ctx context.Context, dir string, settings workflow.Settings, holder LockHolder, onWait func(LockHolder)) (*RunLock, error) {
+	if settings.Lock == "" {
+		return nil, nil
+	}
+	if err := validateLockSettings(settings); err != nil {
+		return nil, err
+	}
+	holder.Name = settings.Lock
+
+	var deadline <-chan time.Time
+	if settings.LockTimeout != "" {
+		timeout, _ := time.ParseDuration(settings.LockTimeout)
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	waiting := false
+	for {
+		lock, current, err := TryLock(dir, holder)
+		if err != nil || lock != nil {
+			return lock, err
+		}
+
+		locked := &LockedError{Holder: *current}
+		if settings.OnLocked == LockFail {
+			return nil, Classify(ErrorLocked, locked)
+		}
+		if !waiting {
+			waiting = true
+			if onWait != nil {
+				onWait(*current)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, Classify(ErrorLocked, fmt.Errorf("gave up waiting %s for the lock: %w", settings.LockTimeout, locked))
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// LockRun takes a workflow's lock for a run with LocksDir and AcquireLock,
+// showing the run as waiting in `opun status` while it is queued. The
+// tracker and onWait may be nil.
+func LockRun(ctx context.Context, wf *workflow.Workflow, runID string, tracker *RunTracker, onWait func(LockHolder)) (*RunLock, error) {
+	if wf.Settings.Lock == "" {
+		return nil, nil
+	}
+	dir, err := LocksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, _ := os.Getwd()
+	holder := LockHolder{RunID: runID, Workflow: wf.Name, WorkDir: workDir}
+	lock, err := AcquireLock(ctx, dir, wf.Settings, holder, func(current LockHolder) {
+		if tracker != nil {
+			tracker.SetLock(wf.Settings.Lock, true)
+		}
+		if onWait != nil {
+			onWait(current)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tracker != nil {
+		tracker.SetLock(wf.Settings.Lock, false)
+	}
+	return lock, nil
+}
+
+// TryLock takes a named lock unless a live run holds it, in which case the
+// holder is returned instead. Locks left behind by processes that no longer
+// exist are taken over.
+func TryLock(dir string, holder LockHolder) (*RunLock, *LockHolder, error) {
+	if holder.PID == 0 {
+		holder.PID = os.Getpid()
+	}
+	if holder.AcquiredAt.IsZero() {
+		holder.AcquiredAt = time.Now()
+	}
+	data, err := json.MarshalIndent(holder, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lock, held, err := utils.TryLockFile(filepath.Join(dir, holder.Name+".json"), data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lock %s: %w", holder.Name, err)
+	}
+	if lock != nil {
+		return &RunLock{lock: lock}, nil, nil
+	}
+	// Being written right now, the holder isn't known yet
+	current := &LockHolder{Name: holder.Name}
+	_ = json.Unmarshal(held, current)
+	return nil, current, nil
+}
+
+// Release gives the lock up. A nil lock is a no-op.
+func (l *RunLock) Release() {
+	if l == nil {
+		return
+	}
+	l.lock.Release()
+}
+
+// ListLocks returns the held locks by name. Locks left behind by processes
+// that no longer exist are removed.
+func ListLocks(dir string) ([]LockHolder, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var locks []LockHolder
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// #nosec G304 -- lock files live in the Opun directory
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var holder LockHolder
+		if err := json.Unmarshal(data, &holder); err != nil {
+			continue
+		}
+		if !utils.ProcessAlive(holder.PID) {
+			utils.RemoveStaleLockFile(path, data)
+			continue
+		}
+		locks = append(locks, holder)
+	}
+
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Name < locks[j].Name })
+	return locks, nil
+}
@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLockSettings(t *testing.T) {
+	assert.NoError(t, validateLockSettings(workflow.Settings{}))
+	assert.NoError(t, validateLockSettings(workflow.Settings{Lock: "repo-main", OnLocked: LockFail, LockTimeout: "5m"}))
+	assert.Error(t, validateLockSettings(workflow.Settings{Lock: "../repo"}))
+	assert.Error(t, validateLockSettings(workflow.Settings{Lock: "repo", OnLocked: "queue"}))
+	assert.Error(t, validateLockSettings(workflow.Settings{Lock: "repo", LockTimeout: "soon"}))
+	assert.Error(t, validateLockSettings(workflow.Settings{OnLocked: LockWait}), "on_locked without a lock")
+}
+
+func TestTryLock(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, holder, err := TryLock(dir, LockHolder{Name: "repo-main", RunID: "a", Workflow: "deploy"})
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	assert.Nil(t, holder)
+
+	again, holder, err := TryLock(dir, LockHolder{Name: "repo-main", RunID: "b", Workflow: "refactor"})
+	require.NoError(t, err)
+	assert.Nil(t, again)
+	require.NotNil(t, holder)
+	assert.Equal(t, "deploy", holder.Workflow)
+	assert.Equal(t, os.Getpid(), holder.PID)
+
+	locks, err := ListLocks(dir)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, "a", locks[0].RunID)
+
+	lock.Release()
+	locks, err = ListLocks(dir)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+}
+
+func TestTryLockTakesOverStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	stale, _ := json.Marshal(LockHolder{Name: "repo-main", PID: 1 << 30, Workflow: "crashed", AcquiredAt: time.Now()})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repo-main.json"), stale, 0644))
+
+	lock, holder, err := TryLock(dir, LockHolder{Name: "repo-main", Workflow: "deploy"})
+	require.NoError(t, err)
+	require.NotNil(t, lock, "a dead process's lock is taken over")
+	assert.Nil(t, holder)
+	lock.Release()
+}
+
+func TestAcquireLock(t *testing.T) {
+	dir := t.TempDir()
+	lockPollInterval = 10 * time.Millisecond
+	defer func() { lockPollInterval = time.Second }()
+	ctx := context.Background()
+
+	lock, err := AcquireLock(ctx, dir, workflow.Settings{}, LockHolder{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, lock, "no lock configured")
+
+	held, err := AcquireLock(ctx, dir, workflow.Settings{Lock: "repo-main"}, LockHolder{Workflow: "deploy"}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+
+	_, err = AcquireLock(ctx, dir, workflow.Settings{Lock: "repo-main", OnLocked: LockFail}, LockHolder{Workflow: "refactor"}, nil)
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked))
+	assert.Equal(t, "deploy", locked.Holder.Workflow)
+	assert.Equal(t, ErrorLocked, ClassOf(err))
+
+	_, err = AcquireLock(ctx, dir, workflow.Settings{Lock: "repo-main", LockTimeout: "50ms"}, LockHolder{Workflow: "refactor"}, nil)
+	assert.Equal(t, ErrorLocked, ClassOf(err))
+
+	// A waiting run gets the lock once it is released
+	waited := make(chan LockHolder, 1)
+	go func() {
+		holder := <-waited
+		assert.Equal(t, "deploy", holder.Workflow)
+		held.Release()
+	}()
+	lock, err = AcquireLock(ctx, dir, workflow.Settings{Lock: "repo-main"}, LockHolder{Workflow: "refactor"}, func(holder LockHolder) { waited <- holder })
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	defer lock.Release()
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = AcquireLock(cancelled, dir, workflow.Settings{Lock: "repo-main"}, LockHolder{Workflow: "review"}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunTrackerLock(t *testing.T) {
+	dir := t.TempDir()
+	tracker := NewRunTracker(dir, "", "deploy")
+	defer tracker.Close()
+
+	tracker.SetLock("repo-main", true)
+	runs, err := ListRuns(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, RunWaiting, runs[0].Status)
+	assert.Equal(t, "repo-main", runs[0].Lock)
+
+	tracker.SetLock("repo-main", false)
+	runs, err = ListRuns(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "running", runs[0].Status)
+}
@@ -22,14 +22,23 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/rizome-dev/opun/internal/telemetry"
 	"github.com/rizome-dev/opun/pkg/workflow"
 	"gopkg.in/yaml.v3"
 )
 
 // Manager manages workflows
 type Manager struct {
-	workflowDir string
+	workflowDir     string
+	requirementsEnv func() (RequirementsEnvironment, error)
+	promptResolver  PromptResolver
+	selection       ProviderSelection
+	promptPolicy    *PromptPolicy
+	outputEvents    bool
+	// queue limits how many of the manager's workflows run at once
+	queue *RunQueue
 }
 
 // NewManager creates a new workflow manager
@@ -49,6 +58,7 @@ func NewManager(workflowDir string) (*Manager, error) {
 
 	return &Manager{
 		workflowDir: workflowDir,
+		queue:       NewRunQueue(DefaultMaxConcurrentRuns),
 	}, nil
 }
 
@@ -78,8 +88,53 @@ func (m *Manager) ListWorkflows() ([]*workflow.Workflow, error) {
 	return workflows, nil
 }
 
+// SetRequirementsEnvironment sets the function used to check the MCP servers and
+// actions workflows declare under requires
+func (m *Manager) SetRequirementsEnvironment(env func() (RequirementsEnvironment, error)) {
+	m.requirementsEnv = env
+}
+
+// SetPromptResolver sets how promptgarden:// references in agent system
+// prompts are resolved
+func (m *Manager) SetPromptResolver(resolve PromptResolver) {
+	m.promptResolver = resolve
+}
+
+// SetProviderSelection sets how providers are chosen for agents that list
+// required capabilities
+func (m *Manager) SetProviderSelection(selection ProviderSelection) {
+	m.selection = selection
+}
+
+// SetPromptPolicy sets the policy prompts are checked against before they are sent
+func (m *Manager) SetPromptPolicy(policy *PromptPolicy) {
+	m.promptPolicy = policy
+}
+
+// SetOutputEvents makes runs report the providers' terminal output as
+// output_chunk events
+func (m *Manager) SetOutputEvents(enabled bool) {
+	m.outputEvents = enabled
+}
+
+// SetMaxConcurrent sets how many workflows run at once, no limit when n is
+// zero or less. Further runs wait in a queue.
+func (m *Manager) SetMaxConcurrent(n int) {
+	m.queue.SetMax(n)
+}
+
+// QueueStatus returns how many workflows are running and queued
+func (m *Manager) QueueStatus() QueueStatus {
+	return m.queue.Status()
+}
+
 // Execute runs a workflow by name
 func (m *Manager) Execute(ctx context.Context, name string, variables map[string]interface{}) (interface{}, error) {
+	return m.ExecuteWithProgress(ctx, name, variables, nil)
+}
+
+// ExecuteWithProgress runs a workflow by name, reporting progress events to onEvent
+func (m *Manager) ExecuteWithProgress(ctx context.Context, name string, variables map[string]interface{}, onEvent func(workflow.WorkflowEvent)) (interface{}, error) {
 	// Find workflow file
 	workflowPath := filepath.Join(m.workflowDir, name+".yaml")
 	if _, err := os.Stat(workflowPath); os.IsNotExist(err) {
@@ -96,8 +151,88 @@ func (m *Manager) Execute(ctx context.Context, name string, variables map[string
 		return nil, fmt.Errorf("failed to load workflow: %w", err)
 	}
 
+	if err := SelectProviders(wf, m.selection); err != nil {
+		return nil, err
+	}
+
+	// Fail fast if declared requirements aren't available
+	if HasRequirements(wf) && m.requirementsEnv != nil {
+		env, err := m.requirementsEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check workflow requirements: %w", err)
+		}
+		if missing := CheckRequirements(wf.Requires, env); !missing.Empty() {
+			return nil, missing
+		}
+	}
+
 	// Create executor
 	executor := NewExecutor()
+	executor.SetPromptResolver(m.promptResolver)
+	executor.SetPromptPolicy(m.promptPolicy)
+	executor.SetOutputEvents(m.outputEvents)
+	var handlers []EventHandler
+	var tracker *RunTracker
+	runID := ""
+	if runsDir, err := RunsDir(); err == nil {
+		// Publish progress so `opun status` can show this run from other terminals
+		tracker = NewRunTracker(runsDir, "", wf.Name)
+		defer tracker.Close()
+		runID = tracker.ID()
+		executor.SetRunID(runID)
+		handlers = append(handlers, tracker.HandleEvent)
+	}
+
+	// Wait for room among the manager's running workflows
+	queued := false
+	release, err := m.queue.Acquire(ctx, func(position int, status QueueStatus) {
+		queued = true
+		if tracker != nil {
+			tracker.SetQueued(position)
+		}
+		if onEvent != nil {
+			onEvent(workflow.WorkflowEvent{
+				Type:      workflow.EventWorkflowQueued,
+				Timestamp: time.Now(),
+				Message:   fmt.Sprintf("Workflow %s is queued at position %d (%d of %d running)", wf.Name, position, status.Running, status.MaxConcurrent),
+				Data: map[string]interface{}{
+					"position":       position,
+					"running":        status.Running,
+					"queued":         status.Queued,
+					"max_concurrent": status.MaxConcurrent,
+				},
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if queued && tracker != nil {
+		tracker.SetQueued(0)
+	}
+
+	// Hold the workflow's lock for the whole run
+	lock, err := LockRun(ctx, wf, runID, tracker, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	// Trace the run when an OTLP endpoint is set in the environment
+	if target := telemetry.TargetFromEnv(); target != "" {
+		tracer, err := NewRunTracer(target)
+		if err != nil {
+			return nil, err
+		}
+		tracer.SetRun(runID, wf.Name)
+		executor.SetTracer(tracer)
+		handlers = append(handlers, tracer.HandleEvent)
+		defer tracer.Close()
+	}
+
+	handlers = append(handlers, onEvent)
+	executor.SetEventHandler(CombineEventHandlers(handlers...))
 
 	// Convert variables to string map if needed
 	stringVars := make(map[string]interface{})
@@ -106,9 +241,11 @@ func (m *Manager) Execute(ctx context.Context, name string, variables map[string
 	}
 
 	// Execute workflow
-	result := executor.Execute(ctx, wf, stringVars)
+	if err := executor.Execute(ctx, wf, stringVars); err != nil {
+		return nil, err
+	}
 
-	return result, nil
+	return executor.GetState(), nil
 }
 
 // loadWorkflow loads a workflow from a file
@@ -0,0 +1,295 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// ManifestFile is the name of the run manifest written to the output directory
+const ManifestFile = "manifest.json"
+
+// Build information recorded in run manifests, set by main
+var (
+	OpunVersion = "dev"
+	OpunCommit  = ""
+)
+
+// secretVariablePattern matches variable names whose values are redacted in manifests
+var secretVariablePattern = regexp.MustCompile(`(?i)(secret|token|passw|api_?key|credential|auth|private)`)
+
+// RunManifest records everything a workflow run used, so it can be audited
+// and reproduced later
+type RunManifest struct {
+	RunID           string                 `json:"run_id,omitempty"`
+	OpunVersion     string                 `json:"opun_version"`
+	OpunCommit      string                 `json:"opun_commit,omitempty"`
+	Platform        string                 `json:"platform"`
+	Workflow        string                 `json:"workflow"`
+	WorkflowVersion string                 `json:"workflow_version,omitempty"`
+	WorkflowHash    string                 `json:"workflow_hash"`
+	Status          string                 `json:"status"`
+	StartedAt       time.Time              `json:"started_at"`
+	FinishedAt      *time.Time             `json:"finished_at,omitempty"`
+	Variables       map[string]interface{} `json:"variables"`
+	Providers       map[string]string      `json:"providers"`
+	Environment     *RunEnvironment        `json:"environment,omitempty"` // Machine and terminal the run happened in
+	Agents          []ManifestAgent        `json:"agents"`
+	Redactions      map[string]int         `json:"redactions,omitempty"` // Secrets scrubbed from outputs, by pattern
+	Storage         *StoredArtifacts       `json:"storage,omitempty"`    // Where settings.storage uploaded the outputs
+	Chaos           []ChaosEvent           `json:"chaos,omitempty"`      // Failures injected with --chaos
+	Error           string                 `json:"error,omitempty"`
+	ErrorClass      ErrorClass             `json:"error_class,omitempty"` // Kind of failure, also the exit code of opun run
+	RunInventory
+}
+
+// ManifestAgent records how an agent ran
+type ManifestAgent struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	Target   string `json:"target,omitempty"`
+	Status   string `json:"status"`
+	Output   string `json:"output,omitempty"`
+	// ErrorClass is the kind of failure of a failed agent
+	ErrorClass string `json:"error_class,omitempty"`
+	// Resources is what the provider used; CPU and memory are sampled on Linux only
+	Resources *ResourceUsage `json:"resources,omitempty"`
+	// Usage is the tokens and cost the provider reported, for headless steps
+	Usage *providers.Usage `json:"usage,omitempty"`
+}
+
+// Usage sums the usage its agents reported, nil when none did
+func (m *RunManifest) Usage() *providers.Usage {
+	var total *providers.Usage
+	for _, agent := range m.Agents {
+		if agent.Usage == nil {
+			continue
+		}
+		if total == nil {
+			total = &providers.Usage{}
+		}
+		total.Add(*agent.Usage)
+	}
+	return total
+}
+
+// ManifestItem is an installed prompt or action and its version
+type ManifestItem struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// RunInventory lists the installed prompts and actions a workflow uses
+type RunInventory struct {
+	Prompts []ManifestItem `json:"prompts,omitempty"`
+	Actions []ManifestItem `json:"actions,omitempty"`
+}
+
+// providerVersionLookup returns the version of an installed provider CLI
+type providerVersionLookup func(ctx context.Context, provider string) (string, error)
+
+// WorkflowHash returns a content hash of a workflow definition
+func WorkflowHash(wf *workflow.Workflow) string {
+	// JSON encoding sorts map keys, so equal workflows hash equally
+	data, err := json.Marshal(wf)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// redactVariables copies variables, hiding the values of secret-looking names
+func redactVariables(vars map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(vars))
+	for name, value := range vars {
+		if secretVariablePattern.MatchString(name) {
+			value = "[REDACTED]"
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// newRunManifest builds the manifest of a run from its workflow and state
+func newRunManifest(wf *workflow.Workflow, state *workflow.ExecutionState, outputs map[string]string, versions map[string]string, inventory RunInventory) *RunManifest {
+	m := &RunManifest{
+		OpunVersion:     OpunVersion,
+		OpunCommit:      OpunCommit,
+		Platform:        runtime.GOOS + "/" + runtime.GOARCH,
+		Workflow:        wf.Name,
+		WorkflowVersion: wf.Version,
+		WorkflowHash:    WorkflowHash(wf),
+		Status:          string(state.Status),
+		StartedAt:       state.StartTime,
+		FinishedAt:      state.EndTime,
+		Variables:       redactVariables(state.Variables),
+		Providers:       versions,
+		RunInventory:    inventory,
+	}
+
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		status := workflow.StatusPending
+		errorClass := ""
+		if agentState, ok := state.AgentStates[agent.ID]; ok {
+			status = agentState.Status
+			if agentState.Error != nil {
+				errorClass = agentState.Error.Class
+			}
+		}
+		m.Agents = append(m.Agents, ManifestAgent{
+			ID:         agent.ID,
+			Name:       agent.Name,
+			Provider:   agent.Provider,
+			Model:      agent.Model,
+			Target:     agentTarget(wf, agent),
+			Status:     string(status),
+			Output:     outputs[agent.ID],
+			ErrorClass: errorClass,
+		})
+	}
+	return m
+}
+
+// writeManifest writes a manifest to dir/manifest.json
+func writeManifest(dir string, m *RunManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, ManifestFile))
+}
+
+// SetRunInventory sets the installed prompts and actions recorded in the run manifest
+func (e *InteractiveExecutor) SetRunInventory(inventory RunInventory) {
+	e.inventory = inventory
+}
+
+// resolveProviderVersions looks up the version of every local provider once per run
+func (e *InteractiveExecutor) resolveProviderVersions() map[string]string {
+	if e.providerVersions != nil {
+		return e.providerVersions
+	}
+
+	lookup := e.versionLookup
+	if lookup == nil {
+		lookup = providers.NewAuthChecker().Version
+	}
+
+	e.providerVersions = make(map[string]string)
+	for i := range e.workflow.Agents {
+		agent := &e.workflow.Agents[i]
+		if !isProviderStep(agent) {
+			continue
+		}
+		provider := strings.ToLower(agent.Provider)
+		if _, done := e.providerVersions[provider]; done {
+			continue
+		}
+		if AgentRunsRemotely(e.workflow, agent) {
+			e.providerVersions[provider] = "remote"
+			continue
+		}
+		version, err := lookup(context.Background(), provider)
+		if err != nil || version == "" {
+			version = "unknown"
+		}
+		e.providerVersions[provider] = version
+	}
+	return e.providerVersions
+}
+
+// runManifest returns the manifest of the run as it stands
+func (e *InteractiveExecutor) runManifest() *RunManifest {
+	// Provider versions are only looked up for runs that keep their outputs
+	var versions map[string]string
+	if e.outputDir != "" {
+		versions = e.resolveProviderVersions()
+		if e.environment == nil {
+			e.environment = CaptureEnvironment()
+		}
+	}
+	e.mu.Lock()
+	m := newRunManifest(e.workflow, e.state, e.outputs, versions, e.inventory)
+	m.RunID = e.runID
+	m.Environment = e.environment
+	for i := range m.Agents {
+		m.Agents[i].Resources = e.resources[m.Agents[i].ID]
+	}
+	if e.runErr != nil {
+		m.Error = e.runErr.Error()
+		m.ErrorClass = ClassOf(e.runErr)
+	}
+	m.Storage = e.stored
+	m.Chaos = e.chaos.Events()
+	e.mu.Unlock()
+	if m.FinishedAt == nil && m.Status != string(workflow.StatusRunning) {
+		// Failed and aborted runs have no end time in the state
+		now := time.Now()
+		m.FinishedAt = &now
+	}
+	return m
+}
+
+// writeRunManifest records the run in the output directory and the run
+// history. It is written when the run starts and rewritten with the final
+// status when it ends.
+func (e *InteractiveExecutor) writeRunManifest() {
+	if e.state == nil {
+		return
+	}
+	m := e.runManifest()
+
+	if e.redactor != nil {
+		if report := e.redactor.Report(); report.Total > 0 {
+			m.Redactions = report.Patterns
+			if e.outputDir != "" {
+				if err := writeRedactionReport(e.outputDir, report); err != nil {
+					fmt.Printf("⚠️  Failed to write redaction report: %v\n", err)
+				}
+			}
+		}
+	}
+
+	e.recordRun(m)
+	if e.outputDir == "" {
+		return
+	}
+	if err := writeManifest(e.outputDir, m); err != nil {
+		fmt.Printf("⚠️  Failed to write run manifest: %v\n", err)
+	}
+}
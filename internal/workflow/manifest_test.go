@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func manifestWorkflow() *workflow.Workflow {
+	return &workflow.Workflow{
+		Name:    "review",
+		Version: "1.2.0",
+		Agents: []workflow.Agent{
+			{ID: "plan", Name: "Planner", Provider: "claude", Model: "opus", Output: "plan.md"},
+			{ID: "check", Name: "Checker", Provider: "gemini", Target: "ssh://dev@box"},
+		},
+	}
+}
+
+func TestWorkflowHash(t *testing.T) {
+	a, b := manifestWorkflow(), manifestWorkflow()
+	assert.Equal(t, WorkflowHash(a), WorkflowHash(b))
+	assert.Contains(t, WorkflowHash(a), "sha256:")
+
+	b.Agents[0].Prompt = "changed"
+	assert.NotEqual(t, WorkflowHash(a), WorkflowHash(b))
+}
+
+func TestRedactVariables(t *testing.T) {
+	vars := redactVariables(map[string]interface{}{
+		"repo":         "opun",
+		"github_token": "ghp_123",
+		"API_KEY":      "sk-123",
+		"db_password":  "hunter2",
+	})
+	assert.Equal(t, map[string]interface{}{
+		"repo":         "opun",
+		"github_token": "[REDACTED]",
+		"API_KEY":      "[REDACTED]",
+		"db_password":  "[REDACTED]",
+	}, vars)
+}
+
+func TestWriteRunManifest(t *testing.T) {
+	dir := t.TempDir()
+	wf := manifestWorkflow()
+
+	var lookups []string
+	e := NewInteractiveExecutor()
+	e.workflow = wf
+	e.outputDir = dir
+	e.outputs["plan"] = filepath.Join(dir, "plan.md")
+	e.versionLookup = func(ctx context.Context, provider string) (string, error) {
+		lookups = append(lookups, provider)
+		if provider == "claude" {
+			return "1.0.35 (Claude Code)", nil
+		}
+		return "", errors.New("not installed")
+	}
+	e.SetRunInventory(RunInventory{Actions: []ManifestItem{{Name: "lint", Version: "2.0.0"}}})
+	e.state = &workflow.ExecutionState{
+		Status:    workflow.StatusRunning,
+		StartTime: time.Now(),
+		Variables: map[string]interface{}{"topic": "auth", "secret_key": "abc"},
+		AgentStates: map[string]*workflow.AgentState{
+			"plan": {AgentID: "plan", Status: workflow.StatusCompleted},
+		},
+	}
+
+	e.writeRunManifest()
+	e.state.Status = workflow.StatusFailed
+	e.writeRunManifest()
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+
+	var m RunManifest
+	require.NoError(t, json.Unmarshal(data, &m))
+
+	assert.Equal(t, "review", m.Workflow)
+	assert.Equal(t, "1.2.0", m.WorkflowVersion)
+	assert.Equal(t, WorkflowHash(wf), m.WorkflowHash)
+	assert.Equal(t, "failed", m.Status)
+	assert.NotNil(t, m.FinishedAt)
+	assert.Equal(t, map[string]interface{}{"topic": "auth", "secret_key": "[REDACTED]"}, m.Variables)
+	assert.Equal(t, map[string]string{"claude": "1.0.35 (Claude Code)", "gemini": "remote"}, m.Providers)
+	assert.Equal(t, []ManifestItem{{Name: "lint", Version: "2.0.0"}}, m.Actions)
+
+	require.Len(t, m.Agents, 2)
+	assert.Equal(t, ManifestAgent{ID: "plan", Name: "Planner", Provider: "claude", Model: "opus", Status: "completed", Output: filepath.Join(dir, "plan.md")}, m.Agents[0])
+	assert.Equal(t, ManifestAgent{ID: "check", Name: "Checker", Provider: "gemini", Target: "ssh://dev@box", Status: "pending"}, m.Agents[1])
+
+	// Provider versions are looked up once per run
+	assert.Equal(t, []string{"claude"}, lookups)
+	assert.NoFileExists(t, filepath.Join(dir, ManifestFile+".tmp"))
+}
@@ -0,0 +1,682 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/config"
+	"github.com/rizome-dev/opun/internal/expr"
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// MatrixResultsFile is the aggregated results file of a matrix run
+const MatrixResultsFile = "matrix.json"
+
+// MatrixCell is one combination of matrix dimension values
+type MatrixCell map[string]interface{}
+
+// headlessRunner runs a prompt on a provider and returns its answer
+type headlessRunner func(ctx context.Context, provider, model, prompt string) (string, error)
+
+// headlessStreamer runs a prompt on a provider CLI with extra args for the
+// agent's model parameters, reporting progress as the answer streams in
+type headlessStreamer func(ctx context.Context, provider, model, prompt string, args []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error)
+
+// MatrixResult is the outcome of running a workflow for one matrix cell
+type MatrixResult struct {
+	Name     string            `json:"name"`
+	Cell     MatrixCell        `json:"cell"`
+	Status   string            `json:"status"`
+	Duration float64           `json:"duration_seconds"`
+	Outputs  map[string]string `json:"outputs"` // agent ID to output file
+	Final    string            `json:"final_output,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	// ErrorClass is the kind of failure, see ErrorClass
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+	// Chaos lists the failures injected with --chaos
+	Chaos []ChaosEvent `json:"chaos,omitempty"`
+	// Usage is the tokens and cost providers reported, by agent ID
+	Usage map[string]providers.Usage `json:"usage,omitempty"`
+	// Findings are what agents with findings on reported, by agent ID
+	Findings map[string][]Finding `json:"findings,omitempty"`
+	// Skipped lists the agents whose condition was false
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// AgentStatuses returns how far each of the workflow's steps got. Steps run
+// in order: everything up to the last output is done, and a failed run
+// stopped at the first step after it that wasn't skipped.
+func (r MatrixResult) AgentStatuses(wf *workflow.Workflow) map[string]workflow.ExecutionStatus {
+	lastDone := -1
+	for i := range wf.Agents {
+		if _, ok := r.Outputs[wf.Agents[i].ID]; ok {
+			lastDone = i
+		}
+	}
+	statuses := make(map[string]workflow.ExecutionStatus, len(wf.Agents))
+	// The step a failed run stopped at is still to be found
+	stopped := r.Status == string(workflow.StatusFailed)
+	for i := range wf.Agents {
+		id := wf.Agents[i].ID
+		status := workflow.StatusPending
+		switch {
+		case contains(r.Skipped, id):
+			status = workflow.StatusSkipped
+		case i <= lastDone || r.Status == string(workflow.StatusCompleted):
+			status = workflow.StatusCompleted
+		case stopped:
+			status = workflow.StatusFailed
+			stopped = false
+		}
+		statuses[id] = status
+	}
+	return statuses
+}
+
+// MatrixRunner runs a workflow headlessly for every cell of its matrix
+type MatrixRunner struct {
+	// OutputDir receives one directory per cell and the aggregated results
+	OutputDir string
+	// Parallel is how many cells run at once, default 1
+	Parallel int
+	// Policy is checked against every prompt; prompts needing confirmation are blocked
+	Policy *PromptPolicy
+	// Chaos injects failures into every provider call when set
+	Chaos *Chaos
+	// Progress, when set, is told what each agent does as its answer
+	// streams in, for providers that stream
+	Progress func(agentID string, progress providers.StreamProgress)
+	// History records headless runs in the run history
+	History bool
+	// RunID identifies a headless run in the history, generated when empty
+	RunID    string
+	run      headlessRunner
+	stream   headlessStreamer
+	redactor *Redactor
+	// promptResolver resolves promptgarden:// agent system prompts
+	promptResolver PromptResolver
+}
+
+// NewMatrixRunner creates a matrix runner writing results to outputDir
+func NewMatrixRunner(outputDir string, parallel int) *MatrixRunner {
+	return &MatrixRunner{
+		OutputDir: outputDir,
+		Parallel:  parallel,
+		stream:    streamHeadlessPrompt,
+	}
+}
+
+// SetRunner replaces how prompts are run, by default on the provider CLIs
+func (r *MatrixRunner) SetRunner(run func(ctx context.Context, provider, model, prompt string) (string, error)) {
+	r.run = run
+}
+
+// SetPromptResolver sets how promptgarden:// references in agent system
+// prompts are resolved
+func (r *MatrixRunner) SetPromptResolver(resolve PromptResolver) {
+	r.promptResolver = resolve
+}
+
+// Name describes a cell, e.g. "model=opus provider=claude"
+func (c MatrixCell) Name() string {
+	var parts []string
+	for _, key := range c.keys() {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, c[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// dirName returns a directory name for a cell, e.g. "model-opus_provider-claude"
+func (c MatrixCell) dirName() string {
+	var parts []string
+	for _, key := range c.keys() {
+		parts = append(parts, unsafePathChars.ReplaceAllString(fmt.Sprintf("%s-%v", key, c[key]), "-"))
+	}
+	return strings.Join(parts, "_")
+}
+
+func (c MatrixCell) keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matches reports whether the cell has every value of an exclude entry
+func (c MatrixCell) matches(entry map[string]interface{}) bool {
+	for key, value := range entry {
+		cellValue, ok := c[key]
+		if !ok || fmt.Sprint(cellValue) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMatrix rejects a temperature dimension: no provider CLI takes a
+// temperature, so every value would run the same
+func validateMatrix(m *workflow.Matrix) error {
+	if m == nil {
+		return nil
+	}
+	if _, ok := m.Dimensions["temperature"]; ok {
+		return fmt.Errorf("matrix dimension temperature is not supported: no provider CLI takes a temperature, so every combination would run alike")
+	}
+	return nil
+}
+
+// ExpandMatrix returns the combinations of a matrix, ordered by dimension name
+func ExpandMatrix(m *workflow.Matrix) ([]MatrixCell, error) {
+	if m == nil || len(m.Dimensions) == 0 {
+		return nil, fmt.Errorf("workflow has no matrix")
+	}
+	if err := validateMatrix(m); err != nil {
+		return nil, err
+	}
+
+	dims := make([]string, 0, len(m.Dimensions))
+	for name, values := range m.Dimensions {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("matrix dimension %s has no values", name)
+		}
+		dims = append(dims, name)
+	}
+	sort.Strings(dims)
+
+	cells := []MatrixCell{{}}
+	for _, dim := range dims {
+		var next []MatrixCell
+		for _, cell := range cells {
+			for _, value := range m.Dimensions[dim] {
+				combined := MatrixCell{dim: value}
+				for k, v := range cell {
+					combined[k] = v
+				}
+				next = append(next, combined)
+			}
+		}
+		cells = next
+	}
+
+	var kept []MatrixCell
+	for _, cell := range cells {
+		excluded := false
+		for _, entry := range m.Exclude {
+			if cell.matches(entry) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, cell)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("matrix excludes every combination")
+	}
+	return kept, nil
+}
+
+// applyMatrixCell returns a copy of the workflow and variables for one cell.
+// provider and model override every agent; other dimensions become
+// variables.
+func applyMatrixCell(wf *workflow.Workflow, cell MatrixCell, vars map[string]interface{}) (*workflow.Workflow, map[string]interface{}, error) {
+	cellWorkflow := *wf
+	cellWorkflow.Agents = append([]workflow.Agent(nil), wf.Agents...)
+
+	cellVars := make(map[string]interface{}, len(vars)+len(cell))
+	for k, v := range vars {
+		cellVars[k] = v
+	}
+
+	for key, value := range cell {
+		switch key {
+		case "provider", "model":
+			for i := range cellWorkflow.Agents {
+				agent := &cellWorkflow.Agents[i]
+				if !isProviderStep(agent) {
+					continue
+				}
+				switch key {
+				case "provider":
+					agent.Provider = fmt.Sprint(value)
+				case "model":
+					agent.Model = fmt.Sprint(value)
+				}
+			}
+		default:
+			cellVars[key] = value
+		}
+	}
+	return &cellWorkflow, cellVars, nil
+}
+
+// Run runs every cell of the workflow's matrix and writes the aggregated
+// results. A failing cell doesn't stop the others.
+func (r *MatrixRunner) Run(ctx context.Context, wf *workflow.Workflow, vars map[string]interface{}) ([]MatrixResult, error) {
+	cells, err := ExpandMatrix(wf.Matrix)
+	if err != nil {
+		return nil, err
+	}
+	if r.redactor, err = NewRedactor(wf.Settings.Redact); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	parallel := r.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > 1 {
+		if err := features.Require(features.ParallelWorkflows, "parallel matrix runs"); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Printf("🧮 Running %s for %d matrix combinations\n", wf.Name, len(cells))
+
+	results := make([]MatrixResult, len(cells))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, cell := range cells {
+		wg.Add(1)
+		go func(i int, cell MatrixCell) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = r.runCell(ctx, wf, vars, cell)
+			icon := "✅"
+			if results[i].Status != string(workflow.StatusCompleted) {
+				icon = "❌"
+			}
+			fmt.Printf("%s [%d/%d] %s (%.1fs)\n", icon, i+1, len(cells), results[i].Name, results[i].Duration)
+		}(i, cell)
+	}
+	wg.Wait()
+
+	if err := writeMatrixResults(r.OutputDir, results); err != nil {
+		return results, err
+	}
+	return results, ctx.Err()
+}
+
+// RunHeadless runs a workflow once without a terminal, writing its outputs
+// to the runner's output directory. Input steps need their variable passed in.
+func (r *MatrixRunner) RunHeadless(ctx context.Context, wf *workflow.Workflow, vars map[string]interface{}) (MatrixResult, error) {
+	var err error
+	if r.redactor, err = NewRedactor(wf.Settings.Redact); err != nil {
+		return MatrixResult{}, err
+	}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return MatrixResult{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if r.RunID == "" {
+		r.RunID = NewRunID()
+	}
+	started := time.Now()
+	result := r.runCell(ctx, wf, vars, MatrixCell{})
+	result.Name = wf.Name
+	if r.History {
+		r.recordHeadlessRun(wf, vars, started, result)
+	}
+	if result.Error != "" {
+		return result, Classify(result.ErrorClass, errors.New(result.Error))
+	}
+	return result, ctx.Err()
+}
+
+// runCell runs the workflow's steps headlessly for one cell
+func (r *MatrixRunner) runCell(ctx context.Context, wf *workflow.Workflow, vars map[string]interface{}, cell MatrixCell) MatrixResult {
+	start := time.Now()
+	result := MatrixResult{
+		Name:    cell.Name(),
+		Cell:    cell,
+		Status:  string(workflow.StatusCompleted),
+		Outputs: make(map[string]string),
+	}
+
+	fail := func(err error) MatrixResult {
+		result.Status = string(workflow.StatusFailed)
+		result.Error = err.Error()
+		result.ErrorClass = ClassOf(err)
+		result.Duration = time.Since(start).Seconds()
+		return result
+	}
+
+	cellWorkflow, cellVars, err := applyMatrixCell(wf, cell, vars)
+	if err != nil {
+		return fail(err)
+	}
+	if err := ValidateVariables(cellWorkflow, cellVars); err != nil {
+		return fail(err)
+	}
+	if err := RequireFeatures(cellWorkflow); err != nil {
+		return fail(err)
+	}
+
+	dir := filepath.Join(r.OutputDir, cell.dirName())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fail(err)
+	}
+
+	outputs := make(map[string]string)
+	expand := func(s string) string { return substituteVariables(s, cellVars) }
+	src := inputSource{
+		vars: cellVars,
+		output: func(id string) (string, bool) {
+			path, ok := result.Outputs[id]
+			return path, ok
+		},
+		artifacts: func(id string) ([]string, bool) {
+			path, ok := result.Outputs[id]
+			if !ok {
+				return nil, false
+			}
+			return producedFiles(workflowAgent(cellWorkflow, id), path, expand), true
+		},
+	}
+	statuses := make(map[string]workflow.ExecutionStatus)
+	for i := range cellWorkflow.Agents {
+		agent := &cellWorkflow.Agents[i]
+
+		// Skip the agent when its condition is false
+		if agent.Condition != "" {
+			run, err := expr.EvalBool(agent.Condition, buildConditionEnv(cellWorkflow, cellVars, statuses, result.Outputs, r.RunID))
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: condition %q: %w", agent.ID, agent.Condition, err))
+			}
+			if !run {
+				name := agent.Name
+				if name == "" {
+					name = agent.ID
+				}
+				fmt.Printf("⏭️  Skipping %s: condition %q is false\n", name, agent.Condition)
+				statuses[agent.ID] = workflow.StatusSkipped
+				result.Skipped = append(result.Skipped, agent.ID)
+				continue
+			}
+		}
+		statuses[agent.ID] = workflow.StatusRunning
+
+		switch {
+		case isWaitStep(agent):
+			plan, err := parseWaitStep(agent)
+			if err == nil {
+				err = runWaitPlan(ctx, plan, func(s string) string { return substituteVariables(s, cellVars) })
+			}
+			if err != nil {
+				return fail(fmt.Errorf("wait step %s: %w", agent.ID, err))
+			}
+
+		case isInputStep(agent):
+			// Nobody is there to answer, the value must be passed in
+			if _, ok := cellVars[agent.Variable]; !ok {
+				return fail(fmt.Errorf("input step %s: pass --var %s=... for headless runs", agent.ID, agent.Variable))
+			}
+
+		case isIssueStep(agent):
+			issue, err := renderIssue(agent.Issue, src)
+			if err != nil {
+				return fail(fmt.Errorf("issue step %s: %w", agent.ID, err))
+			}
+			redactIssue(r.redactor, &issue)
+			issueResult, err := fileIssue(ctx, wf.Settings.Issues, issue)
+			if err != nil {
+				if agent.Settings.ContinueOnError {
+					fmt.Printf("⚠️  issue step %s: %v\n", agent.ID, err)
+					statuses[agent.ID] = workflow.StatusFailed
+					continue
+				}
+				return fail(fmt.Errorf("issue step %s: %w", agent.ID, err))
+			}
+			printIssueResult(issueResult)
+
+			name := agent.ID + ".json"
+			if agent.Output != "" {
+				name = agentOutputName(cellWorkflow, agent, OutputPathVars{Workflow: wf.Name, Time: start}, cellVars)
+			}
+			path, err := cellOutputPath(dir, name)
+			if err != nil {
+				return fail(fmt.Errorf("issue step %s: %w", agent.ID, err))
+			}
+			output, err := writeIssueResult(path, issueResult)
+			if err != nil {
+				return fail(err)
+			}
+			outputs[agent.ID] = output
+			result.Outputs[agent.ID] = path
+
+		default:
+			// Give the agent its role; providers without a system prompt flag,
+			// and runners set with SetRunner, get it ahead of the task
+			systemPrompt, err := resolveSystemPrompt(agent, cellVars, r.promptResolver)
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+
+			prompt := substituteVariables(agent.Prompt, cellVars)
+			for id, output := range outputs {
+				prompt = strings.ReplaceAll(prompt, fmt.Sprintf("{{%s.output}}", id), output)
+				prompt = strings.ReplaceAll(prompt, fmt.Sprintf("{{%s.output_full}}", id), output)
+			}
+			inputs, err := resolveInputs(agent, src)
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+			prompt = renderInputReferences(prompt, inputs)
+			prompt, err = transformPrompt(ctx, agent, prompt, scriptVars(agent, cellVars, result.Outputs))
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+
+			// Apply the agent's model parameters and system prompt, and fit
+			// the prompt to the context window, as interactive runs do
+			prepared, err := prepareAgentPrompt(agent, systemPrompt, prompt, cellWorkflow.Settings.PromptGuard, r.run == nil)
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+			prompt = prepared.Prompt
+
+			if workDir, err := os.Getwd(); err == nil {
+				if _, err := checkFileRefs(wf.Settings, agent.ID, prompt, workDir); err != nil {
+					return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+				}
+			}
+
+			if r.Policy != nil {
+				decision, err := r.Policy.Check(ctx, prepared.PolicyText, []string{"OPUN_WORKFLOW=" + wf.Name, "OPUN_AGENT_ID=" + agent.ID, "OPUN_PROVIDER=" + agent.Provider})
+				if err == nil && decision.Action != PolicyAllow {
+					err = Classify(ErrorGateFailed, fmt.Errorf("prompt blocked by policy (%s)", strings.Join(decision.Reasons, ", ")))
+				}
+				if err != nil {
+					return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+				}
+			}
+
+			var pull []string
+			for _, artifact := range agent.Produces {
+				pull = append(pull, expand(artifact.File))
+			}
+			run := r.agentRunner(cellWorkflow, agent, prepared.Args, pull, &result)
+			output, err := runWithArtifacts(agent, prompt, expand, func(prompt string) (string, error) {
+				if r.Chaos != nil {
+					output, events, err := r.Chaos.run(ctx, run, agent.ID, agent.Provider, agent.Model, prompt)
+					result.Chaos = append(result.Chaos, events...)
+					return output, err
+				}
+				return run(ctx, agent.Provider, agent.Model, prompt)
+			})
+			if err != nil {
+				if agent.Settings.ContinueOnError {
+					statuses[agent.ID] = workflow.StatusFailed
+					continue
+				}
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+			output, err = postProcessOutput(ctx, agent, output, scriptVars(agent, cellVars, result.Outputs))
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+			if r.redactor != nil {
+				output, _ = r.redactor.Redact(output)
+			}
+			outputs[agent.ID] = output
+
+			name := agent.ID + ".md"
+			if agent.Output != "" {
+				name = agentOutputName(cellWorkflow, agent, OutputPathVars{Workflow: wf.Name, Time: start}, cellVars)
+			}
+			path, err := cellOutputPath(dir, name)
+			if err != nil {
+				return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fail(err)
+			}
+			if err := os.WriteFile(path, []byte(output+"\n"), 0644); err != nil {
+				return fail(err)
+			}
+			result.Outputs[agent.ID] = path
+			result.Final = path
+
+			if findingsEnabled(agent) {
+				findings, err := writeFindings(agent, output, dir)
+				if err != nil {
+					return fail(fmt.Errorf("agent %s: %w", agent.ID, err))
+				}
+				if result.Findings == nil {
+					result.Findings = make(map[string][]Finding)
+				}
+				result.Findings[agent.ID] = findings
+			}
+		}
+		statuses[agent.ID] = workflow.StatusCompleted
+	}
+
+	result.Duration = time.Since(start).Seconds()
+	return result
+}
+
+// cellOutputPath returns where an agent's output goes in a cell directory.
+// Variables are substituted into output names only now, so names that would
+// leave the directory are refused here.
+func cellOutputPath(dir, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("output %q must be a path inside the output directory", name)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// agentRunner returns how an agent's prompts run. Unless SetRunner replaced
+// them, they run on the provider CLIs with args, or as Kubernetes Jobs for
+// k8s targets that copy the files in pull back: progress is reported as the
+// answer streams in and the usage providers report is added to the result.
+func (r *MatrixRunner) agentRunner(wf *workflow.Workflow, agent *workflow.Agent, args, pull []string, result *MatrixResult) headlessRunner {
+	if r.run != nil {
+		return r.run
+	}
+	agentID := agent.ID
+	stream := r.stream
+	if IsKubernetesTarget(agentTarget(wf, agent)) {
+		stream = func(ctx context.Context, provider, model, prompt string, args []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error) {
+			// Every attempt gets a Job of its own
+			job, err := newKubernetesJob(wf, agent)
+			if err != nil {
+				return providers.HeadlessResult{}, err
+			}
+			return job.run(ctx, provider, model, prompt, args, pull)
+		}
+	} else {
+		// Claude runs on this machine get Opun's hooks
+		args = config.WithClaudeHooks(agent.Provider, args)
+	}
+	return func(ctx context.Context, provider, model, prompt string) (string, error) {
+		var onProgress func(providers.StreamProgress)
+		if r.Progress != nil {
+			onProgress = func(p providers.StreamProgress) { r.Progress(agentID, p) }
+		}
+		answer, err := stream(ctx, provider, model, prompt, args, onProgress)
+		if answer.Usage != nil {
+			if result.Usage == nil {
+				result.Usage = make(map[string]providers.Usage)
+			}
+			// Retries of the same agent add up
+			usage := result.Usage[agentID]
+			usage.Add(*answer.Usage)
+			result.Usage[agentID] = usage
+		}
+		return answer.Output, err
+	}
+}
+
+// writeMatrixResults writes the results of all cells to dir/matrix.json and a
+// side-by-side comparison to dir/matrix.md
+func writeMatrixResults(dir string, results []MatrixResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, MatrixResultsFile), data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "matrix.md"), []byte(renderMatrixMarkdown(results)), 0644)
+}
+
+// renderMatrixMarkdown renders a summary table followed by each cell's final output
+func renderMatrixMarkdown(results []MatrixResult) string {
+	var sb strings.Builder
+	sb.WriteString("# Matrix results\n\n")
+	sb.WriteString("| Combination | Status | Duration |\n|---|---|---|\n")
+	for _, result := range results {
+		fmt.Fprintf(&sb, "| %s | %s | %.1fs |\n", result.Name, result.Status, result.Duration)
+	}
+
+	for _, result := range results {
+		fmt.Fprintf(&sb, "\n## %s\n\n", result.Name)
+		if result.Error != "" {
+			fmt.Fprintf(&sb, "Error: %s\n", result.Error)
+			continue
+		}
+		if result.Final != "" {
+			if content, err := os.ReadFile(result.Final); err == nil {
+				sb.WriteString(strings.TrimSpace(string(content)) + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
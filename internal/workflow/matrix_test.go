@@ -0,0 +1,353 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/features"
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatrix(t *testing.T) {
+	wf, err := NewParser(t.TempDir()).Parse([]byte(`
+name: sweep
+matrix:
+  provider: [claude, gemini]
+  variant: [concise, detailed]
+  exclude:
+    - provider: gemini
+      variant: detailed
+agents:
+  - id: answer
+    provider: claude
+    prompt: Explain closures, {{variant}}
+`))
+	require.NoError(t, err)
+	require.NotNil(t, wf.Matrix)
+	assert.Equal(t, []interface{}{"claude", "gemini"}, wf.Matrix.Dimensions["provider"])
+	assert.Equal(t, []interface{}{"concise", "detailed"}, wf.Matrix.Dimensions["variant"])
+	assert.Len(t, wf.Matrix.Exclude, 1)
+	assert.NotContains(t, wf.Matrix.Dimensions, "exclude")
+}
+
+func TestExpandMatrix(t *testing.T) {
+	cells, err := ExpandMatrix(&workflow.Matrix{
+		Dimensions: map[string][]interface{}{
+			"provider": {"claude", "gemini"},
+			"model":    {"fast", "smart"},
+		},
+		Exclude: []map[string]interface{}{{"provider": "gemini", "model": "smart"}},
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, cell := range cells {
+		names = append(names, cell.Name())
+	}
+	assert.Equal(t, []string{
+		"model=fast provider=claude",
+		"model=fast provider=gemini",
+		"model=smart provider=claude",
+	}, names)
+	assert.Equal(t, "model-fast_provider-claude", cells[0].dirName())
+
+	_, err = ExpandMatrix(nil)
+	assert.Error(t, err)
+	_, err = ExpandMatrix(&workflow.Matrix{Dimensions: map[string][]interface{}{"model": {}}})
+	assert.Error(t, err)
+	_, err = ExpandMatrix(&workflow.Matrix{
+		Dimensions: map[string][]interface{}{"model": {"a"}},
+		Exclude:    []map[string]interface{}{{"model": "a"}},
+	})
+	assert.ErrorContains(t, err, "excludes every combination")
+
+	// Temperatures can't be swept, no provider CLI takes one
+	_, err = ExpandMatrix(&workflow.Matrix{Dimensions: map[string][]interface{}{"temperature": {0.2, 0.8}}})
+	assert.ErrorContains(t, err, "temperature is not supported")
+}
+
+func TestApplyMatrixCell(t *testing.T) {
+	wf := &workflow.Workflow{Agents: []workflow.Agent{
+		{ID: "a", Provider: "claude", Model: "sonnet"},
+		{ID: "wait", Type: workflow.StepTypeWait, Duration: "1s"},
+	}}
+
+	cellWorkflow, vars, err := applyMatrixCell(wf, MatrixCell{"provider": "gemini", "variant": "short"}, map[string]interface{}{"topic": "go"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gemini", cellWorkflow.Agents[0].Provider)
+	assert.Equal(t, "sonnet", cellWorkflow.Agents[0].Model)
+	assert.Empty(t, cellWorkflow.Agents[1].Provider)
+	assert.Equal(t, map[string]interface{}{"topic": "go", "variant": "short"}, vars)
+
+	// The original workflow is untouched
+	assert.Equal(t, "claude", wf.Agents[0].Provider)
+}
+
+func TestMatrixRunner(t *testing.T) {
+	dir := t.TempDir()
+	wf := &workflow.Workflow{
+		Name: "sweep",
+		Matrix: &workflow.Matrix{Dimensions: map[string][]interface{}{
+			"provider": {"claude", "gemini"},
+			"variant":  {"short"},
+		}},
+		Agents: []workflow.Agent{
+			{ID: "draft", Provider: "claude", Prompt: "Draft a {{variant}} intro", Output: "draft.md"},
+			{ID: "review", Provider: "claude", Prompt: "Review: {{draft.output}}"},
+		},
+	}
+
+	// Running combinations at once is experimental
+	t.Setenv(features.EnvVar, "")
+	_, err := NewMatrixRunner(dir, 2).Run(context.Background(), wf, nil)
+	assert.ErrorContains(t, err, "enable parallel-workflows")
+	t.Setenv(features.EnvVar, features.ParallelWorkflows)
+
+	runner := NewMatrixRunner(dir, 2)
+	runner.run = func(ctx context.Context, provider, model, prompt string) (string, error) {
+		if provider == "gemini" && strings.HasPrefix(prompt, "Review") {
+			return "", errors.New("quota exceeded")
+		}
+		return provider + ": " + prompt, nil
+	}
+
+	results, err := runner.Run(context.Background(), wf, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	claude := results[0]
+	assert.Equal(t, "provider=claude variant=short", claude.Name)
+	assert.Equal(t, "completed", claude.Status)
+	assert.Equal(t, filepath.Join(dir, "provider-claude_variant-short", "review.md"), claude.Final)
+
+	review, err := os.ReadFile(claude.Final)
+	require.NoError(t, err)
+	assert.Equal(t, "claude: Review: claude: Draft a short intro\n", string(review))
+
+	gemini := results[1]
+	assert.Equal(t, "failed", gemini.Status)
+	assert.Contains(t, gemini.Error, "quota exceeded")
+	assert.Contains(t, gemini.Outputs, "draft")
+
+	data, err := os.ReadFile(filepath.Join(dir, MatrixResultsFile))
+	require.NoError(t, err)
+	var saved []MatrixResult
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Len(t, saved, 2)
+
+	summary, err := os.ReadFile(filepath.Join(dir, "matrix.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "| provider=gemini variant=short | failed |")
+	assert.Contains(t, string(summary), "claude: Review: claude: Draft a short intro")
+}
+
+func TestMatrixRunnerStreamUsage(t *testing.T) {
+	wf := &workflow.Workflow{
+		Name: "stream",
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "claude", Prompt: "Plan it"},
+			{ID: "build", Provider: "claude", Prompt: "Build {{plan.output}}"},
+		},
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	var progress []string
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.History = true
+	runner.Progress = func(agentID string, p providers.StreamProgress) {
+		progress = append(progress, agentID+":"+p.Kind)
+	}
+	runner.stream = func(ctx context.Context, provider, model, prompt string, args []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error) {
+		onProgress(providers.StreamProgress{Kind: providers.ProgressTool, Tool: "Read"})
+		return providers.HeadlessResult{
+			Output: "done: " + prompt,
+			Usage:  &providers.Usage{InputTokens: 10, OutputTokens: 2, CostUSD: 0.01},
+		}, nil
+	}
+
+	result, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, []string{"plan:tool", "build:tool"}, progress)
+	assert.Equal(t, map[string]providers.Usage{
+		"plan":  {InputTokens: 10, OutputTokens: 2, CostUSD: 0.01},
+		"build": {InputTokens: 10, OutputTokens: 2, CostUSD: 0.01},
+	}, result.Usage)
+
+	final, err := os.ReadFile(result.Final)
+	require.NoError(t, err)
+	assert.Equal(t, "done: Build done: Plan it\n", string(final))
+
+	// The run is kept in the history with what it cost
+	dir, err := HistoryDir()
+	require.NoError(t, err)
+	records, err := ListRunRecords(dir)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "stream", records[0].Workflow)
+	assert.Equal(t, "completed", records[0].Agents[1].Status)
+	assert.Equal(t, &providers.Usage{InputTokens: 20, OutputTokens: 4, CostUSD: 0.02}, records[0].Usage())
+}
+
+func TestMatrixRunnerInputStep(t *testing.T) {
+	wf := &workflow.Workflow{
+		Matrix: &workflow.Matrix{Dimensions: map[string][]interface{}{"model": {"a"}}},
+		Agents: []workflow.Agent{
+			{ID: "ask", Type: workflow.StepTypeInput, Prompt: "Error?", Variable: "error"},
+			{ID: "fix", Provider: "claude", Prompt: "Fix {{error}}"},
+		},
+	}
+
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.run = func(ctx context.Context, provider, model, prompt string) (string, error) {
+		return prompt, nil
+	}
+
+	results, err := runner.Run(context.Background(), wf, nil)
+	require.NoError(t, err)
+	assert.Contains(t, results[0].Error, "--var error=")
+
+	results, err = runner.Run(context.Background(), wf, map[string]interface{}{"error": "nil map"})
+	require.NoError(t, err)
+	assert.Empty(t, results[0].Error)
+}
+
+func TestMatrixRunnerConditions(t *testing.T) {
+	dir := t.TempDir()
+	wf := &workflow.Workflow{
+		Name: "conditional",
+		Agents: []workflow.Agent{
+			{ID: "first", Provider: "claude", Prompt: "Analyze"},
+			{ID: "second", Provider: "claude", Prompt: "Fix", Condition: `first.output contains "broken"`},
+			{ID: "third", Provider: "claude", Prompt: "Summarize", Condition: `second.skipped && vars.mode == "fast"`},
+		},
+	}
+
+	var prompts []string
+	runner := NewMatrixRunner(dir, 1)
+	runner.run = func(ctx context.Context, provider, model, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "all good", nil
+	}
+
+	result, err := runner.RunHeadless(context.Background(), wf, map[string]interface{}{"mode": "fast"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Analyze", "Summarize"}, prompts)
+	assert.Equal(t, []string{"second"}, result.Skipped)
+	assert.NotContains(t, result.Outputs, "second")
+	assert.NoFileExists(t, filepath.Join(dir, "second.md"))
+
+	statuses := result.AgentStatuses(wf)
+	assert.Equal(t, workflow.StatusCompleted, statuses["first"])
+	assert.Equal(t, workflow.StatusSkipped, statuses["second"])
+	assert.Equal(t, workflow.StatusCompleted, statuses["third"])
+
+	// A condition that can't be evaluated fails the run
+	wf.Agents[1].Condition = `first.output > 3`
+	_, err = runner.RunHeadless(context.Background(), wf, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "condition")
+}
+
+func TestMatrixRunnerModelParams(t *testing.T) {
+	wf := &workflow.Workflow{
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "claude", Prompt: "Plan it", Settings: workflow.AgentSettings{MaxTokens: 8000}},
+			{ID: "build", Provider: "gemini", Prompt: "Build it"},
+		},
+	}
+
+	calls := make(map[string][]string)
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.stream = func(ctx context.Context, provider, model, prompt string, args []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error) {
+		calls[provider] = args
+		return providers.HeadlessResult{Output: "ok"}, nil
+	}
+
+	_, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--settings", `{"env":{"CLAUDE_CODE_MAX_OUTPUT_TOKENS":"8000"}}`}, calls["claude"])
+	assert.Empty(t, calls["gemini"])
+}
+
+func TestMatrixRunnerSystemPrompt(t *testing.T) {
+	wf := &workflow.Workflow{
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "claude", Prompt: "Plan it", SystemPrompt: "You review {{lang}} code"},
+			{ID: "build", Provider: "gemini", Prompt: "Build it", SystemPrompt: "promptgarden://builder"},
+		},
+	}
+
+	args := make(map[string][]string)
+	prompts := make(map[string]string)
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.SetPromptResolver(func(name string) (string, error) { return "You build " + name, nil })
+	runner.stream = func(ctx context.Context, provider, model, prompt string, a []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error) {
+		args[provider] = a
+		prompts[provider] = prompt
+		return providers.HeadlessResult{Output: "ok"}, nil
+	}
+
+	_, err := runner.RunHeadless(context.Background(), wf, map[string]interface{}{"lang": "Go"})
+	require.NoError(t, err)
+
+	// Claude takes it as a flag, Gemini gets it ahead of the task
+	assert.Equal(t, []string{"--append-system-prompt", "You review Go code"}, args["claude"])
+	assert.NotContains(t, prompts["claude"], "You review")
+	assert.Empty(t, args["gemini"])
+	assert.Contains(t, prompts["gemini"], "You build builder")
+	assert.Contains(t, prompts["gemini"], "Build it")
+}
+
+func TestMatrixRunnerFormatProfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	providers.SetFormatProfile("mock", providers.FormatProfile{HeadingOffset: 1})
+	defer providers.SetFormatProfile("mock", providers.FormatProfile{})
+
+	wf := &workflow.Workflow{
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "mock", Prompt: "# Plan\nPlan it", Output: "plan.md"},
+		},
+	}
+
+	// The headless provider run applies the profile, and only it
+	result, err := NewMatrixRunner(t.TempDir(), 1).RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+	data, err := os.ReadFile(result.Outputs["plan"])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "## Plan")
+	assert.NotContains(t, string(data), "### Plan")
+}
+
+func TestMatrixRunnerPromptGuard(t *testing.T) {
+	wf := &workflow.Workflow{
+		Settings: workflow.Settings{PromptGuard: &workflow.PromptGuard{Mode: PromptGuardTrim, ContextWindow: 40, Reserve: 10}},
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "gemini", Prompt: "Plan it {{#context priority=low}}" + strings.Repeat("x", 400) + "{{/context}}"},
+		},
+	}
+
+	var got string
+	runner := NewMatrixRunner(t.TempDir(), 1)
+	runner.stream = func(ctx context.Context, provider, model, prompt string, args []string, onProgress func(providers.StreamProgress)) (providers.HeadlessResult, error) {
+		got = prompt
+		return providers.HeadlessResult{Output: "ok"}, nil
+	}
+
+	_, err := runner.RunHeadless(context.Background(), wf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Plan it ", got)
+
+	wf.Settings.PromptGuard = &workflow.PromptGuard{Mode: PromptGuardError, ContextWindow: 40, Reserve: 10}
+	_, err = runner.RunHeadless(context.Background(), wf, nil)
+	assert.Error(t, err)
+}
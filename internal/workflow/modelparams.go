@@ -0,0 +1,124 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Normalized model parameters an agent can set under settings
+const (
+	paramTemperature = "temperature"
+	paramMaxTokens   = "max_tokens"
+	paramReasoning   = "reasoning"
+)
+
+// reasoningBudgets maps reasoning levels to Claude thinking token budgets
+var reasoningBudgets = map[string]int{
+	"low":    4000,
+	"medium": 10000,
+	"high":   31999,
+}
+
+// providerModelParams lists the model parameters each provider CLI can honor.
+// Neither the Gemini CLI nor its Qwen Code fork has flags or settings for
+// them, so every parameter is ignored there with a warning.
+var providerModelParams = map[string][]string{
+	"claude": {paramMaxTokens, paramReasoning},
+	"gemini": {},
+	"qwen":   {},
+	"mock":   {paramTemperature, paramMaxTokens, paramReasoning},
+}
+
+// modelParams returns the normalized model parameters an agent sets
+func modelParams(settings workflow.AgentSettings) []string {
+	var params []string
+	if settings.Temperature != 0 {
+		params = append(params, paramTemperature)
+	}
+	if settings.MaxTokens != 0 {
+		params = append(params, paramMaxTokens)
+	}
+	if settings.Reasoning != "" {
+		params = append(params, paramReasoning)
+	}
+	return params
+}
+
+// validateModelParams checks an agent's model parameters are in range
+func validateModelParams(settings workflow.AgentSettings) error {
+	if settings.Temperature < 0 || settings.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", settings.Temperature)
+	}
+	if settings.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative, got %d", settings.MaxTokens)
+	}
+	if settings.Reasoning != "" {
+		if _, ok := reasoningBudgets[settings.Reasoning]; !ok {
+			return fmt.Errorf("reasoning must be one of low, medium or high, got %q", settings.Reasoning)
+		}
+	}
+	return nil
+}
+
+// unsupportedModelParams returns the model parameters an agent sets that its
+// provider cannot honor. These are ignored at launch rather than failing the
+// workflow, so the same workflow can be pointed at another provider.
+func unsupportedModelParams(agent *workflow.Agent) []string {
+	supported, ok := providerModelParams[strings.ToLower(agent.Provider)]
+	if !ok {
+		return nil
+	}
+	var unsupported []string
+	for _, param := range modelParams(agent.Settings) {
+		if !contains(supported, param) {
+			unsupported = append(unsupported, param)
+		}
+	}
+	return unsupported
+}
+
+// modelParamArgs returns the provider flags that apply an agent's model
+// parameters at launch
+func modelParamArgs(agent *workflow.Agent) []string {
+	settings := agent.Settings
+	switch strings.ToLower(agent.Provider) {
+	case "claude":
+		// Claude Code has no flags for these; pass them as settings env instead
+		env := make(map[string]string)
+		if settings.MaxTokens > 0 {
+			env["CLAUDE_CODE_MAX_OUTPUT_TOKENS"] = strconv.Itoa(settings.MaxTokens)
+		}
+		if budget, ok := reasoningBudgets[settings.Reasoning]; ok {
+			env["MAX_THINKING_TOKENS"] = strconv.Itoa(budget)
+		}
+		if len(env) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(map[string]interface{}{"env": env})
+		if err != nil {
+			return nil
+		}
+		return []string{"--settings", string(data)}
+	}
+	return nil
+}
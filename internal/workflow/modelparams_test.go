@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModelParams(t *testing.T) {
+	assert.NoError(t, validateModelParams(workflow.AgentSettings{Temperature: 0.2, MaxTokens: 8000, Reasoning: "high"}))
+	assert.NoError(t, validateModelParams(workflow.AgentSettings{}), "nothing set")
+
+	assert.Error(t, validateModelParams(workflow.AgentSettings{Temperature: 3}))
+	assert.Error(t, validateModelParams(workflow.AgentSettings{MaxTokens: -1}))
+	assert.EqualError(t, validateModelParams(workflow.AgentSettings{Reasoning: "extreme"}),
+		`reasoning must be one of low, medium or high, got "extreme"`)
+}
+
+func TestUnsupportedModelParams(t *testing.T) {
+	assert.Empty(t, unsupportedModelParams(&workflow.Agent{Provider: "claude", Settings: workflow.AgentSettings{MaxTokens: 8000, Reasoning: "high"}}))
+	assert.Equal(t, []string{"temperature"}, unsupportedModelParams(&workflow.Agent{Provider: "gemini", Settings: workflow.AgentSettings{Temperature: 0.2}}))
+	assert.Equal(t, []string{"temperature"}, unsupportedModelParams(&workflow.Agent{Provider: "Claude", Settings: workflow.AgentSettings{Temperature: 0.5}}))
+	assert.Equal(t, []string{"max_tokens", "reasoning"},
+		unsupportedModelParams(&workflow.Agent{Provider: "gemini", Settings: workflow.AgentSettings{MaxTokens: 100, Reasoning: "low"}}))
+	assert.Equal(t, []string{"temperature", "max_tokens"},
+		unsupportedModelParams(&workflow.Agent{Provider: "qwen", Settings: workflow.AgentSettings{Temperature: 0.2, MaxTokens: 100}}))
+}
+
+func TestModelParamArgs(t *testing.T) {
+	claude := &workflow.Agent{Provider: "claude", Settings: workflow.AgentSettings{MaxTokens: 8000, Reasoning: "medium"}}
+	assert.Equal(t, []string{"--settings", `{"env":{"CLAUDE_CODE_MAX_OUTPUT_TOKENS":"8000","MAX_THINKING_TOKENS":"10000"}}`}, modelParamArgs(claude))
+
+	gemini := &workflow.Agent{Provider: "gemini", Settings: workflow.AgentSettings{Temperature: 0.3}}
+	assert.Nil(t, modelParamArgs(gemini))
+
+	assert.Nil(t, modelParamArgs(&workflow.Agent{Provider: "claude"}))
+	assert.Nil(t, modelParamArgs(&workflow.Agent{Provider: "mock", Settings: workflow.AgentSettings{Temperature: 0.3}}))
+}
@@ -0,0 +1,164 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nodeIDPattern is what node names look like, so they can't be mistaken for
+// other targets
+var nodeIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validNodeID reports whether id can name a node. local and k8s are targets
+// of their own.
+func validNodeID(id string) bool {
+	return nodeIDPattern.MatchString(id) && id != "local" && id != kubernetesScheme
+}
+
+// Node names an SSH address so workflows can target it by name.
+// Nodes are plain SSH aliases: nothing is provisioned or looked up remotely.
+type Node struct {
+	ID          string `yaml:"id" json:"id"`
+	Address     string `yaml:"address" json:"address"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// NodesPath returns the node registry file, ~/.opun/nodes.yaml
+func NodesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "nodes.yaml"), nil
+}
+
+// LoadNodes reads the node registry, sorted by ID. A missing file is an empty registry.
+func LoadNodes(path string) ([]Node, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// SaveNode adds or replaces a node in the registry
+func SaveNode(path string, node Node) error {
+	if node.ID == "" {
+		return fmt.Errorf("node id is required")
+	}
+	if !validNodeID(node.ID) {
+		return fmt.Errorf("invalid node id %q: use letters, digits, '.', '_' and '-', starting with a letter or digit, other than local and k8s", node.ID)
+	}
+	if _, err := parseSSHTarget(node.Address); err != nil {
+		return fmt.Errorf("invalid node address %q, expected ssh://user@host[:port][/dir] with a host and user not starting with '-'", node.Address)
+	}
+
+	nodes, err := LoadNodes(path)
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range nodes {
+		if nodes[i].ID == node.ID {
+			nodes[i] = node
+			replaced = true
+		}
+	}
+	if !replaced {
+		nodes = append(nodes, node)
+	}
+	return writeNodes(path, nodes)
+}
+
+// RemoveNode deletes a node from the registry
+func RemoveNode(path, id string) error {
+	nodes, err := LoadNodes(path)
+	if err != nil {
+		return err
+	}
+
+	kept := nodes[:0]
+	for _, node := range nodes {
+		if node.ID != id {
+			kept = append(kept, node)
+		}
+	}
+	if len(kept) == len(nodes) {
+		return fmt.Errorf("node %s not found", id)
+	}
+	return writeNodes(path, kept)
+}
+
+// FindNode returns a registered node by ID
+func FindNode(path, id string) (*Node, error) {
+	nodes, err := LoadNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("node %s not found, add it with 'opun node add %s ssh://user@host'", id, id)
+}
+
+// NodeTarget resolves a registered node to its SSH target
+func NodeTarget(id string) (*SSHTarget, error) {
+	path, err := NodesPath()
+	if err != nil {
+		return nil, err
+	}
+	node, err := FindNode(path, id)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := parseSSHTarget(node.Address)
+	if err != nil {
+		return nil, fmt.Errorf("node %s: %w", id, err)
+	}
+	return target, nil
+}
+
+func writeNodes(path string, nodes []Node) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(nodes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.yaml")
+
+	nodes, err := LoadNodes(path)
+	require.NoError(t, err)
+	assert.Empty(t, nodes)
+
+	require.NoError(t, SaveNode(path, Node{ID: "gpu", Address: "ssh://dev@gpu-box/work"}))
+	require.NoError(t, SaveNode(path, Node{ID: "cpu", Address: "ssh://dev@cpu-box"}))
+	require.NoError(t, SaveNode(path, Node{ID: "gpu", Address: "ssh://dev@gpu-box:2222/work", Description: "A100"}))
+
+	nodes, err = LoadNodes(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Node{
+		{ID: "cpu", Address: "ssh://dev@cpu-box"},
+		{ID: "gpu", Address: "ssh://dev@gpu-box:2222/work", Description: "A100"},
+	}, nodes)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	node, err := FindNode(path, "gpu")
+	require.NoError(t, err)
+	assert.Equal(t, "A100", node.Description)
+
+	require.NoError(t, RemoveNode(path, "cpu"))
+	_, err = FindNode(path, "cpu")
+	assert.Error(t, err)
+	assert.Error(t, RemoveNode(path, "cpu"))
+}
+
+func TestSaveNodeValidatesAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.yaml")
+
+	assert.Error(t, SaveNode(path, Node{Address: "ssh://dev@box"}))
+	assert.Error(t, SaveNode(path, Node{ID: "box", Address: "dev@box"}))
+	assert.Error(t, SaveNode(path, Node{ID: "box", Address: "local"}))
+	assert.Error(t, SaveNode(path, Node{ID: "box", Address: "other-node"}))
+	assert.Error(t, SaveNode(path, Node{ID: "box", Address: "ssh://-oProxyCommand=evil"}))
+	assert.Error(t, SaveNode(path, Node{ID: "box", Address: "ssh://-oProxyCommand=evil@box"}))
+	for _, id := range []string{"local", "k8s", "-gpu", "gpu/1", "ssh://gpu"} {
+		assert.Error(t, SaveNode(path, Node{ID: id, Address: "ssh://dev@box"}), id)
+	}
+	assert.NoFileExists(t, path)
+}
+
+func TestNodeTarget(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	_, err := ParseTarget("gpu")
+	assert.ErrorContains(t, err, "opun node add gpu")
+
+	path, err := NodesPath()
+	require.NoError(t, err)
+	require.NoError(t, SaveNode(path, Node{ID: "gpu", Address: "ssh://dev@gpu-box:2222/work"}))
+
+	target, err := ParseTarget("gpu")
+	require.NoError(t, err)
+	assert.Equal(t, &SSHTarget{User: "dev", Host: "gpu-box", Port: "2222", Dir: "/work"}, target)
+	assert.True(t, IsRemoteTarget("gpu"))
+
+	// The old scheme points at the node name
+	_, err = ParseTarget("rizome://gpu")
+	assert.ErrorContains(t, err, "target: gpu")
+	assert.False(t, IsRemoteTarget("rizome://gpu"))
+}
+
+func TestSSHShellCommand(t *testing.T) {
+	cmd, args := (&SSHTarget{User: "dev", Host: "box", Dir: "/work"}).ShellCommand()
+	assert.Equal(t, "ssh", cmd)
+	assert.Equal(t, []string{"-t", "--", "dev@box", "cd /work && exec ${SHELL:-sh} -l"}, args)
+
+	_, args = (&SSHTarget{Host: "box", Port: "2222"}).ShellCommand()
+	assert.Equal(t, []string{"-t", "-p", "2222", "--", "box"}, args)
+}
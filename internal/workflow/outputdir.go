@@ -0,0 +1,148 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Output layouts
+const (
+	OutputLayoutFlat     = "flat"      // <output_dir>/<output>
+	OutputLayoutPerAgent = "per_agent" // <output_dir>/<agent id>/<output>
+)
+
+// OutputPathVars are the values of the placeholders in output_dir and agent
+// output paths
+type OutputPathVars struct {
+	Workflow string
+	RunID    string
+	Agent    string
+	Time     time.Time
+}
+
+// ExpandOutputPath replaces {{timestamp}}, {{date}}, {{workflow}}, {{run_id}}
+// and {{agent}} in an output_dir or output path
+func ExpandOutputPath(template string, vars OutputPathVars) string {
+	return strings.NewReplacer(
+		"{{timestamp}}", vars.Time.Format("20060102-150405"),
+		"{{date}}", vars.Time.Format("2006-01-02"),
+		"{{workflow}}", pathSafe(vars.Workflow),
+		"{{run_id}}", pathSafe(vars.RunID),
+		"{{agent}}", pathSafe(vars.Agent),
+	).Replace(template)
+}
+
+// pathSafe makes a name usable as a single path element
+func pathSafe(name string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '-'
+		}
+		return r
+	}, name), "-.")
+}
+
+// perAgentOutputs reports whether each agent writes into its own
+// subdirectory. Parallel workflows always do, so agents running at the same
+// time can't clash.
+func perAgentOutputs(settings workflow.Settings) bool {
+	return settings.OutputLayout == OutputLayoutPerAgent || settings.Parallel
+}
+
+// agentOutputName returns an agent's output path relative to the output directory
+func agentOutputName(wf *workflow.Workflow, agent *workflow.Agent, vars OutputPathVars, variables map[string]interface{}) string {
+	vars.Agent = agent.ID
+	name := filepath.Clean(substituteVariables(ExpandOutputPath(agent.Output, vars), variables))
+	if perAgentOutputs(wf.Settings) {
+		name = filepath.Join(pathSafe(agent.ID), name)
+	}
+	return name
+}
+
+// validateOutputPaths checks the output layout and that no two agents write
+// the same output file
+func validateOutputPaths(wf *workflow.Workflow) error {
+	switch wf.Settings.OutputLayout {
+	case "", OutputLayoutFlat, OutputLayoutPerAgent:
+	default:
+		return fmt.Errorf("output_layout must be flat or per_agent, got %q", wf.Settings.OutputLayout)
+	}
+	if strings.Contains(wf.Settings.OutputDir, "{{agent}}") {
+		return fmt.Errorf("output_dir can't use {{agent}}; use it in an agent's output or set output_layout: per_agent")
+	}
+
+	// Placeholders expand the same way for every agent of a run, except {{agent}}
+	vars := OutputPathVars{Workflow: wf.Name, RunID: "run", Time: time.Unix(0, 0)}
+	writers := make(map[string]string)
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		if agent.Output == "" {
+			continue
+		}
+
+		name := agentOutputName(wf, agent, vars, nil)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("agent %s: output %q must be a path inside output_dir", agent.ID, agent.Output)
+		}
+
+		key := filepath.ToSlash(name)
+		if other, ok := writers[key]; ok {
+			return fmt.Errorf("agents %s and %s both write %s; give one a different output, use {{agent}} in the name or set output_layout: per_agent", other, agent.ID, agent.Output)
+		}
+		writers[key] = agent.ID
+	}
+	return nil
+}
+
+// claimOutputDir returns dir, or a run-specific variant when another run that
+// is still in progress writes to dir, e.g. two runs started in the same second
+func claimOutputDir(dir, runID string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return dir
+	}
+	var manifest RunManifest
+	if json.Unmarshal(data, &manifest) != nil || manifest.Status != string(workflow.StatusRunning) {
+		return dir
+	}
+
+	claimed := dir + "-" + pathSafe(runID)
+	fmt.Printf("⚠️  %s is in use by another run; writing to %s\n", dir, claimed)
+	return claimed
+}
+
+// outputPathVars returns the placeholder values of the current run
+func (e *InteractiveExecutor) outputPathVars() OutputPathVars {
+	return OutputPathVars{Workflow: e.workflow.Name, RunID: e.runID, Time: e.state.StartTime}
+}
+
+// agentOutputPath returns where an agent saves its output, or "" when it has none
+func (e *InteractiveExecutor) agentOutputPath(agent *workflow.Agent) string {
+	if agent.Output == "" || e.outputDir == "" {
+		return ""
+	}
+	return filepath.Join(e.outputDir, agentOutputName(e.workflow, agent, e.outputPathVars(), e.state.Variables))
+}
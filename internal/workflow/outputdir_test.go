@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandOutputPath(t *testing.T) {
+	vars := OutputPathVars{
+		Workflow: "code review",
+		RunID:    "4242-17",
+		Agent:    "lint/fix",
+		Time:     time.Date(2025, 3, 9, 14, 5, 7, 0, time.UTC),
+	}
+
+	assert.Equal(t, "out/code-review/2025-03-09/4242-17", ExpandOutputPath("out/{{workflow}}/{{date}}/{{run_id}}", vars))
+	assert.Equal(t, "out/20250309-140507", ExpandOutputPath("out/{{timestamp}}", vars))
+	assert.Equal(t, "lint-fix-report.md", ExpandOutputPath("{{agent}}-report.md", vars))
+	assert.Equal(t, "report.md", ExpandOutputPath("report.md", vars))
+}
+
+func TestAgentOutputName(t *testing.T) {
+	wf := &workflow.Workflow{Name: "review"}
+	agent := &workflow.Agent{ID: "security", Output: "report.{{format}}"}
+	vars := OutputPathVars{Workflow: "review", Time: time.Now()}
+	variables := map[string]interface{}{"format": "md"}
+
+	assert.Equal(t, "report.md", agentOutputName(wf, agent, vars, variables))
+
+	wf.Settings.OutputLayout = OutputLayoutPerAgent
+	assert.Equal(t, filepath.Join("security", "report.md"), agentOutputName(wf, agent, vars, variables))
+
+	// Parallel workflows always get per-agent directories
+	wf.Settings = workflow.Settings{Parallel: true}
+	assert.Equal(t, filepath.Join("security", "report.md"), agentOutputName(wf, agent, vars, variables))
+}
+
+func TestValidateOutputPaths(t *testing.T) {
+	wf := &workflow.Workflow{
+		Name: "review",
+		Agents: []workflow.Agent{
+			{ID: "lint", Output: "report.md"},
+			{ID: "security", Output: "report.md"},
+			{ID: "notify"},
+		},
+	}
+	assert.EqualError(t, validateOutputPaths(wf),
+		"agents lint and security both write report.md; give one a different output, use {{agent}} in the name or set output_layout: per_agent")
+
+	wf.Agents[1].Output = "./{{agent}}/../report.md"
+	assert.Error(t, validateOutputPaths(wf), "same file once cleaned")
+
+	wf.Agents[1].Output = "{{agent}}-report.md"
+	assert.NoError(t, validateOutputPaths(wf))
+
+	wf.Agents[1].Output = "report.md"
+	wf.Settings.OutputLayout = OutputLayoutPerAgent
+	assert.NoError(t, validateOutputPaths(wf))
+
+	wf.Agents[1].Output = "../../etc/report.md"
+	assert.EqualError(t, validateOutputPaths(wf), `agent security: output "../../etc/report.md" must be a path inside output_dir`)
+
+	wf.Agents[1].Output = "report.md"
+	wf.Settings.OutputLayout = "nested"
+	assert.Error(t, validateOutputPaths(wf))
+
+	wf.Settings = workflow.Settings{OutputDir: "out/{{agent}}"}
+	assert.Error(t, validateOutputPaths(wf))
+}
+
+func TestClaimOutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "20250309-140507")
+	assert.Equal(t, dir, claimOutputDir(dir, "run-2"), "new directory")
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, writeManifest(dir, &RunManifest{Workflow: "review", Status: string(workflow.StatusCompleted)}))
+	assert.Equal(t, dir, claimOutputDir(dir, "run-2"), "finished runs are overwritten as before")
+
+	require.NoError(t, writeManifest(dir, &RunManifest{Workflow: "review", Status: string(workflow.StatusRunning)}))
+	assert.Equal(t, dir+"-run-2", claimOutputDir(dir, "run-2"))
+}
@@ -17,7 +17,9 @@ package workflow
 // Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -49,8 +51,76 @@ func (p *Parser) ParseFile(filePath string) (*wf.Workflow, error) {
 	return p.Parse(data)
 }
 
-// Parse parses a workflow from YAML data
+// Parse parses a workflow from YAML data. Anchors and aliases, including
+// `<<: *defaults` merge keys, can share blocks such as agent settings. Files
+// holding several workflows separated by --- are parsed with ParseAll.
 func (p *Parser) Parse(data []byte) (*wf.Workflow, error) {
+	docs, err := SplitDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow YAML: %w", err)
+	}
+	if len(docs) > 1 {
+		return nil, fmt.Errorf("found %d workflows separated by ---; add them with `opun add workflow <file> --all`", len(docs))
+	}
+
+	return p.parseDocument(data)
+}
+
+// ParseAll parses every workflow of a multi-document YAML file
+func (p *Parser) ParseAll(data []byte) ([]*wf.Workflow, error) {
+	docs, err := SplitDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow YAML: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no workflows found")
+	}
+
+	workflows := make([]*wf.Workflow, 0, len(docs))
+	for i, doc := range docs {
+		workflow, err := p.parseDocument(doc)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		workflows = append(workflows, workflow)
+	}
+	return workflows, nil
+}
+
+// SplitDocuments splits YAML data at --- separators into one document each,
+// skipping empty documents. Each document is re-encoded on its own, keeping
+// its comments, anchors and aliases.
+func SplitDocuments(data []byte) ([][]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var docs [][]byte
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if err == io.EOF {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		docs = append(docs, buf.Bytes())
+	}
+}
+
+// parseDocument parses and validates a single workflow document
+func (p *Parser) parseDocument(data []byte) (*wf.Workflow, error) {
 	var workflow wf.Workflow
 
 	// Parse YAML
@@ -153,6 +223,44 @@ func (p *Parser) validate(wf *wf.Workflow) error {
 		return fmt.Errorf("workflow must have at least one agent")
 	}
 
+	if _, err := NewRedactor(wf.Settings.Redact); err != nil {
+		return err
+	}
+
+	if err := validateLockSettings(wf.Settings); err != nil {
+		return err
+	}
+
+	if err := validateStorage(wf.Settings.Storage); err != nil {
+		return err
+	}
+
+	if err := validateEmail(wf.Settings.Email); err != nil {
+		return err
+	}
+
+	if err := validateKubernetes(wf); err != nil {
+		return err
+	}
+
+	if err := validateMissingRefs(wf.Settings); err != nil {
+		return err
+	}
+
+	if err := validateShowDiff(wf.Settings); err != nil {
+		return err
+	}
+
+	if err := validateMatrix(wf.Matrix); err != nil {
+		return err
+	}
+
+	for _, v := range wf.Variables {
+		if err := validateVariableRules(v); err != nil {
+			return err
+		}
+	}
+
 	// Validate agents
 	agentIDs := make(map[string]bool)
 	for i, agent := range wf.Agents {
@@ -169,12 +277,61 @@ func (p *Parser) validate(wf *wf.Workflow) error {
 		agentIDs[agent.ID] = true
 
 		// Validate agent fields
-		if agent.Provider == "" {
-			return fmt.Errorf("agent %s: provider is required", agent.ID)
+		switch {
+		case isProviderStep(&agent):
+			if agent.Provider == "" && len(agent.Requires) == 0 {
+				return fmt.Errorf("agent %s: provider or requires is required", agent.ID)
+			}
+
+			if err := validateCapabilities(agent.Requires); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+
+			if agent.Prompt == "" {
+				return fmt.Errorf("agent %s: prompt is required", agent.ID)
+			}
+
+			if err := validateModelParams(agent.Settings); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+
+			if err := validateResourceLimits(agent.Settings); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+
+			if err := validateIdleSettings(agent.Settings); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+
+			if err := validateReadySettings(&agent); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+
+			if err := validateScripts(&agent); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+		case isWaitStep(&agent):
+			if _, err := parseWaitStep(&agent); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+		case isInputStep(&agent):
+			if err := validateInputStep(&agent); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+		case isIssueStep(&agent):
+			if err := validateIssueStep(&agent); err != nil {
+				return fmt.Errorf("agent %s: %w", agent.ID, err)
+			}
+		default:
+			return fmt.Errorf("agent %s: unknown step type %q", agent.ID, agent.Type)
+		}
+
+		if err := validateArtifacts(&agent); err != nil {
+			return fmt.Errorf("agent %s: %w", agent.ID, err)
 		}
 
-		if agent.Prompt == "" {
-			return fmt.Errorf("agent %s: prompt is required", agent.ID)
+		if err := validateInputMapping(&agent, agentIDs); err != nil {
+			return fmt.Errorf("agent %s: %w", agent.ID, err)
 		}
 
 		// Validate dependencies
@@ -185,7 +342,14 @@ func (p *Parser) validate(wf *wf.Workflow) error {
 		}
 	}
 
-	return nil
+	// Conditions may refer to any agent, including later ones
+	for _, agent := range wf.Agents {
+		if err := validateCondition(wf, agent.Condition); err != nil {
+			return fmt.Errorf("agent %s: %w", agent.ID, err)
+		}
+	}
+
+	return validateOutputPaths(wf)
 }
 
 // processAgents processes agent definitions
@@ -194,10 +358,6 @@ func (p *Parser) processAgents(wf *wf.Workflow) error {
 		agent := &wf.Agents[i]
 
 		// Set default values
-		if agent.Settings.Temperature == 0 {
-			agent.Settings.Temperature = 0.7
-		}
-
 		if agent.Settings.Timeout == 0 {
 			agent.Settings.Timeout = 300 // 5 minutes default
 		}
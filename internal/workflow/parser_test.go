@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAnchors(t *testing.T) {
+	data := []byte(`
+name: review
+x-defaults:
+  settings: &careful
+    timeout: 600
+    retry_count: 2
+    temperature: 0.2
+agents:
+  - id: analyze
+    provider: claude
+    prompt: Analyze the code
+    settings: *careful
+  - id: report
+    provider: claude
+    prompt: Write the report
+    settings:
+      <<: *careful
+      timeout: 120
+`)
+
+	wf, err := NewParser("").Parse(data)
+	require.NoError(t, err)
+	require.Len(t, wf.Agents, 2)
+	assert.Equal(t, 600, wf.Agents[0].Settings.Timeout)
+	assert.Equal(t, 2, wf.Agents[0].Settings.RetryCount)
+	assert.Equal(t, 120, wf.Agents[1].Settings.Timeout, "merge key override")
+	assert.Equal(t, 2, wf.Agents[1].Settings.RetryCount)
+	assert.Equal(t, 0.2, wf.Agents[1].Settings.Temperature)
+}
+
+const multiDocWorkflows = `# Shared file
+---
+name: lint
+agents:
+  - id: lint
+    provider: claude
+    prompt: &task Review the diff
+---
+---
+name: review # second
+agents:
+  - id: review
+    provider: gemini
+    prompt: Review the diff
+`
+
+func TestSplitDocuments(t *testing.T) {
+	docs, err := SplitDocuments([]byte(multiDocWorkflows))
+	require.NoError(t, err)
+	require.Len(t, docs, 2, "empty documents are skipped")
+	assert.Contains(t, string(docs[0]), "prompt: &task Review the diff")
+	assert.Contains(t, string(docs[1]), "name: review # second")
+
+	_, err = SplitDocuments([]byte("name: [unclosed"))
+	assert.Error(t, err)
+}
+
+func TestParseAll(t *testing.T) {
+	parser := NewParser("")
+
+	workflows, err := parser.ParseAll([]byte(multiDocWorkflows))
+	require.NoError(t, err)
+	require.Len(t, workflows, 2)
+	assert.Equal(t, "lint", workflows[0].Name)
+	assert.Equal(t, "review", workflows[1].Name)
+	assert.Equal(t, "gemini", workflows[1].Agents[0].Provider)
+
+	_, err = parser.Parse([]byte(multiDocWorkflows))
+	assert.EqualError(t, err, "found 2 workflows separated by ---; add them with `opun add workflow <file> --all`")
+
+	_, err = parser.ParseAll([]byte(multiDocWorkflows + "---\nname: broken\n"))
+	assert.EqualError(t, err, "document 3: workflow validation failed: workflow must have at least one agent")
+
+	_, err = parser.ParseAll([]byte("---\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"golang.org/x/term"
+)
+
+// Prompt policy actions
+const (
+	PolicyAllow   = "allow"
+	PolicyConfirm = "confirm"
+	PolicyBlock   = "block"
+)
+
+// validatorConfirm is the exit code a policy validator uses to ask for confirmation
+const validatorConfirm = 2
+
+// defaultValidatorTimeout bounds a policy validator command
+const defaultValidatorTimeout = 10 * time.Second
+
+// PromptPolicy is checked against every rendered prompt before it is typed
+// into a provider
+type PromptPolicy struct {
+	Rules []PolicyRule `mapstructure:"rules" yaml:"rules"`
+	// Validator is an external command that gets the prompt on stdin
+	Validator *PolicyValidator `mapstructure:"validator" yaml:"validator,omitempty"`
+}
+
+// PolicyRule matches prompts by regular expression or deny phrase
+type PolicyRule struct {
+	Name    string   `mapstructure:"name" yaml:"name"`
+	Pattern string   `mapstructure:"pattern" yaml:"pattern,omitempty"`
+	Phrases []string `mapstructure:"phrases" yaml:"phrases,omitempty"` // Matched case-insensitively
+	Action  string   `mapstructure:"action" yaml:"action,omitempty"`   // block (default) or confirm
+}
+
+// PolicyValidator runs a shell command with the prompt on stdin. Exit code 0
+// allows the prompt, 2 asks for confirmation and anything else blocks it; the
+// first line of its output is shown as the reason.
+type PolicyValidator struct {
+	Command string        `mapstructure:"command" yaml:"command"`
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`
+}
+
+// PolicyDecision is the outcome of checking a prompt
+type PolicyDecision struct {
+	Action  string
+	Reasons []string
+}
+
+// Validate checks the policy's rules compile and have valid actions
+func (p *PromptPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.Pattern == "" && len(rule.Phrases) == 0 {
+			return fmt.Errorf("prompt policy rule %s: needs a pattern or phrases", name)
+		}
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("prompt policy rule %s: %w", name, err)
+			}
+		}
+		switch rule.Action {
+		case "", PolicyBlock, PolicyConfirm:
+		default:
+			return fmt.Errorf("prompt policy rule %s: unknown action %q", name, rule.Action)
+		}
+	}
+	if p.Validator != nil && strings.TrimSpace(p.Validator.Command) == "" {
+		return fmt.Errorf("prompt policy validator: command is required")
+	}
+	return nil
+}
+
+// Check runs the policy's rules and validator against a prompt. The strictest
+// matching action wins. env is added to the validator's environment.
+func (p *PromptPolicy) Check(ctx context.Context, prompt string, env []string) (PolicyDecision, error) {
+	decision := PolicyDecision{Action: PolicyAllow}
+	lower := strings.ToLower(prompt)
+
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		matched := false
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return decision, fmt.Errorf("prompt policy rule %s: %w", name, err)
+			}
+			matched = re.MatchString(prompt)
+		}
+		for _, phrase := range rule.Phrases {
+			if phrase != "" && strings.Contains(lower, strings.ToLower(phrase)) {
+				matched = true
+			}
+		}
+		if matched {
+			action := rule.Action
+			if action == "" {
+				action = PolicyBlock
+			}
+			decision.escalate(action, "rule "+name)
+		}
+	}
+
+	if p.Validator != nil {
+		action, reason, err := p.Validator.run(ctx, prompt, env)
+		if err != nil {
+			return decision, err
+		}
+		if action != PolicyAllow {
+			decision.escalate(action, reason)
+		}
+	}
+	return decision, nil
+}
+
+// escalate records a match, keeping the strictest action
+func (d *PolicyDecision) escalate(action, reason string) {
+	d.Reasons = append(d.Reasons, reason)
+	if action == PolicyBlock || d.Action == PolicyAllow {
+		d.Action = action
+	}
+}
+
+// run pipes the prompt to the validator command and maps its exit code to an action
+func (v *PolicyValidator) run(ctx context.Context, prompt string, env []string) (string, string, error) {
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = defaultValidatorTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	shell, flag := "/bin/sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	// #nosec G204 -- the validator command comes from the user's config
+	cmd := exec.CommandContext(ctx, shell, flag, v.Command)
+	cmd.Stdin = strings.NewReader(prompt)
+	cmd.Env = append(os.Environ(), env...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Don't wait on children of the shell that keep the output open after a timeout
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	reason := "validator"
+	if line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(out.String()), "\n", 2)[0]); line != "" {
+		reason = "validator: " + line
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return PolicyAllow, "", nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", "", fmt.Errorf("prompt policy validator timed out after %s", timeout)
+	case ctx.Err() != nil:
+		return "", "", ctx.Err()
+	case !errors.As(err, &exitErr):
+		return "", "", fmt.Errorf("prompt policy validator failed: %w", err)
+	case exitErr.ExitCode() == validatorConfirm:
+		return PolicyConfirm, reason, nil
+	default:
+		return PolicyBlock, reason, nil
+	}
+}
+
+// SetPromptPolicy sets the policy every prompt is checked against before it
+// is sent to a provider
+func (e *InteractiveExecutor) SetPromptPolicy(policy *PromptPolicy) {
+	e.promptPolicy = policy
+}
+
+// enforcePromptPolicy checks an agent's rendered prompt against the prompt
+// policy, asking the operator to confirm when a rule calls for it
+func (e *InteractiveExecutor) enforcePromptPolicy(ctx context.Context, agent *workflow.Agent, prompt string) error {
+	if e.promptPolicy == nil {
+		return nil
+	}
+
+	decision, err := e.promptPolicy.Check(ctx, prompt, []string{
+		"OPUN_WORKFLOW=" + e.workflow.Name,
+		"OPUN_AGENT_ID=" + agent.ID,
+		"OPUN_PROVIDER=" + agent.Provider,
+	})
+	if err != nil {
+		return err
+	}
+
+	reasons := strings.Join(decision.Reasons, ", ")
+	switch decision.Action {
+	case PolicyAllow:
+		e.recordDecision(agent, DecisionPolicy, PolicyAllow, "")
+		return nil
+	case PolicyBlock:
+		e.recordDecision(agent, DecisionPolicy, PolicyBlock, reasons)
+		return Classify(ErrorGateFailed, fmt.Errorf("prompt blocked by policy (%s)", reasons))
+	}
+
+	ask := e.ask
+	if ask == nil {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			e.recordDecision(agent, DecisionPolicy, "unconfirmed", reasons)
+			return Classify(ErrorGateFailed, fmt.Errorf("prompt needs confirmation by policy (%s) but there is no terminal to ask", reasons))
+		}
+		ask = askOperator
+	}
+	answer, err := ask(fmt.Sprintf("🚧 The prompt for %s matched the prompt policy (%s). Send it anyway?", agent.Name, reasons), []string{"Abort", "Send anyway"}, "Abort")
+	if err != nil {
+		return fmt.Errorf("prompt policy confirmation: %w", err)
+	}
+	if answer != "Send anyway" {
+		e.recordDecision(agent, DecisionPolicy, "rejected", reasons)
+		return Classify(ErrorGateFailed, fmt.Errorf("prompt rejected at policy confirmation (%s)", reasons))
+	}
+	e.recordDecision(agent, DecisionPolicy, "confirmed", reasons)
+	return nil
+}
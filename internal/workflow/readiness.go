@@ -0,0 +1,171 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Actions when a provider's input prompt isn't detected within ready_timeout
+const (
+	// ReadyAsk has the operator press Enter to type the prompt
+	ReadyAsk = "ask"
+	// ReadyRetry starts the session over with the prompt as a launch argument
+	ReadyRetry = "retry"
+	// ReadyType types the prompt anyway
+	ReadyType = "type"
+	// ReadyFail stops the step with a timeout error
+	ReadyFail = "fail"
+)
+
+// How the prompt reaches a provider session
+const (
+	// InjectType types the prompt once the input prompt is detected
+	InjectType = "type"
+	// InjectFlag passes the prompt when the provider is launched
+	InjectFlag = "flag"
+)
+
+// defaultReadyTimeout is how long a provider gets to show its input prompt
+const defaultReadyTimeout = 30 * time.Second
+
+// alternateReadyPatterns are looked for once a provider's own ready pattern
+// hasn't shown up in time, e.g. after a UI update changed its prompt line
+var alternateReadyPatterns = []string{
+	"? for shortcuts",
+	"Type your message",
+	"│ >",
+	"│ ❯",
+	"│\u00a0>",
+	"Human:",
+}
+
+// errPromptNotInjected ends a session that is started over with the prompt
+// as a launch argument
+var errPromptNotInjected = errors.New("provider input prompt not detected")
+
+// validateReadySettings checks an agent's ready timeout, fallback and
+// prompt injection
+func validateReadySettings(agent *workflow.Agent) error {
+	settings := agent.Settings
+	if settings.ReadyTimeout != "" {
+		d, err := time.ParseDuration(settings.ReadyTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid ready_timeout %q: %w", settings.ReadyTimeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("ready_timeout must be positive, got %s", settings.ReadyTimeout)
+		}
+	}
+	switch settings.OnNotReady {
+	case "", ReadyAsk, ReadyRetry, ReadyType, ReadyFail:
+	default:
+		return fmt.Errorf("on_not_ready must be ask, retry, type or fail, got %q", settings.OnNotReady)
+	}
+	switch settings.PromptInjection {
+	case "", InjectType:
+	case InjectFlag:
+		if promptLaunchArgs(agent.Provider, "") == nil {
+			return fmt.Errorf("prompt_injection flag isn't supported by %s", agent.Provider)
+		}
+	default:
+		return fmt.Errorf("prompt_injection must be type or flag, got %q", settings.PromptInjection)
+	}
+	return nil
+}
+
+// readyTimeout returns how long an agent's provider gets to show its input prompt
+func readyTimeout(agent *workflow.Agent) time.Duration {
+	if d, err := time.ParseDuration(agent.Settings.ReadyTimeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultReadyTimeout
+}
+
+// notReadyAction returns what happens when an agent's input prompt isn't
+// detected: the operator is asked when there is one at the terminal, else
+// the session is started over with the prompt as a launch argument where the
+// provider takes one, else the prompt is typed anyway
+func notReadyAction(agent *workflow.Agent, interactive bool) string {
+	action := agent.Settings.OnNotReady
+	if action == "" {
+		action = ReadyRetry
+		if interactive {
+			action = ReadyAsk
+		}
+	}
+	if action == ReadyRetry && promptLaunchArgs(agent.Provider, "") == nil {
+		action = ReadyType
+	}
+	return action
+}
+
+// alternateReady reports whether output looks like an input prompt by the
+// alternate patterns
+func alternateReady(output string) bool {
+	output = providers.StripANSI(output)
+	for _, pattern := range alternateReadyPatterns {
+		if strings.Contains(output, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptLaunchArgs returns the arguments that start a provider's interactive
+// session with a prompt already submitted, nil when it has none
+func promptLaunchArgs(provider, prompt string) []string {
+	switch strings.ToLower(provider) {
+	case "claude":
+		return []string{prompt}
+	case "gemini", "qwen":
+		return []string{"--prompt-interactive", prompt}
+	}
+	return nil
+}
+
+// typePrompt types a prompt into a provider's terminal character by
+// character, after giving its UI delay to settle
+func typePrompt(w io.Writer, prompt string, delay, charDelay time.Duration) {
+	time.Sleep(delay)
+	for _, char := range prompt {
+		_, _ = w.Write([]byte(string(char)))
+		time.Sleep(charDelay)
+	}
+}
+
+// runInteractiveAgent runs an agent's session. A session whose input prompt
+// was never detected is started over with the prompt as a launch argument.
+func (e *InteractiveExecutor) runInteractiveAgent(ctx context.Context, agent *workflow.Agent, agentIndex int) error {
+	err := e.executeInteractiveAgent(ctx, agent, agentIndex)
+	if !errors.Is(err, errPromptNotInjected) {
+		return err
+	}
+
+	fmt.Printf("\n🔁 Starting %s over with the prompt as a launch argument\n", agent.Name)
+	retry := *agent
+	retry.Settings.PromptInjection = InjectFlag
+	return e.executeInteractiveAgent(ctx, &retry, agentIndex)
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReadySettings(t *testing.T) {
+	valid := []workflow.Agent{
+		{Provider: "claude"},
+		{Provider: "claude", Settings: workflow.AgentSettings{ReadyTimeout: "45s", OnNotReady: ReadyAsk}},
+		{Provider: "gemini", Settings: workflow.AgentSettings{OnNotReady: ReadyRetry, PromptInjection: InjectFlag}},
+		{Provider: "qwen", Settings: workflow.AgentSettings{OnNotReady: ReadyFail, PromptInjection: InjectType}},
+	}
+	for _, agent := range valid {
+		assert.NoError(t, validateReadySettings(&agent))
+	}
+
+	invalid := []workflow.Agent{
+		{Provider: "claude", Settings: workflow.AgentSettings{ReadyTimeout: "soon"}},
+		{Provider: "claude", Settings: workflow.AgentSettings{ReadyTimeout: "-5s"}},
+		{Provider: "claude", Settings: workflow.AgentSettings{OnNotReady: "wait"}},
+		{Provider: "claude", Settings: workflow.AgentSettings{PromptInjection: "paste"}},
+		{Provider: "mock", Settings: workflow.AgentSettings{PromptInjection: InjectFlag}},
+	}
+	for _, agent := range invalid {
+		assert.Error(t, validateReadySettings(&agent), agent.Settings)
+	}
+}
+
+func TestReadyTimeout(t *testing.T) {
+	assert.Equal(t, defaultReadyTimeout, readyTimeout(&workflow.Agent{}))
+	assert.Equal(t, 45*time.Second, readyTimeout(&workflow.Agent{Settings: workflow.AgentSettings{ReadyTimeout: "45s"}}))
+}
+
+func TestNotReadyAction(t *testing.T) {
+	claude := &workflow.Agent{Provider: "claude"}
+	assert.Equal(t, ReadyAsk, notReadyAction(claude, true))
+	assert.Equal(t, ReadyRetry, notReadyAction(claude, false))
+
+	// Providers without a prompt argument can't be started over with one
+	mock := &workflow.Agent{Provider: "mock"}
+	assert.Equal(t, ReadyType, notReadyAction(mock, false))
+	mock.Settings.OnNotReady = ReadyRetry
+	assert.Equal(t, ReadyType, notReadyAction(mock, true))
+
+	claude.Settings.OnNotReady = ReadyFail
+	assert.Equal(t, ReadyFail, notReadyAction(claude, true))
+}
+
+func TestAlternateReady(t *testing.T) {
+	assert.True(t, alternateReady("\x1b[2m? for shortcuts\x1b[0m"))
+	assert.True(t, alternateReady("╭────╮\n│ ❯ \n╰────╯"))
+	assert.True(t, alternateReady("│ > Try \"fix lint errors\""))
+	assert.False(t, alternateReady("Do you trust the files in this folder?\n❯ 1. Yes, proceed"))
+}
+
+func TestPromptLaunchArgs(t *testing.T) {
+	assert.Equal(t, []string{"fix it"}, promptLaunchArgs("claude", "fix it"))
+	assert.Equal(t, []string{"--prompt-interactive", "fix it"}, promptLaunchArgs("Gemini", "fix it"))
+	assert.Equal(t, []string{"--prompt-interactive", "fix it"}, promptLaunchArgs("qwen", "fix it"))
+	assert.Nil(t, promptLaunchArgs("mock", "fix it"))
+}
+
+func TestTypePrompt(t *testing.T) {
+	var out bytes.Buffer
+	typePrompt(&out, "héllo", 0, 0)
+	assert.Equal(t, "héllo", out.String())
+}
@@ -0,0 +1,357 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// sessionsDirName is the directory under RunsDir holding session registries.
+// It is kept apart from the state files because ListRuns prunes those as
+// soon as their process is gone, which is exactly when recovery needs them.
+const sessionsDirName = "sessions"
+
+// staleRunFileAge is how old an attach socket without a state file must be
+// before it is considered left over rather than belonging to a starting run
+const staleRunFileAge = time.Minute
+
+// ProviderProcess is a provider started for an agent
+type ProviderProcess struct {
+	AgentID   string    `json:"agent_id"`
+	Provider  string    `json:"provider"`
+	PID       int       `json:"pid"`
+	Command   string    `json:"command"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// SessionRegistry records what a run leaves behind if opun dies without
+// cleaning up: the provider processes it started, whether it put the
+// terminal in raw mode and the output directory it holds
+type SessionRegistry struct {
+	RunID       string            `json:"run_id"`
+	PID         int               `json:"pid"`
+	Workflow    string            `json:"workflow"`
+	OutputDir   string            `json:"output_dir,omitempty"`
+	RawTerminal bool              `json:"raw_terminal,omitempty"`
+	Providers   []ProviderProcess `json:"providers,omitempty"`
+	StartTime   time.Time         `json:"start_time"`
+
+	mu   sync.Mutex
+	path string
+}
+
+// openSessionRegistry starts the registry of a run. It returns nil when the
+// registry can't be written; runs still work, they just can't be recovered.
+func openSessionRegistry(runID, workflowName, outputDir string) *SessionRegistry {
+	runsDir, err := RunsDir()
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Join(runsDir, sessionsDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil
+	}
+
+	r := &SessionRegistry{
+		RunID:     runID,
+		PID:       os.Getpid(),
+		Workflow:  workflowName,
+		OutputDir: outputDir,
+		StartTime: time.Now(),
+		path:      filepath.Join(dir, runID+".json"),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.save() != nil {
+		return nil
+	}
+	return r
+}
+
+// AddProvider records a started provider process
+func (r *SessionRegistry) AddProvider(agent *workflow.Agent, pid int, command string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Providers = append(r.Providers, ProviderProcess{
+		AgentID:   agent.ID,
+		Provider:  agent.Provider,
+		PID:       pid,
+		Command:   filepath.Base(command),
+		StartTime: time.Now(),
+	})
+	_ = r.save()
+}
+
+// RemoveProvider forgets a provider process that has been stopped
+func (r *SessionRegistry) RemoveProvider(pid int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, p := range r.Providers {
+		if p.PID == pid {
+			r.Providers = append(r.Providers[:i], r.Providers[i+1:]...)
+			break
+		}
+	}
+	_ = r.save()
+}
+
+// SetRawTerminal records whether the run has the terminal in raw mode
+func (r *SessionRegistry) SetRawTerminal(raw bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.RawTerminal = raw
+	_ = r.save()
+}
+
+// Close removes the registry of a run that ended normally
+func (r *SessionRegistry) Close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = os.Remove(r.path)
+}
+
+// abort marks the run's output aborted and removes the registry, for runs
+// that ended without finishing
+func (r *SessionRegistry) abort() error {
+	if r == nil {
+		return nil
+	}
+	err := abortManifest(r.OutputDir)
+	r.Close()
+	return err
+}
+
+// save writes the registry. Callers must hold the lock.
+func (r *SessionRegistry) save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// CrashedRun is a run whose opun process exited without cleaning up
+type CrashedRun struct {
+	Registry *SessionRegistry
+	Orphans  []ProviderProcess // provider processes still running
+}
+
+// Recovery is what crashed runs left behind
+type Recovery struct {
+	Runs       []CrashedRun
+	StaleFiles []string // state files and attach sockets of runs that are gone
+
+	runsDir string
+}
+
+// FindCrashedRuns looks for runs whose opun process is gone
+func FindCrashedRuns(runsDir string) (*Recovery, error) {
+	recovery := &Recovery{runsDir: runsDir}
+
+	registries, err := os.ReadDir(filepath.Join(runsDir, sessionsDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range registries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(runsDir, sessionsDirName, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		registry := &SessionRegistry{path: path}
+		if err := json.Unmarshal(data, registry); err != nil || utils.ProcessAlive(registry.PID) {
+			continue
+		}
+
+		run := CrashedRun{Registry: registry}
+		for _, p := range registry.Providers {
+			if utils.ProcessAlive(p.PID) && processRuns(p.PID, p.Command) {
+				run.Orphans = append(run.Orphans, p)
+			}
+		}
+		recovery.Runs = append(recovery.Runs, run)
+	}
+
+	entries, err := os.ReadDir(runsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		var id string
+		switch {
+		case strings.HasSuffix(name, ".json"):
+			id = strings.TrimSuffix(name, ".json")
+		case strings.HasSuffix(name, ".sock"):
+			id = strings.TrimSuffix(name, ".sock")
+		default:
+			continue
+		}
+		info, err := entry.Info()
+		if err == nil && staleRunFile(runsDir, id, info) {
+			recovery.StaleFiles = append(recovery.StaleFiles, filepath.Join(runsDir, name))
+		}
+	}
+
+	return recovery, nil
+}
+
+// Empty reports whether there is nothing to recover
+func (r *Recovery) Empty() bool {
+	return len(r.Runs) == 0 && len(r.StaleFiles) == 0
+}
+
+// Orphans returns the provider processes of all crashed runs still running
+func (r *Recovery) Orphans() []ProviderProcess {
+	var orphans []ProviderProcess
+	for _, run := range r.Runs {
+		orphans = append(orphans, run.Orphans...)
+	}
+	return orphans
+}
+
+// RawTerminal reports whether a crashed run left the terminal in raw mode
+func (r *Recovery) RawTerminal() bool {
+	for _, run := range r.Runs {
+		if run.Registry.RawTerminal {
+			return true
+		}
+	}
+	return false
+}
+
+// Clean stops the orphaned providers when kill is set, marks the outputs of
+// crashed runs aborted so their directories can be reused, and removes the
+// registries and stale files. Runs whose providers are left running keep
+// their registry so a later recovery still finds them.
+func (r *Recovery) Clean(kill bool) []error {
+	var errs []error
+	for _, run := range r.Runs {
+		if len(run.Orphans) > 0 {
+			if !kill {
+				continue
+			}
+			for _, p := range run.Orphans {
+				if err := killOrphan(p.PID); err != nil {
+					errs = append(errs, fmt.Errorf("failed to stop %s (PID %d): %w", p.Provider, p.PID, err))
+				}
+			}
+		}
+
+		if err := run.Registry.abort(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, path := range r.StaleFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// abortManifest marks a run manifest that still says running as aborted;
+// a running manifest makes later runs write somewhere else
+func abortManifest(outputDir string) error {
+	if outputDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestFile))
+	if err != nil {
+		return nil
+	}
+	var m RunManifest
+	if json.Unmarshal(data, &m) != nil || m.Status != string(workflow.StatusRunning) {
+		return nil
+	}
+
+	m.Status = string(workflow.StatusAborted)
+	now := time.Now()
+	m.FinishedAt = &now
+	if err := writeManifest(outputDir, &m); err != nil {
+		return fmt.Errorf("failed to update run manifest in %s: %w", outputDir, err)
+	}
+	return nil
+}
+
+// staleRunFile reports whether a run's state file or attach socket outlived
+// the run, going by the PID in the state file
+func staleRunFile(runsDir, id string, info os.FileInfo) bool {
+	if data, err := os.ReadFile(filepath.Join(runsDir, id+".json")); err == nil {
+		var run RunStatus
+		if json.Unmarshal(data, &run) == nil && run.PID > 0 {
+			return !utils.ProcessAlive(run.PID)
+		}
+	}
+	// Without a readable state file the run is either starting or gone
+	return time.Since(info.ModTime()) > staleRunFileAge
+}
+
+// processRuns reports whether pid is still running command, so a reused PID
+// is never mistaken for an orphaned provider
+func processRuns(pid int, command string) bool {
+	if command == "" {
+		return false
+	}
+
+	var out []byte
+	var err error
+	if runtime.GOOS == "windows" {
+		out, err = exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+	} else {
+		out, err = exec.Command("ps", "-o", "args=", "-p", strconv.Itoa(pid)).Output()
+	}
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(out)), strings.ToLower(strings.TrimSuffix(command, ".exe")))
+}
@@ -0,0 +1,162 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadPID returns the PID of a process that has exited
+func deadPID(t *testing.T) int {
+	t.Helper()
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	cmd := exec.Command(exe, "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func writeJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestSessionRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	registry := openSessionRegistry("run-1", "review", "")
+	require.NotNil(t, registry)
+
+	registry.AddProvider(&workflow.Agent{ID: "analyze", Provider: "claude"}, 4242, "/usr/local/bin/claude")
+	registry.SetRawTerminal(true)
+
+	runsDir, err := RunsDir()
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(runsDir, sessionsDirName, "run-1.json"))
+	require.NoError(t, err)
+	var saved SessionRegistry
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, os.Getpid(), saved.PID)
+	assert.True(t, saved.RawTerminal)
+	require.Len(t, saved.Providers, 1)
+	assert.Equal(t, "claude", saved.Providers[0].Command)
+
+	registry.RemoveProvider(4242)
+	assert.Empty(t, registry.Providers)
+
+	// A live run is not crashed
+	recovery, err := FindCrashedRuns(runsDir)
+	require.NoError(t, err)
+	assert.True(t, recovery.Empty())
+
+	registry.Close()
+	assert.NoFileExists(t, registry.path)
+
+	// Registries are optional
+	var none *SessionRegistry
+	none.AddProvider(&workflow.Agent{ID: "a"}, 1, "claude")
+	none.Close()
+}
+
+func TestRecovery(t *testing.T) {
+	runsDir := t.TempDir()
+	outputDir := t.TempDir()
+	dead := deadPID(t)
+
+	writeJSON(t, filepath.Join(outputDir, ManifestFile), RunManifest{Workflow: "review", Status: string(workflow.StatusRunning)})
+	writeJSON(t, filepath.Join(runsDir, sessionsDirName, "crashed.json"), SessionRegistry{
+		RunID:       "crashed",
+		PID:         dead,
+		Workflow:    "review",
+		OutputDir:   outputDir,
+		RawTerminal: true,
+		Providers:   []ProviderProcess{{AgentID: "analyze", Provider: "claude", PID: dead, Command: "claude"}},
+	})
+	writeJSON(t, filepath.Join(runsDir, sessionsDirName, "live.json"), SessionRegistry{RunID: "live", PID: os.Getpid()})
+
+	writeJSON(t, filepath.Join(runsDir, "crashed.json"), RunStatus{ID: "crashed", PID: dead})
+	require.NoError(t, os.WriteFile(filepath.Join(runsDir, "crashed.sock"), nil, 0644))
+	writeJSON(t, filepath.Join(runsDir, "live.json"), RunStatus{ID: "live", PID: os.Getpid()})
+	require.NoError(t, os.WriteFile(filepath.Join(runsDir, "starting.sock"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(runsDir, "crashed.log"), nil, 0644))
+
+	old := filepath.Join(runsDir, "old.sock")
+	require.NoError(t, os.WriteFile(old, nil, 0644))
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(old, past, past))
+
+	recovery, err := FindCrashedRuns(runsDir)
+	require.NoError(t, err)
+	require.Len(t, recovery.Runs, 1)
+	assert.Equal(t, "crashed", recovery.Runs[0].Registry.RunID)
+	assert.Empty(t, recovery.Orphans(), "the provider has exited too")
+	assert.True(t, recovery.RawTerminal())
+	assert.ElementsMatch(t, []string{
+		filepath.Join(runsDir, "crashed.json"),
+		filepath.Join(runsDir, "crashed.sock"),
+		old,
+	}, recovery.StaleFiles)
+
+	assert.Empty(t, recovery.Clean(false))
+
+	var manifest RunManifest
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, string(workflow.StatusAborted), manifest.Status)
+	assert.NotNil(t, manifest.FinishedAt)
+
+	assert.NoFileExists(t, filepath.Join(runsDir, sessionsDirName, "crashed.json"))
+	assert.FileExists(t, filepath.Join(runsDir, sessionsDirName, "live.json"))
+	assert.FileExists(t, filepath.Join(runsDir, "live.json"))
+	assert.FileExists(t, filepath.Join(runsDir, "starting.sock"))
+	assert.FileExists(t, filepath.Join(runsDir, "crashed.log"))
+
+	recovery, err = FindCrashedRuns(runsDir)
+	require.NoError(t, err)
+	assert.True(t, recovery.Empty())
+}
+
+func TestRecoveryFindsOrphans(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+	orphan := exec.Command(sleep, "30")
+	require.NoError(t, orphan.Start())
+	defer func() {
+		_ = orphan.Process.Kill()
+		_ = orphan.Wait()
+	}()
+
+	runsDir := t.TempDir()
+	writeJSON(t, filepath.Join(runsDir, sessionsDirName, "crashed.json"), SessionRegistry{
+		RunID: "crashed",
+		PID:   deadPID(t),
+		Providers: []ProviderProcess{
+			{AgentID: "analyze", Provider: "claude", PID: orphan.Process.Pid, Command: "sleep"},
+			// A reused PID running something else is left alone
+			{AgentID: "review", Provider: "gemini", PID: orphan.Process.Pid, Command: "gemini"},
+		},
+	})
+
+	recovery, err := FindCrashedRuns(runsDir)
+	require.NoError(t, err)
+	require.Len(t, recovery.Orphans(), 1)
+	assert.Equal(t, "analyze", recovery.Orphans()[0].AgentID)
+
+	// Without killing, the run is kept for a later recovery
+	assert.Empty(t, recovery.Clean(false))
+	assert.FileExists(t, filepath.Join(runsDir, sessionsDirName, "crashed.json"))
+}
@@ -0,0 +1,202 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// RedactionsFile is the per-run report of redactions, written to the output directory
+const RedactionsFile = "redactions.json"
+
+// redactionPattern is a named pattern whose matches are scrubbed
+type redactionPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// builtinRedactions are the secrets and PII scrubbed by default. Order matters
+// where patterns overlap, e.g. Anthropic keys also look like OpenAI keys.
+var builtinRedactions = []redactionPattern{
+	{"private_key", regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"anthropic_key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"openai_key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+}
+
+// Redactor scrubs secrets and PII from text and keeps a tally of what it removed
+type Redactor struct {
+	patterns []redactionPattern
+
+	mu     sync.Mutex
+	report RedactionReport
+}
+
+// RedactionReport counts the redactions of a run by pattern and by file
+type RedactionReport struct {
+	Total    int                       `json:"total"`
+	Patterns map[string]int            `json:"patterns"`
+	Files    map[string]map[string]int `json:"files,omitempty"`
+}
+
+// NewRedactor creates a redactor from workflow settings. It returns nil when
+// redaction is not enabled.
+func NewRedactor(settings *workflow.Redact) (*Redactor, error) {
+	if settings == nil || !settings.Enabled {
+		return nil, nil
+	}
+
+	r := &Redactor{report: RedactionReport{Patterns: make(map[string]int)}}
+	for _, pattern := range builtinRedactions {
+		if !contains(settings.Disable, pattern.name) {
+			r.patterns = append(r.patterns, pattern)
+		}
+	}
+
+	// Custom patterns run after the built-ins, in name order so runs are repeatable
+	names := make([]string, 0, len(settings.Patterns))
+	for name := range settings.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(settings.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %s: %w", name, err)
+		}
+		r.patterns = append(r.patterns, redactionPattern{name, re})
+	}
+	return r, nil
+}
+
+// Redact replaces every match with [REDACTED:<pattern>] and returns the
+// number of matches by pattern
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	text, counts := r.scrub(text)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, n := range counts {
+		r.report.Patterns[name] += n
+		r.report.Total += n
+	}
+	return text, counts
+}
+
+// scrub replaces every match like Redact without adding to the report
+func (r *Redactor) scrub(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	for _, pattern := range r.patterns {
+		text = pattern.re.ReplaceAllStringFunc(text, func(string) string {
+			counts[pattern.name]++
+			return "[REDACTED:" + pattern.name + "]"
+		})
+	}
+	return text, counts
+}
+
+// RedactFile scrubs a file in place, returning the number of redactions
+func (r *Redactor) RedactFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	redacted, counts := r.Redact(string(data))
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, []byte(redacted), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.report.Files == nil {
+		r.report.Files = make(map[string]map[string]int)
+	}
+	r.report.Files[path] = counts
+	return total, nil
+}
+
+// Report returns a copy of the redactions made so far
+func (r *Redactor) Report() RedactionReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := RedactionReport{Total: r.report.Total, Patterns: make(map[string]int, len(r.report.Patterns))}
+	for name, n := range r.report.Patterns {
+		report.Patterns[name] = n
+	}
+	if len(r.report.Files) > 0 {
+		report.Files = make(map[string]map[string]int, len(r.report.Files))
+		for path, counts := range r.report.Files {
+			report.Files[path] = counts
+		}
+	}
+	return report
+}
+
+// writeRedactionReport writes a report to dir/redactions.json
+func writeRedactionReport(dir string, report RedactionReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, RedactionsFile), data, 0644)
+}
+
+// redactOutput scrubs an agent's output file before it is handed to later agents
+func (e *InteractiveExecutor) redactOutput(agent *workflow.Agent) {
+	outputPath, ok := e.outputs[agent.ID]
+	if e.redactor == nil || !ok {
+		return
+	}
+
+	n, err := e.redactor.RedactFile(outputPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("⚠️  Could not redact %s output: %v\n", agent.Name, err)
+		}
+		return
+	}
+	if n > 0 {
+		fmt.Printf("🛡️  Redacted %d secret(s) from %s\n", n, filepath.Base(outputPath))
+	}
+}
@@ -0,0 +1,255 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// SSHTarget is a remote machine that runs agent providers over SSH
+type SSHTarget struct {
+	User string
+	Host string
+	Port string
+	// Dir is the remote working directory; empty means the same path as locally
+	Dir string
+}
+
+// ParseTarget parses an execution target. It returns nil for local execution.
+// A bare name is a node: an alias for the SSH address registered under that
+// name in ~/.opun/nodes.yaml.
+func ParseTarget(target string) (*SSHTarget, error) {
+	if target == "" || target == "local" {
+		return nil, nil
+	}
+	if IsKubernetesTarget(target) {
+		return nil, fmt.Errorf("target %s runs headless steps only, use opun run --headless or --matrix", target)
+	}
+	if validNodeID(target) {
+		return NodeTarget(target)
+	}
+	if id, ok := strings.CutPrefix(target, "rizome://"); ok {
+		return nil, fmt.Errorf("rizome:// targets are no longer supported, use the node name instead: target: %s", id)
+	}
+
+	return parseSSHTarget(target)
+}
+
+// parseSSHTarget parses an ssh:// target. Hosts and users starting with -
+// are rejected so they can't be read as ssh options.
+func parseSSHTarget(target string) (*SSHTarget, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "ssh" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid target %q, expected local, ssh://user@host[:port][/dir] or a node name from 'opun node list'", target)
+	}
+
+	t := &SSHTarget{
+		Host: u.Hostname(),
+		Port: u.Port(),
+		Dir:  u.Path,
+	}
+	if u.User != nil {
+		t.User = u.User.Username()
+	}
+	if strings.HasPrefix(t.Host, "-") || strings.HasPrefix(t.User, "-") {
+		return nil, fmt.Errorf("invalid target %q: host and user can't start with '-'", target)
+	}
+	return t, nil
+}
+
+// IsRemoteTarget reports whether a target runs agents on another machine.
+// Unlike ParseTarget it doesn't look node names up.
+func IsRemoteTarget(target string) bool {
+	if IsKubernetesTarget(target) || validNodeID(target) {
+		return true
+	}
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme == "ssh" && u.Hostname() != ""
+}
+
+// agentTarget returns the effective target of an agent
+func agentTarget(wf *workflow.Workflow, agent *workflow.Agent) string {
+	if agent.Target != "" {
+		return agent.Target
+	}
+	return wf.Settings.Target
+}
+
+// AgentRunsRemotely reports whether an agent of the workflow runs on a remote target
+func AgentRunsRemotely(wf *workflow.Workflow, agent *workflow.Agent) bool {
+	return IsRemoteTarget(agentTarget(wf, agent))
+}
+
+// destination returns the ssh destination, user@host
+func (t *SSHTarget) destination() string {
+	if t.User != "" {
+		return t.User + "@" + t.Host
+	}
+	return t.Host
+}
+
+// sshOptions returns the ssh options selecting the port
+func (t *SSHTarget) sshOptions() []string {
+	if t.Port != "" {
+		return []string{"-p", t.Port}
+	}
+	return nil
+}
+
+// wrapCommand runs a command in the remote working directory over ssh with a TTY.
+// The destination follows --, like in every ssh and rsync call here, so it is
+// never parsed as an option. Window size changes and Ctrl+C reach the remote process through the local PTY.
+func (t *SSHTarget) wrapCommand(remoteDir, cmd string, args []string) (string, []string) {
+	quoted := []string{shellQuote(cmd)}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	remote := fmt.Sprintf("cd %s && exec %s", shellQuote(remoteDir), strings.Join(quoted, " "))
+
+	sshArgs := append([]string{"-t"}, t.sshOptions()...)
+	sshArgs = append(sshArgs, "--", t.destination(), remote)
+	return "ssh", sshArgs
+}
+
+// ShellCommand returns the ssh command that opens an interactive login shell on the target
+func (t *SSHTarget) ShellCommand() (string, []string) {
+	sshArgs := append([]string{"-t"}, t.sshOptions()...)
+	sshArgs = append(sshArgs, "--", t.destination())
+	if t.Dir != "" {
+		sshArgs = append(sshArgs, fmt.Sprintf("cd %s && exec ${SHELL:-sh} -l", shellQuote(t.Dir)))
+	}
+	return "ssh", sshArgs
+}
+
+// remotePath maps a local path used in prompts to the remote machine.
+// Relative paths stay relative to the remote working directory.
+func (t *SSHTarget) remotePath(remoteDir, localPath string) string {
+	if filepath.IsAbs(localPath) {
+		return filepath.ToSlash(localPath)
+	}
+	return path.Join(remoteDir, filepath.ToSlash(localPath))
+}
+
+// syncCommands returns the commands that copy a directory to (push) or from
+// the remote machine with rsync
+func (t *SSHTarget) syncCommands(localDir, remoteDir string, push bool) [][]string {
+	rsh := strings.Join(append([]string{"ssh"}, t.sshOptions()...), " ")
+	remote := t.destination() + ":" + shellQuote(remoteDir) + "/"
+	local := strings.TrimSuffix(localDir, string(filepath.Separator)) + string(filepath.Separator)
+
+	if push {
+		mkdir := append([]string{"ssh"}, t.sshOptions()...)
+		mkdir = append(mkdir, "--", t.destination(), "mkdir -p "+shellQuote(remoteDir))
+		return [][]string{
+			mkdir,
+			{"rsync", "-az", "-e", rsh, "--", local, remote},
+		}
+	}
+	return [][]string{
+		{"rsync", "-az", "-e", rsh, "--", remote, local},
+	}
+}
+
+// shellQuote quotes a string for a POSIX shell
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@,+", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// remoteWorkDir returns the remote working directory for a target
+func remoteWorkDir(t *SSHTarget) (string, error) {
+	if t.Dir != "" {
+		return t.Dir, nil
+	}
+	// Default to the same path as locally, e.g. a project synced to the same location
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(workDir), nil
+}
+
+// remoteTarget returns the SSH target an agent runs on, or nil for local agents
+func (e *InteractiveExecutor) remoteTarget(agent *workflow.Agent) (*SSHTarget, error) {
+	target, err := ParseTarget(agentTarget(e.workflow, agent))
+	if err != nil || target == nil {
+		return nil, err
+	}
+	if resolveSandbox(e.workflow.Settings.Sandbox, agent.Sandbox) != nil {
+		return nil, fmt.Errorf("agent %s: sandbox and ssh target can't be combined", agent.ID)
+	}
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("ssh targets are not supported on Windows")
+	}
+	if _, err := exec.LookPath("ssh"); err != nil {
+		return nil, fmt.Errorf("ssh not found in PATH")
+	}
+	return target, nil
+}
+
+// syncOutputs copies the workflow output directory to or from a remote target
+func (e *InteractiveExecutor) syncOutputs(target *SSHTarget, push bool) error {
+	if e.outputDir == "" {
+		return nil
+	}
+	if _, err := exec.LookPath("rsync"); err != nil {
+		return fmt.Errorf("rsync is required to sync outputs with %s", target.Host)
+	}
+
+	remoteDir, err := remoteWorkDir(target)
+	if err != nil {
+		return err
+	}
+
+	for _, args := range target.syncCommands(e.outputDir, target.remotePath(remoteDir, e.outputDir), push) {
+		// #nosec G204 -- ssh/rsync with arguments built from the workflow target
+		if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %v: %s", args[0], err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// remoteCommand prepares a provider command to run on a remote target:
+// it pushes earlier outputs, then wraps the command in ssh
+func (e *InteractiveExecutor) remoteCommand(target *SSHTarget, providerCmd string, providerArgs []string) (string, []string, error) {
+	if err := e.syncOutputs(target, true); err != nil {
+		return "", nil, err
+	}
+
+	remoteDir, err := remoteWorkDir(target)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fmt.Printf("🌐 Running %s on %s (%s)\n", providerCmd, target.destination(), remoteDir)
+	cmd, args := target.wrapCommand(remoteDir, providerCmd, providerArgs)
+	return cmd, args, nil
+}
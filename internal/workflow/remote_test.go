@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, local := range []string{"", "local"} {
+		target, err := ParseTarget(local)
+		require.NoError(t, err)
+		assert.Nil(t, target)
+	}
+
+	target, err := ParseTarget("ssh://dev@build-box:2222/srv/project")
+	require.NoError(t, err)
+	assert.Equal(t, &SSHTarget{User: "dev", Host: "build-box", Port: "2222", Dir: "/srv/project"}, target)
+	assert.Equal(t, "dev@build-box", target.destination())
+
+	target, err = ParseTarget("ssh://build-box")
+	require.NoError(t, err)
+	assert.Equal(t, "build-box", target.destination())
+	assert.Empty(t, target.sshOptions())
+
+	// build-box isn't a registered node, and hosts or users that look like
+	// ssh options are rejected
+	for _, invalid := range []string{"build-box", "http://build-box", "ssh://", "ssh://-oProxyCommand=evil", "ssh://-oProxyCommand=evil@build-box"} {
+		_, err := ParseTarget(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAgentRunsRemotely(t *testing.T) {
+	wf := &workflow.Workflow{Settings: workflow.Settings{Target: "ssh://dev@build-box"}}
+
+	assert.True(t, AgentRunsRemotely(wf, &workflow.Agent{}))
+	assert.False(t, AgentRunsRemotely(wf, &workflow.Agent{Target: "local"}))
+	assert.False(t, AgentRunsRemotely(&workflow.Workflow{}, &workflow.Agent{}))
+	assert.True(t, AgentRunsRemotely(&workflow.Workflow{}, &workflow.Agent{Target: "ssh://other"}))
+
+	// Bare names are nodes
+	assert.True(t, IsRemoteTarget("build-box"))
+
+	// Targets that aren't valid don't count as remote
+	for _, invalid := range []string{"http://build-box", "ssh://", "rizome://", "-build-box"} {
+		assert.False(t, IsRemoteTarget(invalid), invalid)
+	}
+}
+
+func TestSSHWrapCommand(t *testing.T) {
+	target := &SSHTarget{User: "dev", Host: "build-box", Port: "2222"}
+
+	cmd, args := target.wrapCommand("/srv/my project", "claude", []string{"--resume", "it's"})
+	assert.Equal(t, "ssh", cmd)
+	assert.Equal(t, []string{
+		"-t", "-p", "2222", "--", "dev@build-box",
+		`cd '/srv/my project' && exec claude --resume 'it'"'"'s'`,
+	}, args)
+}
+
+func TestSSHRemotePath(t *testing.T) {
+	target := &SSHTarget{Host: "build-box"}
+
+	assert.Equal(t, "/srv/project/output/run", target.remotePath("/srv/project", "./output/run"))
+	assert.Equal(t, "/tmp/output", target.remotePath("/srv/project", "/tmp/output"))
+}
+
+func TestSSHSyncCommands(t *testing.T) {
+	target := &SSHTarget{User: "dev", Host: "build-box", Port: "2222"}
+
+	push := target.syncCommands("output", "/srv/project/output", true)
+	assert.Equal(t, [][]string{
+		{"ssh", "-p", "2222", "--", "dev@build-box", "mkdir -p /srv/project/output"},
+		{"rsync", "-az", "-e", "ssh -p 2222", "--", "output/", "dev@build-box:/srv/project/output/"},
+	}, push)
+
+	pull := target.syncCommands("output/", "/srv/project/output", false)
+	assert.Equal(t, [][]string{
+		{"rsync", "-az", "-e", "ssh -p 2222", "--", "dev@build-box:/srv/project/output/", "output/"},
+	}, pull)
+}
@@ -0,0 +1,95 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// RequirementsEnvironment describes what is available to a workflow run
+type RequirementsEnvironment struct {
+	InstalledServers []string // MCP servers installed on this machine
+	EnabledServers   []string // MCP servers enabled for the project; nil allows any installed server
+	Actions          []string // IDs of available actions
+}
+
+// MissingRequirements lists the requirements of a workflow that are not met
+type MissingRequirements struct {
+	NotInstalled []string // MCP servers that are not installed
+	NotEnabled   []string // MCP servers that are installed but not enabled
+	Actions      []string // actions that don't exist
+}
+
+// Empty reports whether all requirements are met
+func (m MissingRequirements) Empty() bool {
+	return len(m.NotInstalled) == 0 && len(m.NotEnabled) == 0 && len(m.Actions) == 0
+}
+
+// Error describes the missing requirements
+func (m MissingRequirements) Error() string {
+	var lines []string
+	if len(m.NotInstalled) > 0 {
+		lines = append(lines, fmt.Sprintf("  - MCP servers not installed: %s", strings.Join(m.NotInstalled, ", ")))
+	}
+	if len(m.NotEnabled) > 0 {
+		lines = append(lines, fmt.Sprintf("  - MCP servers not enabled (add them to mcp_servers in config): %s", strings.Join(m.NotEnabled, ", ")))
+	}
+	if len(m.Actions) > 0 {
+		lines = append(lines, fmt.Sprintf("  - actions not found (add them with 'opun add'): %s", strings.Join(m.Actions, ", ")))
+	}
+	return "workflow requirements not met:\n" + strings.Join(lines, "\n")
+}
+
+// CheckRequirements compares a workflow's declared requirements with the environment
+func CheckRequirements(req workflow.Requirements, env RequirementsEnvironment) MissingRequirements {
+	installed := toSet(env.InstalledServers)
+	actions := toSet(env.Actions)
+
+	var missing MissingRequirements
+	for _, server := range req.MCPServers {
+		switch {
+		case !installed[server]:
+			missing.NotInstalled = append(missing.NotInstalled, server)
+		case env.EnabledServers != nil && !toSet(env.EnabledServers)[server]:
+			missing.NotEnabled = append(missing.NotEnabled, server)
+		}
+	}
+
+	for _, action := range req.Actions {
+		if !actions[action] {
+			missing.Actions = append(missing.Actions, action)
+		}
+	}
+
+	return missing
+}
+
+// HasRequirements reports whether a workflow declares any requirements
+func HasRequirements(wf *workflow.Workflow) bool {
+	return len(wf.Requires.MCPServers) > 0 || len(wf.Requires.Actions) > 0
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCheckRequirements(t *testing.T) {
+	var wf workflow.Workflow
+	err := yaml.Unmarshal([]byte(`
+name: review
+requires:
+  mcp_servers: [github, filesystem, memory]
+  actions: [run-tests, lint]
+agents:
+  - id: reviewer
+    provider: claude
+    prompt: Review the code
+`), &wf)
+	assert.NoError(t, err)
+	assert.True(t, HasRequirements(&wf))
+
+	t.Run("All Available", func(t *testing.T) {
+		missing := CheckRequirements(wf.Requires, RequirementsEnvironment{
+			InstalledServers: []string{"github", "filesystem", "memory"},
+			Actions:          []string{"run-tests", "lint"},
+		})
+		assert.True(t, missing.Empty())
+	})
+
+	t.Run("Reports Missing", func(t *testing.T) {
+		missing := CheckRequirements(wf.Requires, RequirementsEnvironment{
+			InstalledServers: []string{"github", "memory"},
+			EnabledServers:   []string{"github"},
+			Actions:          []string{"lint"},
+		})
+
+		assert.False(t, missing.Empty())
+		assert.Equal(t, []string{"filesystem"}, missing.NotInstalled)
+		assert.Equal(t, []string{"memory"}, missing.NotEnabled)
+		assert.Equal(t, []string{"run-tests"}, missing.Actions)
+		assert.Contains(t, missing.Error(), "MCP servers not installed: filesystem")
+	})
+
+	t.Run("No Requirements", func(t *testing.T) {
+		assert.False(t, HasRequirements(&workflow.Workflow{Name: "plain"}))
+	})
+}
@@ -0,0 +1,198 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Actions when a provider goes over max_memory_mb
+const (
+	MemoryLimitWarn = "warn"
+	MemoryLimitKill = "kill"
+)
+
+// resourceSampleInterval is how often provider processes are sampled
+const resourceSampleInterval = time.Second
+
+// errResourceSamplingUnsupported is returned where process usage can't be read
+var errResourceSamplingUnsupported = errors.New("process resource sampling is not supported on this platform")
+
+// ResourceUsage is what an agent's provider process tree used
+type ResourceUsage struct {
+	DurationSeconds float64 `json:"duration_seconds"`
+	CPUSeconds      float64 `json:"cpu_seconds,omitempty"`
+	PeakMemoryMB    float64 `json:"peak_memory_mb,omitempty"`
+	// Killed is set when the provider was stopped for going over max_memory_mb
+	Killed bool `json:"killed,omitempty"`
+}
+
+// String formats usage for the terminal
+func (u ResourceUsage) String() string {
+	s := time.Duration(u.DurationSeconds * float64(time.Second)).Round(time.Second).String()
+	if u.CPUSeconds > 0 || u.PeakMemoryMB > 0 {
+		s += fmt.Sprintf(", %.1fs CPU, %.0fMB peak memory", u.CPUSeconds, u.PeakMemoryMB)
+	}
+	return s
+}
+
+// processSample is the usage of a process and its descendants at one point
+type processSample struct {
+	cpu time.Duration
+	rss uint64 // bytes
+}
+
+// validateResourceLimits checks an agent's resource thresholds
+func validateResourceLimits(settings workflow.AgentSettings) error {
+	if settings.MaxMemoryMB < 0 {
+		return fmt.Errorf("max_memory_mb must not be negative, got %d", settings.MaxMemoryMB)
+	}
+	switch settings.OnMemoryLimit {
+	case "", MemoryLimitWarn, MemoryLimitKill:
+		return nil
+	default:
+		return fmt.Errorf("on_memory_limit must be warn or kill, got %q", settings.OnMemoryLimit)
+	}
+}
+
+// resourceMonitor samples a provider process tree while an agent runs and
+// enforces its memory threshold
+type resourceMonitor struct {
+	agent  string
+	pid    int
+	limit  uint64 // bytes, 0 for none
+	action string
+	sample func(pid int) (processSample, error)
+	kill   func()
+
+	mu     sync.Mutex
+	start  time.Time
+	usage  ResourceUsage
+	warned bool
+
+	stop chan struct{}
+	done chan struct{} // closed when sampling ends, nil until started
+}
+
+// newResourceMonitor creates a monitor for the provider process of an agent;
+// kill stops the provider when it goes over max_memory_mb
+func newResourceMonitor(agent *workflow.Agent, pid int, kill func()) *resourceMonitor {
+	action := agent.Settings.OnMemoryLimit
+	if action == "" {
+		action = MemoryLimitKill
+	}
+	return &resourceMonitor{
+		agent:  agent.Name,
+		pid:    pid,
+		limit:  uint64(agent.Settings.MaxMemoryMB) << 20,
+		action: action,
+		sample: sampleProcessTree,
+		kill:   kill,
+		start:  time.Now(),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start samples the process until Stop is called
+func (m *resourceMonitor) Start(interval time.Duration) {
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := m.check(); err != nil {
+				// Nothing more to learn: unsupported platform or the process is gone
+				return
+			}
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// check takes one sample and applies the memory threshold
+func (m *resourceMonitor) check() error {
+	s, err := m.sample(m.pid)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Descendants that exit take their CPU time with them, so keep the highest total seen
+	if cpu := s.cpu.Seconds(); cpu > m.usage.CPUSeconds {
+		m.usage.CPUSeconds = cpu
+	}
+	if mb := float64(s.rss) / (1 << 20); mb > m.usage.PeakMemoryMB {
+		m.usage.PeakMemoryMB = mb
+	}
+
+	if m.limit == 0 || s.rss <= m.limit || m.usage.Killed {
+		return nil
+	}
+	used, limit := s.rss>>20, m.limit>>20
+	if m.action == MemoryLimitKill {
+		fmt.Printf("\n🛑 %s is using %dMB, over max_memory_mb %d; stopping the provider\n", m.agent, used, limit)
+		m.usage.Killed = true
+		m.kill()
+	} else if !m.warned {
+		fmt.Printf("\n⚠️  %s is using %dMB, over max_memory_mb %d\n", m.agent, used, limit)
+		m.warned = true
+	}
+	return nil
+}
+
+// Stop ends sampling and returns the usage
+func (m *resourceMonitor) Stop() ResourceUsage {
+	close(m.stop)
+	if m.done != nil {
+		<-m.done
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.DurationSeconds = time.Since(m.start).Seconds()
+	return m.usage
+}
+
+// Killed reports whether the provider was stopped for going over its memory threshold
+func (m *resourceMonitor) Killed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage.Killed
+}
+
+// recordResources stores an agent's resource usage for the run manifest
+func (e *InteractiveExecutor) recordResources(agent *workflow.Agent, usage ResourceUsage) {
+	e.mu.Lock()
+	if e.resources == nil {
+		e.resources = make(map[string]*ResourceUsage)
+	}
+	e.resources[agent.ID] = &usage
+	e.mu.Unlock()
+
+	fmt.Printf("📊 %s: %s\n", agent.Name, usage)
+}
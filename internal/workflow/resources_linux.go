@@ -0,0 +1,123 @@
+//go:build linux
+
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of CPU times in /proc/<pid>/stat. It is 100
+// on every architecture Linux supports.
+const clockTicks = 100
+
+// procStat is what resource monitoring reads from /proc/<pid>/stat
+type procStat struct {
+	ppid     int
+	cpuTicks uint64 // user and system time, including reaped children
+	rssPages uint64
+}
+
+// parseProcStat parses the contents of /proc/<pid>/stat
+func parseProcStat(data string) (procStat, error) {
+	// The command name is in parentheses and may itself contain spaces or ')'
+	end := strings.LastIndexByte(data, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat")
+	}
+	// Fields after the name, starting with state (field 3 in proc(5))
+	fields := strings.Fields(data[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat")
+	}
+
+	var stat procStat
+	var err error
+	if stat.ppid, err = strconv.Atoi(fields[1]); err != nil {
+		return procStat{}, err
+	}
+	// utime, stime, cutime and cstime are fields 14-17
+	for _, field := range fields[11:15] {
+		ticks, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return procStat{}, err
+		}
+		if ticks > 0 {
+			stat.cpuTicks += uint64(ticks)
+		}
+	}
+	// rss is field 24
+	if stat.rssPages, err = strconv.ParseUint(fields[21], 10, 64); err != nil {
+		return procStat{}, err
+	}
+	return stat, nil
+}
+
+// sampleProcessTree sums the CPU time and resident memory of a process and
+// all of its descendants, e.g. the node processes a provider CLI starts
+func sampleProcessTree(pid int) (processSample, error) {
+	paths, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return processSample{}, err
+	}
+
+	stats := make(map[int]procStat, len(paths))
+	children := make(map[int][]int)
+	for _, path := range paths {
+		id, err := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+		if err != nil {
+			continue
+		}
+		// Processes may exit between listing and reading
+		// #nosec G304 -- path is a /proc stat file
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		stat, err := parseProcStat(string(data))
+		if err != nil {
+			continue
+		}
+		stats[id] = stat
+		children[stat.ppid] = append(children[stat.ppid], id)
+	}
+
+	if _, ok := stats[pid]; !ok {
+		return processSample{}, fmt.Errorf("process %d not found", pid)
+	}
+
+	var ticks, pages uint64
+	queue := []int{pid}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		ticks += stats[id].cpuTicks
+		pages += stats[id].rssPages
+		queue = append(queue, children[id]...)
+	}
+
+	return processSample{
+		cpu: time.Duration(ticks) * time.Second / clockTicks,
+		rss: pages * uint64(os.Getpagesize()),
+	}, nil
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package workflow
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcStat(t *testing.T) {
+	// Fields 1-24 of proc(5), with a command name containing spaces and ')'
+	stat, err := parseProcStat("1234 (node (v20) x) S 1200 1234 1234 0 -1 4194560 500 0 0 0 150 50 10 5 20 0 11 0 100 1000000 2048 18446744073709551615\n")
+	require.NoError(t, err)
+	assert.Equal(t, 1200, stat.ppid)
+	assert.Equal(t, uint64(215), stat.cpuTicks)
+	assert.Equal(t, uint64(2048), stat.rssPages)
+
+	_, err = parseProcStat("1234 (short) S 1")
+	assert.Error(t, err)
+}
+
+func TestSampleProcessTree(t *testing.T) {
+	sample, err := sampleProcessTree(os.Getpid())
+	require.NoError(t, err)
+	assert.Greater(t, int(sample.rss), 0)
+
+	_, err = sampleProcessTree(-1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResourceLimits(t *testing.T) {
+	assert.NoError(t, validateResourceLimits(workflow.AgentSettings{}))
+	assert.NoError(t, validateResourceLimits(workflow.AgentSettings{MaxMemoryMB: 2048, OnMemoryLimit: "warn"}))
+	assert.Error(t, validateResourceLimits(workflow.AgentSettings{MaxMemoryMB: -1}))
+	assert.EqualError(t, validateResourceLimits(workflow.AgentSettings{OnMemoryLimit: "restart"}),
+		`on_memory_limit must be warn or kill, got "restart"`)
+}
+
+func testMonitor(settings workflow.AgentSettings, samples ...processSample) (*resourceMonitor, *int) {
+	kills := 0
+	m := newResourceMonitor(&workflow.Agent{Name: "build", Settings: settings}, 42, func() { kills++ })
+	m.sample = func(pid int) (processSample, error) {
+		s := samples[0]
+		if len(samples) > 1 {
+			samples = samples[1:]
+		}
+		return s, nil
+	}
+	return m, &kills
+}
+
+func TestResourceMonitorUsage(t *testing.T) {
+	m, kills := testMonitor(workflow.AgentSettings{},
+		processSample{cpu: 2 * time.Second, rss: 300 << 20},
+		processSample{cpu: 5 * time.Second, rss: 500 << 20},
+		processSample{cpu: 4 * time.Second, rss: 100 << 20},
+	)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, m.check())
+	}
+
+	usage := m.Stop()
+	assert.Equal(t, 5.0, usage.CPUSeconds, "highest total seen")
+	assert.Equal(t, 500.0, usage.PeakMemoryMB)
+	assert.False(t, usage.Killed)
+	assert.Zero(t, *kills)
+}
+
+func TestResourceMonitorMemoryLimit(t *testing.T) {
+	over := processSample{rss: 600 << 20}
+
+	m, kills := testMonitor(workflow.AgentSettings{MaxMemoryMB: 512}, processSample{rss: 100 << 20}, over, over)
+	require.NoError(t, m.check())
+	assert.False(t, m.Killed())
+	require.NoError(t, m.check())
+	require.NoError(t, m.check())
+	assert.True(t, m.Killed())
+	assert.Equal(t, 1, *kills, "killed once")
+	assert.True(t, m.Stop().Killed)
+
+	m, kills = testMonitor(workflow.AgentSettings{MaxMemoryMB: 512, OnMemoryLimit: MemoryLimitWarn}, over)
+	require.NoError(t, m.check())
+	assert.False(t, m.Killed())
+	assert.Zero(t, *kills)
+	m.Stop()
+}
+
+func TestResourceUsageString(t *testing.T) {
+	assert.Equal(t, "1m5s", ResourceUsage{DurationSeconds: 65.2}.String())
+	assert.Equal(t, "10s, 3.5s CPU, 256MB peak memory",
+		ResourceUsage{DurationSeconds: 10, CPUSeconds: 3.5, PeakMemoryMB: 256}.String())
+}
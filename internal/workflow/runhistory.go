@@ -0,0 +1,322 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// historyDirName is the directory under RunsDir keeping a record of every
+// finished run, so runs can be looked up by ID once their state file is gone
+const historyDirName = "history"
+
+// definitionsDirName is the directory under the run history keeping the
+// workflow each run ran, so its bundle has the exact definition
+const definitionsDirName = "definitions"
+
+// Feedback ratings range from MinRating to MaxRating
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// RunRecord is a run's manifest as kept in the run history, with the
+// feedback given on it
+type RunRecord struct {
+	RunManifest
+	OutputDir string     `json:"output_dir,omitempty"`
+	Feedback  []Feedback `json:"feedback,omitempty"`
+}
+
+// Feedback is a user's rating of a run, or of one of its agents
+type Feedback struct {
+	// Agent is empty for feedback on the run as a whole
+	Agent    string    `json:"agent,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Rating   int       `json:"rating"`
+	Note     string    `json:"note,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// FeedbackSummary is the average rating of one agent of a workflow
+type FeedbackSummary struct {
+	Workflow string  `json:"workflow"`
+	Agent    string  `json:"agent,omitempty"`
+	Provider string  `json:"provider,omitempty"`
+	Model    string  `json:"model,omitempty"`
+	Ratings  int     `json:"ratings"`
+	Average  float64 `json:"average"`
+}
+
+// HistoryDir returns the directory holding the records of finished runs
+func HistoryDir() (string, error) {
+	runsDir, err := RunsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(runsDir, historyDirName), nil
+}
+
+// saveRunRecord writes a run's manifest to dir/<run-id>.json, keeping the
+// feedback already given on it
+func saveRunRecord(dir string, m *RunManifest, outputDir string) error {
+	record := &RunRecord{RunManifest: *m, OutputDir: outputDir}
+	if existing, err := LoadRunRecord(dir, m.RunID); err == nil {
+		record.Feedback = existing.Feedback
+	}
+	return writeRunRecord(dir, record)
+}
+
+// writeRunRecord atomically replaces a run record
+func writeRunRecord(dir string, record *RunRecord) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, pathSafe(record.RunID)+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// DefinitionPath returns where the workflow a run ran is kept
+func DefinitionPath(runID string) (string, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, definitionsDirName, pathSafe(runID)+".yaml"), nil
+}
+
+// saveRunDefinition keeps the workflow of a run once, when it is first recorded
+func saveRunDefinition(path string, wf *workflow.Workflow) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(wf)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// Prompts and defaults may hold private details, keep them to the user
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadRunRecord reads the record of a run from dir
+func LoadRunRecord(dir, runID string) (*RunRecord, error) {
+	data, err := os.ReadFile(filepath.Join(dir, pathSafe(runID)+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run %s not found", runID)
+		}
+		return nil, err
+	}
+	var record RunRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid record of run %s: %w", runID, err)
+	}
+	return &record, nil
+}
+
+// ListRunRecords returns the recorded runs in dir, oldest first
+func ListRunRecords(dir string) ([]*RunRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var records []*RunRecord
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		record, err := LoadRunRecord(dir, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartedAt.Before(records[j].StartedAt)
+	})
+	return records, nil
+}
+
+// AddFeedback attaches feedback to a recorded run. An agent, given by ID or
+// name, must be one of the run's; its provider and model are filled in.
+func AddFeedback(dir, runID string, feedback Feedback) (*RunRecord, error) {
+	if feedback.Rating < MinRating || feedback.Rating > MaxRating {
+		return nil, fmt.Errorf("rating must be between %d and %d", MinRating, MaxRating)
+	}
+	record, err := LoadRunRecord(dir, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	if feedback.Agent != "" {
+		agent := record.findAgent(feedback.Agent)
+		if agent == nil {
+			return nil, fmt.Errorf("run %s has no agent %s", runID, feedback.Agent)
+		}
+		feedback.Agent = agent.ID
+		feedback.Provider = agent.Provider
+		feedback.Model = agent.Model
+	}
+	if feedback.Time.IsZero() {
+		feedback.Time = time.Now()
+	}
+
+	record.Feedback = append(record.Feedback, feedback)
+	if err := writeRunRecord(dir, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// findAgent returns the agent of the run with the given ID or name
+func (r *RunRecord) findAgent(idOrName string) *ManifestAgent {
+	for i := range r.Agents {
+		if r.Agents[i].ID == idOrName || strings.EqualFold(r.Agents[i].Name, idOrName) {
+			return &r.Agents[i]
+		}
+	}
+	return nil
+}
+
+// SummarizeFeedback averages the ratings in the records per workflow agent,
+// best rated first. Feedback on whole runs is listed without an agent.
+func SummarizeFeedback(records []*RunRecord) []FeedbackSummary {
+	type key struct{ workflow, agent, provider, model string }
+	totals := make(map[key]*FeedbackSummary)
+	for _, record := range records {
+		for _, fb := range record.Feedback {
+			k := key{record.Workflow, fb.Agent, fb.Provider, fb.Model}
+			summary, ok := totals[k]
+			if !ok {
+				summary = &FeedbackSummary{Workflow: k.workflow, Agent: k.agent, Provider: k.provider, Model: k.model}
+				totals[k] = summary
+			}
+			summary.Ratings++
+			summary.Average += float64(fb.Rating)
+		}
+	}
+
+	summaries := make([]FeedbackSummary, 0, len(totals))
+	for _, summary := range totals {
+		summary.Average /= float64(summary.Ratings)
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Average != summaries[j].Average {
+			return summaries[i].Average > summaries[j].Average
+		}
+		if summaries[i].Workflow != summaries[j].Workflow {
+			return summaries[i].Workflow < summaries[j].Workflow
+		}
+		return summaries[i].Agent < summaries[j].Agent
+	})
+	return summaries
+}
+
+// recordRun keeps the run's manifest in the run history
+func (e *InteractiveExecutor) recordRun(m *RunManifest) {
+	if m.RunID == "" {
+		return
+	}
+	dir, err := HistoryDir()
+	if err != nil {
+		return
+	}
+	if err := saveRunRecord(dir, m, e.outputDir); err != nil {
+		fmt.Printf("⚠️  Failed to record run in history: %v\n", err)
+	}
+	if path, err := DefinitionPath(m.RunID); err == nil && e.workflow != nil {
+		_ = saveRunDefinition(path, e.workflow)
+	}
+}
+
+// recordHeadlessRun keeps a headless run in the run history, with the usage
+// its providers reported
+func (r *MatrixRunner) recordHeadlessRun(wf *workflow.Workflow, vars map[string]interface{}, started time.Time, result MatrixResult) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return
+	}
+
+	finished := time.Now()
+	m := &RunManifest{
+		RunID:           r.RunID,
+		OpunVersion:     OpunVersion,
+		OpunCommit:      OpunCommit,
+		Platform:        runtime.GOOS + "/" + runtime.GOARCH,
+		Workflow:        wf.Name,
+		WorkflowVersion: wf.Version,
+		WorkflowHash:    WorkflowHash(wf),
+		Status:          result.Status,
+		StartedAt:       started,
+		FinishedAt:      &finished,
+		Variables:       redactVariables(vars),
+		Error:           result.Error,
+		ErrorClass:      result.ErrorClass,
+		Chaos:           result.Chaos,
+	}
+	statuses := result.AgentStatuses(wf)
+	for i := range wf.Agents {
+		agent := &wf.Agents[i]
+		manifestAgent := ManifestAgent{
+			ID:       agent.ID,
+			Name:     agent.Name,
+			Provider: agent.Provider,
+			Model:    agent.Model,
+			Status:   string(statuses[agent.ID]),
+			Output:   result.Outputs[agent.ID],
+		}
+		if usage, ok := result.Usage[agent.ID]; ok {
+			manifestAgent.Usage = &usage
+		}
+		m.Agents = append(m.Agents, manifestAgent)
+	}
+
+	if err := saveRunRecord(dir, m, r.OutputDir); err != nil {
+		fmt.Printf("⚠️  Failed to record run in history: %v\n", err)
+	}
+	if path, err := DefinitionPath(m.RunID); err == nil {
+		_ = saveRunDefinition(path, wf)
+	}
+}
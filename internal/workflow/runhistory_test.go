@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHistoryFeedback(t *testing.T) {
+	dir := t.TempDir()
+	manifest := &RunManifest{
+		RunID:     "42-1000",
+		Workflow:  "review",
+		Status:    "completed",
+		StartedAt: time.Now(),
+		Agents: []ManifestAgent{
+			{ID: "plan", Name: "Planner", Provider: "claude", Model: "opus"},
+			{ID: "check", Name: "Checker", Provider: "gemini"},
+		},
+	}
+	require.NoError(t, saveRunRecord(dir, manifest, "/tmp/out"))
+
+	record, err := AddFeedback(dir, "42-1000", Feedback{Agent: "checker", Rating: 2, Note: "missed edge case"})
+	require.NoError(t, err)
+	require.Len(t, record.Feedback, 1)
+	assert.Equal(t, "check", record.Feedback[0].Agent)
+	assert.Equal(t, "gemini", record.Feedback[0].Provider)
+	assert.False(t, record.Feedback[0].Time.IsZero())
+
+	_, err = AddFeedback(dir, "42-1000", Feedback{Agent: "plan", Rating: 5})
+	require.NoError(t, err)
+	_, err = AddFeedback(dir, "42-1000", Feedback{Rating: 4})
+	require.NoError(t, err)
+
+	_, err = AddFeedback(dir, "42-1000", Feedback{Rating: 6})
+	assert.Error(t, err)
+	_, err = AddFeedback(dir, "42-1000", Feedback{Agent: "deploy", Rating: 3})
+	assert.ErrorContains(t, err, "no agent deploy")
+	_, err = AddFeedback(dir, "missing", Feedback{Rating: 3})
+	assert.ErrorContains(t, err, "run missing not found")
+
+	// Rewriting the record when the run ends keeps its feedback
+	manifest.Status = "failed"
+	require.NoError(t, saveRunRecord(dir, manifest, "/tmp/out"))
+	record, err = LoadRunRecord(dir, "42-1000")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", record.Status)
+	assert.Equal(t, "/tmp/out", record.OutputDir)
+	assert.Len(t, record.Feedback, 3)
+
+	records, err := ListRunRecords(dir)
+	require.NoError(t, err)
+	summaries := SummarizeFeedback(records)
+	assert.Equal(t, []FeedbackSummary{
+		{Workflow: "review", Agent: "plan", Provider: "claude", Model: "opus", Ratings: 1, Average: 5},
+		{Workflow: "review", Ratings: 1, Average: 4},
+		{Workflow: "review", Agent: "check", Provider: "gemini", Ratings: 1, Average: 2},
+	}, summaries)
+}
+
+func TestWriteRunManifestRecordsRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	e := NewInteractiveExecutor()
+	e.workflow = manifestWorkflow()
+	e.runID = "7-2000"
+	e.state = &workflow.ExecutionState{
+		Status:      workflow.StatusCompleted,
+		StartTime:   time.Now(),
+		AgentStates: map[string]*workflow.AgentState{},
+	}
+	e.writeRunManifest()
+
+	dir, err := HistoryDir()
+	require.NoError(t, err)
+	record, err := LoadRunRecord(dir, "7-2000")
+	require.NoError(t, err)
+	assert.Equal(t, "review", record.Workflow)
+	assert.Len(t, record.Agents, 2)
+	assert.Empty(t, record.OutputDir)
+
+	// Runs without an output directory don't write a manifest
+	_, err = os.Stat(filepath.Join(home, ManifestFile))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,158 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMaxConcurrentRuns is how many workflows a Manager runs at once
+// unless SetMaxConcurrent changes it. Runs drive provider sessions, so by
+// default they take turns.
+const DefaultMaxConcurrentRuns = 1
+
+// QueueStatus is a snapshot of a run queue
+type QueueStatus struct {
+	Running       int `json:"running"`
+	Queued        int `json:"queued"`
+	MaxConcurrent int `json:"max_concurrent"`
+}
+
+// queueTicket is a run waiting in a queue
+type queueTicket struct {
+	granted bool
+}
+
+// RunQueue limits how many runs go at once. Runs over the limit wait in
+// the order they arrived.
+type RunQueue struct {
+	mu      sync.Mutex
+	max     int
+	running int
+	waiting []*queueTicket
+	// changed is closed and replaced whenever the queue moves
+	changed chan struct{}
+}
+
+// NewRunQueue creates a queue running at most max runs at once, no limit
+// when max is zero or less
+func NewRunQueue(max int) *RunQueue {
+	return &RunQueue{max: max, changed: make(chan struct{})}
+}
+
+// SetMax changes how many runs go at once, starting queued runs when it
+// grows
+func (q *RunQueue) SetMax(max int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.max = max
+	q.dispatch()
+}
+
+// Status returns how many runs are running and queued
+func (q *RunQueue) Status() QueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QueueStatus{Running: q.running, Queued: len(q.waiting), MaxConcurrent: q.max}
+}
+
+// Acquire waits for a run's turn and returns the function that ends it.
+// onQueued, which may be nil, is called with the run's 1-based position
+// and the queue's status each time the position changes while it waits.
+func (q *RunQueue) Acquire(ctx context.Context, onQueued func(position int, status QueueStatus)) (func(), error) {
+	ticket := &queueTicket{}
+	q.mu.Lock()
+	q.waiting = append(q.waiting, ticket)
+	q.dispatch()
+	q.mu.Unlock()
+
+	last := 0
+	for {
+		q.mu.Lock()
+		if ticket.granted {
+			q.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(q.release) }, nil
+		}
+		position := q.position(ticket)
+		status := QueueStatus{Running: q.running, Queued: len(q.waiting), MaxConcurrent: q.max}
+		changed := q.changed
+		q.mu.Unlock()
+
+		if position != last && onQueued != nil {
+			onQueued(position, status)
+		}
+		last = position
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			q.mu.Lock()
+			if ticket.granted {
+				// The turn came as the run gave up, pass it on
+				q.running--
+			} else {
+				q.remove(ticket)
+			}
+			q.dispatch()
+			q.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release ends a run and starts the next one
+func (q *RunQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running--
+	q.dispatch()
+}
+
+// dispatch starts queued runs while there is room and tells waiters the
+// queue moved. Callers must hold the lock.
+func (q *RunQueue) dispatch() {
+	for len(q.waiting) > 0 && (q.max <= 0 || q.running < q.max) {
+		q.waiting[0].granted = true
+		q.waiting = q.waiting[1:]
+		q.running++
+	}
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// position returns a waiting ticket's 1-based place in the queue. Callers
+// must hold the lock.
+func (q *RunQueue) position(ticket *queueTicket) int {
+	for i, t := range q.waiting {
+		if t == ticket {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// remove drops a ticket from the queue. Callers must hold the lock.
+func (q *RunQueue) remove(ticket *queueTicket) {
+	for i, t := range q.waiting {
+		if t == ticket {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunQueue(t *testing.T) {
+	q := NewRunQueue(1)
+
+	release, err := q.Acquire(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, QueueStatus{Running: 1, MaxConcurrent: 1}, q.Status())
+
+	// Two more runs wait in arrival order
+	var (
+		mu        sync.Mutex
+		order     []string
+		positions = make(map[string][]int)
+	)
+	var wg sync.WaitGroup
+	start := func(name string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := q.Acquire(context.Background(), func(position int, status QueueStatus) {
+				mu.Lock()
+				positions[name] = append(positions[name], position)
+				mu.Unlock()
+			})
+			require.NoError(t, err)
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			done()
+		}()
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(positions[name]) > 0
+		}, time.Second, time.Millisecond)
+	}
+	start("second")
+	start("third")
+	assert.Equal(t, QueueStatus{Running: 1, Queued: 2, MaxConcurrent: 1}, q.Status())
+
+	release()
+	release() // A second release is ignored
+	wg.Wait()
+
+	assert.Equal(t, []string{"second", "third"}, order)
+	assert.Equal(t, []int{1}, positions["second"])
+	assert.Equal(t, 2, positions["third"][0])
+	assert.Equal(t, QueueStatus{MaxConcurrent: 1}, q.Status())
+}
+
+func TestRunQueueCancel(t *testing.T) {
+	q := NewRunQueue(1)
+	release, err := q.Acquire(context.Background(), nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = q.Acquire(ctx, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, QueueStatus{Running: 1, MaxConcurrent: 1}, q.Status())
+
+	release()
+	assert.Equal(t, 0, q.Status().Running)
+}
+
+func TestRunQueueSetMax(t *testing.T) {
+	q := NewRunQueue(1)
+	release, err := q.Acquire(context.Background(), nil)
+	require.NoError(t, err)
+	defer release()
+
+	started := make(chan func())
+	go func() {
+		done, _ := q.Acquire(context.Background(), nil)
+		started <- done
+	}()
+	require.Eventually(t, func() bool { return q.Status().Queued == 1 }, time.Second, time.Millisecond)
+
+	// Raising the limit starts the queued run
+	q.SetMax(2)
+	select {
+	case done := <-started:
+		done()
+	case <-time.After(time.Second):
+		t.Fatal("queued run didn't start")
+	}
+
+	// No limit
+	q.SetMax(0)
+	for i := 0; i < 5; i++ {
+		_, err := q.Acquire(context.Background(), nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 6, q.Status().Running)
+}
+
+func TestManagerQueuesRuns(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxConcurrentRuns, m.QueueStatus().MaxConcurrent)
+
+	// Hold the only slot so the run has to wait its turn
+	release, err := m.queue.Acquire(context.Background(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(m.workflowDir, "review.yaml"), []byte("name: review\nagents: []\n"), 0644))
+	queued := make(chan map[string]interface{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.ExecuteWithProgress(ctx, "review", nil, func(event workflow.WorkflowEvent) {
+			if event.Type == workflow.EventWorkflowQueued {
+				queued <- event.Data
+			}
+		})
+		done <- err
+	}()
+
+	select {
+	case data := <-queued:
+		assert.Equal(t, 1, data["position"])
+		assert.Equal(t, 1, data["running"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("run wasn't queued")
+	}
+
+	// A queued run shows in `opun status`
+	runsDir, err := RunsDir()
+	require.NoError(t, err)
+	runs, err := ListRuns(runsDir)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, RunQueued, runs[0].Status)
+	assert.Equal(t, 1, runs[0].QueuePosition)
+
+	// Giving up while queued leaves the queue as it was
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, QueueStatus{Running: 1, MaxConcurrent: 1}, m.QueueStatus())
+	release()
+}
@@ -0,0 +1,254 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/utils"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// RunStatus is the progress of a running workflow, persisted so other
+// terminals can see it with `opun status`
+type RunStatus struct {
+	ID             string     `json:"id"`
+	PID            int        `json:"pid"`
+	Workflow       string     `json:"workflow"`
+	WorkDir        string     `json:"work_dir"`
+	Status         string     `json:"status"`
+	CurrentAgent   string     `json:"current_agent,omitempty"`
+	Provider       string     `json:"provider,omitempty"`
+	AgentIndex     int        `json:"agent_index"`
+	TotalAgents    int        `json:"total_agents"`
+	Completed      int        `json:"completed"`
+	StartTime      time.Time  `json:"start_time"`
+	AgentStartTime *time.Time `json:"agent_start_time,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Error          string     `json:"error,omitempty"`
+	// Socket is set for runs started with --detach, for `opun attach`
+	Socket string `json:"socket,omitempty"`
+	// Lock is the workflow's named lock, held or, while Status is waiting, queued for
+	Lock string `json:"lock,omitempty"`
+	// QueuePosition is the run's place in its manager's queue while Status is queued
+	QueuePosition int `json:"queue_position,omitempty"`
+}
+
+// RunWaiting is the status of a run queued for a lock another run holds
+const RunWaiting = "waiting"
+
+// RunQueued is the status of a run waiting for its manager to have room for it
+const RunQueued = "queued"
+
+// RunsDir returns the directory holding the state files of running workflows
+func RunsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "runs"), nil
+}
+
+// RunTracker keeps a workflow's state file up to date from executor events
+type RunTracker struct {
+	mu     sync.Mutex
+	path   string
+	status RunStatus
+}
+
+// NewRunID returns a new unique run ID
+func NewRunID() string {
+	// One process (e.g. the MCP server) may run several workflows at once
+	return fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+}
+
+// NewRunTracker creates a tracker writing to <dir>/<id>.json.
+// An empty id generates a new one.
+func NewRunTracker(dir, id, workflowName string) *RunTracker {
+	workDir, _ := os.Getwd()
+	now := time.Now()
+	if id == "" {
+		id = NewRunID()
+	}
+
+	return &RunTracker{
+		path: filepath.Join(dir, id+".json"),
+		status: RunStatus{
+			ID:        id,
+			PID:       os.Getpid(),
+			Workflow:  workflowName,
+			WorkDir:   workDir,
+			Status:    string(workflow.StatusRunning),
+			StartTime: now,
+			UpdatedAt: now,
+		},
+	}
+}
+
+// ID returns the run's ID
+func (t *RunTracker) ID() string {
+	return t.status.ID
+}
+
+// HandleEvent updates the run state from an executor event
+func (t *RunTracker) HandleEvent(event workflow.WorkflowEvent) {
+	// Terminal output doesn't change the run's status
+	if event.Type == workflow.EventOutputChunk {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Type {
+	case workflow.EventWorkflowStart:
+		if n, ok := event.Data["total_agents"].(int); ok {
+			t.status.TotalAgents = n
+		}
+	case workflow.EventAgentStart:
+		t.status.CurrentAgent = event.AgentID
+		if name, ok := event.Data["name"].(string); ok && name != "" {
+			t.status.CurrentAgent = name
+		}
+		t.status.Provider, _ = event.Data["provider"].(string)
+		if i, ok := event.Data["index"].(int); ok {
+			t.status.AgentIndex = i
+		}
+		started := event.Timestamp
+		t.status.AgentStartTime = &started
+	case workflow.EventAgentComplete, workflow.EventAgentSkipped:
+		t.status.Completed++
+	case workflow.EventWorkflowComplete:
+		t.status.Status = string(workflow.StatusCompleted)
+	case workflow.EventWorkflowError:
+		t.status.Status = string(workflow.StatusFailed)
+		t.status.Error = event.Message
+	}
+
+	t.status.UpdatedAt = event.Timestamp
+	_ = t.write()
+}
+
+// SetAttachSocket records the socket `opun attach` connects to
+func (t *RunTracker) SetAttachSocket(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.Socket = path
+	_ = t.write()
+}
+
+// SetLock records the run's lock, and whether it is still waiting for it
+func (t *RunTracker) SetLock(name string, waiting bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.Lock = name
+	if waiting {
+		t.status.Status = RunWaiting
+	} else {
+		t.status.Status = string(workflow.StatusRunning)
+	}
+	t.status.UpdatedAt = time.Now()
+	_ = t.write()
+}
+
+// SetQueued records the run's place in its manager's queue, 0 once it starts
+func (t *RunTracker) SetQueued(position int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.QueuePosition = position
+	if position > 0 {
+		t.status.Status = RunQueued
+	} else {
+		t.status.Status = string(workflow.StatusRunning)
+	}
+	t.status.UpdatedAt = time.Now()
+	_ = t.write()
+}
+
+// Close removes the state file once the run is over
+func (t *RunTracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = os.Remove(t.path)
+}
+
+// write atomically replaces the state file. Callers must hold the lock.
+func (t *RunTracker) write() error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(t.status, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+// ListRuns returns the workflows that are currently running, oldest first.
+// State files left behind by processes that no longer exist are removed.
+func ListRuns(dir string) ([]RunStatus, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var runs []RunStatus
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var run RunStatus
+		if err := json.Unmarshal(data, &run); err != nil {
+			continue
+		}
+
+		if !utils.ProcessAlive(run.PID) {
+			_ = os.Remove(path)
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartTime.Before(runs[j].StartTime)
+	})
+
+	return runs, nil
+}
@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTracker(t *testing.T) {
+	dir := t.TempDir()
+	tracker := NewRunTracker(dir, "", "review")
+
+	now := time.Now()
+	tracker.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowStart, Timestamp: now, Data: map[string]interface{}{"total_agents": 3}})
+	tracker.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, AgentID: "plan", Timestamp: now, Data: map[string]interface{}{"index": 0, "name": "Planner", "provider": "claude"}})
+	tracker.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentComplete, AgentID: "plan", Timestamp: now})
+	tracker.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, AgentID: "build", Timestamp: now, Data: map[string]interface{}{"index": 1}})
+
+	runs, err := ListRuns(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	run := runs[0]
+	assert.Equal(t, os.Getpid(), run.PID)
+	assert.NotEmpty(t, run.ID)
+	assert.Equal(t, "review", run.Workflow)
+	assert.Equal(t, "running", run.Status)
+	assert.Equal(t, "build", run.CurrentAgent, "falls back to the agent ID")
+	assert.Equal(t, 1, run.AgentIndex)
+	assert.Equal(t, 3, run.TotalAgents)
+	assert.Equal(t, 1, run.Completed)
+	require.NotNil(t, run.AgentStartTime)
+
+	tracker.Close()
+	runs, err = ListRuns(dir)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestListRunsRemovesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	data, err := json.Marshal(RunStatus{PID: -1, Workflow: "gone"})
+	require.NoError(t, err)
+	stale := filepath.Join(dir, "stale.json")
+	require.NoError(t, os.WriteFile(stale, data, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644))
+
+	runs, err := ListRuns(dir)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.NoFileExists(t, stale)
+
+	runs, err = ListRuns(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
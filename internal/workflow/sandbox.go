@@ -0,0 +1,181 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// sandboxHome is the home directory used inside sandbox containers
+const sandboxHome = "/home/opun"
+
+// sandboxCredentialPaths are the provider login files mounted into sandboxes,
+// relative to the home directory
+var sandboxCredentialPaths = map[string][]string{
+	"claude": {".claude", ".claude.json"},
+	"gemini": {".gemini"},
+	"qwen":   {".qwen"},
+}
+
+// sandboxEnvVars are host environment variables passed into every sandbox
+var sandboxEnvVars = []string{
+	"ANTHROPIC_API_KEY",
+	"GEMINI_API_KEY",
+	"GOOGLE_API_KEY",
+	"OPENAI_API_KEY",
+	"DASHSCOPE_API_KEY",
+}
+
+// resolveSandbox returns the sandbox for an agent; agent settings override the
+// workflow's, and runtime "none" turns sandboxing off
+func resolveSandbox(global, agent *workflow.Sandbox) *workflow.Sandbox {
+	sandbox := global
+	if agent != nil {
+		if global != nil && agent.Image == "" {
+			// `sandbox: podman` on an agent keeps the workflow's image and policy
+			merged := *global
+			merged.Runtime = agent.Runtime
+			agent = &merged
+		}
+		sandbox = agent
+	}
+
+	if sandbox == nil || sandbox.Runtime == "" || sandbox.Runtime == "none" {
+		return nil
+	}
+	return sandbox
+}
+
+// sandboxOptions are the host paths a sandboxed provider needs
+type sandboxOptions struct {
+	provider  string
+	workDir   string
+	outputDir string
+	homeDir   string
+	lookupEnv func(string) (string, bool)
+}
+
+// buildSandboxCommand wraps a provider command in a container run.
+// The project is mounted read-write at the same path so @file references and
+// output paths keep working inside the container.
+func buildSandboxCommand(sandbox *workflow.Sandbox, opts sandboxOptions, providerCmd string, providerArgs []string) (string, []string, error) {
+	switch sandbox.Runtime {
+	case "docker", "podman":
+	default:
+		return "", nil, fmt.Errorf("unsupported sandbox runtime: %s (use docker or podman)", sandbox.Runtime)
+	}
+	if sandbox.Image == "" {
+		return "", nil, fmt.Errorf("sandbox image is required; use an image that contains the %s CLI", opts.provider)
+	}
+
+	network := sandbox.Network
+	if network == "" {
+		network = "bridge"
+	}
+
+	args := []string{"run", "--rm", "-i", "-t", "--network", network}
+
+	// Run as the host user so files written to the project aren't owned by root
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+
+	args = append(args, "-v", opts.workDir+":"+opts.workDir, "-w", opts.workDir, "-e", "HOME="+sandboxHome)
+	if opts.outputDir != "" && !isWithin(opts.outputDir, opts.workDir) {
+		args = append(args, "-v", opts.outputDir+":"+opts.outputDir)
+	}
+
+	if sandbox.MountCredentials == nil || *sandbox.MountCredentials {
+		for _, rel := range sandboxCredentialPaths[opts.provider] {
+			hostPath := filepath.Join(opts.homeDir, rel)
+			if _, err := os.Stat(hostPath); err == nil {
+				args = append(args, "-v", hostPath+":"+sandboxHome+"/"+rel)
+			}
+		}
+	}
+
+	for _, mount := range sandbox.Mounts {
+		if strings.HasPrefix(mount, "~/") {
+			mount = filepath.Join(opts.homeDir, mount[2:])
+		}
+		args = append(args, "-v", mount)
+	}
+
+	// Pass variables by name only so their values don't show up in process listings
+	for _, name := range append(append([]string{}, sandboxEnvVars...), sandbox.Env...) {
+		if _, ok := opts.lookupEnv(name); ok {
+			args = append(args, "-e", name)
+		}
+	}
+
+	args = append(args, sandbox.Image, providerCmd)
+	args = append(args, providerArgs...)
+
+	return sandbox.Runtime, args, nil
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sandboxCommand wraps an agent's provider command when sandboxing is configured
+func (e *InteractiveExecutor) sandboxCommand(agent *workflow.Agent, providerCmd string, providerArgs []string) (string, []string, error) {
+	sandbox := resolveSandbox(e.workflow.Settings.Sandbox, agent.Sandbox)
+	if sandbox == nil {
+		return providerCmd, providerArgs, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		return "", nil, fmt.Errorf("container sandboxes are not supported on Windows")
+	}
+	if _, err := exec.LookPath(sandbox.Runtime); err != nil {
+		return "", nil, fmt.Errorf("sandbox runtime %s not found in PATH", sandbox.Runtime)
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	homeDir, _ := os.UserHomeDir()
+	outputDir := e.outputDir
+	if outputDir != "" && !filepath.IsAbs(outputDir) {
+		outputDir = filepath.Join(workDir, outputDir)
+	}
+
+	cmd, args, err := buildSandboxCommand(sandbox, sandboxOptions{
+		provider:  agent.Provider,
+		workDir:   workDir,
+		outputDir: outputDir,
+		homeDir:   homeDir,
+		lookupEnv: os.LookupEnv,
+	}, providerCmd, providerArgs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fmt.Printf("📦 Running %s in a %s sandbox (%s)\n", agent.Provider, sandbox.Runtime, sandbox.Image)
+	return cmd, args, nil
+}
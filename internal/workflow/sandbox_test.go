@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSandboxYAML(t *testing.T) {
+	var wf workflow.Workflow
+	err := yaml.Unmarshal([]byte(`
+name: untrusted
+settings:
+  sandbox:
+    runtime: docker
+    image: ghcr.io/example/agents:latest
+    network: none
+agents:
+  - id: a
+    provider: claude
+  - id: b
+    provider: claude
+    sandbox: podman
+  - id: c
+    provider: claude
+    sandbox: none
+`), &wf)
+	require.NoError(t, err)
+
+	require.NotNil(t, wf.Settings.Sandbox)
+	assert.Equal(t, "none", wf.Settings.Sandbox.Network)
+	assert.Equal(t, "podman", wf.Agents[1].Sandbox.Runtime)
+
+	a := resolveSandbox(wf.Settings.Sandbox, wf.Agents[0].Sandbox)
+	require.NotNil(t, a)
+	assert.Equal(t, "docker", a.Runtime)
+
+	b := resolveSandbox(wf.Settings.Sandbox, wf.Agents[1].Sandbox)
+	require.NotNil(t, b)
+	assert.Equal(t, "podman", b.Runtime)
+	assert.Equal(t, "ghcr.io/example/agents:latest", b.Image, "inherits the workflow image")
+	assert.Equal(t, "docker", wf.Settings.Sandbox.Runtime, "workflow settings are not modified")
+
+	assert.Nil(t, resolveSandbox(wf.Settings.Sandbox, wf.Agents[2].Sandbox))
+	assert.Nil(t, resolveSandbox(nil, nil))
+}
+
+func TestBuildSandboxCommand(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".claude"), 0755))
+
+	opts := sandboxOptions{
+		provider:  "claude",
+		workDir:   "/work/project",
+		outputDir: "/tmp/opun-out",
+		homeDir:   home,
+		lookupEnv: func(name string) (string, bool) {
+			return "secret", name == "ANTHROPIC_API_KEY" || name == "EXTRA"
+		},
+	}
+
+	cmd, args, err := buildSandboxCommand(&workflow.Sandbox{
+		Runtime: "docker",
+		Image:   "agents:latest",
+		Mounts:  []string{"~/cache:/cache:ro"},
+		Env:     []string{"EXTRA", "UNSET"},
+	}, opts, "claude", []string{"--continue"})
+	require.NoError(t, err)
+
+	line := strings.Join(args, " ")
+	assert.Equal(t, "docker", cmd)
+	assert.Contains(t, line, "run --rm -i -t --network bridge")
+	assert.Contains(t, line, "-v /work/project:/work/project -w /work/project")
+	assert.Contains(t, line, "-v /tmp/opun-out:/tmp/opun-out")
+	assert.Contains(t, line, "-v "+filepath.Join(home, ".claude")+":/home/opun/.claude")
+	assert.NotContains(t, line, ".claude.json", "missing credential files are skipped")
+	assert.Contains(t, line, "-v "+filepath.Join(home, "cache")+":/cache:ro")
+	assert.Contains(t, line, "-e ANTHROPIC_API_KEY")
+	assert.Contains(t, line, "-e EXTRA")
+	assert.NotContains(t, line, "UNSET")
+	assert.NotContains(t, line, "secret", "values are never put on the command line")
+	assert.True(t, strings.HasSuffix(line, "agents:latest claude --continue"))
+
+	noCreds := false
+	_, args, err = buildSandboxCommand(&workflow.Sandbox{Runtime: "podman", Image: "x", Network: "none", MountCredentials: &noCreds},
+		sandboxOptions{provider: "claude", workDir: "/w", outputDir: "/w/out", homeDir: home, lookupEnv: func(string) (string, bool) { return "", false }},
+		"claude", nil)
+	require.NoError(t, err)
+	line = strings.Join(args, " ")
+	assert.Contains(t, line, "--network none")
+	assert.NotContains(t, line, ".claude")
+	assert.NotContains(t, line, "/w/out:", "output inside the project is already mounted")
+
+	_, _, err = buildSandboxCommand(&workflow.Sandbox{Runtime: "docker"}, opts, "claude", nil)
+	assert.Error(t, err, "image is required")
+	_, _, err = buildSandboxCommand(&workflow.Sandbox{Runtime: "lxc", Image: "x"}, opts, "claude", nil)
+	assert.Error(t, err)
+}
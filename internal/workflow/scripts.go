@@ -0,0 +1,165 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rizome-dev/opun/internal/script"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// loadScript loads a hook script; tests point it at another directory
+var loadScript = script.Load
+
+// validateScripts checks that an agent's hook scripts exist and compile
+func validateScripts(agent *workflow.Agent) error {
+	if agent.Scripts == nil {
+		return nil
+	}
+	for _, name := range []string{agent.Scripts.Prompt, agent.Scripts.Output} {
+		if name == "" {
+			continue
+		}
+		if _, err := loadScript(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scriptVars are the variables every hook script sees besides its input:
+// the agent, the workflow variables and the paths of earlier outputs
+func scriptVars(agent *workflow.Agent, variables map[string]interface{}, outputs map[string]string) map[string]interface{} {
+	paths := make(map[string]interface{}, len(outputs))
+	for id, path := range outputs {
+		paths[id] = path
+	}
+	return map[string]interface{}{
+		"agent": map[string]interface{}{
+			"id":       agent.ID,
+			"name":     agent.Name,
+			"provider": agent.Provider,
+			"model":    agent.Model,
+		},
+		"variables": variables,
+		"outputs":   paths,
+	}
+}
+
+// runHookScript runs a hook script with input bound to key and returns the
+// string it returns; ok is false when it returns nothing
+func runHookScript(ctx context.Context, name, key, input string, vars map[string]interface{}) (string, bool, error) {
+	s, err := loadScript(name)
+	if err != nil {
+		return "", false, err
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", false, err
+	}
+
+	vars[key] = input
+	result, err := s.Run(ctx, vars, workDir, script.Limits{})
+	if err != nil {
+		return "", false, err
+	}
+	if result == nil {
+		return "", false, nil
+	}
+	text, ok := result.(string)
+	if !ok {
+		return "", false, fmt.Errorf("script %s must return a string, got %T", name, result)
+	}
+	return text, true, nil
+}
+
+// transformPrompt runs the agent's prompt script, if any, on its prompt
+func transformPrompt(ctx context.Context, agent *workflow.Agent, prompt string, vars map[string]interface{}) (string, error) {
+	if agent.Scripts == nil || agent.Scripts.Prompt == "" {
+		return prompt, nil
+	}
+	transformed, ok, err := runHookScript(ctx, agent.Scripts.Prompt, "prompt", prompt, vars)
+	if err != nil {
+		return "", fmt.Errorf("prompt script failed: %w", err)
+	}
+	if !ok {
+		return prompt, nil
+	}
+	return transformed, nil
+}
+
+// postProcessOutput runs the agent's output script, if any, on its output
+func postProcessOutput(ctx context.Context, agent *workflow.Agent, output string, vars map[string]interface{}) (string, error) {
+	if agent.Scripts == nil || agent.Scripts.Output == "" {
+		return output, nil
+	}
+	processed, ok, err := runHookScript(ctx, agent.Scripts.Output, "output", output, vars)
+	if err != nil {
+		return "", fmt.Errorf("output script failed: %w", err)
+	}
+	if !ok {
+		return output, nil
+	}
+	return processed, nil
+}
+
+// scriptPrompt runs the agent's prompt script on its composed prompt
+func (e *InteractiveExecutor) scriptPrompt(ctx context.Context, agent *workflow.Agent, prompt string) (string, error) {
+	transformed, err := transformPrompt(ctx, agent, prompt, scriptVars(agent, e.state.Variables, e.outputs))
+	if err != nil {
+		return "", err
+	}
+	if transformed != prompt {
+		fmt.Printf("📜 Prompt transformed by script %s\n", agent.Scripts.Prompt)
+	}
+	return transformed, nil
+}
+
+// scriptOutput runs the agent's output script on its output file and writes
+// back what the script returns
+func (e *InteractiveExecutor) scriptOutput(ctx context.Context, agent *workflow.Agent) error {
+	if agent.Scripts == nil || agent.Scripts.Output == "" {
+		return nil
+	}
+	outputPath := e.agentOutputPath(agent)
+	if outputPath == "" {
+		return nil
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	processed, err := postProcessOutput(ctx, agent, string(content), scriptVars(agent, e.state.Variables, e.outputs))
+	if err != nil {
+		return err
+	}
+	if processed == string(content) {
+		return nil
+	}
+	if err := os.WriteFile(outputPath, []byte(processed), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("📜 Output post-processed by script %s\n", agent.Scripts.Output)
+	return nil
+}
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizome-dev/opun/internal/script"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useScriptDir loads hook scripts from dir for the rest of the test
+func useScriptDir(t *testing.T, dir string) {
+	loadScript = func(name string) (*script.Script, error) { return script.LoadFrom(dir, name) }
+	t.Cleanup(func() { loadScript = script.Load })
+}
+
+func TestHookScripts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tag.star"), []byte(`
+def main():
+    if variables.get("env") == "prod":
+        return "[" + agent["id"] + "] " + prompt
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "check.star"), []byte(`
+def main():
+    if "## Summary" not in output:
+        fail("output has no summary")
+    return output.strip() + "\n"
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.star"), []byte("def main()\n"), 0644))
+	useScriptDir(t, dir)
+
+	agent := &workflow.Agent{ID: "writer", Provider: "claude", Scripts: &workflow.AgentScripts{Prompt: "tag", Output: "check"}}
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.NoError(t, validateScripts(agent))
+		assert.NoError(t, validateScripts(&workflow.Agent{ID: "plain"}))
+		assert.ErrorContains(t, validateScripts(&workflow.Agent{Scripts: &workflow.AgentScripts{Prompt: "missing"}}), "script missing not found")
+		assert.ErrorContains(t, validateScripts(&workflow.Agent{Scripts: &workflow.AgentScripts{Output: "broken"}}), "script broken: broken.star:2:1")
+	})
+
+	t.Run("Prompt", func(t *testing.T) {
+		prompt, err := transformPrompt(context.Background(), agent, "Write it", scriptVars(agent, map[string]interface{}{"env": "prod"}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "[writer] Write it", prompt)
+
+		// Returning nothing keeps the prompt
+		prompt, err = transformPrompt(context.Background(), agent, "Write it", scriptVars(agent, map[string]interface{}{"env": "dev"}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "Write it", prompt)
+	})
+
+	t.Run("Output", func(t *testing.T) {
+		output, err := postProcessOutput(context.Background(), agent, "## Summary\nDone\n\n\n", scriptVars(agent, nil, map[string]string{"planner": "plan.md"}))
+		require.NoError(t, err)
+		assert.Equal(t, "## Summary\nDone\n", output)
+
+		_, err = postProcessOutput(context.Background(), agent, "Done", scriptVars(agent, nil, nil))
+		assert.ErrorContains(t, err, "output script failed: script check: check.star:4:13: fail: output has no summary")
+	})
+}
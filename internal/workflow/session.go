@@ -0,0 +1,111 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// sessionContinuationArgs returns the provider flags that resume an earlier
+// conversation, or nil if the provider has no native session continuation.
+// With a session ID the exact session is resumed, otherwise the most recent one.
+func sessionContinuationArgs(provider, sessionID string) []string {
+	switch strings.ToLower(provider) {
+	case "claude":
+		if sessionID != "" {
+			return []string{"--resume", sessionID}
+		}
+		return []string{"--continue"}
+	}
+	return nil
+}
+
+// continuesSession reports whether an agent picks up the conversation of the
+// agent that ran before it
+func continuesSession(agent, previous *workflow.Agent) bool {
+	if !agent.ContinueSession || previous == nil || previous.SubAgent != nil {
+		return false
+	}
+	if !strings.EqualFold(agent.Provider, previous.Provider) {
+		return false
+	}
+	return sessionContinuationArgs(agent.Provider, "") != nil
+}
+
+// latestClaudeSessionID returns the ID of the newest Claude Code session for
+// workDir written after since, or "" if there is none
+func latestClaudeSessionID(homeDir, workDir string, since time.Time) string {
+	projectDir := filepath.Join(homeDir, ".claude", "projects", claudeProjectKey(workDir))
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return ""
+	}
+
+	var latestID string
+	var latestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		if info.ModTime().After(latestTime) {
+			latestTime = info.ModTime()
+			latestID = strings.TrimSuffix(entry.Name(), ".jsonl")
+		}
+	}
+
+	return latestID
+}
+
+// claudeProjectKey converts a directory into the name Claude Code uses for its
+// per-project session folder (every non-alphanumeric character becomes '-')
+func claudeProjectKey(dir string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, dir)
+}
+
+// recordSession remembers the agent that just finished so the next agent can
+// continue its provider session
+func (e *InteractiveExecutor) recordSession(agent *workflow.Agent, started time.Time) {
+	e.previousAgent = agent
+	e.sessionID = ""
+
+	if !strings.EqualFold(agent.Provider, "claude") {
+		return
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	e.sessionID = latestClaudeSessionID(homeDir, workDir, started)
+}
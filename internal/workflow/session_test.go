@@ -0,0 +1,74 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionContinuationArgs(t *testing.T) {
+	assert.Equal(t, []string{"--continue"}, sessionContinuationArgs("claude", ""))
+	assert.Equal(t, []string{"--resume", "abc"}, sessionContinuationArgs("Claude", "abc"))
+	assert.Nil(t, sessionContinuationArgs("gemini", ""))
+}
+
+func TestContinuesSession(t *testing.T) {
+	previous := &workflow.Agent{ID: "plan", Provider: "claude"}
+
+	assert.True(t, continuesSession(&workflow.Agent{Provider: "claude", ContinueSession: true}, previous))
+	assert.False(t, continuesSession(&workflow.Agent{Provider: "claude"}, previous), "opt-in only")
+	assert.False(t, continuesSession(&workflow.Agent{Provider: "claude", ContinueSession: true}, nil), "first agent")
+	assert.False(t, continuesSession(&workflow.Agent{Provider: "gemini", ContinueSession: true}, &workflow.Agent{Provider: "gemini"}), "no native continuation")
+	assert.False(t, continuesSession(&workflow.Agent{Provider: "claude", ContinueSession: true}, &workflow.Agent{Provider: "gemini"}), "different provider")
+	assert.False(t, continuesSession(&workflow.Agent{Provider: "claude", ContinueSession: true},
+		&workflow.Agent{Provider: "claude", SubAgent: &workflow.SubAgentConfig{Name: "x"}}), "subagent has no session")
+}
+
+func TestLatestClaudeSessionID(t *testing.T) {
+	home := t.TempDir()
+	workDir := "/home/dev/my.project"
+	projectDir := filepath.Join(home, ".claude", "projects", "-home-dev-my-project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	since := time.Now().Add(-time.Minute)
+	assert.Empty(t, latestClaudeSessionID(home, workDir, since))
+
+	write := func(name string, modTime time.Time) {
+		path := filepath.Join(projectDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	write("old.jsonl", since.Add(-time.Hour))
+	write("first.jsonl", since.Add(10*time.Second))
+	write("second.jsonl", since.Add(20*time.Second))
+	write("notes.txt", since.Add(30*time.Second))
+
+	assert.Equal(t, "second", latestClaudeSessionID(home, workDir, since))
+	assert.Empty(t, latestClaudeSessionID(home, workDir, time.Now().Add(time.Minute)))
+}
+
+func TestProcessPromptSkipsHandoffWhenContinuing(t *testing.T) {
+	executor := NewInteractiveExecutor()
+	executor.workflow = &workflow.Workflow{
+		Agents: []workflow.Agent{
+			{ID: "plan", Provider: "claude"},
+			{ID: "build", Provider: "claude", ContinueSession: true},
+		},
+	}
+	executor.state = &workflow.ExecutionState{Variables: map[string]interface{}{}}
+	executor.handoffContext = []string{"Agent plan (claude) completed"}
+
+	result, err := executor.processPromptWithHandoff("Implement the plan", 1)
+	require.NoError(t, err)
+	assert.Contains(t, result, "WORKFLOW CONTEXT")
+
+	executor.previousAgent = &executor.workflow.Agents[0]
+	result, err = executor.processPromptWithHandoff("Implement the plan", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Implement the plan", result)
+}
@@ -0,0 +1,462 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Snapshot methods
+const (
+	SnapshotGit = "git" // working tree saved as a commit under refs/opun/snapshots
+	SnapshotTar = "tar" // files saved to a gzipped tarball
+)
+
+// maxSnapshotBytes caps tar snapshots of workspaces outside git
+const maxSnapshotBytes = 200 << 20
+
+// snapshotSkipDirs are never included in tar snapshots
+var snapshotSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+}
+
+// Snapshot records the state of the workspace before an agent ran, for
+// `opun rollback`
+type Snapshot struct {
+	RunID     string    `json:"run_id"`
+	Workflow  string    `json:"workflow"`
+	Agent     string    `json:"agent"`
+	WorkDir   string    `json:"work_dir"`
+	Method    string    `json:"method"`
+	Commit    string    `json:"commit,omitempty"`  // git snapshots
+	Ref       string    `json:"ref,omitempty"`     // keeps the commit from being garbage collected
+	Archive   string    `json:"archive,omitempty"` // tar snapshots
+	Files     []string  `json:"files,omitempty"`   // files present when the snapshot was taken; untracked ones for git
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SnapshotsDir returns the directory holding workspace snapshots
+func SnapshotsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".opun", "snapshots"), nil
+}
+
+// CreateSnapshot saves workDir before an agent runs. Git repositories are
+// saved as a commit of the working tree without touching the index or
+// branches; other directories are archived. The snapshot is recorded in
+// <dir>/<run-id>/<agent>.json.
+func CreateSnapshot(dir, runID, workflowName, agentID, workDir string) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		RunID:     runID,
+		Workflow:  workflowName,
+		Agent:     agentID,
+		WorkDir:   workDir,
+		CreatedAt: time.Now(),
+	}
+
+	runDir := filepath.Join(dir, runID)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return nil, err
+	}
+
+	if root, err := git(workDir, "rev-parse", "--show-toplevel"); err == nil {
+		if err := snapshot.saveGit(strings.TrimSpace(root)); err != nil {
+			return nil, err
+		}
+	} else {
+		snapshot.Method = SnapshotTar
+		snapshot.Archive = filepath.Join(runDir, agentID+".tar.gz")
+		files, err := writeSnapshotArchive(snapshot.Archive, workDir)
+		if err != nil {
+			_ = os.Remove(snapshot.Archive)
+			return nil, err
+		}
+		snapshot.Files = files
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(runDir, agentID+".json"), data, 0644); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// saveGit saves the repository's working tree as a commit
+func (s *Snapshot) saveGit(root string) error {
+	s.Method = SnapshotGit
+	s.WorkDir = root
+
+	// stash create prints nothing when the working tree matches HEAD
+	commit, err := git(root, "stash", "create", "opun snapshot before "+s.Agent)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot working tree: %w", err)
+	}
+	if commit = strings.TrimSpace(commit); commit == "" {
+		if commit, err = git(root, "rev-parse", "HEAD"); err != nil {
+			return fmt.Errorf("failed to snapshot working tree: repository has no commits")
+		}
+		commit = strings.TrimSpace(commit)
+	}
+	s.Commit = commit
+
+	s.Ref = "refs/opun/snapshots/" + s.RunID + "/" + s.Agent
+	if _, err := git(root, "update-ref", s.Ref, commit); err != nil {
+		return fmt.Errorf("failed to save snapshot ref: %w", err)
+	}
+
+	untracked, err := gitUntracked(root)
+	if err != nil {
+		return err
+	}
+	s.Files = untracked
+	return nil
+}
+
+// LoadSnapshot reads the snapshot taken before an agent of a run
+func LoadSnapshot(dir, runID, agentID string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, runID, agentID+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no snapshot for agent '%s' in run %s", agentID, runID)
+		}
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots returns the snapshots of a run, oldest first
+func ListSnapshots(dir, runID string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, runID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no snapshots for run %s", runID)
+		}
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snapshot, err := LoadSnapshot(dir, runID, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Restore puts the workspace back the way it was when the snapshot was
+// taken. Files created since are left in place unless clean is set; either
+// way they are returned.
+func (s *Snapshot) Restore(clean bool) ([]string, error) {
+	var (
+		created []string
+		err     error
+	)
+	switch s.Method {
+	case SnapshotGit:
+		// Tracked files, including ones deleted since, come back from the commit
+		if _, err := git(s.WorkDir, "restore", "--source="+s.Commit, "--worktree", "--", "."); err != nil {
+			return nil, fmt.Errorf("failed to restore working tree: %w", err)
+		}
+		untracked, err := gitUntracked(s.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		created = newFiles(untracked, s.Files)
+	case SnapshotTar:
+		if err := extractSnapshotArchive(s.Archive, s.WorkDir); err != nil {
+			return nil, fmt.Errorf("failed to restore workspace: %w", err)
+		}
+		current, err := listSnapshotFiles(s.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		created = newFiles(current, s.Files)
+	default:
+		return nil, fmt.Errorf("unknown snapshot method: %s", s.Method)
+	}
+
+	if clean {
+		for _, rel := range created {
+			if err = os.Remove(filepath.Join(s.WorkDir, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+				return created, err
+			}
+		}
+	}
+	return created, nil
+}
+
+// snapshotAgent saves the workspace before an agent with snapshot: true runs
+func (e *InteractiveExecutor) snapshotAgent(agent *workflow.Agent, remote *SSHTarget) error {
+	if !agent.Snapshot {
+		return nil
+	}
+	if remote != nil {
+		fmt.Printf("⚠️  snapshot ignored: %s runs on %s\n", agent.Name, remote.Host)
+		return nil
+	}
+
+	dir, err := SnapshotsDir()
+	if err != nil {
+		return err
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	snapshot, err := CreateSnapshot(dir, e.runID, e.workflow.Name, agent.ID, workDir)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot workspace: %w", err)
+	}
+	fmt.Printf("📸 Workspace snapshot saved (%s); undo with: opun rollback %s %s\n", snapshot.Method, e.runID, agent.ID)
+	return nil
+}
+
+// SetRunID sets the run ID used for snapshots and {{run_id}}; without one a
+// new ID is generated when the run starts
+func (e *InteractiveExecutor) SetRunID(id string) {
+	e.runID = id
+}
+
+// newFiles returns the files in current that aren't in before
+func newFiles(current, before []string) []string {
+	known := make(map[string]bool, len(before))
+	for _, rel := range before {
+		known[rel] = true
+	}
+	var added []string
+	for _, rel := range current {
+		if !known[rel] {
+			added = append(added, rel)
+		}
+	}
+	return added
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// gitUntracked lists the repository's untracked, non-ignored files
+func gitUntracked(root string) ([]string, error) {
+	out, err := git(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, rel := range strings.Split(out, "\x00") {
+		if rel != "" {
+			files = append(files, rel)
+		}
+	}
+	return files, nil
+}
+
+// listSnapshotFiles lists the regular files and symlinks under root that tar
+// snapshots include, as slash-separated relative paths
+func listSnapshotFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && snapshotSkipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// writeSnapshotArchive archives the files under root and returns their paths
+func writeSnapshotArchive(archive, root string) ([]string, error) {
+	files, err := listSnapshotFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	var total int64
+	for _, rel := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return nil, err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return nil, err
+		}
+		header.Name = rel
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		if total += info.Size(); total > maxSnapshotBytes {
+			return nil, fmt.Errorf("workspace is larger than %dMB; use a git repository for snapshots", maxSnapshotBytes>>20)
+		}
+		// #nosec G304 -- path is a file in the workspace being snapshotted
+		src, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(tw, src)
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return files, f.Close()
+}
+
+// extractSnapshotArchive writes the files of a snapshot archive back under root
+func extractSnapshotArchive(archive, root string) error {
+	// #nosec G304 -- archive is a snapshot Opun wrote
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(root, filepath.FromSlash(header.Name))
+		if !isWithin(path, root) {
+			return fmt.Errorf("invalid path in snapshot: %s", header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		// Replace whatever is there now, e.g. a file that became a symlink
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			// #nosec G304 -- path was checked to be inside the workspace
+			dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			// #nosec G110 -- the archive was capped at maxSnapshotBytes when written
+			_, err = io.Copy(dst, tr)
+			dst.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
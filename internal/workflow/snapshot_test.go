@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSnapshotTar(t *testing.T) {
+	dir := t.TempDir()
+	work := t.TempDir()
+	writeTestFile(t, filepath.Join(work, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(work, "docs", "README.md"), "docs\n")
+	writeTestFile(t, filepath.Join(work, "node_modules", "dep.js"), "skipped\n")
+
+	snapshot, err := CreateSnapshot(dir, "run-1", "refactor", "edit", work)
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotTar, snapshot.Method)
+	assert.ElementsMatch(t, []string{"main.go", "docs/README.md"}, snapshot.Files)
+
+	// The agent edits, deletes and creates files
+	writeTestFile(t, filepath.Join(work, "main.go"), "package broken\n")
+	require.NoError(t, os.Remove(filepath.Join(work, "docs", "README.md")))
+	writeTestFile(t, filepath.Join(work, "new.go"), "package main\n")
+
+	loaded, err := LoadSnapshot(dir, "run-1", "edit")
+	require.NoError(t, err)
+	created, err := loaded.Restore(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.go"}, created)
+	assert.Equal(t, "package main\n", readTestFile(t, filepath.Join(work, "main.go")))
+	assert.Equal(t, "docs\n", readTestFile(t, filepath.Join(work, "docs", "README.md")))
+	assert.FileExists(t, filepath.Join(work, "new.go"))
+
+	created, err = loaded.Restore(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.go"}, created)
+	assert.NoFileExists(t, filepath.Join(work, "new.go"))
+
+	snapshots, err := ListSnapshots(dir, "run-1")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "edit", snapshots[0].Agent)
+
+	_, err = LoadSnapshot(dir, "run-1", "missing")
+	assert.EqualError(t, err, "no snapshot for agent 'missing' in run run-1")
+}
+
+func TestSnapshotGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	work := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		_, err := git(work, args...)
+		require.NoError(t, err)
+	}
+	writeTestFile(t, filepath.Join(work, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(work, "util.go"), "package util\n")
+	_, err := git(work, "add", ".")
+	require.NoError(t, err)
+	_, err = git(work, "commit", "-q", "-m", "initial")
+	require.NoError(t, err)
+
+	// Uncommitted work before the agent runs is part of the snapshot
+	writeTestFile(t, filepath.Join(work, "main.go"), "package main // wip\n")
+	writeTestFile(t, filepath.Join(work, "notes.txt"), "untracked\n")
+
+	snapshot, err := CreateSnapshot(dir, "run-2", "refactor", "edit", work)
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotGit, snapshot.Method)
+	assert.Equal(t, []string{"notes.txt"}, snapshot.Files)
+	status, err := git(work, "status", "--porcelain")
+	require.NoError(t, err)
+	assert.Contains(t, status, " M main.go", "working tree is left alone")
+
+	writeTestFile(t, filepath.Join(work, "main.go"), "package broken\n")
+	require.NoError(t, os.Remove(filepath.Join(work, "util.go")))
+	writeTestFile(t, filepath.Join(work, "generated.go"), "package gen\n")
+
+	created, err := snapshot.Restore(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"generated.go"}, created)
+	assert.Equal(t, "package main // wip\n", readTestFile(t, filepath.Join(work, "main.go")))
+	assert.Equal(t, "package util\n", readTestFile(t, filepath.Join(work, "util.go")))
+	assert.Equal(t, "untracked\n", readTestFile(t, filepath.Join(work, "notes.txt")))
+
+	ref, err := git(work, "rev-parse", snapshot.Ref)
+	require.NoError(t, err)
+	assert.Contains(t, ref, snapshot.Commit)
+}
@@ -0,0 +1,93 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// startupKeyDelay lets a dialog finish drawing before its keys are typed
+const startupKeyDelay = 300 * time.Millisecond
+
+// startupWatcher runs a provider's startup script against the output of a
+// session, typing the keys of each step when its text appears, until the
+// prompt is typed
+type startupWatcher struct {
+	mu      sync.Mutex
+	runner  *providers.StartupRunner
+	keys    io.Writer
+	output  strings.Builder
+	sentAt  time.Time
+	stopped bool
+}
+
+// newStartupWatcher compiles the startup script of an agent's provider
+func newStartupWatcher(agent *workflow.Agent, keys io.Writer) (*startupWatcher, error) {
+	runner, err := providers.StartupScriptFor(agent.Provider).Runner()
+	if err != nil {
+		return nil, fmt.Errorf("invalid startup script for %s: %w", agent.Provider, err)
+	}
+	return &startupWatcher{runner: runner, keys: keys}, nil
+}
+
+// Write feeds session output to the script
+func (w *startupWatcher) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped || w.runner.Done() {
+		return len(p), nil
+	}
+	w.output.Write(p)
+	if keys := w.runner.Feed(w.output.String()); keys != "" {
+		w.sentAt = time.Now()
+		go func() {
+			time.Sleep(startupKeyDelay)
+			_, _ = w.keys.Write([]byte(keys))
+		}()
+	}
+	return len(p), nil
+}
+
+// Offset is where the output after the last answered dialog starts
+func (w *startupWatcher) Offset() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.runner.Offset()
+}
+
+// SentWithin reports whether keys were typed in the last d, while a dialog
+// may still be closing
+func (w *startupWatcher) SentWithin(d time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.sentAt.IsZero() && time.Since(w.sentAt) < d
+}
+
+// Stop ends the script once the prompt is typed, so text in the
+// conversation can't trigger its remaining steps
+func (w *startupWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+}
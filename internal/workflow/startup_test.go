@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/providers"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupWatcher(t *testing.T) {
+	providers.SetStartupScript("gemini", providers.StartupScript{
+		{Expect: `Do you trust this folder\?`, Send: "<enter>"},
+		{Expect: `Select Theme`, Send: "<enter>"},
+	})
+	defer providers.SetStartupScript("gemini", nil)
+
+	keys := &lockedBuffer{}
+	w, err := newStartupWatcher(&workflow.Agent{Provider: "gemini"}, keys)
+	require.NoError(t, err)
+
+	_, _ = w.Write([]byte("Do you trust this folder?\n"))
+	assert.True(t, w.SentWithin(time.Minute))
+	assert.Equal(t, len("Do you trust this folder?\n"), w.Offset())
+	assert.Eventually(t, func() bool { return keys.String() == "\r" }, time.Second, 10*time.Millisecond)
+
+	// Once the prompt is typed, the conversation can't trigger later steps
+	w.Stop()
+	_, _ = w.Write([]byte("Select Theme"))
+	time.Sleep(2 * startupKeyDelay)
+	assert.Equal(t, "\r", keys.String())
+}
+
+func TestStartupWatcherWithoutScript(t *testing.T) {
+	w, err := newStartupWatcher(&workflow.Agent{Provider: "claude"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("│ > "))
+	assert.Zero(t, w.Offset())
+	assert.False(t, w.SentWithin(time.Minute))
+
+	providers.SetStartupScript("claude", providers.StartupScript{{Expect: "(", Send: "y"}})
+	defer providers.SetStartupScript("claude", nil)
+	_, err = newStartupWatcher(&workflow.Agent{Provider: "claude"}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
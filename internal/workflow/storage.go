@@ -0,0 +1,338 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Storage backends for run artifacts
+const (
+	StorageS3    = "s3"
+	StorageGCS   = "gcs"
+	StorageAzure = "azure"
+)
+
+// defaultStoragePrefix is where a run's artifacts go in the bucket
+const defaultStoragePrefix = "{{workflow}}/{{run_id}}"
+
+// defaultURLExpiry is how long signed URLs to artifacts work
+const defaultURLExpiry = 24 * time.Hour
+
+// storageTimeout bounds uploading a run's artifacts
+const storageTimeout = 10 * time.Minute
+
+// ArtifactStorer uploads run artifacts to an object store and signs URLs
+// that reach them without credentials
+type ArtifactStorer interface {
+	// Location is the URI of a key in the bucket
+	Location(key string) string
+	// Upload copies the files under dir to prefix
+	Upload(ctx context.Context, dir, prefix string) error
+	// SignURL returns a URL to key that works until expiry
+	SignURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// StoredArtifacts records where a run's artifacts were uploaded
+type StoredArtifacts struct {
+	Backend  string `json:"backend"`
+	Location string `json:"location"`
+	Uploaded bool   `json:"uploaded"`
+	// URLs are signed URLs to the main artifacts, by path in the output directory
+	URLs      map[string]string `json:"urls,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// storageCommand runs a storage CLI and returns its standard output
+var storageCommand = func(ctx context.Context, args []string) (string, error) {
+	// #nosec G204 -- aws, gcloud or az with arguments built from the storage settings
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s failed: %v: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// validateStorage checks a storage configuration
+func validateStorage(s *workflow.Storage) error {
+	if s == nil {
+		return nil
+	}
+	if s.Bucket == "" {
+		return fmt.Errorf("storage needs a bucket")
+	}
+	switch s.Backend {
+	case StorageS3, StorageGCS:
+	case StorageAzure:
+		if s.Account == "" {
+			return fmt.Errorf("azure storage needs an account")
+		}
+	default:
+		return fmt.Errorf("storage backend must be s3, gcs or azure, got %q", s.Backend)
+	}
+	if _, err := storageURLExpiry(s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// storageURLExpiry returns how long signed URLs work, 0 for no signed URLs
+func storageURLExpiry(s *workflow.Storage) (time.Duration, error) {
+	if s.URLExpiry == "" {
+		return defaultURLExpiry, nil
+	}
+	d, err := time.ParseDuration(s.URLExpiry)
+	if err != nil {
+		return 0, fmt.Errorf("invalid url_expiry %q: %w", s.URLExpiry, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("url_expiry can't be negative, got %s", s.URLExpiry)
+	}
+	return d, nil
+}
+
+// NewArtifactStorer returns the storer of a storage configuration
+func NewArtifactStorer(s *workflow.Storage) (ArtifactStorer, error) {
+	if err := validateStorage(s); err != nil {
+		return nil, err
+	}
+	switch s.Backend {
+	case StorageS3:
+		return &s3Storer{bucket: s.Bucket, region: s.Region, endpoint: s.Endpoint}, nil
+	case StorageGCS:
+		return &gcsStorer{bucket: s.Bucket, serviceAccount: s.ServiceAccount}, nil
+	default:
+		return &azureStorer{account: s.Account, container: s.Bucket}, nil
+	}
+}
+
+// StoreArtifacts uploads an output directory and signs URLs to the given
+// files, paths relative to dir. The URLs are signed first so they can be
+// written into the manifest before it is uploaded; beforeUpload gets the
+// record to do so. Failures are reported in the record's Error.
+func StoreArtifacts(ctx context.Context, s *workflow.Storage, dir string, vars OutputPathVars, files []string, beforeUpload func(*StoredArtifacts)) *StoredArtifacts {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = defaultStoragePrefix
+	}
+	prefix = strings.Trim(ExpandOutputPath(prefix, vars), "/")
+
+	stored := &StoredArtifacts{Backend: s.Backend}
+	storer, err := NewArtifactStorer(s)
+	if err != nil {
+		stored.Error = err.Error()
+		return stored
+	}
+	stored.Location = storer.Location(prefix + "/")
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expiry, _ := storageURLExpiry(s)
+	if expiry > 0 {
+		sort.Strings(files)
+		for _, file := range files {
+			key := path.Join(prefix, filepath.ToSlash(file))
+			url, err := storer.SignURL(ctx, key, expiry)
+			if err != nil {
+				stored.Error = fmt.Sprintf("failed to sign URL to %s: %v", file, err)
+				break
+			}
+			if stored.URLs == nil {
+				stored.URLs = make(map[string]string)
+			}
+			stored.URLs[filepath.ToSlash(file)] = url
+		}
+		if len(stored.URLs) > 0 {
+			expires := time.Now().Add(expiry)
+			stored.ExpiresAt = &expires
+		}
+	}
+
+	// The uploaded copy of the manifest can only exist if the upload works
+	stored.Uploaded = true
+	if beforeUpload != nil {
+		beforeUpload(stored)
+	}
+	if err := storer.Upload(ctx, dir, prefix); err != nil {
+		stored.Uploaded = false
+		stored.Error = err.Error()
+		stored.URLs, stored.ExpiresAt = nil, nil
+	}
+	return stored
+}
+
+// PrintStoredArtifacts reports where artifacts were uploaded
+func PrintStoredArtifacts(stored *StoredArtifacts) {
+	if !stored.Uploaded {
+		fmt.Printf("⚠️  Failed to upload outputs: %s\n", stored.Error)
+		return
+	}
+	fmt.Printf("☁️  Outputs uploaded to %s\n", stored.Location)
+	if stored.Error != "" {
+		fmt.Printf("⚠️  %s\n", stored.Error)
+	}
+	files := make([]string, 0, len(stored.URLs))
+	for file := range stored.URLs {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		fmt.Printf("   🔗 %s: %s\n", file, stored.URLs[file])
+	}
+}
+
+// storeArtifacts uploads the run's output directory when settings.storage
+// is set, recording the upload in the run manifest and notifying event
+// handlers with the signed URLs
+func (e *InteractiveExecutor) storeArtifacts(ctx context.Context) {
+	if e.workflow == nil || e.workflow.Settings.Storage == nil || e.outputDir == "" {
+		return
+	}
+
+	// Artifacts of an aborted run are uploaded too
+	ctx = context.WithoutCancel(ctx)
+
+	e.mu.Lock()
+	files := []string{ManifestFile}
+	for _, output := range e.outputs {
+		if rel, err := filepath.Rel(e.outputDir, output); err == nil && !strings.HasPrefix(rel, "..") {
+			if _, err := os.Stat(output); err == nil {
+				files = append(files, rel)
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	stored := StoreArtifacts(ctx, e.workflow.Settings.Storage, e.outputDir, e.outputPathVars(), files, func(stored *StoredArtifacts) {
+		e.mu.Lock()
+		e.stored = stored
+		e.mu.Unlock()
+		e.writeRunManifest()
+	})
+	// The local manifest records whether the upload worked
+	e.mu.Lock()
+	e.stored = stored
+	e.mu.Unlock()
+	e.writeRunManifest()
+	PrintStoredArtifacts(stored)
+
+	e.emit(workflow.EventOutputCreated, "", fmt.Sprintf("Outputs uploaded to %s", stored.Location), map[string]interface{}{
+		"location": stored.Location,
+		"urls":     stored.URLs,
+		"error":    stored.Error,
+	})
+}
+
+// s3Storer stores artifacts in Amazon S3 or an S3-compatible store with the aws CLI
+type s3Storer struct {
+	bucket   string
+	region   string
+	endpoint string
+}
+
+func (s *s3Storer) Location(key string) string {
+	return "s3://" + s.bucket + "/" + key
+}
+
+// flags returns the region and endpoint flags of every aws command
+func (s *s3Storer) flags() []string {
+	var flags []string
+	if s.region != "" {
+		flags = append(flags, "--region", s.region)
+	}
+	if s.endpoint != "" {
+		flags = append(flags, "--endpoint-url", s.endpoint)
+	}
+	return flags
+}
+
+func (s *s3Storer) Upload(ctx context.Context, dir, prefix string) error {
+	args := append([]string{"aws", "s3", "cp", dir, s.Location(prefix), "--recursive", "--only-show-errors"}, s.flags()...)
+	_, err := storageCommand(ctx, args)
+	return err
+}
+
+func (s *s3Storer) SignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	args := append([]string{"aws", "s3", "presign", s.Location(key), "--expires-in", fmt.Sprint(int(expiry.Seconds()))}, s.flags()...)
+	return storageCommand(ctx, args)
+}
+
+// gcsStorer stores artifacts in Google Cloud Storage with the gcloud CLI
+type gcsStorer struct {
+	bucket         string
+	serviceAccount string
+}
+
+func (s *gcsStorer) Location(key string) string {
+	return "gs://" + s.bucket + "/" + key
+}
+
+func (s *gcsStorer) Upload(ctx context.Context, dir, prefix string) error {
+	_, err := storageCommand(ctx, []string{"gcloud", "storage", "rsync", dir, s.Location(prefix), "--recursive", "--quiet"})
+	return err
+}
+
+func (s *gcsStorer) SignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	args := []string{"gcloud", "storage", "sign-url", s.Location(key), "--duration", fmt.Sprintf("%ds", int(expiry.Seconds())), "--format", "value(signed_url)"}
+	if s.serviceAccount != "" {
+		args = append(args, "--impersonate-service-account", s.serviceAccount)
+	}
+	return storageCommand(ctx, args)
+}
+
+// azureStorer stores artifacts in Azure Blob Storage with the az CLI,
+// authenticated with the logged-in identity
+type azureStorer struct {
+	account   string
+	container string
+}
+
+func (s *azureStorer) Location(key string) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", s.account, s.container, key)
+}
+
+func (s *azureStorer) Upload(ctx context.Context, dir, prefix string) error {
+	_, err := storageCommand(ctx, []string{"az", "storage", "blob", "upload-batch",
+		"--account-name", s.account, "--destination", s.container, "--destination-path", prefix,
+		"--source", dir, "--auth-mode", "login", "--overwrite", "--only-show-errors"})
+	return err
+}
+
+func (s *azureStorer) SignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return storageCommand(ctx, []string{"az", "storage", "blob", "generate-sas",
+		"--account-name", s.account, "--container-name", s.container, "--name", key,
+		"--permissions", "r", "--expiry", time.Now().Add(expiry).UTC().Format("2006-01-02T15:04Z"),
+		"--auth-mode", "login", "--as-user", "--full-uri", "--output", "tsv"})
+}
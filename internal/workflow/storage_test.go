@@ -0,0 +1,133 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStorageCommand records storage commands and answers presign requests
+func fakeStorageCommand(t *testing.T, fail string) *[][]string {
+	t.Helper()
+	var calls [][]string
+	previous := storageCommand
+	storageCommand = func(ctx context.Context, args []string) (string, error) {
+		calls = append(calls, args)
+		command := strings.Join(args, " ")
+		if fail != "" && strings.Contains(command, fail) {
+			return "", fmt.Errorf("%s failed: access denied", args[0])
+		}
+		if strings.Contains(command, "presign") || strings.Contains(command, "sign-url") || strings.Contains(command, "generate-sas") {
+			return "https://signed.example/" + args[3], nil
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { storageCommand = previous })
+	return &calls
+}
+
+func TestValidateStorage(t *testing.T) {
+	assert.NoError(t, validateStorage(nil))
+	assert.NoError(t, validateStorage(&workflow.Storage{Backend: StorageS3, Bucket: "runs", URLExpiry: "1h"}))
+	assert.NoError(t, validateStorage(&workflow.Storage{Backend: StorageGCS, Bucket: "runs", URLExpiry: "0"}))
+	assert.Error(t, validateStorage(&workflow.Storage{Backend: StorageS3}), "bucket is required")
+	assert.Error(t, validateStorage(&workflow.Storage{Backend: "ftp", Bucket: "runs"}))
+	assert.Error(t, validateStorage(&workflow.Storage{Backend: StorageAzure, Bucket: "runs"}), "azure needs an account")
+	assert.Error(t, validateStorage(&workflow.Storage{Backend: StorageS3, Bucket: "runs", URLExpiry: "tomorrow"}))
+}
+
+func TestStoreArtifactsS3(t *testing.T) {
+	calls := fakeStorageCommand(t, "")
+	vars := OutputPathVars{Workflow: "review", RunID: "7-4000", Time: time.Now()}
+	settings := &workflow.Storage{Backend: StorageS3, Bucket: "ci-runs", Region: "eu-west-1", URLExpiry: "2h"}
+
+	var beforeUpload *StoredArtifacts
+	stored := StoreArtifacts(context.Background(), settings, "/tmp/out", vars, []string{"plan.md", ManifestFile}, func(s *StoredArtifacts) {
+		beforeUpload = s
+		assert.Len(t, s.URLs, 2, "URLs are signed before the upload")
+	})
+	require.NotNil(t, beforeUpload)
+
+	assert.True(t, stored.Uploaded)
+	assert.Empty(t, stored.Error)
+	assert.Equal(t, "s3://ci-runs/review/7-4000/", stored.Location)
+	assert.Equal(t, "https://signed.example/s3://ci-runs/review/7-4000/plan.md", stored.URLs["plan.md"])
+	require.NotNil(t, stored.ExpiresAt)
+
+	require.Len(t, *calls, 3)
+	assert.Equal(t, []string{"aws", "s3", "presign", "s3://ci-runs/review/7-4000/manifest.json", "--expires-in", "7200", "--region", "eu-west-1"}, (*calls)[0])
+	assert.Equal(t, []string{"aws", "s3", "cp", "/tmp/out", "s3://ci-runs/review/7-4000", "--recursive", "--only-show-errors", "--region", "eu-west-1"}, (*calls)[2])
+}
+
+func TestStoreArtifactsBackends(t *testing.T) {
+	vars := OutputPathVars{Workflow: "review", RunID: "7-4000", Time: time.Now()}
+
+	calls := fakeStorageCommand(t, "")
+	stored := StoreArtifacts(context.Background(), &workflow.Storage{Backend: StorageGCS, Bucket: "ci-runs", Prefix: "opun/{{run_id}}", ServiceAccount: "signer@p.iam.gserviceaccount.com"}, "/tmp/out", vars, []string{"plan.md"}, nil)
+	assert.Equal(t, "gs://ci-runs/opun/7-4000/", stored.Location)
+	assert.Contains(t, (*calls)[0], "--impersonate-service-account")
+	assert.Equal(t, []string{"gcloud", "storage", "rsync", "/tmp/out", "gs://ci-runs/opun/7-4000", "--recursive", "--quiet"}, (*calls)[1])
+
+	calls = fakeStorageCommand(t, "")
+	stored = StoreArtifacts(context.Background(), &workflow.Storage{Backend: StorageAzure, Bucket: "runs", Account: "opunci", URLExpiry: "0"}, "/tmp/out", vars, []string{"plan.md"}, nil)
+	assert.Equal(t, "https://opunci.blob.core.windows.net/runs/review/7-4000/", stored.Location)
+	assert.Empty(t, stored.URLs, "url_expiry 0 signs nothing")
+	require.Len(t, *calls, 1)
+	assert.Equal(t, "upload-batch", (*calls)[0][3])
+}
+
+func TestStoreArtifactsUploadFailure(t *testing.T) {
+	fakeStorageCommand(t, "s3 cp")
+	stored := StoreArtifacts(context.Background(), &workflow.Storage{Backend: StorageS3, Bucket: "ci-runs"}, "/tmp/out", OutputPathVars{Workflow: "review", RunID: "1"}, []string{"plan.md"}, nil)
+	assert.False(t, stored.Uploaded)
+	assert.Contains(t, stored.Error, "access denied")
+	assert.Empty(t, stored.URLs, "URLs to objects that weren't uploaded are dropped")
+}
+
+func TestExecutorStoresArtifacts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	calls := fakeStorageCommand(t, "")
+
+	outputDir := t.TempDir()
+	plan := filepath.Join(outputDir, "plan.md")
+	require.NoError(t, os.WriteFile(plan, []byte("the plan"), 0644))
+
+	e := NewInteractiveExecutor()
+	e.runID = "7-5000"
+	e.outputDir = outputDir
+	e.workflow = manifestWorkflow()
+	e.workflow.Settings.Storage = &workflow.Storage{Backend: StorageS3, Bucket: "ci-runs"}
+	e.state = &workflow.ExecutionState{Status: workflow.StatusCompleted, StartTime: time.Now(), AgentStates: map[string]*workflow.AgentState{}}
+	e.versionLookup = func(ctx context.Context, provider string) (string, error) { return "1.0", nil }
+	e.outputs["plan"] = plan
+	e.outputs["gone"] = filepath.Join(outputDir, "missing.md")
+
+	var events []workflow.WorkflowEvent
+	e.SetEventHandler(func(event workflow.WorkflowEvent) { events = append(events, event) })
+	e.storeArtifacts(context.Background())
+
+	// Signed URLs for the manifest and the outputs that exist, then the upload
+	require.Len(t, *calls, 3)
+
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestFile))
+	require.NoError(t, err)
+	var m RunManifest
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.NotNil(t, m.Storage)
+	assert.True(t, m.Storage.Uploaded)
+	assert.Contains(t, m.Storage.URLs, "plan.md")
+	assert.NotContains(t, m.Storage.URLs, "missing.md")
+
+	require.Len(t, events, 1)
+	assert.Equal(t, workflow.EventOutputCreated, events[0].Type)
+	assert.Equal(t, "s3://ci-runs/review/7-5000/", events[0].Data["location"])
+}
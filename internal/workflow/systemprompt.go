@@ -0,0 +1,86 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// PromptResolver returns the content of a prompt garden prompt by name
+type PromptResolver func(name string) (string, error)
+
+// SetPromptResolver sets how promptgarden:// references in agent system
+// prompts are resolved
+func (e *InteractiveExecutor) SetPromptResolver(resolve PromptResolver) {
+	e.promptResolver = resolve
+}
+
+// resolveSystemPrompt returns an agent's system prompt with a promptgarden://
+// reference resolved and workflow variables substituted
+func (e *InteractiveExecutor) resolveSystemPrompt(agent *workflow.Agent) (string, error) {
+	return resolveSystemPrompt(agent, e.state.Variables, e.promptResolver)
+}
+
+// resolveSystemPrompt resolves an agent's system prompt against the given
+// variables, with resolve looking up promptgarden:// references
+func resolveSystemPrompt(agent *workflow.Agent, variables map[string]interface{}, resolve PromptResolver) (string, error) {
+	systemPrompt := strings.TrimSpace(agent.SystemPrompt)
+	if systemPrompt == "" {
+		return "", nil
+	}
+
+	if name, ok := strings.CutPrefix(systemPrompt, "promptgarden://"); ok && !strings.ContainsAny(name, " \n") {
+		if resolve == nil {
+			return "", fmt.Errorf("system prompt %s: prompt garden is not available", systemPrompt)
+		}
+		content, err := resolve(name)
+		if err != nil {
+			return "", fmt.Errorf("system prompt %s: %w", systemPrompt, err)
+		}
+		systemPrompt = strings.TrimSpace(content)
+	}
+
+	for name, value := range variables {
+		systemPrompt = strings.ReplaceAll(systemPrompt, fmt.Sprintf("{{%s}}", name), fmt.Sprintf("%v", value))
+	}
+	return systemPrompt, nil
+}
+
+// nativeSystemPrompt reports whether a provider takes a system prompt as a flag
+func nativeSystemPrompt(provider string) bool {
+	return strings.EqualFold(provider, "claude")
+}
+
+// systemPromptArgs returns the provider flags that deliver a system prompt
+func systemPromptArgs(provider, systemPrompt string) []string {
+	if systemPrompt == "" || !nativeSystemPrompt(provider) {
+		return nil
+	}
+	return []string{"--append-system-prompt", systemPrompt}
+}
+
+// withSystemPrompt puts the system prompt ahead of the task prompt for
+// providers that have no system prompt flag
+func withSystemPrompt(provider, systemPrompt, prompt string) string {
+	if systemPrompt == "" || nativeSystemPrompt(provider) {
+		return prompt
+	}
+	return "🎭 ROLE:\n" + systemPrompt + "\n\n---\n\n" + prompt
+}
@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSystemPrompt(t *testing.T) {
+	e := NewInteractiveExecutor()
+	e.state = &workflow.ExecutionState{Variables: map[string]interface{}{"language": "Go"}}
+
+	systemPrompt, err := e.resolveSystemPrompt(&workflow.Agent{SystemPrompt: "  You review {{language}} code.\n"})
+	require.NoError(t, err)
+	assert.Equal(t, "You review Go code.", systemPrompt)
+
+	systemPrompt, err = e.resolveSystemPrompt(&workflow.Agent{})
+	require.NoError(t, err)
+	assert.Empty(t, systemPrompt)
+
+	_, err = e.resolveSystemPrompt(&workflow.Agent{SystemPrompt: "promptgarden://reviewer"})
+	assert.Error(t, err, "no resolver")
+
+	e.SetPromptResolver(func(name string) (string, error) {
+		if name == "reviewer" {
+			return "You are a {{language}} security reviewer.", nil
+		}
+		return "", errors.New("not found")
+	})
+	systemPrompt, err = e.resolveSystemPrompt(&workflow.Agent{SystemPrompt: "promptgarden://reviewer"})
+	require.NoError(t, err)
+	assert.Equal(t, "You are a Go security reviewer.", systemPrompt)
+
+	_, err = e.resolveSystemPrompt(&workflow.Agent{SystemPrompt: "promptgarden://missing"})
+	assert.EqualError(t, err, "system prompt promptgarden://missing: not found")
+}
+
+func TestSystemPromptDelivery(t *testing.T) {
+	assert.Equal(t, []string{"--append-system-prompt", "Be terse."}, systemPromptArgs("claude", "Be terse."))
+	assert.Equal(t, "Review it", withSystemPrompt("claude", "Be terse.", "Review it"), "claude gets a flag instead")
+
+	assert.Nil(t, systemPromptArgs("gemini", "Be terse."))
+	assert.Equal(t, "🎭 ROLE:\nBe terse.\n\n---\n\nReview it", withSystemPrompt("gemini", "Be terse.", "Review it"))
+
+	assert.Nil(t, systemPromptArgs("claude", ""))
+	assert.Equal(t, "Review it", withSystemPrompt("gemini", "", "Review it"))
+}
+
+func TestPrepareAgentPrompt(t *testing.T) {
+	agent := &workflow.Agent{ID: "review", Provider: "claude", Settings: workflow.AgentSettings{MaxTokens: 8000}}
+
+	prepared, err := prepareAgentPrompt(agent, "You review code", "Review {{#context}}main.go{{/context}}", nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--settings", `{"env":{"CLAUDE_CODE_MAX_OUTPUT_TOKENS":"8000"}}`, "--append-system-prompt", "You review code"}, prepared.Args)
+	assert.Equal(t, "Review main.go", prepared.Prompt)
+	assert.Equal(t, "You review code\n\nReview main.go", prepared.PolicyText)
+	assert.Positive(t, prepared.Size.Tokens)
+
+	// Runners without flags get the system prompt in the prompt
+	prepared, err = prepareAgentPrompt(agent, "You review code", "Review it", nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, prepared.Args)
+	assert.Contains(t, prepared.Prompt, "You review code")
+	assert.Equal(t, prepared.Prompt, prepared.PolicyText)
+
+	_, err = prepareAgentPrompt(agent, "", "Review it", &workflow.PromptGuard{Mode: PromptGuardError, ContextWindow: 2, Reserve: 1}, true)
+	assert.Error(t, err)
+}
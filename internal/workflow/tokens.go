@@ -0,0 +1,265 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// Prompt guard modes
+const (
+	PromptGuardWarn  = "warn"
+	PromptGuardError = "error"
+	PromptGuardTrim  = "trim"
+)
+
+// defaultResponseReserve is the number of tokens kept free for the model's response
+const defaultResponseReserve = 8192
+
+// charsPerToken approximates how many characters of English text or code each
+// provider family's tokenizer packs into one token
+var charsPerToken = map[string]float64{
+	"claude": 3.5,
+	"gemini": 4.0,
+	"qwen":   3.7,
+}
+
+// contextWindows are the default context window sizes in tokens by provider family
+var contextWindows = map[string]int{
+	"claude": 200000,
+	"gemini": 1048576,
+	"qwen":   262144,
+}
+
+var (
+	// contextBlockPattern matches {{#context priority=low}}...{{/context}} blocks
+	contextBlockPattern = regexp.MustCompile(`(?s)\{\{#context(?:\s+priority=(\w+))?\s*\}\}(.*?)\{\{/context\}\}`)
+	// fileReferencePattern matches @path references that providers expand into file contents
+	fileReferencePattern = regexp.MustCompile(`@([^\s'"\x60()]+)`)
+)
+
+// EstimateTokens approximates the token count of text for a provider family.
+// Non-ASCII characters (e.g. CJK) are counted as one token each.
+func EstimateTokens(provider, text string) int {
+	ratio, ok := charsPerToken[strings.ToLower(provider)]
+	if !ok {
+		ratio = 4.0
+	}
+
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+
+	return int(math.Ceil(float64(ascii)/ratio)) + other
+}
+
+// ContextWindow returns the context window in tokens for a provider, or 0 if unknown
+func ContextWindow(provider string) int {
+	return contextWindows[strings.ToLower(provider)]
+}
+
+// contextBlock is a trimmable section of a prompt
+type contextBlock struct {
+	start, end int
+	priority   int
+	content    string
+}
+
+// parseContextBlocks finds the {{#context}} blocks in a prompt
+func parseContextBlocks(prompt string) []contextBlock {
+	var blocks []contextBlock
+	for _, m := range contextBlockPattern.FindAllStringSubmatchIndex(prompt, -1) {
+		priority := ""
+		if m[2] >= 0 {
+			priority = prompt[m[2]:m[3]]
+		}
+		blocks = append(blocks, contextBlock{
+			start:    m[0],
+			end:      m[1],
+			priority: parsePriority(priority),
+			content:  prompt[m[4]:m[5]],
+		})
+	}
+	return blocks
+}
+
+// parsePriority converts low/medium/high or a number into a priority; higher is kept longer
+func parsePriority(priority string) int {
+	switch strings.ToLower(priority) {
+	case "low":
+		return 1
+	case "", "medium", "normal":
+		return 2
+	case "high":
+		return 3
+	}
+	if n, err := strconv.Atoi(priority); err == nil {
+		return n
+	}
+	return 2
+}
+
+// renderContextBlocks replaces each block with its content, leaving out dropped blocks
+func renderContextBlocks(prompt string, blocks []contextBlock, dropped map[int]bool) string {
+	var sb strings.Builder
+	last := 0
+	for i, block := range blocks {
+		sb.WriteString(prompt[last:block.start])
+		if !dropped[i] {
+			sb.WriteString(block.content)
+		}
+		last = block.end
+	}
+	sb.WriteString(prompt[last:])
+	return sb.String()
+}
+
+// estimatePromptTokens estimates a composed prompt including the files it references with @path
+func estimatePromptTokens(provider, prompt string) int {
+	total := EstimateTokens(provider, prompt)
+
+	seen := make(map[string]bool)
+	for _, m := range fileReferencePattern.FindAllStringSubmatch(prompt, -1) {
+		path := strings.TrimRight(m[1], ".,;:")
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		total += EstimateTokens(provider, string(content))
+	}
+
+	return total
+}
+
+// PromptSize reports the estimated size of a prompt against its budget
+type PromptSize struct {
+	Tokens  int
+	Budget  int
+	Trimmed int // Number of context blocks dropped to fit
+}
+
+// guardPrompt renders the context blocks of a prompt and checks it fits the
+// provider's context window. In trim mode, blocks are dropped lowest priority
+// first (later blocks before earlier ones) until the prompt fits.
+func guardPrompt(provider, prompt string, guard *workflow.PromptGuard) (string, PromptSize, error) {
+	blocks := parseContextBlocks(prompt)
+	rendered := renderContextBlocks(prompt, blocks, nil)
+
+	mode := PromptGuardWarn
+	window := ContextWindow(provider)
+	reserve := defaultResponseReserve
+	if guard != nil {
+		if guard.Mode != "" {
+			mode = guard.Mode
+		}
+		if guard.ContextWindow > 0 {
+			window = guard.ContextWindow
+		}
+		if guard.Reserve > 0 {
+			reserve = guard.Reserve
+		}
+	}
+
+	size := PromptSize{Tokens: estimatePromptTokens(provider, rendered)}
+	if window == 0 {
+		// Unknown window, nothing to check against
+		return rendered, size, nil
+	}
+	size.Budget = window - reserve
+	if size.Tokens <= size.Budget {
+		return rendered, size, nil
+	}
+
+	switch mode {
+	case PromptGuardWarn:
+		return rendered, size, nil
+	case PromptGuardError:
+		return rendered, size, Classify(ErrorBudgetExceeded, fmt.Errorf("prompt is ~%d tokens, over the %d token budget for %s", size.Tokens, size.Budget, provider))
+	case PromptGuardTrim:
+	default:
+		return rendered, size, fmt.Errorf("unknown prompt_guard mode: %s", mode)
+	}
+
+	order := make([]int, len(blocks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if blocks[order[a]].priority != blocks[order[b]].priority {
+			return blocks[order[a]].priority < blocks[order[b]].priority
+		}
+		return order[a] > order[b]
+	})
+
+	dropped := make(map[int]bool)
+	for _, i := range order {
+		dropped[i] = true
+		size.Trimmed++
+		rendered = renderContextBlocks(prompt, blocks, dropped)
+		size.Tokens = estimatePromptTokens(provider, rendered)
+		if size.Tokens <= size.Budget {
+			return rendered, size, nil
+		}
+	}
+
+	return rendered, size, Classify(ErrorBudgetExceeded, fmt.Errorf("prompt is ~%d tokens after trimming all context blocks, over the %d token budget for %s", size.Tokens, size.Budget, provider))
+}
+
+// recordPromptSize keeps an agent's prompt size for the run summary and
+// records what the prompt guard did, see prepareAgentPrompt
+func (e *InteractiveExecutor) recordPromptSize(agent *workflow.Agent, size PromptSize, err error) {
+	if err != nil {
+		e.recordDecision(agent, DecisionPromptGuard, "failed", err.Error())
+		return
+	}
+
+	e.mu.Lock()
+	if e.promptTokens == nil {
+		e.promptTokens = make(map[string]int)
+	}
+	e.promptTokens[agent.ID] = size.Tokens
+	e.mu.Unlock()
+
+	if size.Trimmed > 0 {
+		e.recordDecision(agent, DecisionPromptGuard, "trimmed", fmt.Sprintf("%d context block(s) trimmed to ~%d/%d tokens", size.Trimmed, size.Tokens, size.Budget))
+	} else if size.Budget > 0 && size.Tokens > size.Budget {
+		e.recordDecision(agent, DecisionPromptGuard, "warned", fmt.Sprintf("~%d tokens, over the %d token budget", size.Tokens, size.Budget))
+	}
+}
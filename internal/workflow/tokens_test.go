@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	text := strings.Repeat("a", 700)
+	assert.Equal(t, 200, EstimateTokens("claude", text))
+	assert.Equal(t, 175, EstimateTokens("gemini", text))
+	assert.Equal(t, 175, EstimateTokens("unknown", text))
+	assert.Equal(t, 3, EstimateTokens("claude", "日本語"))
+	assert.Equal(t, 0, EstimateTokens("claude", ""))
+
+	assert.Equal(t, 200000, ContextWindow("Claude"))
+	assert.Equal(t, 0, ContextWindow("mock"))
+}
+
+func TestGuardPrompt(t *testing.T) {
+	prompt := "Task\n{{#context priority=low}}LOW{{/context}}\n{{#context priority=high}}HIGH{{/context}}\n{{#context}}MEDIUM{{/context}}"
+
+	t.Run("renders blocks when the prompt fits", func(t *testing.T) {
+		out, size, err := guardPrompt("claude", prompt, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Task\nLOW\nHIGH\nMEDIUM", out)
+		assert.Equal(t, 200000-defaultResponseReserve, size.Budget)
+	})
+
+	t.Run("warns by default", func(t *testing.T) {
+		out, size, err := guardPrompt("claude", prompt, &workflow.PromptGuard{ContextWindow: 10, Reserve: 5})
+		require.NoError(t, err)
+		assert.Equal(t, "Task\nLOW\nHIGH\nMEDIUM", out)
+		assert.Greater(t, size.Tokens, size.Budget)
+	})
+
+	t.Run("errors when over budget", func(t *testing.T) {
+		_, _, err := guardPrompt("claude", prompt, &workflow.PromptGuard{Mode: PromptGuardError, ContextWindow: 10, Reserve: 5})
+		assert.Error(t, err)
+	})
+
+	t.Run("trims lowest priority first", func(t *testing.T) {
+		long := "Task {{#context priority=low}}" + strings.Repeat("x", 400) + "{{/context}}" +
+			"{{#context priority=high}}keep{{/context}}" +
+			"{{#context}}" + strings.Repeat("y", 40) + "{{/context}}"
+
+		out, size, err := guardPrompt("gemini", long, &workflow.PromptGuard{Mode: PromptGuardTrim, ContextWindow: 40, Reserve: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, size.Trimmed)
+		assert.Equal(t, "Task keep"+strings.Repeat("y", 40), out)
+
+		_, _, err = guardPrompt("gemini", long, &workflow.PromptGuard{Mode: PromptGuardTrim, ContextWindow: 2, Reserve: 1})
+		assert.Error(t, err, "still too large after trimming everything")
+	})
+
+	t.Run("counts referenced files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "context.md")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("z", 4000)), 0644))
+
+		_, size, err := guardPrompt("gemini", "Read @"+path+".", nil)
+		require.NoError(t, err)
+		assert.Greater(t, size.Tokens, 1000)
+	})
+
+	t.Run("rejects unknown modes", func(t *testing.T) {
+		_, _, err := guardPrompt("claude", prompt, &workflow.PromptGuard{Mode: "shrink", ContextWindow: 10, Reserve: 5})
+		assert.Error(t, err)
+	})
+}
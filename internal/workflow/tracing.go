@@ -0,0 +1,240 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/telemetry"
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// RunTracer turns a run's events into an OpenTelemetry trace: the run is the
+// root span and every agent a child span. Spans are exported on Close.
+type RunTracer struct {
+	mu       sync.Mutex
+	exporter telemetry.Exporter
+	target   string
+	traceID  string
+	runID    string
+	workflow string
+
+	root     *telemetry.Span
+	agents   map[string]*telemetry.Span
+	finished []telemetry.Span
+}
+
+// NewRunTracer creates a tracer exporting to target, see telemetry.NewExporter
+func NewRunTracer(target string) (*RunTracer, error) {
+	exporter, err := telemetry.NewExporter(target)
+	if err != nil {
+		return nil, err
+	}
+	return &RunTracer{
+		exporter: exporter,
+		target:   target,
+		traceID:  telemetry.NewTraceID(),
+		agents:   make(map[string]*telemetry.Span),
+	}, nil
+}
+
+// SetRun records the run ID and workflow name put on every span
+func (t *RunTracer) SetRun(runID, workflowName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runID = runID
+	t.workflow = workflowName
+}
+
+// HandleEvent updates the trace from an executor event
+func (t *RunTracer) HandleEvent(event workflow.WorkflowEvent) {
+	if event.Type == workflow.EventOutputChunk {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Type {
+	case workflow.EventWorkflowStart:
+		t.root = &telemetry.Span{
+			TraceID: t.traceID,
+			SpanID:  telemetry.NewSpanID(),
+			Name:    "workflow " + t.workflow,
+			Kind:    telemetry.SpanKindInternal,
+			Start:   event.Timestamp,
+			Attributes: map[string]interface{}{
+				"opun.run_id":       t.runID,
+				"opun.workflow":     t.workflow,
+				"opun.agents.total": event.Data["total_agents"],
+			},
+		}
+
+	case workflow.EventAgentStart:
+		span := &telemetry.Span{
+			TraceID:      t.traceID,
+			SpanID:       telemetry.NewSpanID(),
+			ParentSpanID: t.rootSpanID(),
+			Name:         "agent " + event.AgentID,
+			Kind:         telemetry.SpanKindClient,
+			Start:        event.Timestamp,
+			Attributes: map[string]interface{}{
+				"opun.run_id":          t.runID,
+				"opun.agent.id":        event.AgentID,
+				"opun.agent.name":      event.Data["name"],
+				"opun.agent.index":     event.Data["index"],
+				"opun.step.type":       event.Data["type"],
+				"gen_ai.system":        nonEmpty(event.Data["provider"]),
+				"gen_ai.request.model": nonEmpty(event.Data["model"]),
+			},
+		}
+		t.agents[event.AgentID] = span
+
+	case workflow.EventAgentRetry:
+		if span := t.agents[event.AgentID]; span != nil {
+			span.Events = append(span.Events, telemetry.SpanEvent{Name: "retry", Time: event.Timestamp,
+				Attributes: map[string]interface{}{"message": event.Message}})
+		}
+
+	case workflow.EventAgentComplete, workflow.EventAgentError:
+		span := t.agents[event.AgentID]
+		if span == nil {
+			return
+		}
+		for key, attr := range agentSpanAttributes {
+			if v, ok := event.Data[key]; ok {
+				span.Attributes[attr] = nonEmpty(v)
+			}
+		}
+		if event.Type == workflow.EventAgentError {
+			span.Error = event.Message
+		}
+		t.endSpan(span, event.Timestamp)
+		delete(t.agents, event.AgentID)
+
+	case workflow.EventAgentSkipped:
+		// Skipped agents never start, so they get an empty span
+		t.endSpan(&telemetry.Span{
+			TraceID:      t.traceID,
+			SpanID:       telemetry.NewSpanID(),
+			ParentSpanID: t.rootSpanID(),
+			Name:         "agent " + event.AgentID,
+			Kind:         telemetry.SpanKindInternal,
+			Start:        event.Timestamp,
+			Attributes: map[string]interface{}{
+				"opun.run_id":    t.runID,
+				"opun.agent.id":  event.AgentID,
+				"opun.skipped":   true,
+				"opun.condition": event.Data["condition"],
+			},
+		}, event.Timestamp)
+
+	case workflow.EventWorkflowComplete, workflow.EventWorkflowError:
+		if t.root == nil {
+			return
+		}
+		if event.Type == workflow.EventWorkflowError {
+			t.root.Error = event.Message
+		}
+		t.endOpenSpans(event.Timestamp, "")
+		t.endSpan(t.root, event.Timestamp)
+		t.root = nil
+	}
+}
+
+// agentSpanAttributes maps agent event data to span attributes
+var agentSpanAttributes = map[string]string{
+	"output":         "opun.agent.output",
+	"prompt_tokens":  "opun.prompt.estimated_tokens",
+	"cpu_seconds":    "opun.resources.cpu_seconds",
+	"peak_memory_mb": "opun.resources.peak_memory_mb",
+	"killed":         "opun.resources.killed",
+}
+
+// ProviderEnv returns environment variables for a provider started for
+// agentID, so tools it calls through opun's MCP server join the agent's span
+func (t *RunTracer) ProviderEnv(agentID string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	env := []string{telemetry.TargetEnv + "=" + t.target}
+	if span := t.agents[agentID]; span != nil {
+		env = append(env, telemetry.TraceParentEnv+"="+telemetry.TraceParent(t.traceID, span.SpanID))
+	}
+	return env
+}
+
+// Close ends any spans still open, e.g. after an interrupt, and exports the
+// trace
+func (t *RunTracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.endOpenSpans(now, "run ended before the agent finished")
+	if t.root != nil {
+		t.root.Error = "run ended before it finished"
+		t.endSpan(t.root, now)
+		t.root = nil
+	}
+	spans := t.finished
+	t.finished = nil
+	return t.exporter.Export(spans)
+}
+
+// rootSpanID returns the run span's ID. Callers must hold the lock.
+func (t *RunTracer) rootSpanID() string {
+	if t.root == nil {
+		return ""
+	}
+	return t.root.SpanID
+}
+
+// endSpan finishes a span. Callers must hold the lock.
+func (t *RunTracer) endSpan(span *telemetry.Span, end time.Time) {
+	span.End = end
+	t.finished = append(t.finished, *span)
+}
+
+// endOpenSpans finishes agent spans that never completed. Callers must hold
+// the lock.
+func (t *RunTracer) endOpenSpans(end time.Time, reason string) {
+	for id, span := range t.agents {
+		if reason != "" {
+			span.Error = reason
+		}
+		t.endSpan(span, end)
+		delete(t.agents, id)
+	}
+}
+
+// nonEmpty drops empty strings so they don't become empty attributes
+func nonEmpty(v interface{}) interface{} {
+	if s, ok := v.(string); ok && s == "" {
+		return nil
+	}
+	return v
+}
+
+// SetTracer makes providers join the tracer's trace. Register the tracer's
+// HandleEvent as an event handler too.
+func (e *InteractiveExecutor) SetTracer(tracer *RunTracer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tracer = tracer
+}
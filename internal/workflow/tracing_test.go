@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/telemetry"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportedSpan is the part of an OTLP JSON span the tests look at
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Attributes   []struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	} `json:"attributes"`
+	Events []struct {
+		Name string `json:"name"`
+	} `json:"events"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+func (s exportedSpan) attr(key string) interface{} {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			for _, v := range a.Value {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+func readSpans(t *testing.T, path string) map[string]exportedSpan {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []exportedSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(data))), &request))
+
+	spans := make(map[string]exportedSpan)
+	for _, span := range request.ResourceSpans[0].ScopeSpans[0].Spans {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func TestRunTracer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	tracer, err := NewRunTracer("file:" + path)
+	require.NoError(t, err)
+	tracer.SetRun("run-1", "review")
+
+	now := time.Now()
+	event := func(eventType workflow.EventType, agentID, message string, data map[string]interface{}) {
+		now = now.Add(time.Second)
+		tracer.HandleEvent(workflow.WorkflowEvent{Type: eventType, AgentID: agentID, Message: message, Data: data, Timestamp: now})
+	}
+
+	event(workflow.EventWorkflowStart, "", "", map[string]interface{}{"total_agents": 3})
+	event(workflow.EventAgentStart, "analyze", "", map[string]interface{}{"index": 0, "provider": "claude", "model": "sonnet"})
+
+	env := tracer.ProviderEnv("analyze")
+	require.Len(t, env, 2)
+	assert.Equal(t, telemetry.TargetEnv+"=file:"+path, env[0])
+
+	event(workflow.EventAgentRetry, "analyze", "retrying", nil)
+	event(workflow.EventAgentComplete, "analyze", "", map[string]interface{}{"prompt_tokens": 1200, "output": ""})
+	event(workflow.EventAgentSkipped, "fix", "", map[string]interface{}{"condition": "false"})
+	event(workflow.EventAgentStart, "report", "", map[string]interface{}{"index": 2, "provider": "gemini"})
+	event(workflow.EventAgentError, "report", "provider exited", nil)
+	event(workflow.EventWorkflowError, "", "agent report failed", nil)
+	require.NoError(t, tracer.Close())
+
+	spans := readSpans(t, path)
+	require.Len(t, spans, 4)
+
+	root := spans["workflow review"]
+	assert.Empty(t, root.ParentSpanID)
+	assert.Equal(t, "run-1", root.attr("opun.run_id"))
+	assert.Equal(t, "agent report failed", root.Status.Message)
+
+	analyze := spans["agent analyze"]
+	assert.Equal(t, root.TraceID, analyze.TraceID)
+	assert.Equal(t, root.SpanID, analyze.ParentSpanID)
+	assert.Equal(t, "claude", analyze.attr("gen_ai.system"))
+	assert.Equal(t, "sonnet", analyze.attr("gen_ai.request.model"))
+	assert.Equal(t, "1200", analyze.attr("opun.prompt.estimated_tokens"))
+	assert.Nil(t, analyze.attr("opun.agent.output"))
+	require.Len(t, analyze.Events, 1)
+	assert.Equal(t, telemetry.TraceParentEnv+"="+telemetry.TraceParent(analyze.TraceID, analyze.SpanID), env[1])
+
+	assert.Equal(t, true, spans["agent fix"].attr("opun.skipped"))
+	assert.Equal(t, "provider exited", spans["agent report"].Status.Message)
+}
+
+func TestRunTracerCloseEndsOpenSpans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	tracer, err := NewRunTracer("file:" + path)
+	require.NoError(t, err)
+	tracer.SetRun("run-2", "review")
+
+	tracer.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventWorkflowStart, Timestamp: time.Now()})
+	tracer.HandleEvent(workflow.WorkflowEvent{Type: workflow.EventAgentStart, AgentID: "analyze", Timestamp: time.Now()})
+	require.NoError(t, tracer.Close())
+
+	spans := readSpans(t, path)
+	require.Len(t, spans, 2)
+	assert.Contains(t, spans["agent analyze"].Status.Message, "before the agent finished")
+	assert.Contains(t, spans["workflow review"].Status.Message, "before it finished")
+}
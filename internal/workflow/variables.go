@@ -0,0 +1,161 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// validateVariableRules checks a variable's validation rules, and that its
+// default passes them
+func validateVariableRules(v workflow.Variable) error {
+	rules := v.Validation
+	if rules == nil {
+		return nil
+	}
+	if rules.Pattern != "" {
+		if _, err := variablePattern(rules.Pattern); err != nil {
+			return fmt.Errorf("variable %s: invalid pattern: %w", v.Name, err)
+		}
+	}
+	if rules.Min != nil || rules.Max != nil {
+		if v.Type != "number" {
+			return fmt.Errorf("variable %s: min and max need type: number", v.Name)
+		}
+		if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+			return fmt.Errorf("variable %s: min %v is greater than max %v", v.Name, *rules.Min, *rules.Max)
+		}
+	}
+	// A missing file default may be created by the time the workflow runs
+	if v.DefaultValue != nil {
+		unchecked := *rules
+		unchecked.MustExist = false
+		v.Validation = &unchecked
+		if err := checkVariable(v, v.DefaultValue); err != nil {
+			return fmt.Errorf("invalid default for %w", err)
+		}
+	}
+	return nil
+}
+
+// variablePattern compiles a validation pattern to match whole values
+func variablePattern(pattern string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// checkVariable checks a value against a variable's validation rules. Empty
+// values are left to the required check.
+func checkVariable(v workflow.Variable, value interface{}) error {
+	rules := v.Validation
+	if rules == nil || value == nil {
+		return nil
+	}
+	text := strings.TrimSpace(fmt.Sprintf("%v", value))
+	if text == "" {
+		return nil
+	}
+
+	var problem string
+	switch {
+	case len(rules.Enum) > 0 && !contains(rules.Enum, text):
+		problem = fmt.Sprintf("must be one of %s", strings.Join(rules.Enum, ", "))
+	case rules.Pattern != "":
+		if re, err := variablePattern(rules.Pattern); err == nil && !re.MatchString(text) {
+			problem = fmt.Sprintf("doesn't match %s", rules.Pattern)
+		}
+	}
+	if problem == "" && (rules.Min != nil || rules.Max != nil) {
+		problem = checkVariableRange(rules, text)
+	}
+	if problem == "" && rules.MustExist {
+		path := text
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		if _, err := os.Stat(path); err != nil {
+			problem = "doesn't exist"
+		}
+	}
+	if problem == "" {
+		return nil
+	}
+
+	msg := fmt.Sprintf("variable %s: %q %s", v.Name, text, problem)
+	if rules.Message != "" {
+		msg += " (" + rules.Message + ")"
+	}
+	return errors.New(msg)
+}
+
+// checkVariableRange checks a number against min and max, returning what is
+// wrong with it
+func checkVariableRange(rules *workflow.VariableValidation, text string) string {
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return "is not a number"
+	}
+	switch {
+	case rules.Min != nil && rules.Max != nil && (n < *rules.Min || n > *rules.Max):
+		return fmt.Sprintf("must be between %v and %v", *rules.Min, *rules.Max)
+	case rules.Min != nil && n < *rules.Min:
+		return fmt.Sprintf("must be at least %v", *rules.Min)
+	case rules.Max != nil && n > *rules.Max:
+		return fmt.Sprintf("must be at most %v", *rules.Max)
+	}
+	return ""
+}
+
+// ValidateVariables checks the values a run starts with, from --var flags
+// and defaults, against the workflow's variable validation rules. Every
+// invalid value is reported at once, classified as invalid_variable.
+func ValidateVariables(wf *workflow.Workflow, values map[string]interface{}) error {
+	var errs []error
+	for _, v := range wf.Variables {
+		if err := checkVariable(v, values[v.Name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return Classify(ErrorInvalidVariable, errors.Join(errs...))
+}
+
+// workflowVariable returns the declaration of a workflow variable
+func workflowVariable(wf *workflow.Workflow, name string) (workflow.Variable, bool) {
+	if wf != nil {
+		for _, v := range wf.Variables {
+			if v.Name == name {
+				return v, true
+			}
+		}
+	}
+	return workflow.Variable{}, false
+}
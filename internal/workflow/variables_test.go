@@ -0,0 +1,137 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validatedWorkflow = `
+name: release
+variables:
+  - name: branch
+    validation:
+      pattern: "[a-z0-9/-]+"
+      message: lowercase branch names only
+  - name: retries
+    type: number
+    default: 3
+    validation:
+      min: 1
+      max: 5
+  - name: env
+    validation:
+      enum: [staging, production]
+  - name: spec
+    type: file
+    validation:
+      must_exist: true
+agents:
+  - id: ship
+    provider: claude
+    prompt: Release {{branch}} to {{env}}
+`
+
+func TestParseVariableValidation(t *testing.T) {
+	p := NewParser(t.TempDir())
+	wf, err := p.Parse([]byte(validatedWorkflow))
+	require.NoError(t, err)
+	require.NotNil(t, wf.Variables[1].Validation)
+	assert.Equal(t, 5.0, *wf.Variables[1].Validation.Max)
+
+	for rules, want := range map[string]string{
+		`validation: {pattern: "[a-z"}`:          "variable v: invalid pattern",
+		`validation: {min: 1}`:                   "min and max need type: number",
+		`default: c, validation: {enum: [a, b]}`: `invalid default for variable v: "c" must be one of a, b`,
+	} {
+		_, err := p.Parse([]byte(`
+name: bad
+variables:
+  - {name: v, ` + rules + `}
+agents:
+  - id: a
+    provider: claude
+    prompt: hi
+`))
+		assert.ErrorContains(t, err, want, rules)
+	}
+}
+
+func TestValidateVariables(t *testing.T) {
+	wf, err := NewParser(t.TempDir()).Parse([]byte(validatedWorkflow))
+	require.NoError(t, err)
+	spec := filepath.Join(t.TempDir(), "spec.md")
+	require.NoError(t, os.WriteFile(spec, []byte("# Spec"), 0644))
+
+	valid := map[string]interface{}{"branch": "release/1-2", "retries": "5", "env": "staging", "spec": spec}
+	assert.NoError(t, ValidateVariables(wf, valid))
+	// Empty and missing values are left to the required check
+	assert.NoError(t, ValidateVariables(wf, map[string]interface{}{"branch": ""}))
+
+	err = ValidateVariables(wf, map[string]interface{}{
+		"branch":  "Feature X",
+		"retries": "9",
+		"env":     "prod",
+		"spec":    filepath.Join(t.TempDir(), "missing.md"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, ErrorInvalidVariable, ClassOf(err))
+	assert.Equal(t, 2, ExitCode(err))
+	assert.Contains(t, err.Error(), `variable branch: "Feature X" doesn't match [a-z0-9/-]+ (lowercase branch names only)`)
+	assert.Contains(t, err.Error(), `variable retries: "9" must be between 1 and 5`)
+	assert.Contains(t, err.Error(), `variable env: "prod" must be one of staging, production`)
+	assert.Contains(t, err.Error(), "missing.md\" doesn't exist")
+
+	err = ValidateVariables(wf, map[string]interface{}{"retries": "lots"})
+	assert.ErrorContains(t, err, `variable retries: "lots" is not a number`)
+}
+
+func TestVariablePromptRejectsInvalidValues(t *testing.T) {
+	m := sendKeys(initialVariablePromptModel([]promptVariable{{
+		Name:       "env",
+		Validation: &workflow.VariableValidation{Enum: []string{"staging", "production"}},
+	}}),
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("prod")},
+		tea.KeyMsg{Type: tea.KeyEnter},
+	).(variablePromptModel)
+	assert.Contains(t, m.invalid, "must be one of staging, production")
+	assert.Contains(t, m.View(), "must be one of")
+	assert.Nil(t, m.values["env"])
+
+	m.inputs[0].SetValue("production")
+	m = sendKeys(m, tea.KeyMsg{Type: tea.KeyEnter}).(variablePromptModel)
+	assert.Empty(t, m.invalid)
+	assert.Equal(t, "production", m.values["env"])
+}
+
+func TestExecuteInputValidatesAnswers(t *testing.T) {
+	e := NewInteractiveExecutor()
+	e.workflow = &workflow.Workflow{Variables: []workflow.Variable{{
+		Name:       "ticket",
+		Validation: &workflow.VariableValidation{Pattern: `[A-Z]+-\d+`},
+	}}}
+	e.state = &workflow.ExecutionState{
+		Variables:   make(map[string]interface{}),
+		AgentStates: make(map[string]*workflow.AgentState),
+	}
+	agent := &workflow.Agent{ID: "ticket", Type: workflow.StepTypeInput, Prompt: "Which ticket?", Variable: "ticket"}
+
+	answers := []string{"fix it", "OPS-12"}
+	e.ask = func(string, []string, string) (string, error) {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	require.NoError(t, e.executeInput(agent))
+	assert.Equal(t, "OPS-12", e.state.Variables["ticket"])
+
+	e.ask = func(string, []string, string) (string, error) { return "nope", nil }
+	err := e.executeInput(agent)
+	assert.ErrorContains(t, err, `variable ticket: "nope" doesn't match`)
+	assert.Equal(t, ErrorInvalidVariable, ClassOf(err))
+}
@@ -0,0 +1,218 @@
+package workflow
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+)
+
+const (
+	// defaultWaitTimeout bounds how long a wait step polls
+	defaultWaitTimeout = 30 * time.Minute
+	// defaultWaitInterval is the time between checks of a wait step
+	defaultWaitInterval = 30 * time.Second
+)
+
+// waitPlan is a parsed wait step
+type waitPlan struct {
+	Delay    time.Duration
+	Command  string
+	File     string
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// isProviderStep reports whether a step starts a provider session
+func isProviderStep(agent *workflow.Agent) bool {
+	return agent.Type == "" || agent.Type == workflow.StepTypeAgent
+}
+
+// isWaitStep reports whether a step is a wait step
+func isWaitStep(agent *workflow.Agent) bool {
+	return agent.Type == workflow.StepTypeWait
+}
+
+// parseWaitStep validates a wait step and parses its durations
+func parseWaitStep(agent *workflow.Agent) (*waitPlan, error) {
+	plan := &waitPlan{
+		Timeout:  defaultWaitTimeout,
+		Interval: defaultWaitInterval,
+	}
+
+	var err error
+	if agent.Duration != "" {
+		if plan.Delay, err = time.ParseDuration(agent.Duration); err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", agent.Duration, err)
+		}
+	}
+
+	until := agent.Until
+	if until == nil {
+		if agent.Duration == "" {
+			return nil, fmt.Errorf("wait step needs a duration or an until condition")
+		}
+		return plan, nil
+	}
+
+	plan.Command, plan.File = until.Command, until.File
+	if (plan.Command == "") == (plan.File == "") {
+		return nil, fmt.Errorf("until needs exactly one of command or file")
+	}
+	if until.Timeout != "" {
+		if plan.Timeout, err = time.ParseDuration(until.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", until.Timeout, err)
+		}
+	}
+	if until.Interval != "" {
+		if plan.Interval, err = time.ParseDuration(until.Interval); err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", until.Interval, err)
+		}
+	}
+	return plan, nil
+}
+
+// pollUntil calls check every interval until it reports done, the timeout
+// passes or the context is canceled
+func pollUntil(ctx context.Context, interval, timeout time.Duration, check func(ctx context.Context) bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		if check(ctx) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return Classify(ErrorTimeout, fmt.Errorf("condition not met within %s", timeout))
+			}
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// sleepContext sleeps for d or until the context is canceled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// commandSucceeds runs a shell command, streaming its output, and reports
+// whether it exited 0
+func commandSucceeds(ctx context.Context, command string) bool {
+	shell, flag := "/bin/sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	// #nosec G204 -- the command comes from the workflow definition
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run() == nil
+}
+
+// fileExists reports whether a file or directory exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// runWaitPlan sleeps for the plan's delay, then polls its condition.
+// expand resolves workflow variables in the command or file path.
+func runWaitPlan(ctx context.Context, plan *waitPlan, expand func(string) string) error {
+	if plan.Delay > 0 {
+		fmt.Printf("⏳ Waiting %s\n", plan.Delay)
+		if err := sleepContext(ctx, plan.Delay); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case plan.Command != "":
+		command := expand(plan.Command)
+		fmt.Printf("⏳ Waiting for `%s` to succeed (timeout %s)\n", command, plan.Timeout)
+		return pollUntil(ctx, plan.Interval, plan.Timeout, func(ctx context.Context) bool {
+			return commandSucceeds(ctx, command)
+		})
+	case plan.File != "":
+		path := expand(plan.File)
+		fmt.Printf("⏳ Waiting for %s to appear (timeout %s)\n", path, plan.Timeout)
+		return pollUntil(ctx, plan.Interval, plan.Timeout, func(ctx context.Context) bool {
+			return fileExists(path)
+		})
+	}
+	return nil
+}
+
+// expandVariables replaces {{name}} workflow variables in a string
+func (e *InteractiveExecutor) expandVariables(s string) string {
+	return substituteVariables(s, e.state.Variables)
+}
+
+// substituteVariables replaces {{name}} placeholders with variable values
+func substituteVariables(s string, vars map[string]interface{}) string {
+	for name, value := range vars {
+		s = strings.ReplaceAll(s, fmt.Sprintf("{{%s}}", name), fmt.Sprintf("%v", value))
+	}
+	return s
+}
+
+// executeWait runs a wait step: a fixed sleep, polling a command until it
+// succeeds, or waiting for a file to appear. A duration before an until
+// condition is an initial delay.
+func (e *InteractiveExecutor) executeWait(ctx context.Context, agent *workflow.Agent) error {
+	startTime := time.Now()
+	agentState := &workflow.AgentState{
+		AgentID:   agent.ID,
+		StartTime: &startTime,
+		Status:    workflow.StatusRunning,
+		Attempts:  1,
+	}
+
+	e.mu.Lock()
+	e.state.AgentStates[agent.ID] = agentState
+	e.state.CurrentAgent = agent.Name
+	e.mu.Unlock()
+
+	plan, err := parseWaitStep(agent)
+	if err != nil {
+		return e.handleAgentError(agent, agentState, err)
+	}
+
+	if err := runWaitPlan(ctx, plan, e.expandVariables); err != nil {
+		return e.handleAgentError(agent, agentState, fmt.Errorf("wait step %s: %w", agent.ID, err))
+	}
+
+	endTime := time.Now()
+	agentState.Status = workflow.StatusCompleted
+	agentState.EndTime = &endTime
+	fmt.Printf("✅ %s done after %s\n", agent.Name, endTime.Sub(startTime).Round(time.Second))
+	return nil
+}
@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWaitStep(t *testing.T) {
+	plan, err := parseWaitStep(&workflow.Agent{Type: "wait", Duration: "90s"})
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, plan.Delay)
+	assert.Empty(t, plan.Command)
+
+	plan, err = parseWaitStep(&workflow.Agent{Type: "wait", Until: &workflow.WaitCondition{
+		Command: "gh pr checks --watch",
+		Timeout: "45m",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "gh pr checks --watch", plan.Command)
+	assert.Equal(t, 45*time.Minute, plan.Timeout)
+	assert.Equal(t, defaultWaitInterval, plan.Interval)
+
+	invalid := []*workflow.Agent{
+		{Type: "wait"},
+		{Type: "wait", Duration: "soon"},
+		{Type: "wait", Until: &workflow.WaitCondition{}},
+		{Type: "wait", Until: &workflow.WaitCondition{Command: "true", File: "done"}},
+		{Type: "wait", Until: &workflow.WaitCondition{File: "done", Timeout: "1 hour"}},
+		{Type: "wait", Until: &workflow.WaitCondition{File: "done", Interval: "x"}},
+	}
+	for _, agent := range invalid {
+		_, err := parseWaitStep(agent)
+		assert.Error(t, err)
+	}
+}
+
+func TestParseWaitStepWorkflow(t *testing.T) {
+	p := NewParser(t.TempDir())
+
+	wf, err := p.Parse([]byte(`
+name: ci-review
+agents:
+  - id: push
+    provider: claude
+    prompt: Push the branch
+  - id: ci
+    type: wait
+    until:
+      command: gh pr checks --watch
+      timeout: 30m
+  - id: review
+    provider: gemini
+    prompt: Review the CI results
+`))
+	require.NoError(t, err)
+	assert.Equal(t, workflow.StepTypeWait, wf.Agents[1].Type)
+	assert.Equal(t, "gh pr checks --watch", wf.Agents[1].Until.Command)
+
+	_, err = p.Parse([]byte(`
+name: broken
+agents:
+  - id: ci
+    type: wait
+`))
+	assert.ErrorContains(t, err, "duration or an until condition")
+
+	_, err = p.Parse([]byte(`
+name: broken
+agents:
+  - id: ci
+    type: sleep
+`))
+	assert.ErrorContains(t, err, "unknown step type")
+}
+
+func TestPollUntil(t *testing.T) {
+	calls := 0
+	err := pollUntil(context.Background(), time.Millisecond, time.Second, func(ctx context.Context) bool {
+		calls++
+		return calls == 3
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	err = pollUntil(context.Background(), time.Millisecond, 20*time.Millisecond, func(ctx context.Context) bool {
+		return false
+	})
+	assert.ErrorContains(t, err, "not met within")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = pollUntil(ctx, time.Millisecond, time.Second, func(ctx context.Context) bool {
+		return false
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPollUntilFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ready")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = os.WriteFile(path, nil, 0644)
+	}()
+
+	err := pollUntil(context.Background(), time.Millisecond, time.Second, func(ctx context.Context) bool {
+		return fileExists(path)
+	})
+	assert.NoError(t, err)
+}
+
+func TestCommandSucceeds(t *testing.T) {
+	assert.True(t, commandSucceeds(context.Background(), "exit 0"))
+	assert.False(t, commandSucceeds(context.Background(), "exit 3"))
+}
@@ -46,14 +46,29 @@ type StandardAction struct {
 	Version     string `json:"version"`
 
 	// Execution details - pick one
-	Command     string `json:"command,omitempty"`      // Direct command to execute
-	WorkflowRef string `json:"workflow_ref,omitempty"` // Reference to a workflow
-	PromptRef   string `json:"prompt_ref,omitempty"`   // Reference to a prompt
+	Command     string       `json:"command,omitempty"`      // Direct command to execute
+	WorkflowRef string       `json:"workflow_ref,omitempty"` // Reference to a workflow
+	PromptRef   string       `json:"prompt_ref,omitempty"`   // Reference to a prompt
+	Steps       []ActionStep `json:"steps,omitempty"`        // Sequence of commands run as one unit
+
+	// Shared execution environment for commands and steps
+	WorkDir string            `json:"workdir,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
 
 	// Provider support
 	Providers []string `json:"providers,omitempty"` // Empty means all providers
 }
 
+// ActionStep is a single command in a multi-step action
+type ActionStep struct {
+	Name            string `yaml:"name,omitempty" json:"name,omitempty"`
+	Command         string `yaml:"command" json:"command"`
+	If              string `yaml:"if,omitempty" json:"if,omitempty"`                               // success (default), failure, always or an expression, judged on the previous step
+	ExitCodes       []int  `yaml:"exit_codes,omitempty" json:"exit_codes,omitempty"`               // exit codes that count as success, defaults to 0
+	ContinueOnError bool   `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"` // a failure does not abort the action
+	Rollback        string `yaml:"rollback,omitempty" json:"rollback,omitempty"`                   // undoes this step if a later step fails
+}
+
 // ActionRegistry manages standardized actions
 type ActionRegistry interface {
 	// Register a new action
@@ -47,16 +47,26 @@ type PromptMetadata struct {
 	Variables   []PromptVariable       `json:"variables"`
 	Includes    []string               `json:"includes"`
 	Extra       map[string]interface{} `json:"extra"`
+	// Variants are alternative bodies runs pick from, for A/B testing
+	Variants    []PromptVariant `json:"variants,omitempty"`
+	VariantMode string          `json:"variant_mode,omitempty"` // random (default) or round_robin
+}
+
+// PromptVariant is one alternative body of a prompt
+type PromptVariant struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
 }
 
 // PromptVariable defines a variable in a prompt template
 type PromptVariable struct {
 	Name         string      `json:"name"`
 	Description  string      `json:"description"`
-	Type         string      `json:"type"` // string, number, boolean, file, prompt
+	Type         string      `json:"type"` // string, number, boolean, file, prompt, select
 	Required     bool        `json:"required"`
 	DefaultValue interface{} `json:"default_value"`
-	Validation   string      `json:"validation"` // regex or validation rule
+	Validation   string      `json:"validation"`        // regex or validation rule
+	Options      []string    `json:"options,omitempty"` // allowed values for select-style variables
 }
 
 // Prompt defines the interface for prompts
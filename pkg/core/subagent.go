@@ -96,6 +96,9 @@ type SubAgentTask struct {
 	Constraints []string               `json:"constraints"`
 	Priority    int                    `json:"priority"`
 	Deadline    *time.Time             `json:"deadline,omitempty"`
+	// WorkDir is where the subagent works, the current directory when empty.
+	// Files it creates or modifies there become the result's artifacts.
+	WorkDir string `json:"work_dir,omitempty"`
 }
 
 // SubAgentResult represents the result of a subagent execution
@@ -121,6 +124,8 @@ type SubAgentArtifact struct {
 	ContentType string    `json:"content_type"`
 	Size        int64     `json:"size"`
 	Created     time.Time `json:"created"`
+	// Change is "created" or "modified" for files the subagent wrote
+	Change string `json:"change,omitempty"`
 }
 
 // ExecutionStatus represents the status of subagent execution
@@ -235,6 +240,11 @@ type SubAgentAdapter interface {
 	GetProviderConfig() map[string]interface{}
 }
 
+// FeedbackRatingKey in a result's metadata holds a user's rating (1-5) of
+// the agent's earlier work. Such a result is feedback for the router to
+// learn from, not a new execution.
+const FeedbackRatingKey = "feedback_rating"
+
 // TaskRouter routes tasks to appropriate subagents
 type TaskRouter interface {
 	// Route a task to the best subagent
@@ -243,7 +253,8 @@ type TaskRouter interface {
 	// Score agents for a given task
 	Score(task SubAgentTask, agent SubAgent) float64
 
-	// Learn from execution results
+	// Learn from execution results, and from user feedback on them (see
+	// FeedbackRatingKey)
 	Learn(task SubAgentTask, agent SubAgent, result *SubAgentResult)
 
 	// Get routing statistics
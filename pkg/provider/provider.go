@@ -0,0 +1,103 @@
+package provider
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rizome-dev/opun/internal/providers"
+)
+
+// Mock is the provider that echoes prompts back, for tests and replays
+const Mock = "mock"
+
+// Request is a prompt to run on a provider
+type Request struct {
+	Model  string
+	Prompt string
+	// WorkDir is where the provider runs, the current directory when empty
+	WorkDir string
+}
+
+// Provider runs prompts on an AI coding CLI without a terminal
+type Provider interface {
+	Name() string
+	Run(ctx context.Context, req Request) (string, error)
+}
+
+// Features is what an installed provider CLI supports, see Probe
+type Features = providers.Features
+
+// Known returns the names of the providers Opun can run
+func Known() []string {
+	return append(append([]string(nil), providers.KnownProviders...), Mock)
+}
+
+// New returns the provider CLI with the given name
+func New(name string) (Provider, error) {
+	name = strings.ToLower(name)
+	for _, known := range Known() {
+		if name == known {
+			return cliProvider{name: name}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown provider %q, expected one of %s", name, strings.Join(Known(), ", "))
+}
+
+// Func adapts a function to a Provider, e.g. to stub providers in tests
+func Func(name string, run func(ctx context.Context, req Request) (string, error)) Provider {
+	return funcProvider{name: name, run: run}
+}
+
+// Probe runs a provider CLI with --version and --help to find out what it
+// supports. A provider that isn't installed is reported, not an error.
+func Probe(ctx context.Context, name string) Features {
+	return providers.Probe(ctx, strings.ToLower(name))
+}
+
+// cliProvider runs prompts with a provider CLI's print mode
+type cliProvider struct {
+	name string
+}
+
+func (p cliProvider) Name() string { return p.name }
+
+func (p cliProvider) Run(ctx context.Context, req Request) (string, error) {
+	workDir := req.WorkDir
+	if workDir == "" {
+		var err error
+		if workDir, err = os.Getwd(); err != nil {
+			return "", err
+		}
+	}
+	return providers.RunHeadless(ctx, p.name, req.Model, req.Prompt, workDir)
+}
+
+// funcProvider is a Provider backed by a function
+type funcProvider struct {
+	name string
+	run  func(ctx context.Context, req Request) (string, error)
+}
+
+func (p funcProvider) Name() string { return p.name }
+
+func (p funcProvider) Run(ctx context.Context, req Request) (string, error) {
+	return p.run(ctx, req)
+}
@@ -0,0 +1,40 @@
+package provider
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Contains(t, Known(), "claude")
+	assert.Contains(t, Known(), Mock)
+
+	p, err := New("Mock")
+	require.NoError(t, err)
+	assert.Equal(t, Mock, p.Name())
+	answer, err := p.Run(context.Background(), Request{Prompt: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "Mock response to: hello", answer)
+
+	_, err = New("copilot")
+	assert.ErrorContains(t, err, "unknown provider")
+}
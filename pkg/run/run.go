@@ -0,0 +1,162 @@
+package run
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/provider"
+	wf "github.com/rizome-dev/opun/pkg/workflow"
+)
+
+// ErrorClass is the kind of failure that ended a run
+type ErrorClass = workflow.ErrorClass
+
+// Error classes, each with its own exit code
+const (
+	ErrorGeneric          = workflow.ErrorGeneric
+	ErrorProviderNotFound = workflow.ErrorProviderNotFound
+	ErrorAuth             = workflow.ErrorAuth
+	ErrorTimeout          = workflow.ErrorTimeout
+	ErrorGateFailed       = workflow.ErrorGateFailed
+	ErrorBudgetExceeded   = workflow.ErrorBudgetExceeded
+	ErrorLocked           = workflow.ErrorLocked
+	ErrorUserAborted      = workflow.ErrorUserAborted
+	ErrorInvalidVariable  = workflow.ErrorInvalidVariable
+)
+
+// Result is the outcome of a run
+type Result struct {
+	Workflow string
+	// Status is completed or failed
+	Status string
+	// Outputs maps agent IDs to their output files
+	Outputs map[string]string
+	// Final is the output file of the last agent
+	Final    string
+	Duration time.Duration
+}
+
+// Option configures a run
+type Option func(*options)
+
+type options struct {
+	vars      map[string]interface{}
+	outputDir string
+	provider  provider.Provider
+}
+
+// WithVariables sets workflow variables, overriding their defaults
+func WithVariables(vars map[string]interface{}) Option {
+	return func(o *options) {
+		for name, value := range vars {
+			o.vars[name] = value
+		}
+	}
+}
+
+// WithOutputDir writes the agents' outputs to dir instead of a new
+// temporary directory
+func WithOutputDir(dir string) Option {
+	return func(o *options) { o.outputDir = dir }
+}
+
+// WithProvider runs every agent on p instead of the provider it names
+func WithProvider(p provider.Provider) Option {
+	return func(o *options) { o.provider = p }
+}
+
+// Load reads and validates a workflow file
+func Load(path string) (*wf.Workflow, error) {
+	return workflow.NewParser(filepath.Dir(path)).ParseFile(path)
+}
+
+// Parse reads and validates a workflow definition
+func Parse(data []byte) (*wf.Workflow, error) {
+	return workflow.NewParser("").Parse(data)
+}
+
+// Variables merges a workflow's variable defaults with overrides
+func Variables(w *wf.Workflow, overrides map[string]string) map[string]interface{} {
+	variables := make(map[string]interface{})
+	for _, v := range w.Variables {
+		if v.DefaultValue != nil {
+			variables[v.Name] = v.DefaultValue
+		}
+	}
+	for k, v := range overrides {
+		variables[k] = v
+	}
+	return variables
+}
+
+// Run runs a workflow headlessly, one agent after the other, with each
+// prompt sent to its provider's print mode. Input steps need their variable
+// set. A failed run returns its result along with an error whose class is
+// given by Class.
+func Run(ctx context.Context, w *wf.Workflow, opts ...Option) (*Result, error) {
+	o := &options{vars: Variables(w, nil)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.outputDir == "" {
+		dir, err := os.MkdirTemp("", "opun-run-")
+		if err != nil {
+			return nil, err
+		}
+		o.outputDir = dir
+	}
+
+	runner := workflow.NewMatrixRunner(o.outputDir, 1)
+	runner.SetRunner(func(ctx context.Context, name, model, prompt string) (string, error) {
+		p := o.provider
+		if p == nil {
+			var err error
+			if p, err = provider.New(name); err != nil {
+				return "", workflow.Classify(ErrorProviderNotFound, err)
+			}
+		}
+		return p.Run(ctx, provider.Request{Model: model, Prompt: prompt})
+	})
+
+	result, err := runner.RunHeadless(ctx, w, o.vars)
+	if result.Name == "" && err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", w.Name, err)
+	}
+	return &Result{
+		Workflow: result.Name,
+		Status:   result.Status,
+		Outputs:  result.Outputs,
+		Final:    result.Final,
+		Duration: time.Duration(result.Duration * float64(time.Second)),
+	}, err
+}
+
+// Class returns the kind of failure err is, empty for nil
+func Class(err error) ErrorClass {
+	return workflow.ClassOf(err)
+}
+
+// ExitCode returns the exit code opun run uses for err, 0 when it's nil
+func ExitCode(err error) int {
+	return workflow.ExitCode(err)
+}
@@ -0,0 +1,93 @@
+package run
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/opun/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reviewWorkflow = `
+name: review
+variables:
+  - name: focus
+    default: style
+agents:
+  - id: plan
+    name: Planner
+    provider: claude
+    prompt: "Plan a {{focus}} review"
+  - id: fix
+    name: Fixer
+    provider: gemini
+    prompt: "Fix {{plan.output}}"
+`
+
+func TestRun(t *testing.T) {
+	w, err := Parse([]byte(reviewWorkflow))
+	require.NoError(t, err)
+
+	var prompts []string
+	stub := provider.Func("stub", func(ctx context.Context, req provider.Request) (string, error) {
+		prompts = append(prompts, req.Prompt)
+		return "answer " + req.Prompt, nil
+	})
+
+	result, err := Run(context.Background(), w,
+		WithProvider(stub),
+		WithOutputDir(t.TempDir()),
+		WithVariables(map[string]interface{}{"focus": "security"}))
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, []string{"Plan a security review", "Fix answer Plan a security review"}, prompts)
+	require.Contains(t, result.Outputs, "fix")
+	assert.Equal(t, result.Outputs["fix"], result.Final)
+
+	data, err := os.ReadFile(result.Final)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "answer Fix"))
+}
+
+func TestRunFailure(t *testing.T) {
+	w, err := Parse([]byte(reviewWorkflow))
+	require.NoError(t, err)
+
+	stub := provider.Func("stub", func(ctx context.Context, req provider.Request) (string, error) {
+		return "", errors.New("rate limited")
+	})
+	result, err := Run(context.Background(), w, WithProvider(stub), WithOutputDir(t.TempDir()))
+	require.Error(t, err)
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, ErrorGeneric, Class(err))
+	assert.Equal(t, 1, ExitCode(err))
+	assert.Equal(t, 0, ExitCode(nil))
+}
+
+func TestVariables(t *testing.T) {
+	w, err := Parse([]byte(reviewWorkflow))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"focus": "style"}, Variables(w, nil))
+	assert.Equal(t, map[string]interface{}{"focus": "perf", "extra": "1"}, Variables(w, map[string]string{"focus": "perf", "extra": "1"}))
+}
@@ -0,0 +1,114 @@
+package store
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/rizome-dev/opun/internal/workflow"
+)
+
+// Record is a finished run as kept in the run history: its manifest, output
+// directory and the feedback given on it
+type Record = workflow.RunRecord
+
+// Manifest records everything a run used
+type Manifest = workflow.RunManifest
+
+// Feedback is a rating of a run, or of one of its agents
+type Feedback = workflow.Feedback
+
+// Inspection is what each step of a run was given, see 'opun inspect'
+type Inspection = workflow.RunInspection
+
+// ErrNotInspected is returned for runs with no inspection, e.g. headless runs
+var ErrNotInspected = errors.New("run has no inspection")
+
+// Store reads and annotates the run history
+type Store interface {
+	// List returns the recorded runs, oldest first
+	List(ctx context.Context) ([]*Record, error)
+	// Get returns the record of a run
+	Get(ctx context.Context, runID string) (*Record, error)
+	// Inspection returns what each step of a run was given
+	Inspection(ctx context.Context, runID string) (*Inspection, error)
+	// AddFeedback rates a run or one of its agents
+	AddFeedback(ctx context.Context, runID string, feedback Feedback) (*Record, error)
+}
+
+// Option configures a store
+type Option func(*fileStore)
+
+// WithDir reads the run history from dir instead of ~/.opun/runs/history
+func WithDir(dir string) Option {
+	return func(s *fileStore) { s.dir = dir }
+}
+
+// Open returns the run history Opun keeps on disk
+func Open(opts ...Option) (Store, error) {
+	s := &fileStore{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.dir == "" {
+		dir, err := workflow.HistoryDir()
+		if err != nil {
+			return nil, err
+		}
+		s.dir = dir
+	}
+	return s, nil
+}
+
+// fileStore is the run history directory
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) List(ctx context.Context) ([]*Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return workflow.ListRunRecords(s.dir)
+}
+
+func (s *fileStore) Get(ctx context.Context, runID string) (*Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return workflow.LoadRunRecord(s.dir, runID)
+}
+
+func (s *fileStore) Inspection(ctx context.Context, runID string) (*Inspection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	inspection, err := workflow.LoadInspection(workflow.HistoryInspectionPath(s.dir, runID))
+	if os.IsNotExist(err) {
+		return nil, ErrNotInspected
+	}
+	return inspection, err
+}
+
+func (s *fileStore) AddFeedback(ctx context.Context, runID string, feedback Feedback) (*Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return workflow.AddFeedback(s.dir, runID, feedback)
+}
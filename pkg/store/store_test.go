@@ -0,0 +1,68 @@
+package store
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	record := `{"run_id":"7-5000","workflow":"review","status":"completed","agents":[{"id":"plan","name":"Planner","provider":"claude","model":"opus","status":"completed"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "7-5000.json"), []byte(record), 0644))
+
+	s, err := Open(WithDir(dir))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	records, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "review", records[0].Workflow)
+
+	updated, err := s.AddFeedback(ctx, "7-5000", Feedback{Agent: "Planner", Rating: 4})
+	require.NoError(t, err)
+	require.Len(t, updated.Feedback, 1)
+	assert.Equal(t, "opus", updated.Feedback[0].Model)
+
+	got, err := s.Get(ctx, "7-5000")
+	require.NoError(t, err)
+	assert.Len(t, got.Feedback, 1)
+
+	_, err = s.Inspection(ctx, "7-5000")
+	assert.ErrorIs(t, err, ErrNotInspected)
+
+	inspection, err := json.Marshal(Inspection{RunID: "7-5000", Workflow: "review"})
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "inspect"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "inspect", "7-5000.json"), inspection, 0600))
+	loaded, err := s.Inspection(ctx, "7-5000")
+	require.NoError(t, err)
+	assert.Equal(t, "review", loaded.Workflow)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.List(cancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,222 @@
+package subagent
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/core"
+)
+
+// Artifact changes
+const (
+	ArtifactCreated  = "created"
+	ArtifactModified = "modified"
+)
+
+// maxWorkspaceFiles bounds the files a workspace snapshot tracks; larger
+// workspaces get no file artifacts
+const maxWorkspaceFiles = 50000
+
+// maxHashBytes is the largest file whose content is hashed, so touching a
+// file without changing it isn't reported. Larger files are compared by
+// size and modification time.
+const maxHashBytes = 1 << 20
+
+// workspaceSkipDirs are never searched for artifacts
+var workspaceSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+}
+
+var errWorkspaceTooLarge = errors.New("workspace has too many files to track")
+
+// fileState is what a workspace snapshot records about a file
+type fileState struct {
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+	hashed  bool
+}
+
+// workspaceSnapshot is the state of every file below a directory, by path
+// relative to it
+type workspaceSnapshot map[string]fileState
+
+// snapshotWorkspace records the size, modification time and, for small
+// files, the content hash of every file below root
+func snapshotWorkspace(root string) (workspaceSnapshot, error) {
+	snapshot := make(workspaceSnapshot)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are left out rather than failing the task
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && workspaceSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(snapshot) >= maxWorkspaceFiles {
+			return errWorkspaceTooLarge
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		if info.Size() <= maxHashBytes {
+			state.hash, state.hashed = hashFile(path)
+		}
+		snapshot[filepath.ToSlash(rel)] = state
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// snapshotTaskWorkspace snapshots the directory a task works in, returning
+// a nil snapshot when it can't be tracked
+func snapshotTaskWorkspace(task core.SubAgentTask) (string, workspaceSnapshot) {
+	workDir := task.WorkDir
+	if workDir == "" {
+		var err error
+		if workDir, err = os.Getwd(); err != nil {
+			return "", nil
+		}
+	}
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", nil
+	}
+	snapshot, err := snapshotWorkspace(workDir)
+	if err != nil {
+		return "", nil
+	}
+	return workDir, snapshot
+}
+
+// hashFile returns the SHA-256 of a file's content
+func hashFile(path string) ([sha256.Size]byte, bool) {
+	// #nosec G304 -- files in the subagent's working directory
+	f, err := os.Open(path)
+	if err != nil {
+		return [sha256.Size]byte{}, false
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return [sha256.Size]byte{}, false
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, true
+}
+
+// workspaceArtifacts compares root with a snapshot taken before a subagent
+// ran and returns the files it created or modified, sorted by path. Only
+// files whose size or modification time changed are hashed again.
+func workspaceArtifacts(root string, before workspaceSnapshot) ([]core.SubAgentArtifact, error) {
+	after, err := snapshotWorkspace(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []core.SubAgentArtifact
+	for rel, state := range after {
+		change := ArtifactCreated
+		if old, ok := before[rel]; ok {
+			if old.size == state.size && old.modTime.Equal(state.modTime) {
+				continue
+			}
+			if old.hashed && state.hashed && old.hash == state.hash {
+				continue
+			}
+			change = ArtifactModified
+		}
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		artifacts = append(artifacts, core.SubAgentArtifact{
+			Name:        rel,
+			Type:        "file",
+			Path:        path,
+			ContentType: contentType(path),
+			Size:        state.size,
+			Created:     state.modTime,
+			Change:      change,
+		})
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+	return artifacts, nil
+}
+
+// contentType guesses a file's MIME type from its extension, or else from
+// its first bytes
+func contentType(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	// #nosec G304 -- files in the subagent's working directory
+	f, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return http.DetectContentType(head[:n])
+}
+
+// mergeArtifacts adds the files found in the workspace to the artifacts an
+// agent reported, skipping files it already reported
+func mergeArtifacts(reported, found []core.SubAgentArtifact) []core.SubAgentArtifact {
+	seen := make(map[string]bool, len(reported))
+	for _, a := range reported {
+		if a.Path != "" {
+			seen[filepath.Clean(a.Path)] = true
+		}
+	}
+	for _, a := range found {
+		if !seen[a.Path] {
+			reported = append(reported, a)
+		}
+	}
+	return reported
+}
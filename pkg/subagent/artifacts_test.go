@@ -0,0 +1,85 @@
+package subagent
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizome-dev/opun/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteCollectsArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("same"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "edit.go"), []byte("package a"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+
+	agent := NewMockSubAgent("writer")
+	agent.executeFunc = func(ctx context.Context, task core.SubAgentTask) (*core.SubAgentResult, error) {
+		later := time.Now().Add(time.Minute)
+		// Touched but unchanged
+		require.NoError(t, os.Chtimes(filepath.Join(task.WorkDir, "keep.txt"), later, later))
+		require.NoError(t, os.WriteFile(filepath.Join(task.WorkDir, "edit.go"), []byte("package a\n\nfunc A() {}\n"), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Join(task.WorkDir, "docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(task.WorkDir, "docs", "notes.md"), []byte("# Notes"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(task.WorkDir, ".git", "index"), []byte("ignored"), 0644))
+		return &core.SubAgentResult{
+			TaskID: task.ID,
+			Status: core.StatusCompleted,
+			// Reported by the agent itself, so not added twice
+			Artifacts: []core.SubAgentArtifact{{Name: "notes", Type: "file", Path: filepath.Join(task.WorkDir, "docs", "notes.md")}},
+		}, nil
+	}
+
+	m := NewManager()
+	require.NoError(t, m.Register(agent))
+
+	result, err := m.Execute(context.Background(), core.SubAgentTask{ID: "t1", Name: "write", WorkDir: dir}, "writer")
+	require.NoError(t, err)
+	require.Len(t, result.Artifacts, 2)
+
+	assert.Equal(t, "notes", result.Artifacts[0].Name)
+	edit := result.Artifacts[1]
+	assert.Equal(t, "edit.go", edit.Name)
+	assert.Equal(t, "file", edit.Type)
+	assert.Equal(t, filepath.Join(dir, "edit.go"), edit.Path)
+	assert.Equal(t, ArtifactModified, edit.Change)
+	assert.Equal(t, int64(len("package a\n\nfunc A() {}\n")), edit.Size)
+}
+
+func TestWorkspaceArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	before, err := snapshotWorkspace(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.json"), []byte(`{"ok":true}`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules", "x"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "node_modules", "x", "index.js"), []byte("x"), 0644))
+
+	artifacts, err := workspaceArtifacts(dir, before)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "report.json", artifacts[0].Name)
+	assert.Equal(t, ArtifactCreated, artifacts[0].Change)
+	assert.Equal(t, "application/json", artifacts[0].ContentType)
+}
@@ -163,7 +163,52 @@ func (m *Manager) Find(capabilities []string) []core.SubAgent {
 	return matched
 }
 
-// Execute executes a task with a specific agent
+// LearnFeedback passes a user's rating of a workflow agent to the router.
+// The subagent named like the agent gets it; failing that, every subagent on
+// the agent's provider does. It returns how many subagents were rated.
+func (m *Manager) LearnFeedback(task core.SubAgentTask, agentName string, provider core.ProviderType, rating int) int {
+	m.mu.RLock()
+	router := m.router
+	var rated []core.SubAgent
+	if agent, exists := m.agents[agentName]; exists {
+		rated = append(rated, agent)
+	} else if provider != "" {
+		for _, agent := range m.agents {
+			if agent.Provider() == provider {
+				rated = append(rated, agent)
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	if router == nil {
+		return 0
+	}
+	for _, agent := range rated {
+		router.Learn(task, agent, &core.SubAgentResult{
+			TaskID:    task.ID,
+			AgentName: agent.Name(),
+			Status:    core.StatusCompleted,
+			Metadata:  map[string]interface{}{core.FeedbackRatingKey: rating},
+		})
+	}
+	return len(rated)
+}
+
+// RouterStats returns the router's statistics
+func (m *Manager) RouterStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.router == nil {
+		return nil
+	}
+	return m.router.GetStats()
+}
+
+// Execute executes a task with a specific agent. Files the agent creates
+// or modifies in the task's working directory are added to the result's
+// artifacts; tasks running in the same directory at the same time may see
+// each other's files.
 func (m *Manager) Execute(ctx context.Context, task core.SubAgentTask, agentName string) (*core.SubAgentResult, error) {
 	agent, err := m.Get(agentName)
 	if err != nil {
@@ -188,8 +233,16 @@ func (m *Manager) Execute(ctx context.Context, task core.SubAgentTask, agentName
 	m.tasks[task.ID] = execution
 	m.mu.Unlock()
 	
+	// Files the agent writes while it runs become artifacts
+	workDir, before := snapshotTaskWorkspace(task)
+	
 	// Execute the task
 	result, err := agent.Execute(ctx, task)
+	if before != nil && result != nil {
+		if found, snapErr := workspaceArtifacts(workDir, before); snapErr == nil {
+			result.Artifacts = mergeArtifacts(result.Artifacts, found)
+		}
+	}
 	
 	// Update execution tracking
 	m.mu.Lock()
@@ -523,6 +523,41 @@ func TestManager_CrossProviderCoordination(t *testing.T) {
 	})
 }
 
+func TestManager_LearnFeedback(t *testing.T) {
+	manager := NewManager()
+
+	reviewer := NewMockSubAgent("reviewer")
+	reviewer.config.Provider = core.ProviderTypeClaude
+	require.NoError(t, manager.Register(reviewer))
+	writer := NewMockSubAgent("writer")
+	writer.config.Provider = core.ProviderTypeClaude
+	require.NoError(t, manager.Register(writer))
+	tester := NewMockSubAgent("tester")
+	tester.config.Provider = core.ProviderTypeGemini
+	require.NoError(t, manager.Register(tester))
+
+	task := core.SubAgentTask{ID: "run1", Name: "review"}
+	ratings := func(name string) interface{} {
+		agents := manager.RouterStats()["agents"].(map[string]interface{})
+		if stats, ok := agents[name].(map[string]interface{}); ok {
+			return stats["ratings"]
+		}
+		return nil
+	}
+
+	// A subagent with the agent's name takes the rating
+	assert.Equal(t, 1, manager.LearnFeedback(task, "reviewer", core.ProviderTypeGemini, 4))
+	assert.Equal(t, 1, ratings("reviewer"))
+	assert.Nil(t, ratings("tester"))
+
+	// Otherwise every subagent on its provider does
+	assert.Equal(t, 2, manager.LearnFeedback(task, "planner", core.ProviderTypeClaude, 2))
+	assert.Equal(t, 2, ratings("reviewer"))
+	assert.Equal(t, 1, ratings("writer"))
+
+	assert.Equal(t, 0, manager.LearnFeedback(task, "planner", core.ProviderTypeQwen, 2))
+}
+
 func TestManager_ThreadSafety(t *testing.T) {
 	manager := NewManager()
 
@@ -39,6 +39,8 @@ type agentStats struct {
 	failedTasks  int
 	totalTime    float64
 	avgTime      float64
+	ratings      int
+	ratingTotal  float64
 }
 
 // NewSimpleRouter creates a new simple router
@@ -124,6 +126,11 @@ func (r *SimpleRouter) Score(task core.SubAgentTask, agent core.SubAgent) float6
 		}
 	}
 	r.mu.RUnlock()
+
+	// User feedback moves an agent up or down by up to 10 points
+	if rating, ok := r.averageRating(agent); ok {
+		score += (rating - 3) * 5
+	}
 	
 	// Provider preference (if specified in task context)
 	if preferredProvider, ok := task.Context["preferred_provider"].(string); ok {
@@ -151,6 +158,14 @@ func (r *SimpleRouter) Learn(task core.SubAgentTask, agent core.SubAgent, result
 	}
 	
 	stats := r.stats[agent.Name()]
+
+	// Feedback rates earlier work; it isn't a task of its own
+	if rating, ok := feedbackRating(result); ok {
+		stats.ratings++
+		stats.ratingTotal += rating
+		return
+	}
+
 	stats.totalTasks++
 	
 	if result.Status == core.StatusCompleted {
@@ -179,13 +194,20 @@ func (r *SimpleRouter) GetStats() map[string]interface{} {
 	// Copy agent stats
 	agentStats := make(map[string]interface{})
 	for name, stat := range r.stats {
-		agentStats[name] = map[string]interface{}{
+		entry := map[string]interface{}{
 			"total_tasks":   stat.totalTasks,
 			"success_tasks": stat.successTasks,
 			"failed_tasks":  stat.failedTasks,
 			"avg_time":      stat.avgTime,
-			"success_rate":  float64(stat.successTasks) / float64(stat.totalTasks),
 		}
+		if stat.totalTasks > 0 {
+			entry["success_rate"] = float64(stat.successTasks) / float64(stat.totalTasks)
+		}
+		if stat.ratings > 0 {
+			entry["ratings"] = stat.ratings
+			entry["avg_rating"] = stat.ratingTotal / float64(stat.ratings)
+		}
+		agentStats[name] = entry
 	}
 	stats["agents"] = agentStats
 	
@@ -199,6 +221,31 @@ func (r *SimpleRouter) GetStats() map[string]interface{} {
 	return stats
 }
 
+// averageRating returns the average feedback rating of an agent
+func (r *SimpleRouter) averageRating(agent core.SubAgent) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats, exists := r.stats[agent.Name()]
+	if !exists || stats.ratings == 0 {
+		return 0, false
+	}
+	return stats.ratingTotal / float64(stats.ratings), true
+}
+
+// feedbackRating returns the rating of a feedback result
+func feedbackRating(result *core.SubAgentResult) (float64, bool) {
+	if result == nil || result.Metadata == nil {
+		return 0, false
+	}
+	switch rating := result.Metadata[core.FeedbackRatingKey].(type) {
+	case int:
+		return float64(rating), true
+	case float64:
+		return rating, true
+	}
+	return 0, false
+}
+
 // calculateContextScore calculates how well task context matches agent context patterns
 func (r *SimpleRouter) calculateContextScore(task core.SubAgentTask, agentContextPatterns []string) float64 {
 	if len(agentContextPatterns) == 0 {
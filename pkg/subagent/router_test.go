@@ -284,6 +284,33 @@ func TestSimpleRouter_Learn(t *testing.T) {
 	})
 }
 
+func TestSimpleRouter_LearnFeedback(t *testing.T) {
+	router := NewSimpleRouter()
+	liked := NewMockSubAgent("liked")
+	disliked := NewMockSubAgent("disliked")
+	task := core.SubAgentTask{ID: "task1", Name: "Review"}
+
+	assert.Equal(t, router.Score(task, liked), router.Score(task, disliked))
+
+	feedback := func(rating int) *core.SubAgentResult {
+		return &core.SubAgentResult{Status: core.StatusCompleted, Metadata: map[string]interface{}{core.FeedbackRatingKey: rating}}
+	}
+	router.Learn(task, liked, feedback(5))
+	router.Learn(task, liked, feedback(4))
+	router.Learn(task, disliked, feedback(1))
+
+	assert.Greater(t, router.Score(task, liked), router.Score(task, disliked))
+	chosen, err := router.Route(task, []core.SubAgent{disliked, liked})
+	require.NoError(t, err)
+	assert.Equal(t, "liked", chosen.Name())
+
+	// Feedback isn't counted as a task
+	stats := router.GetStats()["agents"].(map[string]interface{})["liked"].(map[string]interface{})
+	assert.Equal(t, 0, stats["total_tasks"])
+	assert.Equal(t, 2, stats["ratings"])
+	assert.Equal(t, 4.5, stats["avg_rating"])
+}
+
 func TestSimpleRouter_GetStats(t *testing.T) {
 	router := NewSimpleRouter()
 
@@ -30,9 +30,27 @@ type Workflow struct {
 	Variables   []Variable             `yaml:"variables" json:"variables"`
 	Agents      []Agent                `yaml:"agents" json:"agents"`
 	Settings    Settings               `yaml:"settings" json:"settings"`
+	Requires    Requirements           `yaml:"requires,omitempty" json:"requires,omitempty"`
+	Matrix      *Matrix                `yaml:"matrix,omitempty" json:"matrix,omitempty"`
 	Metadata    map[string]interface{} `yaml:"metadata" json:"metadata"`
 }
 
+// Requirements declares what a workflow needs in order to run
+type Requirements struct {
+	MCPServers []string `yaml:"mcp_servers,omitempty" json:"mcp_servers,omitempty"`
+	Actions    []string `yaml:"actions,omitempty" json:"actions,omitempty"`
+}
+
+// Matrix defines dimensions to sweep a workflow over, like a CI build matrix.
+// The provider and model dimensions override every agent; any other
+// dimension is set as a workflow variable, e.g. a prompt variant.
+// temperature is rejected, as no provider CLI takes one.
+type Matrix struct {
+	Dimensions map[string][]interface{} `yaml:",inline" json:"dimensions"`
+	// Exclude drops combinations matching all keys of an entry
+	Exclude []map[string]interface{} `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
 // Variable defines a workflow-level variable
 type Variable struct {
 	Name         string      `yaml:"name" json:"name"`
@@ -41,6 +59,19 @@ type Variable struct {
 	Required     bool        `yaml:"required" json:"required"`
 	DefaultValue interface{} `yaml:"default" json:"default"`
 	Internal     bool        `yaml:"internal" json:"internal"` // If true, don't prompt user for this variable
+	// Validation constrains the values the variable accepts
+	Validation *VariableValidation `yaml:"validation,omitempty" json:"validation,omitempty"`
+}
+
+// VariableValidation constrains a variable's value, checked for --var values,
+// defaults and answers typed in when a workflow runs
+type VariableValidation struct {
+	Pattern   string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`       // Regular expression the whole value must match
+	Min       *float64 `yaml:"min,omitempty" json:"min,omitempty"`               // Smallest number allowed
+	Max       *float64 `yaml:"max,omitempty" json:"max,omitempty"`               // Largest number allowed
+	Enum      []string `yaml:"enum,omitempty" json:"enum,omitempty"`             // Values allowed
+	MustExist bool     `yaml:"must_exist,omitempty" json:"must_exist,omitempty"` // The value is a path that must exist
+	Message   string   `yaml:"message,omitempty" json:"message,omitempty"`       // Hint shown when a value is rejected
 }
 
 // Agent represents a single agent in the workflow
@@ -53,11 +84,93 @@ type Agent struct {
 	Input     map[string]interface{} `yaml:"input" json:"input"`
 	Output    string                 `yaml:"output" json:"output"`
 	DependsOn []string               `yaml:"depends_on" json:"depends_on"`
-	Condition string                 `yaml:"condition" json:"condition"`
+	Condition string                 `yaml:"condition" json:"condition"` // Expression, the agent is skipped when it is false
 	Settings  AgentSettings          `yaml:"settings" json:"settings"`
 	OnSuccess []Action               `yaml:"on_success" json:"on_success"`
 	OnFailure []Action               `yaml:"on_failure" json:"on_failure"`
 	SubAgent  *SubAgentConfig        `yaml:"subagent,omitempty" json:"subagent,omitempty"`
+	// Requires lists capabilities (e.g. vision, 200k-context) used to choose a provider when none is set
+	Requires []string `yaml:"requires,omitempty" json:"requires,omitempty"`
+	// SystemPrompt sets the agent's role, inline or as a promptgarden://name reference
+	SystemPrompt string `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"`
+	// ContinueSession resumes the previous agent's conversation when both use the same provider
+	ContinueSession bool `yaml:"continue_session,omitempty" json:"continue_session,omitempty"`
+	// Snapshot saves the workspace before the agent runs so `opun rollback` can restore it
+	Snapshot bool `yaml:"snapshot,omitempty" json:"snapshot,omitempty"`
+	// Sandbox runs the provider in a container, overriding the workflow setting
+	Sandbox *Sandbox `yaml:"sandbox,omitempty" json:"sandbox,omitempty"`
+	// Target overrides the workflow's execution target for this agent
+	Target string `yaml:"target,omitempty" json:"target,omitempty"`
+	// Type is the kind of step: agent (default), wait or input
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Duration is how long a wait step sleeps, e.g. 30s or 5m
+	Duration string `yaml:"duration,omitempty" json:"duration,omitempty"`
+	// Until makes a wait step poll until a command succeeds or a file appears
+	Until *WaitCondition `yaml:"until,omitempty" json:"until,omitempty"`
+	// Variable is the workflow variable an input step stores the answer in
+	Variable string `yaml:"variable,omitempty" json:"variable,omitempty"`
+	// Options turns an input step into a selection
+	Options []string `yaml:"options,omitempty" json:"options,omitempty"`
+	// Produces lists the files the agent is expected to write, checked after its session
+	Produces []Artifact `yaml:"produces,omitempty" json:"produces,omitempty"`
+	// Scripts names hook scripts in ~/.opun/scripts that transform the prompt and post-process the output
+	Scripts *AgentScripts `yaml:"scripts,omitempty" json:"scripts,omitempty"`
+	// Issue is the ticket an issue step creates or updates
+	Issue *Issue `yaml:"issue,omitempty" json:"issue,omitempty"`
+	// Findings asks the agent for its review findings as JSON and converts them to SARIF and GitHub annotations
+	Findings *Findings `yaml:"findings,omitempty" json:"findings,omitempty"`
+}
+
+// Step types
+const (
+	StepTypeAgent = "agent"
+	StepTypeWait  = "wait"
+	StepTypeInput = "input"
+	StepTypeIssue = "issue"
+)
+
+// WaitCondition is what a wait step polls for. Set either Command or File.
+type WaitCondition struct {
+	Command  string `yaml:"command,omitempty" json:"command,omitempty"`   // done when the command exits 0
+	File     string `yaml:"file,omitempty" json:"file,omitempty"`         // done when the file exists
+	Timeout  string `yaml:"timeout,omitempty" json:"timeout,omitempty"`   // default 30m
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"` // time between checks, default 30s
+}
+
+// Artifact is a file an agent promises to write. When the contract isn't met
+// the agent is reminded and run again up to settings.retry_count times, then
+// the step fails.
+type Artifact struct {
+	File     string `yaml:"file" json:"file"`                               // Relative to the working directory, may use {{variables}}
+	Required *bool  `yaml:"required,omitempty" json:"required,omitempty"`   // Must exist (default true)
+	MinBytes int64  `yaml:"min_bytes,omitempty" json:"min_bytes,omitempty"` // Smallest acceptable size
+}
+
+// Findings configures converting a review agent's findings. In YAML it can
+// be written as just `findings: true`; a mapping is enabled unless it says
+// otherwise.
+type Findings struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Tool    string `yaml:"tool,omitempty" json:"tool,omitempty"` // Tool name in SARIF, default opun
+}
+
+// UnmarshalYAML accepts either a boolean or a full findings mapping
+func (f *Findings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*f = Findings{Enabled: enabled}
+		return nil
+	}
+
+	type plain Findings
+	*f = Findings{Enabled: true}
+	return unmarshal((*plain)(f))
+}
+
+// AgentScripts are the hook scripts run around an agent's session
+type AgentScripts struct {
+	Prompt string `yaml:"prompt,omitempty" json:"prompt,omitempty"` // Returns the prompt to send, or nothing to keep it
+	Output string `yaml:"output,omitempty" json:"output,omitempty"` // Returns the new output file contents, or nothing to keep them
 }
 
 // SubAgentConfig represents subagent configuration within a workflow
@@ -69,9 +182,10 @@ type SubAgentConfig struct {
 
 // AgentSettings contains agent-specific settings
 type AgentSettings struct {
-	Temperature     float64  `yaml:"temperature" json:"temperature"`
-	MaxTokens       int      `yaml:"max_tokens" json:"max_tokens"`
-	Timeout         int      `yaml:"timeout" json:"timeout"` // seconds
+	Temperature     float64  `yaml:"temperature" json:"temperature"`                 // 0 leaves the provider default
+	MaxTokens       int      `yaml:"max_tokens" json:"max_tokens"`                   // Max output tokens, 0 leaves the provider default
+	Reasoning       string   `yaml:"reasoning,omitempty" json:"reasoning,omitempty"` // low, medium or high thinking effort
+	Timeout         int      `yaml:"timeout" json:"timeout"`                         // seconds
 	RetryCount      int      `yaml:"retry_count" json:"retry_count"`
 	QualityMode     string   `yaml:"quality_mode" json:"quality_mode"`
 	Tools           []string `yaml:"tools" json:"tools"`
@@ -79,6 +193,22 @@ type AgentSettings struct {
 	WaitForFile     string   `yaml:"wait_for_file" json:"wait_for_file"`
 	Interactive     bool     `yaml:"interactive" json:"interactive"`
 	ContinueOnError bool     `yaml:"continue_on_error" json:"continue_on_error"`
+	// MaxMemoryMB is the most resident memory the provider process tree may use, 0 for no limit
+	MaxMemoryMB int `yaml:"max_memory_mb,omitempty" json:"max_memory_mb,omitempty"`
+	// OnMemoryLimit is what happens over MaxMemoryMB: kill (default) or warn
+	OnMemoryLimit string `yaml:"on_memory_limit,omitempty" json:"on_memory_limit,omitempty"`
+	// OutputNudges is how many times an agent that didn't save its output file is reminded to (default 1, 0 to never)
+	OutputNudges *int `yaml:"output_nudges,omitempty" json:"output_nudges,omitempty"`
+	// IdleTimeout is how long a session may go without output before OnIdle applies, e.g. 10m
+	IdleTimeout string `yaml:"idle_timeout,omitempty" json:"idle_timeout,omitempty"`
+	// OnIdle is what happens after IdleTimeout: notify (default), prompt or terminate
+	OnIdle string `yaml:"on_idle,omitempty" json:"on_idle,omitempty"`
+	// ReadyTimeout is how long to wait for the provider's input prompt before OnNotReady applies, e.g. 45s (default 30s)
+	ReadyTimeout string `yaml:"ready_timeout,omitempty" json:"ready_timeout,omitempty"`
+	// OnNotReady is what happens when the input prompt isn't detected: ask, retry, type or fail
+	OnNotReady string `yaml:"on_not_ready,omitempty" json:"on_not_ready,omitempty"`
+	// PromptInjection is how the prompt reaches the session: type (default) or flag, as a launch argument
+	PromptInjection string `yaml:"prompt_injection,omitempty" json:"prompt_injection,omitempty"`
 }
 
 // Settings contains workflow-level settings
@@ -88,6 +218,199 @@ type Settings struct {
 	StopOnError   bool   `yaml:"stop_on_error" json:"stop_on_error"`
 	OutputDir     string `yaml:"output_dir" json:"output_dir"`
 	LogLevel      string `yaml:"log_level" json:"log_level"`
+	// OutputLayout is flat (default) or per_agent, which gives every agent its own subdirectory
+	OutputLayout string `yaml:"output_layout,omitempty" json:"output_layout,omitempty"`
+	// HandoffSummary compresses large agent outputs before they are handed to later agents
+	HandoffSummary *HandoffSummary `yaml:"handoff_summary,omitempty" json:"handoff_summary,omitempty"`
+	// PromptGuard checks composed prompts against the model's context window
+	PromptGuard *PromptGuard `yaml:"prompt_guard,omitempty" json:"prompt_guard,omitempty"`
+	// Sandbox runs every agent's provider in a container
+	Sandbox *Sandbox `yaml:"sandbox,omitempty" json:"sandbox,omitempty"`
+	// Target is where agents run: local (default), ssh://user@host[:port][/dir],
+	// the name of a node (an SSH alias from ~/.opun/nodes.yaml), or
+	// k8s[://namespace] for headless steps
+	Target string `yaml:"target,omitempty" json:"target,omitempty"`
+	// Kubernetes runs headless steps whose target is k8s as Kubernetes Jobs
+	Kubernetes *Kubernetes `yaml:"kubernetes,omitempty" json:"kubernetes,omitempty"`
+	// Redact scrubs secrets and PII from agent outputs before they are persisted
+	Redact *Redact `yaml:"redact,omitempty" json:"redact,omitempty"`
+	// Lock is a named lock held for the whole run, so workflows sharing it never run at once
+	Lock string `yaml:"lock,omitempty" json:"lock,omitempty"`
+	// OnLocked is what a run does while another holds its lock: wait (default) or fail
+	OnLocked string `yaml:"on_locked,omitempty" json:"on_locked,omitempty"`
+	// LockTimeout is the longest a run waits for its lock, e.g. 30m; empty waits forever
+	LockTimeout string `yaml:"lock_timeout,omitempty" json:"lock_timeout,omitempty"`
+	// Storage uploads the output directory to a bucket when the run ends
+	Storage *Storage `yaml:"storage,omitempty" json:"storage,omitempty"`
+	// MissingRefs is what happens when a prompt references an @path that
+	// doesn't exist or can't be read: warn (default), fail or ignore
+	MissingRefs string `yaml:"missing_refs,omitempty" json:"missing_refs,omitempty"`
+	// Issues configures the Jira and Linear accounts issue steps file tickets in
+	Issues *IssueTrackers `yaml:"issues,omitempty" json:"issues,omitempty"`
+	// Email sends a report of the run by email when it ends
+	Email *Email `yaml:"email,omitempty" json:"email,omitempty"`
+	// ShowDiff is what is shown of the files each agent changed: summary
+	// (default), page to page through the diff, or off
+	ShowDiff string `yaml:"show_diff,omitempty" json:"show_diff,omitempty"`
+}
+
+// Kubernetes configures the Jobs that run headless steps on a cluster. The
+// repository is mounted from a volume claim and the provider CLI comes from
+// the image.
+type Kubernetes struct {
+	Context        string            `yaml:"context,omitempty" json:"context,omitempty"`                 // kubectl context, default the current one
+	Namespace      string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`             // Default "default"; k8s://<namespace> targets override it
+	Image          string            `yaml:"image" json:"image"`                                         // Must contain the provider CLI, sh and tar
+	Volume         string            `yaml:"volume" json:"volume"`                                       // PersistentVolumeClaim holding the repository
+	SubPath        string            `yaml:"sub_path,omitempty" json:"sub_path,omitempty"`               // Directory of the repository in the volume
+	MountPath      string            `yaml:"mount_path,omitempty" json:"mount_path,omitempty"`           // Where the repository is mounted, default /workspace
+	EnvSecret      string            `yaml:"env_secret,omitempty" json:"env_secret,omitempty"`           // Secret whose keys become environment variables, e.g. API keys
+	ServiceAccount string            `yaml:"service_account,omitempty" json:"service_account,omitempty"` // Service account the Jobs run as
+	CPU            string            `yaml:"cpu,omitempty" json:"cpu,omitempty"`                         // CPU request and limit, e.g. "2"
+	Memory         string            `yaml:"memory,omitempty" json:"memory,omitempty"`                   // Memory request and limit, e.g. 4Gi
+	NodeSelector   map[string]string `yaml:"node_selector,omitempty" json:"node_selector,omitempty"`     // Labels of the nodes Jobs may run on
+	Timeout        string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`                 // Longest a Job may run, default 30m
+	Keep           bool              `yaml:"keep,omitempty" json:"keep,omitempty"`                       // Keep finished Jobs instead of deleting them
+}
+
+// IssueTrackers configures the issue trackers issue steps file tickets in.
+// API tokens are read from environment variables, never from the workflow.
+type IssueTrackers struct {
+	Jira   *JiraTracker   `yaml:"jira,omitempty" json:"jira,omitempty"`
+	Linear *LinearTracker `yaml:"linear,omitempty" json:"linear,omitempty"`
+	DryRun bool           `yaml:"dry_run,omitempty" json:"dry_run,omitempty"` // Print what issue steps would send instead of sending it
+}
+
+// JiraTracker is a Jira Cloud or Server site
+type JiraTracker struct {
+	URL       string `yaml:"url" json:"url"`                                   // Site, e.g. https://acme.atlassian.net
+	Email     string `yaml:"email,omitempty" json:"email,omitempty"`           // Account of the API token; empty sends it as a bearer token
+	TokenEnv  string `yaml:"token_env,omitempty" json:"token_env,omitempty"`   // Variable holding the API token, default JIRA_API_TOKEN
+	Project   string `yaml:"project,omitempty" json:"project,omitempty"`       // Default project key
+	IssueType string `yaml:"issue_type,omitempty" json:"issue_type,omitempty"` // Default issue type, default Task
+}
+
+// LinearTracker is a Linear workspace
+type LinearTracker struct {
+	TokenEnv string `yaml:"token_env,omitempty" json:"token_env,omitempty"` // Variable holding the API key, default LINEAR_API_KEY
+	Team     string `yaml:"team,omitempty" json:"team,omitempty"`           // Default team key
+	APIURL   string `yaml:"api_url,omitempty" json:"api_url,omitempty"`     // GraphQL endpoint, default https://api.linear.app/graphql
+}
+
+// Issue is a ticket an issue step files. Every text field may use
+// {{variables}}, {{agent.output}} and {{agent.output.json.path}}.
+type Issue struct {
+	Tracker  string   `yaml:"tracker" json:"tracker"`                       // jira or linear
+	Key      string   `yaml:"key,omitempty" json:"key,omitempty"`           // Issue to update, e.g. OPS-12; empty creates one
+	Project  string   `yaml:"project,omitempty" json:"project,omitempty"`   // Jira project or Linear team key, overrides the tracker's
+	Type     string   `yaml:"type,omitempty" json:"type,omitempty"`         // Jira issue type
+	Title    string   `yaml:"title,omitempty" json:"title,omitempty"`       // Required to create
+	Body     string   `yaml:"body,omitempty" json:"body,omitempty"`         // Description, markdown
+	Labels   []string `yaml:"labels,omitempty" json:"labels,omitempty"`     // A reference to a list adds each item
+	Priority string   `yaml:"priority,omitempty" json:"priority,omitempty"` // Jira priority name, or Linear 0-4 / urgent, high, medium, low
+	DryRun   bool     `yaml:"dry_run,omitempty" json:"dry_run,omitempty"`   // Print the request instead of sending it
+}
+
+// Email configures the report emailed when a run ends. The SMTP server is
+// usually set in ~/.opun/config.yaml and the recipients per workflow; in
+// YAML `email: false` turns the configured report off for a workflow.
+type Email struct {
+	Disabled    bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Host        string   `yaml:"host,omitempty" json:"host,omitempty"`                 // SMTP server
+	Port        int      `yaml:"port,omitempty" json:"port,omitempty"`                 // Default 587, or 465 with tls
+	TLS         string   `yaml:"tls,omitempty" json:"tls,omitempty"`                   // starttls (default), tls or none
+	Username    string   `yaml:"username,omitempty" json:"username,omitempty"`         // Login; empty sends without one
+	PasswordEnv string   `yaml:"password_env,omitempty" json:"password_env,omitempty"` // Variable holding the password, default SMTP_PASSWORD
+	From        string   `yaml:"from,omitempty" json:"from,omitempty"`
+	To          []string `yaml:"to,omitempty" json:"to,omitempty"`
+	Cc          []string `yaml:"cc,omitempty" json:"cc,omitempty"`
+	On          []string `yaml:"on,omitempty" json:"on,omitempty"`           // Run statuses that send the report: completed, failed, aborted; default all
+	Subject     string   `yaml:"subject,omitempty" json:"subject,omitempty"` // Go template over the report
+	Body        string   `yaml:"body,omitempty" json:"body,omitempty"`       // Go template over the report
+	Attach      []string `yaml:"attach,omitempty" json:"attach,omitempty"`   // Steps whose outputs are attached, default the final one; [] attaches none
+}
+
+// UnmarshalYAML accepts either a boolean or a full email mapping
+func (e *Email) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*e = Email{Disabled: !enabled}
+		return nil
+	}
+
+	type plain Email
+	return unmarshal((*plain)(e))
+}
+
+// Storage configures uploading run artifacts to an object store
+type Storage struct {
+	Backend        string `yaml:"backend" json:"backend"`                                     // s3, gcs or azure
+	Bucket         string `yaml:"bucket" json:"bucket"`                                       // Bucket, or container for azure
+	Prefix         string `yaml:"prefix,omitempty" json:"prefix,omitempty"`                   // Key prefix, default {{workflow}}/{{run_id}}
+	Region         string `yaml:"region,omitempty" json:"region,omitempty"`                   // s3 only
+	Endpoint       string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`               // S3-compatible endpoint, e.g. MinIO or R2
+	Account        string `yaml:"account,omitempty" json:"account,omitempty"`                 // Storage account, azure only
+	ServiceAccount string `yaml:"service_account,omitempty" json:"service_account,omitempty"` // Service account that signs URLs, gcs only
+	URLExpiry      string `yaml:"url_expiry,omitempty" json:"url_expiry,omitempty"`           // Lifetime of signed URLs, default 24h; 0 signs none
+}
+
+// Redact configures scrubbing secrets and PII from run artifacts.
+// In YAML it can be written as just `redact: true`.
+type Redact struct {
+	Enabled  bool              `yaml:"enabled" json:"enabled"`
+	Patterns map[string]string `yaml:"patterns,omitempty" json:"patterns,omitempty"` // Extra regular expressions by name
+	Disable  []string          `yaml:"disable,omitempty" json:"disable,omitempty"`   // Built-in patterns to skip, e.g. email
+}
+
+// UnmarshalYAML accepts either a boolean or a full redact mapping
+func (r *Redact) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*r = Redact{Enabled: enabled}
+		return nil
+	}
+
+	type plain Redact
+	return unmarshal((*plain)(r))
+}
+
+// Sandbox configures running a provider inside a container.
+// In YAML it can be written as just the runtime, e.g. `sandbox: docker`.
+type Sandbox struct {
+	Runtime          string   `yaml:"runtime" json:"runtime"`                                         // docker, podman or none
+	Image            string   `yaml:"image,omitempty" json:"image,omitempty"`                         // Must contain the provider CLI
+	Network          string   `yaml:"network,omitempty" json:"network,omitempty"`                     // none, bridge (default), host or a named network
+	Mounts           []string `yaml:"mounts,omitempty" json:"mounts,omitempty"`                       // Extra host:container[:ro] mounts
+	Env              []string `yaml:"env,omitempty" json:"env,omitempty"`                             // Extra host environment variables to pass through
+	MountCredentials *bool    `yaml:"mount_credentials,omitempty" json:"mount_credentials,omitempty"` // Mount provider login state (default true)
+}
+
+// UnmarshalYAML accepts either a runtime name or a full sandbox mapping
+func (s *Sandbox) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var runtime string
+	if err := unmarshal(&runtime); err == nil {
+		*s = Sandbox{Runtime: runtime}
+		return nil
+	}
+
+	type plain Sandbox
+	return unmarshal((*plain)(s))
+}
+
+// PromptGuard configures prompt size checks before a prompt is injected
+type PromptGuard struct {
+	Mode          string `yaml:"mode,omitempty" json:"mode,omitempty"`                     // warn (default), error or trim
+	ContextWindow int    `yaml:"context_window,omitempty" json:"context_window,omitempty"` // Overrides the provider's known window, in tokens
+	Reserve       int    `yaml:"reserve,omitempty" json:"reserve,omitempty"`               // Tokens kept free for the response
+}
+
+// HandoffSummary configures summarization of agent outputs between agents
+type HandoffSummary struct {
+	Enabled   bool   `yaml:"enabled" json:"enabled"`
+	Provider  string `yaml:"provider,omitempty" json:"provider,omitempty"`   // Defaults to the agent's provider
+	Model     string `yaml:"model,omitempty" json:"model,omitempty"`         // Defaults to the provider's default model
+	Threshold int    `yaml:"threshold,omitempty" json:"threshold,omitempty"` // Output size in bytes above which to summarize
+	MaxWords  int    `yaml:"max_words,omitempty" json:"max_words,omitempty"` // Target length of the brief
 }
 
 // Action represents an action to take on success/failure
@@ -132,6 +455,7 @@ const (
 	StatusFailed    ExecutionStatus = "failed"
 	StatusSkipped   ExecutionStatus = "skipped"
 	StatusAborted   ExecutionStatus = "aborted"
+	StatusTimedOut  ExecutionStatus = "timed_out"
 )
 
 // ExecutionError represents an error during execution
@@ -140,6 +464,7 @@ type ExecutionError struct {
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
 	Fatal     bool      `json:"fatal"`
+	Class     string    `json:"class,omitempty"` // Kind of failure, e.g. timeout or auth
 }
 
 // WorkflowEvent represents an event during workflow execution
@@ -155,6 +480,7 @@ type WorkflowEvent struct {
 type EventType string
 
 const (
+	EventWorkflowQueued   EventType = "workflow_queued"
 	EventWorkflowStart    EventType = "workflow_start"
 	EventWorkflowComplete EventType = "workflow_complete"
 	EventWorkflowError    EventType = "workflow_error"
@@ -162,6 +488,9 @@ const (
 	EventAgentComplete    EventType = "agent_complete"
 	EventAgentError       EventType = "agent_error"
 	EventAgentRetry       EventType = "agent_retry"
+	EventAgentSkipped     EventType = "agent_skipped"
 	EventVariableSet      EventType = "variable_set"
 	EventOutputCreated    EventType = "output_created"
+	EventOutputChunk      EventType = "output_chunk"
+	EventVariableNeeded   EventType = "variable_needed"
 )
@@ -0,0 +1,134 @@
+package e2e
+
+// Copyright (C) 2025 Rizome Labs, Inc.
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+
+import (
+	"context"
+	"testing"
+
+	workflowexec "github.com/rizome-dev/opun/internal/workflow"
+	"github.com/rizome-dev/opun/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chaosWorkflow is three mock agents, each building on the one before
+func chaosWorkflow(continueOnError bool) *workflow.Workflow {
+	wf := &workflow.Workflow{Name: "chaos", Version: "1.0.0"}
+	for _, id := range []string{"plan", "build", "review"} {
+		wf.Agents = append(wf.Agents, workflow.Agent{
+			ID:       id,
+			Name:     id,
+			Provider: "mock",
+			Prompt:   "Do the " + id + " step",
+			Settings: workflow.AgentSettings{ContinueOnError: continueOnError},
+		})
+	}
+	return wf
+}
+
+// runChaos runs a workflow headlessly with failures injected per spec
+func runChaos(t *testing.T, wf *workflow.Workflow, spec string) workflowexec.MatrixResult {
+	t.Helper()
+	chaos, err := workflowexec.ParseChaos(spec)
+	require.NoError(t, err)
+
+	runner := workflowexec.NewMatrixRunner(t.TempDir(), 1)
+	runner.Chaos = chaos
+	runner.SetRunner(func(ctx context.Context, provider, model, prompt string) (string, error) {
+		return `{"status": "ok"}`, nil
+	})
+	result, _ := runner.RunHeadless(context.Background(), wf, nil)
+	return result
+}
+
+// assertChaosHandled checks a run dealt with every injected failure: an exit
+// either failed the run at that agent or was tolerated, and no agent that
+// exited left an output behind
+func assertChaosHandled(t *testing.T, wf *workflow.Workflow, result workflowexec.MatrixResult) {
+	t.Helper()
+	for _, event := range result.Chaos {
+		if event.Kind != workflowexec.ChaosExit {
+			continue
+		}
+		assert.NotContains(t, result.Outputs, event.Agent, "agent %s exited but has an output", event.Agent)
+
+		tolerated := false
+		for _, agent := range wf.Agents {
+			if agent.ID == event.Agent {
+				tolerated = agent.Settings.ContinueOnError
+			}
+		}
+		if !tolerated {
+			assert.Equal(t, string(workflow.StatusFailed), result.Status)
+			assert.Contains(t, result.Error, "agent "+event.Agent+":")
+			assert.Contains(t, result.Error, "(chaos)")
+		}
+	}
+}
+
+func TestChaosExitFailsRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E test in short mode")
+	}
+
+	wf := chaosWorkflow(false)
+	result := runChaos(t, wf, "exit=1,seed=1")
+	require.Len(t, result.Chaos, 1)
+	assert.Equal(t, "plan", result.Chaos[0].Agent)
+	assertChaosHandled(t, wf, result)
+}
+
+func TestChaosExitTolerated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E test in short mode")
+	}
+
+	wf := chaosWorkflow(true)
+	result := runChaos(t, wf, "exit=1,seed=1")
+	assert.Equal(t, string(workflow.StatusCompleted), result.Status)
+	assert.Len(t, result.Chaos, 3)
+	assert.Empty(t, result.Outputs)
+	assertChaosHandled(t, wf, result)
+}
+
+func TestChaosMalformedOutputFailsInputMapping(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E test in short mode")
+	}
+
+	wf := chaosWorkflow(false)
+	wf.Agents[1].Input = map[string]interface{}{"status": "{{plan.output.json.status}}"}
+	result := runChaos(t, wf, "malformed=1,seed=1")
+	assert.Equal(t, string(workflow.StatusFailed), result.Status)
+	assert.Contains(t, result.Error, "agent build:")
+	assert.Contains(t, result.Outputs, "plan")
+	assertChaosHandled(t, wf, result)
+}
+
+func TestChaosSeedIsRepeatable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E test in short mode")
+	}
+
+	spec := "delay=5ms,exit=0.3,malformed=0.3,seed=42"
+	first := runChaos(t, chaosWorkflow(true), spec)
+	second := runChaos(t, chaosWorkflow(true), spec)
+	assert.Equal(t, first.Chaos, second.Chaos)
+	assertChaosHandled(t, chaosWorkflow(true), first)
+	require.NotEmpty(t, first.Chaos)
+}